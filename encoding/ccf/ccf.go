@@ -0,0 +1,335 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ccf implements the Cadence Compact Format (CCF),
+// a deterministic CBOR-based encoding of Cadence values and their types.
+//
+// In contrast to JSON-Cadence, types are not repeated for each value:
+// A message first contains the definitions of all composite and interface types
+// (structs, resources, events, contracts, enums, and interfaces) used by the value,
+// which are then referenced by index. Values are encoded against their static type,
+// e.g. the element type of an array, or the field type of a composite,
+// and only values whose dynamic type differs from the static type are encoded with their type,
+// e.g. the elements of an array of type `[AnyStruct]`.
+//
+// A message is encoded as a CBOR array:
+//
+//	[
+//	    // composite and interface type definition headers, referenced by index
+//	    [ tag(<kind>) <type ID> ... ],
+//	    // composite and interface type definition bodies, in the same order
+//	    [ [ <fields>, <initializers>, <enum raw type> ] ... ],
+//	    // the type of the value
+//	    <type>,
+//	    // the value, encoded against the type
+//	    <value>
+//	]
+//
+package ccf
+
+import (
+	"math"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence"
+)
+
+// !!! *WARNING* !!!
+//
+// Only add new tags by:
+// - replacing existing placeholders (`_`) with new tags
+// - appending new tags
+//
+// DO *NOT* REPLACE EXISTING TAGS!
+// DO *NOT* ADD NEW TAGS IN BETWEEN!
+
+const CBORTagBase = 128
+
+const (
+	// Values
+
+	// CBORTagTypeAndValue tags a value which is encoded together with its type,
+	// because its dynamic type differs from the static type
+	CBORTagTypeAndValue = CBORTagBase + iota
+	// CBORTagSomeValue tags a non-nil optional value
+	CBORTagSomeValue
+	_
+	_
+	_
+	_
+	_
+	_
+
+	// Inline types
+
+	CBORTagSimpleType
+	CBORTagOptionalType
+	CBORTagVariableSizedArrayType
+	CBORTagConstantSizedArrayType
+	CBORTagDictionaryType
+	CBORTagReferenceType
+	CBORTagRestrictedType
+	CBORTagCapabilityType
+	CBORTagFunctionType
+	CBORTagTypeRef
+	CBORTagTypeID
+	CBORTagUntypedType
+	_
+	_
+	_
+	_
+
+	// Type definitions
+
+	CBORTagStructType
+	CBORTagResourceType
+	CBORTagEventType
+	CBORTagContractType
+	CBORTagEnumType
+	CBORTagStructInterfaceType
+	CBORTagResourceInterfaceType
+	CBORTagContractInterfaceType
+
+	// !!! *WARNING* !!!
+	// ADD NEW TAGS *BEFORE* THIS WARNING.
+	// DO *NOT* ADD NEW TAGS AFTER THIS LINE!
+	CBORTag_Count
+)
+
+// untypedKind is the kind of value which has no type,
+// e.g. an array created using cadence.NewArray without a type, or a link.
+// Such values are encoded with the type tag(CBORTagUntypedType) <untyped kind>,
+// and their elements are encoded with their types.
+//
+type untypedKind uint64
+
+// !!! *WARNING* !!!
+//
+// Only append new kinds. DO *NOT* REPLACE EXISTING KINDS!
+
+const (
+	untypedKindArray untypedKind = iota
+	untypedKindDictionary
+	untypedKindStruct
+	untypedKindResource
+	untypedKindEvent
+	untypedKindContract
+	untypedKindEnum
+	untypedKindLink
+)
+
+// simpleTypeCode is the code of a type which has no type arguments,
+// encoded as tag(CBORTagSimpleType) <simple type code>.
+//
+type simpleTypeCode uint64
+
+// !!! *WARNING* !!!
+//
+// Only append new codes. DO *NOT* REPLACE EXISTING CODES!
+
+const (
+	simpleTypeAny simpleTypeCode = iota
+	simpleTypeAnyStruct
+	simpleTypeAnyResource
+	simpleTypeMeta
+	simpleTypeVoid
+	simpleTypeNever
+	simpleTypeBool
+	simpleTypeString
+	simpleTypeCharacter
+	simpleTypeBytes
+	simpleTypeAddress
+	simpleTypeNumber
+	simpleTypeSignedNumber
+	simpleTypeInteger
+	simpleTypeSignedInteger
+	simpleTypeFixedPoint
+	simpleTypeSignedFixedPoint
+	simpleTypeInt
+	simpleTypeInt8
+	simpleTypeInt16
+	simpleTypeInt32
+	simpleTypeInt64
+	simpleTypeInt128
+	simpleTypeInt256
+	simpleTypeUInt
+	simpleTypeUInt8
+	simpleTypeUInt16
+	simpleTypeUInt32
+	simpleTypeUInt64
+	simpleTypeUInt128
+	simpleTypeUInt256
+	simpleTypeWord8
+	simpleTypeWord16
+	simpleTypeWord32
+	simpleTypeWord64
+	simpleTypeFix64
+	simpleTypeUFix64
+	simpleTypeBlock
+	simpleTypePath
+	simpleTypeCapabilityPath
+	simpleTypeStoragePath
+	simpleTypePublicPath
+	simpleTypePrivatePath
+	simpleTypeAuthAccount
+	simpleTypePublicAccount
+	simpleTypeAuthAccountKeys
+	simpleTypePublicAccountKeys
+	simpleTypeAuthAccountContracts
+	simpleTypePublicAccountContracts
+	simpleTypeDeployedContract
+	simpleTypeAccountKey
+)
+
+func simpleTypeCodeOf(typ cadence.Type) (simpleTypeCode, bool) {
+	switch typ.(type) {
+	case cadence.AnyType:
+		return simpleTypeAny, true
+	case cadence.AnyStructType:
+		return simpleTypeAnyStruct, true
+	case cadence.AnyResourceType:
+		return simpleTypeAnyResource, true
+	case cadence.MetaType:
+		return simpleTypeMeta, true
+	case cadence.VoidType:
+		return simpleTypeVoid, true
+	case cadence.NeverType:
+		return simpleTypeNever, true
+	case cadence.BoolType:
+		return simpleTypeBool, true
+	case cadence.StringType:
+		return simpleTypeString, true
+	case cadence.CharacterType:
+		return simpleTypeCharacter, true
+	case cadence.BytesType:
+		return simpleTypeBytes, true
+	case cadence.AddressType:
+		return simpleTypeAddress, true
+	case cadence.NumberType:
+		return simpleTypeNumber, true
+	case cadence.SignedNumberType:
+		return simpleTypeSignedNumber, true
+	case cadence.IntegerType:
+		return simpleTypeInteger, true
+	case cadence.SignedIntegerType:
+		return simpleTypeSignedInteger, true
+	case cadence.FixedPointType:
+		return simpleTypeFixedPoint, true
+	case cadence.SignedFixedPointType:
+		return simpleTypeSignedFixedPoint, true
+	case cadence.IntType:
+		return simpleTypeInt, true
+	case cadence.Int8Type:
+		return simpleTypeInt8, true
+	case cadence.Int16Type:
+		return simpleTypeInt16, true
+	case cadence.Int32Type:
+		return simpleTypeInt32, true
+	case cadence.Int64Type:
+		return simpleTypeInt64, true
+	case cadence.Int128Type:
+		return simpleTypeInt128, true
+	case cadence.Int256Type:
+		return simpleTypeInt256, true
+	case cadence.UIntType:
+		return simpleTypeUInt, true
+	case cadence.UInt8Type:
+		return simpleTypeUInt8, true
+	case cadence.UInt16Type:
+		return simpleTypeUInt16, true
+	case cadence.UInt32Type:
+		return simpleTypeUInt32, true
+	case cadence.UInt64Type:
+		return simpleTypeUInt64, true
+	case cadence.UInt128Type:
+		return simpleTypeUInt128, true
+	case cadence.UInt256Type:
+		return simpleTypeUInt256, true
+	case cadence.Word8Type:
+		return simpleTypeWord8, true
+	case cadence.Word16Type:
+		return simpleTypeWord16, true
+	case cadence.Word32Type:
+		return simpleTypeWord32, true
+	case cadence.Word64Type:
+		return simpleTypeWord64, true
+	case cadence.Fix64Type:
+		return simpleTypeFix64, true
+	case cadence.UFix64Type:
+		return simpleTypeUFix64, true
+	case cadence.BlockType:
+		return simpleTypeBlock, true
+	case cadence.PathType:
+		return simpleTypePath, true
+	case cadence.CapabilityPathType:
+		return simpleTypeCapabilityPath, true
+	case cadence.StoragePathType:
+		return simpleTypeStoragePath, true
+	case cadence.PublicPathType:
+		return simpleTypePublicPath, true
+	case cadence.PrivatePathType:
+		return simpleTypePrivatePath, true
+	case cadence.AuthAccountType:
+		return simpleTypeAuthAccount, true
+	case cadence.PublicAccountType:
+		return simpleTypePublicAccount, true
+	case cadence.AuthAccountKeysType:
+		return simpleTypeAuthAccountKeys, true
+	case cadence.PublicAccountKeysType:
+		return simpleTypePublicAccountKeys, true
+	case cadence.AuthAccountContractsType:
+		return simpleTypeAuthAccountContracts, true
+	case cadence.PublicAccountContractsType:
+		return simpleTypePublicAccountContracts, true
+	case cadence.DeployedContractType:
+		return simpleTypeDeployedContract, true
+	case cadence.AccountKeyType:
+		return simpleTypeAccountKey, true
+	}
+
+	return 0, false
+}
+
+// CBOREncMode
+//
+// See https://github.com/fxamacker/cbor:
+// "For best performance, reuse EncMode and DecMode after creating them."
+//
+var CBOREncMode = func() cbor.EncMode {
+	options := cbor.CanonicalEncOptions()
+	options.BigIntConvert = cbor.BigIntConvertNone
+	encMode, err := options.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return encMode
+}()
+
+var CBORDecMode = func() cbor.DecMode {
+	decMode, err := cbor.DecOptions{
+		IntDec:           cbor.IntDecConvertNone,
+		MaxArrayElements: math.MaxInt64,
+		MaxMapPairs:      math.MaxInt64,
+		MaxNestedLevels:  math.MaxInt16,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return decMode
+}()
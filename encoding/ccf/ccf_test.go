@@ -0,0 +1,600 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+type encodeTest struct {
+	name string
+	val  cadence.Value
+}
+
+func testAllEncodeAndDecode(t *testing.T, tests ...encodeTest) {
+
+	test := func(testCase encodeTest) {
+
+		t.Run(testCase.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			testEncodeAndDecode(t, testCase.val)
+		})
+	}
+
+	for _, testCase := range tests {
+		test(testCase)
+	}
+}
+
+func testEncodeAndDecode(t *testing.T, val cadence.Value, options ...ccf.Option) []byte {
+	encoded, err := ccf.Encode(val)
+	require.NoError(t, err)
+
+	decoded, err := ccf.Decode(nil, encoded, options...)
+	require.NoError(t, err)
+
+	assert.Equal(t, val, decoded)
+
+	// Encoding is deterministic
+
+	reencoded, err := ccf.Encode(decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, encoded, reencoded)
+
+	return encoded
+}
+
+func must(value cadence.Value, err error) cadence.Value {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+var fooStructType = &cadence.StructType{
+	Location:            utils.TestLocation,
+	QualifiedIdentifier: "Foo",
+	Fields: []cadence.Field{
+		{
+			Identifier: "a",
+			Type:       cadence.IntType{},
+		},
+		{
+			Identifier: "b",
+			Type:       cadence.StringType{},
+		},
+	},
+	Initializers: [][]cadence.Parameter{
+		{
+			{
+				Label:      "a",
+				Identifier: "a",
+				Type:       cadence.IntType{},
+			},
+			{
+				Label:      "_",
+				Identifier: "b",
+				Type:       cadence.StringType{},
+			},
+		},
+	},
+}
+
+func TestEncodeSimpleValues(t *testing.T) {
+
+	t.Parallel()
+
+	testAllEncodeAndDecode(t,
+		encodeTest{"Void", cadence.NewVoid()},
+		encodeTest{"True", cadence.NewBool(true)},
+		encodeTest{"False", cadence.NewBool(false)},
+		encodeTest{"String", cadence.String("foo")},
+		encodeTest{"Empty string", cadence.String("")},
+		encodeTest{"Character", cadence.Character("a")},
+		encodeTest{"Address", cadence.BytesToAddress([]byte{1, 2, 3, 4, 5, 6, 7, 8})},
+		encodeTest{"Path", cadence.NewPath("storage", "foo")},
+		encodeTest{"Link", cadence.NewLink(cadence.NewPath("private", "foo"), "&Foo")},
+	)
+}
+
+func TestEncodeNumbers(t *testing.T) {
+
+	t.Parallel()
+
+	bigPositive, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigNegative := new(big.Int).Neg(bigPositive)
+
+	testAllEncodeAndDecode(t,
+		encodeTest{"Int zero", cadence.NewInt(0)},
+		encodeTest{"Int negative", cadence.NewIntFromBig(bigNegative)},
+		encodeTest{"Int positive", cadence.NewIntFromBig(bigPositive)},
+		encodeTest{"Int8 min", cadence.NewInt8(-128)},
+		encodeTest{"Int8 max", cadence.NewInt8(127)},
+		encodeTest{"Int16", cadence.NewInt16(-32768)},
+		encodeTest{"Int32", cadence.NewInt32(2147483647)},
+		encodeTest{"Int64", cadence.NewInt64(-9223372036854775808)},
+		encodeTest{"Int128", must(cadence.NewInt128FromBig(bigNegative))},
+		encodeTest{"Int256", must(cadence.NewInt256FromBig(bigPositive))},
+		encodeTest{"UInt", must(cadence.NewUIntFromBig(bigPositive))},
+		encodeTest{"UInt8", cadence.NewUInt8(255)},
+		encodeTest{"UInt16", cadence.NewUInt16(65535)},
+		encodeTest{"UInt32", cadence.NewUInt32(4294967295)},
+		encodeTest{"UInt64", cadence.NewUInt64(18446744073709551615)},
+		encodeTest{"UInt128", must(cadence.NewUInt128FromBig(bigPositive))},
+		encodeTest{"UInt256", must(cadence.NewUInt256FromBig(bigPositive))},
+		encodeTest{"Word8", cadence.NewWord8(255)},
+		encodeTest{"Word16", cadence.NewWord16(65535)},
+		encodeTest{"Word32", cadence.NewWord32(4294967295)},
+		encodeTest{"Word64", cadence.NewWord64(18446744073709551615)},
+		encodeTest{"Fix64", cadence.Fix64(-12_300_000_000)},
+		encodeTest{"UFix64", cadence.UFix64(12_300_000_000)},
+	)
+}
+
+func TestEncodeBytes(t *testing.T) {
+
+	t.Parallel()
+
+	encoded, err := ccf.Encode(cadence.NewInt8(-1))
+	require.NoError(t, err)
+
+	// [ [], [], tag(SimpleType) Int8, -1 ]
+	assert.Equal(t, "848080d8881220", hex.EncodeToString(encoded))
+}
+
+func TestEncodeOptional(t *testing.T) {
+
+	t.Parallel()
+
+	testAllEncodeAndDecode(t,
+		encodeTest{"Nil", cadence.NewOptional(nil)},
+		encodeTest{"Non-nil", cadence.NewOptional(cadence.NewInt(42))},
+		encodeTest{"Nested nil", cadence.NewOptional(cadence.NewOptional(nil))},
+		encodeTest{"Nested non-nil", cadence.NewOptional(cadence.NewOptional(cadence.String("foo")))},
+	)
+}
+
+func TestEncodeArray(t *testing.T) {
+
+	t.Parallel()
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Untyped",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.String("foo"),
+			}),
+		},
+		encodeTest{
+			"Untyped, nil values",
+			cadence.NewArray(nil),
+		},
+		encodeTest{
+			"Variable-sized, empty",
+			cadence.NewArray([]cadence.Value{}).
+				WithType(cadence.NewVariableSizedArrayType(cadence.IntType{})),
+		},
+		encodeTest{
+			"Variable-sized",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.NewInt(2),
+			}).WithType(cadence.NewVariableSizedArrayType(cadence.IntType{})),
+		},
+		encodeTest{
+			"Constant-sized",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewUInt8(1),
+				cadence.NewUInt8(2),
+			}).WithType(cadence.NewConstantSizedArrayType(2, cadence.UInt8Type{})),
+		},
+		encodeTest{
+			"AnyStruct elements",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.String("foo"),
+				cadence.NewOptional(cadence.NewBool(true)),
+				cadence.NewArray([]cadence.Value{
+					cadence.NewInt8(1),
+				}).WithType(cadence.NewVariableSizedArrayType(cadence.Int8Type{})),
+			}).WithType(cadence.NewVariableSizedArrayType(cadence.AnyStructType{})),
+		},
+		encodeTest{
+			"Optional elements",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewOptional(nil),
+				cadence.NewOptional(cadence.NewInt(1)),
+			}).WithType(cadence.NewVariableSizedArrayType(cadence.NewOptionalType(cadence.IntType{}))),
+		},
+	)
+}
+
+func TestEncodeDictionary(t *testing.T) {
+
+	t.Parallel()
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Untyped",
+			cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.NewInt(1)},
+				{Key: cadence.NewInt(2), Value: cadence.NewBool(true)},
+			}),
+		},
+		encodeTest{
+			"Typed",
+			cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.NewInt(1)},
+				{Key: cadence.String("b"), Value: cadence.NewInt(2)},
+			}).WithType(cadence.NewDictionaryType(cadence.StringType{}, cadence.IntType{})),
+		},
+		encodeTest{
+			"AnyStruct values",
+			cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.NewInt(1)},
+				{Key: cadence.String("b"), Value: cadence.String("foo")},
+			}).WithType(cadence.NewDictionaryType(cadence.StringType{}, cadence.AnyStructType{})),
+		},
+	)
+}
+
+func TestEncodeComposite(t *testing.T) {
+
+	t.Parallel()
+
+	fooEventType := &cadence.EventType{
+		Location:            common.AddressLocation{Name: "Bar"},
+		QualifiedIdentifier: "Bar.Foo",
+		Fields: []cadence.Field{
+			{
+				Identifier: "value",
+				Type:       cadence.AnyStructType{},
+			},
+		},
+		Initializer: []cadence.Parameter{},
+	}
+
+	fooEnumType := &cadence.EnumType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "E",
+		RawType:             cadence.UInt8Type{},
+		Fields: []cadence.Field{
+			{
+				Identifier: "rawValue",
+				Type:       cadence.UInt8Type{},
+			},
+		},
+	}
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Struct",
+			cadence.NewStruct([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.String("foo"),
+			}).WithType(fooStructType),
+		},
+		encodeTest{
+			"Untyped struct",
+			cadence.NewStruct([]cadence.Value{
+				cadence.NewInt(1),
+			}),
+		},
+		encodeTest{
+			"Event",
+			cadence.NewEvent([]cadence.Value{
+				cadence.NewStruct([]cadence.Value{
+					cadence.NewInt(1),
+					cadence.String("foo"),
+				}).WithType(fooStructType),
+			}).WithType(fooEventType),
+		},
+		encodeTest{
+			"Enum",
+			cadence.NewEnum([]cadence.Value{
+				cadence.NewUInt8(1),
+			}).WithType(fooEnumType),
+		},
+		encodeTest{
+			"Array of structs",
+			cadence.NewArray([]cadence.Value{
+				cadence.NewStruct([]cadence.Value{
+					cadence.NewInt(1),
+					cadence.String("foo"),
+				}).WithType(fooStructType),
+				cadence.NewStruct([]cadence.Value{
+					cadence.NewInt(2),
+					cadence.String("bar"),
+				}).WithType(fooStructType),
+			}).WithType(cadence.NewVariableSizedArrayType(fooStructType)),
+		},
+	)
+}
+
+func TestEncodeRecursiveType(t *testing.T) {
+
+	t.Parallel()
+
+	ty := &cadence.ResourceType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "Foo",
+		Fields: []cadence.Field{
+			{
+				Identifier: "foo",
+			},
+		},
+	}
+
+	ty.Fields[0].Type = cadence.OptionalType{
+		Type: ty,
+	}
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Resource",
+			cadence.NewResource([]cadence.Value{
+				cadence.NewOptional(
+					cadence.NewResource([]cadence.Value{
+						cadence.NewOptional(nil),
+					}).WithType(ty),
+				),
+			}).WithType(ty),
+		},
+		encodeTest{
+			"Type value",
+			cadence.TypeValue{
+				StaticType: ty,
+			},
+		},
+	)
+}
+
+func TestEncodeTypeValue(t *testing.T) {
+
+	t.Parallel()
+
+	interfaceType := &cadence.ResourceInterfaceType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "I",
+		Fields:              []cadence.Field{},
+		Initializers:        [][]cadence.Parameter{},
+	}
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Simple",
+			cadence.TypeValue{StaticType: cadence.IntType{}},
+		},
+		encodeTest{
+			"Empty",
+			cadence.TypeValue{},
+		},
+		encodeTest{
+			"Reference",
+			cadence.TypeValue{
+				StaticType: cadence.ReferenceType{
+					Authorized: true,
+					Type:       fooStructType,
+				},
+			},
+		},
+		encodeTest{
+			"Restricted",
+			cadence.TypeValue{
+				StaticType: (&cadence.RestrictedType{
+					Type:         cadence.AnyResourceType{},
+					Restrictions: []cadence.Type{interfaceType},
+				}).WithID("AnyResource{S.test.I}"),
+			},
+		},
+		encodeTest{
+			"Function",
+			cadence.TypeValue{
+				StaticType: (&cadence.FunctionType{
+					Parameters: []cadence.Parameter{
+						{Label: "_", Identifier: "x", Type: cadence.IntType{}},
+					},
+					ReturnType: cadence.StringType{},
+				}).WithID("((Int):String)"),
+			},
+		},
+		encodeTest{
+			"Dictionary",
+			cadence.TypeValue{
+				StaticType: cadence.DictionaryType{
+					KeyType:     cadence.StringType{},
+					ElementType: cadence.NewConstantSizedArrayType(3, cadence.AddressType{}),
+				},
+			},
+		},
+	)
+}
+
+func TestEncodeCapability(t *testing.T) {
+
+	t.Parallel()
+
+	testAllEncodeAndDecode(t,
+		encodeTest{
+			"Typed",
+			cadence.NewCapability(
+				cadence.NewPath("public", "foo"),
+				cadence.BytesToAddress([]byte{1}),
+				cadence.ReferenceType{Type: fooStructType},
+			),
+		},
+		encodeTest{
+			"Untyped",
+			cadence.NewCapability(
+				cadence.NewPath("public", "foo"),
+				cadence.BytesToAddress([]byte{1}),
+				nil,
+			),
+		},
+	)
+}
+
+func TestEncodeRepeatedTypes(t *testing.T) {
+
+	t.Parallel()
+
+	// Distinct, but equal types share a single definition
+
+	otherFooStructType := *fooStructType
+
+	value := cadence.NewArray([]cadence.Value{
+		cadence.NewStruct([]cadence.Value{
+			cadence.NewInt(1),
+			cadence.String("foo"),
+		}).WithType(fooStructType),
+		cadence.NewStruct([]cadence.Value{
+			cadence.NewInt(2),
+			cadence.String("bar"),
+		}).WithType(&otherFooStructType),
+	}).WithType(cadence.NewVariableSizedArrayType(fooStructType))
+
+	encoded, err := ccf.Encode(value)
+	require.NoError(t, err)
+
+	decoded, err := ccf.Decode(nil, encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, value, decoded)
+
+	elements := decoded.(cadence.Array).Values
+	assert.Same(t,
+		elements[0].(cadence.Struct).StructType,
+		elements[1].(cadence.Struct).StructType,
+	)
+}
+
+func TestEncodeUnstructuredStaticType(t *testing.T) {
+
+	t.Parallel()
+
+	value := cadence.TypeValue{
+		StaticType: cadence.TypeID("S.test.Foo"),
+	}
+
+	encoded, err := ccf.Encode(value)
+	require.NoError(t, err)
+
+	_, err = ccf.Decode(nil, encoded)
+	require.Error(t, err)
+
+	testEncodeAndDecode(t, value, ccf.WithAllowUnstructuredStaticTypes(true))
+}
+
+func TestEncodeSmallerThanJSON(t *testing.T) {
+
+	t.Parallel()
+
+	values := make([]cadence.Value, 0, 100)
+	for i := 0; i < 100; i++ {
+		values = append(
+			values,
+			cadence.NewStruct([]cadence.Value{
+				cadence.NewInt(i),
+				cadence.String("foo"),
+			}).WithType(fooStructType),
+		)
+	}
+
+	value := cadence.NewArray(values).
+		WithType(cadence.NewVariableSizedArrayType(fooStructType))
+
+	ccfEncoded, err := ccf.Encode(value)
+	require.NoError(t, err)
+
+	jsonEncoded, err := json.Encode(value)
+	require.NoError(t, err)
+
+	assert.Less(t, len(ccfEncoded)*5, len(jsonEncoded))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+
+	t.Parallel()
+
+	valid := ccf.MustEncode(cadence.NewInt8(-1))
+
+	for name, encoded := range map[string][]byte{
+		"empty":         {},
+		"not an array":  {0x01},
+		"short array":   {0x83, 0x80, 0x80, 0xf6},
+		"trailing data": append(valid, 0x00),
+		"missing type":  {0x84, 0x80, 0x80, 0xf6, 0x20},
+		// [ [], [], tag(SimpleType) Int8, 128 ]
+		"out of range": {0x84, 0x80, 0x80, 0xd8, 0x88, 0x12, 0x18, 0x80},
+		// [ [], [], tag(TypeRef) 0, [] ]
+		"missing type definition": {0x84, 0x80, 0x80, 0xd8, 0x91, 0x00, 0x80},
+	} {
+		_, err := ccf.Decode(nil, encoded)
+		require.Error(t, err, name)
+	}
+}
+
+type testMemoryGauge struct {
+	meter map[common.MemoryKind]uint64
+}
+
+func (g *testMemoryGauge) MeterMemory(usage common.MemoryUsage) error {
+	g.meter[usage.Kind] += usage.Amount
+	return nil
+}
+
+func TestDecodeMemoryMetering(t *testing.T) {
+
+	t.Parallel()
+
+	encoded := ccf.MustEncode(
+		cadence.NewStruct([]cadence.Value{
+			cadence.NewInt(1),
+			cadence.String("foo"),
+		}).WithType(fooStructType),
+	)
+
+	gauge := &testMemoryGauge{
+		meter: map[common.MemoryKind]uint64{},
+	}
+
+	_, err := ccf.Decode(gauge, encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), gauge.meter[common.MemoryKindCadenceStructType])
+	assert.Equal(t, uint64(1), gauge.meter[common.MemoryKindCadenceStructValueBase])
+	assert.Equal(t, uint64(2), gauge.meter[common.MemoryKindCadenceField])
+	assert.Equal(t, uint64(2), gauge.meter[common.MemoryKindCadenceParameter])
+	assert.NotZero(t, gauge.meter[common.MemoryKindCadenceIntValue])
+}
@@ -0,0 +1,1157 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// A Decoder decodes CCF-encoded representations of Cadence values.
+type Decoder struct {
+	dec   *cbor.StreamDecoder
+	gauge common.MemoryGauge
+	// allowUnstructuredStaticTypes controls if the decoding
+	// of a static type as a type ID (cadence.TypeID) is allowed
+	allowUnstructuredStaticTypes bool
+	// typeDefs are the composite and interface types defined in the message
+	typeDefs []cadence.Type
+}
+
+type Option func(*Decoder)
+
+// WithAllowUnstructuredStaticTypes returns a new Decoder Option
+// which enables or disables if the decoding of a static type
+// as a type ID (cadence.TypeID) is allowed
+//
+func WithAllowUnstructuredStaticTypes(allow bool) Option {
+	return func(decoder *Decoder) {
+		decoder.allowUnstructuredStaticTypes = allow
+	}
+}
+
+// Decode returns a Cadence value decoded from its CCF-encoded representation.
+//
+// This function returns an error if the bytes represent CBOR that is malformed,
+// does not conform to the CCF specification, or is followed by trailing data.
+func Decode(gauge common.MemoryGauge, b []byte, options ...Option) (cadence.Value, error) {
+	r := bytes.NewReader(b)
+	dec := NewDecoder(gauge, r)
+
+	for _, option := range options {
+		option(dec)
+	}
+
+	v, err := dec.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if dec.dec.NumBytesDecoded() != len(b) {
+		return nil, errors.NewDefaultUserError(
+			"failed to decode value: %w",
+			ErrTrailingData,
+		)
+	}
+
+	return v, nil
+}
+
+// NewDecoder initializes a Decoder that will decode CCF-encoded bytes from the
+// given io.Reader.
+func NewDecoder(gauge common.MemoryGauge, r io.Reader) *Decoder {
+	return &Decoder{
+		dec:   CBORDecMode.NewStreamDecoder(r),
+		gauge: gauge,
+	}
+}
+
+// Decode reads CCF-encoded bytes from the io.Reader and decodes them to a
+// Cadence value.
+//
+// This function returns an error if the bytes represent CBOR that is malformed
+// or does not conform to the CCF specification.
+func (d *Decoder) Decode() (value cadence.Value, err error) {
+	// capture panics that occur during decoding
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr, isError := r.(error)
+			if !isError {
+				panic(r)
+			}
+
+			err = errors.NewDefaultUserError("failed to decode value: %w", panicErr)
+		}
+	}()
+
+	d.typeDefs = nil
+
+	d.decodeArrayHeadWithLength(4)
+
+	d.decodeTypeDefHeaders()
+	d.decodeTypeDefBodies()
+
+	typ, kind, untyped := d.decodeValueType()
+	if untyped {
+		return d.decodeUntypedData(kind), nil
+	}
+
+	return d.decodeData(typ), nil
+}
+
+var ErrInvalidCCF = errors.NewDefaultUserError("invalid CCF structure")
+
+var ErrTrailingData = errors.NewDefaultUserError("trailing data after CCF message")
+
+func (d *Decoder) decodeTypeDefHeaders() {
+	count := d.decodeArrayHead()
+
+	d.typeDefs = make([]cadence.Type, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		tag := d.decodeTagNumber()
+
+		location, qualifiedIdentifier, err := common.DecodeTypeID(d.gauge, d.decodeString())
+		if err != nil {
+			panic(ErrInvalidCCF)
+		}
+
+		// Fields and initializers are decoded separately,
+		// as they may refer to any of the defined types
+
+		var typ cadence.Type
+
+		switch tag {
+		case CBORTagStructType:
+			typ = cadence.NewMeteredStructType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagResourceType:
+			typ = cadence.NewMeteredResourceType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagEventType:
+			typ = cadence.NewMeteredEventType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagContractType:
+			typ = cadence.NewMeteredContractType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagEnumType:
+			typ = cadence.NewMeteredEnumType(d.gauge, location, qualifiedIdentifier, nil, nil, nil)
+		case CBORTagStructInterfaceType:
+			typ = cadence.NewMeteredStructInterfaceType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagResourceInterfaceType:
+			typ = cadence.NewMeteredResourceInterfaceType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		case CBORTagContractInterfaceType:
+			typ = cadence.NewMeteredContractInterfaceType(d.gauge, location, qualifiedIdentifier, nil, nil)
+		default:
+			panic(ErrInvalidCCF)
+		}
+
+		d.typeDefs = append(d.typeDefs, typ)
+	}
+}
+
+func (d *Decoder) decodeTypeDefBodies() {
+	d.decodeArrayHeadWithLength(uint64(len(d.typeDefs)))
+
+	for _, typ := range d.typeDefs {
+		switch typ := typ.(type) {
+		case *cadence.EventType:
+			d.decodeArrayHeadWithLength(2)
+			typ.Fields = d.decodeFields()
+			typ.Initializer = d.decodeParameters()
+
+		case *cadence.EnumType:
+			d.decodeArrayHeadWithLength(3)
+			typ.Fields = d.decodeFields()
+			typ.Initializers = d.decodeInitializers()
+			typ.RawType = d.decodeType()
+
+		case cadence.CompositeType:
+			d.decodeArrayHeadWithLength(2)
+			typ.SetCompositeFields(d.decodeFields())
+			initializers := d.decodeInitializers()
+			switch typ := typ.(type) {
+			case *cadence.StructType:
+				typ.Initializers = initializers
+			case *cadence.ResourceType:
+				typ.Initializers = initializers
+			case *cadence.ContractType:
+				typ.Initializers = initializers
+			default:
+				panic(errors.NewUnreachableError())
+			}
+
+		case cadence.InterfaceType:
+			d.decodeArrayHeadWithLength(2)
+			typ.SetInterfaceFields(d.decodeFields())
+			initializers := d.decodeInitializers()
+			switch typ := typ.(type) {
+			case *cadence.StructInterfaceType:
+				typ.Initializers = initializers
+			case *cadence.ResourceInterfaceType:
+				typ.Initializers = initializers
+			case *cadence.ContractInterfaceType:
+				typ.Initializers = initializers
+			default:
+				panic(errors.NewUnreachableError())
+			}
+
+		default:
+			panic(errors.NewUnreachableError())
+		}
+	}
+}
+
+func (d *Decoder) decodeFields() []cadence.Field {
+	count, isNil := d.decodeArrayHeadOrNil()
+	if isNil {
+		return nil
+	}
+
+	common.UseMemory(d.gauge, common.MemoryUsage{
+		Kind:   common.MemoryKindCadenceField,
+		Amount: count,
+	})
+
+	fields := make([]cadence.Field, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		d.decodeArrayHeadWithLength(2)
+		// Unmetered because the fields are metered above
+		fields = append(
+			fields,
+			cadence.NewField(
+				d.decodeString(),
+				d.decodeType(),
+			),
+		)
+	}
+
+	return fields
+}
+
+func (d *Decoder) decodeParameters() []cadence.Parameter {
+	count, isNil := d.decodeArrayHeadOrNil()
+	if isNil {
+		return nil
+	}
+
+	common.UseMemory(d.gauge, common.MemoryUsage{
+		Kind:   common.MemoryKindCadenceParameter,
+		Amount: count,
+	})
+
+	parameters := make([]cadence.Parameter, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		d.decodeArrayHeadWithLength(3)
+		// Unmetered because the parameters are metered above
+		parameters = append(
+			parameters,
+			cadence.NewParameter(
+				d.decodeString(),
+				d.decodeString(),
+				d.decodeType(),
+			),
+		)
+	}
+
+	return parameters
+}
+
+func (d *Decoder) decodeInitializers() [][]cadence.Parameter {
+	count, isNil := d.decodeArrayHeadOrNil()
+	if isNil {
+		return nil
+	}
+
+	// Unmetered because this is created as an array of nil arrays, not Parameter structs
+	initializers := make([][]cadence.Parameter, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		initializers = append(initializers, d.decodeParameters())
+	}
+
+	return initializers
+}
+
+// decodeValueType decodes the type of a value.
+// If the value has no type, untyped is true and kind is the kind of the value.
+//
+func (d *Decoder) decodeValueType() (typ cadence.Type, kind untypedKind, untyped bool) {
+	if d.nextType() == cbor.TagType {
+		tag := d.decodeTagNumber()
+		if tag == CBORTagUntypedType {
+			kind := untypedKind(d.decodeUint64())
+			if kind > untypedKindLink {
+				panic(ErrInvalidCCF)
+			}
+			return nil, kind, true
+		}
+		return d.decodeTaggedType(tag), 0, false
+	}
+
+	return d.decodeType(), 0, false
+}
+
+func (d *Decoder) decodeType() cadence.Type {
+	switch d.nextType() {
+	case cbor.NilType:
+		d.decodeNil()
+		return nil
+
+	case cbor.TagType:
+		return d.decodeTaggedType(d.decodeTagNumber())
+
+	default:
+		panic(ErrInvalidCCF)
+	}
+}
+
+func (d *Decoder) decodeTaggedType(tag uint64) cadence.Type {
+	switch tag {
+	case CBORTagSimpleType:
+		return d.decodeSimpleType(simpleTypeCode(d.decodeUint64()))
+
+	case CBORTagOptionalType:
+		return cadence.NewMeteredOptionalType(d.gauge, d.decodeType())
+
+	case CBORTagVariableSizedArrayType:
+		return cadence.NewMeteredVariableSizedArrayType(d.gauge, d.decodeType())
+
+	case CBORTagConstantSizedArrayType:
+		d.decodeArrayHeadWithLength(2)
+		size := d.decodeUint64()
+		if size > math.MaxUint32 {
+			panic(ErrInvalidCCF)
+		}
+		return cadence.NewMeteredConstantSizedArrayType(d.gauge, uint(size), d.decodeType())
+
+	case CBORTagDictionaryType:
+		d.decodeArrayHeadWithLength(2)
+		keyType := d.decodeType()
+		elementType := d.decodeType()
+		return cadence.NewMeteredDictionaryType(d.gauge, keyType, elementType)
+
+	case CBORTagReferenceType:
+		d.decodeArrayHeadWithLength(2)
+		authorized := d.decodeBool()
+		return cadence.NewMeteredReferenceType(d.gauge, authorized, d.decodeType())
+
+	case CBORTagRestrictedType:
+		d.decodeArrayHeadWithLength(3)
+		typeID := d.decodeString()
+		typ := d.decodeType()
+
+		var restrictions []cadence.Type
+		count, isNil := d.decodeArrayHeadOrNil()
+		if !isNil {
+			restrictions = make([]cadence.Type, 0, count)
+			for i := uint64(0); i < count; i++ {
+				restrictions = append(restrictions, d.decodeType())
+			}
+		}
+
+		return cadence.NewMeteredRestrictedType(
+			d.gauge,
+			"",
+			typ,
+			restrictions,
+		).WithID(typeID)
+
+	case CBORTagCapabilityType:
+		return cadence.NewMeteredCapabilityType(d.gauge, d.decodeType())
+
+	case CBORTagFunctionType:
+		d.decodeArrayHeadWithLength(3)
+		typeID := d.decodeString()
+		parameters := d.decodeParameters()
+		returnType := d.decodeType()
+		return cadence.NewMeteredFunctionType(
+			d.gauge,
+			"",
+			parameters,
+			returnType,
+		).WithID(typeID)
+
+	case CBORTagTypeRef:
+		index := d.decodeUint64()
+		if index >= uint64(len(d.typeDefs)) {
+			panic(ErrInvalidCCF)
+		}
+		return d.typeDefs[index]
+
+	case CBORTagTypeID:
+		if !d.allowUnstructuredStaticTypes {
+			panic(ErrInvalidCCF)
+		}
+		typeID := d.decodeString()
+		common.UseMemory(d.gauge, common.MemoryUsage{
+			Kind:   common.MemoryKindRawString,
+			Amount: uint64(len(typeID)),
+		})
+		return cadence.TypeID(typeID)
+
+	default:
+		panic(ErrInvalidCCF)
+	}
+}
+
+func (d *Decoder) decodeSimpleType(code simpleTypeCode) cadence.Type {
+	switch code {
+	case simpleTypeAny:
+		return cadence.NewMeteredAnyType(d.gauge)
+	case simpleTypeAnyStruct:
+		return cadence.NewMeteredAnyStructType(d.gauge)
+	case simpleTypeAnyResource:
+		return cadence.NewMeteredAnyResourceType(d.gauge)
+	case simpleTypeMeta:
+		return cadence.NewMeteredMetaType(d.gauge)
+	case simpleTypeVoid:
+		return cadence.NewMeteredVoidType(d.gauge)
+	case simpleTypeNever:
+		return cadence.NewMeteredNeverType(d.gauge)
+	case simpleTypeBool:
+		return cadence.NewMeteredBoolType(d.gauge)
+	case simpleTypeString:
+		return cadence.NewMeteredStringType(d.gauge)
+	case simpleTypeCharacter:
+		return cadence.NewMeteredCharacterType(d.gauge)
+	case simpleTypeBytes:
+		return cadence.NewMeteredBytesType(d.gauge)
+	case simpleTypeAddress:
+		return cadence.NewMeteredAddressType(d.gauge)
+	case simpleTypeNumber:
+		return cadence.NewMeteredNumberType(d.gauge)
+	case simpleTypeSignedNumber:
+		return cadence.NewMeteredSignedNumberType(d.gauge)
+	case simpleTypeInteger:
+		return cadence.NewMeteredIntegerType(d.gauge)
+	case simpleTypeSignedInteger:
+		return cadence.NewMeteredSignedIntegerType(d.gauge)
+	case simpleTypeFixedPoint:
+		return cadence.NewMeteredFixedPointType(d.gauge)
+	case simpleTypeSignedFixedPoint:
+		return cadence.NewMeteredSignedFixedPointType(d.gauge)
+	case simpleTypeInt:
+		return cadence.NewMeteredIntType(d.gauge)
+	case simpleTypeInt8:
+		return cadence.NewMeteredInt8Type(d.gauge)
+	case simpleTypeInt16:
+		return cadence.NewMeteredInt16Type(d.gauge)
+	case simpleTypeInt32:
+		return cadence.NewMeteredInt32Type(d.gauge)
+	case simpleTypeInt64:
+		return cadence.NewMeteredInt64Type(d.gauge)
+	case simpleTypeInt128:
+		return cadence.NewMeteredInt128Type(d.gauge)
+	case simpleTypeInt256:
+		return cadence.NewMeteredInt256Type(d.gauge)
+	case simpleTypeUInt:
+		return cadence.NewMeteredUIntType(d.gauge)
+	case simpleTypeUInt8:
+		return cadence.NewMeteredUInt8Type(d.gauge)
+	case simpleTypeUInt16:
+		return cadence.NewMeteredUInt16Type(d.gauge)
+	case simpleTypeUInt32:
+		return cadence.NewMeteredUInt32Type(d.gauge)
+	case simpleTypeUInt64:
+		return cadence.NewMeteredUInt64Type(d.gauge)
+	case simpleTypeUInt128:
+		return cadence.NewMeteredUInt128Type(d.gauge)
+	case simpleTypeUInt256:
+		return cadence.NewMeteredUInt256Type(d.gauge)
+	case simpleTypeWord8:
+		return cadence.NewMeteredWord8Type(d.gauge)
+	case simpleTypeWord16:
+		return cadence.NewMeteredWord16Type(d.gauge)
+	case simpleTypeWord32:
+		return cadence.NewMeteredWord32Type(d.gauge)
+	case simpleTypeWord64:
+		return cadence.NewMeteredWord64Type(d.gauge)
+	case simpleTypeFix64:
+		return cadence.NewMeteredFix64Type(d.gauge)
+	case simpleTypeUFix64:
+		return cadence.NewMeteredUFix64Type(d.gauge)
+	case simpleTypeBlock:
+		return cadence.NewMeteredBlockType(d.gauge)
+	case simpleTypePath:
+		return cadence.NewMeteredPathType(d.gauge)
+	case simpleTypeCapabilityPath:
+		return cadence.NewMeteredCapabilityPathType(d.gauge)
+	case simpleTypeStoragePath:
+		return cadence.NewMeteredStoragePathType(d.gauge)
+	case simpleTypePublicPath:
+		return cadence.NewMeteredPublicPathType(d.gauge)
+	case simpleTypePrivatePath:
+		return cadence.NewMeteredPrivatePathType(d.gauge)
+	case simpleTypeAuthAccount:
+		return cadence.NewMeteredAuthAccountType(d.gauge)
+	case simpleTypePublicAccount:
+		return cadence.NewMeteredPublicAccountType(d.gauge)
+	case simpleTypeAuthAccountKeys:
+		return cadence.NewMeteredAuthAccountKeysType(d.gauge)
+	case simpleTypePublicAccountKeys:
+		return cadence.NewMeteredPublicAccountKeysType(d.gauge)
+	case simpleTypeAuthAccountContracts:
+		return cadence.NewMeteredAuthAccountContractsType(d.gauge)
+	case simpleTypePublicAccountContracts:
+		return cadence.NewMeteredPublicAccountContractsType(d.gauge)
+	case simpleTypeDeployedContract:
+		return cadence.NewMeteredDeployedContractType(d.gauge)
+	case simpleTypeAccountKey:
+		return cadence.NewMeteredAccountKeyType(d.gauge)
+	default:
+		panic(ErrInvalidCCF)
+	}
+}
+
+// decodeValue decodes a value which was encoded against the given static type,
+// i.e. either just the data of the value, or the value together with its type.
+//
+func (d *Decoder) decodeValue(staticType cadence.Type) cadence.Value {
+	if d.nextType() == cbor.TagType {
+		tag := d.decodeTagNumber()
+		switch tag {
+		case CBORTagTypeAndValue:
+			d.decodeArrayHeadWithLength(2)
+			typ, kind, untyped := d.decodeValueType()
+			if untyped {
+				return d.decodeUntypedData(kind)
+			}
+			return d.decodeData(typ)
+
+		case CBORTagSomeValue:
+			optionalType, ok := staticType.(cadence.OptionalType)
+			if !ok {
+				panic(ErrInvalidCCF)
+			}
+			return cadence.NewMeteredOptional(d.gauge, d.decodeValue(optionalType.Type))
+
+		default:
+			panic(ErrInvalidCCF)
+		}
+	}
+
+	return d.decodeData(staticType)
+}
+
+// decodeData decodes the data of a value of the given type.
+//
+func (d *Decoder) decodeData(typ cadence.Type) cadence.Value {
+	switch typ := typ.(type) {
+	case nil:
+		panic(ErrInvalidCCF)
+
+	case cadence.OptionalType:
+		if d.nextType() == cbor.NilType {
+			d.decodeNil()
+			return cadence.NewMeteredOptional(d.gauge, nil)
+		}
+		if d.decodeTagNumber() != CBORTagSomeValue {
+			panic(ErrInvalidCCF)
+		}
+		return cadence.NewMeteredOptional(d.gauge, d.decodeValue(typ.Type))
+
+	case cadence.VoidType:
+		d.decodeNil()
+		return cadence.NewMeteredVoid(d.gauge)
+
+	case cadence.BoolType:
+		return cadence.NewMeteredBool(d.gauge, d.decodeBool())
+
+	case cadence.StringType:
+		s := d.decodeString()
+		str, err := cadence.NewMeteredString(
+			d.gauge,
+			common.NewCadenceStringMemoryUsage(len(s)),
+			func() string {
+				return s
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return str
+
+	case cadence.CharacterType:
+		s := d.decodeString()
+		char, err := cadence.NewMeteredCharacter(
+			d.gauge,
+			common.NewCadenceCharacterMemoryUsage(len(s)),
+			func() string {
+				return s
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return char
+
+	case cadence.AddressType:
+		return d.decodeAddress()
+
+	case cadence.IntType:
+		i := d.decodeBigInt()
+		return cadence.NewMeteredIntFromBig(
+			d.gauge,
+			common.NewCadenceIntMemoryUsage(
+				common.BigIntByteLength(i),
+			),
+			func() *big.Int {
+				return i
+			},
+		)
+
+	case cadence.Int8Type:
+		return cadence.NewMeteredInt8(d.gauge, int8(d.decodeInt64InRange(math.MinInt8, math.MaxInt8)))
+
+	case cadence.Int16Type:
+		return cadence.NewMeteredInt16(d.gauge, int16(d.decodeInt64InRange(math.MinInt16, math.MaxInt16)))
+
+	case cadence.Int32Type:
+		return cadence.NewMeteredInt32(d.gauge, int32(d.decodeInt64InRange(math.MinInt32, math.MaxInt32)))
+
+	case cadence.Int64Type:
+		return cadence.NewMeteredInt64(d.gauge, d.decodeInt64())
+
+	case cadence.Int128Type:
+		i := d.decodeBigInt()
+		value, err := cadence.NewMeteredInt128FromBig(
+			d.gauge,
+			func() *big.Int {
+				return i
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return value
+
+	case cadence.Int256Type:
+		i := d.decodeBigInt()
+		value, err := cadence.NewMeteredInt256FromBig(
+			d.gauge,
+			func() *big.Int {
+				return i
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return value
+
+	case cadence.UIntType:
+		i := d.decodeBigInt()
+		value, err := cadence.NewMeteredUIntFromBig(
+			d.gauge,
+			common.NewCadenceIntMemoryUsage(
+				common.BigIntByteLength(i),
+			),
+			func() *big.Int {
+				return i
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return value
+
+	case cadence.UInt8Type:
+		return cadence.NewMeteredUInt8(d.gauge, uint8(d.decodeUint64InRange(math.MaxUint8)))
+
+	case cadence.UInt16Type:
+		return cadence.NewMeteredUInt16(d.gauge, uint16(d.decodeUint64InRange(math.MaxUint16)))
+
+	case cadence.UInt32Type:
+		return cadence.NewMeteredUInt32(d.gauge, uint32(d.decodeUint64InRange(math.MaxUint32)))
+
+	case cadence.UInt64Type:
+		return cadence.NewMeteredUInt64(d.gauge, d.decodeUint64())
+
+	case cadence.UInt128Type:
+		i := d.decodeBigInt()
+		value, err := cadence.NewMeteredUInt128FromBig(
+			d.gauge,
+			func() *big.Int {
+				return i
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return value
+
+	case cadence.UInt256Type:
+		i := d.decodeBigInt()
+		value, err := cadence.NewMeteredUInt256FromBig(
+			d.gauge,
+			func() *big.Int {
+				return i
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return value
+
+	case cadence.Word8Type:
+		return cadence.NewMeteredWord8(d.gauge, uint8(d.decodeUint64InRange(math.MaxUint8)))
+
+	case cadence.Word16Type:
+		return cadence.NewMeteredWord16(d.gauge, uint16(d.decodeUint64InRange(math.MaxUint16)))
+
+	case cadence.Word32Type:
+		return cadence.NewMeteredWord32(d.gauge, uint32(d.decodeUint64InRange(math.MaxUint32)))
+
+	case cadence.Word64Type:
+		return cadence.NewMeteredWord64(d.gauge, d.decodeUint64())
+
+	case cadence.Fix64Type:
+		return cadence.NewMeteredFix64FromRawFixedPointNumber(d.gauge, d.decodeInt64())
+
+	case cadence.UFix64Type:
+		return cadence.NewMeteredUFix64FromRawFixedPointNumber(d.gauge, d.decodeUint64())
+
+	case cadence.ArrayType:
+		return d.decodeArray(typ)
+
+	case cadence.DictionaryType:
+		return d.decodeDictionary(typ)
+
+	case *cadence.StructType:
+		fields := d.decodeCompositeFields(typ.Fields)
+		structure, err := cadence.NewMeteredStruct(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return structure.WithType(typ)
+
+	case *cadence.ResourceType:
+		fields := d.decodeCompositeFields(typ.Fields)
+		resource, err := cadence.NewMeteredResource(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return resource.WithType(typ)
+
+	case *cadence.EventType:
+		fields := d.decodeCompositeFields(typ.Fields)
+		event, err := cadence.NewMeteredEvent(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return event.WithType(typ)
+
+	case *cadence.ContractType:
+		fields := d.decodeCompositeFields(typ.Fields)
+		contract, err := cadence.NewMeteredContract(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return contract.WithType(typ)
+
+	case *cadence.EnumType:
+		fields := d.decodeCompositeFields(typ.Fields)
+		enum, err := cadence.NewMeteredEnum(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return enum.WithType(typ)
+
+	case cadence.PathType:
+		return d.decodePath()
+
+	case cadence.MetaType:
+		return cadence.NewMeteredTypeValue(d.gauge, d.decodeType())
+
+	case cadence.CapabilityType:
+		d.decodeArrayHeadWithLength(3)
+		path := d.decodePath()
+		address := d.decodeAddress()
+		borrowType := d.decodeType()
+		return cadence.NewMeteredCapability(d.gauge, path, address, borrowType)
+
+	default:
+		panic(ErrInvalidCCF)
+	}
+}
+
+// decodeUntypedData decodes the data of a value which has no type.
+// Nested values are encoded together with their types.
+//
+func (d *Decoder) decodeUntypedData(kind untypedKind) cadence.Value {
+	switch kind {
+	case untypedKindArray:
+		return d.decodeArray(nil)
+
+	case untypedKindDictionary:
+		return d.decodeDictionary(cadence.DictionaryType{})
+
+	case untypedKindStruct:
+		fields := d.decodeCompositeFields(nil)
+		structure, err := cadence.NewMeteredStruct(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return structure
+
+	case untypedKindResource:
+		fields := d.decodeCompositeFields(nil)
+		resource, err := cadence.NewMeteredResource(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return resource
+
+	case untypedKindEvent:
+		fields := d.decodeCompositeFields(nil)
+		event, err := cadence.NewMeteredEvent(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return event
+
+	case untypedKindContract:
+		fields := d.decodeCompositeFields(nil)
+		contract, err := cadence.NewMeteredContract(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return contract
+
+	case untypedKindEnum:
+		fields := d.decodeCompositeFields(nil)
+		enum, err := cadence.NewMeteredEnum(
+			d.gauge,
+			len(fields),
+			func() ([]cadence.Value, error) {
+				return fields, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		return enum
+
+	case untypedKindLink:
+		d.decodeArrayHeadWithLength(2)
+		targetPath := d.decodePath()
+		borrowType := d.decodeString()
+		common.UseMemory(d.gauge, common.MemoryUsage{
+			Kind:   common.MemoryKindRawString,
+			Amount: uint64(len(borrowType)),
+		})
+		return cadence.NewMeteredLink(d.gauge, targetPath, borrowType)
+
+	default:
+		panic(ErrInvalidCCF)
+	}
+}
+
+// decodeArray decodes the elements of an array of the given type.
+// If the type is nil, the elements are encoded together with their types.
+//
+func (d *Decoder) decodeArray(typ cadence.ArrayType) cadence.Array {
+	count, isNil := d.decodeArrayHeadOrNil()
+
+	var elementType cadence.Type
+	if typ != nil {
+		elementType = typ.Element()
+
+		constantSizedArrayType, ok := typ.(cadence.ConstantSizedArrayType)
+		if ok && !isNil && count != uint64(constantSizedArrayType.Size) {
+			panic(ErrInvalidCCF)
+		}
+	}
+
+	array, err := cadence.NewMeteredArray(
+		d.gauge,
+		int(count),
+		func() ([]cadence.Value, error) {
+			if isNil {
+				return nil, nil
+			}
+
+			values := make([]cadence.Value, 0, count)
+			for i := uint64(0); i < count; i++ {
+				values = append(values, d.decodeValue(elementType))
+			}
+			return values, nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if typ == nil {
+		return array
+	}
+
+	return array.WithType(typ)
+}
+
+// decodeDictionary decodes the pairs of a dictionary of the given type.
+// If the key and element types are nil, the keys and values are encoded together with their types.
+//
+func (d *Decoder) decodeDictionary(typ cadence.DictionaryType) cadence.Dictionary {
+	count, isNil := d.decodeArrayHeadOrNil()
+	if count%2 != 0 {
+		panic(ErrInvalidCCF)
+	}
+	count /= 2
+
+	dictionary, err := cadence.NewMeteredDictionary(
+		d.gauge,
+		int(count),
+		func() ([]cadence.KeyValuePair, error) {
+			if isNil {
+				return nil, nil
+			}
+
+			pairs := make([]cadence.KeyValuePair, 0, count)
+			for i := uint64(0); i < count; i++ {
+				key := d.decodeValue(typ.KeyType)
+				value := d.decodeValue(typ.ElementType)
+				pairs = append(pairs, cadence.NewMeteredKeyValuePair(d.gauge, key, value))
+			}
+			return pairs, nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if typ.KeyType == nil && typ.ElementType == nil {
+		return dictionary
+	}
+
+	return dictionary.WithType(typ)
+}
+
+// decodeCompositeFields decodes the field values of a composite value
+// against the given field types.
+//
+func (d *Decoder) decodeCompositeFields(fieldTypes []cadence.Field) []cadence.Value {
+	count, isNil := d.decodeArrayHeadOrNil()
+	if isNil {
+		return nil
+	}
+
+	fields := make([]cadence.Value, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		var fieldType cadence.Type
+		if i < uint64(len(fieldTypes)) {
+			fieldType = fieldTypes[i].Type
+		}
+
+		fields = append(fields, d.decodeValue(fieldType))
+	}
+
+	return fields
+}
+
+func (d *Decoder) decodePath() cadence.Path {
+	d.decodeArrayHeadWithLength(2)
+	domain := d.decodeString()
+	identifier := d.decodeString()
+	return cadence.NewMeteredPath(d.gauge, domain, identifier)
+}
+
+func (d *Decoder) decodeAddress() cadence.Address {
+	b := d.decodeBytes()
+	if len(b) != cadence.AddressLength {
+		panic(ErrInvalidCCF)
+	}
+	return cadence.BytesToMeteredAddress(d.gauge, b)
+}
+
+func (d *Decoder) nextType() cbor.Type {
+	t, err := d.dec.NextType()
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// decodeArrayHeadOrNil decodes either CBOR nil, or the head of an array.
+// It returns true if nil was decoded.
+//
+func (d *Decoder) decodeArrayHeadOrNil() (count uint64, isNil bool) {
+	if d.nextType() == cbor.NilType {
+		d.decodeNil()
+		return 0, true
+	}
+	return d.decodeArrayHead(), false
+}
+
+func (d *Decoder) decodeArrayHead() uint64 {
+	count, err := d.dec.DecodeArrayHead()
+	if err != nil {
+		panic(err)
+	}
+	return count
+}
+
+func (d *Decoder) decodeArrayHeadWithLength(expected uint64) {
+	if d.decodeArrayHead() != expected {
+		panic(ErrInvalidCCF)
+	}
+}
+
+func (d *Decoder) decodeTagNumber() uint64 {
+	tag, err := d.dec.DecodeTagNumber()
+	if err != nil {
+		panic(err)
+	}
+	return tag
+}
+
+func (d *Decoder) decodeNil() {
+	err := d.dec.DecodeNil()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (d *Decoder) decodeBool() bool {
+	b, err := d.dec.DecodeBool()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (d *Decoder) decodeString() string {
+	s, err := d.dec.DecodeString()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (d *Decoder) decodeBytes() []byte {
+	b, err := d.dec.DecodeBytes()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (d *Decoder) decodeInt64() int64 {
+	i, err := d.dec.DecodeInt64()
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func (d *Decoder) decodeInt64InRange(min, max int64) int64 {
+	i := d.decodeInt64()
+	if i < min || i > max {
+		panic(ErrInvalidCCF)
+	}
+	return i
+}
+
+func (d *Decoder) decodeUint64() uint64 {
+	i, err := d.dec.DecodeUint64()
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func (d *Decoder) decodeUint64InRange(max uint64) uint64 {
+	i := d.decodeUint64()
+	if i > max {
+		panic(ErrInvalidCCF)
+	}
+	return i
+}
+
+func (d *Decoder) decodeBigInt() *big.Int {
+	if d.nextType() != cbor.BigNumType {
+		panic(ErrInvalidCCF)
+	}
+	i, err := d.dec.DecodeBigInt()
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
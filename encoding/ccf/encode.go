@@ -0,0 +1,965 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	goRuntime "runtime"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// An Encoder converts Cadence values into CCF-encoded bytes.
+type Encoder struct {
+	enc *cbor.StreamEncoder
+	// typeDefs are the composite and interface types of the encoded value,
+	// in the order of their definition
+	typeDefs []cadence.Type
+	// typeDefIndices maps composite and interface types to the index of their definition.
+	// Distinct, but equal types share the same definition
+	typeDefIndices map[cadence.Type]int
+}
+
+// Encode returns the CCF-encoded representation of the given value.
+//
+// This function returns an error if the Cadence value cannot be represented in CCF.
+func Encode(value cadence.Value) ([]byte, error) {
+	var w bytes.Buffer
+	enc := NewEncoder(&w)
+
+	err := enc.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Bytes(), nil
+}
+
+// MustEncode returns the CCF-encoded representation of the given value, or panics
+// if the value cannot be represented in CCF.
+func MustEncode(value cadence.Value) []byte {
+	b, err := Encode(value)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NewEncoder initializes an Encoder that will write CCF-encoded bytes to the
+// given io.Writer.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		enc: CBOREncMode.NewStreamEncoder(w),
+	}
+}
+
+// Encode writes the CCF-encoded representation of the given value to this
+// encoder's io.Writer.
+//
+// This function returns an error if the given value's type is not supported
+// by this encoder.
+func (e *Encoder) Encode(value cadence.Value) (err error) {
+	// capture panics that occur during encoding
+	defer func() {
+		if r := recover(); r != nil {
+			// don't recover Go errors
+			goErr, ok := r.(goRuntime.Error)
+			if ok {
+				panic(goErr)
+			}
+
+			panicErr, isError := r.(error)
+			if !isError {
+				panic(r)
+			}
+
+			err = fmt.Errorf("failed to encode value: %w", panicErr)
+		}
+	}()
+
+	e.typeDefs = nil
+	e.typeDefIndices = map[cadence.Type]int{}
+
+	// Collect the definitions of all composite and interface types first,
+	// so they can be encoded before the value, and referenced by index
+
+	e.registerValueTypes(value)
+
+	e.encodeArrayHead(4)
+
+	e.encodeTypeDefHeaders()
+	e.encodeTypeDefBodies()
+
+	e.encodeValueType(value)
+	e.encodeData(value, valueType(value))
+
+	return e.enc.Flush()
+}
+
+// valueType returns the type of the given value,
+// or nil if the value has no type, e.g. an array created without a type.
+//
+func valueType(value cadence.Value) cadence.Type {
+	switch value := value.(type) {
+	case cadence.Optional:
+		if value.Value != nil {
+			return cadence.NewOptionalType(valueType(value.Value))
+		}
+	case cadence.Struct:
+		if value.StructType == nil {
+			return nil
+		}
+	case cadence.Resource:
+		if value.ResourceType == nil {
+			return nil
+		}
+	case cadence.Event:
+		if value.EventType == nil {
+			return nil
+		}
+	case cadence.Contract:
+		if value.ContractType == nil {
+			return nil
+		}
+	case cadence.Enum:
+		if value.EnumType == nil {
+			return nil
+		}
+	}
+
+	return value.Type()
+}
+
+// registerValueTypes registers the composite and interface types
+// of the given value and its nested values.
+//
+func (e *Encoder) registerValueTypes(value cadence.Value) {
+	switch value := value.(type) {
+	case cadence.Optional:
+		if value.Value != nil {
+			e.registerValueTypes(value.Value)
+		}
+
+	case cadence.Array:
+		e.registerType(valueType(value))
+		for _, element := range value.Values {
+			e.registerValueTypes(element)
+		}
+
+	case cadence.Dictionary:
+		e.registerType(valueType(value))
+		for _, pair := range value.Pairs {
+			e.registerValueTypes(pair.Key)
+			e.registerValueTypes(pair.Value)
+		}
+
+	case cadence.Struct:
+		e.registerType(valueType(value))
+		e.registerValuesTypes(value.Fields)
+
+	case cadence.Resource:
+		e.registerType(valueType(value))
+		e.registerValuesTypes(value.Fields)
+
+	case cadence.Event:
+		e.registerType(valueType(value))
+		e.registerValuesTypes(value.Fields)
+
+	case cadence.Contract:
+		e.registerType(valueType(value))
+		e.registerValuesTypes(value.Fields)
+
+	case cadence.Enum:
+		e.registerType(valueType(value))
+		e.registerValuesTypes(value.Fields)
+
+	case cadence.TypeValue:
+		e.registerType(value.StaticType)
+
+	case cadence.Capability:
+		e.registerType(value.BorrowType)
+	}
+}
+
+func (e *Encoder) registerValuesTypes(values []cadence.Value) {
+	for _, value := range values {
+		e.registerValueTypes(value)
+	}
+}
+
+// registerType registers the given type, if it is a composite or interface type,
+// and the composite and interface types it refers to.
+//
+func (e *Encoder) registerType(typ cadence.Type) {
+	switch typ := typ.(type) {
+	case nil:
+		return
+
+	case cadence.CompositeType, cadence.InterfaceType:
+		e.registerTypeDef(typ)
+
+	case cadence.OptionalType:
+		e.registerType(typ.Type)
+
+	case cadence.VariableSizedArrayType:
+		e.registerType(typ.ElementType)
+
+	case cadence.ConstantSizedArrayType:
+		e.registerType(typ.ElementType)
+
+	case cadence.DictionaryType:
+		e.registerType(typ.KeyType)
+		e.registerType(typ.ElementType)
+
+	case cadence.ReferenceType:
+		e.registerType(typ.Type)
+
+	case *cadence.RestrictedType:
+		e.registerType(typ.Type)
+		for _, restriction := range typ.Restrictions {
+			e.registerType(restriction)
+		}
+
+	case cadence.CapabilityType:
+		e.registerType(typ.BorrowType)
+
+	case *cadence.FunctionType:
+		e.registerParametersTypes(typ.Parameters)
+		e.registerType(typ.ReturnType)
+	}
+}
+
+func (e *Encoder) registerTypeDef(typ cadence.Type) {
+	if _, ok := e.typeDefIndices[typ]; ok {
+		return
+	}
+
+	// Reuse the definition of an equal type, if any
+
+	for index, typeDef := range e.typeDefs {
+		if reflect.TypeOf(typeDef) == reflect.TypeOf(typ) &&
+			typeDef.ID() == typ.ID() &&
+			reflect.DeepEqual(typeDef, typ) {
+
+			e.typeDefIndices[typ] = index
+			return
+		}
+	}
+
+	e.typeDefIndices[typ] = len(e.typeDefs)
+	e.typeDefs = append(e.typeDefs, typ)
+
+	var fields []cadence.Field
+	var initializers [][]cadence.Parameter
+
+	switch typ := typ.(type) {
+	case *cadence.EventType:
+		fields = typ.Fields
+		e.registerParametersTypes(typ.Initializer)
+
+	case *cadence.EnumType:
+		fields = typ.Fields
+		initializers = typ.Initializers
+		e.registerType(typ.RawType)
+
+	case cadence.CompositeType:
+		fields = typ.CompositeFields()
+		initializers = typ.CompositeInitializers()
+
+	case cadence.InterfaceType:
+		fields = typ.InterfaceFields()
+		initializers = typ.InterfaceInitializers()
+	}
+
+	for _, field := range fields {
+		e.registerType(field.Type)
+	}
+
+	for _, parameters := range initializers {
+		e.registerParametersTypes(parameters)
+	}
+}
+
+func (e *Encoder) registerParametersTypes(parameters []cadence.Parameter) {
+	for _, parameter := range parameters {
+		e.registerType(parameter.Type)
+	}
+}
+
+func (e *Encoder) typeDefIndex(typ cadence.Type) int {
+	index, ok := e.typeDefIndices[typ]
+	if !ok {
+		panic(errors.NewUnexpectedError("missing type definition: %s", typ.ID()))
+	}
+	return index
+}
+
+// encodeTypeDefHeaders encodes the kinds and IDs of all type definitions:
+//
+//	[ tag(<kind>) <type ID> ... ]
+//
+func (e *Encoder) encodeTypeDefHeaders() {
+	e.encodeArrayHead(uint64(len(e.typeDefs)))
+
+	for _, typ := range e.typeDefs {
+		var tag uint64
+		var location common.Location
+		var qualifiedIdentifier string
+
+		switch typ := typ.(type) {
+		case *cadence.StructType:
+			tag = CBORTagStructType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.ResourceType:
+			tag = CBORTagResourceType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.EventType:
+			tag = CBORTagEventType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.ContractType:
+			tag = CBORTagContractType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.EnumType:
+			tag = CBORTagEnumType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.StructInterfaceType:
+			tag = CBORTagStructInterfaceType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.ResourceInterfaceType:
+			tag = CBORTagResourceInterfaceType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		case *cadence.ContractInterfaceType:
+			tag = CBORTagContractInterfaceType
+			location, qualifiedIdentifier = typ.Location, typ.QualifiedIdentifier
+		default:
+			panic(fmt.Errorf("unsupported type: %T, %v", typ, typ))
+		}
+
+		e.encodeTagHead(tag)
+		e.encodeString(typeID(location, qualifiedIdentifier))
+	}
+}
+
+// encodeTypeDefBodies encodes the fields and initializers of all type definitions:
+//
+//	[ [ <fields>, <initializers> ] ... ]
+//
+// Event types have a single initializer, i.e. [ <fields>, <parameters> ],
+// and enum types also have a raw type, i.e. [ <fields>, <initializers>, <raw type> ].
+//
+func (e *Encoder) encodeTypeDefBodies() {
+	e.encodeArrayHead(uint64(len(e.typeDefs)))
+
+	for _, typ := range e.typeDefs {
+		switch typ := typ.(type) {
+		case *cadence.EventType:
+			e.encodeArrayHead(2)
+			e.encodeFields(typ.Fields)
+			e.encodeParameters(typ.Initializer)
+
+		case *cadence.EnumType:
+			e.encodeArrayHead(3)
+			e.encodeFields(typ.Fields)
+			e.encodeInitializers(typ.Initializers)
+			e.encodeType(typ.RawType)
+
+		case cadence.CompositeType:
+			e.encodeArrayHead(2)
+			e.encodeFields(typ.CompositeFields())
+			e.encodeInitializers(typ.CompositeInitializers())
+
+		case cadence.InterfaceType:
+			e.encodeArrayHead(2)
+			e.encodeFields(typ.InterfaceFields())
+			e.encodeInitializers(typ.InterfaceInitializers())
+
+		default:
+			panic(errors.NewUnreachableError())
+		}
+	}
+}
+
+// encodeFields encodes the given fields as
+//
+//	[ [ <identifier>, <type> ] ... ]
+//
+func (e *Encoder) encodeFields(fields []cadence.Field) {
+	if e.encodeArrayHeadOrNil(fields == nil, len(fields)) {
+		return
+	}
+
+	for _, field := range fields {
+		e.encodeArrayHead(2)
+		e.encodeString(field.Identifier)
+		e.encodeType(field.Type)
+	}
+}
+
+// encodeParameters encodes the given parameters as
+//
+//	[ [ <label>, <identifier>, <type> ] ... ]
+//
+func (e *Encoder) encodeParameters(parameters []cadence.Parameter) {
+	if e.encodeArrayHeadOrNil(parameters == nil, len(parameters)) {
+		return
+	}
+
+	for _, parameter := range parameters {
+		e.encodeArrayHead(3)
+		e.encodeString(parameter.Label)
+		e.encodeString(parameter.Identifier)
+		e.encodeType(parameter.Type)
+	}
+}
+
+func (e *Encoder) encodeInitializers(initializers [][]cadence.Parameter) {
+	if e.encodeArrayHeadOrNil(initializers == nil, len(initializers)) {
+		return
+	}
+
+	for _, parameters := range initializers {
+		e.encodeParameters(parameters)
+	}
+}
+
+// encodeValueType encodes the type of the given value.
+// Values without a type are encoded as tag(CBORTagUntypedType) <untyped kind>.
+//
+func (e *Encoder) encodeValueType(value cadence.Value) {
+	typ := valueType(value)
+	if typ != nil {
+		e.encodeType(typ)
+		return
+	}
+
+	var kind untypedKind
+
+	switch value.(type) {
+	case cadence.Array:
+		kind = untypedKindArray
+	case cadence.Dictionary:
+		kind = untypedKindDictionary
+	case cadence.Struct:
+		kind = untypedKindStruct
+	case cadence.Resource:
+		kind = untypedKindResource
+	case cadence.Event:
+		kind = untypedKindEvent
+	case cadence.Contract:
+		kind = untypedKindContract
+	case cadence.Enum:
+		kind = untypedKindEnum
+	case cadence.Link:
+		kind = untypedKindLink
+	default:
+		panic(fmt.Errorf("unsupported value: %T, %v", value, value))
+	}
+
+	e.encodeTagHead(CBORTagUntypedType)
+	e.encodeUint64(uint64(kind))
+}
+
+// encodeType encodes the given type inline.
+// Composite and interface types are encoded as references to their definition.
+//
+func (e *Encoder) encodeType(typ cadence.Type) {
+	switch typ := typ.(type) {
+	case nil:
+		e.encodeNil()
+
+	case cadence.CompositeType, cadence.InterfaceType:
+		e.encodeTagHead(CBORTagTypeRef)
+		e.encodeUint64(uint64(e.typeDefIndex(typ)))
+
+	case cadence.TypeID:
+		e.encodeTagHead(CBORTagTypeID)
+		e.encodeString(string(typ))
+
+	case cadence.OptionalType:
+		e.encodeTagHead(CBORTagOptionalType)
+		e.encodeType(typ.Type)
+
+	case cadence.VariableSizedArrayType:
+		e.encodeTagHead(CBORTagVariableSizedArrayType)
+		e.encodeType(typ.ElementType)
+
+	case cadence.ConstantSizedArrayType:
+		e.encodeTagHead(CBORTagConstantSizedArrayType)
+		e.encodeArrayHead(2)
+		e.encodeUint64(uint64(typ.Size))
+		e.encodeType(typ.ElementType)
+
+	case cadence.DictionaryType:
+		e.encodeTagHead(CBORTagDictionaryType)
+		e.encodeArrayHead(2)
+		e.encodeType(typ.KeyType)
+		e.encodeType(typ.ElementType)
+
+	case cadence.ReferenceType:
+		e.encodeTagHead(CBORTagReferenceType)
+		e.encodeArrayHead(2)
+		e.encodeBool(typ.Authorized)
+		e.encodeType(typ.Type)
+
+	case *cadence.RestrictedType:
+		e.encodeTagHead(CBORTagRestrictedType)
+		e.encodeArrayHead(3)
+		e.encodeString(typ.ID())
+		e.encodeType(typ.Type)
+		if !e.encodeArrayHeadOrNil(typ.Restrictions == nil, len(typ.Restrictions)) {
+			for _, restriction := range typ.Restrictions {
+				e.encodeType(restriction)
+			}
+		}
+
+	case cadence.CapabilityType:
+		e.encodeTagHead(CBORTagCapabilityType)
+		e.encodeType(typ.BorrowType)
+
+	case *cadence.FunctionType:
+		e.encodeTagHead(CBORTagFunctionType)
+		e.encodeArrayHead(3)
+		e.encodeString(typ.ID())
+		e.encodeParameters(typ.Parameters)
+		e.encodeType(typ.ReturnType)
+
+	default:
+		code, ok := simpleTypeCodeOf(typ)
+		if !ok {
+			panic(fmt.Errorf("unsupported type: %T, %v", typ, typ))
+		}
+		e.encodeTagHead(CBORTagSimpleType)
+		e.encodeUint64(uint64(code))
+	}
+}
+
+// encodeValue encodes the given value against the given static type.
+//
+// If the value's type is not the static type,
+// e.g. the value is an element of an array of type `[AnyStruct]`,
+// the value is encoded together with its type:
+//
+//	tag(CBORTagTypeAndValue) [ <type>, <value> ]
+//
+func (e *Encoder) encodeValue(value cadence.Value, staticType cadence.Type) {
+	if !e.hasStaticType(value, staticType) {
+		e.encodeTagHead(CBORTagTypeAndValue)
+		e.encodeArrayHead(2)
+		e.encodeValueType(value)
+		staticType = valueType(value)
+	}
+
+	e.encodeData(value, staticType)
+}
+
+// hasStaticType returns true if the given value can be encoded
+// without its type, because its type is the given static type.
+//
+func (e *Encoder) hasStaticType(value cadence.Value, staticType cadence.Type) bool {
+	if staticType == nil {
+		return false
+	}
+
+	if _, ok := value.(cadence.Optional); ok {
+		_, ok := staticType.(cadence.OptionalType)
+		return ok
+	}
+
+	typ := valueType(value)
+	if typ == nil {
+		return false
+	}
+
+	return e.typesEqual(typ, staticType)
+}
+
+func (e *Encoder) typesEqual(a, b cadence.Type) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+
+	switch a := a.(type) {
+	case cadence.CompositeType, cadence.InterfaceType:
+		return e.typeDefIndex(a) == e.typeDefIndex(b)
+
+	case cadence.TypeID:
+		return a == b.(cadence.TypeID)
+
+	case cadence.OptionalType:
+		return e.typesEqual(a.Type, b.(cadence.OptionalType).Type)
+
+	case cadence.VariableSizedArrayType:
+		return e.typesEqual(a.ElementType, b.(cadence.VariableSizedArrayType).ElementType)
+
+	case cadence.ConstantSizedArrayType:
+		other := b.(cadence.ConstantSizedArrayType)
+		return a.Size == other.Size &&
+			e.typesEqual(a.ElementType, other.ElementType)
+
+	case cadence.DictionaryType:
+		other := b.(cadence.DictionaryType)
+		return e.typesEqual(a.KeyType, other.KeyType) &&
+			e.typesEqual(a.ElementType, other.ElementType)
+
+	case cadence.ReferenceType:
+		other := b.(cadence.ReferenceType)
+		return a.Authorized == other.Authorized &&
+			e.typesEqual(a.Type, other.Type)
+
+	case cadence.CapabilityType:
+		return e.typesEqual(a.BorrowType, b.(cadence.CapabilityType).BorrowType)
+
+	case *cadence.RestrictedType:
+		other := b.(*cadence.RestrictedType)
+		if a.ID() != other.ID() ||
+			!e.typesEqual(a.Type, other.Type) ||
+			len(a.Restrictions) != len(other.Restrictions) ||
+			(a.Restrictions == nil) != (other.Restrictions == nil) {
+
+			return false
+		}
+		for i, restriction := range a.Restrictions {
+			if !e.typesEqual(restriction, other.Restrictions[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *cadence.FunctionType:
+		other := b.(*cadence.FunctionType)
+		if a.ID() != other.ID() ||
+			!e.typesEqual(a.ReturnType, other.ReturnType) ||
+			len(a.Parameters) != len(other.Parameters) ||
+			(a.Parameters == nil) != (other.Parameters == nil) {
+
+			return false
+		}
+		for i, parameter := range a.Parameters {
+			otherParameter := other.Parameters[i]
+			if parameter.Label != otherParameter.Label ||
+				parameter.Identifier != otherParameter.Identifier ||
+				!e.typesEqual(parameter.Type, otherParameter.Type) {
+
+				return false
+			}
+		}
+		return true
+	}
+
+	// Simple types
+	return true
+}
+
+// encodeData encodes the given value without its type.
+// The given static type is the type of the value, or nil if the value has no type.
+//
+func (e *Encoder) encodeData(value cadence.Value, staticType cadence.Type) {
+	switch value := value.(type) {
+	case cadence.Void:
+		e.encodeNil()
+
+	case cadence.Optional:
+		if value.Value == nil {
+			e.encodeNil()
+			return
+		}
+
+		var innerType cadence.Type
+		if optionalType, ok := staticType.(cadence.OptionalType); ok {
+			innerType = optionalType.Type
+		}
+
+		e.encodeTagHead(CBORTagSomeValue)
+		e.encodeValue(value.Value, innerType)
+
+	case cadence.Bool:
+		e.encodeBool(bool(value))
+
+	case cadence.String:
+		e.encodeString(string(value))
+
+	case cadence.Character:
+		e.encodeString(string(value))
+
+	case cadence.Address:
+		e.encodeBytes(value.Bytes())
+
+	case cadence.Int:
+		e.encodeBigInt(value.Value)
+
+	case cadence.Int8:
+		e.encodeInt64(int64(value))
+
+	case cadence.Int16:
+		e.encodeInt64(int64(value))
+
+	case cadence.Int32:
+		e.encodeInt64(int64(value))
+
+	case cadence.Int64:
+		e.encodeInt64(int64(value))
+
+	case cadence.Int128:
+		e.encodeBigInt(value.Value)
+
+	case cadence.Int256:
+		e.encodeBigInt(value.Value)
+
+	case cadence.UInt:
+		e.encodeBigInt(value.Value)
+
+	case cadence.UInt8:
+		e.encodeUint64(uint64(value))
+
+	case cadence.UInt16:
+		e.encodeUint64(uint64(value))
+
+	case cadence.UInt32:
+		e.encodeUint64(uint64(value))
+
+	case cadence.UInt64:
+		e.encodeUint64(uint64(value))
+
+	case cadence.UInt128:
+		e.encodeBigInt(value.Value)
+
+	case cadence.UInt256:
+		e.encodeBigInt(value.Value)
+
+	case cadence.Word8:
+		e.encodeUint64(uint64(value))
+
+	case cadence.Word16:
+		e.encodeUint64(uint64(value))
+
+	case cadence.Word32:
+		e.encodeUint64(uint64(value))
+
+	case cadence.Word64:
+		e.encodeUint64(uint64(value))
+
+	case cadence.Fix64:
+		e.encodeInt64(int64(value))
+
+	case cadence.UFix64:
+		e.encodeUint64(uint64(value))
+
+	case cadence.Array:
+		var elementType cadence.Type
+		if arrayType, ok := staticType.(cadence.ArrayType); ok {
+			elementType = arrayType.Element()
+		}
+
+		if e.encodeArrayHeadOrNil(value.Values == nil, len(value.Values)) {
+			return
+		}
+
+		for _, element := range value.Values {
+			e.encodeValue(element, elementType)
+		}
+
+	case cadence.Dictionary:
+		var keyType, elementType cadence.Type
+		if dictionaryType, ok := staticType.(cadence.DictionaryType); ok {
+			keyType = dictionaryType.KeyType
+			elementType = dictionaryType.ElementType
+		}
+
+		// Pairs are encoded as a flat array of alternating keys and values
+
+		if e.encodeArrayHeadOrNil(value.Pairs == nil, len(value.Pairs)*2) {
+			return
+		}
+
+		for _, pair := range value.Pairs {
+			e.encodeValue(pair.Key, keyType)
+			e.encodeValue(pair.Value, elementType)
+		}
+
+	case cadence.Struct:
+		e.encodeComposite(value.Fields, staticType)
+
+	case cadence.Resource:
+		e.encodeComposite(value.Fields, staticType)
+
+	case cadence.Event:
+		e.encodeComposite(value.Fields, staticType)
+
+	case cadence.Contract:
+		e.encodeComposite(value.Fields, staticType)
+
+	case cadence.Enum:
+		e.encodeComposite(value.Fields, staticType)
+
+	case cadence.Path:
+		e.encodePath(value)
+
+	case cadence.Link:
+		e.encodeArrayHead(2)
+		e.encodePath(value.TargetPath)
+		e.encodeString(value.BorrowType)
+
+	case cadence.TypeValue:
+		e.encodeType(value.StaticType)
+
+	case cadence.Capability:
+		e.encodeArrayHead(3)
+		e.encodePath(value.Path)
+		e.encodeBytes(value.Address.Bytes())
+		e.encodeType(value.BorrowType)
+
+	default:
+		panic(fmt.Errorf("unsupported value: %T, %v", value, value))
+	}
+}
+
+// encodeComposite encodes the given fields of a composite value as
+//
+//	[ <field value> ... ]
+//
+// The fields are encoded against the field types of the given composite type, if any.
+//
+func (e *Encoder) encodeComposite(fields []cadence.Value, staticType cadence.Type) {
+	var fieldTypes []cadence.Field
+	if compositeType, ok := staticType.(cadence.CompositeType); ok {
+		fieldTypes = compositeType.CompositeFields()
+	}
+
+	if e.encodeArrayHeadOrNil(fields == nil, len(fields)) {
+		return
+	}
+
+	for i, field := range fields {
+		var fieldType cadence.Type
+		if i < len(fieldTypes) {
+			fieldType = fieldTypes[i].Type
+		}
+
+		e.encodeValue(field, fieldType)
+	}
+}
+
+// encodePath encodes the given path as
+//
+//	[ <domain>, <identifier> ]
+//
+func (e *Encoder) encodePath(path cadence.Path) {
+	e.encodeArrayHead(2)
+	e.encodeString(path.Domain)
+	e.encodeString(path.Identifier)
+}
+
+// encodeArrayHeadOrNil encodes CBOR nil if isNil is true,
+// and the head of an array of the given length otherwise.
+// It returns true if nil was encoded.
+//
+func (e *Encoder) encodeArrayHeadOrNil(isNil bool, length int) bool {
+	if isNil {
+		e.encodeNil()
+		return true
+	}
+	e.encodeArrayHead(uint64(length))
+	return false
+}
+
+func (e *Encoder) encodeArrayHead(length uint64) {
+	err := e.enc.EncodeArrayHead(length)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeTagHead(tag uint64) {
+	err := e.enc.EncodeTagHead(tag)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeNil() {
+	err := e.enc.EncodeNil()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeBool(b bool) {
+	err := e.enc.EncodeBool(b)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeString(s string) {
+	err := e.enc.EncodeString(s)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeBytes(b []byte) {
+	err := e.enc.EncodeBytes(b)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeInt64(i int64) {
+	err := e.enc.EncodeInt64(i)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeUint64(i uint64) {
+	err := e.enc.EncodeUint64(i)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (e *Encoder) encodeBigInt(i *big.Int) {
+	if i == nil {
+		panic(fmt.Errorf("unsupported value: nil integer"))
+	}
+	err := e.enc.EncodeBigInt(i)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func typeID(location common.Location, identifier string) string {
+	if location == nil {
+		return identifier
+	}
+
+	return string(location.TypeID(nil, identifier))
+}
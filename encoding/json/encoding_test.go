@@ -33,6 +33,7 @@ import (
 	"github.com/onflow/cadence/runtime/tests/checker"
 
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
 	"github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime/sema"
 	"github.com/onflow/cadence/runtime/tests/utils"
@@ -1976,6 +1977,23 @@ func testEncodeAndDecode(t *testing.T, val cadence.Value, expectedJSON string) {
 	testDecode(t, actualJSON, val)
 }
 
+// testCCFEncodeAndDecode tests that the value decoded from the given JSON
+// can be encoded to CCF and decoded back to the same value.
+// All encoding test cases are also CCF test cases this way
+//
+func testCCFEncodeAndDecode(t *testing.T, actualJSON string) {
+	decodedVal, err := json.Decode(nil, []byte(actualJSON))
+	require.NoError(t, err)
+
+	ccfEncoded, err := ccf.Encode(decodedVal)
+	require.NoError(t, err)
+
+	ccfDecoded, err := ccf.Decode(nil, ccfEncoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, decodedVal, ccfDecoded)
+}
+
 func testEncode(t *testing.T, val cadence.Value, expectedJSON string) (actualJSON string) {
 	actualJSONBytes, err := json.Encode(val)
 	require.NoError(t, err)
@@ -1984,6 +2002,8 @@ func testEncode(t *testing.T, val cadence.Value, expectedJSON string) (actualJSO
 
 	assert.JSONEq(t, expectedJSON, actualJSON, fmt.Sprintf("actual: %s", actualJSON))
 
+	testCCFEncodeAndDecode(t, actualJSON)
+
 	return actualJSON
 }
 
@@ -52,6 +52,25 @@ func ProtocolToSemaPosition(pos protocol.Position) sema.Position {
 	}
 }
 
+// SemaToProtocolPosition converts a sema position to a LSP position
+//
+func SemaToProtocolPosition(pos sema.Position) protocol.Position {
+	return protocol.Position{
+		Line:      uint32(pos.Line - 1),
+		Character: uint32(pos.Column),
+	}
+}
+
+// SemaToProtocolRange converts a sema range, e.g. the range of an occurrence, to a LSP range
+//
+func SemaToProtocolRange(startPos, endPos sema.Position) protocol.Range {
+	endPos.Column += 1
+	return protocol.Range{
+		Start: SemaToProtocolPosition(startPos),
+		End:   SemaToProtocolPosition(endPos),
+	}
+}
+
 func DeclarationKindToSymbolKind(kind common.DeclarationKind) protocol.SymbolKind {
 
 	switch kind {
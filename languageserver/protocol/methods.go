@@ -94,6 +94,51 @@ func (s *Server) handleRename(req *json.RawMessage) (any, error) {
 	return s.Handler.Rename(s.conn, &params)
 }
 
+func (s *Server) handleReferences(req *json.RawMessage) (any, error) {
+	var params ReferenceParams
+	if err := json.Unmarshal(*req, &params); err != nil {
+		return nil, err
+	}
+
+	return s.Handler.References(s.conn, &params)
+}
+
+func (s *Server) handleDocumentFormatting(req *json.RawMessage) (any, error) {
+	var params DocumentFormattingParams
+	if err := json.Unmarshal(*req, &params); err != nil {
+		return nil, err
+	}
+
+	return s.Handler.DocumentFormatting(s.conn, &params)
+}
+
+func (s *Server) handleDocumentRangeFormatting(req *json.RawMessage) (any, error) {
+	var params DocumentRangeFormattingParams
+	if err := json.Unmarshal(*req, &params); err != nil {
+		return nil, err
+	}
+
+	return s.Handler.DocumentRangeFormatting(s.conn, &params)
+}
+
+func (s *Server) handleSemanticTokensFull(req *json.RawMessage) (any, error) {
+	var params SemanticTokensParams
+	if err := json.Unmarshal(*req, &params); err != nil {
+		return nil, err
+	}
+
+	return s.Handler.SemanticTokensFull(s.conn, &params)
+}
+
+func (s *Server) handleSemanticTokensRange(req *json.RawMessage) (any, error) {
+	var params SemanticTokensRangeParams
+	if err := json.Unmarshal(*req, &params); err != nil {
+		return nil, err
+	}
+
+	return s.Handler.SemanticTokensRange(s.conn, &params)
+}
+
 func (s *Server) handleCodeAction(req *json.RawMessage) (any, error) {
 	var params CodeActionParams
 	if err := json.Unmarshal(*req, &params); err != nil {
@@ -86,6 +86,11 @@ type Handler interface {
 	SignatureHelp(conn Conn, params *TextDocumentPositionParams) (*SignatureHelp, error)
 	DocumentHighlight(conn Conn, params *TextDocumentPositionParams) ([]*DocumentHighlight, error)
 	Rename(conn Conn, params *RenameParams) (*WorkspaceEdit, error)
+	References(conn Conn, params *ReferenceParams) ([]*Location, error)
+	DocumentFormatting(conn Conn, params *DocumentFormattingParams) ([]*TextEdit, error)
+	DocumentRangeFormatting(conn Conn, params *DocumentRangeFormattingParams) ([]*TextEdit, error)
+	SemanticTokensFull(conn Conn, params *SemanticTokensParams) (*SemanticTokens, error)
+	SemanticTokensRange(conn Conn, params *SemanticTokensRangeParams) (*SemanticTokens, error)
 	CodeAction(conn Conn, params *CodeActionParams) ([]*CodeAction, error)
 	CodeLens(conn Conn, params *CodeLensParams) ([]*CodeLens, error)
 	Completion(conn Conn, params *CompletionParams) ([]*CompletionItem, error)
@@ -138,6 +143,21 @@ func NewServer(handler Handler) *Server {
 	jsonrpc2Server.Methods["textDocument/rename"] =
 		server.handleRename
 
+	jsonrpc2Server.Methods["textDocument/references"] =
+		server.handleReferences
+
+	jsonrpc2Server.Methods["textDocument/formatting"] =
+		server.handleDocumentFormatting
+
+	jsonrpc2Server.Methods["textDocument/rangeFormatting"] =
+		server.handleDocumentRangeFormatting
+
+	jsonrpc2Server.Methods["textDocument/semanticTokens/full"] =
+		server.handleSemanticTokensFull
+
+	jsonrpc2Server.Methods["textDocument/semanticTokens/range"] =
+		server.handleSemanticTokensRange
+
 	jsonrpc2Server.Methods["textDocument/codeAction"] =
 		server.handleCodeAction
 
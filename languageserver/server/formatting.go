@@ -0,0 +1,163 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"strings"
+
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/tools/formatter"
+
+	"github.com/onflow/cadence/languageserver/conversion"
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+// DocumentFormatting formats the whole document.
+//
+// Documents with syntax errors are not formatted,
+// the errors are already reported as diagnostics.
+func (s *Server) DocumentFormatting(
+	_ protocol.Conn,
+	params *protocol.DocumentFormattingParams,
+) (
+	[]*protocol.TextEdit,
+	error,
+) {
+	// NOTE: Always initialize to an empty slice, i.e DON'T use nil:
+	// The later will be ignored instead of being treated as no items
+	textEdits := []*protocol.TextEdit{}
+
+	document, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return textEdits, nil
+	}
+
+	formatted, err := formatter.Format(document.Text, formatterConfig(params.Options))
+	if err != nil {
+		if _, ok := err.(parser.Error); ok {
+			return textEdits, nil
+		}
+		return nil, err
+	}
+
+	if formatted == document.Text {
+		return textEdits, nil
+	}
+
+	textEdits = append(
+		textEdits,
+		&protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{},
+				End:   documentEndPosition(document.Text),
+			},
+			NewText: formatted,
+		},
+	)
+
+	return textEdits, nil
+}
+
+// DocumentRangeFormatting formats the declarations and statements of the document
+// which overlap with the given range.
+//
+// Documents with syntax errors are not formatted,
+// the errors are already reported as diagnostics.
+func (s *Server) DocumentRangeFormatting(
+	_ protocol.Conn,
+	params *protocol.DocumentRangeFormattingParams,
+) (
+	[]*protocol.TextEdit,
+	error,
+) {
+	// NOTE: Always initialize to an empty slice, i.e DON'T use nil:
+	// The later will be ignored instead of being treated as no items
+	textEdits := []*protocol.TextEdit{}
+
+	document, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return textEdits, nil
+	}
+
+	startOffset := document.Offset(
+		int(params.Range.Start.Line+1),
+		int(params.Range.Start.Character),
+	)
+	endOffset := document.Offset(
+		int(params.Range.End.Line+1),
+		int(params.Range.End.Character),
+	)
+	if startOffset < 0 || endOffset < 0 {
+		return textEdits, nil
+	}
+
+	edit, err := formatter.FormatRange(
+		document.Text,
+		startOffset,
+		endOffset,
+		formatterConfig(params.Options),
+	)
+	if err != nil {
+		if _, ok := err.(parser.Error); ok {
+			return textEdits, nil
+		}
+		return nil, err
+	}
+
+	if edit == nil {
+		return textEdits, nil
+	}
+
+	textEdits = append(
+		textEdits,
+		&protocol.TextEdit{
+			Range: protocol.Range{
+				Start: conversion.ASTToProtocolPosition(edit.StartPos),
+				End:   conversion.ASTToProtocolPosition(edit.EndPos),
+			},
+			NewText: edit.NewText,
+		},
+	)
+
+	return textEdits, nil
+}
+
+func formatterConfig(options protocol.FormattingOptions) formatter.Config {
+	var indent string
+	if options.InsertSpaces {
+		indent = strings.Repeat(" ", int(options.TabSize))
+	} else {
+		indent = "\t"
+	}
+
+	return formatter.Config{
+		Indent: indent,
+	}
+}
+
+// documentEndPosition returns the position after the last character of the given text
+func documentEndPosition(text string) protocol.Position {
+	line := strings.Count(text, "\n")
+	lastLineStart := strings.LastIndexByte(text, '\n') + 1
+
+	return protocol.Position{
+		Line:      uint32(line),
+		Character: uint32(len(text) - lastLineStart),
+	}
+}
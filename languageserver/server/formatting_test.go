@@ -0,0 +1,158 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+func TestDocumentFormatting(t *testing.T) {
+
+	t.Parallel()
+
+	const uri = protocol.DocumentURI("file:///test.cdc")
+
+	options := protocol.FormattingOptions{
+		TabSize:      4,
+		InsertSpaces: true,
+	}
+
+	t.Run("unformatted", func(t *testing.T) {
+
+		t.Parallel()
+
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		server.documents[uri] = Document{
+			Text: "// test\nfun test() { return }",
+		}
+
+		textEdits, err := server.DocumentFormatting(
+			nil,
+			&protocol.DocumentFormattingParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Options:      options,
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]*protocol.TextEdit{
+				{
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 0, Character: 0},
+						End:   protocol.Position{Line: 1, Character: 21},
+					},
+					NewText: "// test\nfun test() {\n    return\n}\n",
+				},
+			},
+			textEdits,
+		)
+	})
+
+	t.Run("formatted", func(t *testing.T) {
+
+		t.Parallel()
+
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		server.documents[uri] = Document{
+			Text: "fun test() {\n    return\n}\n",
+		}
+
+		textEdits, err := server.DocumentFormatting(
+			nil,
+			&protocol.DocumentFormattingParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Options:      options,
+			},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, textEdits)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+
+		t.Parallel()
+
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		server.documents[uri] = Document{
+			Text: "fun test( {",
+		}
+
+		textEdits, err := server.DocumentFormatting(
+			nil,
+			&protocol.DocumentFormattingParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Options:      options,
+			},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, textEdits)
+	})
+}
+
+func TestDocumentRangeFormatting(t *testing.T) {
+
+	t.Parallel()
+
+	const uri = protocol.DocumentURI("file:///test.cdc")
+
+	server, err := NewServer()
+	require.NoError(t, err)
+
+	server.documents[uri] = Document{
+		Text: "fun a() { return }\n\nfun b() { return }\n",
+	}
+
+	textEdits, err := server.DocumentRangeFormatting(
+		nil,
+		&protocol.DocumentRangeFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Range:        protocolRange(2, 0, 1),
+			Options: protocol.FormattingOptions{
+				TabSize:      2,
+				InsertSpaces: true,
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]*protocol.TextEdit{
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 0},
+					End:   protocol.Position{Line: 2, Character: 18},
+				},
+				NewText: "fun b() {\n  return\n}",
+			},
+		},
+		textEdits,
+	)
+}
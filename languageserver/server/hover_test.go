@@ -0,0 +1,81 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+func TestHoverStringTemplate(t *testing.T) {
+
+	t.Parallel()
+
+	const uri = protocol.DocumentURI("file:///test.cdc")
+
+	server, err := NewServer()
+	require.NoError(t, err)
+
+	openDocuments(
+		t,
+		server,
+		map[protocol.DocumentURI]string{
+			uri: "let count = 1\nlet s = \"\\(count + 1) \\(\"\\(1.5)\")\"",
+		},
+		uri,
+	)
+
+	hover := func(line, character uint32) *protocol.Hover {
+		result, err := server.Hover(
+			nil,
+			&protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Position:     protocol.Position{Line: line, Character: character},
+			},
+		)
+		require.NoError(t, err)
+		return result
+	}
+
+	typeHover := func(ty string) *protocol.Hover {
+		return &protocol.Hover{
+			Contents: protocol.MarkupContent{
+				Kind:  protocol.Markdown,
+				Value: "**Type**\n\n```cadence\n" + ty + "\n```\n",
+			},
+		}
+	}
+
+	// Identifier in interpolation
+	assert.Equal(t, typeHover("Int"), hover(1, 11))
+
+	// Operator in interpolation
+	assert.Equal(t, typeHover("Int"), hover(1, 17))
+
+	// Nested string template
+	assert.Equal(t, typeHover("String"), hover(1, 24))
+	assert.Equal(t, typeHover("UFix64"), hover(1, 28))
+
+	// Outside of interpolation
+	assert.Nil(t, hover(1, 1))
+}
@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"github.com/onflow/cadence/languageserver/protocol"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -111,6 +113,27 @@ func TestLinting(t *testing.T) {
 		}, diagnostic)
 	})
 
+	t.Run("security", func(t *testing.T) {
+
+		t.Parallel()
+
+		diagnostics := checkProgram(t, `pub contract C {
+			pub let values: [Int]
+			init() { self.values = [] }
+		}`)
+
+		require.Equal(t, 1, len(diagnostics))
+
+		require.Equal(t, protocol.Diagnostic{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 1, Character: 3},
+				End:   protocol.Position{Line: 1, Character: 24},
+			},
+			Severity: protocol.SeverityWarning,
+			Message:  "public field `values` has type `[Int]`, its contents can be modified by anyone",
+		}, diagnostics[0])
+	})
+
 	t.Run("no lints in the presence of a type error", func(t *testing.T) {
 
 		t.Parallel()
@@ -133,3 +156,101 @@ func TestLinting(t *testing.T) {
 		}, diagnostic)
 	})
 }
+
+func TestConvertDiagnosticSuggestedFixes(t *testing.T) {
+
+	t.Parallel()
+
+	const uri = protocol.DocumentURI("file:///test.cdc")
+
+	diagnostic, codeActionsResolver := convertDiagnostic(
+		analysis.Diagnostic{
+			Range: ast.Range{
+				StartPos: ast.Position{Offset: 8, Line: 1, Column: 8},
+				EndPos:   ast.Position{Offset: 9, Line: 1, Column: 9},
+			},
+			Message: "unnecessary force operator",
+			SuggestedFixes: []analysis.SuggestedFix{
+				{
+					Message: "Remove unnecessary force operator",
+					TextEdits: []analysis.TextEdit{
+						{
+							Range: ast.Range{
+								StartPos: ast.Position{Offset: 9, Line: 1, Column: 9},
+								EndPos:   ast.Position{Offset: 9, Line: 1, Column: 9},
+							},
+						},
+					},
+				},
+				{
+					Message: "Add comment",
+					TextEdits: []analysis.TextEdit{
+						{
+							Insertion: " // forced",
+							Range: ast.Range{
+								StartPos: ast.Position{Offset: 10, Line: 1, Column: 10},
+								EndPos:   ast.Position{Offset: 10, Line: 1, Column: 10},
+							},
+						},
+					},
+				},
+			},
+		},
+		uri,
+	)
+
+	expectedDiagnostic := protocol.Diagnostic{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 0, Character: 8},
+			End:   protocol.Position{Line: 0, Character: 10},
+		},
+		Severity: protocol.SeverityInformation,
+		Message:  "unnecessary force operator",
+	}
+	require.Equal(t, expectedDiagnostic, diagnostic)
+
+	require.NotNil(t, codeActionsResolver)
+
+	assert.Equal(t,
+		[]*protocol.CodeAction{
+			{
+				Title:       "Remove unnecessary force operator",
+				Kind:        protocol.QuickFix,
+				Diagnostics: []protocol.Diagnostic{expectedDiagnostic},
+				Edit: protocol.WorkspaceEdit{
+					Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+						uri: {
+							{
+								Range: protocol.Range{
+									Start: protocol.Position{Line: 0, Character: 9},
+									End:   protocol.Position{Line: 0, Character: 10},
+								},
+								NewText: "",
+							},
+						},
+					},
+				},
+				IsPreferred: true,
+			},
+			{
+				Title:       "Add comment",
+				Kind:        protocol.QuickFix,
+				Diagnostics: []protocol.Diagnostic{expectedDiagnostic},
+				Edit: protocol.WorkspaceEdit{
+					Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+						uri: {
+							{
+								Range: protocol.Range{
+									Start: protocol.Position{Line: 0, Character: 10},
+									End:   protocol.Position{Line: 0, Character: 10},
+								},
+								NewText: " // forced",
+							},
+						},
+					},
+				},
+			},
+		},
+		codeActionsResolver(),
+	)
+}
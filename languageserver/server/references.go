@@ -0,0 +1,626 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"sort"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+
+	"github.com/onflow/cadence/languageserver/conversion"
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+// declaration identifies a declaration across programs.
+//
+// Each checker has its own origins, so the origins of a declaration in the program
+// which declares it and in the programs which import it are different.
+// Imported declarations also have no position in the importing program,
+// so they are identified by their identifier, declaration kind, and type.
+//
+type declaration struct {
+	location        common.Location
+	identifier      string
+	position        ast.Position
+	declarationKind common.DeclarationKind
+	ty              sema.Type
+	// global is true if the declaration is a global declaration of the program,
+	// i.e. it may be imported by other programs
+	global bool
+	// member is true if the declaration is a member of a composite or interface,
+	// i.e. it may be accessed in the programs which import the composite or interface
+	member bool
+}
+
+// findDeclaration finds the declaration referred to at the given position of the given document.
+// The declaration may be declared in the document itself, or in a program imported by it.
+//
+// Returns nil if there is no declaration at the given position.
+//
+func (s *Server) findDeclaration(uri protocol.DocumentURI, position protocol.Position) *declaration {
+	checker := s.checkerForDocument(uri)
+	if checker == nil {
+		return nil
+	}
+
+	semaPosition := conversion.ProtocolToSemaPosition(position)
+
+	occurrence := findOccurrence(checker, semaPosition)
+	if occurrence == nil {
+		// Accesses of imported members have no origin
+		return findImportedMemberDeclaration(checker, semaPosition)
+	}
+
+	origin := occurrence.Origin
+	identifier := s.openDocumentOccurrenceIdentifier(uri, occurrence)
+
+	if !isImportedOrigin(origin) {
+		variable := globalDeclaration(checker, identifier, origin.DeclarationKind)
+
+		return &declaration{
+			location:        checker.Location,
+			identifier:      identifier,
+			position:        *origin.StartPos,
+			declarationKind: origin.DeclarationKind,
+			ty:              origin.Type,
+			global: variable != nil &&
+				variable.Pos != nil &&
+				*variable.Pos == *origin.StartPos,
+			member: isMemberDeclaration(checker, identifier, *origin.StartPos, origin.DeclarationKind),
+		}
+	}
+
+	if identifier == "" {
+		return nil
+	}
+
+	importedChecker, variable := s.importedDeclaration(checker, identifier, origin)
+	if variable == nil {
+		return nil
+	}
+
+	return &declaration{
+		location:        importedChecker.Location,
+		identifier:      identifier,
+		position:        *variable.Pos,
+		declarationKind: origin.DeclarationKind,
+		ty:              origin.Type,
+		global:          true,
+	}
+}
+
+// importedDeclaration returns the checker of the imported program which declares
+// the imported declaration with the given identifier and origin, and the declaration's variable.
+//
+// Only programs from which the identifier is imported are considered,
+// i.e. the import declaration must name the identifier, or import all declarations.
+//
+// Returns nil if none of the programs imported by the given checker declares it.
+//
+func (s *Server) importedDeclaration(
+	checker *sema.Checker,
+	identifier string,
+	origin *sema.Origin,
+) (
+	*sema.Checker,
+	*sema.Variable,
+) {
+	for _, importDeclaration := range checker.Program.ImportDeclarations() {
+		if !importDeclarationImportsIdentifier(importDeclaration, identifier) {
+			continue
+		}
+
+		resolvedLocations := checker.Elaboration.ImportDeclarationsResolvedLocations[importDeclaration]
+		for _, resolvedLocation := range resolvedLocations {
+			importedLocation := normalizeImportedLocation(checker, resolvedLocation.Location)
+
+			importedChecker, ok := s.checkers[importedLocation.ID()]
+			if !ok {
+				continue
+			}
+
+			variable := globalDeclaration(importedChecker, identifier, origin.DeclarationKind)
+			if variable == nil ||
+				variable.Pos == nil ||
+				!typesEqual(variable.Type, origin.Type) {
+
+				continue
+			}
+
+			return importedChecker, variable
+		}
+	}
+
+	return nil, nil
+}
+
+// importDeclarationImportsIdentifier returns true if the given import declaration
+// imports the declaration with the given identifier, i.e. if it names the identifier,
+// or if it names no identifiers and so imports all declarations.
+//
+func importDeclarationImportsIdentifier(importDeclaration *ast.ImportDeclaration, identifier string) bool {
+	if len(importDeclaration.Identifiers) == 0 {
+		return true
+	}
+
+	for _, importedIdentifier := range importDeclaration.Identifiers {
+		if importedIdentifier.Identifier == identifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findImportedMemberDeclaration finds the declaration of the imported member
+// which is accessed at the given position of the given checker's program.
+//
+// Returns nil if there is no access of an imported member at the given position.
+//
+func findImportedMemberDeclaration(checker *sema.Checker, position sema.Position) *declaration {
+	for memberExpression, memberInfo := range checker.Elaboration.MemberExpressionMemberInfos {
+		identifier := memberExpression.Identifier
+		startPosition := identifier.StartPosition()
+		endPosition := identifier.EndPosition(nil)
+
+		// Like in findOccurrence, the position after the identifier is also accepted
+
+		if position.Line != startPosition.Line ||
+			position.Column < startPosition.Column ||
+			position.Column > endPosition.Column+1 {
+
+			continue
+		}
+
+		member := memberInfo.Member
+		if member == nil {
+			return nil
+		}
+
+		location := memberContainerLocation(member)
+		if location == nil || location.ID() == checker.Location.ID() {
+			return nil
+		}
+
+		var ty sema.Type
+		if member.TypeAnnotation != nil {
+			ty = member.TypeAnnotation.Type
+		}
+
+		return &declaration{
+			location:        location,
+			identifier:      member.Identifier.Identifier,
+			position:        member.Identifier.Pos,
+			declarationKind: member.DeclarationKind,
+			ty:              ty,
+			member:          true,
+		}
+	}
+
+	return nil
+}
+
+// isMemberDeclaration returns true if the declaration with the given identifier, position,
+// and declaration kind is a member of a composite or interface declared by the given checker.
+//
+func isMemberDeclaration(
+	checker *sema.Checker,
+	identifier string,
+	position ast.Position,
+	declarationKind common.DeclarationKind,
+) bool {
+	if identifier == "" {
+		return false
+	}
+
+	var membersList []*sema.StringMemberOrderedMap
+
+	for _, compositeType := range checker.Elaboration.CompositeDeclarationTypes {
+		membersList = append(membersList, compositeType.Members)
+	}
+
+	for _, interfaceType := range checker.Elaboration.InterfaceDeclarationTypes {
+		membersList = append(membersList, interfaceType.Members)
+	}
+
+	for _, members := range membersList {
+		if members == nil {
+			continue
+		}
+
+		member, ok := members.Get(identifier)
+		if ok &&
+			member.Identifier.Pos == position &&
+			member.DeclarationKind == declarationKind {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// memberContainerLocation returns the location of the type which declares the given member,
+// or nil if the type has no location.
+//
+func memberContainerLocation(member *sema.Member) common.Location {
+	locatedType, ok := member.ContainerType.(sema.LocatedType)
+	if !ok {
+		return nil
+	}
+	return locatedType.GetLocation()
+}
+
+// declarationReferences returns the ranges of all references to the given declaration,
+// grouped by document.
+//
+// References are searched in the program which declares the declaration,
+// and in all programs which import it, through string or address locations.
+// Only programs which are open documents or which have a file location are considered.
+//
+func (s *Server) declarationReferences(
+	declaration *declaration,
+	uris map[common.LocationID]protocol.DocumentURI,
+	includeDeclaration bool,
+) map[protocol.DocumentURI][]protocol.Range {
+
+	references := map[protocol.DocumentURI][]protocol.Range{}
+
+	for locationID, checker := range s.checkers {
+		checkerURI, ok := uris[locationID]
+		if !ok {
+			continue
+		}
+
+		// The identifiers of imported declarations are compared against the text of the program,
+		// so the program's text must be available
+
+		document, ok := s.programDocument(checkerURI, checker.Location)
+		if !ok {
+			continue
+		}
+
+		isDeclaringChecker := locationID == declaration.location.ID()
+		if !isDeclaringChecker &&
+			!((declaration.global || declaration.member) &&
+				importsLocation(checker, declaration.location)) {
+
+			continue
+		}
+
+		var ranges []protocol.Range
+
+		addRange := func(textRange protocol.Range) {
+			for _, existingRange := range ranges {
+				if existingRange == textRange {
+					return
+				}
+			}
+			ranges = append(ranges, textRange)
+		}
+
+		for _, occurrence := range checker.Occurrences.All() {
+			origin := occurrence.Origin
+			if origin == nil || origin.DeclarationKind != declaration.declarationKind {
+				continue
+			}
+
+			if isDeclaringChecker {
+				if origin.StartPos == nil || *origin.StartPos != declaration.position {
+					continue
+				}
+
+				if !includeDeclaration &&
+					occurrence.StartPos == sema.ASTToSemaPosition(declaration.position) {
+
+					continue
+				}
+
+			} else if !declaration.global ||
+				!isImportedOrigin(origin) ||
+				!typesEqual(origin.Type, declaration.ty) ||
+				occurrenceIdentifier(document, &occurrence) != declaration.identifier ||
+				!s.isImportedFrom(checker, declaration.identifier, origin, declaration.location) {
+
+				continue
+			}
+
+			addRange(
+				conversion.SemaToProtocolRange(
+					occurrence.StartPos,
+					occurrence.EndPos,
+				),
+			)
+		}
+
+		// Accesses of imported members are not recorded as occurrences with an origin,
+		// so they are found through the members of the member expressions
+
+		if !isDeclaringChecker && declaration.member {
+			for memberExpression, memberInfo := range checker.Elaboration.MemberExpressionMemberInfos {
+				member := memberInfo.Member
+				if member == nil ||
+					member.Identifier.Pos != declaration.position ||
+					member.DeclarationKind != declaration.declarationKind {
+
+					continue
+				}
+
+				location := memberContainerLocation(member)
+				if location == nil || location.ID() != declaration.location.ID() {
+					continue
+				}
+
+				identifier := memberExpression.Identifier
+
+				addRange(
+					conversion.ASTToProtocolRange(
+						identifier.StartPosition(),
+						identifier.EndPosition(nil),
+					),
+				)
+			}
+		}
+
+		// Imported declarations are named in the import declarations,
+		// which are not recorded as occurrences
+
+		if !isDeclaringChecker && declaration.global {
+			for _, importDeclaration := range checker.Program.ImportDeclarations() {
+				if !importDeclarationImportsLocation(checker, importDeclaration, declaration.location) {
+					continue
+				}
+
+				for _, importedIdentifier := range importDeclaration.Identifiers {
+					if importedIdentifier.Identifier != declaration.identifier {
+						continue
+					}
+
+					addRange(
+						conversion.ASTToProtocolRange(
+							importedIdentifier.StartPosition(),
+							importedIdentifier.EndPosition(nil),
+						),
+					)
+				}
+			}
+		}
+
+		if len(ranges) == 0 {
+			continue
+		}
+
+		sort.Slice(ranges, func(i, j int) bool {
+			a, b := ranges[i].Start, ranges[j].Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Character < b.Character
+		})
+
+		references[checkerURI] = ranges
+	}
+
+	return references
+}
+
+// findOccurrence returns the occurrence at the given position which has an origin.
+// If there is no such occurrence, the preceding position is tried,
+// so that the end of an identifier is also found.
+//
+func findOccurrence(checker *sema.Checker, position sema.Position) *sema.Occurrence {
+	occurrences := checker.Occurrences.FindAll(position)
+	if len(occurrences) == 0 && position.Column > 0 {
+		previousPosition := position
+		previousPosition.Column -= 1
+		occurrences = checker.Occurrences.FindAll(previousPosition)
+	}
+
+	for _, occurrence := range occurrences {
+		origin := occurrence.Origin
+		if origin == nil || origin.StartPos == nil || origin.EndPos == nil {
+			continue
+		}
+
+		return &occurrence
+	}
+
+	return nil
+}
+
+// isImportedOrigin returns true if the given origin is the origin of an imported declaration.
+// Imported declarations are declared without a position.
+//
+func isImportedOrigin(origin *sema.Origin) bool {
+	return origin.StartPos != nil && *origin.StartPos == ast.EmptyPosition
+}
+
+// globalDeclaration returns the global value or type declaration
+// with the given identifier and declaration kind of the given checker, if any.
+//
+func globalDeclaration(
+	checker *sema.Checker,
+	identifier string,
+	declarationKind common.DeclarationKind,
+) *sema.Variable {
+	if identifier == "" {
+		return nil
+	}
+
+	for _, globals := range []*sema.StringVariableOrderedMap{
+		checker.Elaboration.GlobalValues,
+		checker.Elaboration.GlobalTypes,
+	} {
+		variable, ok := globals.Get(identifier)
+		if ok && variable.DeclarationKind == declarationKind {
+			return variable
+		}
+	}
+
+	return nil
+}
+
+func typesEqual(a, b sema.Type) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(b)
+}
+
+// occurrenceIdentifier returns the identifier of the given occurrence in the given document,
+// or the empty string if the occurrence is not in the document.
+//
+func occurrenceIdentifier(document Document, occurrence *sema.Occurrence) string {
+	startOffset := document.Offset(occurrence.StartPos.Line, occurrence.StartPos.Column)
+	endOffset := document.Offset(occurrence.EndPos.Line, occurrence.EndPos.Column) + 1
+
+	if startOffset < 0 || endOffset > len(document.Text) || startOffset >= endOffset {
+		return ""
+	}
+
+	return document.Text[startOffset:endOffset]
+}
+
+// openDocumentOccurrenceIdentifier returns the identifier of the given occurrence in the given open document,
+// or the empty string if the document is not open.
+//
+func (s *Server) openDocumentOccurrenceIdentifier(uri protocol.DocumentURI, occurrence *sema.Occurrence) string {
+	document, ok := s.documents[uri]
+	if !ok {
+		return ""
+	}
+
+	return occurrenceIdentifier(document, occurrence)
+}
+
+// programDocument returns the document of the program with the given URI and location:
+// The open document, or if the document is not open, the code of the file which is imported.
+//
+// Returns false if the document is not open and the file's code cannot be resolved.
+//
+func (s *Server) programDocument(uri protocol.DocumentURI, location common.Location) (Document, bool) {
+	if document, ok := s.documents[uri]; ok {
+		return document, true
+	}
+
+	stringLocation, ok := location.(common.StringLocation)
+	if !ok || s.resolveStringImport == nil {
+		return Document{}, false
+	}
+
+	code, err := s.resolveStringImport(stringLocation)
+	if err != nil {
+		return Document{}, false
+	}
+
+	return Document{Text: code}, true
+}
+
+// isImportedFrom returns true if the imported declaration with the given identifier and origin
+// is declared by the program with the given location, and not by another program imported by the given checker.
+//
+func (s *Server) isImportedFrom(
+	checker *sema.Checker,
+	identifier string,
+	origin *sema.Origin,
+	location common.Location,
+) bool {
+	importedChecker, _ := s.importedDeclaration(checker, identifier, origin)
+	return importedChecker != nil &&
+		importedChecker.Location.ID() == location.ID()
+}
+
+// locationURIs returns the URIs of the programs of all checkers, by location.
+// Open documents have their URI, and programs with a path location have a file URI.
+//
+func (s *Server) locationURIs() map[common.LocationID]protocol.DocumentURI {
+	uris := make(map[common.LocationID]protocol.DocumentURI, len(s.checkers))
+
+	for locationID, checker := range s.checkers {
+		path := locationToPath(checker.Location)
+		if path == "" {
+			continue
+		}
+		uris[locationID] = protocol.DocumentURI(filePrefix + path)
+	}
+
+	// Prefer the URIs of open documents, as clients may send URIs without the file prefix
+
+	for uri := range s.documents {
+		uris[uriToLocation(uri).ID()] = uri
+	}
+
+	return uris
+}
+
+// importedLocations returns the locations of all programs imported by the given checker.
+// Path locations are normalized against the location of the importing program.
+//
+func importedLocations(checker *sema.Checker) []common.Location {
+	var locations []common.Location
+
+	for _, importDeclaration := range checker.Program.ImportDeclarations() {
+		resolvedLocations := checker.Elaboration.ImportDeclarationsResolvedLocations[importDeclaration]
+		for _, resolvedLocation := range resolvedLocations {
+			locations = append(
+				locations,
+				normalizeImportedLocation(checker, resolvedLocation.Location),
+			)
+		}
+	}
+
+	return locations
+}
+
+// importsLocation returns true if the given checker imports the program with the given location.
+//
+func importsLocation(checker *sema.Checker, location common.Location) bool {
+	for _, importedLocation := range importedLocations(checker) {
+		if importedLocation.ID() == location.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// importDeclarationImportsLocation returns true if the given import declaration
+// of the given checker imports the program with the given location.
+//
+func importDeclarationImportsLocation(
+	checker *sema.Checker,
+	importDeclaration *ast.ImportDeclaration,
+	location common.Location,
+) bool {
+	resolvedLocations := checker.Elaboration.ImportDeclarationsResolvedLocations[importDeclaration]
+	for _, resolvedLocation := range resolvedLocations {
+		if normalizeImportedLocation(checker, resolvedLocation.Location).ID() == location.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeImportedLocation(checker *sema.Checker, location common.Location) common.Location {
+	if isPathLocation(location) {
+		return normalizePathLocation(checker.Location, location)
+	}
+	return location
+}
@@ -0,0 +1,335 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+func openDocuments(t *testing.T, server *Server, documents map[protocol.DocumentURI]string, order ...protocol.DocumentURI) {
+	for _, uri := range order {
+		text := documents[uri]
+		server.documents[uri] = Document{Text: text}
+		_, err := server.getDiagnostics(uri, text, 0, func(_ *protocol.LogMessageParams) {})
+		require.NoError(t, err)
+	}
+}
+
+func protocolRange(line, startCharacter, endCharacter uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: line, Character: startCharacter},
+		End:   protocol.Position{Line: line, Character: endCharacter},
+	}
+}
+
+func TestReferences(t *testing.T) {
+
+	t.Parallel()
+
+	const fooURI = protocol.DocumentURI("file:///foo.cdc")
+	const barURI = protocol.DocumentURI("file:///bar.cdc")
+	const bazURI = protocol.DocumentURI("file:///baz.cdc")
+
+	documents := map[protocol.DocumentURI]string{
+		fooURI: "pub fun foo(): Int { return 1 }\npub fun unused() {}",
+		barURI: "import foo from \"./foo.cdc\"\npub fun bar(): Int { return foo() + foo() }",
+		bazURI: "pub fun foo(): Int { return 2 }\npub fun baz(): Int { return foo() }",
+	}
+
+	newServer := func(t *testing.T) *Server {
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		err = server.SetOptions(
+			WithStringImportResolver(func(location common.StringLocation) (string, error) {
+				return documents[protocol.DocumentURI(filePrefix+string(location))], nil
+			}),
+		)
+		require.NoError(t, err)
+
+		openDocuments(t, server, documents, fooURI, barURI, bazURI)
+
+		return server
+	}
+
+	t.Run("from declaration", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t)
+
+		locations, err := server.References(nil, &protocol.ReferenceParams{
+			Context: protocol.ReferenceContext{
+				IncludeDeclaration: true,
+			},
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fooURI},
+				Position:     protocol.Position{Line: 0, Character: 9},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]*protocol.Location{
+				{URI: barURI, Range: protocolRange(0, 7, 10)},
+				{URI: barURI, Range: protocolRange(1, 28, 31)},
+				{URI: barURI, Range: protocolRange(1, 36, 39)},
+				{URI: fooURI, Range: protocolRange(0, 8, 11)},
+			},
+			locations,
+		)
+	})
+
+	t.Run("from import, without declaration", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t)
+
+		locations, err := server.References(nil, &protocol.ReferenceParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: barURI},
+				Position:     protocol.Position{Line: 1, Character: 29},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]*protocol.Location{
+				{URI: barURI, Range: protocolRange(0, 7, 10)},
+				{URI: barURI, Range: protocolRange(1, 28, 31)},
+				{URI: barURI, Range: protocolRange(1, 36, 39)},
+			},
+			locations,
+		)
+	})
+
+	t.Run("no declaration", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t)
+
+		locations, err := server.References(nil, &protocol.ReferenceParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fooURI},
+				Position:     protocol.Position{Line: 0, Character: 0},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, locations)
+	})
+}
+
+func TestReferencesInFiles(t *testing.T) {
+
+	t.Parallel()
+
+	const fooURI = protocol.DocumentURI("file:///foo.cdc")
+	const quxURI = protocol.DocumentURI("file:///qux.cdc")
+	const barURI = protocol.DocumentURI("file:///bar.cdc")
+	const otherURI = protocol.DocumentURI("file:///other.cdc")
+	const mainURI = protocol.DocumentURI("file:///main.cdc")
+
+	// Only foo.cdc and main.cdc are open documents, the other programs are files.
+	// bar.cdc imports a function with the same type and identifier length from qux.cdc,
+	// and other.cdc imports a function with the same type and identifier from qux.cdc
+
+	documents := map[protocol.DocumentURI]string{
+		fooURI:   "pub fun foo(): Int { return 1 }\npub fun unused() {}",
+		quxURI:   "pub fun foo(): Int { return 3 }\npub fun baz(): Int { return 4 }",
+		barURI:   "import foo from \"./foo.cdc\"\nimport baz from \"./qux.cdc\"\npub fun bar(): Int { return foo() + baz() }",
+		otherURI: "import unused from \"./foo.cdc\"\nimport foo from \"./qux.cdc\"\npub fun other(): Int { return foo() }",
+		mainURI:  "import bar from \"./bar.cdc\"\nimport other from \"./other.cdc\"\npub fun main(): Int { return bar() + other() }",
+	}
+
+	server, err := NewServer()
+	require.NoError(t, err)
+
+	err = server.SetOptions(
+		WithStringImportResolver(func(location common.StringLocation) (string, error) {
+			return documents[protocol.DocumentURI(filePrefix+string(location))], nil
+		}),
+	)
+	require.NoError(t, err)
+
+	openDocuments(t, server, documents, fooURI, mainURI)
+
+	locations, err := server.References(nil, &protocol.ReferenceParams{
+		Context: protocol.ReferenceContext{
+			IncludeDeclaration: true,
+		},
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: fooURI},
+			Position:     protocol.Position{Line: 0, Character: 9},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]*protocol.Location{
+			{URI: barURI, Range: protocolRange(0, 7, 10)},
+			{URI: barURI, Range: protocolRange(2, 28, 31)},
+			{URI: fooURI, Range: protocolRange(0, 8, 11)},
+		},
+		locations,
+	)
+}
+
+func TestRename(t *testing.T) {
+
+	t.Parallel()
+
+	const fooURI = protocol.DocumentURI("file:///foo.cdc")
+	const barURI = protocol.DocumentURI("file:///bar.cdc")
+
+	const fooCode = "pub contract Foo {\n    pub fun answer(): Int { return 42 }\n}"
+
+	newServer := func(t *testing.T, barCode string) *Server {
+		documents := map[protocol.DocumentURI]string{
+			fooURI: fooCode,
+			barURI: barCode,
+		}
+
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		err = server.SetOptions(
+			WithStringImportResolver(func(location common.StringLocation) (string, error) {
+				return documents[protocol.DocumentURI(filePrefix+string(location))], nil
+			}),
+			WithAddressImportResolver(func(location common.AddressLocation) (string, error) {
+				return fooCode, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		openDocuments(t, server, documents, fooURI, barURI)
+
+		return server
+	}
+
+	t.Run("contract imported from address", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t, "import Foo from 0x1\npub fun bar(): Int { return Foo.answer() }")
+
+		// The contract is declared at an address location, which has no document,
+		// so renaming it would break the import
+
+		edit, err := server.Rename(nil, &protocol.RenameParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: barURI},
+			Position:     protocol.Position{Line: 1, Character: 29},
+			NewName:      "Bar",
+		})
+		require.EqualError(t,
+			err,
+			"cannot rename Foo: declared in 0000000000000001.Foo, which is not an open document or file",
+		)
+		assert.Nil(t, edit)
+	})
+
+	t.Run("contract imported from file", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t, "import Foo from \"./foo.cdc\"\npub fun bar(): Int { return Foo.answer() }")
+
+		edit, err := server.Rename(nil, &protocol.RenameParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: barURI},
+			Position:     protocol.Position{Line: 1, Character: 29},
+			NewName:      "Bar",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			&protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					fooURI: {
+						{Range: protocolRange(0, 13, 16), NewText: "Bar"},
+					},
+					barURI: {
+						{Range: protocolRange(0, 7, 10), NewText: "Bar"},
+						{Range: protocolRange(1, 28, 31), NewText: "Bar"},
+					},
+				},
+			},
+			edit,
+		)
+	})
+
+	t.Run("member imported from file", func(t *testing.T) {
+
+		t.Parallel()
+
+		const barCode = "import Foo from \"./foo.cdc\"\npub fun bar(): Int { return Foo.answer() }"
+
+		expected := &protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+				fooURI: {
+					{Range: protocolRange(1, 12, 18), NewText: "question"},
+				},
+				barURI: {
+					{Range: protocolRange(1, 32, 38), NewText: "question"},
+				},
+			},
+		}
+
+		t.Run("from declaration", func(t *testing.T) {
+
+			t.Parallel()
+
+			server := newServer(t, barCode)
+
+			edit, err := server.Rename(nil, &protocol.RenameParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: fooURI},
+				Position:     protocol.Position{Line: 1, Character: 13},
+				NewName:      "question",
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, edit)
+		})
+
+		t.Run("from access", func(t *testing.T) {
+
+			t.Parallel()
+
+			server := newServer(t, barCode)
+
+			edit, err := server.Rename(nil, &protocol.RenameParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: barURI},
+				Position:     protocol.Position{Line: 1, Character: 33},
+				NewName:      "question",
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, edit)
+		})
+	})
+}
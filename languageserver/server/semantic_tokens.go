@@ -0,0 +1,425 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+
+	"github.com/onflow/cadence/languageserver/conversion"
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+type semanticTokenType uint32
+
+// NOTE: The order of the token types must match semanticTokenTypes
+const (
+	semanticTokenTypeNamespace semanticTokenType = iota
+	semanticTokenTypeType
+	semanticTokenTypeClass
+	semanticTokenTypeEnum
+	semanticTokenTypeInterface
+	semanticTokenTypeStruct
+	semanticTokenTypeTypeParameter
+	semanticTokenTypeParameter
+	semanticTokenTypeVariable
+	semanticTokenTypeProperty
+	semanticTokenTypeEnumMember
+	semanticTokenTypeEvent
+	semanticTokenTypeFunction
+	semanticTokenTypeMethod
+)
+
+var semanticTokenTypes = []string{
+	"namespace",
+	"type",
+	"class",
+	"enum",
+	"interface",
+	"struct",
+	"typeParameter",
+	"parameter",
+	"variable",
+	"property",
+	"enumMember",
+	"event",
+	"function",
+	"method",
+}
+
+type semanticTokenModifiers uint32
+
+// NOTE: The order of the token modifiers must match semanticTokenModifierNames
+const (
+	semanticTokenModifierDeclaration semanticTokenModifiers = 1 << iota
+	semanticTokenModifierResource
+	semanticTokenModifierMutable
+	semanticTokenModifierDeprecated
+	semanticTokenModifierCapability
+	semanticTokenModifierReference
+)
+
+var semanticTokenModifierNames = []string{
+	"declaration",
+	"resource",
+	"mutable",
+	"deprecated",
+	"capability",
+	"reference",
+}
+
+// deprecatedDocStringTag marks a declaration as deprecated when it occurs in its doc string
+const deprecatedDocStringTag = "@deprecated"
+
+func semanticTokensLegend() protocol.SemanticTokensLegend {
+	return protocol.SemanticTokensLegend{
+		TokenTypes:     semanticTokenTypes,
+		TokenModifiers: semanticTokenModifierNames,
+	}
+}
+
+type semanticToken struct {
+	line      int
+	column    int
+	length    int
+	tokenType semanticTokenType
+	modifiers semanticTokenModifiers
+}
+
+// SemanticTokensFull returns the semantic tokens of the whole document.
+func (s *Server) SemanticTokensFull(
+	_ protocol.Conn,
+	params *protocol.SemanticTokensParams,
+) (
+	*protocol.SemanticTokens,
+	error,
+) {
+	checker := s.checkerForDocument(params.TextDocument.URI)
+	if checker == nil {
+		return &protocol.SemanticTokens{Data: []uint32{}}, nil
+	}
+
+	tokens := semanticTokens(checker)
+
+	return &protocol.SemanticTokens{
+		Data: encodeSemanticTokens(tokens),
+	}, nil
+}
+
+// SemanticTokensRange returns the semantic tokens of the document
+// which are inside of the given range.
+func (s *Server) SemanticTokensRange(
+	_ protocol.Conn,
+	params *protocol.SemanticTokensRangeParams,
+) (
+	*protocol.SemanticTokens,
+	error,
+) {
+	checker := s.checkerForDocument(params.TextDocument.URI)
+	if checker == nil {
+		return &protocol.SemanticTokens{Data: []uint32{}}, nil
+	}
+
+	start := conversion.ProtocolToSemaPosition(params.Range.Start)
+	end := conversion.ProtocolToSemaPosition(params.Range.End)
+
+	allTokens := semanticTokens(checker)
+	tokens := make([]semanticToken, 0, len(allTokens))
+
+	for _, token := range allTokens {
+		tokenStart := sema.Position{
+			Line:   token.line,
+			Column: token.column,
+		}
+		tokenEnd := sema.Position{
+			Line:   token.line,
+			Column: token.column + token.length,
+		}
+		if tokenEnd.Compare(start) <= 0 || tokenStart.Compare(end) >= 0 {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	return &protocol.SemanticTokens{
+		Data: encodeSemanticTokens(tokens),
+	}, nil
+}
+
+// semanticTokens returns the semantic tokens for the occurrences of the given checker,
+// sorted by position.
+//
+// Overlapping occurrences, e.g. the declaration of a composite type
+// and the declaration of its constructor function, result in a single token.
+//
+func semanticTokens(checker *sema.Checker) []semanticToken {
+	members := semanticTokenMembers(checker.Elaboration)
+
+	occurrences := checker.Occurrences.All()
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartPos.Compare(occurrences[j].StartPos) < 0
+	})
+
+	tokens := make([]semanticToken, 0, len(occurrences))
+
+	var previousEnd *sema.Position
+
+	for _, occurrence := range occurrences {
+		// Multi-line tokens are not supported
+		if occurrence.StartPos.Line != occurrence.EndPos.Line {
+			continue
+		}
+
+		if previousEnd != nil && occurrence.StartPos.Compare(*previousEnd) <= 0 {
+			continue
+		}
+
+		member := members[occurrence.StartPos]
+
+		token, ok := newSemanticToken(occurrence, member)
+		if !ok {
+			continue
+		}
+
+		tokens = append(tokens, token)
+
+		endPos := occurrence.EndPos
+		previousEnd = &endPos
+	}
+
+	return tokens
+}
+
+// semanticTokenMembers returns the members declared in the program,
+// and the members accessed in member expressions, by the position of their identifier.
+//
+func semanticTokenMembers(elaboration *sema.Elaboration) map[sema.Position]*sema.Member {
+	members := map[sema.Position]*sema.Member{}
+
+	addMembers := func(declaredMembers *sema.StringMemberOrderedMap) {
+		declaredMembers.Foreach(func(_ string, member *sema.Member) {
+			position := sema.ASTToSemaPosition(member.Identifier.Pos)
+			members[position] = member
+		})
+	}
+
+	for _, compositeType := range elaboration.CompositeDeclarationTypes {
+		addMembers(compositeType.Members)
+	}
+
+	for _, interfaceType := range elaboration.InterfaceDeclarationTypes {
+		addMembers(interfaceType.Members)
+	}
+
+	for memberExpression, memberInfo := range elaboration.MemberExpressionMemberInfos {
+		if memberInfo.Member == nil {
+			continue
+		}
+		position := sema.ASTToSemaPosition(memberExpression.Identifier.Pos)
+		members[position] = memberInfo.Member
+	}
+
+	return members
+}
+
+// newSemanticToken returns the semantic token for the given occurrence.
+// The member is the member declared or accessed at the occurrence, if any.
+//
+// Returns false if the occurrence has no semantic token,
+// e.g. because it is the occurrence of a special function.
+//
+func newSemanticToken(occurrence sema.Occurrence, member *sema.Member) (semanticToken, bool) {
+
+	var declarationKind common.DeclarationKind
+	var ty sema.Type
+	var docString string
+	var modifiers semanticTokenModifiers
+
+	origin := occurrence.Origin
+
+	switch {
+	case origin != nil:
+		declarationKind = origin.DeclarationKind
+		ty = origin.Type
+		docString = origin.DocString
+
+		if origin.StartPos != nil &&
+			sema.ASTToSemaPosition(*origin.StartPos) == occurrence.StartPos {
+
+			modifiers |= semanticTokenModifierDeclaration
+		}
+
+	case member != nil:
+		declarationKind = member.DeclarationKind
+		if member.TypeAnnotation != nil {
+			ty = member.TypeAnnotation.Type
+		}
+		docString = member.DocString
+
+	default:
+		return semanticToken{}, false
+	}
+
+	tokenType, ok := semanticTokenTypeForDeclarationKind(declarationKind, member)
+	if !ok {
+		return semanticToken{}, false
+	}
+
+	// The occurrence of a composite type may refer to its constructor function,
+	// so the declaration kind determines if it is a resource type
+	if declarationKind == common.DeclarationKindResource ||
+		declarationKind == common.DeclarationKindResourceInterface {
+
+		modifiers |= semanticTokenModifierResource
+	}
+
+	if ty != nil {
+		if ty.IsResourceType() {
+			modifiers |= semanticTokenModifierResource
+		}
+
+		switch sema.UnwrapOptionalType(ty).(type) {
+		case *sema.CapabilityType:
+			modifiers |= semanticTokenModifierCapability
+		case *sema.ReferenceType:
+			modifiers |= semanticTokenModifierReference
+		}
+	}
+
+	if declarationKind == common.DeclarationKindVariable ||
+		(member != nil && member.VariableKind == ast.VariableKindVariable) {
+
+		modifiers |= semanticTokenModifierMutable
+	}
+
+	if strings.Contains(docString, deprecatedDocStringTag) {
+		modifiers |= semanticTokenModifierDeprecated
+	}
+
+	return semanticToken{
+		line:      occurrence.StartPos.Line,
+		column:    occurrence.StartPos.Column,
+		length:    occurrence.EndPos.Column - occurrence.StartPos.Column + 1,
+		tokenType: tokenType,
+		modifiers: modifiers,
+	}, true
+}
+
+func semanticTokenTypeForDeclarationKind(
+	declarationKind common.DeclarationKind,
+	member *sema.Member,
+) (semanticTokenType, bool) {
+
+	switch declarationKind {
+	case common.DeclarationKindContract,
+		common.DeclarationKindImport:
+
+		return semanticTokenTypeNamespace, true
+
+	case common.DeclarationKindType,
+		common.DeclarationKindTypeAlias:
+
+		return semanticTokenTypeType, true
+
+	case common.DeclarationKindResource:
+		return semanticTokenTypeClass, true
+
+	case common.DeclarationKindStructure:
+		return semanticTokenTypeStruct, true
+
+	case common.DeclarationKindStructureInterface,
+		common.DeclarationKindResourceInterface,
+		common.DeclarationKindContractInterface:
+
+		return semanticTokenTypeInterface, true
+
+	case common.DeclarationKindEnum:
+		return semanticTokenTypeEnum, true
+
+	case common.DeclarationKindEnumCase:
+		return semanticTokenTypeEnumMember, true
+
+	case common.DeclarationKindEvent:
+		return semanticTokenTypeEvent, true
+
+	case common.DeclarationKindTypeParameter:
+		return semanticTokenTypeTypeParameter, true
+
+	case common.DeclarationKindParameter:
+		return semanticTokenTypeParameter, true
+
+	case common.DeclarationKindField:
+		return semanticTokenTypeProperty, true
+
+	case common.DeclarationKindFunction:
+		if member != nil {
+			return semanticTokenTypeMethod, true
+		}
+		return semanticTokenTypeFunction, true
+
+	case common.DeclarationKindValue,
+		common.DeclarationKindConstant,
+		common.DeclarationKindVariable,
+		common.DeclarationKindSelf:
+
+		return semanticTokenTypeVariable, true
+	}
+
+	return 0, false
+}
+
+// encodeSemanticTokens encodes the given tokens, which must be sorted by position,
+// in the relative format of the protocol:
+// Each token is encoded as five integers, the line relative to the previous token,
+// the start character relative to the previous token if on the same line,
+// the length, the token type, and the token modifiers.
+//
+func encodeSemanticTokens(tokens []semanticToken) []uint32 {
+	data := make([]uint32, 0, len(tokens)*5)
+
+	// Lines of tokens start at 1, lines in the protocol start at 0
+	previousLine := 1
+	previousColumn := 0
+
+	for _, token := range tokens {
+		deltaLine := token.line - previousLine
+		deltaColumn := token.column
+		if deltaLine == 0 {
+			deltaColumn -= previousColumn
+		}
+
+		data = append(
+			data,
+			uint32(deltaLine),
+			uint32(deltaColumn),
+			uint32(token.length),
+			uint32(token.tokenType),
+			uint32(token.modifiers),
+		)
+
+		previousLine = token.line
+		previousColumn = token.column
+	}
+
+	return data
+}
@@ -0,0 +1,195 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/languageserver/protocol"
+)
+
+const semanticTokensCode = `pub resource R {
+    pub var balance: Int
+
+    init() {
+        self.balance = 0
+    }
+
+    /// @deprecated use new
+    pub fun old() {}
+}
+
+pub fun test(cap: Capability<&R>) {
+    let r <- create R()
+    let ref = &r as &R
+    ref.old()
+    destroy r
+}
+`
+
+type decodedSemanticToken struct {
+	line      uint32
+	character uint32
+	length    uint32
+	tokenType semanticTokenType
+	modifiers semanticTokenModifiers
+}
+
+// decodeSemanticTokens decodes the relative encoding of the protocol into absolute positions
+func decodeSemanticTokens(t *testing.T, data []uint32) []decodedSemanticToken {
+	require.Zero(t, len(data)%5)
+
+	tokens := make([]decodedSemanticToken, 0, len(data)/5)
+
+	var line, character uint32
+
+	for i := 0; i < len(data); i += 5 {
+		if data[i] > 0 {
+			character = 0
+		}
+		line += data[i]
+		character += data[i+1]
+
+		tokens = append(tokens, decodedSemanticToken{
+			line:      line,
+			character: character,
+			length:    data[i+2],
+			tokenType: semanticTokenType(data[i+3]),
+			modifiers: semanticTokenModifiers(data[i+4]),
+		})
+	}
+
+	return tokens
+}
+
+func TestSemanticTokens(t *testing.T) {
+
+	t.Parallel()
+
+	const uri = protocol.DocumentURI("file:///test.cdc")
+
+	newServer := func(t *testing.T) *Server {
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		openDocuments(
+			t,
+			server,
+			map[protocol.DocumentURI]string{uri: semanticTokensCode},
+			uri,
+		)
+
+		return server
+	}
+
+	t.Run("full", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t)
+
+		tokens, err := server.SemanticTokensFull(
+			nil,
+			&protocol.SemanticTokensParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]decodedSemanticToken{
+				// resource R
+				{0, 13, 1, semanticTokenTypeClass, semanticTokenModifierDeclaration | semanticTokenModifierResource},
+				// var balance: Int
+				{1, 12, 7, semanticTokenTypeProperty, semanticTokenModifierDeclaration | semanticTokenModifierMutable},
+				{1, 21, 3, semanticTokenTypeType, 0},
+				// self.balance
+				{4, 8, 4, semanticTokenTypeVariable, semanticTokenModifierResource},
+				{4, 13, 7, semanticTokenTypeProperty, semanticTokenModifierMutable},
+				// fun old
+				{8, 12, 3, semanticTokenTypeMethod, semanticTokenModifierDeclaration | semanticTokenModifierDeprecated},
+				// fun test(cap: Capability<&R>)
+				{11, 8, 4, semanticTokenTypeFunction, semanticTokenModifierDeclaration},
+				{11, 13, 3, semanticTokenTypeParameter, semanticTokenModifierDeclaration | semanticTokenModifierCapability},
+				{11, 18, 10, semanticTokenTypeType, semanticTokenModifierCapability},
+				{11, 30, 1, semanticTokenTypeClass, semanticTokenModifierResource},
+				// let r <- create R()
+				{12, 8, 1, semanticTokenTypeVariable, semanticTokenModifierDeclaration | semanticTokenModifierResource},
+				{12, 20, 1, semanticTokenTypeClass, semanticTokenModifierResource},
+				// let ref = &r as &R
+				{13, 8, 3, semanticTokenTypeVariable, semanticTokenModifierDeclaration | semanticTokenModifierReference},
+				{13, 15, 1, semanticTokenTypeVariable, semanticTokenModifierResource},
+				{13, 21, 1, semanticTokenTypeClass, semanticTokenModifierResource},
+				// ref.old()
+				{14, 4, 3, semanticTokenTypeVariable, semanticTokenModifierReference},
+				{14, 8, 3, semanticTokenTypeMethod, semanticTokenModifierDeprecated},
+				// destroy r
+				{15, 12, 1, semanticTokenTypeVariable, semanticTokenModifierResource},
+			},
+			decodeSemanticTokens(t, tokens.Data),
+		)
+	})
+
+	t.Run("range", func(t *testing.T) {
+
+		t.Parallel()
+
+		server := newServer(t)
+
+		tokens, err := server.SemanticTokensRange(
+			nil,
+			&protocol.SemanticTokensRangeParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 14, Character: 0},
+					End:   protocol.Position{Line: 15, Character: 0},
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]decodedSemanticToken{
+				{14, 4, 3, semanticTokenTypeVariable, semanticTokenModifierReference},
+				{14, 8, 3, semanticTokenTypeMethod, semanticTokenModifierDeprecated},
+			},
+			decodeSemanticTokens(t, tokens.Data),
+		)
+	})
+
+	t.Run("unknown document", func(t *testing.T) {
+
+		t.Parallel()
+
+		server, err := NewServer()
+		require.NoError(t, err)
+
+		tokens, err := server.SemanticTokensFull(
+			nil,
+			&protocol.SemanticTokensParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, tokens.Data)
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -41,6 +42,7 @@ import (
 	"github.com/onflow/cadence/runtime/sema"
 	"github.com/onflow/cadence/runtime/stdlib"
 	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
 	"golang.org/x/exp/maps"
 
 	"github.com/onflow/cadence/languageserver/conversion"
@@ -360,9 +362,17 @@ func (s *Server) Initialize(
 				TriggerCharacters: []string{"."},
 				ResolveProvider:   true,
 			},
-			DocumentHighlightProvider: true,
-			DocumentSymbolProvider:    true,
-			RenameProvider:            true,
+			DocumentHighlightProvider:       true,
+			DocumentSymbolProvider:          true,
+			RenameProvider:                  true,
+			ReferencesProvider:              true,
+			DocumentFormattingProvider:      true,
+			DocumentRangeFormattingProvider: true,
+			SemanticTokensProvider: protocol.SemanticTokensOptions{
+				Legend: semanticTokensLegend(),
+				Range:  true,
+				Full:   true,
+			},
 			SignatureHelpProvider: protocol.SignatureHelpOptions{
 				TriggerCharacters: []string{"("},
 			},
@@ -606,16 +616,12 @@ func (s *Server) Hover(
 	occurrence := checker.Occurrences.Find(position)
 
 	if occurrence == nil || occurrence.Origin == nil {
-		return nil, nil
+		return stringTemplateHover(checker, position), nil
 	}
 
 	var markup strings.Builder
 
-	_, _ = fmt.Fprintf(
-		&markup,
-		"**Type**\n\n```cadence\n%s\n```\n",
-		documentType(occurrence.Origin.Type),
-	)
+	writeTypeMarkup(&markup, occurrence.Origin.Type)
 
 	docString := occurrence.Origin.DocString
 	if docString != "" {
@@ -633,6 +639,61 @@ func (s *Server) Hover(
 	return &protocol.Hover{Contents: contents}, nil
 }
 
+// stringTemplateHover returns the type of the interpolated expression of a string template
+// at the given position, if any.
+//
+func stringTemplateHover(checker *sema.Checker, position sema.Position) *protocol.Hover {
+	var valueType sema.Type
+
+	ast.Inspect(checker.Program, func(element ast.Element) bool {
+		stringTemplate, ok := element.(*ast.StringTemplateExpression)
+		if !ok {
+			return true
+		}
+
+		valueTypes := checker.Elaboration.StringTemplateExpressionValueTypes[stringTemplate]
+
+		for i, expression := range stringTemplate.Expressions {
+			if i >= len(valueTypes) {
+				break
+			}
+
+			startPosition := sema.ASTToSemaPosition(expression.StartPosition())
+			endPosition := sema.ASTToSemaPosition(expression.EndPosition(nil))
+
+			if startPosition.Compare(position) <= 0 && position.Compare(endPosition) <= 0 {
+				// Nested string templates are inspected later,
+				// so the innermost interpolated expression is found
+				valueType = valueTypes[i]
+			}
+		}
+
+		return true
+	})
+
+	if valueType == nil {
+		return nil
+	}
+
+	var markup strings.Builder
+	writeTypeMarkup(&markup, valueType)
+
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: markup.String(),
+		},
+	}
+}
+
+func writeTypeMarkup(markup *strings.Builder, ty sema.Type) {
+	_, _ = fmt.Fprintf(
+		markup,
+		"**Type**\n\n```cadence\n%s\n```\n",
+		documentType(ty),
+	)
+}
+
 func documentType(ty sema.Type) string {
 	if functionType, ok := ty.(*sema.FunctionType); ok {
 		return documentFunctionType(functionType)
@@ -830,6 +891,11 @@ func (s *Server) DocumentHighlight(
 	return documentHighlights, nil
 }
 
+// Rename renames the declaration at the given position,
+// and all references to it, in all documents which import the declaration.
+//
+// The declaration must be declared in an open document or a file,
+// e.g. declarations of programs imported from an address cannot be renamed.
 func (s *Server) Rename(
 	_ protocol.Conn,
 	params *protocol.RenameParams,
@@ -837,51 +903,86 @@ func (s *Server) Rename(
 	*protocol.WorkspaceEdit,
 	error,
 ) {
-	uri := params.TextDocument.URI
-	checker := s.checkerForDocument(uri)
-	if checker == nil {
+	declaration := s.findDeclaration(params.TextDocument.URI, params.Position)
+	if declaration == nil {
 		return nil, nil
 	}
 
-	position := conversion.ProtocolToSemaPosition(params.Position)
-	occurrences := checker.Occurrences.FindAll(position)
-	// If there are no occurrences,
-	// then try the preceding position
-	if len(occurrences) == 0 && position.Column > 0 {
-		previousPosition := position
-		previousPosition.Column -= 1
-		occurrences = checker.Occurrences.FindAll(previousPosition)
-	}
+	uris := s.locationURIs()
 
-	textEdits := make([]protocol.TextEdit, 0)
+	if _, ok := uris[declaration.location.ID()]; !ok {
+		return nil, fmt.Errorf(
+			"cannot rename %s: declared in %s, which is not an open document or file",
+			declaration.identifier,
+			declaration.location,
+		)
+	}
 
-	for _, occurrence := range occurrences {
+	references := s.declarationReferences(declaration, uris, true)
 
-		origin := occurrence.Origin
-		if origin == nil || origin.StartPos == nil || origin.EndPos == nil {
-			continue
-		}
+	changes := make(map[protocol.DocumentURI][]protocol.TextEdit, len(references))
 
-		for _, occurrenceRange := range origin.Occurrences {
+	for uri, ranges := range references {
+		textEdits := make([]protocol.TextEdit, 0, len(ranges))
+		for _, textRange := range ranges {
 			textEdits = append(textEdits,
 				protocol.TextEdit{
-					Range: conversion.ASTToProtocolRange(
-						occurrenceRange.StartPos,
-						occurrenceRange.EndPos,
-					),
+					Range:   textRange,
 					NewText: params.NewName,
 				},
 			)
 		}
+		changes[uri] = textEdits
 	}
 
 	return &protocol.WorkspaceEdit{
-		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
-			uri: textEdits,
-		},
+		Changes: changes,
 	}, nil
 }
 
+// References finds all references to the declaration at the given position,
+// in all open documents and imported programs.
+func (s *Server) References(
+	_ protocol.Conn,
+	params *protocol.ReferenceParams,
+) (
+	[]*protocol.Location,
+	error,
+) {
+	var references map[protocol.DocumentURI][]protocol.Range
+
+	declaration := s.findDeclaration(params.TextDocument.URI, params.Position)
+	if declaration != nil {
+		references = s.declarationReferences(
+			declaration,
+			s.locationURIs(),
+			params.Context.IncludeDeclaration,
+		)
+	}
+
+	// NOTE: Always initialize to an empty slice, i.e DON'T use nil:
+	// The later will be ignored instead of being treated as no items
+	locations := make([]*protocol.Location, 0)
+
+	uris := maps.Keys(references)
+	sort.Slice(uris, func(i, j int) bool {
+		return uris[i] < uris[j]
+	})
+
+	for _, uri := range uris {
+		for _, textRange := range references[uri] {
+			locations = append(locations,
+				&protocol.Location{
+					URI:   uri,
+					Range: textRange,
+				},
+			)
+		}
+	}
+
+	return locations, nil
+}
+
 func (s *Server) CodeAction(
 	conn protocol.Conn,
 	params *protocol.CodeActionParams,
@@ -1755,7 +1856,10 @@ func (*Server) Exit(_ protocol.Conn) error {
 
 const filePrefix = "file://"
 
-var lintingAnalyzers = maps.Values(linter.Analyzers)
+var lintingAnalyzers = append(
+	maps.Values(linter.Analyzers),
+	maps.Values(analyzers.SecurityAnalyzers)...,
+)
 
 // getDiagnostics parses and checks the given file and generates diagnostics
 // indicating each syntax or semantic error. Returns a list of diagnostics
@@ -3084,68 +3188,112 @@ func convertDiagnostic(
 
 	protocolRange := conversion.ASTToProtocolRange(linterDiagnostic.StartPos, linterDiagnostic.EndPos)
 
-	var protocolDiagnostic protocol.Diagnostic
-	var message string
+	message := linterDiagnostic.Message
+	if linterDiagnostic.Category == linter.ReplacementCategory {
+		message = fmt.Sprintf("%s `%s`", linterDiagnostic.Message, linterDiagnostic.SecondaryMessage)
+	}
+
+	// protocol.SeverityHint doesn't look prominent enough in VS Code,
+	// only the first character of the range is highlighted.
+	severity := protocol.SeverityInformation
+	if linterDiagnostic.Category == analyzers.SecurityCategory {
+		severity = protocol.SeverityWarning
+	}
 
-	var codeActionsResolver func() []*protocol.CodeAction
+	protocolDiagnostic := protocol.Diagnostic{
+		Message:  message,
+		Severity: severity,
+		Range:    protocolRange,
+	}
+
+	suggestedFixes := linterDiagnostic.SuggestedFixes
+	if len(suggestedFixes) == 0 {
+		suggestedFixes = legacySuggestedFixes(linterDiagnostic)
+	}
+
+	if len(suggestedFixes) == 0 {
+		return protocolDiagnostic, nil
+	}
+
+	codeActionsResolver := func() []*protocol.CodeAction {
+		codeActions := make([]*protocol.CodeAction, 0, len(suggestedFixes))
+
+		for i, suggestedFix := range suggestedFixes {
+			textEdits := make([]protocol.TextEdit, 0, len(suggestedFix.TextEdits))
+			for _, textEdit := range suggestedFix.TextEdits {
+				textEdits = append(textEdits, convertTextEdit(textEdit))
+			}
+
+			codeActions = append(codeActions, &protocol.CodeAction{
+				Title:       suggestedFix.Message,
+				Kind:        protocol.QuickFix,
+				Diagnostics: []protocol.Diagnostic{protocolDiagnostic},
+				Edit: protocol.WorkspaceEdit{
+					Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+						uri: textEdits,
+					},
+				},
+				IsPreferred: i == 0,
+			})
+		}
+
+		return codeActions
+	}
 
+	return protocolDiagnostic, codeActionsResolver
+}
+
+// legacySuggestedFixes returns the suggested fixes for diagnostics
+// of linters which do not provide suggested fixes yet,
+// but encode the fix in the category and secondary message.
+//
+func legacySuggestedFixes(linterDiagnostic analysis.Diagnostic) []analysis.SuggestedFix {
 	switch linterDiagnostic.Category {
 	case linter.ReplacementCategory:
-		codeActionsResolver = func() []*protocol.CodeAction {
-			return []*protocol.CodeAction{
-				{
-					Title:       fmt.Sprintf("%s `%s`", linterDiagnostic.Message, linterDiagnostic.SecondaryMessage),
-					Kind:        protocol.QuickFix,
-					Diagnostics: []protocol.Diagnostic{protocolDiagnostic},
-					Edit: protocol.WorkspaceEdit{
-						Changes: map[protocol.DocumentURI][]protocol.TextEdit{
-							uri: {
-								{
-									Range:   protocolRange,
-									NewText: linterDiagnostic.SecondaryMessage,
-								},
-							},
-						},
+		return []analysis.SuggestedFix{
+			{
+				Message: fmt.Sprintf("%s `%s`", linterDiagnostic.Message, linterDiagnostic.SecondaryMessage),
+				TextEdits: []analysis.TextEdit{
+					{
+						Replacement: linterDiagnostic.SecondaryMessage,
+						Range:       linterDiagnostic.Range,
 					},
-					IsPreferred: true,
 				},
-			}
+			},
 		}
-		message = fmt.Sprintf("%s `%s`", linterDiagnostic.Message, linterDiagnostic.SecondaryMessage)
+
 	case linter.RemovalCategory:
-		codeActionsResolver = func() []*protocol.CodeAction {
-			return []*protocol.CodeAction{
-				{
-					Title:       "Remove unnecessary code",
-					Kind:        protocol.QuickFix,
-					Diagnostics: []protocol.Diagnostic{protocolDiagnostic},
-					Edit: protocol.WorkspaceEdit{
-						Changes: map[protocol.DocumentURI][]protocol.TextEdit{
-							uri: {
-								{
-									Range:   protocolRange,
-									NewText: "",
-								},
-							},
-						},
+		return []analysis.SuggestedFix{
+			{
+				Message: "Remove unnecessary code",
+				TextEdits: []analysis.TextEdit{
+					{
+						Range: linterDiagnostic.Range,
 					},
-					IsPreferred: true,
 				},
-			}
+			},
 		}
 	}
-	
-	if message == "" {
-		message = linterDiagnostic.Message
-	}
 
-	protocolDiagnostic = protocol.Diagnostic{
-		Message: message,
-		// protocol.SeverityHint doesn't look prominent enough in VS Code,
-		// only the first character of the range is highlighted.
-		Severity: protocol.SeverityInformation,
-		Range:    protocolRange,
+	return nil
+}
+
+// convertTextEdit converts an analysis text edit to a protocol text edit.
+//
+func convertTextEdit(textEdit analysis.TextEdit) protocol.TextEdit {
+	if textEdit.Insertion != "" {
+		position := conversion.ASTToProtocolPosition(textEdit.StartPos)
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: position,
+				End:   position,
+			},
+			NewText: textEdit.Insertion,
+		}
 	}
 
-	return protocolDiagnostic, codeActionsResolver
+	return protocol.TextEdit{
+		Range:   conversion.ASTToProtocolRange(textEdit.StartPos, textEdit.EndPos),
+		NewText: textEdit.Replacement,
+	}
 }
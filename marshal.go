@@ -0,0 +1,1003 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// marshalTagName is the name of the struct tag which specifies
+// the name of the Cadence field a Go struct field is marshalled to and unmarshalled from.
+// The tag value "-" excludes the Go struct field.
+//
+const marshalTagName = "cadence"
+
+var valueInterfaceType = reflect.TypeOf((*Value)(nil)).Elem()
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// Marshal returns the Cadence value of the given type for the given Go value.
+//
+// Marshal works similar to json.Marshal, but is driven by the given Cadence type:
+//
+//   - Go values which already are Cadence values are returned as-is
+//   - Optional types accept nil pointers, interfaces, maps and slices as nil,
+//     and all other values as the inner type
+//   - Bool, String, and Character types accept Go booleans and strings
+//   - Address types accept [8]byte arrays and byte slices of length 8
+//   - Integer types accept Go integers and big.Int, and report values out of range
+//   - Fixed-point types accept strings in decimal notation, e.g. "1.5",
+//     and Go integers, which are interpreted as the scaled integer representation
+//   - Array types accept Go slices and arrays
+//   - Dictionary types accept Go maps. The pairs are ordered by the key's string representation
+//   - Composite types accept Go structs. The Go struct field of a Cadence field is either
+//     the field with a `cadence:"name"` tag, or the field with the same name (case-insensitively).
+//     Enums also accept Go integers as the raw value
+//   - AnyStruct and AnyResource types accept the Go values supported by NewValue
+//
+func Marshal(value any, typ Type) (Value, error) {
+	return marshal(reflect.ValueOf(value), typ)
+}
+
+// MarshalTypeError is returned by Marshal when a Go value cannot be marshalled
+// to a Cadence value of the given type.
+//
+type MarshalTypeError struct {
+	GoType reflect.Type
+	Type   Type
+}
+
+func (e MarshalTypeError) Error() string {
+	goType := "nil"
+	if e.GoType != nil {
+		goType = e.GoType.String()
+	}
+	return fmt.Sprintf(
+		"cadence: cannot marshal Go value of type %s to Cadence type %s",
+		goType,
+		typeID(e.Type),
+	)
+}
+
+func typeID(typ Type) string {
+	if typ == nil {
+		return "nil"
+	}
+	return typ.ID()
+}
+
+func marshal(rv reflect.Value, typ Type) (Value, error) {
+
+	rv = indirectInterface(rv)
+
+	if optionalType, ok := typ.(OptionalType); ok {
+		if isNil(rv) {
+			return NewOptional(nil), nil
+		}
+
+		if rv.Type().Implements(valueInterfaceType) {
+			if optional, ok := rv.Interface().(Optional); ok {
+				return optional, nil
+			}
+		}
+
+		inner, err := marshal(rv, optionalType.Type)
+		if err != nil {
+			return nil, err
+		}
+		return NewOptional(inner), nil
+	}
+
+	// Dereference pointers,
+	// and return values which are already Cadence values as-is
+
+	for {
+		if !rv.IsValid() {
+			return nil, MarshalTypeError{Type: typ}
+		}
+
+		if rv.Kind() != reflect.Ptr {
+			break
+		}
+
+		if rv.Type().Elem() == bigIntType {
+			break
+		}
+
+		if rv.IsNil() {
+			return nil, MarshalTypeError{
+				GoType: rv.Type(),
+				Type:   typ,
+			}
+		}
+
+		rv = indirectInterface(rv.Elem())
+	}
+
+	if rv.Type().Implements(valueInterfaceType) {
+		return rv.Interface().(Value), nil
+	}
+
+	typeError := MarshalTypeError{
+		GoType: rv.Type(),
+		Type:   typ,
+	}
+
+	switch typ := typ.(type) {
+	case AnyType, AnyStructType, AnyResourceType:
+		return NewValue(rv.Interface())
+
+	case VoidType:
+		return NewVoid(), nil
+
+	case BoolType:
+		if rv.Kind() != reflect.Bool {
+			return nil, typeError
+		}
+		return NewBool(rv.Bool()), nil
+
+	case StringType:
+		if rv.Kind() != reflect.String {
+			return nil, typeError
+		}
+		return NewString(rv.String())
+
+	case CharacterType:
+		if rv.Kind() != reflect.String {
+			return nil, typeError
+		}
+		return NewCharacter(rv.String())
+
+	case AddressType:
+		if !isByteSequence(rv) || rv.Len() != AddressLength {
+			return nil, typeError
+		}
+		var address Address
+		reflect.Copy(reflect.ValueOf(address[:]), rv)
+		return address, nil
+
+	case IntType, Int8Type, Int16Type, Int32Type, Int64Type, Int128Type, Int256Type,
+		UIntType, UInt8Type, UInt16Type, UInt32Type, UInt64Type, UInt128Type, UInt256Type,
+		Word8Type, Word16Type, Word32Type, Word64Type:
+
+		integer, ok := goIntegerToBig(rv)
+		if !ok {
+			return nil, typeError
+		}
+		return newInteger(integer, typ)
+
+	case Fix64Type:
+		switch rv.Kind() {
+		case reflect.String:
+			return NewFix64(rv.String())
+		default:
+			integer, ok := goIntegerToBig(rv)
+			if !ok {
+				return nil, typeError
+			}
+			if !integer.IsInt64() {
+				return nil, newOutOfRangeError(integer, typ)
+			}
+			return Fix64(integer.Int64()), nil
+		}
+
+	case UFix64Type:
+		switch rv.Kind() {
+		case reflect.String:
+			return NewUFix64(rv.String())
+		default:
+			integer, ok := goIntegerToBig(rv)
+			if !ok {
+				return nil, typeError
+			}
+			if !integer.IsUint64() {
+				return nil, newOutOfRangeError(integer, typ)
+			}
+			return UFix64(integer.Uint64()), nil
+		}
+
+	case VariableSizedArrayType:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, typeError
+		}
+		return marshalArray(rv, typ)
+
+	case ConstantSizedArrayType:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, typeError
+		}
+		if uint(rv.Len()) != typ.Size {
+			return nil, fmt.Errorf(
+				"cadence: cannot marshal Go value of length %d to Cadence type %s",
+				rv.Len(),
+				typ.ID(),
+			)
+		}
+		return marshalArray(rv, typ)
+
+	case DictionaryType:
+		if rv.Kind() != reflect.Map {
+			return nil, typeError
+		}
+		return marshalDictionary(rv, typ)
+
+	case *EnumType:
+		if rv.Kind() != reflect.Struct {
+			rawValue, err := marshal(rv, typ.RawType)
+			if err != nil {
+				return nil, err
+			}
+			return NewEnum([]Value{rawValue}).WithType(typ), nil
+		}
+		return marshalComposite(rv, typ)
+
+	case *StructType, *ResourceType, *EventType, *ContractType:
+		if rv.Kind() != reflect.Struct {
+			return nil, typeError
+		}
+		return marshalComposite(rv, typ.(CompositeType))
+	}
+
+	return nil, typeError
+}
+
+func marshalArray(rv reflect.Value, arrayType ArrayType) (Value, error) {
+	elementType := arrayType.Element()
+
+	values := make([]Value, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		value, err := marshal(rv.Index(i), elementType)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return NewArray(values).WithType(arrayType), nil
+}
+
+func marshalDictionary(rv reflect.Value, dictionaryType DictionaryType) (Value, error) {
+	pairs := make([]KeyValuePair, 0, rv.Len())
+
+	iterator := rv.MapRange()
+	for iterator.Next() {
+		key, err := marshal(iterator.Key(), dictionaryType.KeyType)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := marshal(iterator.Value(), dictionaryType.ElementType)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(
+			pairs,
+			KeyValuePair{
+				Key:   key,
+				Value: value,
+			},
+		)
+	}
+
+	// Go maps are unordered, sort the pairs to produce a deterministic result
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key.String() < pairs[j].Key.String()
+	})
+
+	return NewDictionary(pairs).WithType(dictionaryType), nil
+}
+
+func marshalComposite(rv reflect.Value, compositeType CompositeType) (Value, error) {
+	compositeFields := compositeType.CompositeFields()
+
+	fields := make([]Value, len(compositeFields))
+
+	for i, compositeField := range compositeFields {
+		goField, ok := findGoField(rv, compositeField.Identifier)
+		if !ok {
+			return nil, fmt.Errorf(
+				"cadence: cannot marshal Go value of type %s to Cadence type %s: missing field %s",
+				rv.Type(),
+				compositeType.ID(),
+				compositeField.Identifier,
+			)
+		}
+
+		value, err := marshal(goField, compositeField.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = value
+	}
+
+	switch compositeType := compositeType.(type) {
+	case *StructType:
+		return NewStruct(fields).WithType(compositeType), nil
+	case *ResourceType:
+		return NewResource(fields).WithType(compositeType), nil
+	case *EventType:
+		return NewEvent(fields).WithType(compositeType), nil
+	case *ContractType:
+		return NewContract(fields).WithType(compositeType), nil
+	case *EnumType:
+		return NewEnum(fields).WithType(compositeType), nil
+	}
+
+	return nil, MarshalTypeError{
+		GoType: rv.Type(),
+		Type:   compositeType,
+	}
+}
+
+func newInteger(integer *big.Int, typ Type) (Value, error) {
+	switch typ.(type) {
+	case IntType:
+		return NewIntFromBig(integer), nil
+
+	case Int8Type:
+		value, ok := bigToInt64(integer, 8)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewInt8(int8(value)), nil
+
+	case Int16Type:
+		value, ok := bigToInt64(integer, 16)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewInt16(int16(value)), nil
+
+	case Int32Type:
+		value, ok := bigToInt64(integer, 32)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewInt32(int32(value)), nil
+
+	case Int64Type:
+		value, ok := bigToInt64(integer, 64)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewInt64(value), nil
+
+	case Int128Type:
+		return NewInt128FromBig(integer)
+
+	case Int256Type:
+		return NewInt256FromBig(integer)
+
+	case UIntType:
+		return NewUIntFromBig(integer)
+
+	case UInt8Type:
+		value, ok := bigToUint64(integer, 8)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewUInt8(uint8(value)), nil
+
+	case UInt16Type:
+		value, ok := bigToUint64(integer, 16)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewUInt16(uint16(value)), nil
+
+	case UInt32Type:
+		value, ok := bigToUint64(integer, 32)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewUInt32(uint32(value)), nil
+
+	case UInt64Type:
+		value, ok := bigToUint64(integer, 64)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewUInt64(value), nil
+
+	case UInt128Type:
+		return NewUInt128FromBig(integer)
+
+	case UInt256Type:
+		return NewUInt256FromBig(integer)
+
+	case Word8Type:
+		value, ok := bigToUint64(integer, 8)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewWord8(uint8(value)), nil
+
+	case Word16Type:
+		value, ok := bigToUint64(integer, 16)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewWord16(uint16(value)), nil
+
+	case Word32Type:
+		value, ok := bigToUint64(integer, 32)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewWord32(uint32(value)), nil
+
+	case Word64Type:
+		value, ok := bigToUint64(integer, 64)
+		if !ok {
+			return nil, newOutOfRangeError(integer, typ)
+		}
+		return NewWord64(value), nil
+	}
+
+	return nil, MarshalTypeError{
+		GoType: bigIntType,
+		Type:   typ,
+	}
+}
+
+func newOutOfRangeError(integer *big.Int, typ Type) error {
+	return fmt.Errorf(
+		"cadence: cannot marshal %s to Cadence type %s: value out of range",
+		integer,
+		typ.ID(),
+	)
+}
+
+// Unmarshal stores the given Cadence value in the Go value pointed to by target.
+//
+// Unmarshal works similar to json.Unmarshal, and is the inverse of Marshal:
+//
+//   - Targets which are Cadence value types, or interfaces implemented by the value,
+//     e.g. Value, are set to the value as-is
+//   - Empty interface targets are set to the Go value returned by Value.ToGoValue
+//   - Pointer targets are allocated if necessary. Nil optionals set pointers to nil
+//   - Bool, String, and Character values are stored in Go booleans and strings
+//   - Address values are stored in [8]byte arrays, byte slices, and strings (hex)
+//   - Integer values are stored in Go integers and big.Int, and report values out of range
+//   - Fixed-point values are stored in strings in decimal notation, e.g. "1.5",
+//     and Go integers and big.Int, as the scaled integer representation
+//   - Array values are stored in Go slices and arrays
+//   - Dictionary values are stored in Go maps
+//   - Composite values are stored in Go structs. The Go struct field of a Cadence field is either
+//     the field with a `cadence:"name"` tag, or the field with the same name (case-insensitively).
+//     Cadence fields without a corresponding Go struct field are ignored.
+//     Enum values can also be stored in Go integers, as the raw value
+//
+func Unmarshal(value Value, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return InvalidUnmarshalError{
+			Type: reflect.TypeOf(target),
+		}
+	}
+
+	return unmarshal(value, rv.Elem())
+}
+
+// InvalidUnmarshalError is returned by Unmarshal when the given target is not a non-nil pointer.
+//
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "cadence: cannot unmarshal into nil"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return fmt.Sprintf("cadence: cannot unmarshal into non-pointer %s", e.Type)
+	}
+
+	return fmt.Sprintf("cadence: cannot unmarshal into nil %s", e.Type)
+}
+
+// UnmarshalTypeError is returned by Unmarshal when a Cadence value
+// cannot be stored in a Go value of the given type.
+//
+type UnmarshalTypeError struct {
+	Value  Value
+	GoType reflect.Type
+}
+
+func (e UnmarshalTypeError) Error() string {
+	return fmt.Sprintf(
+		"cadence: cannot unmarshal Cadence value %s into Go value of type %s",
+		e.Value,
+		e.GoType,
+	)
+}
+
+func unmarshal(value Value, rv reflect.Value) error {
+
+	valueType := reflect.TypeOf(value)
+
+	// Cadence values are stored as-is,
+	// e.g. in targets of type Value, or a concrete value type like Int
+
+	if rv.Kind() != reflect.Interface || rv.NumMethod() > 0 {
+		if valueType != nil && valueType.AssignableTo(rv.Type()) {
+			rv.Set(reflect.ValueOf(value))
+			return nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() > 0 {
+			break
+		}
+		if value == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		goValue := value.ToGoValue()
+		if goValue == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(goValue))
+		}
+		return nil
+
+	case reflect.Ptr:
+		if optional, ok := value.(Optional); ok && optional.Value == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshal(value, rv.Elem())
+	}
+
+	typeError := UnmarshalTypeError{
+		Value:  value,
+		GoType: rv.Type(),
+	}
+
+	switch value := value.(type) {
+	case Optional:
+		if value.Value == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		return unmarshal(value.Value, rv)
+
+	case Bool:
+		if rv.Kind() != reflect.Bool {
+			return typeError
+		}
+		rv.SetBool(bool(value))
+		return nil
+
+	case String:
+		if rv.Kind() != reflect.String {
+			return typeError
+		}
+		rv.SetString(string(value))
+		return nil
+
+	case Character:
+		if rv.Kind() != reflect.String {
+			return typeError
+		}
+		rv.SetString(string(value))
+		return nil
+
+	case Address:
+		switch {
+		case rv.Kind() == reflect.String:
+			rv.SetString(value.String())
+			return nil
+
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			rv.SetBytes(value.Bytes())
+			return nil
+
+		case isByteSequence(rv) && rv.Len() == AddressLength:
+			reflect.Copy(rv, reflect.ValueOf(value[:]))
+			return nil
+		}
+		return typeError
+
+	case Fix64:
+		if rv.Kind() == reflect.String {
+			rv.SetString(value.String())
+			return nil
+		}
+		return unmarshalInteger(value, big.NewInt(int64(value)), rv)
+
+	case UFix64:
+		if rv.Kind() == reflect.String {
+			rv.SetString(value.String())
+			return nil
+		}
+		return unmarshalInteger(value, new(big.Int).SetUint64(uint64(value)), rv)
+
+	case Array:
+		return unmarshalArray(value, rv)
+
+	case Dictionary:
+		return unmarshalDictionary(value, rv)
+
+	case Enum:
+		if rv.Kind() != reflect.Struct {
+			rawValue, ok := compositeField(value.EnumType, value.Fields, sema.EnumRawValueFieldName)
+			if !ok {
+				return typeError
+			}
+			return unmarshal(rawValue, rv)
+		}
+		return unmarshalComposite(value, value.EnumType, value.Fields, rv)
+
+	case Struct:
+		return unmarshalComposite(value, value.StructType, value.Fields, rv)
+
+	case Resource:
+		return unmarshalComposite(value, value.ResourceType, value.Fields, rv)
+
+	case Event:
+		return unmarshalComposite(value, value.EventType, value.Fields, rv)
+
+	case Contract:
+		return unmarshalComposite(value, value.ContractType, value.Fields, rv)
+	}
+
+	if integer, ok := integerToBig(value); ok {
+		return unmarshalInteger(value, integer, rv)
+	}
+
+	return typeError
+}
+
+func unmarshalInteger(value Value, integer *big.Int, rv reflect.Value) error {
+
+	typeError := UnmarshalTypeError{
+		Value:  value,
+		GoType: rv.Type(),
+	}
+
+	if rv.Type() == bigIntType {
+		rv.Addr().Interface().(*big.Int).Set(integer)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !integer.IsInt64() || rv.OverflowInt(integer.Int64()) {
+			return fmt.Errorf(
+				"cadence: cannot unmarshal Cadence value %s into Go value of type %s: value out of range",
+				value,
+				rv.Type(),
+			)
+		}
+		rv.SetInt(integer.Int64())
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !integer.IsUint64() || rv.OverflowUint(integer.Uint64()) {
+			return fmt.Errorf(
+				"cadence: cannot unmarshal Cadence value %s into Go value of type %s: value out of range",
+				value,
+				rv.Type(),
+			)
+		}
+		rv.SetUint(integer.Uint64())
+		return nil
+	}
+
+	return typeError
+}
+
+func unmarshalArray(array Array, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(rv.Type(), len(array.Values), len(array.Values))
+		for i, element := range array.Values {
+			err := unmarshal(element, slice.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+
+	case reflect.Array:
+		if rv.Len() != len(array.Values) {
+			return fmt.Errorf(
+				"cadence: cannot unmarshal Cadence array of length %d into Go value of type %s",
+				len(array.Values),
+				rv.Type(),
+			)
+		}
+		for i, element := range array.Values {
+			err := unmarshal(element, rv.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return UnmarshalTypeError{
+		Value:  array,
+		GoType: rv.Type(),
+	}
+}
+
+func unmarshalDictionary(dictionary Dictionary, rv reflect.Value) error {
+	if rv.Kind() != reflect.Map {
+		return UnmarshalTypeError{
+			Value:  dictionary,
+			GoType: rv.Type(),
+		}
+	}
+
+	mapType := rv.Type()
+
+	result := reflect.MakeMapWithSize(mapType, len(dictionary.Pairs))
+
+	for _, pair := range dictionary.Pairs {
+		key := reflect.New(mapType.Key()).Elem()
+		err := unmarshal(pair.Key, key)
+		if err != nil {
+			return err
+		}
+
+		element := reflect.New(mapType.Elem()).Elem()
+		err = unmarshal(pair.Value, element)
+		if err != nil {
+			return err
+		}
+
+		result.SetMapIndex(key, element)
+	}
+
+	rv.Set(result)
+	return nil
+}
+
+func unmarshalComposite(value Value, compositeType CompositeType, fields []Value, rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return UnmarshalTypeError{
+			Value:  value,
+			GoType: rv.Type(),
+		}
+	}
+
+	if compositeType == nil || reflect.ValueOf(compositeType).IsNil() {
+		return fmt.Errorf(
+			"cadence: cannot unmarshal Cadence value %s into Go value of type %s: missing composite type",
+			value,
+			rv.Type(),
+		)
+	}
+
+	compositeFields := compositeType.CompositeFields()
+	if len(compositeFields) != len(fields) {
+		return fmt.Errorf(
+			"cadence: cannot unmarshal Cadence value %s into Go value of type %s: mismatched number of fields",
+			value,
+			rv.Type(),
+		)
+	}
+
+	for i, compositeField := range compositeFields {
+		goField, ok := findGoField(rv, compositeField.Identifier)
+		if !ok {
+			continue
+		}
+
+		err := unmarshal(fields[i], goField)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compositeField(compositeType CompositeType, fields []Value, name string) (Value, bool) {
+	if compositeType == nil || reflect.ValueOf(compositeType).IsNil() {
+		return nil, false
+	}
+
+	for i, field := range compositeType.CompositeFields() {
+		if field.Identifier == name && i < len(fields) {
+			return fields[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// findGoField returns the field of the given Go struct value
+// which corresponds to the Cadence field with the given name.
+// A field with a matching tag is preferred over a field with a matching name.
+//
+func findGoField(rv reflect.Value, name string) (reflect.Value, bool) {
+	structType := rv.Type()
+
+	fieldIndex := -1
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup(marshalTagName)
+		if tag == "-" {
+			continue
+		}
+
+		if hasTag && tag != "" {
+			if tag == name {
+				return rv.Field(i), true
+			}
+			continue
+		}
+
+		if fieldIndex < 0 && strings.EqualFold(field.Name, name) {
+			fieldIndex = i
+		}
+	}
+
+	if fieldIndex < 0 {
+		return reflect.Value{}, false
+	}
+
+	return rv.Field(fieldIndex), true
+}
+
+func indirectInterface(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func isNil(rv reflect.Value) bool {
+	if !rv.IsValid() {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+func isByteSequence(rv reflect.Value) bool {
+	return (rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice) &&
+		rv.Type().Elem().Kind() == reflect.Uint8
+}
+
+// goIntegerToBig returns the given Go integer or big.Int as a new big.Int.
+//
+func goIntegerToBig(rv reflect.Value) (*big.Int, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(rv.Uint()), true
+
+	case reflect.Ptr:
+		if rv.Type().Elem() != bigIntType || rv.IsNil() {
+			return nil, false
+		}
+		return new(big.Int).Set(rv.Interface().(*big.Int)), true
+
+	case reflect.Struct:
+		if rv.Type() != bigIntType {
+			return nil, false
+		}
+		integer := rv.Interface().(big.Int)
+		return new(big.Int).Set(&integer), true
+	}
+
+	return nil, false
+}
+
+// integerToBig returns the given Cadence integer value as a big.Int.
+//
+func integerToBig(value Value) (*big.Int, bool) {
+	switch value := value.(type) {
+	case Int:
+		return value.Big(), true
+	case Int8:
+		return big.NewInt(int64(value)), true
+	case Int16:
+		return big.NewInt(int64(value)), true
+	case Int32:
+		return big.NewInt(int64(value)), true
+	case Int64:
+		return big.NewInt(int64(value)), true
+	case Int128:
+		return value.Big(), true
+	case Int256:
+		return value.Big(), true
+	case UInt:
+		return value.Big(), true
+	case UInt8:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt16:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt32:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt128:
+		return value.Big(), true
+	case UInt256:
+		return value.Big(), true
+	case Word8:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word16:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word32:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	}
+
+	return nil, false
+}
+
+// bigToInt64 returns the given big.Int as an int64,
+// if it fits into a signed integer of the given bit size.
+//
+func bigToInt64(integer *big.Int, bitSize uint) (int64, bool) {
+	if !integer.IsInt64() {
+		return 0, false
+	}
+	value := integer.Int64()
+	shift := 64 - bitSize
+	return value, (value<<shift)>>shift == value
+}
+
+// bigToUint64 returns the given big.Int as an uint64,
+// if it fits into an unsigned integer of the given bit size.
+//
+func bigToUint64(integer *big.Int, bitSize uint) (uint64, bool) {
+	if !integer.IsUint64() {
+		return 0, false
+	}
+	value := integer.Uint64()
+	shift := 64 - bitSize
+	return value, (value<<shift)>>shift == value
+}
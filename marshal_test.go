@@ -0,0 +1,409 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+type marshalTestPoint struct {
+	X int64
+	Y int64 `cadence:"y"`
+}
+
+type marshalTestFoo struct {
+	Name     string             `cadence:"name"`
+	Amount   string             `cadence:"amount"`
+	Balance  *big.Int           `cadence:"balance"`
+	Owner    [8]byte            `cadence:"owner"`
+	Nickname *string            `cadence:"nickname"`
+	Tags     []string           `cadence:"tags"`
+	Scores   map[string]uint8   `cadence:"scores"`
+	Points   []marshalTestPoint `cadence:"points"`
+	Ignored  string             `cadence:"-"`
+}
+
+var marshalTestPointType = &StructType{
+	Location:            utils.TestLocation,
+	QualifiedIdentifier: "Point",
+	Fields: []Field{
+		{Identifier: "x", Type: Int64Type{}},
+		{Identifier: "y", Type: Int64Type{}},
+	},
+}
+
+var marshalTestFooType = &StructType{
+	Location:            utils.TestLocation,
+	QualifiedIdentifier: "Foo",
+	Fields: []Field{
+		{Identifier: "name", Type: StringType{}},
+		{Identifier: "amount", Type: UFix64Type{}},
+		{Identifier: "balance", Type: IntType{}},
+		{Identifier: "owner", Type: AddressType{}},
+		{Identifier: "nickname", Type: OptionalType{Type: StringType{}}},
+		{Identifier: "tags", Type: VariableSizedArrayType{ElementType: StringType{}}},
+		{Identifier: "scores", Type: DictionaryType{KeyType: StringType{}, ElementType: UInt8Type{}}},
+		{Identifier: "points", Type: VariableSizedArrayType{ElementType: marshalTestPointType}},
+	},
+}
+
+func TestMarshalComposite(t *testing.T) {
+
+	t.Parallel()
+
+	foo := marshalTestFoo{
+		Name:    "foo",
+		Amount:  "1.50000000",
+		Balance: big.NewInt(42),
+		Owner:   [8]byte{0, 0, 0, 0, 0, 0, 0, 1},
+		Tags:    []string{"a", "b"},
+		Scores: map[string]uint8{
+			"b": 2,
+			"a": 1,
+		},
+		Points: []marshalTestPoint{
+			{X: 1, Y: 2},
+		},
+		Ignored: "ignored",
+	}
+
+	expected := NewStruct([]Value{
+		String("foo"),
+		UFix64(150_000_000),
+		NewInt(42),
+		NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+		NewOptional(nil),
+		NewArray([]Value{
+			String("a"),
+			String("b"),
+		}).WithType(VariableSizedArrayType{ElementType: StringType{}}),
+		NewDictionary([]KeyValuePair{
+			{Key: String("a"), Value: UInt8(1)},
+			{Key: String("b"), Value: UInt8(2)},
+		}).WithType(DictionaryType{KeyType: StringType{}, ElementType: UInt8Type{}}),
+		NewArray([]Value{
+			NewStruct([]Value{
+				Int64(1),
+				Int64(2),
+			}).WithType(marshalTestPointType),
+		}).WithType(VariableSizedArrayType{ElementType: marshalTestPointType}),
+	}).WithType(marshalTestFooType)
+
+	t.Run("marshal", func(t *testing.T) {
+
+		t.Parallel()
+
+		value, err := Marshal(foo, marshalTestFooType)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+
+		// Pointers are dereferenced
+
+		value, err = Marshal(&foo, marshalTestFooType)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+
+		t.Parallel()
+
+		var result marshalTestFoo
+		err := Unmarshal(expected, &result)
+		require.NoError(t, err)
+
+		expectedFoo := foo
+		expectedFoo.Ignored = ""
+		assert.Equal(t, expectedFoo, result)
+	})
+
+	t.Run("round trip with optional", func(t *testing.T) {
+
+		t.Parallel()
+
+		nickname := "bar"
+
+		fooWithNickname := foo
+		fooWithNickname.Ignored = ""
+		fooWithNickname.Nickname = &nickname
+
+		value, err := Marshal(fooWithNickname, marshalTestFooType)
+		require.NoError(t, err)
+		assert.Equal(t, NewOptional(String("bar")), value.(Struct).Fields[4])
+
+		var result marshalTestFoo
+		err = Unmarshal(value, &result)
+		require.NoError(t, err)
+		assert.Equal(t, fooWithNickname, result)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := Marshal(marshalTestPoint{}, marshalTestFooType)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing field name")
+	})
+
+	t.Run("event", func(t *testing.T) {
+
+		t.Parallel()
+
+		eventType := &EventType{
+			Location:            utils.TestLocation,
+			QualifiedIdentifier: "Moved",
+			Fields:              marshalTestPointType.Fields,
+		}
+
+		event := NewEvent([]Value{
+			Int64(3),
+			Int64(4),
+		}).WithType(eventType)
+
+		var point marshalTestPoint
+		err := Unmarshal(event, &point)
+		require.NoError(t, err)
+		assert.Equal(t, marshalTestPoint{X: 3, Y: 4}, point)
+
+		value, err := Marshal(point, eventType)
+		require.NoError(t, err)
+		assert.Equal(t, event, value)
+	})
+
+	t.Run("enum", func(t *testing.T) {
+
+		t.Parallel()
+
+		enumType := &EnumType{
+			Location:            utils.TestLocation,
+			QualifiedIdentifier: "Color",
+			RawType:             UInt8Type{},
+			Fields: []Field{
+				{Identifier: "rawValue", Type: UInt8Type{}},
+			},
+		}
+
+		value, err := Marshal(2, enumType)
+		require.NoError(t, err)
+
+		enum := NewEnum([]Value{UInt8(2)}).WithType(enumType)
+		assert.Equal(t, enum, value)
+
+		var rawValue int
+		err = Unmarshal(enum, &rawValue)
+		require.NoError(t, err)
+		assert.Equal(t, 2, rawValue)
+	})
+}
+
+func TestMarshalIntegers(t *testing.T) {
+
+	t.Parallel()
+
+	type testCase struct {
+		typ      Type
+		value    any
+		expected Value
+	}
+
+	for _, testCase := range []testCase{
+		{IntType{}, -1, NewInt(-1)},
+		{IntType{}, big.NewInt(1), NewInt(1)},
+		{Int8Type{}, int64(-128), Int8(-128)},
+		{Int16Type{}, 1, Int16(1)},
+		{Int32Type{}, 1, Int32(1)},
+		{Int64Type{}, 1, Int64(1)},
+		{Int128Type{}, 1, NewInt128(1)},
+		{Int256Type{}, 1, NewInt256(1)},
+		{UIntType{}, uint(1), NewUInt(1)},
+		{UInt8Type{}, 255, UInt8(255)},
+		{UInt16Type{}, 1, UInt16(1)},
+		{UInt32Type{}, 1, UInt32(1)},
+		{UInt64Type{}, uint64(1), UInt64(1)},
+		{UInt128Type{}, 1, NewUInt128(1)},
+		{UInt256Type{}, 1, NewUInt256(1)},
+		{Word8Type{}, 1, Word8(1)},
+		{Word16Type{}, 1, Word16(1)},
+		{Word32Type{}, 1, Word32(1)},
+		{Word64Type{}, 1, Word64(1)},
+		{Fix64Type{}, "-1.5", Fix64(-150_000_000)},
+		{Fix64Type{}, int64(-150_000_000), Fix64(-150_000_000)},
+		{UFix64Type{}, "1.5", UFix64(150_000_000)},
+	} {
+		value, err := Marshal(testCase.value, testCase.typ)
+		require.NoError(t, err, testCase.typ.ID())
+		assert.Equal(t, testCase.expected, value, testCase.typ.ID())
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+
+		t.Parallel()
+
+		for _, testCase := range []testCase{
+			{Int8Type{}, 128, nil},
+			{Int8Type{}, -129, nil},
+			{UInt8Type{}, 256, nil},
+			{UInt8Type{}, -1, nil},
+			{UIntType{}, -1, nil},
+			{Word64Type{}, -1, nil},
+			{UFix64Type{}, -1, nil},
+		} {
+			_, err := Marshal(testCase.value, testCase.typ)
+			require.Error(t, err, testCase.typ.ID())
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+
+		t.Parallel()
+
+		var i8 int8
+		err := Unmarshal(NewInt(-128), &i8)
+		require.NoError(t, err)
+		assert.Equal(t, int8(-128), i8)
+
+		err = Unmarshal(NewInt(128), &i8)
+		require.Error(t, err)
+
+		var u uint
+		err = Unmarshal(Int8(-1), &u)
+		require.Error(t, err)
+
+		var b big.Int
+		err = Unmarshal(NewUInt256(42), &b)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(42), &b)
+
+		var s string
+		err = Unmarshal(UFix64(150_000_000), &s)
+		require.NoError(t, err)
+		assert.Equal(t, "1.50000000", s)
+
+		var raw uint64
+		err = Unmarshal(UFix64(150_000_000), &raw)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(150_000_000), raw)
+	})
+}
+
+func TestMarshalTypeMismatch(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := Marshal("foo", IntType{})
+	require.Error(t, err)
+	require.ErrorAs(t, err, &MarshalTypeError{})
+
+	_, err = Marshal(nil, StringType{})
+	require.Error(t, err)
+	require.ErrorAs(t, err, &MarshalTypeError{})
+
+	_, err = Marshal([]int{1, 2}, ConstantSizedArrayType{Size: 3, ElementType: IntType{}})
+	require.Error(t, err)
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("invalid target", func(t *testing.T) {
+
+		t.Parallel()
+
+		var s string
+
+		err := Unmarshal(String("foo"), s)
+		require.ErrorAs(t, err, &InvalidUnmarshalError{})
+
+		err = Unmarshal(String("foo"), nil)
+		require.ErrorAs(t, err, &InvalidUnmarshalError{})
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+
+		t.Parallel()
+
+		var i int
+		err := Unmarshal(String("foo"), &i)
+		require.ErrorAs(t, err, &UnmarshalTypeError{})
+	})
+
+	t.Run("cadence value targets", func(t *testing.T) {
+
+		t.Parallel()
+
+		var value Value
+		err := Unmarshal(NewInt(1), &value)
+		require.NoError(t, err)
+		assert.Equal(t, NewInt(1), value)
+
+		var path Path
+		err = Unmarshal(NewPath("storage", "foo"), &path)
+		require.NoError(t, err)
+		assert.Equal(t, NewPath("storage", "foo"), path)
+	})
+
+	t.Run("empty interface", func(t *testing.T) {
+
+		t.Parallel()
+
+		var value any
+		err := Unmarshal(NewInt(1), &value)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), value)
+	})
+
+	t.Run("optional", func(t *testing.T) {
+
+		t.Parallel()
+
+		var i *int
+		err := Unmarshal(NewOptional(nil), &i)
+		require.NoError(t, err)
+		assert.Nil(t, i)
+
+		err = Unmarshal(NewOptional(NewInt(1)), &i)
+		require.NoError(t, err)
+		require.NotNil(t, i)
+		assert.Equal(t, 1, *i)
+	})
+
+	t.Run("constant-sized array", func(t *testing.T) {
+
+		t.Parallel()
+
+		array := NewArray([]Value{Bool(true), Bool(false)})
+
+		var result [2]bool
+		err := Unmarshal(array, &result)
+		require.NoError(t, err)
+		assert.Equal(t, [2]bool{true, false}, result)
+
+		var tooLong [3]bool
+		err = Unmarshal(array, &tooLong)
+		require.Error(t, err)
+	})
+}
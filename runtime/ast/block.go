@@ -29,6 +29,7 @@ import (
 type Block struct {
 	Statements []Statement
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &Block{}
@@ -63,28 +64,56 @@ var blockEndDoc prettier.Doc = prettier.Text("}")
 var blockEmptyDoc prettier.Doc = prettier.Text("{}")
 
 func (b *Block) Doc() prettier.Doc {
-	if b.IsEmpty() {
+	if b.IsEmpty() && len(b.Comments.Dangling) == 0 {
 		return blockEmptyDoc
 	}
 
 	return prettier.Concat{
 		blockStartDoc,
 		prettier.Indent{
-			Doc: StatementsDoc(b.Statements),
+			Doc: b.statementsDoc(),
 		},
 		prettier.HardLine{},
 		blockEndDoc,
 	}
 }
 
+// statementsDoc returns the document for the statements of the block,
+// followed by the dangling comments of the block, if any
+//
+func (b *Block) statementsDoc() prettier.Doc {
+	statementsDoc := StatementsDoc(b.Statements)
+
+	danglingCommentsDoc := DanglingCommentsDoc(b.Comments.Dangling)
+	if danglingCommentsDoc == nil {
+		return statementsDoc
+	}
+
+	return prettier.Concat{
+		statementsDoc,
+		danglingCommentsDoc,
+	}
+}
+
+// StatementsDoc returns the document for the given statements, each on its own line.
+// Comments attached to the statements are included,
+// and empty lines between the statements are preserved.
+//
 func StatementsDoc(statements []Statement) prettier.Doc {
 	var doc prettier.Concat
 
-	for _, statement := range statements {
+	for i, statement := range statements {
+		if i > 0 && isSeparatedByEmptyLine(statements[i-1], statement) {
+			doc = append(
+				doc,
+				prettier.HardLine{},
+			)
+		}
+
 		doc = append(
 			doc,
 			prettier.HardLine{},
-			statement.Doc(),
+			CommentedDoc(statement, statement.Doc()),
 		)
 	}
 
@@ -176,7 +205,9 @@ var preConditionsKeywordDoc = prettier.Text("pre")
 var postConditionsKeywordDoc = prettier.Text("post")
 
 func (b *FunctionBlock) Doc() prettier.Doc {
-	if b.IsEmpty() {
+	if b.IsEmpty() &&
+		(b == nil || len(b.Block.Comments.Dangling) == 0) {
+
 		return blockEmptyDoc
 	}
 
@@ -200,7 +231,7 @@ func (b *FunctionBlock) Doc() prettier.Doc {
 
 	var bodyDoc prettier.Doc
 
-	statementsDoc := StatementsDoc(b.Block.Statements)
+	statementsDoc := b.Block.statementsDoc()
 
 	if len(conditionDocs) > 0 {
 		bodyConcatDoc := prettier.Concat(conditionDocs)
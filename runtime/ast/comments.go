@@ -0,0 +1,210 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"strings"
+
+	"github.com/turbolent/prettier"
+
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// Comment is a line comment or a block comment.
+// The text of the comment includes the delimiters, e.g. `//` or `/*` and `*/`.
+//
+type Comment struct {
+	Text string
+	Range
+}
+
+func NewComment(memoryGauge common.MemoryGauge, text string, astRange Range) *Comment {
+	common.UseMemory(memoryGauge, common.NewRawStringMemoryUsage(len(text)))
+	return &Comment{
+		Text:  text,
+		Range: astRange,
+	}
+}
+
+// IsBlockComment returns true if the comment is a block comment (`/* ... */`),
+// and false if it is a line comment (`// ...`)
+//
+func (c *Comment) IsBlockComment() bool {
+	return strings.HasPrefix(c.Text, "/*")
+}
+
+// Doc returns the document for the comment.
+//
+// The lines of a multi-line block comment are re-indented:
+// The indentation the comment had in the source is removed from all lines
+// after the first, so they are indented relative to the current indentation.
+//
+func (c *Comment) Doc() prettier.Doc {
+	lines := strings.Split(c.Text, "\n")
+	if len(lines) == 1 {
+		return prettier.Text(strings.TrimRight(c.Text, " \t\r"))
+	}
+
+	docs := make([]prettier.Doc, 0, len(lines))
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if i > 0 {
+			line = trimIndentation(line, c.StartPos.Column)
+		}
+		docs = append(docs, prettier.Text(line))
+	}
+
+	return prettier.Join(prettier.HardLine{}, docs...)
+}
+
+// trimIndentation removes up to the given number of whitespace characters from the start of the line
+//
+func trimIndentation(line string, indentation int) string {
+	i := 0
+	for i < len(line) && i < indentation && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return line[i:]
+}
+
+// Comments are the comments attached to an element.
+//
+// Comments are only attached when requested when parsing,
+// see parser.ParseProgramWithComments.
+//
+type Comments struct {
+	// Leading are the comments before the element, on their own lines,
+	// and the comments inside the element which cannot be attached to any nested element
+	Leading []*Comment
+	// Trailing are the comments after the element, on the same line as the end of the element
+	Trailing []*Comment
+	// Dangling are the comments inside the element which are not attached to any of its children,
+	// e.g. the comments at the end of a block, after the last statement
+	Dangling []*Comment
+}
+
+func (c *Comments) ElementComments() *Comments {
+	return c
+}
+
+func (c *Comments) IsEmpty() bool {
+	return len(c.Leading) == 0 &&
+		len(c.Trailing) == 0 &&
+		len(c.Dangling) == 0
+}
+
+// CommentedElement is an element which can have comments attached,
+// e.g. a declaration, a statement, or a switch case
+//
+type CommentedElement interface {
+	HasPosition
+	ElementComments() *Comments
+}
+
+// CommentedDoc returns the given document of the given element,
+// preceded by the leading comments and followed by the trailing comments of the element, if any.
+//
+func CommentedDoc(element CommentedElement, doc prettier.Doc) prettier.Doc {
+	comments := element.ElementComments()
+	if len(comments.Leading) == 0 && len(comments.Trailing) == 0 {
+		return doc
+	}
+
+	var result prettier.Concat
+
+	startLine := element.StartPosition().Line
+
+	for i, comment := range comments.Leading {
+		result = append(
+			result,
+			comment.Doc(),
+			prettier.HardLine{},
+		)
+
+		// Preserve empty lines between the leading comments, and before the element
+
+		nextLine := startLine
+		if i+1 < len(comments.Leading) {
+			nextLine = comments.Leading[i+1].StartPos.Line
+		}
+		if nextLine-comment.EndPos.Line > 1 {
+			result = append(result, prettier.HardLine{})
+		}
+	}
+
+	result = append(result, doc)
+
+	for _, comment := range comments.Trailing {
+		result = append(
+			result,
+			prettier.Space,
+			comment.Doc(),
+		)
+	}
+
+	return result
+}
+
+// DanglingCommentsDoc returns the document for the given dangling comments.
+// Each comment is preceded by a line break.
+//
+func DanglingCommentsDoc(comments []*Comment) prettier.Doc {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	doc := make(prettier.Concat, 0, len(comments)*2)
+
+	for _, comment := range comments {
+		doc = append(
+			doc,
+			prettier.HardLine{},
+			comment.Doc(),
+		)
+	}
+
+	return doc
+}
+
+// commentedStartLine returns the line on which the given element starts, including its leading comments
+//
+func commentedStartLine(element CommentedElement) int {
+	leading := element.ElementComments().Leading
+	if len(leading) > 0 && leading[0].StartPos.Line < element.StartPosition().Line {
+		return leading[0].StartPos.Line
+	}
+	return element.StartPosition().Line
+}
+
+// commentedEndLine returns the line on which the given element ends, including its trailing comments
+//
+func commentedEndLine(element CommentedElement) int {
+	trailing := element.ElementComments().Trailing
+	if len(trailing) > 0 {
+		return trailing[len(trailing)-1].EndPos.Line
+	}
+	return element.EndPosition(nil).Line
+}
+
+// isSeparatedByEmptyLine returns true if there is at least one empty line between the two given elements
+//
+func isSeparatedByEmptyLine(previous, next CommentedElement) bool {
+	return commentedStartLine(next)-commentedEndLine(previous) > 1
+}
@@ -0,0 +1,132 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/turbolent/prettier"
+)
+
+func TestComment_Doc(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("line comment", func(t *testing.T) {
+
+		t.Parallel()
+
+		comment := &Comment{
+			Text: "// test  ",
+		}
+
+		require.Equal(t,
+			prettier.Text("// test"),
+			comment.Doc(),
+		)
+	})
+
+	t.Run("multi-line block comment", func(t *testing.T) {
+
+		t.Parallel()
+
+		comment := &Comment{
+			Text: "/* first\n         second\n     */",
+			Range: Range{
+				StartPos: Position{Offset: 4, Line: 1, Column: 4},
+			},
+		}
+
+		require.Equal(t,
+			prettier.Join(
+				prettier.HardLine{},
+				prettier.Text("/* first"),
+				prettier.Text("     second"),
+				prettier.Text(" */"),
+			),
+			comment.Doc(),
+		)
+	})
+}
+
+func TestBlock_DocWithComments(t *testing.T) {
+
+	t.Parallel()
+
+	block := &Block{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Expression: &BoolExpression{
+					Value: false,
+					Range: Range{
+						StartPos: Position{Line: 2},
+						EndPos:   Position{Line: 2},
+					},
+				},
+				Comments: Comments{
+					Leading: []*Comment{
+						{
+							Text: "// leading",
+							Range: Range{
+								StartPos: Position{Line: 1},
+								EndPos:   Position{Line: 1},
+							},
+						},
+					},
+					Trailing: []*Comment{
+						{
+							Text: "/* trailing */",
+							Range: Range{
+								StartPos: Position{Line: 2},
+								EndPos:   Position{Line: 2},
+							},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Expression: &StringExpression{
+					Value: "test",
+					Range: Range{
+						StartPos: Position{Line: 4},
+						EndPos:   Position{Line: 4},
+					},
+				},
+			},
+		},
+		Comments: Comments{
+			Dangling: []*Comment{
+				{Text: "// dangling"},
+			},
+		},
+	}
+
+	require.Equal(
+		t,
+		"{\n"+
+			"    // leading\n"+
+			"    false /* trailing */\n"+
+			"    \n"+
+			"    \"test\"\n"+
+			"    // dangling\n"+
+			"}",
+		Prettier(block),
+	)
+}
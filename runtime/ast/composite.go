@@ -32,13 +32,15 @@ import (
 // NOTE: For events, only an empty initializer is declared
 
 type CompositeDeclaration struct {
-	Access        Access
-	CompositeKind common.CompositeKind
-	Identifier    Identifier
-	Conformances  []*NominalType
-	Members       *Members
-	DocString     string
+	Access            Access
+	CompositeKind     common.CompositeKind
+	Identifier        Identifier
+	TypeParameterList *TypeParameterList `json:",omitempty"`
+	Conformances      []*NominalType
+	Members           *Members
+	DocString         string
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &CompositeDeclaration{}
@@ -50,6 +52,7 @@ func NewCompositeDeclaration(
 	access Access,
 	compositeKind common.CompositeKind,
 	identifier Identifier,
+	typeParameterList *TypeParameterList,
 	conformances []*NominalType,
 	members *Members,
 	docString string,
@@ -58,13 +61,14 @@ func NewCompositeDeclaration(
 	common.UseMemory(memoryGauge, common.CompositeDeclarationMemoryUsage)
 
 	return &CompositeDeclaration{
-		Access:        access,
-		CompositeKind: compositeKind,
-		Identifier:    identifier,
-		Conformances:  conformances,
-		Members:       members,
-		DocString:     docString,
-		Range:         declarationRange,
+		Access:            access,
+		CompositeKind:     compositeKind,
+		Identifier:        identifier,
+		TypeParameterList: typeParameterList,
+		Conformances:      conformances,
+		Members:           members,
+		DocString:         docString,
+		Range:             declarationRange,
 	}
 }
 
@@ -129,6 +133,7 @@ func (d *CompositeDeclaration) Doc() prettier.Doc {
 		d.CompositeKind,
 		false,
 		d.Identifier.Identifier,
+		d.TypeParameterList,
 		d.Conformances,
 		d.Members,
 	)
@@ -179,6 +184,7 @@ func CompositeDocument(
 	kind common.CompositeKind,
 	isInterface bool,
 	identifier string,
+	typeParameterList *TypeParameterList,
 	conformances []*NominalType,
 	members *Members,
 ) prettier.Doc {
@@ -211,6 +217,13 @@ func CompositeDocument(
 		prettier.Text(identifier),
 	)
 
+	if !typeParameterList.IsEmpty() {
+		doc = append(
+			doc,
+			typeParameterList.Doc(),
+		)
+	}
+
 	if len(conformances) > 0 {
 
 		conformancesDoc := prettier.Concat{
@@ -271,6 +284,7 @@ type FieldDeclaration struct {
 	TypeAnnotation *TypeAnnotation
 	DocString      string
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &FieldDeclaration{}
@@ -418,6 +432,7 @@ type EnumCaseDeclaration struct {
 	Identifier Identifier
 	DocString  string
 	StartPos   Position `json:"-"`
+	Comments   `json:"-"`
 }
 
 var _ Element = &EnumCaseDeclaration{}
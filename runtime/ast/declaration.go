@@ -36,4 +36,5 @@ type Declaration interface {
 	DeclarationMembers() *Members
 	DeclarationDocString() string
 	Doc() prettier.Doc
+	ElementComments() *Comments
 }
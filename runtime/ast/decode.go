@@ -0,0 +1,355 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// CBORDecMode is the CBOR decoding mode of the element encoding.
+//
+// Elements may be deeply nested, so the maximum nesting level is higher than the default.
+//
+var CBORDecMode = func() cbor.DecMode {
+	decMode, err := cbor.DecOptions{
+		IntDec:           cbor.IntDecConvertNone,
+		MaxArrayElements: math.MaxInt64,
+		MaxMapPairs:      math.MaxInt64,
+		MaxNestedLevels:  math.MaxInt16,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return decMode
+}()
+
+// Decoder decodes values encoded by an Encoder.
+//
+// Values must be decoded in the same order they were encoded,
+// as pointer references refer to the pointers decoded before.
+//
+type Decoder struct {
+	decoder  *cbor.StreamDecoder
+	pointers []reflect.Value
+}
+
+func NewDecoder(decoder *cbor.StreamDecoder) *Decoder {
+	return &Decoder{
+		decoder: decoder,
+	}
+}
+
+// Decode decodes a value into the value the given pointer points to,
+// e.g. Decode(&program) decodes a program.
+//
+func (d *Decoder) Decode(pointer any) error {
+	value := reflect.ValueOf(pointer)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errors.NewUnexpectedError("cannot decode into %T: expected non-nil pointer", pointer)
+	}
+	return d.decodeValue(value.Elem())
+}
+
+// Pointer returns the pointer with the given index, see Encoder.PointerIndex,
+// if it was decoded by the decoder.
+//
+func (d *Decoder) Pointer(index uint64) (any, bool) {
+	if index >= uint64(len(d.pointers)) {
+		return nil, false
+	}
+	return d.pointers[index].Interface(), true
+}
+
+func (d *Decoder) decodeValue(value reflect.Value) error {
+	ty := value.Type()
+
+	if ty == bigIntPointerType {
+		isNil, err := d.decodeNil()
+		if err != nil || isNil {
+			return err
+		}
+		bigInt, err := d.decoder.DecodeBigInt()
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(bigInt))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		b, err := d.decoder.DecodeBool()
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.decoder.DecodeInt64()
+		if err != nil {
+			return err
+		}
+		if value.OverflowInt(i) {
+			return errors.NewUnexpectedError("invalid value for %s: %d", ty, i)
+		}
+		value.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return err
+		}
+		if value.OverflowUint(u) {
+			return errors.NewUnexpectedError("invalid value for %s: %d", ty, u)
+		}
+		value.SetUint(u)
+		return nil
+
+	case reflect.String:
+		s, err := d.decoder.DecodeString()
+		if err != nil {
+			return err
+		}
+		value.SetString(s)
+		return nil
+
+	case reflect.Slice:
+		isNil, err := d.decodeNil()
+		if err != nil || isNil {
+			return err
+		}
+
+		if ty.Elem().Kind() == reflect.Uint8 {
+			b, err := d.decoder.DecodeBytes()
+			if err != nil {
+				return err
+			}
+			value.SetBytes(b)
+			return nil
+		}
+
+		length, err := d.decoder.DecodeArrayHead()
+		if err != nil {
+			return err
+		}
+
+		value.Set(reflect.MakeSlice(ty, int(length), int(length)))
+
+		return d.decodeElements(value, length)
+
+	case reflect.Array:
+		length, err := d.decoder.DecodeArrayHead()
+		if err != nil {
+			return err
+		}
+		if length != uint64(value.Len()) {
+			return errors.NewUnexpectedError(
+				"invalid array length for %s: expected %d, got %d",
+				ty,
+				value.Len(),
+				length,
+			)
+		}
+		return d.decodeElements(value, length)
+
+	case reflect.Struct:
+		return d.decodeStruct(value)
+
+	case reflect.Pointer:
+		return d.decodePointer(value)
+
+	case reflect.Interface:
+		return d.decodeInterface(value)
+	}
+
+	return errors.NewUnexpectedError("cannot decode value of type %s", ty)
+}
+
+// decodeNil decodes a CBOR nil, if the next value is nil.
+//
+func (d *Decoder) decodeNil() (bool, error) {
+	t, err := d.decoder.NextType()
+	if err != nil {
+		return false, err
+	}
+	if t != cbor.NilType {
+		return false, nil
+	}
+	return true, d.decoder.DecodeNil()
+}
+
+func (d *Decoder) decodeElements(value reflect.Value, length uint64) error {
+	for i := 0; i < int(length); i++ {
+		err := d.decodeValue(value.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) decodeStruct(value reflect.Value) error {
+	ty := value.Type()
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+
+	switch ty {
+	case programType, membersType:
+		if length != 2 {
+			return errors.NewUnexpectedError("invalid %s encoding: expected 2 fields, got %d", ty, length)
+		}
+
+		var declarations []Declaration
+		var comments Comments
+
+		err = d.decodeValue(reflect.ValueOf(&declarations).Elem())
+		if err != nil {
+			return err
+		}
+		err = d.decodeValue(reflect.ValueOf(&comments).Elem())
+		if err != nil {
+			return err
+		}
+
+		switch element := value.Addr().Interface().(type) {
+		case *Program:
+			element.declarations = declarations
+			element.Comments = comments
+		case *Members:
+			element.declarations = declarations
+			element.Comments = comments
+		}
+
+		return nil
+	}
+
+	fields := exportedFields(ty)
+
+	if length != uint64(len(fields)) {
+		return errors.NewUnexpectedError(
+			"invalid %s encoding: expected %d fields, got %d",
+			ty,
+			len(fields),
+			length,
+		)
+	}
+
+	for _, field := range fields {
+		err = d.decodeValue(value.Field(field))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodePointer(value reflect.Value) error {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return err
+	}
+
+	tag, err := d.decoder.DecodeTagNumber()
+	if err != nil {
+		return err
+	}
+
+	switch tag {
+	case CBORTagPointer:
+		pointer := reflect.New(value.Type().Elem())
+		d.pointers = append(d.pointers, pointer)
+
+		err = d.decodeValue(pointer.Elem())
+		if err != nil {
+			return err
+		}
+
+		value.Set(pointer)
+		return nil
+
+	case CBORTagPointerReference:
+		index, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return err
+		}
+
+		if index >= uint64(len(d.pointers)) {
+			return errors.NewUnexpectedError("invalid pointer reference: %d", index)
+		}
+
+		pointer := d.pointers[index]
+		if pointer.Type() != value.Type() {
+			return errors.NewUnexpectedError(
+				"invalid pointer reference: expected %s, got %s",
+				value.Type(),
+				pointer.Type(),
+			)
+		}
+
+		value.Set(pointer)
+		return nil
+	}
+
+	return errors.NewUnexpectedError("invalid pointer tag: %d", tag)
+}
+
+func (d *Decoder) decodeInterface(value reflect.Value) error {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+	if length != 2 {
+		return errors.NewUnexpectedError("invalid interface encoding: expected 2 elements, got %d", length)
+	}
+
+	name, err := d.decoder.DecodeString()
+	if err != nil {
+		return err
+	}
+
+	ty, ok := encodingTypes[name]
+	if !ok || !ty.Implements(value.Type()) {
+		return errors.NewUnexpectedError("cannot decode value of type %s into %s", name, value.Type())
+	}
+
+	concreteValue := reflect.New(ty).Elem()
+	err = d.decodeValue(concreteValue)
+	if err != nil {
+		return err
+	}
+
+	value.Set(concreteValue)
+	return nil
+}
@@ -79,4 +79,6 @@ const (
 	ElementTypeReferenceExpression
 	ElementTypeForceExpression
 	ElementTypePathExpression
+	ElementTypeStringTemplateExpression
+	ElementTypeTypeAliasDeclaration
 )
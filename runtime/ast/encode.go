@@ -0,0 +1,380 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// CBOR tags of the element encoding.
+//
+const (
+	// CBORTagPointer is the tag of a pointer which is encoded for the first time,
+	// the tag content is the encoding of the pointed-to value
+	CBORTagPointer = 200 + iota
+	// CBORTagPointerReference is the tag of a pointer which was already encoded,
+	// the tag content is the index of the pointer, in the order the pointers were encoded
+	CBORTagPointerReference
+)
+
+// CBOREncMode is the CBOR encoding mode of the element encoding.
+//
+var CBOREncMode = func() cbor.EncMode {
+	options := cbor.CanonicalEncOptions()
+	options.BigIntConvert = cbor.BigIntConvertNone
+	encMode, err := options.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return encMode
+}()
+
+// encodingTypes are the concrete types which may occur in interface-typed fields,
+// e.g. the declarations of a program, or the location of an import declaration.
+// They are keyed by their name, which is part of the encoding.
+//
+var encodingTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, value := range []any{
+		// Declarations
+		&CompositeDeclaration{},
+		&EnumCaseDeclaration{},
+		&FieldDeclaration{},
+		&FunctionDeclaration{},
+		&ImportDeclaration{},
+		&InterfaceDeclaration{},
+		&PragmaDeclaration{},
+		&SpecialFunctionDeclaration{},
+		&TransactionDeclaration{},
+		&TypeAliasDeclaration{},
+		&VariableDeclaration{},
+
+		// Statements
+		&AssignmentStatement{},
+		&BreakStatement{},
+		&ContinueStatement{},
+		&EmitStatement{},
+		&ExpressionStatement{},
+		&ForStatement{},
+		&IfStatement{},
+		&ReturnStatement{},
+		&SwapStatement{},
+		&SwitchStatement{},
+		&WhileStatement{},
+
+		// Expressions
+		&ArrayExpression{},
+		&BinaryExpression{},
+		&BoolExpression{},
+		&CastingExpression{},
+		&ConditionalExpression{},
+		&CreateExpression{},
+		&DestroyExpression{},
+		&DictionaryExpression{},
+		&FixedPointExpression{},
+		&ForceExpression{},
+		&FunctionExpression{},
+		&IdentifierExpression{},
+		&IndexExpression{},
+		&IntegerExpression{},
+		&InvocationExpression{},
+		&MemberExpression{},
+		&NilExpression{},
+		&PathExpression{},
+		&ReferenceExpression{},
+		&StringExpression{},
+		&StringTemplateExpression{},
+		&UnaryExpression{},
+
+		// Types
+		&ConstantSizedType{},
+		&DictionaryType{},
+		&FunctionType{},
+		&InstantiationType{},
+		&NominalType{},
+		&OptionalType{},
+		&ReferenceType{},
+		&RestrictedType{},
+		&VariableSizedType{},
+
+		// Locations
+		common.AddressLocation{},
+		common.IdentifierLocation(""),
+		common.REPLLocation{},
+		common.ScriptLocation{},
+		common.StringLocation(""),
+		common.TransactionLocation{},
+	} {
+		ty := reflect.TypeOf(value)
+		encodingTypes[ty.String()] = ty
+	}
+}
+
+var bigIntPointerType = reflect.TypeOf((*big.Int)(nil))
+var programType = reflect.TypeOf(Program{})
+var membersType = reflect.TypeOf(Members{})
+
+type pointerKey struct {
+	pointer uintptr
+	ty      reflect.Type
+}
+
+// Encoder encodes elements, and values of the types they consist of, e.g. locations, to CBOR.
+//
+// All values encoded with the same encoder share one pointer table:
+// A pointer which is encountered again, e.g. an expression which is both part of a program
+// and the rewrite of a condition, is encoded as a reference to the first occurrence.
+// Decoding with a single Decoder restores the sharing.
+//
+// Pointers are indexed in the order they are encountered.
+// The index of a pointer, see PointerIndex, can be used to refer to elements,
+// e.g. in other encodings which are keyed by elements.
+//
+// Only exported fields are encoded, except for the declarations of programs and members.
+// Unexported fields only cache information derived from the exported fields.
+//
+type Encoder struct {
+	encoder        *cbor.StreamEncoder
+	pointerIndices map[pointerKey]uint64
+}
+
+func NewEncoder(encoder *cbor.StreamEncoder) *Encoder {
+	return &Encoder{
+		encoder:        encoder,
+		pointerIndices: map[pointerKey]uint64{},
+	}
+}
+
+// Encode encodes the value the given pointer points to,
+// e.g. Encode(&program) encodes the program.
+//
+func (e *Encoder) Encode(pointer any) error {
+	value := reflect.ValueOf(pointer)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errors.NewUnexpectedError("cannot encode %T: expected non-nil pointer", pointer)
+	}
+	return e.encodeValue(value.Elem())
+}
+
+// PointerIndex returns the index of the given pointer,
+// if it was encoded by the encoder.
+//
+func (e *Encoder) PointerIndex(pointer any) (uint64, bool) {
+	value := reflect.ValueOf(pointer)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return 0, false
+	}
+	index, ok := e.pointerIndices[pointerKey{
+		pointer: value.Pointer(),
+		ty:      value.Type(),
+	}]
+	return index, ok
+}
+
+func (e *Encoder) encodeValue(value reflect.Value) error {
+	ty := value.Type()
+
+	if ty == bigIntPointerType {
+		if value.IsNil() {
+			return e.encoder.EncodeNil()
+		}
+		return e.encoder.EncodeBigInt(value.Interface().(*big.Int))
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return e.encoder.EncodeBool(value.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.encoder.EncodeInt64(value.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.encoder.EncodeUint64(value.Uint())
+
+	case reflect.String:
+		return e.encoder.EncodeString(value.String())
+
+	case reflect.Slice:
+		if value.IsNil() {
+			return e.encoder.EncodeNil()
+		}
+		if ty.Elem().Kind() == reflect.Uint8 {
+			return e.encoder.EncodeBytes(value.Bytes())
+		}
+		return e.encodeElements(value)
+
+	case reflect.Array:
+		return e.encodeElements(value)
+
+	case reflect.Struct:
+		return e.encodeStruct(value)
+
+	case reflect.Pointer:
+		return e.encodePointer(value)
+
+	case reflect.Interface:
+		return e.encodeInterface(value)
+	}
+
+	return errors.NewUnexpectedError("cannot encode value of type %s", ty)
+}
+
+func (e *Encoder) encodeElements(value reflect.Value) error {
+	length := value.Len()
+
+	err := e.encoder.EncodeArrayHead(uint64(length))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < length; i++ {
+		err = e.encodeValue(value.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeStruct encodes the exported fields of the given struct as a CBOR array.
+//
+// Programs and members are encoded as an array of their declarations and their comments.
+//
+func (e *Encoder) encodeStruct(value reflect.Value) error {
+	ty := value.Type()
+
+	switch ty {
+	case programType, membersType:
+		err := e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		var declarations []Declaration
+		var comments Comments
+
+		switch element := value.Addr().Interface().(type) {
+		case *Program:
+			declarations = element.declarations
+			comments = element.Comments
+		case *Members:
+			declarations = element.declarations
+			comments = element.Comments
+		}
+
+		err = e.encodeValue(reflect.ValueOf(&declarations).Elem())
+		if err != nil {
+			return err
+		}
+		return e.encodeValue(reflect.ValueOf(&comments).Elem())
+	}
+
+	fields := exportedFields(ty)
+
+	err := e.encoder.EncodeArrayHead(uint64(len(fields)))
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		err = e.encodeValue(value.Field(field))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) encodePointer(value reflect.Value) error {
+	if value.IsNil() {
+		return e.encoder.EncodeNil()
+	}
+
+	key := pointerKey{
+		pointer: value.Pointer(),
+		ty:      value.Type(),
+	}
+
+	if index, ok := e.pointerIndices[key]; ok {
+		err := e.encoder.EncodeTagHead(CBORTagPointerReference)
+		if err != nil {
+			return err
+		}
+		return e.encoder.EncodeUint64(index)
+	}
+
+	e.pointerIndices[key] = uint64(len(e.pointerIndices))
+
+	err := e.encoder.EncodeTagHead(CBORTagPointer)
+	if err != nil {
+		return err
+	}
+	return e.encodeValue(value.Elem())
+}
+
+// encodeInterface encodes a non-nil interface value
+// as a CBOR array of the name of the concrete type and the concrete value.
+//
+func (e *Encoder) encodeInterface(value reflect.Value) error {
+	if value.IsNil() {
+		return e.encoder.EncodeNil()
+	}
+
+	concreteValue := value.Elem()
+	name := concreteValue.Type().String()
+
+	if _, ok := encodingTypes[name]; !ok {
+		return errors.NewUnexpectedError("cannot encode value of type %s", name)
+	}
+
+	err := e.encoder.EncodeArrayHead(2)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeString(name)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeValue(concreteValue)
+}
+
+// exportedFields returns the indices of the exported fields of the given struct type.
+//
+func exportedFields(ty reflect.Type) []int {
+	fieldCount := ty.NumField()
+	fields := make([]int, 0, fieldCount)
+	for i := 0; i < fieldCount; i++ {
+		if !ty.Field(i).IsExported() {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	return fields
+}
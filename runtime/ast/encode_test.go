@@ -0,0 +1,177 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/tests/examples"
+)
+
+func TestEncodeDecodeProgram(t *testing.T) {
+
+	t.Parallel()
+
+	test := func(t *testing.T, code string) {
+		program, err := parser.ParseProgramWithComments(code, nil)
+		require.NoError(t, err)
+
+		var buffer bytes.Buffer
+		encoder := ast.CBOREncMode.NewStreamEncoder(&buffer)
+
+		err = ast.NewEncoder(encoder).Encode(&program)
+		require.NoError(t, err)
+
+		err = encoder.Flush()
+		require.NoError(t, err)
+
+		decoder := ast.CBORDecMode.NewByteStreamDecoder(buffer.Bytes())
+
+		var decodedProgram *ast.Program
+		err = ast.NewDecoder(decoder).Decode(&decodedProgram)
+		require.NoError(t, err)
+
+		expected, err := json.Marshal(program)
+		require.NoError(t, err)
+
+		actual, err := json.Marshal(decodedProgram)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(expected), string(actual))
+
+		assert.Equal(t, program.Comments, decodedProgram.Comments)
+		assert.Equal(t,
+			ast.Prettier(program),
+			ast.Prettier(decodedProgram),
+		)
+	}
+
+	t.Run("fungible token", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t, examples.FungibleTokenContractInterface)
+	})
+
+	t.Run("expressions", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t, `
+          import A from 0x1
+          import "B"
+
+          /// test
+          pub fun test(_ x: Int, y: [String; 2]): {String: Int?} {
+              pre { x > 0: "positive" }
+              post { before(x) == x }
+              let a = -1_000_000_000_000_000_000_000_000 + 0x1 * 1.5 as Int
+              let b = "a\(x)b"
+              var c = [1, 2][0] ?? nil
+              let d = fun (): &AnyStruct { return &x as &AnyStruct }
+              let e = create R() as! @R{I}
+              destroy e
+              let f = /storage/foo
+              let g = y.length < 2 ? Type<Int>() : Type<Int8>()
+              switch x {
+                  case 1: break
+                  default: c = 3
+              }
+              for i in [1] { continue }
+              while false {}
+              if let h = c {} else if true {}
+              emit E()
+              c <-> c
+              return {"a": 1}
+          }
+        `)
+	})
+}
+
+func TestEncoder_PointerIndex(t *testing.T) {
+
+	t.Parallel()
+
+	expression := &ast.IdentifierExpression{
+		Identifier: ast.Identifier{Identifier: "x"},
+	}
+
+	// The same expression occurs twice
+	statements := []ast.Statement{
+		&ast.ExpressionStatement{Expression: expression},
+		&ast.ExpressionStatement{Expression: expression},
+	}
+
+	location := common.Location(common.AddressLocation{
+		Address: common.MustBytesToAddress([]byte{0x1}),
+		Name:    "A",
+	})
+
+	var buffer bytes.Buffer
+	encoder := ast.CBOREncMode.NewStreamEncoder(&buffer)
+	elementEncoder := ast.NewEncoder(encoder)
+
+	err := elementEncoder.Encode(&statements)
+	require.NoError(t, err)
+
+	err = elementEncoder.Encode(&location)
+	require.NoError(t, err)
+
+	err = encoder.Flush()
+	require.NoError(t, err)
+
+	index, ok := elementEncoder.PointerIndex(expression)
+	require.True(t, ok)
+
+	_, ok = elementEncoder.PointerIndex(&ast.IdentifierExpression{})
+	require.False(t, ok)
+
+	decoder := ast.CBORDecMode.NewByteStreamDecoder(buffer.Bytes())
+	elementDecoder := ast.NewDecoder(decoder)
+
+	var decodedStatements []ast.Statement
+	err = elementDecoder.Decode(&decodedStatements)
+	require.NoError(t, err)
+
+	var decodedLocation common.Location
+	err = elementDecoder.Decode(&decodedLocation)
+	require.NoError(t, err)
+
+	require.Len(t, decodedStatements, 2)
+
+	first := decodedStatements[0].(*ast.ExpressionStatement).Expression
+	second := decodedStatements[1].(*ast.ExpressionStatement).Expression
+
+	assert.Equal(t, expression, first)
+	assert.Same(t, first, second)
+
+	decodedExpression, ok := elementDecoder.Pointer(index)
+	require.True(t, ok)
+	assert.Same(t, first, decodedExpression)
+
+	assert.Equal(t, location, decodedLocation)
+}
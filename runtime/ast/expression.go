@@ -245,6 +245,121 @@ func (*StringExpression) precedence() precedence {
 	return precedenceLiteral
 }
 
+// StringTemplateExpression
+
+type StringTemplateExpression struct {
+	// Values are the string parts of the template,
+	// there is always one more value than there are expressions,
+	// i.e. the expressions are interpolated between the values
+	Values      []string
+	Expressions []Expression
+	Range
+}
+
+var _ Expression = &StringTemplateExpression{}
+
+func NewStringTemplateExpression(
+	gauge common.MemoryGauge,
+	values []string,
+	expressions []Expression,
+	exprRange Range,
+) *StringTemplateExpression {
+	common.UseMemory(gauge, common.StringTemplateExpressionMemoryUsage)
+	return &StringTemplateExpression{
+		Values:      values,
+		Expressions: expressions,
+		Range:       exprRange,
+	}
+}
+
+var _ Element = &StringTemplateExpression{}
+var _ Expression = &StringTemplateExpression{}
+
+func (*StringTemplateExpression) ElementType() ElementType {
+	return ElementTypeStringTemplateExpression
+}
+
+func (*StringTemplateExpression) isExpression() {}
+
+func (*StringTemplateExpression) isIfStatementTest() {}
+
+func (e *StringTemplateExpression) Accept(visitor Visitor) Repr {
+	return e.AcceptExp(visitor)
+}
+
+func (e *StringTemplateExpression) Walk(walkChild func(Element)) {
+	walkExpressions(walkChild, e.Expressions)
+}
+
+// AcceptExp calls VisitStringTemplateExpression.
+//
+// The visitor must implement StringTemplateExpressionVisitor.
+// Visitors which do not implement it cannot visit the interpolated expressions,
+// so visiting the template fails instead of silently skipping them.
+//
+func (e *StringTemplateExpression) AcceptExp(visitor ExpressionVisitor) Repr {
+	templateVisitor, ok := visitor.(StringTemplateExpressionVisitor)
+	if !ok {
+		panic(errors.NewUnexpectedError(
+			"%T does not support string template expressions: it must implement StringTemplateExpressionVisitor",
+			visitor,
+		))
+	}
+
+	return templateVisitor.VisitStringTemplateExpression(e)
+}
+
+func (e *StringTemplateExpression) String() string {
+	return Prettier(e)
+}
+
+const stringTemplateInterpolationStart = `\(`
+const stringTemplateInterpolationEnd = ")"
+
+func (e *StringTemplateExpression) Doc() prettier.Doc {
+	var b strings.Builder
+
+	doc := make(prettier.Concat, 0, len(e.Values)+len(e.Expressions))
+
+	b.WriteByte('"')
+
+	for i, value := range e.Values {
+		writeEscapedString(&b, value)
+
+		if i < len(e.Expressions) {
+			b.WriteString(stringTemplateInterpolationStart)
+			doc = append(
+				doc,
+				prettier.Text(b.String()),
+				e.Expressions[i].Doc(),
+			)
+
+			b.Reset()
+			b.WriteString(stringTemplateInterpolationEnd)
+		}
+	}
+
+	b.WriteByte('"')
+	doc = append(doc, prettier.Text(b.String()))
+
+	return doc
+}
+
+func (e *StringTemplateExpression) MarshalJSON() ([]byte, error) {
+	type Alias StringTemplateExpression
+	return json.Marshal(&struct {
+		Type string
+		*Alias
+	}{
+		Type:  "StringTemplateExpression",
+		Alias: (*Alias)(e),
+	})
+}
+
+func (*StringTemplateExpression) precedence() precedence {
+	return precedenceLiteral
+}
+
 // IntegerExpression
 
 type IntegerExpression struct {
@@ -1507,6 +1622,7 @@ func FunctionDocument(
 	access Access,
 	includeKeyword bool,
 	identifier string,
+	typeParameterList *TypeParameterList,
 	parameterList *ParameterList,
 	returnTypeAnnotation *TypeAnnotation,
 	block *FunctionBlock,
@@ -1554,6 +1670,13 @@ func FunctionDocument(
 		)
 	}
 
+	if !typeParameterList.IsEmpty() {
+		doc = append(
+			doc,
+			typeParameterList.Doc(),
+		)
+	}
+
 	if signatureDoc != nil {
 		doc = append(
 			doc,
@@ -1581,6 +1704,7 @@ func (e *FunctionExpression) Doc() prettier.Doc {
 		AccessNotSpecified,
 		true,
 		"",
+		nil,
 		e.ParameterList,
 		e.ReturnTypeAnnotation,
 		e.FunctionBlock,
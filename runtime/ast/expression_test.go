@@ -26,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/turbolent/prettier"
+
+	"github.com/onflow/cadence/runtime/errors"
 )
 
 func TestBoolExpression_MarshalJSON(t *testing.T) {
@@ -177,6 +179,175 @@ func TestStringExpression_String(t *testing.T) {
 	)
 }
 
+func TestStringTemplateExpression_MarshalJSON(t *testing.T) {
+
+	t.Parallel()
+
+	expr := &StringTemplateExpression{
+		Values: []string{"Hello, ", "!"},
+		Expressions: []Expression{
+			&IdentifierExpression{
+				Identifier: Identifier{
+					Identifier: "name",
+					Pos:        Position{Offset: 2, Line: 3, Column: 4},
+				},
+			},
+		},
+		Range: Range{
+			StartPos: Position{Offset: 1, Line: 2, Column: 3},
+			EndPos:   Position{Offset: 4, Line: 5, Column: 6},
+		},
+	}
+
+	actual, err := json.Marshal(expr)
+	require.NoError(t, err)
+
+	assert.JSONEq(t,
+		`
+        {
+            "Type": "StringTemplateExpression",
+            "Values": ["Hello, ", "!"],
+            "Expressions": [
+                {
+                    "Type": "IdentifierExpression",
+                    "Identifier": {
+                        "Identifier": "name",
+                        "StartPos": {"Offset": 2, "Line": 3, "Column": 4},
+                        "EndPos": {"Offset": 5, "Line": 3, "Column": 7}
+                    },
+                    "StartPos": {"Offset": 2, "Line": 3, "Column": 4},
+                    "EndPos": {"Offset": 5, "Line": 3, "Column": 7}
+                }
+            ],
+            "StartPos": {"Offset": 1, "Line": 2, "Column": 3},
+            "EndPos": {"Offset": 4, "Line": 5, "Column": 6}
+        }
+        `,
+		string(actual),
+	)
+}
+
+func TestStringTemplateExpression_Doc(t *testing.T) {
+
+	t.Parallel()
+
+	expr := &StringTemplateExpression{
+		Values: []string{"a\n", "", "\""},
+		Expressions: []Expression{
+			&IdentifierExpression{
+				Identifier: Identifier{Identifier: "x"},
+			},
+			&IdentifierExpression{
+				Identifier: Identifier{Identifier: "y"},
+			},
+		},
+	}
+
+	assert.Equal(t,
+		prettier.Concat{
+			prettier.Text(`"a\n\(`),
+			prettier.Text("x"),
+			prettier.Text(`)\(`),
+			prettier.Text("y"),
+			prettier.Text(`)\""`),
+		},
+		expr.Doc(),
+	)
+
+	assert.Equal(t,
+		`"a\n\(x)\(y)\""`,
+		expr.String(),
+	)
+}
+
+func TestStringTemplateExpression_Walk(t *testing.T) {
+
+	t.Parallel()
+
+	x := &IdentifierExpression{
+		Identifier: Identifier{Identifier: "x"},
+	}
+	y := &IdentifierExpression{
+		Identifier: Identifier{Identifier: "y"},
+	}
+
+	expr := &StringTemplateExpression{
+		Values:      []string{"", "", ""},
+		Expressions: []Expression{x, y},
+	}
+
+	var children []Element
+	expr.Walk(func(element Element) {
+		children = append(children, element)
+	})
+
+	assert.Equal(t, []Element{x, y}, children)
+}
+
+type testStringExpressionVisitor struct {
+	ExpressionVisitor
+}
+
+func (testStringExpressionVisitor) VisitStringExpression(expression *StringExpression) Repr {
+	return expression
+}
+
+type testStringTemplateExpressionVisitor struct {
+	testStringExpressionVisitor
+}
+
+func (testStringTemplateExpressionVisitor) VisitStringTemplateExpression(expression *StringTemplateExpression) Repr {
+	return expression
+}
+
+func TestStringTemplateExpression_AcceptExp(t *testing.T) {
+
+	t.Parallel()
+
+	expr := &StringTemplateExpression{
+		Values: []string{"a", "b"},
+		Expressions: []Expression{
+			&IdentifierExpression{
+				Identifier: Identifier{Identifier: "x"},
+			},
+		},
+		Range: Range{
+			StartPos: Position{Offset: 1, Line: 2, Column: 3},
+			EndPos:   Position{Offset: 4, Line: 5, Column: 6},
+		},
+	}
+
+	t.Run("string template visitor", func(t *testing.T) {
+
+		t.Parallel()
+
+		assert.Same(t,
+			expr,
+			expr.AcceptExp(testStringTemplateExpressionVisitor{}),
+		)
+	})
+
+	t.Run("expression visitor", func(t *testing.T) {
+
+		t.Parallel()
+
+		// The interpolated expressions must not be skipped silently
+
+		defer func() {
+			err, ok := recover().(errors.UnexpectedError)
+			require.True(t, ok)
+
+			assert.EqualError(t,
+				err.Unwrap(),
+				"ast.testStringExpressionVisitor does not support string template expressions: "+
+					"it must implement StringTemplateExpressionVisitor",
+			)
+		}()
+
+		expr.AcceptExp(testStringExpressionVisitor{})
+	})
+}
+
 func TestIntegerExpression_MarshalJSON(t *testing.T) {
 
 	t.Parallel()
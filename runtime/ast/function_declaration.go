@@ -29,11 +29,13 @@ import (
 type FunctionDeclaration struct {
 	Access               Access
 	Identifier           Identifier
+	TypeParameterList    *TypeParameterList `json:",omitempty"`
 	ParameterList        *ParameterList
 	ReturnTypeAnnotation *TypeAnnotation
 	FunctionBlock        *FunctionBlock
 	DocString            string
 	StartPos             Position `json:"-"`
+	Comments             `json:"-"`
 }
 
 var _ Element = &FunctionDeclaration{}
@@ -44,6 +46,7 @@ func NewFunctionDeclaration(
 	gauge common.MemoryGauge,
 	access Access,
 	identifier Identifier,
+	typeParameterList *TypeParameterList,
 	parameterList *ParameterList,
 	returnTypeAnnotation *TypeAnnotation,
 	functionBlock *FunctionBlock,
@@ -55,6 +58,7 @@ func NewFunctionDeclaration(
 	return &FunctionDeclaration{
 		Access:               access,
 		Identifier:           identifier,
+		TypeParameterList:    typeParameterList,
 		ParameterList:        parameterList,
 		ReturnTypeAnnotation: returnTypeAnnotation,
 		FunctionBlock:        functionBlock,
@@ -132,6 +136,7 @@ func (d *FunctionDeclaration) Doc() prettier.Doc {
 		d.Access,
 		true,
 		d.Identifier.Identifier,
+		d.TypeParameterList,
 		d.ParameterList,
 		d.ReturnTypeAnnotation,
 		d.FunctionBlock,
@@ -160,6 +165,7 @@ func (d *FunctionDeclaration) String() string {
 type SpecialFunctionDeclaration struct {
 	Kind                common.DeclarationKind
 	FunctionDeclaration *FunctionDeclaration
+	Comments            `json:"-"`
 }
 
 var _ Element = &SpecialFunctionDeclaration{}
@@ -228,6 +234,7 @@ func (d *SpecialFunctionDeclaration) Doc() prettier.Doc {
 		d.FunctionDeclaration.Access,
 		false,
 		d.Kind.Keywords(),
+		nil,
 		d.FunctionDeclaration.ParameterList,
 		d.FunctionDeclaration.ReturnTypeAnnotation,
 		d.FunctionDeclaration.FunctionBlock,
@@ -33,6 +33,7 @@ type ImportDeclaration struct {
 	Location    common.Location
 	LocationPos Position
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &ImportDeclaration{}
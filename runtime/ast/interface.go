@@ -35,6 +35,7 @@ type InterfaceDeclaration struct {
 	Members       *Members
 	DocString     string
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &InterfaceDeclaration{}
@@ -119,6 +120,7 @@ func (d *InterfaceDeclaration) Doc() prettier.Doc {
 		true,
 		d.Identifier.Identifier,
 		nil,
+		nil,
 		d.Members,
 	)
 }
@@ -31,6 +31,7 @@ import (
 type Members struct {
 	declarations []Declaration
 	indices      memberIndices
+	Comments     `json:"-"`
 }
 
 func NewMembers(memoryGauge common.MemoryGauge, declarations []Declaration) *Members {
@@ -72,6 +73,10 @@ func (m *Members) EnumCases() []*EnumCaseDeclaration {
 	return m.indices.EnumCases(m.declarations)
 }
 
+func (m *Members) TypeAliases() []*TypeAliasDeclaration {
+	return m.indices.TypeAliases(m.declarations)
+}
+
 func (m *Members) FieldsByIdentifier() map[string]*FieldDeclaration {
 	return m.indices.FieldsByIdentifier(m.declarations)
 }
@@ -133,7 +138,7 @@ var membersEndDoc prettier.Doc = prettier.Text("}")
 var membersEmptyDoc prettier.Doc = prettier.Text("{}")
 
 func (m *Members) Doc() prettier.Doc {
-	if len(m.declarations) == 0 {
+	if len(m.declarations) == 0 && len(m.Comments.Dangling) == 0 {
 		return membersEmptyDoc
 	}
 
@@ -144,18 +149,27 @@ func (m *Members) Doc() prettier.Doc {
 			docs,
 			prettier.Concat{
 				prettier.HardLine{},
-				decl.Doc(),
+				CommentedDoc(decl, decl.Doc()),
 			},
 		)
 	}
 
+	var bodyDoc prettier.Doc = prettier.Join(
+		prettier.HardLine{},
+		docs...,
+	)
+
+	if danglingCommentsDoc := DanglingCommentsDoc(m.Comments.Dangling); danglingCommentsDoc != nil {
+		bodyDoc = prettier.Concat{
+			bodyDoc,
+			danglingCommentsDoc,
+		}
+	}
+
 	return prettier.Concat{
 		membersStartDoc,
 		prettier.Indent{
-			Doc: prettier.Join(
-				prettier.HardLine{},
-				docs...,
-			),
+			Doc: bodyDoc,
 		},
 		prettier.HardLine{},
 		membersEndDoc,
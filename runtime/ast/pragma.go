@@ -31,6 +31,7 @@ import (
 type PragmaDeclaration struct {
 	Expression Expression
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &PragmaDeclaration{}
@@ -30,6 +30,7 @@ type Program struct {
 	// all declarations, in the order they are defined
 	declarations []Declaration
 	indices      programIndices
+	Comments     `json:"-"`
 }
 
 var _ Element = &Program{}
@@ -102,6 +103,10 @@ func (p *Program) VariableDeclarations() []*VariableDeclaration {
 	return p.indices.variableDeclarations(p.declarations)
 }
 
+func (p *Program) TypeAliasDeclarations() []*TypeAliasDeclaration {
+	return p.indices.typeAliasDeclarations(p.declarations)
+}
+
 // SoleContractDeclaration returns the sole contract declaration, if any,
 // and if there are no other actionable declarations.
 //
@@ -192,7 +197,11 @@ func (p *Program) Doc() prettier.Doc {
 	docs := make([]prettier.Doc, 0, len(declarations))
 
 	for _, declaration := range declarations {
-		docs = append(docs, declaration.Doc())
+		docs = append(docs, CommentedDoc(declaration, declaration.Doc()))
+	}
+
+	for _, comment := range p.Comments.Dangling {
+		docs = append(docs, comment.Doc())
 	}
 
 	return prettier.Join(programSeparatorDoc, docs...)
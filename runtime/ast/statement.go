@@ -32,6 +32,7 @@ type Statement interface {
 	fmt.Stringer
 	isStatement()
 	Doc() prettier.Doc
+	ElementComments() *Comments
 }
 
 // ReturnStatement
@@ -39,6 +40,7 @@ type Statement interface {
 type ReturnStatement struct {
 	Expression Expression
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &ReturnStatement{}
@@ -101,6 +103,7 @@ func (s *ReturnStatement) MarshalJSON() ([]byte, error) {
 
 type BreakStatement struct {
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &BreakStatement{}
@@ -152,6 +155,7 @@ func (s *BreakStatement) MarshalJSON() ([]byte, error) {
 
 type ContinueStatement struct {
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &ContinueStatement{}
@@ -214,6 +218,7 @@ type IfStatement struct {
 	Then     *Block
 	Else     *Block
 	StartPos Position `json:"-"`
+	Comments `json:"-"`
 }
 
 var _ Element = &IfStatement{}
@@ -277,10 +282,15 @@ func (s *IfStatement) Doc() prettier.Doc {
 		s.Then.Doc(),
 	}
 
-	if s.Else != nil && len(s.Else.Statements) > 0 {
+	if s.Else != nil &&
+		(len(s.Else.Statements) > 0 || len(s.Else.Comments.Dangling) > 0) {
+
 		var elseDoc prettier.Doc
-		if len(s.Else.Statements) == 1 {
-			if elseIfStatement, ok := s.Else.Statements[0].(*IfStatement); ok {
+		if len(s.Else.Statements) == 1 && len(s.Else.Comments.Dangling) == 0 {
+			elseIfStatement, ok := s.Else.Statements[0].(*IfStatement)
+			// An else-if statement with comments is written as a block,
+			// so the comments are not lost
+			if ok && elseIfStatement.Comments.IsEmpty() {
 				elseDoc = elseIfStatement.Doc()
 			}
 		}
@@ -325,6 +335,7 @@ type WhileStatement struct {
 	Test     Expression
 	Block    *Block
 	StartPos Position `json:"-"`
+	Comments `json:"-"`
 }
 
 var _ Element = &WhileStatement{}
@@ -405,6 +416,7 @@ type ForStatement struct {
 	Value      Expression
 	Block      *Block
 	StartPos   Position `json:"-"`
+	Comments   `json:"-"`
 }
 
 var _ Element = &ForStatement{}
@@ -504,6 +516,7 @@ func (s *ForStatement) MarshalJSON() ([]byte, error) {
 type EmitStatement struct {
 	InvocationExpression *InvocationExpression
 	StartPos             Position `json:"-"`
+	Comments             `json:"-"`
 }
 
 var _ Element = &EmitStatement{}
@@ -575,6 +588,7 @@ type AssignmentStatement struct {
 	Target   Expression
 	Transfer *Transfer
 	Value    Expression
+	Comments `json:"-"`
 }
 
 var _ Element = &AssignmentStatement{}
@@ -654,8 +668,9 @@ func (s *AssignmentStatement) MarshalJSON() ([]byte, error) {
 // SwapStatement
 
 type SwapStatement struct {
-	Left  Expression
-	Right Expression
+	Left     Expression
+	Right    Expression
+	Comments `json:"-"`
 }
 
 var _ Element = &SwapStatement{}
@@ -725,6 +740,7 @@ func (s *SwapStatement) MarshalJSON() ([]byte, error) {
 
 type ExpressionStatement struct {
 	Expression Expression
+	Comments   `json:"-"`
 }
 
 var _ Element = &ExpressionStatement{}
@@ -786,6 +802,7 @@ type SwitchStatement struct {
 	Expression Expression
 	Cases      []*SwitchCase
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &SwitchStatement{}
@@ -837,7 +854,14 @@ func (s *SwitchStatement) Doc() prettier.Doc {
 		bodyDoc = append(
 			bodyDoc,
 			prettier.HardLine{},
-			switchCase.Doc(),
+			CommentedDoc(switchCase, switchCase.Doc()),
+		)
+	}
+
+	if danglingCommentsDoc := DanglingCommentsDoc(s.Comments.Dangling); danglingCommentsDoc != nil {
+		bodyDoc = append(
+			bodyDoc,
+			danglingCommentsDoc,
 		)
 	}
 
@@ -884,6 +908,7 @@ type SwitchCase struct {
 	Expression Expression
 	Statements []Statement
 	Range
+	Comments `json:"-"`
 }
 
 func (s *SwitchCase) MarshalJSON() ([]byte, error) {
@@ -902,8 +927,17 @@ const switchCaseColonSymbolDoc = prettier.Text(":")
 const switchCaseDefaultKeywordSpaceDoc = prettier.Text("default:")
 
 func (s *SwitchCase) Doc() prettier.Doc {
+	bodyDoc := StatementsDoc(s.Statements)
+
+	if danglingCommentsDoc := DanglingCommentsDoc(s.Comments.Dangling); danglingCommentsDoc != nil {
+		bodyDoc = prettier.Concat{
+			bodyDoc,
+			danglingCommentsDoc,
+		}
+	}
+
 	statementsDoc := prettier.Indent{
-		Doc: StatementsDoc(s.Statements),
+		Doc: bodyDoc,
 	}
 
 	if s.Expression == nil {
@@ -35,6 +35,7 @@ type TransactionDeclaration struct {
 	PostConditions *Conditions
 	DocString      string
 	Range
+	Comments `json:"-"`
 }
 
 var _ Element = &TransactionDeclaration{}
@@ -139,11 +140,11 @@ func (d *TransactionDeclaration) Doc() prettier.Doc {
 	}
 
 	for _, field := range d.Fields {
-		addContent(field.Doc())
+		addContent(CommentedDoc(field, field.Doc()))
 	}
 
 	if d.Prepare != nil {
-		addContent(d.Prepare.Doc())
+		addContent(CommentedDoc(d.Prepare, d.Prepare.Doc()))
 	}
 
 	if conditionsDoc := d.PreConditions.Doc(preConditionsKeywordDoc); conditionsDoc != nil {
@@ -151,13 +152,17 @@ func (d *TransactionDeclaration) Doc() prettier.Doc {
 	}
 
 	if d.Execute != nil {
-		addContent(d.Execute.Doc())
+		addContent(CommentedDoc(d.Execute, d.Execute.Doc()))
 	}
 
 	if conditionsDoc := d.PostConditions.Doc(postConditionsKeywordDoc); conditionsDoc != nil {
 		addContent(conditionsDoc)
 	}
 
+	for _, comment := range d.Comments.Dangling {
+		addContent(comment.Doc())
+	}
+
 	doc := prettier.Concat{
 		transactionKeywordDoc,
 	}
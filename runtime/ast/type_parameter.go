@@ -0,0 +1,147 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"encoding/json"
+
+	"github.com/turbolent/prettier"
+
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// TypeParameter is a type parameter of a generic function or composite declaration,
+// e.g. `T`, or `T: AnyStruct` if it has a type bound
+//
+type TypeParameter struct {
+	Identifier Identifier
+	TypeBound  *TypeAnnotation `json:",omitempty"`
+}
+
+func NewTypeParameter(
+	gauge common.MemoryGauge,
+	identifier Identifier,
+	typeBound *TypeAnnotation,
+) *TypeParameter {
+	common.UseMemory(gauge, common.TypeParameterMemoryUsage)
+	return &TypeParameter{
+		Identifier: identifier,
+		TypeBound:  typeBound,
+	}
+}
+
+func (p *TypeParameter) StartPosition() Position {
+	return p.Identifier.StartPosition()
+}
+
+func (p *TypeParameter) EndPosition(memoryGauge common.MemoryGauge) Position {
+	if p.TypeBound != nil {
+		return p.TypeBound.EndPosition(memoryGauge)
+	}
+	return p.Identifier.EndPosition(memoryGauge)
+}
+
+func (p *TypeParameter) Doc() prettier.Doc {
+	identifierDoc := prettier.Text(p.Identifier.Identifier)
+
+	if p.TypeBound == nil {
+		return identifierDoc
+	}
+
+	return prettier.Concat{
+		identifierDoc,
+		typeSeparatorSpaceDoc,
+		p.TypeBound.Doc(),
+	}
+}
+
+func (p *TypeParameter) MarshalJSON() ([]byte, error) {
+	type Alias TypeParameter
+	return json.Marshal(&struct {
+		Range
+		*Alias
+	}{
+		Range: NewUnmeteredRangeFromPositioned(p),
+		Alias: (*Alias)(p),
+	})
+}
+
+// TypeParameterList is the list of type parameters of a generic function or composite declaration,
+// e.g. `<T: AnyStruct, U>`
+//
+type TypeParameterList struct {
+	TypeParameters []*TypeParameter
+	Range
+}
+
+func NewTypeParameterList(
+	gauge common.MemoryGauge,
+	typeParameters []*TypeParameter,
+	astRange Range,
+) *TypeParameterList {
+	common.UseMemory(gauge, common.TypeParameterListMemoryUsage)
+	return &TypeParameterList{
+		TypeParameters: typeParameters,
+		Range:          astRange,
+	}
+}
+
+func (l *TypeParameterList) IsEmpty() bool {
+	return l == nil || len(l.TypeParameters) == 0
+}
+
+const typeParameterListStartDoc = prettier.Text("<")
+const typeParameterListEndDoc = prettier.Text(">")
+
+var typeParameterSeparatorDoc prettier.Doc = prettier.Concat{
+	prettier.Text(","),
+	prettier.Line{},
+}
+
+func (l *TypeParameterList) Doc() prettier.Doc {
+	if l.IsEmpty() {
+		return nil
+	}
+
+	typeParameterDocs := make([]prettier.Doc, 0, len(l.TypeParameters))
+	for _, typeParameter := range l.TypeParameters {
+		typeParameterDocs = append(typeParameterDocs, typeParameter.Doc())
+	}
+
+	return prettier.Group{
+		Doc: prettier.Concat{
+			typeParameterListStartDoc,
+			prettier.Indent{
+				Doc: prettier.Concat{
+					prettier.SoftLine{},
+					prettier.Join(
+						typeParameterSeparatorDoc,
+						typeParameterDocs...,
+					),
+				},
+			},
+			prettier.SoftLine{},
+			typeParameterListEndDoc,
+		},
+	}
+}
+
+func (l *TypeParameterList) String() string {
+	return Prettier(l)
+}
@@ -0,0 +1,121 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeParameterList_MarshalJSON(t *testing.T) {
+
+	t.Parallel()
+
+	list := &TypeParameterList{
+		TypeParameters: []*TypeParameter{
+			{
+				Identifier: Identifier{
+					Identifier: "T",
+					Pos:        Position{Offset: 1, Line: 2, Column: 3},
+				},
+			},
+		},
+		Range: Range{
+			StartPos: Position{Offset: 0, Line: 2, Column: 2},
+			EndPos:   Position{Offset: 2, Line: 2, Column: 4},
+		},
+	}
+
+	actual, err := json.Marshal(list)
+	require.NoError(t, err)
+
+	assert.JSONEq(t,
+		`
+        {
+            "TypeParameters": [
+                {
+                    "Identifier": {
+                        "Identifier": "T",
+                        "StartPos": {"Offset": 1, "Line": 2, "Column": 3},
+                        "EndPos": {"Offset": 1, "Line": 2, "Column": 3}
+                    },
+                    "StartPos": {"Offset": 1, "Line": 2, "Column": 3},
+                    "EndPos": {"Offset": 1, "Line": 2, "Column": 3}
+                }
+            ],
+            "StartPos": {"Offset": 0, "Line": 2, "Column": 2},
+            "EndPos": {"Offset": 2, "Line": 2, "Column": 4}
+        }
+        `,
+		string(actual),
+	)
+}
+
+func TestTypeParameterList_String(t *testing.T) {
+
+	t.Parallel()
+
+	list := &TypeParameterList{
+		TypeParameters: []*TypeParameter{
+			{
+				Identifier: Identifier{
+					Identifier: "T",
+				},
+				TypeBound: &TypeAnnotation{
+					Type: &NominalType{
+						Identifier: Identifier{
+							Identifier: "AnyStruct",
+						},
+					},
+				},
+			},
+			{
+				Identifier: Identifier{
+					Identifier: "U",
+				},
+			},
+		},
+	}
+
+	require.Equal(t,
+		"<T: AnyStruct, U>",
+		list.String(),
+	)
+
+	decl := &FunctionDeclaration{
+		Identifier: Identifier{
+			Identifier: "test",
+		},
+		TypeParameterList: list,
+		ParameterList:     &ParameterList{},
+		FunctionBlock: &FunctionBlock{
+			Block: &Block{
+				Statements: []Statement{},
+			},
+		},
+	}
+
+	require.Equal(t,
+		"fun test<T: AnyStruct, U>() {}",
+		decl.String(),
+	)
+}
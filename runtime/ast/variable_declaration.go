@@ -38,6 +38,7 @@ type VariableDeclaration struct {
 	SecondValue       Expression
 	ParentIfStatement *IfStatement `json:"-"`
 	DocString         string
+	Comments          `json:"-"`
 }
 
 var _ Element = &VariableDeclaration{}
@@ -69,6 +69,7 @@ type DeclarationVisitor interface {
 	VisitEnumCaseDeclaration(*EnumCaseDeclaration) Repr
 	VisitPragmaDeclaration(*PragmaDeclaration) Repr
 	VisitImportDeclaration(*ImportDeclaration) Repr
+	VisitTypeAliasDeclaration(*TypeAliasDeclaration) Repr
 }
 
 type StatementVisitor interface {
@@ -110,6 +111,17 @@ type ExpressionVisitor interface {
 	VisitPathExpression(*PathExpression) Repr
 }
 
+// StringTemplateExpressionVisitor is implemented by expression visitors
+// which support string template expressions.
+//
+// It is separate from ExpressionVisitor, so existing implementations of ExpressionVisitor
+// outside of this repository still compile. However, visitors which do not implement it
+// fail when they visit a string template expression, see StringTemplateExpression.AcceptExp
+//
+type StringTemplateExpressionVisitor interface {
+	VisitStringTemplateExpression(*StringTemplateExpression) Repr
+}
+
 type Visitor interface {
 	StatementVisitor
 	ExpressionVisitor
@@ -134,6 +134,26 @@ func PrepareChecker(
 	must func(error),
 ) (*sema.Checker, func(error)) {
 
+	checker, err := NewChecker(program, location, codes, memberAccountAccess)
+	must(err)
+
+	return checker, must
+}
+
+// NewChecker returns a new checker for the given program,
+// with the default options of the command-line tools.
+//
+// The given options are applied after the default options,
+// so they may override them, e.g. to handle imports differently.
+//
+func NewChecker(
+	program *ast.Program,
+	location common.Location,
+	codes map[common.Location]string,
+	memberAccountAccess map[common.LocationID]map[common.LocationID]struct{},
+	options ...sema.Option,
+) (*sema.Checker, error) {
+
 	defaultCheckerOptions, _ :=
 		DefaultCheckerInterpreterOptions(
 			checkers,
@@ -158,16 +178,13 @@ func PrepareChecker(
 		}),
 	)
 
-	checker, err := sema.NewChecker(
+	return sema.NewChecker(
 		program,
 		location,
 		nil,
 		false,
-		defaultCheckerOptions...,
+		append(defaultCheckerOptions, options...)...,
 	)
-	must(err)
-
-	return checker, must
 }
 
 func PrepareInterpreter(filename string, debugger *interpreter.Debugger) (*interpreter.Interpreter, *sema.Checker, func(error)) {
@@ -183,6 +200,34 @@ func PrepareInterpreter(filename string, debugger *interpreter.Debugger) (*inter
 
 	must(checker.Check())
 
+	inter, err := NewInterpreter(
+		interpreter.ProgramFromChecker(checker),
+		checker.Location,
+		codes,
+		debugger,
+	)
+	must(err)
+
+	must(inter.Interpret())
+
+	return inter, checker, must
+}
+
+// NewInterpreter returns a new interpreter for the given checked program,
+// with the default options of the command-line tools:
+// an in-memory storage, a UUID handler, the given debugger, and the default predeclared values.
+//
+// The given options are applied after the default options,
+// so they may override them, e.g. to handle imports differently.
+//
+func NewInterpreter(
+	program *interpreter.Program,
+	location common.Location,
+	codes map[common.Location]string,
+	debugger *interpreter.Debugger,
+	options ...interpreter.Option,
+) (*interpreter.Interpreter, error) {
+
 	var uuid uint64
 
 	storage := interpreter.NewInMemoryStorage(nil)
@@ -216,16 +261,16 @@ func PrepareInterpreter(filename string, debugger *interpreter.Debugger) (*inter
 		defaultInterpreterOptions...,
 	)
 
-	inter, err := interpreter.NewInterpreter(
-		interpreter.ProgramFromChecker(checker),
-		checker.Location,
-		interpreterOptions...,
+	interpreterOptions = append(
+		interpreterOptions,
+		options...,
 	)
-	must(err)
 
-	must(inter.Interpret())
-
-	return inter, checker, must
+	return interpreter.NewInterpreter(
+		program,
+		location,
+		interpreterOptions...,
+	)
 }
 
 func ExitWithError(message string) {
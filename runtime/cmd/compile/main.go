@@ -19,14 +19,12 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
-	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/cmd"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/compiler"
-	"github.com/onflow/cadence/runtime/compiler/ir"
-	"github.com/onflow/cadence/runtime/compiler/wasm"
 )
 
 func main() {
@@ -48,52 +46,14 @@ func main() {
 
 	must(checker.Check())
 
-	// Compile all functions
+	// Compile the program to bytecode
 
-	comp := compiler.NewCompiler(checker)
+	comp := compiler.NewCompiler(checker.Program, checker.Elaboration, checker.Location)
 
-	functionDeclarations := checker.Program.FunctionDeclarations()
+	compiled, err := comp.Compile()
+	must(err)
 
-	funcs := make([]*ir.Func, len(functionDeclarations))
+	// Write the disassembly to stdout
 
-	for i, functionDeclaration := range functionDeclarations {
-		funcs[i] = functionDeclaration.Accept(comp).(*ir.Func)
-	}
-
-	// Generate a WebAssembly module for the functions
-
-	module := compiler.GenerateWasm(funcs)
-
-	// Export all public functions
-
-	for i, functionDeclaration := range functionDeclarations {
-		if functionDeclaration.Access != ast.AccessPublic {
-			continue
-		}
-
-		module.Exports = append(module.Exports,
-			&wasm.Export{
-				Name: functionDeclaration.Identifier.Identifier,
-				Descriptor: wasm.FunctionExport{
-					FunctionIndex: uint32(i),
-				},
-			},
-		)
-	}
-
-	// Generate WASM binary
-
-	var buf wasm.Buffer
-	w := wasm.NewWASMWriter(&buf)
-	err := w.WriteModule(module)
-	if err != nil {
-		panic(nil)
-	}
-
-	// Write WASM binary to stdout
-
-	_, err = os.Stdout.Write(buf.Bytes())
-	if err != nil {
-		panic(nil)
-	}
+	fmt.Print(compiler.Disassemble(compiled))
 }
@@ -16,29 +16,24 @@
  * limitations under the License.
  */
 
-package ir
-
-type Constant interface {
-	isConstant()
-	Accept(Visitor) Repr
-}
-
-type Int struct {
-	Value []byte
-}
-
-func (Int) isConstant() {}
-
-func (c Int) Accept(v Visitor) Repr {
-	return v.VisitInt(c)
-}
-
-type String struct {
-	Value string
-}
-
-func (String) isConstant() {}
-
-func (c String) Accept(v Visitor) Repr {
-	return v.VisitString(c)
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/onflow/cadence/tools/dap"
+)
+
+// main runs a Debug Adapter Protocol server for Cadence programs,
+// which communicates over STDIN and STDOUT
+//
+func main() {
+	server := dap.NewServer(dap.NewStdioStream())
+
+	err := server.Run()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -40,10 +40,23 @@ const commandShortShow = "s"
 const commandLongShow = "show"
 const commandShortWhere = "w"
 const commandLongWhere = "where"
+const commandLongStep = "step"
+const commandLongOver = "over"
+const commandLongOut = "out"
+const commandLongUp = "up"
+const commandLongDown = "down"
+const commandShortBacktrace = "bt"
+const commandLongBacktrace = "backtrace"
 
 var debuggerCommandSuggestions = []prompt.Suggest{
 	{Text: commandLongContinue, Description: "Continue"},
 	{Text: commandLongNext, Description: "Next / step"},
+	{Text: commandLongStep, Description: "Step into the next function call"},
+	{Text: commandLongOver, Description: "Step over the next function call"},
+	{Text: commandLongOut, Description: "Step out of the current function"},
+	{Text: commandLongUp, Description: "Select the calling frame"},
+	{Text: commandLongDown, Description: "Select the called frame"},
+	{Text: commandLongBacktrace, Description: "Show the call stack"},
 	{Text: commandLongWhere, Description: "Location info"},
 	{Text: commandLongShow, Description: "Show variable(s)"},
 	{Text: commandLongExit, Description: "Exit"},
@@ -53,13 +66,34 @@ var debuggerCommandSuggestions = []prompt.Suggest{
 type InteractiveDebugger struct {
 	debugger *interpreter.Debugger
 	stop     interpreter.Stop
+	// frame is the index of the selected frame in the call stack of the stop
+	frame int
 }
 
 func NewInteractiveDebugger(debugger *interpreter.Debugger, stop interpreter.Stop) *InteractiveDebugger {
-	return &InteractiveDebugger{
+	d := &InteractiveDebugger{
 		debugger: debugger,
-		stop:     stop,
 	}
+	d.setStop(stop)
+	return d
+}
+
+func (d *InteractiveDebugger) setStop(stop interpreter.Stop) {
+	d.stop = stop
+	d.frame = len(stop.CallStack) - 1
+}
+
+// selectedFrame returns the selected frame of the call stack
+//
+func (d *InteractiveDebugger) selectedFrame() interpreter.StackFrame {
+	if d.frame < 0 || d.frame >= len(d.stop.CallStack) {
+		return interpreter.StackFrame{
+			Interpreter: d.stop.Interpreter,
+			Statement:   d.stop.Statement,
+			Activation:  d.debugger.CurrentActivation(d.stop.Interpreter),
+		}
+	}
+	return d.stop.CallStack[d.frame]
 }
 
 func (d *InteractiveDebugger) Continue() {
@@ -67,14 +101,75 @@ func (d *InteractiveDebugger) Continue() {
 }
 
 func (d *InteractiveDebugger) Next() {
-	d.stop = d.debugger.Next()
+	d.setStop(d.debugger.Next())
+}
+
+func (d *InteractiveDebugger) StepIn() {
+	d.setStop(d.debugger.StepIn())
+}
+
+func (d *InteractiveDebugger) StepOver() {
+	d.setStop(d.debugger.StepOver())
+}
+
+func (d *InteractiveDebugger) StepOut() {
+	d.setStop(d.debugger.StepOut())
+}
+
+// Up selects the frame of the calling function
+//
+func (d *InteractiveDebugger) Up() {
+	if d.frame <= 0 {
+		fmt.Println(colorizeError("error: already in the outermost frame"))
+		return
+	}
+	d.frame--
+	d.Where()
+}
+
+// Down selects the frame of the called function
+//
+func (d *InteractiveDebugger) Down() {
+	if d.frame >= len(d.stop.CallStack)-1 {
+		fmt.Println(colorizeError("error: already in the innermost frame"))
+		return
+	}
+	d.frame++
+	d.Where()
+}
+
+// Backtrace shows the call stack, from the innermost to the outermost frame.
+// The selected frame is marked
+//
+func (d *InteractiveDebugger) Backtrace() {
+	for i := len(d.stop.CallStack) - 1; i >= 0; i-- {
+		marker := " "
+		if i == d.frame {
+			marker = "*"
+		}
+
+		frame := d.stop.CallStack[i]
+
+		name := frame.FunctionName
+		if name == "" {
+			name = "<anonymous>"
+		}
+
+		fmt.Printf(
+			"%s #%d %s at %s\n",
+			marker,
+			len(d.stop.CallStack)-1-i,
+			name,
+			formatFrameLocation(frame),
+		)
+	}
 }
 
 // Show shows the values for the variables with the given names.
 // If no names are given, lists all non-base variables
 //
 func (d *InteractiveDebugger) Show(names []string) {
-	current := d.debugger.CurrentActivation(d.stop.Interpreter)
+	current := d.selectedFrame().Activation
 	switch len(names) {
 	case 0:
 		for name := range current.FunctionValues() { //nolint:maprangecheck
@@ -127,6 +222,18 @@ func (d *InteractiveDebugger) Run() {
 			d.Show(arguments)
 		case commandShortWhere, commandLongWhere:
 			d.Where()
+		case commandLongStep:
+			d.StepIn()
+		case commandLongOver:
+			d.StepOver()
+		case commandLongOut:
+			d.StepOut()
+		case commandLongUp:
+			d.Up()
+		case commandLongDown:
+			d.Down()
+		case commandShortBacktrace, commandLongBacktrace:
+			d.Backtrace()
 		case commandShortHelp, commandLongHelp:
 			d.Help()
 		case commandLongExit:
@@ -177,9 +284,17 @@ func (d *InteractiveDebugger) Help() {
 }
 
 func (d *InteractiveDebugger) Where() {
-	fmt.Printf(
-		"%s @ %d\n",
-		d.stop.Interpreter.Location,
-		d.stop.Statement.StartPosition().Line,
+	fmt.Println(formatFrameLocation(d.selectedFrame()))
+}
+
+func formatFrameLocation(frame interpreter.StackFrame) string {
+	if frame.Statement == nil {
+		return frame.Interpreter.Location.String()
+	}
+
+	return fmt.Sprintf(
+		"%s @ %d",
+		frame.Interpreter.Location,
+		frame.Statement.StartPosition().Line,
 	)
 }
@@ -0,0 +1,115 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/pretty"
+	"github.com/onflow/cadence/tools/formatter"
+)
+
+var checkFlag = flag.Bool("check", false, "do not write the formatted code, but exit with a non-zero status if any file is not formatted")
+var writeFlag = flag.Bool("w", false, "write the formatted code to the files instead of the standard output")
+var widthFlag = flag.Int("width", formatter.DefaultMaxLineWidth, "the maximum line width")
+
+func main() {
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: fmt [-check] [-w] [-width n] <path>...")
+		os.Exit(2)
+	}
+
+	config := formatter.Config{
+		MaxLineWidth: *widthFlag,
+	}
+
+	succeeded := true
+
+	for _, path := range paths {
+		if !formatPath(path, config) {
+			succeeded = false
+		}
+	}
+
+	if !succeeded {
+		os.Exit(1)
+	}
+}
+
+func formatPath(path string, config formatter.Config) (succeeded bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	code := string(data)
+
+	formatted, err := formatter.Format(code, config)
+	if err != nil {
+		location := common.StringLocation(path)
+		codes := map[common.Location]string{
+			location: code,
+		}
+		printErr := pretty.NewErrorPrettyPrinter(os.Stderr, true).
+			PrettyPrintError(err, location, codes)
+		if printErr != nil {
+			panic(printErr)
+		}
+		return false
+	}
+
+	switch {
+	case *checkFlag:
+		if formatted != code {
+			// Like gofmt -l, list the files which are not formatted
+			fmt.Println(path)
+			return false
+		}
+
+	case *writeFlag:
+		if formatted == code {
+			return true
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+
+		err = ioutil.WriteFile(path, []byte(formatted), info.Mode().Perm())
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+
+	default:
+		fmt.Print(formatted)
+	}
+
+	return true
+}
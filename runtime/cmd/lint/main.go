@@ -0,0 +1,219 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/pretty"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+var fixFlag = flag.Bool("fix", false, "apply the suggested fixes to the files")
+var analyzersFlag = flag.String("analyzers", "", "comma-separated names of the analyzers to run (default: all)")
+
+func main() {
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: lint [-fix] [-analyzers name,...] <path>...")
+		os.Exit(2)
+	}
+
+	var names []string
+	if *analyzersFlag != "" {
+		names = strings.Split(*analyzersFlag, ",")
+	} else {
+		names = make([]string, 0, len(analyzers.Analyzers))
+		for name := range analyzers.Analyzers { //nolint:maprangecheck
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	enabledAnalyzers := make([]*analysis.Analyzer, 0, len(names))
+	for _, name := range names {
+		analyzer, ok := analyzers.Analyzers[strings.TrimSpace(name)]
+		if !ok {
+			_, _ = fmt.Fprintf(os.Stderr, "unknown analyzer: %s\n", name)
+			os.Exit(2)
+		}
+		enabledAnalyzers = append(enabledAnalyzers, analyzer)
+	}
+
+	succeeded := true
+
+	for _, path := range paths {
+		if !lintPath(path, enabledAnalyzers) {
+			succeeded = false
+		}
+	}
+
+	if !succeeded {
+		os.Exit(1)
+	}
+}
+
+func lintPath(path string, enabledAnalyzers []*analysis.Analyzer) (succeeded bool) {
+	location := common.StringLocation(path)
+
+	codes := map[common.Location]string{}
+
+	config := &analysis.Config{
+		Mode: analysis.NeedTypes,
+		ResolveAddressContractNames: func(address common.Address) ([]string, error) {
+			return nil, fmt.Errorf("cannot import address %s", address)
+		},
+		ResolveCode: func(
+			location common.Location,
+			_ common.Location,
+			_ ast.Range,
+		) (string, error) {
+			stringLocation, ok := location.(common.StringLocation)
+			if !ok {
+				return "", fmt.Errorf("cannot import location %s", location)
+			}
+
+			data, err := ioutil.ReadFile(string(stringLocation))
+			if err != nil {
+				return "", err
+			}
+
+			code := string(data)
+			codes[location] = code
+			return code, nil
+		},
+	}
+
+	programs, err := analysis.Load(config, location)
+	if err != nil {
+		printErr := pretty.NewErrorPrettyPrinter(os.Stderr, true).
+			PrettyPrintError(err, location, codes)
+		if printErr != nil {
+			panic(printErr)
+		}
+		return false
+	}
+
+	program := programs[location]
+
+	var diagnostics []analysis.Diagnostic
+	program.Run(enabledAnalyzers, func(diagnostic analysis.Diagnostic) {
+		diagnostics = append(diagnostics, diagnostic)
+	})
+
+	// Analyzers run in parallel, so sort the diagnostics by position
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].StartPos.Offset < diagnostics[j].StartPos.Offset
+	})
+
+	if *fixFlag {
+		var fixCount int
+		diagnostics, fixCount, err = fixPath(path, program.Code, diagnostics)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+		if fixCount > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: applied %d fixes\n", path, fixCount)
+		}
+	}
+
+	for _, diagnostic := range diagnostics {
+		printDiagnostic(path, diagnostic)
+	}
+
+	return len(diagnostics) == 0
+}
+
+// fixPath applies the suggested fixes to the file,
+// and returns the diagnostics which were not fixed.
+//
+func fixPath(
+	path string,
+	code string,
+	diagnostics []analysis.Diagnostic,
+) (
+	remaining []analysis.Diagnostic,
+	fixCount int,
+	err error,
+) {
+	var fixable []analysis.Diagnostic
+	for _, diagnostic := range diagnostics {
+		if len(diagnostic.SuggestedFixes) == 0 {
+			remaining = append(remaining, diagnostic)
+			continue
+		}
+		fixable = append(fixable, diagnostic)
+	}
+
+	fixed, fixCount := analysis.ApplyFixes(code, fixable)
+	if fixCount == 0 {
+		return diagnostics, 0, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = ioutil.WriteFile(path, []byte(fixed), info.Mode().Perm())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Fixes which were skipped because they overlap with other fixes
+	// are reported, and can be applied by running the linter again
+
+	if fixCount < len(fixable) {
+		_, _ = fmt.Fprintf(
+			os.Stderr,
+			"%s: %d fixes overlap with other fixes, run again to apply them\n",
+			path,
+			len(fixable)-fixCount,
+		)
+	}
+
+	return remaining, fixCount, nil
+}
+
+func printDiagnostic(path string, diagnostic analysis.Diagnostic) {
+	message := diagnostic.Message
+	if diagnostic.SecondaryMessage != "" {
+		message = fmt.Sprintf("%s: %s", message, diagnostic.SecondaryMessage)
+	}
+
+	_, _ = fmt.Printf(
+		"%s:%d:%d: %s\n",
+		path,
+		diagnostic.StartPos.Line,
+		diagnostic.StartPos.Column+1,
+		message,
+	)
+}
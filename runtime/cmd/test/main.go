@@ -0,0 +1,155 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/pretty"
+	"github.com/onflow/cadence/tools/test"
+)
+
+var coverFlag = flag.String("cover", "", "write a coverage report to the given file")
+var coverFormatFlag = flag.String("coverformat", "json", "format of the coverage report: json, lcov, or cobertura")
+var coverExcludeFlag = flag.String("coverexclude", "", "exclude locations matching the given regular expression from the coverage report")
+
+func main() {
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: test [-cover file [-coverformat format] [-coverexclude pattern]] <path>...")
+		os.Exit(2)
+	}
+
+	var coverageReport *runtime.CoverageReport
+	if *coverFlag != "" {
+		coverageReport = runtime.NewCoverageReport()
+
+		if *coverExcludeFlag != "" {
+			err := coverageReport.ExcludeLocations(*coverExcludeFlag)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+		}
+	}
+
+	allSucceeded := true
+
+	for _, path := range paths {
+		if !runPath(path, coverageReport) {
+			allSucceeded = false
+		}
+	}
+
+	if coverageReport != nil {
+		err := writeCoverageReport(*coverFlag, *coverFormatFlag, coverageReport)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to write coverage report: %s\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("coverage: %s of statements\n", coverageReport.Percentage())
+	}
+
+	if !allSucceeded {
+		os.Exit(1)
+	}
+}
+
+func runPath(path string, coverageReport *runtime.CoverageReport) (succeeded bool) {
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	location := common.NewStringLocation(nil, path)
+
+	codes := map[common.Location]string{
+		location: string(code),
+	}
+
+	fmt.Println(path)
+
+	runner := test.NewTestRunner().
+		WithCoverageReport(coverageReport)
+
+	results, err := runner.RunTests(location, string(code))
+	if err != nil {
+		printErr := pretty.NewErrorPrettyPrinter(os.Stderr, true).
+			PrettyPrintError(err, location, codes)
+		if printErr != nil {
+			_, _ = fmt.Fprintln(os.Stderr, printErr)
+		}
+		return false
+	}
+
+	err = test.PrettyPrintResults(os.Stdout, results, location, codes, true)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to print results: %s\n", err)
+		return false
+	}
+
+	for _, result := range results {
+		if result.Error != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeCoverageReport(path string, format string, coverageReport *runtime.CoverageReport) error {
+	var buffer bytes.Buffer
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(coverageReport, "", "  ")
+		if err != nil {
+			return err
+		}
+		buffer.Write(data)
+
+	case "lcov":
+		err := coverageReport.WriteLCOV(&buffer)
+		if err != nil {
+			return err
+		}
+
+	case "cobertura":
+		err := coverageReport.WriteCobertura(&buffer)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported coverage report format: %s", format)
+	}
+
+	return ioutil.WriteFile(path, buffer.Bytes(), 0644)
+}
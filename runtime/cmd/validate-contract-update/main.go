@@ -0,0 +1,233 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/pretty"
+)
+
+const contractFileExtension = ".cdc"
+
+var importsFlag = flag.String(
+	"imports",
+	"",
+	"the directory containing the contracts imported from addresses, as <name>.cdc (default: the directory of the new code)",
+)
+
+func main() {
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 2 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: validate-contract-update [-imports dir] <old path> <new path>")
+		os.Exit(2)
+	}
+
+	oldPath := args[0]
+	newPath := args[1]
+
+	oldInfo, err := os.Stat(oldPath)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	newInfo, err := os.Stat(newPath)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	var succeeded bool
+
+	switch {
+	case oldInfo.IsDir() && newInfo.IsDir():
+		importsDir := *importsFlag
+		if importsDir == "" {
+			importsDir = newPath
+		}
+		succeeded = validateDirectory(oldPath, newPath, importsDir)
+
+	case !oldInfo.IsDir() && !newInfo.IsDir():
+		importsDir := *importsFlag
+		if importsDir == "" {
+			importsDir = filepath.Dir(newPath)
+		}
+		succeeded = validateFile(oldPath, newPath, importsDir)
+
+	default:
+		exitWithError(fmt.Errorf("paths must either both be files or both be directories"))
+	}
+
+	if !succeeded {
+		os.Exit(1)
+	}
+}
+
+// validateDirectory validates the update of each contract in the old directory
+// to the contract with the same file name in the new directory.
+//
+func validateDirectory(oldDir, newDir, importsDir string) (succeeded bool) {
+	entries, err := ioutil.ReadDir(oldDir)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), contractFileExtension) {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	succeeded = true
+
+	for _, name := range names {
+		newPath := filepath.Join(newDir, name)
+
+		_, err := os.Stat(newPath)
+		if os.IsNotExist(err) {
+			_, _ = fmt.Fprintf(os.Stdout, "%s: skipped, no new version\n", name)
+			continue
+		}
+
+		if !validateFile(filepath.Join(oldDir, name), newPath, importsDir) {
+			succeeded = false
+		}
+	}
+
+	return succeeded
+}
+
+// validateFile validates the update of the contract in the old file
+// to the contract in the new file, and pretty-prints all errors.
+//
+func validateFile(oldPath, newPath, importsDir string) (succeeded bool) {
+	oldCode, err := ioutil.ReadFile(oldPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	newCode, err := ioutil.ReadFile(newPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	oldLocation := common.StringLocation(oldPath)
+	newLocation := common.StringLocation(newPath)
+
+	// The old and the new code, and their imports, are read from different files,
+	// so the sources are recorded separately
+
+	oldCodes := map[common.Location]string{
+		oldLocation: string(oldCode),
+	}
+	newCodes := map[common.Location]string{
+		newLocation: string(newCode),
+	}
+
+	err = runtime.ValidateContractCodeUpdate(
+		oldLocation,
+		oldCode,
+		newImportResolver(filepath.Dir(oldPath), importsDir, oldCodes),
+		newLocation,
+		newCode,
+		newImportResolver(filepath.Dir(newPath), importsDir, newCodes),
+	)
+	if err != nil {
+		// Parsing and checking errors of the old code are reported for the old location,
+		// all other errors are reported for the new code
+
+		location := newLocation
+		codes := newCodes
+
+		var parsingCheckingErr *runtime.ParsingCheckingError
+		if errors.As(err, &parsingCheckingErr) &&
+			parsingCheckingErr.Location == oldLocation {
+
+			location = oldLocation
+			codes = oldCodes
+		}
+
+		printErr := pretty.NewErrorPrettyPrinter(os.Stderr, true).
+			PrettyPrintError(err, location, codes)
+		if printErr != nil {
+			panic(printErr)
+		}
+		return false
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "%s: valid update\n", newPath)
+
+	return true
+}
+
+// newImportResolver returns an import resolver which reads imported files relative to the given directory,
+// and resolves contracts imported from addresses to the files in the imports directory.
+//
+func newImportResolver(dir string, importsDir string, codes map[common.Location]string) runtime.ImportResolver {
+	return func(location common.Location) (*ast.Program, error) {
+
+		var path string
+
+		switch location := location.(type) {
+		case common.StringLocation:
+			path = string(location)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+		case common.AddressLocation:
+			path = filepath.Join(importsDir, location.Name+contractFileExtension)
+
+		default:
+			return nil, fmt.Errorf("cannot import location %s", location)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		code := string(data)
+		codes[location] = code
+
+		return parser.ParseProgram(code, nil)
+	}
+}
+
+func exitWithError(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -84,7 +84,8 @@ const (
 	_
 	_
 	_
-	_
+	// interpreter - string operations
+	ComputationKindStringManipulation
 	_
 	_
 	_
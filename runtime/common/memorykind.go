@@ -233,6 +233,13 @@ const (
 	MemoryKindOrderedMapEntryList
 	MemoryKindOrderedMapEntry
 
+	// New kinds are appended here, before the placeholder,
+	// so the values of the existing kinds do not change
+	MemoryKindStringTemplateExpression
+	MemoryKindTypeAliasDeclaration
+	MemoryKindTypeParameter
+	MemoryKindTypeParameterList
+
 	// Placeholder kind to allow consistent indexing
 	// this should always be the last kind
 	MemoryKindLast
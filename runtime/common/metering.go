@@ -44,16 +44,18 @@ var (
 
 	// AST
 
-	ProgramMemoryUsage         = NewConstantMemoryUsage(MemoryKindProgram)
-	IdentifierMemoryUsage      = NewConstantMemoryUsage(MemoryKindIdentifier)
-	ArgumentMemoryUsage        = NewConstantMemoryUsage(MemoryKindArgument)
-	BlockMemoryUsage           = NewConstantMemoryUsage(MemoryKindBlock)
-	FunctionBlockMemoryUsage   = NewConstantMemoryUsage(MemoryKindFunctionBlock)
-	ParameterMemoryUsage       = NewConstantMemoryUsage(MemoryKindParameter)
-	ParameterListMemoryUsage   = NewConstantMemoryUsage(MemoryKindParameterList)
-	TransferMemoryUsage        = NewConstantMemoryUsage(MemoryKindTransfer)
-	TypeAnnotationMemoryUsage  = NewConstantMemoryUsage(MemoryKindTypeAnnotation)
-	DictionaryEntryMemoryUsage = NewConstantMemoryUsage(MemoryKindDictionaryEntry)
+	ProgramMemoryUsage           = NewConstantMemoryUsage(MemoryKindProgram)
+	IdentifierMemoryUsage        = NewConstantMemoryUsage(MemoryKindIdentifier)
+	ArgumentMemoryUsage          = NewConstantMemoryUsage(MemoryKindArgument)
+	BlockMemoryUsage             = NewConstantMemoryUsage(MemoryKindBlock)
+	FunctionBlockMemoryUsage     = NewConstantMemoryUsage(MemoryKindFunctionBlock)
+	ParameterMemoryUsage         = NewConstantMemoryUsage(MemoryKindParameter)
+	ParameterListMemoryUsage     = NewConstantMemoryUsage(MemoryKindParameterList)
+	TypeParameterMemoryUsage     = NewConstantMemoryUsage(MemoryKindTypeParameter)
+	TypeParameterListMemoryUsage = NewConstantMemoryUsage(MemoryKindTypeParameterList)
+	TransferMemoryUsage          = NewConstantMemoryUsage(MemoryKindTransfer)
+	TypeAnnotationMemoryUsage    = NewConstantMemoryUsage(MemoryKindTypeAnnotation)
+	DictionaryEntryMemoryUsage   = NewConstantMemoryUsage(MemoryKindDictionaryEntry)
 
 	// AST Declarations
 
@@ -67,6 +69,7 @@ var (
 	VariableDeclarationMemoryUsage        = NewConstantMemoryUsage(MemoryKindVariableDeclaration)
 	SpecialFunctionDeclarationMemoryUsage = NewConstantMemoryUsage(MemoryKindSpecialFunctionDeclaration)
 	PragmaDeclarationMemoryUsage          = NewConstantMemoryUsage(MemoryKindPragmaDeclaration)
+	TypeAliasDeclarationMemoryUsage       = NewConstantMemoryUsage(MemoryKindTypeAliasDeclaration)
 
 	// AST Statements
 
@@ -84,25 +87,26 @@ var (
 
 	// AST Expressions
 
-	BooleanExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindBooleanExpression)
-	NilExpressionMemoryUsage         = NewConstantMemoryUsage(MemoryKindNilExpression)
-	StringExpressionMemoryUsage      = NewConstantMemoryUsage(MemoryKindStringExpression)
-	IntegerExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindIntegerExpression)
-	FixedPointExpressionMemoryUsage  = NewConstantMemoryUsage(MemoryKindFixedPointExpression)
-	IdentifierExpressionMemoryUsage  = NewConstantMemoryUsage(MemoryKindIdentifierExpression)
-	InvocationExpressionMemoryUsage  = NewConstantMemoryUsage(MemoryKindInvocationExpression)
-	MemberExpressionMemoryUsage      = NewConstantMemoryUsage(MemoryKindMemberExpression)
-	IndexExpressionMemoryUsage       = NewConstantMemoryUsage(MemoryKindIndexExpression)
-	ConditionalExpressionMemoryUsage = NewConstantMemoryUsage(MemoryKindConditionalExpression)
-	UnaryExpressionMemoryUsage       = NewConstantMemoryUsage(MemoryKindUnaryExpression)
-	BinaryExpressionMemoryUsage      = NewConstantMemoryUsage(MemoryKindBinaryExpression)
-	FunctionExpressionMemoryUsage    = NewConstantMemoryUsage(MemoryKindFunctionExpression)
-	CastingExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindCastingExpression)
-	CreateExpressionMemoryUsage      = NewConstantMemoryUsage(MemoryKindCreateExpression)
-	DestroyExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindDestroyExpression)
-	ReferenceExpressionMemoryUsage   = NewConstantMemoryUsage(MemoryKindReferenceExpression)
-	ForceExpressionMemoryUsage       = NewConstantMemoryUsage(MemoryKindForceExpression)
-	PathExpressionMemoryUsage        = NewConstantMemoryUsage(MemoryKindPathExpression)
+	BooleanExpressionMemoryUsage        = NewConstantMemoryUsage(MemoryKindBooleanExpression)
+	NilExpressionMemoryUsage            = NewConstantMemoryUsage(MemoryKindNilExpression)
+	StringExpressionMemoryUsage         = NewConstantMemoryUsage(MemoryKindStringExpression)
+	IntegerExpressionMemoryUsage        = NewConstantMemoryUsage(MemoryKindIntegerExpression)
+	FixedPointExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindFixedPointExpression)
+	IdentifierExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindIdentifierExpression)
+	InvocationExpressionMemoryUsage     = NewConstantMemoryUsage(MemoryKindInvocationExpression)
+	MemberExpressionMemoryUsage         = NewConstantMemoryUsage(MemoryKindMemberExpression)
+	IndexExpressionMemoryUsage          = NewConstantMemoryUsage(MemoryKindIndexExpression)
+	ConditionalExpressionMemoryUsage    = NewConstantMemoryUsage(MemoryKindConditionalExpression)
+	UnaryExpressionMemoryUsage          = NewConstantMemoryUsage(MemoryKindUnaryExpression)
+	BinaryExpressionMemoryUsage         = NewConstantMemoryUsage(MemoryKindBinaryExpression)
+	FunctionExpressionMemoryUsage       = NewConstantMemoryUsage(MemoryKindFunctionExpression)
+	CastingExpressionMemoryUsage        = NewConstantMemoryUsage(MemoryKindCastingExpression)
+	CreateExpressionMemoryUsage         = NewConstantMemoryUsage(MemoryKindCreateExpression)
+	DestroyExpressionMemoryUsage        = NewConstantMemoryUsage(MemoryKindDestroyExpression)
+	ReferenceExpressionMemoryUsage      = NewConstantMemoryUsage(MemoryKindReferenceExpression)
+	ForceExpressionMemoryUsage          = NewConstantMemoryUsage(MemoryKindForceExpression)
+	PathExpressionMemoryUsage           = NewConstantMemoryUsage(MemoryKindPathExpression)
+	StringTemplateExpressionMemoryUsage = NewConstantMemoryUsage(MemoryKindStringTemplateExpression)
 
 	// AST Types
 
@@ -301,20 +305,20 @@ func newAtreeMemoryUsage(count uint64, elementSize uint, array bool) (MemoryUsag
 	newLeafNodes, newBranchNodes := atreeNodes(count, elementSize)
 	if array {
 		return MemoryUsage{
-				Kind:   MemoryKindAtreeArrayDataSlab,
-				Amount: newLeafNodes,
-			}, MemoryUsage{
-				Kind:   MemoryKindAtreeArrayMetaDataSlab,
-				Amount: newBranchNodes,
-			}
+			Kind:   MemoryKindAtreeArrayDataSlab,
+			Amount: newLeafNodes,
+		}, MemoryUsage{
+			Kind:   MemoryKindAtreeArrayMetaDataSlab,
+			Amount: newBranchNodes,
+		}
 	} else {
 		return MemoryUsage{
-				Kind:   MemoryKindAtreeMapDataSlab,
-				Amount: newLeafNodes,
-			}, MemoryUsage{
-				Kind:   MemoryKindAtreeMapMetaDataSlab,
-				Amount: newBranchNodes,
-			}
+			Kind:   MemoryKindAtreeMapDataSlab,
+			Amount: newLeafNodes,
+		}, MemoryUsage{
+			Kind:   MemoryKindAtreeMapMetaDataSlab,
+			Amount: newBranchNodes,
+		}
 	}
 }
 
@@ -330,20 +334,20 @@ func AdditionalAtreeMemoryUsage(originalCount uint64, elementSize uint, array bo
 	newLeafNodes, newBranchNodes := atreeNodes(originalCount+1, elementSize)
 	if array {
 		return MemoryUsage{
-				Kind:   MemoryKindAtreeArrayDataSlab,
-				Amount: newLeafNodes - originalLeafNodes,
-			}, MemoryUsage{
-				Kind:   MemoryKindAtreeArrayMetaDataSlab,
-				Amount: newBranchNodes - originalBranchNodes,
-			}
+			Kind:   MemoryKindAtreeArrayDataSlab,
+			Amount: newLeafNodes - originalLeafNodes,
+		}, MemoryUsage{
+			Kind:   MemoryKindAtreeArrayMetaDataSlab,
+			Amount: newBranchNodes - originalBranchNodes,
+		}
 	} else {
 		return MemoryUsage{
-				Kind:   MemoryKindAtreeMapDataSlab,
-				Amount: newLeafNodes - originalLeafNodes,
-			}, MemoryUsage{
-				Kind:   MemoryKindAtreeMapMetaDataSlab,
-				Amount: newBranchNodes - originalBranchNodes,
-			}
+			Kind:   MemoryKindAtreeMapDataSlab,
+			Amount: newLeafNodes - originalLeafNodes,
+		}, MemoryUsage{
+			Kind:   MemoryKindAtreeMapMetaDataSlab,
+			Amount: newBranchNodes - originalBranchNodes,
+		}
 	}
 }
 
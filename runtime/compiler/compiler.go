@@ -19,417 +19,1320 @@
 package compiler
 
 import (
+	"math"
+	"math/big"
+
 	"github.com/onflow/cadence/runtime/ast"
-	"github.com/onflow/cadence/runtime/compiler/ir"
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/errors"
 	"github.com/onflow/cadence/runtime/sema"
 )
 
+// InitializerFunctionName is the name of the function
+// which initializes the globals of a program.
+//
+const InitializerFunctionName = "$init"
+
+// DestructorFunctionName is the name of the method
+// which is the destructor of a composite.
+//
+const DestructorFunctionName = "destroy"
+
+// Compiler compiles a checked program to bytecode.
+//
+// Each function of the program, including the methods, initializers, and destructors
+// of composite declarations, is compiled to a separate function of the resulting program.
+// Nested functions and function expressions capture the locals of their enclosing functions.
+//
 type Compiler struct {
-	Checker     *sema.Checker
-	activations *LocalActivations
-	locals      []*Local
+	Program     *ast.Program
+	Elaboration *sema.Elaboration
+	Location    common.Location
+
+	function     *function
+	currentRange ast.Range
+	// compositeTypes are the types of the composite declarations
+	// which enclose the declaration that is currently compiled
+	compositeTypes []*sema.CompositeType
+
+	functions   []*Function
+	constants   []Constant
+	types       []sema.Type
+	invocations []Invocation
+	globals     []string
+	imports     []Import
+	contract    string
+
+	globalIndices   map[string]uint16
+	importIndices   map[Import]uint16
+	stringConstants map[string]uint16
+	typeIndices     map[sema.TypeID]uint16
+
+	// conformanceMembers are the members of the interfaces and composites declared in the program,
+	// by type. It is initialized when the first conformance is compiled
+	conformanceMembers map[sema.Type]*ast.Members
 }
 
-func NewCompiler(checker *sema.Checker) *Compiler {
+var _ ast.DeclarationVisitor = &Compiler{}
+var _ ast.StatementVisitor = &Compiler{}
+var _ ast.ExpressionVisitor = &Compiler{}
+var _ ast.StringTemplateExpressionVisitor = &Compiler{}
+
+func NewCompiler(
+	program *ast.Program,
+	elaboration *sema.Elaboration,
+	location common.Location,
+) *Compiler {
 	return &Compiler{
-		Checker:     checker,
-		activations: &LocalActivations{},
+		Program:         program,
+		Elaboration:     elaboration,
+		Location:        location,
+		globalIndices:   map[string]uint16{},
+		importIndices:   map[Import]uint16{},
+		stringConstants: map[string]uint16{},
+		typeIndices:     map[sema.TypeID]uint16{},
 	}
 }
 
-// declareLocal declares a local
-func (compiler *Compiler) declareLocal(identifier string, valType ir.ValType) *Local {
-	// NOTE: semantic analysis already checked possible invalid redeclaration
-	index := uint32(len(compiler.locals))
-	local := NewLocal(index, valType)
-	compiler.locals = append(compiler.locals, local)
-	compiler.setLocal(identifier, local)
-	return local
+// function is the state of a function which is being compiled.
+//
+type function struct {
+	*Function
+	// parent is the function which lexically encloses the function, if any
+	parent *function
+	// previous is the function which was compiled before the function
+	previous    *function
+	activations *LocalActivations
+	breakables  []*breakable
+	// hasEpilogue indicates if return statements jump to the end of the function,
+	// e.g. to evaluate post-conditions, instead of returning directly
+	hasEpilogue bool
+	// returns are the operand offsets of the jumps to the epilogue
+	returns []int
+	// result is the local which holds the result in the epilogue, if any
+	result *Local
 }
 
-func (compiler *Compiler) findLocal(name string) *Local {
-	return compiler.activations.Find(name)
+// breakable is a loop or switch statement which is being compiled.
+//
+type breakable struct {
+	isLoop bool
+	// breaks and continues are the operand offsets of the jumps
+	// of the break and continue statements
+	breaks    []int
+	continues []int
 }
 
-func (compiler *Compiler) setLocal(name string, variable *Local) {
-	compiler.activations.Set(name, variable)
-}
+// functionKind determines how the locals of a compiled function are laid out.
+//
+type functionKind uint8
+
+const (
+	functionKindFunction functionKind = iota
+	functionKindMethod
+	functionKindConstructor
+)
+
+// Compile compiles the program.
+//
+// Programs which use language features that are not supported by the compiler yet
+// result in an UnsupportedError.
+//
+func (compiler *Compiler) Compile() (program *Program, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			unsupportedErr, ok := r.(*UnsupportedError)
+			if !ok {
+				panic(r)
+			}
+			err = unsupportedErr
+		}
+	}()
+
+	declarations := compiler.Program.Declarations()
+
+	compiler.declareGlobals(declarations)
+
+	initializer := compiler.pushFunction(
+		InitializerFunctionName,
+		&sema.FunctionType{
+			ReturnTypeAnnotation: sema.NewTypeAnnotation(sema.VoidType),
+		},
+		nil,
+	)
+
+	// Declare the functions and composites first,
+	// so the values of global variables may refer to them
+
+	for _, declaration := range declarations {
+		switch declaration := declaration.(type) {
+		case *ast.FunctionDeclaration:
+			compiler.compileGlobalFunctionDeclaration(declaration)
+
+		case *ast.CompositeDeclaration:
+			compiler.compileCompositeDeclaration(declaration)
+		}
+	}
 
-func (compiler *Compiler) VisitReturnStatement(statement *ast.ReturnStatement) ast.Repr {
-	exp := statement.Expression.Accept(compiler).(ir.Expr)
-	return &ir.Return{
-		Exp: exp,
+	for _, declaration := range declarations {
+		if declaration, ok := declaration.(*ast.VariableDeclaration); ok {
+			compiler.compileGlobalVariableDeclaration(declaration)
+		}
 	}
+
+	compiler.emit(Return)
+
+	compiler.popFunction()
+
+	return &Program{
+		Location:    compiler.Location,
+		Elaboration: compiler.Elaboration,
+		Imports:     compiler.imports,
+		Globals:     compiler.globals,
+		Contract:    compiler.contract,
+		Functions:   compiler.functions,
+		Initializer: initializer,
+		Constants:   compiler.constants,
+		Types:       compiler.types,
+		Invocations: compiler.invocations,
+	}, nil
 }
 
-func (compiler *Compiler) VisitBreakStatement(_ *ast.BreakStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// declareGlobals declares the globals of the given declarations:
+// The functions, the constructors of composites, the cases of enums,
+// the contract, and the variables.
+//
+func (compiler *Compiler) declareGlobals(declarations []ast.Declaration) {
+	for _, declaration := range declarations {
+		switch declaration := declaration.(type) {
+		case *ast.FunctionDeclaration:
+			compiler.declareGlobal(declaration.Identifier.Identifier)
+
+		case *ast.VariableDeclaration:
+			compiler.declareGlobal(declaration.Identifier.Identifier)
+
+		case *ast.CompositeDeclaration:
+			compiler.declareCompositeGlobals(declaration)
+
+		case *ast.TransactionDeclaration:
+			panic(&UnsupportedError{
+				Feature: "transactions",
+				Range:   ast.NewUnmeteredRangeFromPositioned(declaration),
+			})
+		}
+	}
 }
 
-func (compiler *Compiler) VisitContinueStatement(_ *ast.ContinueStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) declareCompositeGlobals(declaration *ast.CompositeDeclaration) {
+	compositeType := compiler.Elaboration.CompositeDeclarationTypes[declaration]
+	qualifiedIdentifier := compositeType.QualifiedIdentifier()
+
+	compiler.declareGlobal(qualifiedIdentifier)
+
+	switch declaration.CompositeKind {
+	case common.CompositeKindContract:
+		compiler.contract = qualifiedIdentifier
+
+	case common.CompositeKindEnum:
+		for _, enumCase := range declaration.Members.EnumCases() {
+			compiler.declareGlobal(enumCaseGlobalName(compositeType, enumCase.Identifier.Identifier))
+		}
+	}
+
+	for _, nestedDeclaration := range declaration.Members.Composites() {
+		compiler.declareCompositeGlobals(nestedDeclaration)
+	}
 }
 
-func (compiler *Compiler) VisitIfStatement(_ *ast.IfStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) declareGlobal(name string) {
+	if len(compiler.globals) >= math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "more than 65535 globals",
+			Range:   compiler.currentRange,
+		})
+	}
+	compiler.globalIndices[name] = uint16(len(compiler.globals))
+	compiler.globals = append(compiler.globals, name)
 }
 
-func (compiler *Compiler) VisitWhileStatement(_ *ast.WhileStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// checkNotGeneric rejects the declaration of a generic function or composite type.
+//
+// The types in the compiled code are constants, so the types in the code of a generic declaration
+// would refer to its type parameters, instead of the type arguments of the invocation
+//
+func (compiler *Compiler) checkNotGeneric(typeParameterList *ast.TypeParameterList) {
+	if typeParameterList.IsEmpty() {
+		return
+	}
+
+	panic(&UnsupportedError{
+		Feature: "generic declarations",
+		Range:   compiler.currentRange,
+	})
 }
 
-func (compiler *Compiler) VisitForStatement(_ *ast.ForStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func enumCaseGlobalName(enumType *sema.CompositeType, caseName string) string {
+	return enumType.QualifiedIdentifier() + "." + caseName
 }
 
-func (compiler *Compiler) VisitEmitStatement(_ *ast.EmitStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func nestedGlobalName(containerType *sema.CompositeType, name string) string {
+	return containerType.QualifiedIdentifier() + "." + name
 }
 
-func (compiler *Compiler) VisitSwitchStatement(_ *ast.SwitchStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func methodName(compositeType *sema.CompositeType, name string) string {
+	return compositeType.QualifiedIdentifier() + "." + name
 }
 
-func (compiler *Compiler) VisitVariableDeclaration(declaration *ast.VariableDeclaration) ast.Repr {
+// pushFunction starts the compilation of a new function.
+// The parent is the function which encloses the new function, if any.
+//
+func (compiler *Compiler) pushFunction(
+	name string,
+	functionType *sema.FunctionType,
+	parent *function,
+) uint16 {
+	if len(compiler.functions) >= math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "more than 65535 functions",
+			Range:   compiler.currentRange,
+		})
+	}
+
+	index := uint16(len(compiler.functions))
 
-	// TODO: potential storage removal
-	// TODO: copy and convert
-	// TODO: second value
+	compiled := &Function{
+		Name: name,
+		Type: functionType,
+	}
+	compiler.functions = append(compiler.functions, compiled)
 
-	identifier := declaration.Identifier.Identifier
-	targetType := compiler.Checker.Elaboration.VariableDeclarationTargetTypes[declaration]
-	valType := compileValueType(targetType)
-	local := compiler.declareLocal(identifier, valType)
-	exp := declaration.Value.Accept(compiler).(ir.Expr)
+	activations := NewLocalActivations(nil)
+	activations.PushNewWithCurrent()
 
-	return &ir.StoreLocal{
-		LocalIndex: local.Index,
-		Exp:        exp,
+	compiler.function = &function{
+		Function:    compiled,
+		parent:      parent,
+		previous:    compiler.function,
+		activations: activations,
 	}
+
+	return index
 }
 
-func (compiler *Compiler) VisitAssignmentStatement(_ *ast.AssignmentStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// popFunction ends the compilation of the current function,
+// and continues the compilation of the function which was compiled before.
+//
+func (compiler *Compiler) popFunction() {
+	compiler.function = compiler.function.previous
 }
 
-func (compiler *Compiler) VisitSwapStatement(_ *ast.SwapStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// withRange sets the range of the source code the following instructions are compiled from,
+// and returns a function which restores the previous range.
+//
+func (compiler *Compiler) withRange(element ast.HasPosition) func() {
+	previous := compiler.currentRange
+	compiler.currentRange = ast.NewUnmeteredRangeFromPositioned(element)
+	return func() {
+		compiler.currentRange = previous
+	}
 }
 
-func (compiler *Compiler) VisitExpressionStatement(_ *ast.ExpressionStatement) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) emit(opcode Opcode, operands ...uint16) {
+	function := compiler.function
+	offset := len(function.Code)
+
+	positionCount := len(function.Positions)
+	if positionCount == 0 || function.Positions[positionCount-1].Range != compiler.currentRange {
+		function.Positions = append(
+			function.Positions,
+			Position{
+				Offset: uint16(offset),
+				Range:  compiler.currentRange,
+			},
+		)
+	}
+
+	function.Code = append(function.Code, byte(opcode))
+
+	for i, size := range opcode.Operands() {
+		operand := operands[i]
+		switch size {
+		case 1:
+			function.Code = append(function.Code, byte(operand))
+		case 2:
+			function.Code = append(function.Code, byte(operand>>8), byte(operand))
+		default:
+			panic(errors.NewUnreachableError())
+		}
+	}
+
+	if len(function.Code) > math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "functions larger than 65535 bytes",
+			Range:   compiler.currentRange,
+		})
+	}
 }
 
-func (compiler *Compiler) VisitBoolExpression(_ *ast.BoolExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// emitJump emits a jump with a target which is not known yet,
+// and returns the offset of the target operand, so it can be patched later.
+//
+func (compiler *Compiler) emitJump(opcode Opcode) int {
+	compiler.emit(opcode, 0)
+	return len(compiler.function.Code) - 2
 }
 
-func (compiler *Compiler) VisitNilExpression(_ *ast.NilExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// patchJump sets the target operand at the given offset to the current offset.
+//
+func (compiler *Compiler) patchJump(operandOffset int) {
+	code := compiler.function.Code
+	target := len(code)
+	code[operandOffset] = byte(target >> 8)
+	code[operandOffset+1] = byte(target)
 }
 
-func (compiler *Compiler) VisitIntegerExpression(expression *ast.IntegerExpression) ast.Repr {
-	var value []byte
+func (compiler *Compiler) currentOffset() uint16 {
+	return uint16(len(compiler.function.Code))
+}
 
-	if expression.Value.Sign() < 0 {
-		value = append(value, 0)
-	} else {
-		value = append(value, 1)
+// declareLocal declares a local with the given name in the current scope
+//
+func (compiler *Compiler) declareLocal(name string) *Local {
+	// NOTE: semantic analysis already checked possible invalid redeclaration
+	local := compiler.newLocal()
+	compiler.function.activations.Set(name, local)
+	return local
+}
+
+// newLocal allocates a local which has no name, e.g. for a temporary value
+//
+func (compiler *Compiler) newLocal() *Local {
+	function := compiler.function
+	if function.LocalCount == math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "more than 65535 locals",
+			Range:   compiler.currentRange,
+		})
 	}
+	local := NewLocal(function.LocalCount)
+	function.LocalCount++
+	return local
+}
 
-	value = append(value,
-		expression.Value.Bytes()...,
-	)
+func (compiler *Compiler) addConstant(constant Constant) uint16 {
+	if len(compiler.constants) >= math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "more than 65535 constants",
+			Range:   compiler.currentRange,
+		})
+	}
+	index := uint16(len(compiler.constants))
+	compiler.constants = append(compiler.constants, constant)
+	return index
+}
 
-	return &ir.Const{
-		Constant: ir.Int{
-			Value: value,
-		},
+// stringConstant returns the index of the string constant with the given value.
+// String constants are interned, as they are also used for names.
+//
+func (compiler *Compiler) stringConstant(value string) uint16 {
+	index, ok := compiler.stringConstants[value]
+	if !ok {
+		index = compiler.addConstant(NewStringConstant(value))
+		compiler.stringConstants[value] = index
 	}
+	return index
 }
 
-func (compiler *Compiler) VisitFixedPointExpression(_ *ast.FixedPointExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) typeIndex(ty sema.Type) uint16 {
+	typeID := ty.ID()
+	index, ok := compiler.typeIndices[typeID]
+	if !ok {
+		if len(compiler.types) >= math.MaxUint16 {
+			panic(&UnsupportedError{
+				Feature: "more than 65535 types",
+				Range:   compiler.currentRange,
+			})
+		}
+		index = uint16(len(compiler.types))
+		compiler.types = append(compiler.types, ty)
+		compiler.typeIndices[typeID] = index
+	}
+	return index
 }
 
-func (compiler *Compiler) VisitArrayExpression(_ *ast.ArrayExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) addInvocation(invocation Invocation) uint16 {
+	if len(compiler.invocations) >= math.MaxUint16 {
+		panic(&UnsupportedError{
+			Feature: "more than 65535 invocations",
+			Range:   compiler.currentRange,
+		})
+	}
+	index := uint16(len(compiler.invocations))
+	compiler.invocations = append(compiler.invocations, invocation)
+	return index
 }
 
-func (compiler *Compiler) VisitDictionaryExpression(_ *ast.DictionaryExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// emitTransferAndConvert emits the transfer of the value on the stack,
+// and its conversion from the value type to the target type.
+//
+func (compiler *Compiler) emitTransferAndConvert(valueType, targetType sema.Type) {
+	compiler.emit(
+		TransferAndConvert,
+		compiler.typeIndex(valueType),
+		compiler.typeIndex(targetType),
+	)
 }
 
-func (compiler *Compiler) VisitIdentifierExpression(expression *ast.IdentifierExpression) ast.Repr {
-	// TODO
-	local := compiler.findLocal(expression.Identifier.Identifier)
-	// TODO: moves
-	return &ir.CopyLocal{
-		LocalIndex: local.Index,
+// Variables
+
+// findLocal returns the local with the given name, if any,
+// and the number of functions between the current function and the function which declares the local.
+//
+func (compiler *Compiler) findLocal(name string) (local *Local, depth uint16) {
+	for function := compiler.function; function != nil; function = function.parent {
+		local = function.activations.Find(name)
+		if local != nil {
+			return local, depth
+		}
+		depth++
 	}
+	return nil, 0
 }
 
-func (compiler *Compiler) VisitInvocationExpression(_ *ast.InvocationExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
-}
+// findGlobal returns the index of the global with the given name, if any.
+// Names are resolved in the scopes of the enclosing composite declarations first,
+// e.g. the name `S` in the contract `C` may refer to the nested composite `C.S`.
+//
+func (compiler *Compiler) findGlobal(name string) (uint16, bool) {
+	for i := len(compiler.compositeTypes) - 1; i >= 0; i-- {
+		qualifiedName := nestedGlobalName(compiler.compositeTypes[i], name)
+		if index, ok := compiler.globalIndices[qualifiedName]; ok {
+			return index, true
+		}
+	}
 
-func (compiler *Compiler) VisitMemberExpression(_ *ast.MemberExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+	index, ok := compiler.globalIndices[name]
+	return index, ok
 }
 
-func (compiler *Compiler) VisitIndexExpression(_ *ast.IndexExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// findImport returns the index of the global for the imported value with the given name, if any.
+//
+func (compiler *Compiler) findImport(name string, includeImportAll bool) (uint16, bool) {
+	var candidate common.Location
+
+	for _, declaration := range compiler.Program.ImportDeclarations() {
+		resolvedLocations := compiler.Elaboration.ImportDeclarationsResolvedLocations[declaration]
+		for _, resolvedLocation := range resolvedLocations {
+
+			if len(declaration.Identifiers) == 0 {
+				// The import declaration imports all values of the location
+				if candidate == nil {
+					candidate = resolvedLocation.Location
+				}
+				continue
+			}
+
+			for _, identifier := range resolvedLocation.Identifiers {
+				if identifier.Identifier == name {
+					return compiler.importIndex(resolvedLocation.Location, name), true
+				}
+			}
+		}
+	}
+
+	if candidate == nil || !includeImportAll {
+		return 0, false
+	}
+
+	return compiler.importIndex(candidate, name), true
 }
 
-func (compiler *Compiler) VisitConditionalExpression(_ *ast.ConditionalExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// importIndex returns the index of the global for the value with the given name,
+// which is declared in the program with the given location.
+//
+func (compiler *Compiler) importIndex(location common.Location, name string) uint16 {
+	if location == compiler.Location {
+		index, ok := compiler.globalIndices[name]
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+		return index
+	}
+
+	key := Import{
+		Location: location,
+		Name:     name,
+	}
+
+	index, ok := compiler.importIndices[key]
+	if !ok {
+		if len(compiler.globals)+len(compiler.imports) >= math.MaxUint16 {
+			panic(&UnsupportedError{
+				Feature: "more than 65535 globals",
+				Range:   compiler.currentRange,
+			})
+		}
+		// NOTE: imports are declared after the globals have been declared,
+		// so the global count is final
+		index = uint16(len(compiler.globals) + len(compiler.imports))
+		compiler.imports = append(compiler.imports, key)
+		compiler.importIndices[key] = index
+	}
+
+	return index
 }
 
-func (compiler *Compiler) VisitUnaryExpression(_ *ast.UnaryExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// isBuiltin returns true if the name refers to a value
+// which is provided by the environment, e.g. a conversion function or a standard library function.
+//
+func (compiler *Compiler) isBuiltin(name string) bool {
+	if sema.BaseValueActivation.Find(name) != nil {
+		return true
+	}
+	_, ok := compiler.Elaboration.EffectivePredeclaredValues[name]
+	return ok
 }
 
-func (compiler *Compiler) VisitBinaryExpression(expression *ast.BinaryExpression) ast.Repr {
-	op := compileBinaryOperation(expression.Operation)
-	left := expression.Left.Accept(compiler).(ir.Expr)
-	right := expression.Right.Accept(compiler).(ir.Expr)
+func (compiler *Compiler) emitGetVariable(name string) {
+	if local, depth := compiler.findLocal(name); local != nil {
+		if depth == 0 {
+			compiler.emit(GetLocal, local.Index)
+		} else {
+			compiler.emit(GetUpvalue, depth, local.Index)
+		}
+		return
+	}
+
+	if index, ok := compiler.findGlobal(name); ok {
+		compiler.emit(GetGlobal, index)
+		return
+	}
 
-	return &ir.BinOpExpr{
-		Op:    op,
-		Left:  left,
-		Right: right,
+	const includeImportAll = false
+	if index, ok := compiler.findImport(name, includeImportAll); ok {
+		compiler.emit(GetGlobal, index)
+		return
+	}
+
+	if !compiler.isBuiltin(name) {
+		const includeImportAll = true
+		if index, ok := compiler.findImport(name, includeImportAll); ok {
+			compiler.emit(GetGlobal, index)
+			return
+		}
 	}
+
+	compiler.emit(GetBuiltin, compiler.stringConstant(name))
 }
 
-func (compiler *Compiler) VisitFunctionExpression(_ *ast.FunctionExpression) ast.Repr {
-	// TODO
+func (compiler *Compiler) emitSetVariable(name string) {
+	if local, depth := compiler.findLocal(name); local != nil {
+		if depth == 0 {
+			compiler.emit(SetLocal, local.Index)
+		} else {
+			compiler.emit(SetUpvalue, depth, local.Index)
+		}
+		return
+	}
+
+	if index, ok := compiler.findGlobal(name); ok {
+		compiler.emit(SetGlobal, index)
+		return
+	}
+
+	// Imported and predeclared values are constant
 	panic(errors.NewUnreachableError())
 }
 
-func (compiler *Compiler) VisitStringExpression(e *ast.StringExpression) ast.Repr {
-	return &ir.Const{
-		Constant: ir.String{
-			Value: e.Value,
-		},
+func (compiler *Compiler) emitSetGlobal(name string) {
+	index, ok := compiler.globalIndices[name]
+	if !ok {
+		panic(errors.NewUnreachableError())
 	}
+	compiler.emit(SetGlobal, index)
 }
 
-func (compiler *Compiler) VisitCastingExpression(_ *ast.CastingExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// Functions
+
+// compileFunction compiles a function and returns its index.
+//
+// Methods receive `self` as the first local, followed by the parameters.
+// Constructors create the new composite value, bind it to `self`, and return it.
+//
+// The conditions of the given conformance functions are inlined into the function,
+// see compileFunctionBlock.
+//
+func (compiler *Compiler) compileFunction(
+	name string,
+	kind functionKind,
+	functionType *sema.FunctionType,
+	parameterList *ast.ParameterList,
+	functionBlock *ast.FunctionBlock,
+	conformanceFunctions []*ast.FunctionDeclaration,
+	parent *function,
+) uint16 {
+	index := compiler.pushFunction(name, functionType, parent)
+	defer compiler.popFunction()
+
+	function := compiler.function
+
+	if kind == functionKindMethod {
+		function.IsMethod = true
+		compiler.declareLocal(sema.SelfIdentifier)
+	}
+
+	var parameters []*Local
+
+	if parameterList != nil {
+		for _, parameter := range parameterList.Parameters {
+			parameters = append(
+				parameters,
+				compiler.declareLocal(parameter.Identifier.Identifier),
+			)
+		}
+		function.ParameterCount = uint16(len(parameterList.Parameters))
+	}
+
+	var self *Local
+
+	if kind == functionKindConstructor {
+		compositeType, ok := functionType.ReturnTypeAnnotation.Type.(*sema.CompositeType)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		self = compiler.declareLocal(sema.SelfIdentifier)
+
+		compiler.emit(NewComposite, compiler.typeIndex(compositeType))
+		compiler.emit(SetLocal, self.Index)
+
+		// The contract is available as a global while it is initialized
+		if compositeType.Kind == common.CompositeKindContract {
+			compiler.emit(GetLocal, self.Index)
+			compiler.emitSetGlobal(compositeType.QualifiedIdentifier())
+		}
+	}
+
+	compiler.compileFunctionBlock(
+		functionBlock,
+		conformanceFunctions,
+		parameters,
+		functionType.ReturnTypeAnnotation.Type,
+		self,
+	)
+
+	return index
 }
 
-func (compiler *Compiler) VisitCreateExpression(_ *ast.CreateExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileFunctionBlock compiles the before-statements, the pre-conditions,
+// the statements, and the post-conditions of a function block.
+//
+// If the function has post-conditions, or is a constructor,
+// return statements jump to the epilogue at the end of the function,
+// which evaluates the post-conditions and then returns.
+//
+// The conditions of the given conformance functions are inlined, like the interpreter evaluates them:
+// The before-statements and pre-conditions of the conformances are evaluated first, in order,
+// and their post-conditions last, in reverse order.
+// The parameters of a conformance function refer to the parameters of the function, by position.
+//
+func (compiler *Compiler) compileFunctionBlock(
+	functionBlock *ast.FunctionBlock,
+	conformanceFunctions []*ast.FunctionDeclaration,
+	parameters []*Local,
+	returnType sema.Type,
+	self *Local,
+) {
+	function := compiler.function
+
+	conditionBlocks := make([]conditionBlock, 0, len(conformanceFunctions)+1)
+
+	for _, conformanceFunction := range conformanceFunctions {
+		conditionBlocks = append(
+			conditionBlocks,
+			compiler.newConditionBlock(
+				conformanceFunction.FunctionBlock,
+				conformanceFunction.ParameterList,
+			),
+		)
+	}
+
+	conditionBlocks = append(
+		conditionBlocks,
+		compiler.newConditionBlock(functionBlock, nil),
+	)
+
+	hasPostConditions := false
+	for _, conditionBlock := range conditionBlocks {
+		if len(conditionBlock.postConditions) > 0 {
+			hasPostConditions = true
+			break
+		}
+	}
+
+	function.hasEpilogue = self != nil || hasPostConditions
+	if function.hasEpilogue && self == nil && returnType != sema.VoidType {
+		function.result = compiler.newLocal()
+	}
+
+	// Each function block gets a scope.
+	// The scopes of the conformances are not nested in each other,
+	// so the parameters of a conformance are only visible in its own conditions
+
+	functionScope := function.activations.Current()
+
+	for _, conditionBlock := range conditionBlocks {
+		function.activations.PushNewWithParent(functionScope)
+
+		if conditionBlock.parameterList != nil {
+			for i, parameter := range conditionBlock.parameterList.Parameters {
+				function.activations.Set(parameter.Identifier.Identifier, parameters[i])
+			}
+		}
+
+		compiler.compileStatements(conditionBlock.beforeStatements)
+
+		compiler.compileConditions(conditionBlock.preConditions)
+	}
+
+	if functionBlock != nil && functionBlock.Block != nil {
+		compiler.compileStatements(functionBlock.Block.Statements)
+	}
+
+	if function.hasEpilogue {
+		for _, operandOffset := range function.returns {
+			compiler.patchJump(operandOffset)
+		}
+	} else {
+		compiler.emit(Return)
+	}
+
+	// If there is a return type, declare the constant `result` for the post-conditions.
+	// If it is a resource type, the constant has the same type as a reference to the return type.
+	// If it is not a resource type, the constant has the same type as the return type.
+
+	var result *Local
+
+	if hasPostConditions && function.result != nil {
+		if returnType.IsResourceType() {
+			result = compiler.newLocal()
+			compiler.emit(GetLocal, function.result.Index)
+			compiler.emit(NewReference, compiler.typeIndex(&sema.ReferenceType{Type: returnType}))
+			compiler.emit(SetLocal, result.Index)
+		} else {
+			result = function.result
+		}
+	}
+
+	for i := len(conditionBlocks) - 1; i >= 0; i-- {
+		postConditions := conditionBlocks[i].postConditions
+
+		if len(postConditions) > 0 {
+			if result != nil {
+				function.activations.Set(sema.ResultIdentifier, result)
+			}
+
+			compiler.compileConditions(postConditions)
+		}
+
+		function.activations.Pop()
+	}
+
+	if !function.hasEpilogue {
+		return
+	}
+
+	switch {
+	case self != nil:
+		compiler.emit(GetLocal, self.Index)
+		compiler.emit(ReturnValue)
+
+	case function.result != nil:
+		compiler.emit(GetLocal, function.result.Index)
+		compiler.emit(ReturnValue)
+
+	default:
+		compiler.emit(Return)
+	}
 }
 
-func (compiler *Compiler) VisitDestroyExpression(_ *ast.DestroyExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// conditionBlock is the before-statements, the pre-conditions, and the post-conditions
+// of a function block which are compiled into a function.
+//
+type conditionBlock struct {
+	// parameterList is the parameter list of the function of a conformance, if any
+	parameterList    *ast.ParameterList
+	beforeStatements []ast.Statement
+	preConditions    ast.Conditions
+	postConditions   ast.Conditions
 }
 
-func (compiler *Compiler) VisitReferenceExpression(_ *ast.ReferenceExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) newConditionBlock(
+	functionBlock *ast.FunctionBlock,
+	parameterList *ast.ParameterList,
+) conditionBlock {
+	block := conditionBlock{
+		parameterList: parameterList,
+	}
+
+	if functionBlock == nil {
+		return block
+	}
+
+	if functionBlock.PreConditions != nil {
+		block.preConditions = *functionBlock.PreConditions
+	}
+
+	if functionBlock.PostConditions != nil {
+		postConditionsRewrite :=
+			compiler.Elaboration.PostConditionsRewrite[functionBlock.PostConditions]
+
+		block.beforeStatements = postConditionsRewrite.BeforeStatements
+		block.postConditions = postConditionsRewrite.RewrittenPostConditions
+	}
+
+	return block
 }
 
-func (compiler *Compiler) VisitForceExpression(_ *ast.ForceExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileConditions compiles the conditions, which abort with a condition error if they fail.
+//
+func (compiler *Compiler) compileConditions(conditions ast.Conditions) {
+	for _, condition := range conditions {
+		compiler.compileCondition(condition)
+	}
 }
 
-func (compiler *Compiler) VisitPathExpression(_ *ast.PathExpression) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) compileCondition(condition *ast.Condition) {
+	defer compiler.withRange(condition.Test)()
+
+	// Conditions are evaluated like statements
+	compiler.emit(Statement)
+
+	compiler.compileExpression(condition.Test)
+	succeeded := compiler.emitJump(JumpIfTrue)
+
+	if condition.Message != nil {
+		compiler.compileExpression(condition.Message)
+	} else {
+		compiler.emit(Nil)
+	}
+	compiler.emit(Fail, uint16(condition.Kind))
+
+	compiler.patchJump(succeeded)
 }
 
-func (compiler *Compiler) VisitProgram(_ *ast.Program) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) compileGlobalFunctionDeclaration(declaration *ast.FunctionDeclaration) {
+	defer compiler.withRange(declaration)()
+
+	compiler.checkNotGeneric(declaration.TypeParameterList)
+
+	name := declaration.Identifier.Identifier
+	functionType := compiler.Elaboration.FunctionDeclarationFunctionTypes[declaration]
+
+	index := compiler.compileFunction(
+		name,
+		functionKindFunction,
+		functionType,
+		declaration.ParameterList,
+		declaration.FunctionBlock,
+		nil,
+		nil,
+	)
+
+	compiler.emit(NewClosure, index)
+	compiler.emitSetGlobal(name)
 }
 
-func (compiler *Compiler) VisitSpecialFunctionDeclaration(declaration *ast.SpecialFunctionDeclaration) ast.Repr {
-	return compiler.VisitFunctionDeclaration(declaration.FunctionDeclaration)
+func (compiler *Compiler) compileGlobalVariableDeclaration(declaration *ast.VariableDeclaration) {
+	defer compiler.withRange(declaration)()
+
+	compiler.compileVariableDeclaration(declaration, func() {
+		compiler.emitSetGlobal(declaration.Identifier.Identifier)
+	})
 }
 
-func (compiler *Compiler) VisitFunctionDeclaration(declaration *ast.FunctionDeclaration) ast.Repr {
+// Composites
 
-	// TODO: declare function in current scope, use current scope in function
-	// TODO: conditions
+// compileCompositeDeclaration compiles the constructor, the methods, and the destructor
+// of the composite declaration, and the nested composite declarations.
+// The code which declares the constructor, and the enum cases, if any,
+// is emitted into the current function, i.e. the initializer of the program.
+//
+func (compiler *Compiler) compileCompositeDeclaration(declaration *ast.CompositeDeclaration) {
+	defer compiler.withRange(declaration)()
 
-	compiler.locals = nil
+	compiler.checkNotGeneric(declaration.TypeParameterList)
 
-	block := declaration.FunctionBlock.Block
+	compositeType := compiler.Elaboration.CompositeDeclarationTypes[declaration]
+	qualifiedIdentifier := compositeType.QualifiedIdentifier()
 
-	// Declare a local for each parameter
+	compiler.compositeTypes = append(compiler.compositeTypes, compositeType)
+	defer func() {
+		compiler.compositeTypes = compiler.compositeTypes[:len(compiler.compositeTypes)-1]
+	}()
 
-	functionType := compiler.Checker.Elaboration.FunctionDeclarationFunctionTypes[declaration]
+	var constructor uint16
 
-	parameters := declaration.ParameterList.Parameters
+	switch declaration.CompositeKind {
+	case common.CompositeKindEnum:
+		constructor = compiler.compileEnumConstructor(declaration, compositeType)
 
-	for i, parameter := range parameters {
-		parameterType := functionType.Parameters[i].TypeAnnotation.Type
-		valType := compileValueType(parameterType)
-		name := parameter.Identifier.Identifier
-		compiler.declareLocal(name, valType)
+	case common.CompositeKindEvent:
+		constructor = compiler.compileEventConstructor(compositeType)
+
+	default:
+		constructor = compiler.compileConstructor(declaration, compositeType)
 	}
 
-	// Compile the function block
+	// The global of a contract is the contract value, not its constructor.
+	// The constructor is invoked when the contract is initialized
 
-	stmt := block.Accept(compiler).(ir.Stmt)
+	if declaration.CompositeKind != common.CompositeKindContract {
+		compiler.emit(NewClosure, constructor)
+		compiler.emitSetGlobal(qualifiedIdentifier)
+	}
 
-	// Important: compile locals after compiling function block,
-	// and don't include parameters in locals
-	locals := compileLocals(compiler.locals[len(parameters):])
+	if declaration.CompositeKind == common.CompositeKindEnum {
+		compiler.compileEnumCases(declaration, compositeType)
+	}
 
-	compiledFunctionType := compileFunctionType(functionType)
+	for _, functionDeclaration := range declaration.Members.Functions() {
+		name := functionDeclaration.Identifier.Identifier
+
+		compiler.compileMethod(
+			compositeType,
+			name,
+			functionDeclaration,
+			compiler.conformanceFunctions(
+				compositeType,
+				func(members *ast.Members) *ast.FunctionDeclaration {
+					return members.FunctionsByIdentifier()[name]
+				},
+			),
+		)
+	}
 
-	return &ir.Func{
-		// TODO: fully qualify
-		Name:      declaration.Identifier.Identifier,
-		Type:      compiledFunctionType,
-		Locals:    locals,
-		Statement: stmt,
+	// The conformances may declare conditions for the destructor,
+	// even if the composite does not declare a destructor
+
+	var destructorDeclaration *ast.FunctionDeclaration
+	destructor := declaration.Members.Destructor()
+	if destructor != nil {
+		destructorDeclaration = destructor.FunctionDeclaration
+	}
+
+	destructorConformanceFunctions := compiler.conformanceFunctions(
+		compositeType,
+		func(members *ast.Members) *ast.FunctionDeclaration {
+			destructor := members.Destructor()
+			if destructor == nil {
+				return nil
+			}
+			return destructor.FunctionDeclaration
+		},
+	)
+
+	if destructorDeclaration != nil || len(destructorConformanceFunctions) > 0 {
+		compiler.compileMethod(
+			compositeType,
+			DestructorFunctionName,
+			destructorDeclaration,
+			destructorConformanceFunctions,
+		)
+	}
+
+	for _, nestedDeclaration := range declaration.Members.Composites() {
+		compiler.compileCompositeDeclaration(nestedDeclaration)
 	}
 }
 
-func (compiler *Compiler) VisitBlock(block *ast.Block) ast.Repr {
+// conformanceFunctions returns the functions of the type requirements and the interface conformances
+// of the given composite type which have a function block, i.e. which may declare conditions.
+// The function of a conformance is selected from its members by the given function.
+//
+// The functions are returned in the order in which their conditions are evaluated:
+// First the type requirements, then the interface conformances, each in declaration order.
+//
+// Only the conformances declared in the compiled program are supported,
+// as the declarations of other programs are not available.
+//
+func (compiler *Compiler) conformanceFunctions(
+	compositeType *sema.CompositeType,
+	selectFunction func(members *ast.Members) *ast.FunctionDeclaration,
+) []*ast.FunctionDeclaration {
+
+	var conformanceTypes []sema.Type
+
+	for _, typeRequirement := range compositeType.TypeRequirements() {
+		conformanceTypes = append(conformanceTypes, typeRequirement)
+	}
+
+	for _, conformance := range compositeType.ExplicitInterfaceConformances {
+		conformanceTypes = append(conformanceTypes, conformance)
+	}
+
+	if len(conformanceTypes) == 0 {
+		return nil
+	}
+
+	if compiler.conformanceMembers == nil {
+		compiler.conformanceMembers = map[sema.Type]*ast.Members{}
+
+		for declaration, interfaceType := range compiler.Elaboration.InterfaceDeclarationTypes { //nolint:maprangecheck
+			compiler.conformanceMembers[interfaceType] = declaration.Members
+		}
+
+		for declaration, compositeType := range compiler.Elaboration.CompositeDeclarationTypes { //nolint:maprangecheck
+			compiler.conformanceMembers[compositeType] = declaration.Members
+		}
+	}
+
+	var functions []*ast.FunctionDeclaration
+
+	for _, conformanceType := range conformanceTypes {
+		members, ok := compiler.conformanceMembers[conformanceType]
+		if !ok {
+			panic(&UnsupportedError{
+				Feature: "conformances declared in other programs",
+				Range:   compiler.currentRange,
+			})
+		}
+
+		function := selectFunction(members)
+		if function == nil || function.FunctionBlock == nil {
+			continue
+		}
 
-	// Block scope: each block gets an activation record
+		functions = append(functions, function)
+	}
 
-	compiler.activations.PushNewWithCurrent()
-	defer compiler.activations.Pop()
+	return functions
+}
 
-	// Compile each statement in the block
+func (compiler *Compiler) compileConstructor(
+	declaration *ast.CompositeDeclaration,
+	compositeType *sema.CompositeType,
+) uint16 {
 
-	stmts := make([]ir.Stmt, len(block.Statements))
-	for i, statement := range block.Statements {
-		stmts[i] = statement.Accept(compiler).(ir.Stmt)
+	var parameterList *ast.ParameterList
+	var functionBlock *ast.FunctionBlock
+
+	initializers := declaration.Members.Initializers()
+	if len(initializers) > 0 {
+		initializer := initializers[0].FunctionDeclaration
+		parameterList = initializer.ParameterList
+		functionBlock = initializer.FunctionBlock
 	}
 
-	// NOTE: just return an IR statement sequence,
-	// there is no need for an IR block
-	return &ir.Sequence{
-		Stmts: stmts,
+	conformanceFunctions := compiler.conformanceFunctions(
+		compositeType,
+		func(members *ast.Members) *ast.FunctionDeclaration {
+			initializers := members.Initializers()
+			if len(initializers) == 0 {
+				return nil
+			}
+			return initializers[0].FunctionDeclaration
+		},
+	)
+
+	return compiler.compileFunction(
+		compositeType.QualifiedIdentifier(),
+		functionKindConstructor,
+		constructorFunctionType(compositeType),
+		parameterList,
+		functionBlock,
+		conformanceFunctions,
+		nil,
+	)
+}
+
+func constructorFunctionType(compositeType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		IsConstructor:        true,
+		Parameters:           compositeType.ConstructorParameters,
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(compositeType),
 	}
 }
 
-func (compiler *Compiler) VisitFunctionBlock(_ *ast.FunctionBlock) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileEventConstructor compiles the constructor of an event,
+// which sets the fields of the event to the arguments.
+//
+func (compiler *Compiler) compileEventConstructor(compositeType *sema.CompositeType) uint16 {
+
+	index := compiler.pushFunction(
+		compositeType.QualifiedIdentifier(),
+		constructorFunctionType(compositeType),
+		nil,
+	)
+	defer compiler.popFunction()
+
+	parameters := compositeType.ConstructorParameters
+
+	parameterLocals := make([]*Local, len(parameters))
+	for i, parameter := range parameters {
+		parameterLocals[i] = compiler.declareLocal(parameter.Identifier)
+	}
+	compiler.function.ParameterCount = uint16(len(parameters))
+
+	compiler.emit(NewComposite, compiler.typeIndex(compositeType))
+
+	for i, parameter := range parameters {
+		compiler.emit(Dup)
+		compiler.emit(GetLocal, parameterLocals[i].Index)
+		compiler.emit(SetField, compiler.stringConstant(parameter.Identifier))
+	}
+
+	compiler.emit(ReturnValue)
+
+	return index
 }
 
-func (compiler *Compiler) VisitCompositeDeclaration(_ *ast.CompositeDeclaration) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileEnumConstructor compiles the constructor of an enum,
+// which returns the case with the given raw value, or nil.
+//
+func (compiler *Compiler) compileEnumConstructor(
+	declaration *ast.CompositeDeclaration,
+	compositeType *sema.CompositeType,
+) uint16 {
+
+	index := compiler.pushFunction(
+		compositeType.QualifiedIdentifier(),
+		sema.EnumConstructorType(compositeType),
+		nil,
+	)
+	defer compiler.popFunction()
+
+	rawValue := compiler.declareLocal(sema.EnumRawValueFieldName)
+	compiler.function.ParameterCount = 1
+
+	for i, enumCase := range declaration.Members.EnumCases() {
+		compiler.emit(GetLocal, rawValue.Index)
+		compiler.emitEnumRawValue(compositeType, i)
+		compiler.emit(Equal)
+		next := compiler.emitJump(JumpIfFalse)
+
+		compiler.emit(GetGlobal, compiler.globalIndices[enumCaseGlobalName(compositeType, enumCase.Identifier.Identifier)])
+		compiler.emit(Some)
+		compiler.emit(ReturnValue)
+
+		compiler.patchJump(next)
+	}
+
+	compiler.emit(Nil)
+	compiler.emit(ReturnValue)
+
+	return index
 }
 
-func (compiler *Compiler) VisitInterfaceDeclaration(_ *ast.InterfaceDeclaration) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+func (compiler *Compiler) emitEnumRawValue(compositeType *sema.CompositeType, index int) {
+	compiler.emit(
+		GetConstant,
+		compiler.addConstant(NewIntegerConstant(big.NewInt(int64(index)), compositeType.EnumRawType)),
+	)
 }
 
-func (compiler *Compiler) VisitFieldDeclaration(_ *ast.FieldDeclaration) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileEnumCases emits the code which declares the cases of the enum.
+//
+func (compiler *Compiler) compileEnumCases(
+	declaration *ast.CompositeDeclaration,
+	compositeType *sema.CompositeType,
+) {
+	for i, enumCase := range declaration.Members.EnumCases() {
+		compiler.emit(NewComposite, compiler.typeIndex(compositeType))
+		compiler.emit(Dup)
+		compiler.emitEnumRawValue(compositeType, i)
+		compiler.emit(SetField, compiler.stringConstant(sema.EnumRawValueFieldName))
+		compiler.emitSetGlobal(enumCaseGlobalName(compositeType, enumCase.Identifier.Identifier))
+	}
 }
 
-func (compiler *Compiler) VisitCondition(_ *ast.Condition) ast.Repr {
-	// TODO
-	panic(errors.NewUnreachableError())
+// compileMethod compiles the method with the given name of the given composite type.
+// The declaration is nil if only the conformances declare the method,
+// i.e. the method only evaluates the conditions of the conformances.
+//
+func (compiler *Compiler) compileMethod(
+	compositeType *sema.CompositeType,
+	name string,
+	declaration *ast.FunctionDeclaration,
+	conformanceFunctions []*ast.FunctionDeclaration,
+) {
+	var functionType *sema.FunctionType
+	var parameterList *ast.ParameterList
+	var functionBlock *ast.FunctionBlock
+
+	if declaration != nil {
+		defer compiler.withRange(declaration)()
+
+		compiler.checkNotGeneric(declaration.TypeParameterList)
+
+		functionType = compiler.Elaboration.FunctionDeclarationFunctionTypes[declaration]
+		parameterList = declaration.ParameterList
+		functionBlock = declaration.FunctionBlock
+	}
+
+	if functionType == nil {
+		// Special functions, like destructors, have no declared function type
+		functionType = &sema.FunctionType{
+			ReturnTypeAnnotation: sema.NewTypeAnnotation(sema.VoidType),
+		}
+	}
+
+	compiler.compileFunction(
+		methodName(compositeType, name),
+		functionKindMethod,
+		functionType,
+		parameterList,
+		functionBlock,
+		conformanceFunctions,
+		nil,
+	)
 }
 
-func (compiler *Compiler) VisitPragmaDeclaration(_ *ast.PragmaDeclaration) ast.Repr {
-	// TODO
+// Declarations, which are compiled explicitly
+
+func (compiler *Compiler) VisitProgram(_ *ast.Program) ast.Repr {
 	panic(errors.NewUnreachableError())
 }
 
-func (compiler *Compiler) VisitImportDeclaration(_ *ast.ImportDeclaration) ast.Repr {
-	// TODO
+func (compiler *Compiler) VisitSpecialFunctionDeclaration(_ *ast.SpecialFunctionDeclaration) ast.Repr {
 	panic(errors.NewUnreachableError())
 }
 
-func (compiler *Compiler) VisitTransactionDeclaration(_ *ast.TransactionDeclaration) ast.Repr {
-	// TODO
+func (compiler *Compiler) VisitCompositeDeclaration(_ *ast.CompositeDeclaration) ast.Repr {
+	// Composite declarations are only allowed at the top-level or nested in composites,
+	// and are compiled explicitly
 	panic(errors.NewUnreachableError())
 }
 
-func (compiler *Compiler) VisitEnumCaseDeclaration(_ *ast.EnumCaseDeclaration) ast.Repr {
-	// TODO
+func (compiler *Compiler) VisitInterfaceDeclaration(_ *ast.InterfaceDeclaration) ast.Repr {
 	panic(errors.NewUnreachableError())
 }
 
-func compileBinaryOperation(operation ast.Operation) ir.BinOp {
-	// TODO: add remaining operations
-	switch operation {
-	case ast.OperationPlus:
-		return ir.BinOpPlus
-	}
+func (compiler *Compiler) VisitTransactionDeclaration(declaration *ast.TransactionDeclaration) ast.Repr {
+	panic(&UnsupportedError{
+		Feature: "transactions",
+		Range:   ast.NewUnmeteredRangeFromPositioned(declaration),
+	})
+}
 
+func (compiler *Compiler) VisitFieldDeclaration(_ *ast.FieldDeclaration) ast.Repr {
 	panic(errors.NewUnreachableError())
 }
 
-func compileValueType(ty sema.Type) ir.ValType {
-	// TODO: add remaining types
-
-	switch ty {
-	case sema.StringType:
-		return ir.ValTypeString
-	case sema.IntType:
-		return ir.ValTypeInt
-	}
-
+func (compiler *Compiler) VisitEnumCaseDeclaration(_ *ast.EnumCaseDeclaration) ast.Repr {
 	panic(errors.NewUnreachableError())
 }
 
-func compileFunctionType(functionType *sema.FunctionType) ir.FuncType {
-	// compile parameter types
-	paramTypes := make([]ir.ValType, len(functionType.Parameters))
-	for i, parameter := range functionType.Parameters {
-		paramTypes[i] = compileValueType(parameter.TypeAnnotation.Type)
-	}
+func (compiler *Compiler) VisitPragmaDeclaration(_ *ast.PragmaDeclaration) ast.Repr {
+	return nil
+}
 
-	// compile return / result type
-	var resultTypes []ir.ValType
-	if functionType.ReturnTypeAnnotation.Type != sema.VoidType {
-		resultTypes = []ir.ValType{
-			compileValueType(functionType.ReturnTypeAnnotation.Type),
-		}
-	}
-	return ir.FuncType{
-		Params:  paramTypes,
-		Results: resultTypes,
-	}
+func (compiler *Compiler) VisitImportDeclaration(_ *ast.ImportDeclaration) ast.Repr {
+	return nil
 }
 
-func compileLocals(locals []*Local) []ir.Local {
-	result := make([]ir.Local, len(locals))
-	for i, local := range locals {
-		result[i] = ir.Local{
-			Type: local.Type,
-		}
-	}
-	return result
+func (compiler *Compiler) VisitTypeAliasDeclaration(_ *ast.TypeAliasDeclaration) ast.Repr {
+	// Type aliases are fully resolved by the checker
+	return nil
 }
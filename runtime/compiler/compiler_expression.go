@@ -0,0 +1,556 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+import (
+	"math/big"
+
+	"github.com/onflow/cadence/fixedpoint"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+func (compiler *Compiler) compileExpression(expression ast.Expression) {
+	defer compiler.withRange(expression)()
+
+	expression.Accept(compiler)
+}
+
+func (compiler *Compiler) emitIntegerConstant(value *big.Int, ty sema.Type) {
+	compiler.emit(GetConstant, compiler.addConstant(NewIntegerConstant(value, ty)))
+}
+
+func (compiler *Compiler) VisitBoolExpression(expression *ast.BoolExpression) ast.Repr {
+	if expression.Value {
+		compiler.emit(True)
+	} else {
+		compiler.emit(False)
+	}
+	return nil
+}
+
+func (compiler *Compiler) VisitNilExpression(_ *ast.NilExpression) ast.Repr {
+	compiler.emit(Nil)
+	return nil
+}
+
+func (compiler *Compiler) VisitIntegerExpression(expression *ast.IntegerExpression) ast.Repr {
+	integerType := compiler.Elaboration.IntegerExpressionType[expression]
+
+	if _, ok := integerType.(*sema.AddressType); ok {
+		address, err := common.BytesToAddress(expression.Value.Bytes())
+		if err != nil {
+			// The range of addresses is checked by the checker
+			panic(errors.NewUnreachableError())
+		}
+		compiler.emit(GetConstant, compiler.addConstant(NewAddressConstant(address)))
+		return nil
+	}
+
+	// The ranges are checked at the checker level.
+	// Hence, it is safe to create the constant without validation.
+	compiler.emitIntegerConstant(expression.Value, integerType)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitFixedPointExpression(expression *ast.FixedPointExpression) ast.Repr {
+	// TODO: adjust once/if we support more fixed point types
+
+	fixedPointSubType := compiler.Elaboration.FixedPointExpression[expression]
+
+	value := fixedpoint.ConvertToFixedPointBigInt(
+		expression.Negative,
+		expression.UnsignedInteger,
+		expression.Fractional,
+		expression.Scale,
+		sema.Fix64Scale,
+	)
+
+	var constant Constant
+
+	switch fixedPointSubType {
+	case sema.Fix64Type, sema.SignedFixedPointType:
+		constant = NewFixedPointConstant(ConstantKindFix64, uint64(value.Int64()))
+	case sema.UFix64Type:
+		constant = NewFixedPointConstant(ConstantKindUFix64, value.Uint64())
+	case sema.FixedPointType:
+		if expression.Negative {
+			constant = NewFixedPointConstant(ConstantKindFix64, uint64(value.Int64()))
+		} else {
+			constant = NewFixedPointConstant(ConstantKindUFix64, value.Uint64())
+		}
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	compiler.emit(GetConstant, compiler.addConstant(constant))
+
+	return nil
+}
+
+func (compiler *Compiler) VisitStringExpression(expression *ast.StringExpression) ast.Repr {
+	stringType := compiler.Elaboration.StringExpressionType[expression]
+
+	if stringType == sema.CharacterType {
+		compiler.emit(GetConstant, compiler.addConstant(NewCharacterConstant(expression.Value)))
+		return nil
+	}
+
+	compiler.emit(GetConstant, compiler.stringConstant(expression.Value))
+	return nil
+}
+
+func (compiler *Compiler) VisitStringTemplateExpression(expression *ast.StringTemplateExpression) ast.Repr {
+	for _, value := range expression.Values {
+		compiler.emit(GetConstant, compiler.stringConstant(value))
+	}
+
+	for _, interpolatedExpression := range expression.Expressions {
+		compiler.compileExpression(interpolatedExpression)
+	}
+
+	compiler.emit(StringTemplate, uint16(len(expression.Expressions)))
+
+	return nil
+}
+
+func (compiler *Compiler) VisitArrayExpression(expression *ast.ArrayExpression) ast.Repr {
+	argumentTypes := compiler.Elaboration.ArrayExpressionArgumentTypes[expression]
+	arrayType := compiler.Elaboration.ArrayExpressionArrayType[expression]
+	elementType := arrayType.ElementType(false)
+
+	for i, value := range expression.Values {
+		compiler.compileExpression(value)
+		compiler.emitTransferAndConvert(argumentTypes[i], elementType)
+	}
+
+	compiler.emit(
+		NewArray,
+		compiler.typeIndex(arrayType),
+		uint16(len(expression.Values)),
+	)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitDictionaryExpression(expression *ast.DictionaryExpression) ast.Repr {
+	dictionaryType := compiler.Elaboration.DictionaryExpressionType[expression]
+	entryTypes := compiler.Elaboration.DictionaryExpressionEntryTypes[expression]
+
+	for i, entry := range expression.Entries {
+		entryType := entryTypes[i]
+
+		compiler.compileExpression(entry.Key)
+		compiler.emitTransferAndConvert(entryType.KeyType, dictionaryType.KeyType)
+
+		compiler.compileExpression(entry.Value)
+		compiler.emitTransferAndConvert(entryType.ValueType, dictionaryType.ValueType)
+	}
+
+	compiler.emit(
+		NewDictionary,
+		compiler.typeIndex(dictionaryType),
+		uint16(len(expression.Entries)),
+	)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitIdentifierExpression(expression *ast.IdentifierExpression) ast.Repr {
+	compiler.emitGetVariable(expression.Identifier.Identifier)
+	return nil
+}
+
+func (compiler *Compiler) VisitMemberExpression(expression *ast.MemberExpression) ast.Repr {
+	memberInfo := compiler.Elaboration.MemberExpressionMemberInfos[expression]
+	member := memberInfo.Member
+
+	if compiler.compileStaticMember(member) {
+		return nil
+	}
+
+	compiler.compileExpression(expression.Expression)
+
+	if !expression.Optional {
+		compiler.emitMemberAccess(expression, member)
+		return nil
+	}
+
+	// Optional chaining: If the target is nil, the result is nil.
+	// Only wrap the result in an optional, if it is not already an optional value
+
+	compiler.emit(Dup)
+	nilJump := compiler.emitJump(JumpIfNil)
+	compiler.emit(Unwrap)
+	compiler.emitMemberAccess(expression, member)
+	compiler.emit(EnsureOptional)
+	compiler.patchJump(nilJump)
+
+	return nil
+}
+
+// compileStaticMember emits the code for the access of a member which does not depend on the target value,
+// i.e. a nested composite or an enum case, and returns true.
+// If the member is not static, no code is emitted and false is returned.
+//
+func (compiler *Compiler) compileStaticMember(member *sema.Member) bool {
+	switch containerType := member.ContainerType.(type) {
+	case *sema.CompositeType:
+		switch member.DeclarationKind {
+		case common.DeclarationKindStructure,
+			common.DeclarationKindResource,
+			common.DeclarationKindEvent,
+			common.DeclarationKindEnum,
+			common.DeclarationKindContract:
+
+			name := nestedGlobalName(containerType, member.Identifier.Identifier)
+			compiler.emit(GetGlobal, compiler.importIndex(containerType.Location, name))
+			return true
+		}
+
+	case *sema.FunctionType:
+		// The members of enum constructors are the enum cases
+		if !containerType.IsConstructor {
+			return false
+		}
+		optionalType, ok := containerType.ReturnTypeAnnotation.Type.(*sema.OptionalType)
+		if !ok {
+			return false
+		}
+		enumType, ok := optionalType.Type.(*sema.CompositeType)
+		if !ok || enumType.Kind != common.CompositeKindEnum {
+			return false
+		}
+
+		name := enumCaseGlobalName(enumType, member.Identifier.Identifier)
+		compiler.emit(GetGlobal, compiler.importIndex(enumType.Location, name))
+		return true
+	}
+
+	return false
+}
+
+// emitMemberAccess emits the access of the member of the value on the stack.
+//
+// Functions of user-defined composites and interfaces are methods,
+// which are looked up based on the dynamic type of the value.
+//
+func (compiler *Compiler) emitMemberAccess(expression *ast.MemberExpression, member *sema.Member) {
+	name := compiler.stringConstant(expression.Identifier.Identifier)
+
+	if isMethod(member) {
+		compiler.emit(GetMethod, name)
+		return
+	}
+
+	if _, ok := compiler.Elaboration.IsNestedResourceMoveExpression[expression]; ok {
+		compiler.emit(RemoveField, name)
+		return
+	}
+
+	compiler.emit(GetField, name)
+}
+
+func isMethod(member *sema.Member) bool {
+	if member.DeclarationKind != common.DeclarationKindFunction {
+		return false
+	}
+
+	switch containerType := member.ContainerType.(type) {
+	case *sema.CompositeType:
+		return containerType.Location != nil
+	case *sema.InterfaceType:
+		return containerType.Location != nil
+	}
+
+	return false
+}
+
+func (compiler *Compiler) VisitIndexExpression(expression *ast.IndexExpression) ast.Repr {
+	compiler.compileExpression(expression.TargetExpression)
+	compiler.compileExpression(expression.IndexingExpression)
+	compiler.emit(GetIndex)
+	return nil
+}
+
+func (compiler *Compiler) VisitConditionalExpression(expression *ast.ConditionalExpression) ast.Repr {
+	compiler.compileExpression(expression.Test)
+	elseJump := compiler.emitJump(JumpIfFalse)
+
+	compiler.compileExpression(expression.Then)
+	endJump := compiler.emitJump(Jump)
+
+	compiler.patchJump(elseJump)
+	compiler.compileExpression(expression.Else)
+
+	compiler.patchJump(endJump)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitInvocationExpression(expression *ast.InvocationExpression) ast.Repr {
+
+	// Handle optional chaining on member expression, if any:
+	// - If the member expression is nil, finish execution
+	// - If the member expression is some value, the wrapped value
+	//   is the function value that should be invoked
+
+	nilJump := -1
+
+	invokedMemberExpression, ok := expression.InvokedExpression.(*ast.MemberExpression)
+	if ok && invokedMemberExpression.Optional {
+		memberInfo := compiler.Elaboration.MemberExpressionMemberInfos[invokedMemberExpression]
+
+		restore := compiler.withRange(invokedMemberExpression)
+		compiler.compileExpression(invokedMemberExpression.Expression)
+		compiler.emit(Dup)
+		nilJump = compiler.emitJump(JumpIfNil)
+		compiler.emit(Unwrap)
+		compiler.emitMemberAccess(invokedMemberExpression, memberInfo.Member)
+		restore()
+	} else {
+		compiler.compileExpression(expression.InvokedExpression)
+	}
+
+	// NOTE: evaluate all argument expressions in call-site scope, not in function body
+
+	argumentTypes := compiler.Elaboration.InvocationExpressionArgumentTypes[expression]
+	parameterTypes := compiler.Elaboration.InvocationExpressionParameterTypes[expression]
+	typeParameterTypes := compiler.Elaboration.InvocationExpressionTypeArguments[expression]
+
+	for i, argument := range expression.Arguments {
+		compiler.compileExpression(argument.Expression)
+
+		restore := compiler.withRange(argument.Expression)
+		if i < len(parameterTypes) {
+			compiler.emitTransferAndConvert(argumentTypes[i], parameterTypes[i])
+		} else {
+			compiler.emit(Transfer)
+		}
+		restore()
+	}
+
+	invocation := compiler.addInvocation(Invocation{
+		ArgumentTypes:      argumentTypes,
+		TypeParameterTypes: typeParameterTypes,
+	})
+	compiler.emit(Invoke, invocation)
+
+	// If this is invocation is optional chaining, wrap the result
+	// as an optional, as the result is expected to be an optional
+
+	if nilJump >= 0 {
+		compiler.emit(Some)
+		compiler.patchJump(nilJump)
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) VisitUnaryExpression(expression *ast.UnaryExpression) ast.Repr {
+	compiler.compileExpression(expression.Expression)
+
+	switch expression.Operation {
+	case ast.OperationNegate:
+		compiler.emit(Not)
+
+	case ast.OperationMinus:
+		compiler.emit(Negate)
+
+	case ast.OperationMove:
+		// NO-OP
+
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	return nil
+}
+
+var binaryOpcodes = map[ast.Operation]Opcode{
+	ast.OperationPlus:              Add,
+	ast.OperationMinus:             Subtract,
+	ast.OperationMul:               Multiply,
+	ast.OperationDiv:               Divide,
+	ast.OperationMod:               Mod,
+	ast.OperationBitwiseOr:         BitwiseOr,
+	ast.OperationBitwiseXor:        BitwiseXor,
+	ast.OperationBitwiseAnd:        BitwiseAnd,
+	ast.OperationBitwiseLeftShift:  BitwiseLeftShift,
+	ast.OperationBitwiseRightShift: BitwiseRightShift,
+	ast.OperationLess:              Less,
+	ast.OperationLessEqual:         LessEqual,
+	ast.OperationGreater:           Greater,
+	ast.OperationGreaterEqual:      GreaterEqual,
+	ast.OperationEqual:             Equal,
+	ast.OperationNotEqual:          NotEqual,
+}
+
+func (compiler *Compiler) VisitBinaryExpression(expression *ast.BinaryExpression) ast.Repr {
+
+	switch expression.Operation {
+	case ast.OperationOr, ast.OperationAnd:
+		// Only evaluate the right-hand side if the left-hand side
+		// does not already determine the result
+
+		var jumpOpcode Opcode
+		if expression.Operation == ast.OperationOr {
+			jumpOpcode = JumpIfTrue
+		} else {
+			jumpOpcode = JumpIfFalse
+		}
+
+		compiler.compileExpression(expression.Left)
+		compiler.emit(Dup)
+		endJump := compiler.emitJump(jumpOpcode)
+		compiler.emit(Pop)
+		compiler.compileExpression(expression.Right)
+		compiler.patchJump(endJump)
+
+		return nil
+
+	case ast.OperationNilCoalesce:
+		// Only evaluate the right-hand side if the left-hand side is nil
+
+		compiler.compileExpression(expression.Left)
+		compiler.emit(Dup)
+		nilJump := compiler.emitJump(JumpIfNil)
+		compiler.emit(Unwrap)
+		endJump := compiler.emitJump(Jump)
+
+		compiler.patchJump(nilJump)
+		compiler.emit(Pop)
+		compiler.compileExpression(expression.Right)
+
+		rightType := compiler.Elaboration.BinaryExpressionRightTypes[expression]
+		resultType := compiler.Elaboration.BinaryExpressionResultTypes[expression]
+
+		// NOTE: important to convert both any and optional
+		compiler.emit(
+			Convert,
+			compiler.typeIndex(rightType),
+			compiler.typeIndex(resultType),
+		)
+
+		compiler.patchJump(endJump)
+
+		return nil
+	}
+
+	opcode, ok := binaryOpcodes[expression.Operation]
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	compiler.compileExpression(expression.Left)
+	compiler.compileExpression(expression.Right)
+	compiler.emit(opcode)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitFunctionExpression(expression *ast.FunctionExpression) ast.Repr {
+	functionType := compiler.Elaboration.FunctionExpressionFunctionType[expression]
+
+	index := compiler.compileFunction(
+		"",
+		functionKindFunction,
+		functionType,
+		expression.ParameterList,
+		expression.FunctionBlock,
+		nil,
+		compiler.function,
+	)
+
+	compiler.emit(NewClosure, index)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitCastingExpression(expression *ast.CastingExpression) ast.Repr {
+	compiler.compileExpression(expression.Expression)
+
+	expectedType := compiler.Elaboration.CastingTargetTypes[expression]
+
+	switch expression.Operation {
+	case ast.OperationFailableCast:
+		compiler.emit(FailableCast, compiler.typeIndex(expectedType))
+
+	case ast.OperationForceCast:
+		compiler.emit(ForceCast, compiler.typeIndex(expectedType))
+
+	case ast.OperationCast:
+		staticValueType := compiler.Elaboration.CastingStaticValueTypes[expression]
+		// The cast may upcast to an optional type, e.g. `1 as Int?`, so box
+		compiler.emit(
+			Convert,
+			compiler.typeIndex(staticValueType),
+			compiler.typeIndex(expectedType),
+		)
+
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) VisitCreateExpression(expression *ast.CreateExpression) ast.Repr {
+	compiler.compileExpression(expression.InvocationExpression)
+	return nil
+}
+
+func (compiler *Compiler) VisitDestroyExpression(expression *ast.DestroyExpression) ast.Repr {
+	compiler.compileExpression(expression.Expression)
+	compiler.emit(Destroy)
+	compiler.emit(Void)
+	return nil
+}
+
+func (compiler *Compiler) VisitReferenceExpression(expression *ast.ReferenceExpression) ast.Repr {
+	borrowType := compiler.Elaboration.ReferenceExpressionBorrowTypes[expression]
+
+	compiler.compileExpression(expression.Expression)
+	compiler.emit(NewReference, compiler.typeIndex(borrowType))
+
+	return nil
+}
+
+func (compiler *Compiler) VisitForceExpression(expression *ast.ForceExpression) ast.Repr {
+	compiler.compileExpression(expression.Expression)
+
+	// The force-nil error is reported at the end of the expression
+	end := expression.EndPosition(nil)
+	previous := compiler.currentRange
+	compiler.currentRange = ast.NewUnmeteredRange(end, end)
+	compiler.emit(Force)
+	compiler.currentRange = previous
+
+	return nil
+}
+
+func (compiler *Compiler) VisitPathExpression(expression *ast.PathExpression) ast.Repr {
+	domain := common.PathDomainFromIdentifier(expression.Domain.Identifier)
+	constant := NewPathConstant(domain, expression.Identifier.Identifier)
+	compiler.emit(GetConstant, compiler.addConstant(constant))
+	return nil
+}
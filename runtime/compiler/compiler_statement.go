@@ -0,0 +1,642 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+import (
+	"math/big"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+func (compiler *Compiler) compileStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		compiler.compileStatement(statement)
+	}
+}
+
+func (compiler *Compiler) compileStatement(statement ast.Statement) {
+	defer compiler.withRange(statement)()
+
+	compiler.emit(Statement)
+	statement.Accept(compiler)
+}
+
+func (compiler *Compiler) VisitBlock(block *ast.Block) ast.Repr {
+	// block scope: each block gets a scope
+	activations := compiler.function.activations
+	activations.PushNewWithCurrent()
+	defer activations.Pop()
+
+	compiler.compileStatements(block.Statements)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitFunctionBlock(_ *ast.FunctionBlock) ast.Repr {
+	// Function blocks are compiled explicitly, see compileFunctionBlock
+	panic(errors.NewUnreachableError())
+}
+
+func (compiler *Compiler) VisitReturnStatement(statement *ast.ReturnStatement) ast.Repr {
+	function := compiler.function
+
+	if statement.Expression != nil {
+		compiler.compileExpression(statement.Expression)
+
+		valueType := compiler.Elaboration.ReturnStatementValueTypes[statement]
+		returnType := compiler.Elaboration.ReturnStatementReturnTypes[statement]
+
+		// NOTE: copy on return
+		compiler.emitTransferAndConvert(valueType, returnType)
+
+		if function.hasEpilogue {
+			if function.result != nil {
+				compiler.emit(SetLocal, function.result.Index)
+			} else {
+				compiler.emit(Pop)
+			}
+		} else {
+			compiler.emit(ReturnValue)
+			return nil
+		}
+	} else if !function.hasEpilogue {
+		compiler.emit(Return)
+		return nil
+	}
+
+	function.returns = append(function.returns, compiler.emitJump(Jump))
+
+	return nil
+}
+
+func (compiler *Compiler) currentBreakable(loopOnly bool) *breakable {
+	breakables := compiler.function.breakables
+	for i := len(breakables) - 1; i >= 0; i-- {
+		breakable := breakables[i]
+		if !loopOnly || breakable.isLoop {
+			return breakable
+		}
+	}
+	panic(errors.NewUnreachableError())
+}
+
+func (compiler *Compiler) pushBreakable(isLoop bool) *breakable {
+	breakable := &breakable{
+		isLoop: isLoop,
+	}
+	function := compiler.function
+	function.breakables = append(function.breakables, breakable)
+	return breakable
+}
+
+func (compiler *Compiler) popBreakable() {
+	function := compiler.function
+	function.breakables = function.breakables[:len(function.breakables)-1]
+}
+
+func (compiler *Compiler) VisitBreakStatement(_ *ast.BreakStatement) ast.Repr {
+	const loopOnly = false
+	breakable := compiler.currentBreakable(loopOnly)
+	breakable.breaks = append(breakable.breaks, compiler.emitJump(Jump))
+	return nil
+}
+
+func (compiler *Compiler) VisitContinueStatement(_ *ast.ContinueStatement) ast.Repr {
+	const loopOnly = true
+	breakable := compiler.currentBreakable(loopOnly)
+	breakable.continues = append(breakable.continues, compiler.emitJump(Jump))
+	return nil
+}
+
+func (compiler *Compiler) VisitIfStatement(statement *ast.IfStatement) ast.Repr {
+	switch test := statement.Test.(type) {
+	case ast.Expression:
+		compiler.compileIfStatementWithTestExpression(test, statement.Then, statement.Else)
+	case *ast.VariableDeclaration:
+		compiler.compileIfStatementWithVariableDeclaration(test, statement.Then, statement.Else)
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) compileIfStatementWithTestExpression(
+	test ast.Expression,
+	thenBlock, elseBlock *ast.Block,
+) {
+	compiler.compileExpression(test)
+	elseJump := compiler.emitJump(JumpIfFalse)
+
+	compiler.VisitBlock(thenBlock)
+
+	if elseBlock == nil {
+		compiler.patchJump(elseJump)
+		return
+	}
+
+	endJump := compiler.emitJump(Jump)
+	compiler.patchJump(elseJump)
+	compiler.VisitBlock(elseBlock)
+	compiler.patchJump(endJump)
+}
+
+func (compiler *Compiler) compileIfStatementWithVariableDeclaration(
+	declaration *ast.VariableDeclaration,
+	thenBlock, elseBlock *ast.Block,
+) {
+	valueType := compiler.Elaboration.VariableDeclarationValueTypes[declaration]
+	targetType := compiler.Elaboration.VariableDeclarationTargetTypes[declaration]
+
+	compiler.compileVariableDeclarationValue(declaration)
+
+	compiler.emit(Dup)
+	nilJump := compiler.emitJump(JumpIfNil)
+
+	(func() {
+		activations := compiler.function.activations
+		activations.PushNewWithCurrent()
+		defer activations.Pop()
+
+		compiler.emit(Unwrap)
+		compiler.emitTransferAndConvert(valueType, targetType)
+
+		local := compiler.declareLocal(declaration.Identifier.Identifier)
+		compiler.emit(SetLocal, local.Index)
+
+		compiler.VisitBlock(thenBlock)
+	})()
+
+	endJump := compiler.emitJump(Jump)
+
+	// The nil value is still on the stack
+	compiler.patchJump(nilJump)
+	compiler.emit(Pop)
+
+	if elseBlock != nil {
+		compiler.VisitBlock(elseBlock)
+	}
+
+	compiler.patchJump(endJump)
+}
+
+// compileVariableDeclarationValue compiles the value of the variable declaration,
+// and the assignment of the second value, if any.
+//
+func (compiler *Compiler) compileVariableDeclarationValue(declaration *ast.VariableDeclaration) {
+
+	// NOTE: The value may be an access expression (member access, index access),
+	// which implicitly removes a resource, and the removal must be performed
+	// before a second value is assigned, if any
+
+	if _, ok := declaration.Value.(*ast.IndexExpression); !ok && declaration.SecondValue == nil {
+		compiler.compileExpression(declaration.Value)
+		return
+	}
+
+	target := compiler.prepareAssignmentTarget(declaration.Value)
+
+	const allowMissing = false
+	target.emitGet(allowMissing)
+
+	if declaration.SecondValue == nil {
+		return
+	}
+
+	valueType := compiler.Elaboration.VariableDeclarationValueTypes[declaration]
+	secondValueType := compiler.Elaboration.VariableDeclarationSecondValueTypes[declaration]
+
+	value := compiler.newLocal()
+	compiler.emit(SetLocal, value.Index)
+
+	target.emitSet(func() {
+		compiler.compileExpression(declaration.SecondValue)
+		compiler.emitTransferAndConvert(secondValueType, valueType)
+	})
+
+	compiler.emit(GetLocal, value.Index)
+}
+
+func (compiler *Compiler) VisitSwitchStatement(statement *ast.SwitchStatement) ast.Repr {
+	compiler.compileExpression(statement.Expression)
+	testValue := compiler.newLocal()
+	compiler.emit(SetLocal, testValue.Index)
+
+	const isLoop = false
+	breakable := compiler.pushBreakable(isLoop)
+
+	var endJumps []int
+
+	for _, switchCase := range statement.Cases {
+
+		nextJump := -1
+
+		// If the case has no expression it is the default case,
+		// otherwise compare the case value to the test value
+
+		if switchCase.Expression != nil {
+			restore := compiler.withRange(switchCase.Expression)
+			compiler.emit(GetLocal, testValue.Index)
+			compiler.compileExpression(switchCase.Expression)
+			compiler.emit(Equal)
+			nextJump = compiler.emitJump(JumpIfFalse)
+			restore()
+		}
+
+		// NOTE: the new block ensures that a new scope is introduced
+
+		compiler.VisitBlock(&ast.Block{
+			Statements: switchCase.Statements,
+			Range:      switchCase.Range,
+		})
+
+		if nextJump < 0 {
+			break
+		}
+
+		endJumps = append(endJumps, compiler.emitJump(Jump))
+		compiler.patchJump(nextJump)
+	}
+
+	compiler.popBreakable()
+
+	for _, operandOffset := range endJumps {
+		compiler.patchJump(operandOffset)
+	}
+	for _, operandOffset := range breakable.breaks {
+		compiler.patchJump(operandOffset)
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) VisitWhileStatement(statement *ast.WhileStatement) ast.Repr {
+	start := compiler.currentOffset()
+
+	compiler.compileExpression(statement.Test)
+	endJump := compiler.emitJump(JumpIfFalse)
+
+	compiler.emit(Loop)
+
+	const isLoop = true
+	breakable := compiler.pushBreakable(isLoop)
+	compiler.VisitBlock(statement.Block)
+	compiler.popBreakable()
+
+	for _, operandOffset := range breakable.continues {
+		compiler.patchJump(operandOffset)
+	}
+
+	compiler.emit(Jump, start)
+
+	compiler.patchJump(endJump)
+	for _, operandOffset := range breakable.breaks {
+		compiler.patchJump(operandOffset)
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) VisitForStatement(statement *ast.ForStatement) ast.Repr {
+	activations := compiler.function.activations
+	activations.PushNewWithCurrent()
+	defer activations.Pop()
+
+	// Iterate over a copy of the array
+
+	compiler.compileExpression(statement.Value)
+	compiler.emit(Transfer)
+	array := compiler.newLocal()
+	compiler.emit(SetLocal, array.Index)
+
+	// The index is also the index variable, if any
+
+	var index *Local
+	if statement.Index != nil {
+		index = compiler.declareLocal(statement.Index.Identifier)
+	} else {
+		index = compiler.newLocal()
+	}
+	compiler.emitIntegerConstant(big.NewInt(0), sema.IntType)
+	compiler.emit(SetLocal, index.Index)
+
+	element := compiler.declareLocal(statement.Identifier.Identifier)
+
+	start := compiler.currentOffset()
+
+	compiler.emit(GetLocal, index.Index)
+	compiler.emit(GetLocal, array.Index)
+	compiler.emit(GetField, compiler.stringConstant("length"))
+	compiler.emit(Less)
+	endJump := compiler.emitJump(JumpIfFalse)
+
+	compiler.emit(Loop)
+
+	compiler.emit(GetLocal, array.Index)
+	compiler.emit(GetLocal, index.Index)
+	compiler.emit(GetIndex)
+	compiler.emit(SetLocal, element.Index)
+
+	const isLoop = true
+	breakable := compiler.pushBreakable(isLoop)
+	compiler.VisitBlock(statement.Block)
+	compiler.popBreakable()
+
+	for _, operandOffset := range breakable.continues {
+		compiler.patchJump(operandOffset)
+	}
+
+	compiler.emit(GetLocal, index.Index)
+	compiler.emitIntegerConstant(big.NewInt(1), sema.IntType)
+	compiler.emit(Add)
+	compiler.emit(SetLocal, index.Index)
+
+	compiler.emit(Jump, start)
+
+	compiler.patchJump(endJump)
+	for _, operandOffset := range breakable.breaks {
+		compiler.patchJump(operandOffset)
+	}
+
+	return nil
+}
+
+func (compiler *Compiler) VisitEmitStatement(statement *ast.EmitStatement) ast.Repr {
+	compiler.compileExpression(statement.InvocationExpression)
+
+	eventType := compiler.Elaboration.EmitStatementEventTypes[statement]
+	compiler.emit(Emit, compiler.typeIndex(eventType))
+
+	return nil
+}
+
+func (compiler *Compiler) VisitVariableDeclaration(declaration *ast.VariableDeclaration) ast.Repr {
+	compiler.compileVariableDeclaration(declaration, func() {
+
+		// NOTE: lexical scope, always declare a new local.
+		// Do not find an existing local and assign the value!
+
+		local := compiler.declareLocal(declaration.Identifier.Identifier)
+		compiler.emit(SetLocal, local.Index)
+	})
+
+	return nil
+}
+
+// compileVariableDeclaration compiles the value of the variable declaration,
+// and then calls the given function, which emits the code that declares the variable.
+//
+func (compiler *Compiler) compileVariableDeclaration(
+	declaration *ast.VariableDeclaration,
+	declare func(),
+) {
+	valueType := compiler.Elaboration.VariableDeclarationValueTypes[declaration]
+	targetType := compiler.Elaboration.VariableDeclarationTargetTypes[declaration]
+
+	compiler.compileVariableDeclarationValue(declaration)
+	compiler.emitTransferAndConvert(valueType, targetType)
+
+	declare()
+}
+
+func (compiler *Compiler) VisitFunctionDeclaration(declaration *ast.FunctionDeclaration) ast.Repr {
+
+	// Function declarations are only compiled by this visitor method
+	// when they are nested in a function
+
+	compiler.checkNotGeneric(declaration.TypeParameterList)
+
+	name := declaration.Identifier.Identifier
+	functionType := compiler.Elaboration.FunctionDeclarationFunctionTypes[declaration]
+
+	// Declare the local before compiling the function,
+	// so the function can refer to itself
+
+	local := compiler.declareLocal(name)
+
+	index := compiler.compileFunction(
+		name,
+		functionKindFunction,
+		functionType,
+		declaration.ParameterList,
+		declaration.FunctionBlock,
+		nil,
+		compiler.function,
+	)
+
+	compiler.emit(NewClosure, index)
+	compiler.emit(SetLocal, local.Index)
+
+	return nil
+}
+
+func (compiler *Compiler) VisitAssignmentStatement(assignment *ast.AssignmentStatement) ast.Repr {
+	targetType := compiler.Elaboration.AssignmentStatementTargetTypes[assignment]
+	valueType := compiler.Elaboration.AssignmentStatementValueTypes[assignment]
+
+	target := compiler.prepareAssignmentTarget(assignment.Target)
+
+	// If the assignment is a forced move,
+	// ensure that the target is nil,
+	// otherwise abort
+
+	if assignment.Transfer.Operation == ast.TransferOperationMoveForced {
+
+		// If the force-move assignment is used for the initialization of a field,
+		// then there is no prior value for the field, so allow missing
+
+		const allowMissing = true
+		target.emitGet(allowMissing)
+
+		nilJump := compiler.emitJump(JumpIfNil)
+		compiler.emit(FailForceAssignment)
+		compiler.patchJump(nilJump)
+	}
+
+	target.emitSet(func() {
+		compiler.compileExpression(assignment.Value)
+		compiler.emitTransferAndConvert(valueType, targetType)
+	})
+
+	return nil
+}
+
+func (compiler *Compiler) VisitSwapStatement(swap *ast.SwapStatement) ast.Repr {
+	leftType := compiler.Elaboration.SwapStatementLeftTypes[swap]
+	rightType := compiler.Elaboration.SwapStatementRightTypes[swap]
+
+	const allowMissing = false
+
+	left := compiler.prepareAssignmentTarget(swap.Left)
+	left.emitGet(allowMissing)
+	leftValue := compiler.newLocal()
+	compiler.emit(SetLocal, leftValue.Index)
+
+	right := compiler.prepareAssignmentTarget(swap.Right)
+	right.emitGet(allowMissing)
+	rightValue := compiler.newLocal()
+	compiler.emit(SetLocal, rightValue.Index)
+
+	// Set right value to left target
+	// and left value to right target
+
+	left.emitSet(func() {
+		compiler.emit(GetLocal, rightValue.Index)
+		compiler.emitTransferAndConvert(rightType, leftType)
+	})
+
+	right.emitSet(func() {
+		compiler.emit(GetLocal, leftValue.Index)
+		compiler.emitTransferAndConvert(leftType, rightType)
+	})
+
+	return nil
+}
+
+func (compiler *Compiler) VisitExpressionStatement(statement *ast.ExpressionStatement) ast.Repr {
+	compiler.compileExpression(statement.Expression)
+	compiler.emit(Pop)
+	return nil
+}
+
+// assignmentTarget is the target of an assignment, i.e. a variable, a member, or an element.
+//
+// The target expression and the indexing expression, if any,
+// are evaluated once, when the target is prepared,
+// and stored in locals, so the target can be read and written multiple times.
+//
+type assignmentTarget struct {
+	compiler   *Compiler
+	expression ast.Expression
+	// target is the local which holds the value of a member or index expression's target
+	target *Local
+	// key is the local which holds the value of an index expression's indexing value
+	key                  *Local
+	isNestedResourceMove bool
+}
+
+func (compiler *Compiler) prepareAssignmentTarget(expression ast.Expression) assignmentTarget {
+	_, isNestedResourceMove := compiler.Elaboration.IsNestedResourceMoveExpression[expression]
+
+	target := assignmentTarget{
+		compiler:             compiler,
+		expression:           expression,
+		isNestedResourceMove: isNestedResourceMove,
+	}
+
+	switch expression := expression.(type) {
+	case *ast.MemberExpression:
+		compiler.compileExpression(expression.Expression)
+		target.target = compiler.newLocal()
+		compiler.emit(SetLocal, target.target.Index)
+
+	case *ast.IndexExpression:
+		compiler.compileExpression(expression.TargetExpression)
+		target.target = compiler.newLocal()
+		compiler.emit(SetLocal, target.target.Index)
+
+		// Transfer and convert the indexing value,
+		// as it is essentially an "argument" of the get/set operation
+
+		indexedType := compiler.Elaboration.IndexExpressionIndexedTypes[expression]
+		indexingType := compiler.Elaboration.IndexExpressionIndexingTypes[expression]
+
+		compiler.compileExpression(expression.IndexingExpression)
+		compiler.emitTransferAndConvert(indexingType, indexedType.IndexingType())
+		target.key = compiler.newLocal()
+		compiler.emit(SetLocal, target.key.Index)
+	}
+
+	return target
+}
+
+// emitGet emits the code which pushes the value of the target.
+// If allowMissing is true, a missing member results in nil.
+//
+func (t assignmentTarget) emitGet(allowMissing bool) {
+	compiler := t.compiler
+
+	defer compiler.withRange(t.expression)()
+
+	switch expression := t.expression.(type) {
+	case *ast.IdentifierExpression:
+		compiler.emitGetVariable(expression.Identifier.Identifier)
+
+	case *ast.MemberExpression:
+		name := compiler.stringConstant(expression.Identifier.Identifier)
+		compiler.emit(GetLocal, t.target.Index)
+		switch {
+		case t.isNestedResourceMove:
+			compiler.emit(RemoveField, name)
+		case allowMissing:
+			compiler.emit(GetFieldOrNil, name)
+		default:
+			compiler.emit(GetField, name)
+		}
+
+	case *ast.IndexExpression:
+		compiler.emit(GetLocal, t.target.Index)
+		compiler.emit(GetLocal, t.key.Index)
+		if t.isNestedResourceMove {
+			compiler.emit(RemoveIndex)
+		} else {
+			compiler.emit(GetIndex)
+		}
+
+	default:
+		compiler.compileExpression(expression)
+	}
+}
+
+// emitSet emits the code which sets the target to the value
+// pushed by the code emitted by the given function.
+//
+func (t assignmentTarget) emitSet(emitValue func()) {
+	compiler := t.compiler
+
+	switch expression := t.expression.(type) {
+	case *ast.IdentifierExpression:
+		emitValue()
+		compiler.emitSetVariable(expression.Identifier.Identifier)
+
+	case *ast.MemberExpression:
+		compiler.emit(GetLocal, t.target.Index)
+		emitValue()
+		restore := compiler.withRange(expression)
+		compiler.emit(SetField, compiler.stringConstant(expression.Identifier.Identifier))
+		restore()
+
+	case *ast.IndexExpression:
+		compiler.emit(GetLocal, t.target.Index)
+		compiler.emit(GetLocal, t.key.Index)
+		emitValue()
+		restore := compiler.withRange(expression)
+		if t.isNestedResourceMove {
+			compiler.emit(InsertIndex)
+		} else {
+			compiler.emit(SetIndex)
+		}
+		restore()
+
+	default:
+		panic(errors.NewUnreachableError())
+	}
+}
@@ -21,63 +21,256 @@ package compiler
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/onflow/cadence/runtime/compiler/ir"
+	"github.com/onflow/cadence/runtime/sema"
 	"github.com/onflow/cadence/runtime/tests/checker"
 )
 
-func TestCompilerSimple(t *testing.T) {
+func compile(t *testing.T, code string) (*Program, error) {
+	checker, err := checker.ParseAndCheck(t, code)
+	require.NoError(t, err)
+
+	compiler := NewCompiler(checker.Program, checker.Elaboration, checker.Location)
+	return compiler.Compile()
+}
+
+func TestCompileFunction(t *testing.T) {
 
-	checker, err := checker.ParseAndCheck(t, `
+	t.Parallel()
+
+	program, err := compile(t, `
       fun inc(a: Int): Int {
           let mod = 1
           return a + mod
       }
     `)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"inc"}, program.Globals)
+	assert.Equal(t, uint16(0), program.Initializer)
+
+	require.Len(t, program.Functions, 2)
+
+	initializer := program.Functions[0]
+	assert.Equal(t, InitializerFunctionName, initializer.Name)
+	assert.Equal(t,
+		[]byte{
+			byte(NewClosure), 0, 1,
+			byte(SetGlobal), 0, 0,
+			byte(Return),
+		},
+		initializer.Code,
+	)
+
+	function := program.Functions[1]
+	assert.Equal(t, "inc", function.Name)
+	assert.Equal(t, uint16(1), function.ParameterCount)
+	assert.Equal(t, uint16(2), function.LocalCount)
+	assert.False(t, function.IsMethod)
+	assert.Equal(t,
+		[]byte{
+			// let mod = 1
+			byte(Statement),
+			byte(GetConstant), 0, 0,
+			byte(TransferAndConvert), 0, 0, 0, 0,
+			byte(SetLocal), 0, 1,
+			// return a + mod
+			byte(Statement),
+			byte(GetLocal), 0, 0,
+			byte(GetLocal), 0, 1,
+			byte(Add),
+			byte(TransferAndConvert), 0, 0, 0, 0,
+			byte(ReturnValue),
+			byte(Return),
+		},
+		function.Code,
+	)
+
+	assert.Equal(t, []sema.Type{sema.IntType}, program.Types)
+	require.Len(t, program.Constants, 1)
+	assert.Equal(t, "1", program.Constants[0].String())
 
+	// The return statement is on the fourth line
+	assert.Equal(t, 4, function.Range(len(function.Code)-3).StartPos.Line)
+}
+
+func TestCompileComposites(t *testing.T) {
+
+	t.Parallel()
+
+	program, err := compile(t, `
+      pub contract C {
+
+          pub struct S {
+              pub fun foo() {}
+          }
+
+          pub resource R {
+              destroy() {}
+          }
+
+          pub enum E: UInt8 {
+              pub case a
+              pub case b
+          }
+
+          pub event Foo(x: Int)
+      }
+    `)
 	require.NoError(t, err)
 
-	compiler := NewCompiler(checker)
-
-	res := compiler.VisitFunctionDeclaration(checker.Program.FunctionDeclarations()[0])
-
-	require.Equal(t,
-		&ir.Func{
-			Name: "inc",
-			Type: ir.FuncType{
-				Params: []ir.ValType{
-					ir.ValTypeInt,
-				},
-				Results: []ir.ValType{
-					ir.ValTypeInt,
-				},
-			},
-			Locals: []ir.Local{
-				{Type: ir.ValTypeInt},
-			},
-			Statement: &ir.Sequence{
-				Stmts: []ir.Stmt{
-					&ir.StoreLocal{
-						LocalIndex: 1,
-						Exp: &ir.Const{
-							Constant: ir.Int{Value: []byte{1, 1}},
-						},
-					},
-					&ir.Return{
-						Exp: &ir.BinOpExpr{
-							Op: ir.BinOpPlus,
-							Left: &ir.CopyLocal{
-								LocalIndex: 0,
-							},
-							Right: &ir.CopyLocal{
-								LocalIndex: 1,
-							},
-						},
-					},
-				},
-			},
+	assert.Equal(t, "C", program.Contract)
+	assert.Equal(t,
+		[]string{"C", "C.S", "C.R", "C.E", "C.E.a", "C.E.b", "C.Foo"},
+		program.Globals,
+	)
+
+	functionNames := make([]string, len(program.Functions))
+	for i, function := range program.Functions {
+		functionNames[i] = function.Name
+	}
+
+	assert.Equal(t,
+		[]string{
+			InitializerFunctionName,
+			"C",
+			"C.S",
+			"C.S.foo",
+			"C.R",
+			"C.R.destroy",
+			"C.E",
+			"C.Foo",
 		},
-		res,
+		functionNames,
+	)
+
+	assert.True(t, program.Functions[3].IsMethod)
+	assert.Equal(t, uint16(1), program.Functions[7].ParameterCount)
+}
+
+func TestCompileUnsupported(t *testing.T) {
+
+	t.Parallel()
+
+	test := func(t *testing.T, code string, feature string) {
+		_, err := compile(t, code)
+		require.Error(t, err)
+
+		var unsupportedErr *UnsupportedError
+		require.ErrorAs(t, err, &unsupportedErr)
+		assert.Equal(t, feature, unsupportedErr.Feature)
+	}
+
+	t.Run("transactions", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t,
+			`
+              transaction {
+                  execute {}
+              }
+            `,
+			"transactions",
+		)
+	})
+
+	t.Run("generic function", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t,
+			`
+              fun id<T>(_ x: T): T {
+                  return x
+              }
+            `,
+			"generic declarations",
+		)
+	})
+
+	t.Run("generic nested function", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t,
+			`
+              fun test(): Int {
+                  fun id<T>(_ x: T): T {
+                      return x
+                  }
+                  return id<Int>(1)
+              }
+            `,
+			"generic declarations",
+		)
+	})
+
+	t.Run("generic composite", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t,
+			`
+              struct Box<T> {
+                  let value: T
+
+                  init(value: T) {
+                      self.value = value
+                  }
+              }
+            `,
+			"generic declarations",
+		)
+	})
+
+	t.Run("generic method", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(t,
+			`
+              struct S {
+                  fun id<T>(_ x: T): T {
+                      return x
+                  }
+              }
+            `,
+			"generic declarations",
+		)
+	})
+}
+
+func TestDisassemble(t *testing.T) {
+
+	t.Parallel()
+
+	program, err := compile(t, `
+      fun test(): String {
+          return "a".concat("b")
+      }
+    `)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`fun $init (parameters: 0, locals: 0)
+     0 NewClosure 1 // test
+     3 SetGlobal 0 // test
+     6 Return
+
+fun test (parameters: 0, locals: 0)
+     0 Statement
+     1 GetConstant 0 // "a"
+     4 GetField 1 // concat
+     7 GetConstant 2 // "b"
+    10 TransferAndConvert 0 0 // String to String
+    15 Invoke 0
+    18 TransferAndConvert 0 0 // String to String
+    23 ReturnValue
+    24 Return
+`,
+		Disassemble(program),
 	)
 }
@@ -0,0 +1,168 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/format"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type=ConstantKind -trimprefix=ConstantKind
+
+type ConstantKind byte
+
+const (
+	ConstantKindUnknown ConstantKind = iota
+	ConstantKindString
+	ConstantKindCharacter
+	ConstantKindInteger
+	ConstantKindFix64
+	ConstantKindUFix64
+	ConstantKindAddress
+	ConstantKindPath
+
+	// NOTE: add new constant kinds above
+)
+
+// Constant is a constant value of a program.
+//
+// Integers are encoded as a sign byte followed by the big-endian magnitude,
+// and their type is stored in the type of the constant.
+// Fixed-point numbers are encoded as big-endian 64-bit integers.
+// Paths are encoded as the domain byte followed by the identifier.
+//
+type Constant struct {
+	Kind ConstantKind
+	// Type is the integer type of integer constants
+	Type sema.Type
+	Data []byte
+}
+
+func NewStringConstant(value string) Constant {
+	return Constant{
+		Kind: ConstantKindString,
+		Data: []byte(value),
+	}
+}
+
+func NewCharacterConstant(value string) Constant {
+	return Constant{
+		Kind: ConstantKindCharacter,
+		Data: []byte(value),
+	}
+}
+
+func NewAddressConstant(address common.Address) Constant {
+	return Constant{
+		Kind: ConstantKindAddress,
+		Data: address[:],
+	}
+}
+
+func NewIntegerConstant(value *big.Int, ty sema.Type) Constant {
+	data := make([]byte, 1, 1+len(value.Bits())*8)
+	if value.Sign() < 0 {
+		data[0] = 1
+	}
+	data = append(data, value.Bytes()...)
+
+	return Constant{
+		Kind: ConstantKindInteger,
+		Type: ty,
+		Data: data,
+	}
+}
+
+// BigInt returns the value of an integer constant.
+//
+func (c Constant) BigInt() *big.Int {
+	if c.Kind != ConstantKindInteger || len(c.Data) < 1 {
+		panic(errors.NewUnreachableError())
+	}
+
+	value := new(big.Int).SetBytes(c.Data[1:])
+	if c.Data[0] == 1 {
+		value.Neg(value)
+	}
+	return value
+}
+
+func NewFixedPointConstant(kind ConstantKind, value uint64) Constant {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+
+	return Constant{
+		Kind: kind,
+		Data: data,
+	}
+}
+
+// Uint64 returns the value of a fixed-point constant.
+//
+func (c Constant) Uint64() uint64 {
+	switch c.Kind {
+	case ConstantKindFix64, ConstantKindUFix64:
+		return binary.BigEndian.Uint64(c.Data)
+	}
+
+	panic(errors.NewUnreachableError())
+}
+
+func NewPathConstant(domain common.PathDomain, identifier string) Constant {
+	data := make([]byte, 1, 1+len(identifier))
+	data[0] = byte(domain)
+	data = append(data, identifier...)
+
+	return Constant{
+		Kind: ConstantKindPath,
+		Data: data,
+	}
+}
+
+// String returns the Cadence source representation of the constant.
+//
+func (c Constant) String() string {
+	switch c.Kind {
+	case ConstantKindString, ConstantKindCharacter:
+		return format.String(string(c.Data))
+
+	case ConstantKindInteger:
+		return c.BigInt().String()
+
+	case ConstantKindFix64:
+		return format.Fix64(int64(c.Uint64()))
+
+	case ConstantKindUFix64:
+		return format.UFix64(c.Uint64())
+
+	case ConstantKindAddress:
+		return common.MustBytesToAddress(c.Data).HexWithPrefix()
+
+	case ConstantKindPath:
+		domain := common.PathDomain(c.Data[0])
+		return format.Path(domain.Identifier(), string(c.Data[1:]))
+	}
+
+	panic(errors.NewUnreachableError())
+}
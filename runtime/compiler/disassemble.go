@@ -0,0 +1,127 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+)
+
+// Disassemble returns a human-readable listing of the functions of the program.
+//
+func Disassemble(program *Program) string {
+	var builder strings.Builder
+
+	for i, function := range program.Functions {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		disassembleFunction(&builder, program, function)
+	}
+
+	return builder.String()
+}
+
+func disassembleFunction(builder *strings.Builder, program *Program, function *Function) {
+	name := function.Name
+	if name == "" {
+		name = "<anonymous>"
+	}
+
+	_, _ = fmt.Fprintf(
+		builder,
+		"fun %s (parameters: %d, locals: %d)\n",
+		name,
+		function.ParameterCount,
+		function.LocalCount,
+	)
+
+	code := function.Code
+	for offset := 0; offset < len(code); {
+		opcode := Opcode(code[offset])
+
+		_, _ = fmt.Fprintf(builder, "%6d %s", offset, opcode)
+
+		operandOffset := offset + 1
+		operands := make([]uint16, 0, 2)
+		for _, size := range opcode.Operands() {
+			var operand uint16
+			switch size {
+			case 1:
+				operand = uint16(code[operandOffset])
+			case 2:
+				operand = binary.BigEndian.Uint16(code[operandOffset:])
+			}
+			operands = append(operands, operand)
+			operandOffset += size
+
+			_, _ = fmt.Fprintf(builder, " %d", operand)
+		}
+
+		comment := operandsComment(program, opcode, operands)
+		if comment != "" {
+			_, _ = fmt.Fprintf(builder, " // %s", comment)
+		}
+
+		builder.WriteString("\n")
+
+		offset = operandOffset
+	}
+}
+
+func operandsComment(program *Program, opcode Opcode, operands []uint16) string {
+	switch opcode {
+	case GetConstant:
+		return program.Constants[operands[0]].String()
+
+	case GetBuiltin, GetMethod,
+		GetField, GetFieldOrNil, SetField, RemoveField:
+
+		return string(program.Constants[operands[0]].Data)
+
+	case GetGlobal, SetGlobal:
+		index := int(operands[0])
+		if index < len(program.Globals) {
+			return program.Globals[index]
+		}
+		imported := program.Imports[index-len(program.Globals)]
+		return fmt.Sprintf("%s from %s", imported.Name, imported.Location)
+
+	case NewClosure:
+		return program.Functions[operands[0]].Name
+
+	case NewComposite, Emit, NewReference, FailableCast, ForceCast, NewArray, NewDictionary:
+		return program.Types[operands[0]].QualifiedString()
+
+	case TransferAndConvert, Convert:
+		return fmt.Sprintf(
+			"%s to %s",
+			program.Types[operands[0]].QualifiedString(),
+			program.Types[operands[1]].QualifiedString(),
+		)
+
+	case Fail:
+		return ast.ConditionKind(operands[0]).Name()
+	}
+
+	return ""
+}
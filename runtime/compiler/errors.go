@@ -16,20 +16,27 @@
  * limitations under the License.
  */
 
-package ir
+package compiler
 
-type Func struct {
-	Name      string
-	Type      FuncType
-	Locals    []Local
-	Statement Stmt
-}
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/errors"
+)
 
-func (f *Func) Accept(v Visitor) Repr {
-	return v.VisitFunc(f)
+// UnsupportedError is reported when a program uses a feature
+// which is not supported by the compiler.
+//
+type UnsupportedError struct {
+	Feature string
+	ast.Range
 }
 
-type FuncType struct {
-	Params  []ValType
-	Results []ValType
+var _ errors.UserError = &UnsupportedError{}
+
+func (*UnsupportedError) IsUserError() {}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("compiler does not support %s", e.Feature)
 }
@@ -1,54 +0,0 @@
-/*
- * Cadence - The resource-oriented smart contract programming language
- *
- * Copyright 2019-2022 Dapper Labs, Inc.
- *
- * Licensed under the Apache License, Version 2.0 (the "License");
- * you may not use this file except in compliance with the License.
- * You may obtain a copy of the License at
- *
- *   http://www.apache.org/licenses/LICENSE-2.0
- *
- * Unless required by applicable law or agreed to in writing, software
- * distributed under the License is distributed on an "AS IS" BASIS,
- * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
- * See the License for the specific language governing permissions and
- * limitations under the License.
- */
-
-package ir
-
-type Repr any
-
-type ConstVisitor interface {
-	VisitInt(Int) Repr
-	VisitString(String) Repr
-}
-
-type StmtVisitor interface {
-	VisitSequence(*Sequence) Repr
-	VisitBlock(*Block) Repr
-	VisitLoop(*Loop) Repr
-	VisitIf(*If) Repr
-	VisitBranch(*Branch) Repr
-	VisitBranchIf(*BranchIf) Repr
-	VisitStoreLocal(*StoreLocal) Repr
-	VisitDrop(*Drop) Repr
-	VisitReturn(*Return) Repr
-}
-
-type ExprVisitor interface {
-	VisitConst(*Const) Repr
-	VisitCopyLocal(*CopyLocal) Repr
-	VisitMoveLocal(*MoveLocal) Repr
-	VisitUnOpExpr(*UnOpExpr) Repr
-	VisitBinOpExpr(*BinOpExpr) Repr
-	VisitCall(*Call) Repr
-}
-
-type Visitor interface {
-	ConstVisitor
-	StmtVisitor
-	ExprVisitor
-	VisitFunc(f *Func) Repr
-}
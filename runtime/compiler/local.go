@@ -18,18 +18,15 @@
 
 package compiler
 
-import (
-	"github.com/onflow/cadence/runtime/compiler/ir"
-)
-
+// Local is a local variable of a function,
+// i.e. a slot in the locals of the function's call frame
+//
 type Local struct {
-	Index uint32
-	Type  ir.ValType
+	Index uint16
 }
 
-func NewLocal(index uint32, valType ir.ValType) *Local {
+func NewLocal(index uint16) *Local {
 	return &Local{
 		Index: index,
-		Type:  valType,
 	}
 }
@@ -0,0 +1,227 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type=Opcode
+
+// Opcode is the first byte of an instruction.
+//
+// Operands follow the opcode and are encoded as big-endian 16-bit unsigned integers,
+// unless noted otherwise.
+// Jump targets are absolute offsets into the code of the function.
+//
+type Opcode byte
+
+const (
+	Unknown Opcode = iota
+
+	// Control flow
+
+	// Return returns void from the current function.
+	Return
+	// ReturnValue pops a value and returns it from the current function.
+	ReturnValue
+	// Jump jumps to the target. Operands: target
+	Jump
+	// JumpIfFalse pops a boolean and jumps to the target if it is false. Operands: target
+	JumpIfFalse
+	// JumpIfTrue pops a boolean and jumps to the target if it is true. Operands: target
+	JumpIfTrue
+	// JumpIfNil pops a value and jumps to the target if it is nil. Operands: target
+	JumpIfNil
+	// Loop marks the start of a loop iteration.
+	Loop
+	// Statement marks the start of a statement.
+	Statement
+	// Fail pops a message, or nil, and aborts with a condition error. Operands: condition kind (8-bit)
+	Fail
+	// FailForceAssignment aborts with a force assignment error.
+	FailForceAssignment
+
+	// Constants
+
+	True
+	False
+	Nil
+	Void
+	// GetConstant pushes a constant. Operands: constant index
+	GetConstant
+
+	// Variables
+
+	// GetLocal pushes a local. Operands: local index
+	GetLocal
+	// SetLocal pops a value and sets a local. Operands: local index
+	SetLocal
+	// GetUpvalue pushes a local of an enclosing function. Operands: depth, local index
+	GetUpvalue
+	// SetUpvalue pops a value and sets a local of an enclosing function. Operands: depth, local index
+	SetUpvalue
+	// GetGlobal pushes a global. Operands: global index
+	GetGlobal
+	// SetGlobal pops a value and sets a global. Operands: global index
+	SetGlobal
+	// GetBuiltin pushes a predeclared value. Operands: constant index of the name
+	GetBuiltin
+
+	// Stack
+
+	Pop
+	Dup
+
+	// Functions
+
+	// NewClosure pushes a function value for a function of the program,
+	// which captures the locals of the current function. Operands: function index
+	NewClosure
+	// Invoke pops the arguments and the function, invokes the function,
+	// and pushes the result. Operands: invocation index
+	Invoke
+	// GetMethod pops a composite value and pushes its bound method. Operands: constant index of the name
+	GetMethod
+
+	// Composites
+
+	// NewComposite pushes a new composite value. Operands: type index
+	NewComposite
+	// GetField pops a value and pushes its member. Operands: constant index of the name
+	GetField
+	// GetFieldOrNil pops a value and pushes its member, or nil if it has no such member.
+	// Operands: constant index of the name
+	GetFieldOrNil
+	// SetField pops a value and a target, and sets the member of the target.
+	// Operands: constant index of the name
+	SetField
+	// RemoveField pops a value, removes its member, and pushes it. Operands: constant index of the name
+	RemoveField
+	// Destroy pops a resource and destroys it.
+	Destroy
+	// Emit pops an event and emits it. Operands: type index
+	Emit
+
+	// Arrays and dictionaries
+
+	// NewArray pops the elements and pushes a new array. Operands: type index, element count
+	NewArray
+	// NewDictionary pops the keys and values and pushes a new dictionary. Operands: type index, entry count
+	NewDictionary
+	// GetIndex pops a key and a target, and pushes the element of the target.
+	GetIndex
+	// SetIndex pops a value, a key, and a target, and sets the element of the target.
+	SetIndex
+	// RemoveIndex pops a key and a target, removes the element of the target, and pushes it.
+	RemoveIndex
+	// InsertIndex pops a value, a key, and a target, and inserts the element into the target.
+	InsertIndex
+
+	// Optionals, references, and casting
+
+	// Some pops a value and pushes it wrapped in an optional.
+	Some
+	// EnsureOptional pops a value and pushes it wrapped in an optional, if it is not already one.
+	EnsureOptional
+	// Unwrap pops an optional and pushes its inner value.
+	Unwrap
+	// Force pops an optional and pushes its inner value, or aborts if it is nil.
+	Force
+	// NewReference pops a value and pushes a reference to it. Operands: type index of the borrow type
+	NewReference
+	// FailableCast pops a value and pushes it as an optional of the type, or nil. Operands: type index
+	FailableCast
+	// ForceCast pops a value and pushes it, or aborts if it is not of the type. Operands: type index
+	ForceCast
+	// Transfer pops a value and pushes a transferred copy.
+	Transfer
+	// TransferAndConvert pops a value and pushes a transferred copy,
+	// converted and boxed into the target type. Operands: type index of value type, type index of target type
+	TransferAndConvert
+	// Convert pops a value and pushes it converted and boxed into the target type.
+	// Operands: type index of value type, type index of target type
+	Convert
+
+	// Operators
+
+	Add
+	Subtract
+	Multiply
+	Divide
+	Mod
+	BitwiseOr
+	BitwiseXor
+	BitwiseAnd
+	BitwiseLeftShift
+	BitwiseRightShift
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
+	Equal
+	NotEqual
+	Not
+	Negate
+	// StringTemplate pops the string parts and the interpolated values,
+	// and pushes the concatenated string. Operands: interpolated value count
+	StringTemplate
+
+	// NOTE: add new opcodes above
+)
+
+// Operands returns the sizes of the operands of the opcode, in bytes.
+//
+func (o Opcode) Operands() []int {
+	switch o {
+	case Fail:
+		return operandsU8
+
+	case Jump, JumpIfFalse, JumpIfTrue, JumpIfNil,
+		GetConstant,
+		GetLocal, SetLocal,
+		GetGlobal, SetGlobal,
+		GetBuiltin,
+		NewClosure, Invoke, GetMethod,
+		NewComposite, GetField, GetFieldOrNil, SetField, RemoveField,
+		Emit,
+		NewReference, FailableCast, ForceCast,
+		StringTemplate:
+
+		return operandsU16
+
+	case GetUpvalue, SetUpvalue,
+		NewArray, NewDictionary,
+		TransferAndConvert, Convert:
+
+		return operandsU16U16
+	}
+
+	return nil
+}
+
+var operandsU8 = []int{1}
+var operandsU16 = []int{2}
+var operandsU16U16 = []int{2, 2}
+
+// Size returns the size of the instruction, in bytes.
+//
+func (o Opcode) Size() int {
+	size := 1
+	for _, operand := range o.Operands() {
+		size += operand
+	}
+	return size
+}
@@ -0,0 +1,113 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compiler
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// Program is a compiled program.
+//
+// The globals of the program are the values declared in the program, in the order of the global names,
+// followed by the imported values, in the order of the imports.
+//
+type Program struct {
+	Location    common.Location
+	Elaboration *sema.Elaboration
+	Imports     []Import
+	Globals     []string
+	// Contract is the name of the contract declared by the program, if any
+	Contract  string
+	Functions []*Function
+	// Initializer is the index of the function which initializes the globals
+	Initializer uint16
+	Constants   []Constant
+	Types       []sema.Type
+	Invocations []Invocation
+}
+
+// GlobalIndex returns the index of the global with the given name,
+// which is declared in the program.
+//
+func (p *Program) GlobalIndex(name string) (uint16, bool) {
+	for i, global := range p.Globals {
+		if global == name {
+			return uint16(i), true
+		}
+	}
+	return 0, false
+}
+
+// Import is a value imported from another program.
+//
+type Import struct {
+	Location common.Location
+	Name     string
+}
+
+// Function is a compiled function.
+//
+// The locals of the function are the receiver of methods,
+// followed by the parameters and the variables declared in the function.
+//
+type Function struct {
+	// Name is the qualified name of the function,
+	// e.g. `S.foo` for the function `foo` of the composite type `S`
+	Name           string
+	Type           *sema.FunctionType
+	ParameterCount uint16
+	LocalCount     uint16
+	// IsMethod indicates if the function has a receiver,
+	// which is passed as the first local
+	IsMethod  bool
+	Code      []byte
+	Positions []Position
+}
+
+// Position maps the instructions starting at the offset
+// to the range of the source code they were compiled from.
+//
+type Position struct {
+	Offset uint16
+	ast.Range
+}
+
+// Range returns the range of the source code
+// the instruction at the given offset was compiled from.
+//
+func (f *Function) Range(offset int) ast.Range {
+	var result ast.Range
+	for _, position := range f.Positions {
+		if int(position.Offset) > offset {
+			break
+		}
+		result = position.Range
+	}
+	return result
+}
+
+// Invocation describes the static types of an invocation,
+// which are passed to host functions.
+//
+type Invocation struct {
+	ArgumentTypes      []sema.Type
+	TypeParameterTypes *sema.TypeParameterTypeOrderedMap
+}
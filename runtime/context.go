@@ -23,12 +23,33 @@ import (
 	"github.com/onflow/cadence/runtime/common"
 )
 
+// DefaultCallStackDepthLimit is the maximum depth of the call stack
+// which is used if the context does not specify a limit.
+//
+const DefaultCallStackDepthLimit = 2000
+
 type Context struct {
 	Interface         Interface
 	Location          Location
 	PredeclaredValues []ValueDeclaration
-	codes             map[common.Location][]byte
-	programs          map[common.Location]*ast.Program
+	// CallStackDepthLimit is the maximum depth of the call stack.
+	// If zero, DefaultCallStackDepthLimit is used
+	CallStackDepthLimit uint64
+	// Profiler profiles the execution, if set.
+	// If nil, the execution is not profiled (default)
+	Profiler *Profiler
+	codes    map[common.Location][]byte
+	programs map[common.Location]*ast.Program
+}
+
+// EffectiveCallStackDepthLimit returns the call stack depth limit of the context,
+// or DefaultCallStackDepthLimit, if the context does not specify a limit.
+//
+func (c Context) EffectiveCallStackDepthLimit() uint64 {
+	if c.CallStackDepthLimit == 0 {
+		return DefaultCallStackDepthLimit
+	}
+	return c.CallStackDepthLimit
 }
 
 func (c Context) SetCode(location common.Location, code []byte) {
@@ -0,0 +1,262 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// ValidateContractUpdate performs a dry run of the update of the contract
+// with the given location from the old code to the new code,
+// without executing a transaction.
+//
+// Both the old and the new code are parsed and type-checked,
+// unless the old code has parser errors which are allowed for updates,
+// and imports are resolved using the given import resolver.
+// The import resolver may be nil if the code has no imports.
+//
+// If the update is invalid, all invalid changes are reported at once,
+// as the child errors of the returned ContractUpdateError.
+//
+func ValidateContractUpdate(
+	location Location,
+	oldCode []byte,
+	newCode []byte,
+	importResolver ImportResolver,
+) error {
+	return ValidateContractCodeUpdate(
+		location,
+		oldCode,
+		importResolver,
+		location,
+		newCode,
+		importResolver,
+	)
+}
+
+// ValidateContractCodeUpdate is like ValidateContractUpdate,
+// but parses and checks the old code at its own location, using its own import resolver,
+// e.g. when the old and the new code are read from different files.
+//
+// Parsing and checking errors of the old code are reported for the old location.
+// The new location is the location of the updated contract.
+//
+// Old code with parser errors which are allowed for updates is only parsed, and not checked,
+// like when the contract is updated in a transaction, see ignoreUpdatedProgramParserError.
+//
+func ValidateContractCodeUpdate(
+	oldLocation Location,
+	oldCode []byte,
+	oldImportResolver ImportResolver,
+	newLocation Location,
+	newCode []byte,
+	newImportResolver ImportResolver,
+) error {
+
+	// Existing contracts may have parsing errors which are allowed for updates,
+	// see ignoreUpdatedProgramParserError
+
+	oldProgram, err := parseAndCheckContractUpdateProgram(oldLocation, oldCode, oldImportResolver, true)
+	if err != nil {
+		return err
+	}
+
+	newProgram, err := parseAndCheckContractUpdateProgram(newLocation, newCode, newImportResolver, false)
+	if err != nil {
+		return err
+	}
+
+	var contractName string
+	if addressLocation, ok := newLocation.(common.AddressLocation); ok {
+		contractName = addressLocation.Name
+	}
+	if contractName == "" {
+		declaration, err := getRootDeclaration(newProgram)
+		if err == nil {
+			contractName = declaration.DeclarationIdentifier().Identifier
+		}
+	}
+
+	validator := NewContractUpdateValidator(
+		newLocation,
+		contractName,
+		oldProgram,
+		newProgram,
+	)
+	return validator.Validate()
+}
+
+func parseAndCheckContractUpdateProgram(
+	location Location,
+	code []byte,
+	importResolver ImportResolver,
+	ignoreUpdateParserError bool,
+) (*ast.Program, error) {
+
+	wrapError := func(err error) error {
+		return &ParsingCheckingError{
+			Err:      err,
+			Location: location,
+		}
+	}
+
+	program, err := parser.ParseProgram(string(code), nil)
+	if err != nil {
+		if !(ignoreUpdateParserError && ignoreUpdatedProgramParserError(err)) {
+			return nil, wrapError(err)
+		}
+
+		// The code was deployed before the parser error was reported,
+		// so it may also be invalid for the current checker.
+		// Like when the contract is updated in a transaction,
+		// it is only parsed, and not checked
+
+		return program, nil
+	}
+
+	checker, err := sema.NewChecker(
+		program,
+		location,
+		nil,
+		false,
+		contractUpdateCheckerOptions(importResolver)...,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	err = checker.Check()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return program, nil
+}
+
+// contractUpdateCheckerOptions returns the options for checking the code of a contract update.
+// The options are shared by the checkers of the imported programs.
+//
+func contractUpdateCheckerOptions(importResolver ImportResolver) []sema.Option {
+
+	semaPredeclaredValues, _ := stdlib.FlowDefaultPredeclaredValues(stdlib.FlowBuiltinImpls{})
+
+	// Imported programs are only checked once.
+	// A nil elaboration indicates the imported program is currently being checked
+
+	elaborations := map[common.Location]*sema.Elaboration{}
+
+	return []sema.Option{
+		sema.WithPredeclaredValues(semaPredeclaredValues),
+		sema.WithPredeclaredTypes(stdlib.FlowDefaultPredeclaredTypes),
+		sema.WithValidTopLevelDeclarationsHandler(validTopLevelDeclarations),
+		sema.WithLocationHandler(resolveContractUpdateLocation),
+		sema.WithImportHandler(
+			func(checker *sema.Checker, importedLocation common.Location, importRange ast.Range) (sema.Import, error) {
+
+				if importedLocation == stdlib.CryptoChecker.Location {
+					return sema.ElaborationImport{
+						Elaboration: stdlib.CryptoChecker.Elaboration,
+					}, nil
+				}
+
+				elaboration, ok := elaborations[importedLocation]
+				if ok {
+					if elaboration == nil {
+						return nil, &sema.CyclicImportsError{
+							Location: importedLocation,
+							Range:    importRange,
+						}
+					}
+
+					return sema.ElaborationImport{
+						Elaboration: elaboration,
+					}, nil
+				}
+
+				if importResolver == nil {
+					return nil, nil
+				}
+
+				importedProgram, err := importResolver(importedLocation)
+				if err != nil {
+					return nil, err
+				}
+				if importedProgram == nil {
+					return nil, nil
+				}
+
+				elaborations[importedLocation] = nil
+
+				importedChecker, err := checker.SubChecker(importedProgram, importedLocation)
+				if err != nil {
+					delete(elaborations, importedLocation)
+					return nil, err
+				}
+
+				err = importedChecker.Check()
+				if err != nil {
+					delete(elaborations, importedLocation)
+					return nil, err
+				}
+
+				elaborations[importedLocation] = importedChecker.Elaboration
+
+				return sema.ElaborationImport{
+					Elaboration: importedChecker.Elaboration,
+				}, nil
+			},
+		),
+	}
+}
+
+// resolveContractUpdateLocation resolves each identifier imported from an address
+// to the contract with the same name in the account.
+//
+func resolveContractUpdateLocation(identifiers []Identifier, location Location) ([]ResolvedLocation, error) {
+
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok || len(identifiers) == 0 {
+		return []ResolvedLocation{
+			{
+				Location:    location,
+				Identifiers: identifiers,
+			},
+		}, nil
+	}
+
+	resolvedLocations := make([]ResolvedLocation, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		resolvedLocations = append(
+			resolvedLocations,
+			ResolvedLocation{
+				Location: common.AddressLocation{
+					Address: addressLocation.Address,
+					Name:    identifier.Identifier,
+				},
+				Identifiers: []Identifier{identifier},
+			},
+		)
+	}
+
+	return resolvedLocations, nil
+}
@@ -0,0 +1,364 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+func TestRuntimeValidateContractUpdate(t *testing.T) {
+
+	t.Parallel()
+
+	location := common.AddressLocation{
+		Address: common.MustBytesToAddress([]byte{0x1}),
+		Name:    "Test",
+	}
+
+	t.Run("valid", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub var a: String
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub var a: String
+
+                pub fun test(): String {
+                    return self.a
+                }
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		err := ValidateContractUpdate(location, []byte(oldCode), []byte(newCode), nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub var a: String
+
+                pub struct S {
+                    pub var b: Int
+
+                    init() {
+                        self.b = 0
+                    }
+                }
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub var a: Int
+
+                pub struct S {
+                    pub var b: Int
+                    pub var c: Int
+
+                    init() {
+                        self.b = 0
+                        self.c = 1
+                    }
+                }
+
+                init() {
+                    self.a = 0
+                }
+            }
+        `
+
+		err := ValidateContractUpdate(location, []byte(oldCode), []byte(newCode), nil)
+		require.Error(t, err)
+
+		var updateErr *ContractUpdateError
+		require.ErrorAs(t, err, &updateErr)
+
+		assert.Equal(t, "Test", updateErr.ContractName)
+		assert.Equal(t, location, updateErr.Location)
+
+		require.Len(t, updateErr.Errors, 2)
+		assertFieldTypeMismatchError(t, updateErr.Errors[0], "Test", "a", "String", "Int")
+		assertExtraneousFieldError(t, updateErr.Errors[1], "S", "c")
+	})
+
+	t.Run("invalid new code", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {}
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub let a: Int
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		err := ValidateContractUpdate(location, []byte(oldCode), []byte(newCode), nil)
+		require.Error(t, err)
+
+		var parsingCheckingErr *ParsingCheckingError
+		require.ErrorAs(t, err, &parsingCheckingErr)
+
+		var checkerErr *sema.CheckerError
+		require.ErrorAs(t, err, &checkerErr)
+	})
+
+	t.Run("imports", func(t *testing.T) {
+
+		t.Parallel()
+
+		const importedCode = `
+            pub contract Imported {
+                pub struct S {}
+            }
+        `
+
+		const oldCode = `
+            import Imported from 0x2
+
+            pub contract Test {
+                pub var s: Imported.S
+
+                init() {
+                    self.s = Imported.S()
+                }
+            }
+        `
+
+		const newCode = `
+            import Imported from 0x2
+
+            pub contract Test {
+                pub var s: Imported.S?
+
+                init() {
+                    self.s = nil
+                }
+            }
+        `
+
+		importedLocation := common.AddressLocation{
+			Address: common.MustBytesToAddress([]byte{0x2}),
+			Name:    "Imported",
+		}
+
+		var resolvedLocations []common.Location
+
+		importResolver := func(location common.Location) (*ast.Program, error) {
+			resolvedLocations = append(resolvedLocations, location)
+
+			if location != importedLocation {
+				return nil, nil
+			}
+			return parser.ParseProgram(importedCode, nil)
+		}
+
+		err := ValidateContractUpdate(location, []byte(oldCode), []byte(newCode), importResolver)
+
+		var updateErr *ContractUpdateError
+		require.ErrorAs(t, err, &updateErr)
+		require.Len(t, updateErr.Errors, 1)
+
+		assertFieldTypeMismatchError(t, updateErr.Errors[0], "Test", "s", "Imported.S", "Imported.S?")
+
+		assert.Equal(t,
+			[]common.Location{importedLocation, importedLocation},
+			resolvedLocations,
+		)
+	})
+
+	t.Run("unresolved import", func(t *testing.T) {
+
+		t.Parallel()
+
+		const code = `
+            import Imported from 0x2
+
+            pub contract Test {}
+        `
+
+		err := ValidateContractUpdate(location, []byte(code), []byte(code), nil)
+		require.Error(t, err)
+
+		var checkerErr *sema.CheckerError
+		require.ErrorAs(t, err, &checkerErr)
+
+		require.Len(t, checkerErr.Errors, 1)
+		require.IsType(t, &sema.UnresolvedImportError{}, checkerErr.Errors[0])
+	})
+
+	t.Run("invalid old code at own location", func(t *testing.T) {
+
+		t.Parallel()
+
+		oldLocation := common.StringLocation("old")
+		newLocation := common.StringLocation("new")
+
+		const oldCode = `
+            pub contract Test {
+                pub let a: Int
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {}
+        `
+
+		err := ValidateContractCodeUpdate(
+			oldLocation,
+			[]byte(oldCode),
+			nil,
+			newLocation,
+			[]byte(newCode),
+			nil,
+		)
+		require.Error(t, err)
+
+		var parsingCheckingErr *ParsingCheckingError
+		require.ErrorAs(t, err, &parsingCheckingErr)
+		assert.Equal(t, oldLocation, parsingCheckingErr.Location)
+
+		var checkerErr *sema.CheckerError
+		require.ErrorAs(t, err, &checkerErr)
+		assert.Equal(t, oldLocation, checkerErr.Location)
+	})
+
+	t.Run("old code with ignored parser error", func(t *testing.T) {
+
+		t.Parallel()
+
+		// The old code has a missing comma in a parameter list,
+		// which is allowed for updates, and is also invalid for the current checker.
+		// It is not checked, but the update is still validated
+
+		const oldCode = `
+            pub contract Test {
+                pub let a: Int
+
+                pub fun add(a: Int b: Int): Int {
+                    return a + b
+                }
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub let a: String
+
+                pub fun add(a: Int, b: Int): Int {
+                    return a + b
+                }
+
+                init() {
+                    self.a = "hello"
+                }
+            }
+        `
+
+		err := ValidateContractUpdate(location, []byte(oldCode), []byte(newCode), nil)
+		require.Error(t, err)
+
+		var updateErr *ContractUpdateError
+		require.ErrorAs(t, err, &updateErr)
+
+		require.Len(t, updateErr.Errors, 1)
+		assertFieldTypeMismatchError(t, updateErr.Errors[0], "Test", "a", "Int", "String")
+	})
+
+	t.Run("separate import resolvers", func(t *testing.T) {
+
+		t.Parallel()
+
+		const code = `
+            import "imported"
+
+            pub contract Test {}
+        `
+
+		var resolved []string
+
+		newImportResolver := func(name string) ImportResolver {
+			return func(location Location) (*ast.Program, error) {
+				require.Equal(t, common.StringLocation("imported"), location)
+				resolved = append(resolved, name)
+				return parser.ParseProgram(
+					fmt.Sprintf("pub contract %s {}", name),
+					nil,
+				)
+			}
+		}
+
+		err := ValidateContractCodeUpdate(
+			common.StringLocation("old"),
+			[]byte(code),
+			newImportResolver("Old"),
+			common.StringLocation("new"),
+			[]byte(code),
+			newImportResolver("New"),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Old", "New"}, resolved)
+	})
+}
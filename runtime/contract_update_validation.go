@@ -69,6 +69,8 @@ func (validator *ContractUpdateValidator) Validate() error {
 	}
 
 	validator.TypeComparator.RootDeclIdentifier = newRootDecl.DeclarationIdentifier()
+	validator.TypeComparator.ExpectedTypeAliases = aliasedTypes(oldRootDecl)
+	validator.TypeComparator.FoundTypeAliases = aliasedTypes(newRootDecl)
 
 	validator.checkDeclarationUpdatability(oldRootDecl, newRootDecl)
 
@@ -140,9 +142,12 @@ func (validator *ContractUpdateValidator) checkDeclarationUpdatability(
 
 	validator.checkNestedDeclarations(oldDeclaration, newDeclaration)
 
+	validator.checkTypeAliases(oldDeclaration, newDeclaration)
+
 	if newDecl, ok := newDeclaration.(*ast.CompositeDeclaration); ok {
 		if oldDecl, ok := oldDeclaration.(*ast.CompositeDeclaration); ok {
 			validator.checkConformances(oldDecl, newDecl)
+			validator.checkTypeParameters(oldDecl, newDecl)
 		}
 	}
 }
@@ -267,6 +272,58 @@ func getNestedCompositeAndInterfaceDecls(declaration ast.Declaration) map[string
 	return compositeAndInterfaceDecls
 }
 
+// aliasedTypes returns the types aliased by the type aliases declared in the given declaration, by name.
+func aliasedTypes(declaration ast.Declaration) map[string]ast.Type {
+	typeAliasDecls := declaration.DeclarationMembers().TypeAliases()
+
+	aliasedTypes := make(map[string]ast.Type, len(typeAliasDecls))
+	for _, typeAliasDecl := range typeAliasDecls {
+		aliasedTypes[typeAliasDecl.Identifier.Identifier] = typeAliasDecl.Type
+	}
+
+	return aliasedTypes
+}
+
+// checkTypeAliases validates updating type aliases. Updated declaration must:
+//   - Have all the type aliases of the old declaration, as other programs may refer to them.
+//     Adding new type aliases is allowed.
+//   - Preserve the aliased types. A type alias may be updated to refer to an equal type,
+//     e.g. a type alias which aliases the same type.
+//
+// Fields and conformances may switch between using a type alias and the aliased type,
+// as type aliases are expanded when comparing types (see TypeComparator).
+func (validator *ContractUpdateValidator) checkTypeAliases(oldDeclaration ast.Declaration, newDeclaration ast.Declaration) {
+	newTypeAliases := map[string]*ast.TypeAliasDeclaration{}
+	for _, newTypeAlias := range newDeclaration.DeclarationMembers().TypeAliases() {
+		newTypeAliases[newTypeAlias.Identifier.Identifier] = newTypeAlias
+	}
+
+	for _, oldTypeAlias := range oldDeclaration.DeclarationMembers().TypeAliases() {
+		newTypeAlias, ok := newTypeAliases[oldTypeAlias.Identifier.Identifier]
+		if !ok {
+			validator.report(&MissingDeclarationError{
+				Name: oldTypeAlias.Identifier.Identifier,
+				Kind: oldTypeAlias.DeclarationKind(),
+				Range: ast.NewUnmeteredRangeFromPositioned(
+					newDeclaration.DeclarationIdentifier(),
+				),
+			})
+
+			continue
+		}
+
+		err := oldTypeAlias.Type.CheckEqual(newTypeAlias.Type, validator)
+		if err != nil {
+			validator.report(&TypeAliasMismatchError{
+				DeclName:  newDeclaration.DeclarationIdentifier().Identifier,
+				AliasName: newTypeAlias.Identifier.Identifier,
+				Err:       err,
+				Range:     ast.NewUnmeteredRangeFromPositioned(newTypeAlias.Type),
+			})
+		}
+	}
+}
+
 // checkEnumCases validates updating enum cases. Updated enum must:
 //   - Have at-least the same number of enum-cases as the old enum (Adding is allowed, but no removals).
 //   - Preserve the order of the old enum-cases (Adding to top/middle is not allowed, swapping is not allowed).
@@ -350,6 +407,67 @@ func (validator *ContractUpdateValidator) checkConformances(
 	}
 }
 
+// checkTypeParameters validates updating the type parameters of a generic composite declaration.
+//
+// Stored values of generic composite types encode their type arguments by position,
+// so the type parameters must not be added, removed, reordered, or renamed,
+// and their bounds must not change.
+//
+func (validator *ContractUpdateValidator) checkTypeParameters(
+	oldDecl *ast.CompositeDeclaration,
+	newDecl *ast.CompositeDeclaration,
+) {
+	var oldTypeParameters, newTypeParameters []*ast.TypeParameter
+	if oldDecl.TypeParameterList != nil {
+		oldTypeParameters = oldDecl.TypeParameterList.TypeParameters
+	}
+	if newDecl.TypeParameterList != nil {
+		newTypeParameters = newDecl.TypeParameterList.TypeParameters
+	}
+
+	report := func() {
+		var errorRange ast.Range
+		if newDecl.TypeParameterList != nil {
+			errorRange = newDecl.TypeParameterList.Range
+		} else {
+			errorRange = ast.NewUnmeteredRangeFromPositioned(newDecl.Identifier)
+		}
+
+		validator.report(&TypeParametersMismatchError{
+			DeclName: newDecl.Identifier.Identifier,
+			Range:    errorRange,
+		})
+	}
+
+	if len(oldTypeParameters) != len(newTypeParameters) {
+		report()
+		return
+	}
+
+	for i, oldTypeParameter := range oldTypeParameters {
+		newTypeParameter := newTypeParameters[i]
+
+		if oldTypeParameter.Identifier.Identifier != newTypeParameter.Identifier.Identifier {
+			report()
+			return
+		}
+
+		oldTypeBound := oldTypeParameter.TypeBound
+		newTypeBound := newTypeParameter.TypeBound
+
+		switch {
+		case oldTypeBound == nil && newTypeBound == nil:
+			continue
+
+		case oldTypeBound == nil || newTypeBound == nil,
+			oldTypeBound.Type.CheckEqual(newTypeBound.Type, validator) != nil:
+
+			report()
+			return
+		}
+	}
+}
+
 func (validator *ContractUpdateValidator) report(err error) {
 	if err == nil {
 		return
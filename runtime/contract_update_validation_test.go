@@ -1884,6 +1884,27 @@ func assertFieldTypeMismatchError(
 	assert.Equal(t, foundType, typeMismatchError.FoundType.String())
 }
 
+func assertTypeAliasMismatchError(
+	t *testing.T,
+	err error,
+	erroneousDeclName string,
+	aliasName string,
+	expectedType string,
+	foundType string,
+) {
+	var typeAliasMismatchError *TypeAliasMismatchError
+	require.ErrorAs(t, err, &typeAliasMismatchError)
+
+	assert.Equal(t, aliasName, typeAliasMismatchError.AliasName)
+	assert.Equal(t, erroneousDeclName, typeAliasMismatchError.DeclName)
+
+	var typeMismatchError *TypeMismatchError
+	assert.ErrorAs(t, typeAliasMismatchError.Err, &typeMismatchError)
+
+	assert.Equal(t, expectedType, typeMismatchError.ExpectedType.String())
+	assert.Equal(t, foundType, typeMismatchError.FoundType.String())
+}
+
 func assertConformanceMismatchError(
 	t *testing.T,
 	err error,
@@ -1895,6 +1916,17 @@ func assertConformanceMismatchError(
 	assert.Equal(t, erroneousDeclName, conformanceMismatchError.DeclName)
 }
 
+func assertTypeParametersMismatchError(
+	t *testing.T,
+	err error,
+	erroneousDeclName string,
+) {
+	var typeParametersMismatchError *TypeParametersMismatchError
+	require.ErrorAs(t, err, &typeParametersMismatchError)
+
+	assert.Equal(t, erroneousDeclName, typeParametersMismatchError.DeclName)
+}
+
 func assertEnumCaseMismatchError(t *testing.T, err error, expectedEnumCase string, foundEnumCase string) {
 	var enumMismatchError *EnumCaseMismatchError
 	require.ErrorAs(t, err, &enumMismatchError)
@@ -2245,6 +2277,339 @@ func TestRuntimeContractUpdateConformanceChanges(t *testing.T) {
 	})
 }
 
+func TestRuntimeContractUpdateTypeAliases(t *testing.T) {
+
+	t.Parallel()
+
+	const contractValidationEnabled = true
+
+	t.Run("add type alias", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {}
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Amount = UFix64
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.NoError(t, err)
+	})
+
+	t.Run("remove type alias", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub typealias Amount = UFix64
+            }
+        `
+
+		const newCode = `
+            pub contract Test {}
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		cause := getSingleContractUpdateErrorCause(t, err, "Test")
+		assertMissingDeclarationError(t, cause, "Amount")
+	})
+
+	t.Run("change aliased type", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub typealias Amount = UFix64
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Amount = UInt64
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		cause := getSingleContractUpdateErrorCause(t, err, "Test")
+		assertTypeAliasMismatchError(t, cause, "Test", "Amount", "UFix64", "UInt64")
+	})
+
+	t.Run("change aliased type to equal type", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub typealias Vault = R
+
+                pub resource R {}
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Vault = Test.R
+
+                pub resource R {}
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.NoError(t, err)
+	})
+
+	t.Run("change field type to type alias", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub var a: {String: UFix64}
+
+                init() {
+                    self.a = {}
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Amount = UFix64
+
+                pub var a: {String: Test.Amount}
+
+                init() {
+                    self.a = {}
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.NoError(t, err)
+	})
+
+	t.Run("change field type from type alias", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub typealias Vault = R
+
+                pub resource R {}
+
+                pub var vaults: @[Vault]
+
+                init() {
+                    self.vaults <- []
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Vault = R
+
+                pub resource R {}
+
+                pub var vaults: @[R]
+
+                init() {
+                    self.vaults <- []
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.NoError(t, err)
+	})
+
+	t.Run("change field type through type alias", func(t *testing.T) {
+
+		t.Parallel()
+
+		const oldCode = `
+            pub contract Test {
+                pub var a: UFix64
+
+                init() {
+                    self.a = 0.0
+                }
+            }
+        `
+
+		const newCode = `
+            pub contract Test {
+                pub typealias Amount = UInt64
+
+                pub var a: Amount
+
+                init() {
+                    self.a = 0
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		cause := getSingleContractUpdateErrorCause(t, err, "Test")
+		assertFieldTypeMismatchError(t, cause, "Test", "a", "UFix64", "UInt64")
+	})
+}
+
+func TestRuntimeContractUpdateTypeParameters(t *testing.T) {
+
+	t.Parallel()
+
+	const contractValidationEnabled = true
+
+	const oldCode = `
+        pub contract Test {
+            pub struct Box<T: Integer> {
+                pub let value: T
+
+                init(value: T) {
+                    self.value = value
+                }
+            }
+        }
+    `
+
+	t.Run("unchanged", func(t *testing.T) {
+
+		t.Parallel()
+
+		const newCode = `
+            pub contract Test {
+                pub struct Box<T: Integer> {
+                    pub let value: T
+
+                    init(value: T) {
+                        self.value = value
+                    }
+
+                    pub fun get(): T {
+                        return self.value
+                    }
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.NoError(t, err)
+	})
+
+	t.Run("add type parameter", func(t *testing.T) {
+
+		t.Parallel()
+
+		const newCode = `
+            pub contract Test {
+                pub struct Box<T: Integer, U> {
+                    pub let value: T
+
+                    init(value: T) {
+                        self.value = value
+                    }
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		cause := getSingleContractUpdateErrorCause(t, err, "Test")
+		assertTypeParametersMismatchError(t, cause, "Box")
+	})
+
+	t.Run("remove type parameters", func(t *testing.T) {
+
+		t.Parallel()
+
+		const newCode = `
+            pub contract Test {
+                pub struct Box {
+                    pub let value: Int
+
+                    init(value: Int) {
+                        self.value = value
+                    }
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		updateErr := getContractUpdateError(t, err, "Test")
+		require.Len(t, updateErr.Errors, 2)
+
+		assertFieldTypeMismatchError(t, updateErr.Errors[0], "Box", "value", "T", "Int")
+		assertTypeParametersMismatchError(t, updateErr.Errors[1], "Box")
+	})
+
+	t.Run("rename type parameter", func(t *testing.T) {
+
+		t.Parallel()
+
+		const newCode = `
+            pub contract Test {
+                pub struct Box<U: Integer> {
+                    pub let value: U
+
+                    init(value: U) {
+                        self.value = value
+                    }
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		updateErr := getContractUpdateError(t, err, "Test")
+		require.Len(t, updateErr.Errors, 2)
+
+		assertFieldTypeMismatchError(t, updateErr.Errors[0], "Box", "value", "T", "U")
+		assertTypeParametersMismatchError(t, updateErr.Errors[1], "Box")
+	})
+
+	t.Run("change type bound", func(t *testing.T) {
+
+		t.Parallel()
+
+		const newCode = `
+            pub contract Test {
+                pub struct Box<T: SignedInteger> {
+                    pub let value: T
+
+                    init(value: T) {
+                        self.value = value
+                    }
+                }
+            }
+        `
+
+		err := testDeployAndUpdate(t, contractValidationEnabled, "Test", oldCode, newCode)
+		require.Error(t, err)
+
+		cause := getSingleContractUpdateErrorCause(t, err, "Test")
+		assertTypeParametersMismatchError(t, cause, "Box")
+	})
+}
+
 func TestRuntimeContractUpdateProgramCaching(t *testing.T) {
 
 	const name = "Test"
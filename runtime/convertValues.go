@@ -759,6 +759,21 @@ func exportEvent(
 	return exported.WithType(eventType), nil
 }
 
+// ImportValue converts a Cadence value to a runtime value.
+func ImportValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	value cadence.Value,
+	expectedType sema.Type,
+) (interpreter.Value, error) {
+	return importValue(
+		inter,
+		getLocationRange,
+		value,
+		expectedType,
+	)
+}
+
 // importValue converts a Cadence value to a runtime value.
 func importValue(
 	inter *interpreter.Interpreter,
@@ -18,42 +18,745 @@
 
 package runtime
 
-import "github.com/onflow/cadence/runtime/common"
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
 
-// LocationCoverage records coverage information for a location
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// BranchKind is the kind of branching element of a branch coverage entry
+//
+type BranchKind string
+
+const (
+	BranchKindIf               BranchKind = "if"
+	BranchKindSwitch           BranchKind = "switch"
+	BranchKindConditional      BranchKind = "conditional"
+	BranchKindNilCoalescing    BranchKind = "nil-coalescing"
+	BranchKindOptionalChaining BranchKind = "optional-chaining"
+)
+
+// BranchCoverage records the hits of the branches of a branching element,
+// e.g. an if-statement or a conditional expression.
+//
+// The branches are numbered as reported by the interpreter,
+// see interpreter.OnBranchFunc.
+//
+type BranchCoverage struct {
+	Kind   BranchKind `json:"kind"`
+	Line   int        `json:"line"`
+	Column int        `json:"column"`
+	Hits   []int      `json:"hits"`
+}
+
+// Reached returns true if any of the branches was taken
+//
+func (c *BranchCoverage) Reached() bool {
+	for _, hits := range c.Hits {
+		if hits > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FunctionCoverage records the hits of a function
+//
+type FunctionCoverage struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	Hits int    `json:"hits"`
+}
+
+// LocationCoverage records coverage information for a location.
+//
+// LineHits contains an entry for each statement line of the inspected program,
+// even if the line was never hit, so the lines which are not covered can be determined.
+// Branches and functions are keyed by the source range of their element.
 //
 type LocationCoverage struct {
-	LineHits map[int]int `json:"line_hits"`
+	LineHits   map[int]int                  `json:"line_hits"`
+	Statements int                          `json:"statements"`
+	Branches   map[string]*BranchCoverage   `json:"branches,omitempty"`
+	Functions  map[string]*FunctionCoverage `json:"functions,omitempty"`
 }
 
 func (c *LocationCoverage) AddLineHit(line int) {
 	c.LineHits[line]++
 }
 
+// AddStatementLine records the given line as coverable, without hitting it
+//
+func (c *LocationCoverage) AddStatementLine(line int) {
+	if _, ok := c.LineHits[line]; ok {
+		return
+	}
+	c.LineHits[line] = 0
+	c.Statements++
+}
+
+func (c *LocationCoverage) branch(element ast.Element) *BranchCoverage {
+	key := coverageKey(element)
+	branch := c.Branches[key]
+	if branch == nil {
+		kind, count := branchKindAndCount(element)
+		position := element.StartPosition()
+		branch = &BranchCoverage{
+			Kind:   kind,
+			Line:   position.Line,
+			Column: position.Column,
+			Hits:   make([]int, count),
+		}
+		c.Branches[key] = branch
+	}
+	return branch
+}
+
+// AddBranchHit records that the given branch of the given branching element was taken
+//
+func (c *LocationCoverage) AddBranchHit(element ast.Element, branch int) {
+	hits := c.branch(element).Hits
+	if branch < 0 || branch >= len(hits) {
+		return
+	}
+	hits[branch]++
+}
+
+func (c *LocationCoverage) function(declaration ast.Element, name string) *FunctionCoverage {
+	key := coverageKey(declaration)
+	function := c.Functions[key]
+	if function == nil {
+		function = &FunctionCoverage{
+			Name: name,
+			Line: declaration.StartPosition().Line,
+		}
+		c.Functions[key] = function
+	}
+	return function
+}
+
+// AddFunctionHit records an invocation of the function
+// with the given function declaration or function expression
+//
+func (c *LocationCoverage) AddFunctionHit(declaration ast.Element) {
+	c.function(declaration, functionName(declaration, nil)).Hits++
+}
+
+// CoveredLines returns the number of statement lines which were hit
+//
+func (c *LocationCoverage) CoveredLines() int {
+	coveredLines := 0
+	for _, hits := range c.LineHits { //nolint:maprangecheck
+		if hits > 0 {
+			coveredLines++
+		}
+	}
+	return coveredLines
+}
+
+// Percentage returns the percentage of covered statement lines, e.g. "75.0%"
+//
+func (c *LocationCoverage) Percentage() string {
+	return coveragePercentage(c.CoveredLines(), len(c.LineHits))
+}
+
+// Merge adds the coverage of the given location coverage
+//
+func (c *LocationCoverage) Merge(other *LocationCoverage) {
+	for line, hits := range other.LineHits { //nolint:maprangecheck
+		c.LineHits[line] += hits
+	}
+
+	if other.Statements > c.Statements {
+		c.Statements = other.Statements
+	}
+
+	for key, otherBranch := range other.Branches { //nolint:maprangecheck
+		branch := c.Branches[key]
+		if branch == nil {
+			branch = &BranchCoverage{
+				Kind:   otherBranch.Kind,
+				Line:   otherBranch.Line,
+				Column: otherBranch.Column,
+				Hits:   make([]int, len(otherBranch.Hits)),
+			}
+			c.Branches[key] = branch
+		}
+		for i, hits := range otherBranch.Hits {
+			if i < len(branch.Hits) {
+				branch.Hits[i] += hits
+			}
+		}
+	}
+
+	for key, otherFunction := range other.Functions { //nolint:maprangecheck
+		function := c.Functions[key]
+		if function == nil {
+			function = &FunctionCoverage{
+				Name: otherFunction.Name,
+				Line: otherFunction.Line,
+			}
+			c.Functions[key] = function
+		}
+		function.Hits += otherFunction.Hits
+	}
+}
+
 func NewLocationCoverage() *LocationCoverage {
 	return &LocationCoverage{
-		LineHits: map[int]int{},
+		LineHits:  map[int]int{},
+		Branches:  map[string]*BranchCoverage{},
+		Functions: map[string]*FunctionCoverage{},
 	}
 }
 
 // CoverageReport is a collection of coverage per location
 //
 type CoverageReport struct {
-	Coverage map[common.LocationID]*LocationCoverage `json:"coverage"`
+	Coverage          map[common.LocationID]*LocationCoverage `json:"coverage"`
+	excludedLocations []*regexp.Regexp
 }
 
-func (r *CoverageReport) AddLineHit(location common.Location, line int) {
+// ExcludeLocations excludes all locations with an ID matching
+// one of the given regular expressions from the report
+//
+func (r *CoverageReport) ExcludeLocations(patterns ...string) error {
+	for _, pattern := range patterns {
+		excludedLocation, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid location exclusion pattern %q: %w", pattern, err)
+		}
+		r.excludedLocations = append(r.excludedLocations, excludedLocation)
+	}
+
+	for locationID := range r.Coverage { //nolint:maprangecheck
+		if r.isLocationIDExcluded(locationID) {
+			delete(r.Coverage, locationID)
+		}
+	}
+
+	return nil
+}
+
+// IsLocationExcluded returns true if the given location is excluded from the report
+//
+func (r *CoverageReport) IsLocationExcluded(location common.Location) bool {
+	return r.isLocationIDExcluded(location.ID())
+}
+
+func (r *CoverageReport) isLocationIDExcluded(locationID common.LocationID) bool {
+	for _, excludedLocation := range r.excludedLocations {
+		if excludedLocation.MatchString(string(locationID)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CoverageReport) locationCoverage(location common.Location) *LocationCoverage {
+	if r.IsLocationExcluded(location) {
+		return nil
+	}
+
 	locationID := location.ID()
 	locationCoverage := r.Coverage[locationID]
 	if locationCoverage == nil {
 		locationCoverage = NewLocationCoverage()
 		r.Coverage[locationID] = locationCoverage
 	}
+	return locationCoverage
+}
+
+func (r *CoverageReport) AddLineHit(location common.Location, line int) {
+	locationCoverage := r.locationCoverage(location)
+	if locationCoverage == nil {
+		return
+	}
 	locationCoverage.AddLineHit(line)
 }
 
+func (r *CoverageReport) AddBranchHit(location common.Location, element ast.Element, branch int) {
+	locationCoverage := r.locationCoverage(location)
+	if locationCoverage == nil {
+		return
+	}
+	locationCoverage.AddBranchHit(element, branch)
+}
+
+func (r *CoverageReport) AddFunctionHit(location common.Location, declaration ast.Element) {
+	locationCoverage := r.locationCoverage(location)
+	if locationCoverage == nil {
+		return
+	}
+	locationCoverage.AddFunctionHit(declaration)
+}
+
+// InspectProgram records the statement lines, branches, and functions
+// of the given program, so that they are reported even if they are never hit
+//
+func (r *CoverageReport) InspectProgram(location common.Location, program *ast.Program) {
+	locationCoverage := r.locationCoverage(location)
+	if locationCoverage == nil {
+		return
+	}
+
+	functionNames := qualifiedFunctionNames(program)
+
+	var inspect func(element ast.Element, inFunction bool)
+	inspect = func(element ast.Element, inFunction bool) {
+		if element == nil {
+			return
+		}
+
+		if _, ok := element.(ast.Statement); ok && inFunction {
+			locationCoverage.AddStatementLine(element.StartPosition().Line)
+		}
+
+		switch element := element.(type) {
+		case *ast.IfStatement,
+			*ast.SwitchStatement,
+			*ast.ConditionalExpression:
+
+			locationCoverage.branch(element)
+
+		case *ast.BinaryExpression:
+			if element.Operation == ast.OperationNilCoalesce {
+				locationCoverage.branch(element)
+			}
+
+		case *ast.MemberExpression:
+			if element.Optional {
+				locationCoverage.branch(element)
+			}
+
+		case *ast.InterfaceDeclaration:
+			// Interfaces declare no code which is executed
+			return
+
+		case *ast.SpecialFunctionDeclaration:
+			// The function declaration of a special function is not walked as a child,
+			// only its function block is
+			inspect(element.FunctionDeclaration, inFunction)
+			return
+
+		case *ast.FunctionDeclaration:
+			if element.FunctionBlock == nil {
+				break
+			}
+			locationCoverage.function(element, functionNames[element])
+
+		case *ast.FunctionExpression:
+			locationCoverage.function(element, functionNames[element])
+
+		case *ast.FunctionBlock:
+			inFunction = true
+		}
+
+		element.Walk(func(child ast.Element) {
+			inspect(child, inFunction)
+		})
+	}
+
+	inspect(program, false)
+}
+
+// Merge adds the coverage of the given report
+//
+func (r *CoverageReport) Merge(other *CoverageReport) {
+	for locationID, otherLocationCoverage := range other.Coverage { //nolint:maprangecheck
+		if r.isLocationIDExcluded(locationID) {
+			continue
+		}
+
+		locationCoverage := r.Coverage[locationID]
+		if locationCoverage == nil {
+			locationCoverage = NewLocationCoverage()
+			r.Coverage[locationID] = locationCoverage
+		}
+		locationCoverage.Merge(otherLocationCoverage)
+	}
+}
+
+// CoveredLines returns the number of statement lines which were hit, over all locations
+//
+func (r *CoverageReport) CoveredLines() int {
+	coveredLines := 0
+	for _, locationCoverage := range r.Coverage { //nolint:maprangecheck
+		coveredLines += locationCoverage.CoveredLines()
+	}
+	return coveredLines
+}
+
+// TotalLines returns the number of statement lines, over all locations
+//
+func (r *CoverageReport) TotalLines() int {
+	totalLines := 0
+	for _, locationCoverage := range r.Coverage { //nolint:maprangecheck
+		totalLines += len(locationCoverage.LineHits)
+	}
+	return totalLines
+}
+
+// Percentage returns the percentage of covered statement lines over all locations, e.g. "75.0%"
+//
+func (r *CoverageReport) Percentage() string {
+	return coveragePercentage(r.CoveredLines(), r.TotalLines())
+}
+
+func (r *CoverageReport) sortedLocationIDs() []common.LocationID {
+	locationIDs := make([]common.LocationID, 0, len(r.Coverage))
+	for locationID := range r.Coverage { //nolint:maprangecheck
+		locationIDs = append(locationIDs, locationID)
+	}
+	sort.Slice(locationIDs, func(i, j int) bool {
+		return locationIDs[i] < locationIDs[j]
+	})
+	return locationIDs
+}
+
+// WriteLCOV writes the report in the LCOV tracefile format
+//
+func (r *CoverageReport) WriteLCOV(w io.Writer) error {
+	var builder strings.Builder
+
+	for _, locationID := range r.sortedLocationIDs() {
+		locationCoverage := r.Coverage[locationID]
+
+		builder.WriteString("TN:\n")
+		fmt.Fprintf(&builder, "SF:%s\n", coverageSourceName(locationID))
+
+		functions := locationCoverage.sortedFunctions()
+		functionsHit := 0
+		for _, function := range functions {
+			fmt.Fprintf(&builder, "FN:%d,%s\n", function.Line, function.Name)
+		}
+		for _, function := range functions {
+			fmt.Fprintf(&builder, "FNDA:%d,%s\n", function.Hits, function.Name)
+			if function.Hits > 0 {
+				functionsHit++
+			}
+		}
+		fmt.Fprintf(&builder, "FNF:%d\n", len(functions))
+		fmt.Fprintf(&builder, "FNH:%d\n", functionsHit)
+
+		branches := locationCoverage.sortedBranches()
+		branchCount := 0
+		branchesHit := 0
+		for blockNumber, branch := range branches {
+			reached := branch.Reached()
+			for branchNumber, hits := range branch.Hits {
+				taken := "-"
+				if reached {
+					taken = fmt.Sprint(hits)
+				}
+				fmt.Fprintf(&builder, "BRDA:%d,%d,%d,%s\n", branch.Line, blockNumber, branchNumber, taken)
+				branchCount++
+				if hits > 0 {
+					branchesHit++
+				}
+			}
+		}
+		fmt.Fprintf(&builder, "BRF:%d\n", branchCount)
+		fmt.Fprintf(&builder, "BRH:%d\n", branchesHit)
+
+		lines := locationCoverage.sortedLines()
+		for _, line := range lines {
+			fmt.Fprintf(&builder, "DA:%d,%d\n", line, locationCoverage.LineHits[line])
+		}
+		fmt.Fprintf(&builder, "LF:%d\n", len(lines))
+		fmt.Fprintf(&builder, "LH:%d\n", locationCoverage.CoveredLines())
+
+		builder.WriteString("end_of_record\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int    `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// WriteCobertura writes the report in the Cobertura XML format.
+// Each location is reported as a class of a single package
+//
+func (r *CoverageReport) WriteCobertura(w io.Writer) error {
+
+	coverage := coberturaCoverage{
+		Version: "1.9",
+	}
+
+	pkg := coberturaPackage{
+		Name: "cadence",
+	}
+
+	for _, locationID := range r.sortedLocationIDs() {
+		locationCoverage := r.Coverage[locationID]
+
+		// Aggregate the branches by line
+
+		branchesValidByLine := map[int]int{}
+		branchesCoveredByLine := map[int]int{}
+
+		for _, branch := range locationCoverage.sortedBranches() {
+			for _, hits := range branch.Hits {
+				branchesValidByLine[branch.Line]++
+				if hits > 0 {
+					branchesCoveredByLine[branch.Line]++
+				}
+			}
+		}
+
+		lines := locationCoverage.sortedLines()
+		linesCovered := locationCoverage.CoveredLines()
+		branchesValid := 0
+		branchesCovered := 0
+
+		class := coberturaClass{
+			Name:     string(locationID),
+			Filename: coverageSourceName(locationID),
+		}
+
+		for _, line := range lines {
+			coberturaLine := coberturaLine{
+				Number: line,
+				Hits:   locationCoverage.LineHits[line],
+			}
+
+			lineBranchesValid := branchesValidByLine[line]
+			if lineBranchesValid > 0 {
+				lineBranchesCovered := branchesCoveredByLine[line]
+				coberturaLine.Branch = true
+				coberturaLine.ConditionCoverage = fmt.Sprintf(
+					"%.0f%% (%d/%d)",
+					coverageRate(lineBranchesCovered, lineBranchesValid)*100,
+					lineBranchesCovered,
+					lineBranchesValid,
+				)
+				branchesValid += lineBranchesValid
+				branchesCovered += lineBranchesCovered
+			}
+
+			class.Lines = append(class.Lines, coberturaLine)
+		}
+
+		for _, function := range locationCoverage.sortedFunctions() {
+			var functionLineRate float64
+			if function.Hits > 0 {
+				functionLineRate = 1
+			}
+			class.Methods = append(class.Methods, coberturaMethod{
+				Name:     function.Name,
+				LineRate: functionLineRate,
+				Lines: []coberturaLine{
+					{
+						Number: function.Line,
+						Hits:   function.Hits,
+					},
+				},
+			})
+		}
+
+		class.LineRate = coverageRate(linesCovered, len(lines))
+		class.BranchRate = coverageRate(branchesCovered, branchesValid)
+
+		pkg.Classes = append(pkg.Classes, class)
+
+		coverage.LinesCovered += linesCovered
+		coverage.LinesValid += len(lines)
+		coverage.BranchesCovered += branchesCovered
+		coverage.BranchesValid += branchesValid
+	}
+
+	coverage.LineRate = coverageRate(coverage.LinesCovered, coverage.LinesValid)
+	coverage.BranchRate = coverageRate(coverage.BranchesCovered, coverage.BranchesValid)
+
+	pkg.LineRate = coverage.LineRate
+	pkg.BranchRate = coverage.BranchRate
+
+	coverage.Packages = []coberturaPackage{pkg}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(coverage)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func (c *LocationCoverage) sortedLines() []int {
+	lines := make([]int, 0, len(c.LineHits))
+	for line := range c.LineHits { //nolint:maprangecheck
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func (c *LocationCoverage) sortedBranches() []*BranchCoverage {
+	branches := make([]*BranchCoverage, 0, len(c.Branches))
+	for _, branch := range c.Branches { //nolint:maprangecheck
+		branches = append(branches, branch)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		a := branches[i]
+		b := branches[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Kind < b.Kind
+	})
+	return branches
+}
+
+func (c *LocationCoverage) sortedFunctions() []*FunctionCoverage {
+	functions := make([]*FunctionCoverage, 0, len(c.Functions))
+	for _, function := range c.Functions { //nolint:maprangecheck
+		functions = append(functions, function)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		a := functions[i]
+		b := functions[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Name < b.Name
+	})
+	return functions
+}
+
 func NewCoverageReport() *CoverageReport {
 	return &CoverageReport{
 		Coverage: map[common.LocationID]*LocationCoverage{},
 	}
 }
+
+// coverageKey returns the key of a branch or function coverage entry,
+// the start and end offset of the element.
+//
+// Branching elements may start at the same position,
+// e.g. the nil-coalescing expression `a?.b ?? c` and its left-hand side,
+// but they never have the same range.
+//
+func coverageKey(element ast.Element) string {
+	return fmt.Sprintf(
+		"%d-%d",
+		element.StartPosition().Offset,
+		element.EndPosition(nil).Offset,
+	)
+}
+
+func branchKindAndCount(element ast.Element) (BranchKind, int) {
+	switch element := element.(type) {
+	case *ast.IfStatement:
+		return BranchKindIf, 2
+
+	case *ast.SwitchStatement:
+		count := len(element.Cases)
+		hasDefault := false
+		for _, switchCase := range element.Cases {
+			if switchCase.Expression == nil {
+				hasDefault = true
+				break
+			}
+		}
+		if !hasDefault {
+			// The implicit branch when no case matches
+			count++
+		}
+		return BranchKindSwitch, count
+
+	case *ast.ConditionalExpression:
+		return BranchKindConditional, 2
+
+	case *ast.BinaryExpression:
+		return BranchKindNilCoalescing, 2
+
+	case *ast.MemberExpression:
+		return BranchKindOptionalChaining, 2
+	}
+
+	panic(errors.NewUnreachableError())
+}
+
+// coverageSourceName returns the source file name reported for the given location.
+// String locations are assumed to be file paths
+//
+func coverageSourceName(locationID common.LocationID) string {
+	return strings.TrimPrefix(
+		string(locationID),
+		common.StringLocationPrefix+".",
+	)
+}
+
+func coverageRate(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total)
+}
+
+func coveragePercentage(covered, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%0.1f%%", coverageRate(covered, total)*100)
+}
@@ -21,12 +21,14 @@ package runtime
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime/common"
 )
 
@@ -101,13 +103,38 @@ func TestRuntimeCoverage(t *testing.T) {
                 "4": 1,
                 "5": 42,
                 "7": 1
+              },
+              "statements": 4,
+              "functions": {
+                "7-125": {
+                  "name": "answer",
+                  "line": 2,
+                  "hits": 1
+                }
               }
             },
             "t.0000000000000000000000000000000000000000000000000000000000000000": {
               "line_hits": {
                 "5": 1,
                 "6": 1,
+                "7": 0,
                 "9": 1
+              },
+              "statements": 4,
+              "branches": {
+                "96-148": {
+                  "kind": "if",
+                  "line": 6,
+                  "column": 10,
+                  "hits": [0, 1]
+                }
+              },
+              "functions": {
+                "32-182": {
+                  "name": "main",
+                  "line": 4,
+                  "hits": 1
+                }
               }
             }
           }
@@ -116,3 +143,290 @@ func TestRuntimeCoverage(t *testing.T) {
 		string(actual),
 	)
 }
+
+func executeCoverageTestScript(t *testing.T, coverageReport *CoverageReport, script string) cadence.Value {
+	runtime := newTestInterpreterRuntime()
+	runtime.SetCoverageReport(coverageReport)
+
+	value, err := runtime.ExecuteScript(
+		Script{
+			Source: []byte(script),
+		},
+		Context{
+			Interface: &testRuntimeInterface{},
+			Location:  common.StringLocation("test"),
+		},
+	)
+	require.NoError(t, err)
+
+	return value
+}
+
+func TestRuntimeCoverageBranches(t *testing.T) {
+
+	t.Parallel()
+
+	coverageReport := NewCoverageReport()
+
+	value := executeCoverageTestScript(t, coverageReport, `
+      pub fun classify(_ x: Int?): Int {
+          switch x ?? 0 {
+          case 1:
+              return 1
+          case 2:
+              return 2
+          }
+          return x != nil ? 3 : 4
+      }
+
+      pub struct S {
+          pub let n: Int
+          init() {
+              self.n = 1
+          }
+      }
+
+      pub fun main(): Int {
+          let s: S? = nil
+          let n = s?.n
+          return classify(1) + classify(nil)
+      }
+    `)
+
+	assert.Equal(t, cadence.NewInt(5), value)
+
+	locationCoverage := coverageReport.Coverage[common.StringLocation("test").ID()]
+	require.NotNil(t, locationCoverage)
+
+	branches := locationCoverage.sortedBranches()
+
+	assert.Equal(t,
+		[]*BranchCoverage{
+			{Kind: BranchKindSwitch, Line: 3, Column: 10, Hits: []int{1, 0, 1}},
+			{Kind: BranchKindNilCoalescing, Line: 3, Column: 17, Hits: []int{1, 1}},
+			{Kind: BranchKindConditional, Line: 9, Column: 17, Hits: []int{0, 1}},
+			{Kind: BranchKindOptionalChaining, Line: 21, Column: 18, Hits: []int{0, 1}},
+		},
+		branches,
+	)
+}
+
+func TestRuntimeCoverageFunctions(t *testing.T) {
+
+	t.Parallel()
+
+	coverageReport := NewCoverageReport()
+
+	executeCoverageTestScript(t, coverageReport, `
+      pub struct S {
+          init() {}
+
+          pub fun foo() {}
+
+          pub fun bar() {}
+      }
+
+      pub fun main() {
+          let s = S()
+          s.foo()
+          s.foo()
+          let f = fun () {}
+          f()
+      }
+    `)
+
+	locationCoverage := coverageReport.Coverage[common.StringLocation("test").ID()]
+	require.NotNil(t, locationCoverage)
+
+	assert.Equal(t,
+		[]*FunctionCoverage{
+			{Name: "S.init", Line: 3, Hits: 1},
+			{Name: "S.foo", Line: 5, Hits: 2},
+			{Name: "S.bar", Line: 7, Hits: 0},
+			{Name: "main", Line: 10, Hits: 1},
+			{Name: "main.<anonymous 14:18>", Line: 14, Hits: 1},
+		},
+		locationCoverage.sortedFunctions(),
+	)
+
+	assert.Equal(t, 5, locationCoverage.Statements)
+	assert.Equal(t, 5, locationCoverage.CoveredLines())
+	assert.Equal(t, "100.0%", locationCoverage.Percentage())
+}
+
+func TestCoverageReportExcludeLocations(t *testing.T) {
+
+	t.Parallel()
+
+	coverageReport := NewCoverageReport()
+	coverageReport.AddLineHit(common.StringLocation("test"), 1)
+	coverageReport.AddLineHit(common.StringLocation("other"), 1)
+
+	err := coverageReport.ExcludeLocations(`^S\.test$`)
+	require.NoError(t, err)
+
+	coverageReport.AddLineHit(common.StringLocation("test"), 2)
+	coverageReport.AddLineHit(common.StringLocation("other"), 2)
+
+	assert.Equal(t,
+		map[common.LocationID]*LocationCoverage{
+			common.StringLocation("other").ID(): {
+				LineHits:  map[int]int{1: 1, 2: 1},
+				Branches:  map[string]*BranchCoverage{},
+				Functions: map[string]*FunctionCoverage{},
+			},
+		},
+		coverageReport.Coverage,
+	)
+
+	err = coverageReport.ExcludeLocations(`(`)
+	require.Error(t, err)
+}
+
+const coverageTestScript = `
+  pub fun main(_ x: Int): Int {
+      if x > 0 {
+          return 1
+      }
+      return 2
+  }
+`
+
+func executeCoverageTestScriptWithArgument(t *testing.T, coverageReport *CoverageReport, argument int) {
+	runtime := newTestInterpreterRuntime()
+	runtime.SetCoverageReport(coverageReport)
+
+	argumentValue, err := json.Marshal(map[string]string{
+		"type":  "Int",
+		"value": fmt.Sprint(argument),
+	})
+	require.NoError(t, err)
+
+	_, err = runtime.ExecuteScript(
+		Script{
+			Source:    []byte(coverageTestScript),
+			Arguments: [][]byte{argumentValue},
+		},
+		Context{
+			Interface: &testRuntimeInterface{
+				decodeArgument: func(b []byte, t cadence.Type) (cadence.Value, error) {
+					return jsoncdc.Decode(nil, b)
+				},
+			},
+			Location: common.StringLocation("test.cdc"),
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestCoverageReportMerge(t *testing.T) {
+
+	t.Parallel()
+
+	positiveReport := NewCoverageReport()
+	executeCoverageTestScriptWithArgument(t, positiveReport, 1)
+
+	negativeReport := NewCoverageReport()
+	executeCoverageTestScriptWithArgument(t, negativeReport, -1)
+
+	locationID := common.StringLocation("test.cdc").ID()
+
+	assert.Equal(t, "66.7%", positiveReport.Percentage())
+	assert.Equal(t, "66.7%", negativeReport.Percentage())
+
+	mergedReport := NewCoverageReport()
+	mergedReport.Merge(positiveReport)
+	mergedReport.Merge(negativeReport)
+
+	locationCoverage := mergedReport.Coverage[locationID]
+	require.NotNil(t, locationCoverage)
+
+	assert.Equal(t, map[int]int{3: 2, 4: 1, 6: 1}, locationCoverage.LineHits)
+	assert.Equal(t, 3, locationCoverage.Statements)
+	assert.Equal(t, "100.0%", mergedReport.Percentage())
+
+	branches := locationCoverage.sortedBranches()
+	require.Len(t, branches, 1)
+	assert.Equal(t, []int{1, 1}, branches[0].Hits)
+
+	functions := locationCoverage.sortedFunctions()
+	require.Len(t, functions, 1)
+	assert.Equal(t, 2, functions[0].Hits)
+
+	// Merging must not modify the merged reports
+
+	assert.Equal(t, map[int]int{3: 1, 4: 1, 6: 0}, positiveReport.Coverage[locationID].LineHits)
+}
+
+func TestCoverageReportLCOV(t *testing.T) {
+
+	t.Parallel()
+
+	coverageReport := NewCoverageReport()
+	executeCoverageTestScriptWithArgument(t, coverageReport, 1)
+
+	var builder strings.Builder
+	err := coverageReport.WriteLCOV(&builder)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`TN:
+SF:test.cdc
+FN:2,main
+FNDA:1,main
+FNF:1
+FNH:1
+BRDA:3,0,0,1
+BRDA:3,0,1,0
+BRF:2
+BRH:1
+DA:3,1
+DA:4,1
+DA:6,0
+LF:3
+LH:2
+end_of_record
+`,
+		builder.String(),
+	)
+}
+
+func TestCoverageReportCobertura(t *testing.T) {
+
+	t.Parallel()
+
+	coverageReport := NewCoverageReport()
+	executeCoverageTestScriptWithArgument(t, coverageReport, 1)
+
+	var builder strings.Builder
+	err := coverageReport.WriteCobertura(&builder)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<coverage line-rate="0.6666666666666666" branch-rate="0.5" lines-covered="2" lines-valid="3" branches-covered="1" branches-valid="2" version="1.9" timestamp="0">
+  <packages>
+    <package name="cadence" line-rate="0.6666666666666666" branch-rate="0.5">
+      <classes>
+        <class name="S.test.cdc" filename="test.cdc" line-rate="0.6666666666666666" branch-rate="0.5">
+          <methods>
+            <method name="main" signature="" line-rate="1" branch-rate="0">
+              <lines>
+                <line number="2" hits="1" branch="false"></line>
+              </lines>
+            </method>
+          </methods>
+          <lines>
+            <line number="3" hits="1" branch="true" condition-coverage="50% (1/2)"></line>
+            <line number="4" hits="1" branch="false"></line>
+            <line number="6" hits="0" branch="false"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`,
+		builder.String(),
+	)
+}
@@ -24,9 +24,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
 )
 
 func TestRuntimeDebugger(t *testing.T) {
@@ -203,3 +205,253 @@ func TestRuntimeDebuggerBreakpoints(t *testing.T) {
 
 	require.True(t, logged)
 }
+
+const debuggerTestScript = `
+pub fun add(_ a: Int, _ b: Int): Int {
+    let sum = a + b
+    return sum
+}
+
+pub fun main(): Int {
+    var total = 0
+    var i = 0
+    while i < 3 {
+        total = add(total, i)
+        i = i + 1
+    }
+    return total
+}
+`
+
+// runDebuggerTestScript runs the debugger test script in a goroutine,
+// as it will pause/block on stops.
+// The returned wait group is done when the script finished execution
+//
+func runDebuggerTestScript(
+	t *testing.T,
+	debugger *interpreter.Debugger,
+	location common.Location,
+) *sync.WaitGroup {
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		runtime := newTestInterpreterRuntime()
+		runtime.SetDebugger(debugger)
+
+		runtimeInterface := &testRuntimeInterface{
+			storage: newTestLedger(nil, nil),
+		}
+
+		result, err := runtime.ExecuteScript(
+			Script{
+				Source: []byte(debuggerTestScript),
+			},
+			Context{
+				Interface: runtimeInterface,
+				Location:  location,
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, cadence.NewInt(3), result)
+	}()
+
+	return &wg
+}
+
+func debuggerStopLine(stop interpreter.Stop) int {
+	return stop.Statement.StartPosition().Line
+}
+
+func debuggerStopVariable(t *testing.T, stop interpreter.Stop, name string) interpreter.Value {
+	variable := stop.CallStack[len(stop.CallStack)-1].Activation.Find(name)
+	require.NotNil(t, variable)
+	return variable.GetValue()
+}
+
+func TestRuntimeDebuggerStepping(t *testing.T) {
+
+	t.Parallel()
+
+	location := common.ScriptLocation{0x1}
+
+	debugger := interpreter.NewDebugger()
+	debugger.AddBreakpoint(location, 11)
+
+	wg := runDebuggerTestScript(t, debugger, location)
+
+	// Stop at `total = add(total, i)`
+	stop := <-debugger.Stops()
+	require.Equal(t, 11, debuggerStopLine(stop))
+	require.Len(t, stop.CallStack, 1)
+	require.Equal(t, "main", stop.CallStack[0].FunctionName)
+
+	// Step into `add`
+	stop = debugger.StepIn()
+	require.Equal(t, 3, debuggerStopLine(stop))
+	require.Len(t, stop.CallStack, 2)
+	require.Equal(t, "main", stop.CallStack[0].FunctionName)
+	require.Equal(t, 11, stop.CallStack[0].Statement.StartPosition().Line)
+	require.Equal(t, "add", stop.CallStack[1].FunctionName)
+
+	// The caller's frame has its own activation
+	require.Nil(t, stop.CallStack[1].Activation.Find("total"))
+	require.NotNil(t, stop.CallStack[0].Activation.Find("total"))
+
+	// Step over `let sum = a + b`
+	stop = debugger.StepOver()
+	require.Equal(t, 4, debuggerStopLine(stop))
+	require.Equal(
+		t,
+		interpreter.NewUnmeteredIntValueFromInt64(0),
+		debuggerStopVariable(t, stop, "sum"),
+	)
+
+	// Step out of `add`
+	stop = debugger.StepOut()
+	require.Equal(t, 12, debuggerStopLine(stop))
+	require.Len(t, stop.CallStack, 1)
+
+	// Step over `i = i + 1`, back to the loop
+	stop = debugger.StepOver()
+	require.Equal(t, 11, debuggerStopLine(stop))
+
+	// Step over the invocation of `add`
+	stop = debugger.StepOver()
+	require.Equal(t, 12, debuggerStopLine(stop))
+	require.Len(t, stop.CallStack, 1)
+
+	debugger.ClearBreakpoints()
+	debugger.Continue()
+
+	wg.Wait()
+}
+
+func TestRuntimeDebuggerConditionalBreakpoints(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("condition", func(t *testing.T) {
+
+		t.Parallel()
+
+		location := common.ScriptLocation{0x2}
+
+		debugger := interpreter.NewDebugger()
+		condition, errs := parser.ParseExpression("b == 2", nil)
+		require.Empty(t, errs)
+
+		debugger.AddConditionalBreakpoint(
+			location,
+			3,
+			interpreter.BreakpointConditions{
+				Condition: condition,
+			},
+		)
+
+		wg := runDebuggerTestScript(t, debugger, location)
+
+		stop := <-debugger.Stops()
+		require.Equal(t, 3, debuggerStopLine(stop))
+		require.Equal(
+			t,
+			interpreter.NewUnmeteredIntValueFromInt64(2),
+			debuggerStopVariable(t, stop, "b"),
+		)
+
+		debugger.Continue()
+
+		wg.Wait()
+	})
+
+	t.Run("hit count", func(t *testing.T) {
+
+		t.Parallel()
+
+		location := common.ScriptLocation{0x3}
+
+		debugger := interpreter.NewDebugger()
+		debugger.AddConditionalBreakpoint(
+			location,
+			3,
+			interpreter.BreakpointConditions{
+				HitCount: 2,
+			},
+		)
+
+		wg := runDebuggerTestScript(t, debugger, location)
+
+		for _, expected := range []int64{1, 2} {
+			stop := <-debugger.Stops()
+			require.Equal(t, 3, debuggerStopLine(stop))
+			require.Equal(
+				t,
+				interpreter.NewUnmeteredIntValueFromInt64(expected),
+				debuggerStopVariable(t, stop, "b"),
+			)
+
+			debugger.Continue()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("ill-typed condition", func(t *testing.T) {
+
+		t.Parallel()
+
+		location := common.ScriptLocation{0x4}
+
+		debugger := interpreter.NewDebugger()
+
+		condition, errs := parser.ParseExpression("b + 1", nil)
+		require.Empty(t, errs)
+
+		debugger.AddConditionalBreakpoint(
+			location,
+			3,
+			interpreter.BreakpointConditions{
+				Condition: condition,
+			},
+		)
+
+		wg := runDebuggerTestScript(t, debugger, location)
+
+		// Conditions which cannot be checked always stop
+		stop := <-debugger.Stops()
+		require.Equal(
+			t,
+			interpreter.NewUnmeteredIntValueFromInt64(0),
+			debuggerStopVariable(t, stop, "b"),
+		)
+
+		debugger.ClearBreakpoints()
+		debugger.Continue()
+
+		wg.Wait()
+	})}
+
+func TestRuntimeDebuggerFunctionBreakpoints(t *testing.T) {
+
+	t.Parallel()
+
+	location := common.ScriptLocation{0x5}
+
+	debugger := interpreter.NewDebugger()
+	debugger.AddFunctionBreakpoint(location, "add")
+
+	wg := runDebuggerTestScript(t, debugger, location)
+
+	stop := <-debugger.Stops()
+	require.Equal(t, 3, debuggerStopLine(stop))
+	require.Len(t, stop.CallStack, 2)
+	require.Equal(t, "add", stop.CallStack[1].FunctionName)
+
+	debugger.RemoveFunctionBreakpoint(location, "add")
+	debugger.Continue()
+
+	wg.Wait()
+}
@@ -71,20 +71,7 @@ func (e Error) Error() string {
 
 // CallStackLimitExceededError
 
-type CallStackLimitExceededError struct {
-	Limit uint64
-}
-
-var _ errors.UserError = CallStackLimitExceededError{}
-
-func (CallStackLimitExceededError) IsUserError() {}
-
-func (e CallStackLimitExceededError) Error() string {
-	return fmt.Sprintf(
-		"call stack limit exceeded: %d",
-		e.Limit,
-	)
-}
+type CallStackLimitExceededError = interpreter.CallStackLimitExceededError
 
 // InvalidTransactionCountError
 
@@ -423,6 +410,31 @@ func (e *FieldMismatchError) SecondaryError() string {
 	return e.Err.Error()
 }
 
+// TypeAliasMismatchError is reported during a contract update, when the type aliased
+// by a type alias does not match the existing aliased type of the same type alias.
+type TypeAliasMismatchError struct {
+	DeclName  string
+	AliasName string
+	Err       error
+	ast.Range
+}
+
+var _ errors.UserError = &TypeAliasMismatchError{}
+var _ errors.SecondaryError = &TypeAliasMismatchError{}
+
+func (*TypeAliasMismatchError) IsUserError() {}
+
+func (e *TypeAliasMismatchError) Error() string {
+	return fmt.Sprintf("mismatching type alias `%s` in `%s`",
+		e.AliasName,
+		e.DeclName,
+	)
+}
+
+func (e *TypeAliasMismatchError) SecondaryError() string {
+	return e.Err.Error()
+}
+
 // TypeMismatchError is reported during a contract update, when a type of the new program
 // does not match the existing type.
 type TypeMismatchError struct {
@@ -507,6 +519,21 @@ func (e *ConformanceMismatchError) Error() string {
 	return fmt.Sprintf("conformances does not match in `%s`", e.DeclName)
 }
 
+// TypeParametersMismatchError is reported during a contract update, when the type parameters
+// of a generic composite declaration of the new program do not match the existing ones.
+type TypeParametersMismatchError struct {
+	DeclName string
+	ast.Range
+}
+
+var _ errors.UserError = &TypeParametersMismatchError{}
+
+func (*TypeParametersMismatchError) IsUserError() {}
+
+func (e *TypeParametersMismatchError) Error() string {
+	return fmt.Sprintf("type parameters does not match in `%s`", e.DeclName)
+}
+
 // EnumCaseMismatchError is reported during an enum update, when an updated enum case
 // does not match the existing enum case.
 type EnumCaseMismatchError struct {
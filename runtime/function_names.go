@@ -0,0 +1,108 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// qualifiedFunctionNames returns the names of all functions of the given program,
+// by their function declaration or function expression.
+//
+// Functions are qualified by the names of the enclosing declarations, e.g. `C.R.foo`,
+// function expressions are named by their position, e.g. `main.<anonymous 3:16>`.
+//
+func qualifiedFunctionNames(program *ast.Program) map[ast.Element]string {
+	names := map[ast.Element]string{}
+
+	var enclosingNames []string
+
+	var walk func(element ast.Element)
+	walk = func(element ast.Element) {
+		if element == nil {
+			return
+		}
+
+		switch element := element.(type) {
+		case *ast.CompositeDeclaration:
+			enclosingNames = append(enclosingNames, element.Identifier.Identifier)
+			defer func() {
+				enclosingNames = enclosingNames[:len(enclosingNames)-1]
+			}()
+
+		case *ast.InterfaceDeclaration:
+			// Interfaces declare no functions which can be invoked
+			return
+
+		case *ast.SpecialFunctionDeclaration:
+			// The function declaration of a special function is not walked as a child,
+			// only its function block is
+			walk(element.FunctionDeclaration)
+			return
+
+		case *ast.FunctionDeclaration:
+			if element.FunctionBlock == nil {
+				return
+			}
+			names[element] = functionName(element, enclosingNames)
+			enclosingNames = append(enclosingNames, element.Identifier.Identifier)
+			defer func() {
+				enclosingNames = enclosingNames[:len(enclosingNames)-1]
+			}()
+
+		case *ast.FunctionExpression:
+			names[element] = functionName(element, enclosingNames)
+		}
+
+		element.Walk(walk)
+	}
+
+	walk(program)
+
+	return names
+}
+
+// functionName returns the name of the function
+// with the given function declaration or function expression,
+// qualified by the names of the enclosing declarations
+//
+func functionName(declaration ast.Element, enclosingNames []string) string {
+	var name string
+	switch declaration := declaration.(type) {
+	case *ast.FunctionDeclaration:
+		name = declaration.Identifier.Identifier
+
+	case *ast.FunctionExpression:
+		position := declaration.StartPosition()
+		name = fmt.Sprintf("<anonymous %d:%d>", position.Line, position.Column)
+
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	if len(enclosingNames) == 0 {
+		return name
+	}
+
+	return strings.Join(enclosingNames, ".") + "." + name
+}
@@ -63,6 +63,11 @@ type Interface interface {
 	//
 	GetProgram(Location) (*interpreter.Program, error)
 	// SetProgram sets the program for the given location.
+	//
+	// Implementations may persist the program across restarts
+	// by encoding it with interpreter.EncodeProgram,
+	// and loading it with interpreter.DecodeProgram.
+	//
 	SetProgram(Location, *interpreter.Program) error
 	// GetValue gets a value for the given key in the storage, owned by the given account.
 	GetValue(owner, key []byte) (value []byte, err error)
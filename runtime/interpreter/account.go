@@ -113,6 +113,36 @@ func NewAuthAccountValue(
 		sema.AuthAccountGetLinkTargetField: func(inter *Interpreter, _ func() LocationRange) Value {
 			return inter.accountGetLinkTargetFunction(address)
 		},
+		sema.AuthAccountStoragePathsField: func(inter *Interpreter, getLocationRange func() LocationRange) Value {
+			return inter.accountPathsField(address, common.PathDomainStorage, getLocationRange)
+		},
+		sema.AuthAccountPublicPathsField: func(inter *Interpreter, getLocationRange func() LocationRange) Value {
+			return inter.accountPathsField(address, common.PathDomainPublic, getLocationRange)
+		},
+		sema.AuthAccountPrivatePathsField: func(inter *Interpreter, getLocationRange func() LocationRange) Value {
+			return inter.accountPathsField(address, common.PathDomainPrivate, getLocationRange)
+		},
+		sema.AuthAccountForEachStoredField: func(inter *Interpreter, _ func() LocationRange) Value {
+			return inter.accountForEachFunction(
+				address,
+				common.PathDomainStorage,
+				sema.AuthAccountForEachStoredFunctionType,
+			)
+		},
+		sema.AuthAccountForEachPublicField: func(inter *Interpreter, _ func() LocationRange) Value {
+			return inter.accountForEachFunction(
+				address,
+				common.PathDomainPublic,
+				sema.AccountForEachPublicFunctionType,
+			)
+		},
+		sema.AuthAccountForEachPrivateField: func(inter *Interpreter, _ func() LocationRange) Value {
+			return inter.accountForEachFunction(
+				address,
+				common.PathDomainPrivate,
+				sema.AuthAccountForEachPrivateFunctionType,
+			)
+		},
 	}
 
 	var str string
@@ -200,6 +230,16 @@ func NewPublicAccountValue(
 		sema.PublicAccountGetTargetLinkField: func(inter *Interpreter, _ func() LocationRange) Value {
 			return inter.accountGetLinkTargetFunction(address)
 		},
+		sema.PublicAccountPublicPathsField: func(inter *Interpreter, getLocationRange func() LocationRange) Value {
+			return inter.accountPathsField(address, common.PathDomainPublic, getLocationRange)
+		},
+		sema.PublicAccountForEachPublicField: func(inter *Interpreter, _ func() LocationRange) Value {
+			return inter.accountForEachFunction(
+				address,
+				common.PathDomainPublic,
+				sema.AccountForEachPublicFunctionType,
+			)
+		},
 	}
 
 	var str string
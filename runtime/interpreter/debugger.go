@@ -19,31 +19,108 @@
 package interpreter
 
 import (
+	"fmt"
+	"sync"
 	"sync/atomic"
 
 	"github.com/bits-and-blooms/bitset"
 
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
 )
 
+// Stop describes where the program stopped
+//
 type Stop struct {
 	Interpreter *Interpreter
 	Statement   ast.Statement
+	// CallStack are the frames of the invoked functions,
+	// from the outermost to the innermost frame.
+	// The innermost frame is the frame of the statement.
+	CallStack []StackFrame
+}
+
+// StackFrame is a frame of the call stack of a stopped program
+//
+type StackFrame struct {
+	Interpreter *Interpreter
+	// FunctionName is the qualified name of the invoked function,
+	// or empty if the function has no name, e.g. for function expressions
+	FunctionName string
+	// Statement is the statement that is executed in the frame:
+	// The stopped statement for the innermost frame,
+	// and the statement which performs the invocation for all other frames
+	Statement ast.Statement
+	// Activation is the activation of the frame,
+	// at the time of the stop, or at the time of the invocation, respectively
+	Activation *VariableActivation
+}
+
+// BreakpointConditions are the optional conditions of a line breakpoint
+//
+type BreakpointConditions struct {
+	// Condition is an optional Cadence expression of type Bool,
+	// which is evaluated in the activation of the statement.
+	// The program only stops if the condition evaluates to true,
+	// or if it cannot be checked or evaluated
+	Condition ast.Expression
+	// HitCount is the number of times the breakpoint must be reached
+	// before the program stops. The program then stops every time the breakpoint is reached
+	HitCount uint
+}
+
+type breakpoint struct {
+	condition ast.Expression
+	hitCount  uint
+	hits      uint
+}
+
+type stepKind uint8
+
+const (
+	stepKindNone stepKind = iota
+	stepKindIn
+	stepKindOver
+	stepKindOut
+)
+
+type debuggerFrame struct {
+	interpreter *Interpreter
+	function    *InterpretedFunctionValue
+	// statement and activation are only set when the frame performs an invocation
+	statement  ast.Statement
+	activation *VariableActivation
+	// stopOnEntry indicates if the program should stop at the first statement of the frame
+	stopOnEntry bool
 }
 
 type Debugger struct {
 	pauseRequested uint32
 	stops          chan Stop
 	continues      chan struct{}
-	breakpoints    map[common.Location]*bitset.BitSet
+	// breakpointsLock guards the breakpoints,
+	// as they may be changed while the program is running
+	breakpointsLock     sync.Mutex
+	breakpoints         map[common.Location]*bitset.BitSet
+	breakpointOptions   map[common.Location]map[uint]*breakpoint
+	functionBreakpoints map[common.Location]map[string]struct{}
+	functionNames       map[*ast.Program]map[ast.Statement]string
+	frames              []*debuggerFrame
+	step                stepKind
+	stepDepth           int
+	stopDepth           int
+	evaluating          bool
 }
 
 func NewDebugger() *Debugger {
 	return &Debugger{
-		stops:       make(chan Stop),
-		continues:   make(chan struct{}),
-		breakpoints: map[common.Location]*bitset.BitSet{},
+		stops:               make(chan Stop),
+		continues:           make(chan struct{}),
+		breakpoints:         map[common.Location]*bitset.BitSet{},
+		breakpointOptions:   map[common.Location]map[uint]*breakpoint{},
+		functionBreakpoints: map[common.Location]map[string]struct{}{},
+		functionNames:       map[*ast.Program]map[ast.Statement]string{},
 	}
 }
 
@@ -52,53 +129,550 @@ func (d *Debugger) Stops() <-chan Stop {
 }
 
 func (d *Debugger) AddBreakpoint(location common.Location, line uint) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
 	breakpoints, ok := d.breakpoints[location]
 	if !ok {
 		breakpoints = bitset.New(1024)
 		d.breakpoints[location] = breakpoints
 	}
 	breakpoints.Set(line)
+
+	if options, ok := d.breakpointOptions[location]; ok {
+		delete(options, line)
+	}
+}
+
+// AddConditionalBreakpoint adds a breakpoint which only stops the program
+// if the given conditions are satisfied.
+//
+func (d *Debugger) AddConditionalBreakpoint(
+	location common.Location,
+	line uint,
+	conditions BreakpointConditions,
+) {
+	d.AddBreakpoint(location, line)
+
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
+	options, ok := d.breakpointOptions[location]
+	if !ok {
+		options = map[uint]*breakpoint{}
+		d.breakpointOptions[location] = options
+	}
+	options[line] = &breakpoint{
+		condition: conditions.Condition,
+		hitCount:  conditions.HitCount,
+	}
 }
 
 func (d *Debugger) RemoveBreakpoint(location common.Location, line uint) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
 	breakpoints, ok := d.breakpoints[location]
 	if !ok {
 		return
 	}
 	breakpoints.Clear(line)
+
+	if options, ok := d.breakpointOptions[location]; ok {
+		delete(options, line)
+	}
+}
+
+// AddFunctionBreakpoint adds a breakpoint which stops the program
+// at the first statement of the function with the given qualified name,
+// e.g. `foo`, or `Token.Vault.deposit`.
+//
+// Initializers and destructors are named `init` and `destroy`,
+// and the phases of transactions are named `prepare` and `execute`.
+//
+func (d *Debugger) AddFunctionBreakpoint(location common.Location, qualifiedName string) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
+	functionBreakpoints, ok := d.functionBreakpoints[location]
+	if !ok {
+		functionBreakpoints = map[string]struct{}{}
+		d.functionBreakpoints[location] = functionBreakpoints
+	}
+	functionBreakpoints[qualifiedName] = struct{}{}
+}
+
+func (d *Debugger) RemoveFunctionBreakpoint(location common.Location, qualifiedName string) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
+	functionBreakpoints, ok := d.functionBreakpoints[location]
+	if !ok {
+		return
+	}
+	delete(functionBreakpoints, qualifiedName)
 }
 
 func (d *Debugger) ClearBreakpoints() {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
 	for location := range d.breakpoints { //nolint:maprangecheck
 		delete(d.breakpoints, location)
 	}
+	for location := range d.breakpointOptions { //nolint:maprangecheck
+		delete(d.breakpointOptions, location)
+	}
+	for location := range d.functionBreakpoints { //nolint:maprangecheck
+		delete(d.functionBreakpoints, location)
+	}
 }
 
 func (d *Debugger) ClearBreakpointsForLocation(location common.Location) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
 	delete(d.breakpoints, location)
+	delete(d.breakpointOptions, location)
+	delete(d.functionBreakpoints, location)
+}
+
+// enterFunction records the invocation of the given function.
+//
+// NOTE: must be called before the activation of the function is pushed,
+// so the current activation of the calling frame can be recorded
+//
+func (d *Debugger) enterFunction(interpreter *Interpreter, function *InterpretedFunctionValue) {
+	if len(d.frames) > 0 {
+		caller := d.frames[len(d.frames)-1]
+		caller.statement = caller.interpreter.statement
+		caller.activation = caller.interpreter.activations.Current()
+	}
+
+	frame := &debuggerFrame{
+		interpreter: interpreter,
+		function:    function,
+	}
+
+	d.breakpointsLock.Lock()
+	if functionBreakpoints, ok := d.functionBreakpoints[interpreter.Location]; ok {
+		_, frame.stopOnEntry = functionBreakpoints[d.functionName(interpreter, function)]
+	}
+	d.breakpointsLock.Unlock()
+
+	d.frames = append(d.frames, frame)
+}
+
+// exitFunction records the end of the invocation of the innermost function
+//
+func (d *Debugger) exitFunction() {
+	lastIndex := len(d.frames) - 1
+	d.frames[lastIndex] = nil
+	d.frames = d.frames[:lastIndex]
 }
 
 func (d *Debugger) onStatement(interpreter *Interpreter, statement ast.Statement) {
-	if !atomic.CompareAndSwapUint32(&d.pauseRequested, 1, 0) {
-		breakpoints, ok := d.breakpoints[interpreter.Location]
-		if !ok {
-			return
-		}
+	// Statements which are executed while evaluating a condition,
+	// e.g. in an invoked function, never stop the program
+	if d.evaluating {
+		return
+	}
 
-		startPosition := statement.StartPosition()
-		if !breakpoints.Test(uint(startPosition.Line)) {
-			return
+	stop := atomic.CompareAndSwapUint32(&d.pauseRequested, 1, 0)
+
+	if d.stepCompleted() {
+		stop = true
+	}
+
+	if len(d.frames) > 0 {
+		frame := d.frames[len(d.frames)-1]
+		if frame.stopOnEntry {
+			frame.stopOnEntry = false
+			stop = true
 		}
 	}
 
+	// Always check the breakpoints, so hits are counted
+
+	if d.breakpointReached(interpreter, statement) {
+		stop = true
+	}
+
+	if !stop {
+		return
+	}
+
+	d.step = stepKindNone
+	d.stopDepth = len(d.frames)
+
 	d.stops <- Stop{
 		Interpreter: interpreter,
 		Statement:   statement,
+		CallStack:   d.callStack(interpreter, statement),
 	}
 
 	<-d.continues
 }
 
+func (d *Debugger) stepCompleted() bool {
+	depth := len(d.frames)
+
+	switch d.step {
+	case stepKindIn:
+		return true
+	case stepKindOver:
+		return depth <= d.stepDepth
+	case stepKindOut:
+		return depth < d.stepDepth
+	default:
+		return false
+	}
+}
+
+func (d *Debugger) breakpointReached(interpreter *Interpreter, statement ast.Statement) bool {
+	reached, condition := d.lineBreakpointReached(interpreter.Location, statement)
+	if !reached {
+		return false
+	}
+
+	if condition == nil {
+		return true
+	}
+
+	// The condition is evaluated without holding the lock,
+	// as the evaluation may invoke functions
+
+	result, err := d.Evaluate(
+		interpreter,
+		interpreter.activations.Current(),
+		condition,
+		sema.BoolType,
+	)
+	if err != nil {
+		return true
+	}
+
+	return result == BoolValue(true)
+}
+
+// lineBreakpointReached returns true if there is a breakpoint for the line of the given statement,
+// and the breakpoint's hit count is reached.
+// The condition of the breakpoint, if any, still needs to be evaluated
+//
+func (d *Debugger) lineBreakpointReached(
+	location common.Location,
+	statement ast.Statement,
+) (
+	reached bool,
+	condition ast.Expression,
+) {
+	d.breakpointsLock.Lock()
+	defer d.breakpointsLock.Unlock()
+
+	breakpoints, ok := d.breakpoints[location]
+	if !ok {
+		return false, nil
+	}
+
+	line := uint(statement.StartPosition().Line)
+	if !breakpoints.Test(line) {
+		return false, nil
+	}
+
+	options, ok := d.breakpointOptions[location][line]
+	if !ok {
+		return true, nil
+	}
+
+	options.hits++
+	if options.hits < options.hitCount {
+		return false, nil
+	}
+
+	return true, options.condition
+}
+
+// Evaluate checks and evaluates the given expression in the given activation,
+// e.g. the activation of a frame of the call stack of a stop.
+// The expected type is optional.
+//
+// Evaluate must only be called while the program is stopped.
+//
+func (d *Debugger) Evaluate(
+	interpreter *Interpreter,
+	activation *VariableActivation,
+	expression ast.Expression,
+	expectedType sema.Type,
+) (
+	result Value,
+	err error,
+) {
+	d.evaluating = true
+	defer func() {
+		d.evaluating = false
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			err, ok = r.(error)
+			if !ok {
+				err = fmt.Errorf("%s", r)
+			}
+		}
+	}()
+
+	checker, err := sema.NewChecker(
+		nil,
+		interpreter.Location,
+		nil,
+		false,
+		sema.WithPredeclaredValues(debuggerValueDeclarations(interpreter, activation)),
+		sema.WithAccessCheckMode(sema.AccessCheckModeNone),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	checker.VisitExpression(expression, expectedType)
+
+	checkerErr := checker.CheckerError()
+	if checkerErr != nil {
+		return nil, checkerErr
+	}
+
+	// The sub-interpreter registers itself as the interpreter for the location,
+	// so restore the registration of the interpreter
+
+	location := interpreter.Location
+	registeredInterpreter, registered := interpreter.allInterpreters[location]
+
+	expressionInterpreter, err := interpreter.NewSubInterpreter(
+		&Program{
+			Program:     ast.NewProgram(nil, nil),
+			Elaboration: checker.Elaboration,
+		},
+		location,
+		WithDebugger(nil),
+		WithOnStatementHandler(nil),
+		WithOnFunctionInvocationHandler(nil),
+		WithOnInvokedFunctionReturnHandler(nil),
+		WithOnFunctionEntryHandler(nil),
+		WithOnFunctionExitHandler(nil),
+		WithOnBranchHandler(nil),
+		WithOnMeterComputationFuncHandler(nil),
+	)
+
+	if registered {
+		interpreter.allInterpreters[location] = registeredInterpreter
+	} else {
+		delete(interpreter.allInterpreters, location)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	expressionInterpreter.activations.PushNewWithParent(activation)
+
+	return expressionInterpreter.evalExpression(expression), nil
+}
+
+// debuggerValueDeclarations returns the value declarations for all variables
+// in the given activation, so they can be used in a checker.
+// The variables of the base activation are declared by the checker itself.
+//
+func debuggerValueDeclarations(interpreter *Interpreter, activation *VariableActivation) []sema.ValueDeclaration {
+	var declarations []sema.ValueDeclaration
+	declared := map[string]struct{}{}
+
+	for current := activation; current != nil && current != baseActivation; current = current.Parent {
+		for name, variable := range current.entries { //nolint:maprangecheck
+			if _, ok := declared[name]; ok {
+				continue
+			}
+			declared[name] = struct{}{}
+
+			staticType := variable.GetValue().StaticType(interpreter)
+			if staticType == nil {
+				continue
+			}
+
+			semaType, err := interpreter.ConvertStaticToSemaType(staticType)
+			if err != nil || semaType == nil {
+				continue
+			}
+
+			declarations = append(
+				declarations,
+				debuggerValueDeclaration{
+					name: name,
+					ty:   semaType,
+				},
+			)
+		}
+	}
+
+	return declarations
+}
+
+type debuggerValueDeclaration struct {
+	name string
+	ty   sema.Type
+}
+
+var _ sema.ValueDeclaration = debuggerValueDeclaration{}
+
+func (d debuggerValueDeclaration) ValueDeclarationName() string {
+	return d.name
+}
+
+func (d debuggerValueDeclaration) ValueDeclarationType() sema.Type {
+	return d.ty
+}
+
+func (debuggerValueDeclaration) ValueDeclarationDocString() string {
+	return ""
+}
+
+func (debuggerValueDeclaration) ValueDeclarationKind() common.DeclarationKind {
+	return common.DeclarationKindConstant
+}
+
+func (debuggerValueDeclaration) ValueDeclarationPosition() ast.Position {
+	return ast.Position{}
+}
+
+func (debuggerValueDeclaration) ValueDeclarationIsConstant() bool {
+	return true
+}
+
+func (debuggerValueDeclaration) ValueDeclarationArgumentLabels() []string {
+	return nil
+}
+
+func (debuggerValueDeclaration) ValueDeclarationAvailable(_ common.Location) bool {
+	return true
+}
+
+// callStack returns the call stack for a stop at the given statement
+//
+func (d *Debugger) callStack(interpreter *Interpreter, statement ast.Statement) []StackFrame {
+	callStack := make([]StackFrame, 0, len(d.frames)+1)
+
+	for _, frame := range d.frames {
+		callStack = append(
+			callStack,
+			StackFrame{
+				Interpreter:  frame.interpreter,
+				FunctionName: d.functionName(frame.interpreter, frame.function),
+				Statement:    frame.statement,
+				Activation:   frame.activation,
+			},
+		)
+	}
+
+	// The statement might not be executed in a function, e.g. in the REPL
+
+	if len(callStack) == 0 {
+		callStack = append(
+			callStack,
+			StackFrame{
+				Interpreter: interpreter,
+			},
+		)
+	}
+
+	innermost := &callStack[len(callStack)-1]
+	innermost.Statement = statement
+	innermost.Activation = interpreter.activations.Current()
+
+	return callStack
+}
+
+// functionName returns the qualified name of the given function,
+// or an empty string if the function has no name.
+//
+// Functions are identified by their first statement.
+//
+func (d *Debugger) functionName(interpreter *Interpreter, function *InterpretedFunctionValue) string {
+	if len(function.Statements) == 0 ||
+		interpreter.Program == nil ||
+		interpreter.Program.Program == nil {
+
+		return ""
+	}
+
+	program := interpreter.Program.Program
+
+	names, ok := d.functionNames[program]
+	if !ok {
+		names = functionNames(program)
+		d.functionNames[program] = names
+	}
+
+	return names[function.Statements[0]]
+}
+
+// functionNames returns the qualified names of all named functions in the given program,
+// by the first statement of the function
+//
+func functionNames(program *ast.Program) map[ast.Statement]string {
+	names := map[ast.Statement]string{}
+
+	addFunction := func(prefix string, declaration *ast.FunctionDeclaration) {
+		if declaration == nil ||
+			declaration.FunctionBlock == nil ||
+			declaration.FunctionBlock.Block == nil {
+
+			return
+		}
+
+		statements := declaration.FunctionBlock.Block.Statements
+		if len(statements) == 0 {
+			return
+		}
+
+		names[statements[0]] = prefix + declaration.Identifier.Identifier
+	}
+
+	var addComposite func(prefix string, declaration *ast.CompositeDeclaration)
+	addComposite = func(prefix string, declaration *ast.CompositeDeclaration) {
+		prefix += declaration.Identifier.Identifier + "."
+
+		for _, function := range declaration.Members.Functions() {
+			addFunction(prefix, function)
+		}
+
+		for _, specialFunction := range declaration.Members.SpecialFunctions() {
+			addFunction(prefix, specialFunction.FunctionDeclaration)
+		}
+
+		for _, nestedComposite := range declaration.Members.Composites() {
+			addComposite(prefix, nestedComposite)
+		}
+	}
+
+	for _, declaration := range program.FunctionDeclarations() {
+		addFunction("", declaration)
+	}
+
+	for _, declaration := range program.CompositeDeclarations() {
+		addComposite("", declaration)
+	}
+
+	for _, declaration := range program.TransactionDeclarations() {
+		if declaration.Prepare != nil {
+			addFunction("", declaration.Prepare.FunctionDeclaration)
+		}
+		if declaration.Execute != nil {
+			addFunction("", declaration.Execute.FunctionDeclaration)
+		}
+	}
+
+	return names
+}
+
 func (d *Debugger) RequestPause() {
 	atomic.StoreUint32(&d.pauseRequested, 1)
 }
@@ -118,6 +692,61 @@ func (d *Debugger) Next() Stop {
 	return <-d.Stops()
 }
 
+// ContinueStepIn continues the stopped program,
+// and stops it again at the next statement, which might be in an invoked function.
+// It does not wait for the program to stop.
+//
+func (d *Debugger) ContinueStepIn() {
+	d.continueStep(stepKindIn)
+}
+
+// ContinueStepOver continues the stopped program,
+// and stops it again at the next statement in the current function,
+// or in a calling function if the current function returns.
+// It does not wait for the program to stop.
+//
+func (d *Debugger) ContinueStepOver() {
+	d.continueStep(stepKindOver)
+}
+
+// ContinueStepOut continues the stopped program,
+// and stops it again at the next statement after the current function returned.
+// It does not wait for the program to stop.
+//
+func (d *Debugger) ContinueStepOut() {
+	d.continueStep(stepKindOut)
+}
+
+func (d *Debugger) continueStep(step stepKind) {
+	d.step = step
+	d.stepDepth = d.stopDepth
+	d.Continue()
+}
+
+// StepIn continues the stopped program like ContinueStepIn,
+// and waits for the program to stop again.
+//
+func (d *Debugger) StepIn() Stop {
+	d.ContinueStepIn()
+	return <-d.Stops()
+}
+
+// StepOver continues the stopped program like ContinueStepOver,
+// and waits for the program to stop again.
+//
+func (d *Debugger) StepOver() Stop {
+	d.ContinueStepOver()
+	return <-d.Stops()
+}
+
+// StepOut continues the stopped program like ContinueStepOut,
+// and waits for the program to stop again.
+//
+func (d *Debugger) StepOut() Stop {
+	d.ContinueStepOut()
+	return <-d.Stops()
+}
+
 func (d *Debugger) CurrentActivation(interpreter *Interpreter) *VariableActivation {
 	return interpreter.activations.Current()
 }
@@ -1139,7 +1139,7 @@ func (d TypeDecoder) decodeCompositeStaticType() (StaticType, error) {
 		return nil, err
 	}
 
-	if size != expectedLength {
+	if size != expectedLength && size != encodedGenericCompositeStaticTypeLength {
 		return nil, errors.NewUnexpectedError(
 			"invalid composite static type encoding: expected [%d]any, got [%d]any",
 			expectedLength,
@@ -1165,7 +1165,37 @@ func (d TypeDecoder) decodeCompositeStaticType() (StaticType, error) {
 		return nil, err
 	}
 
-	return NewCompositeStaticTypeComputeTypeID(d.memoryGauge, location, qualifiedIdentifier), nil
+	staticType := NewCompositeStaticTypeComputeTypeID(d.memoryGauge, location, qualifiedIdentifier)
+
+	if size == encodedGenericCompositeStaticTypeLength {
+		// Decode type arguments at array index encodedCompositeStaticTypeTypeArgumentsFieldKey
+		staticType.TypeArguments, err = d.decodeStaticTypes()
+		if err != nil {
+			return nil, errors.NewUnexpectedError(
+				"invalid composite static type type arguments encoding: %w",
+				err,
+			)
+		}
+	}
+
+	return staticType, nil
+}
+
+func (d TypeDecoder) decodeStaticTypes() ([]StaticType, error) {
+	size, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	staticTypes := make([]StaticType, size)
+	for i := 0; i < int(size); i++ {
+		staticTypes[i], err = d.DecodeStaticType()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return staticTypes, nil
 }
 
 func (d TypeDecoder) decodeInterfaceStaticType() (InterfaceStaticType, error) {
@@ -1506,7 +1536,9 @@ func (d TypeDecoder) decodeCompositeTypeInfo() (atree.TypeInfo, error) {
 		return nil, err
 	}
 
-	if length != encodedCompositeTypeInfoLength {
+	if length != encodedCompositeTypeInfoLength &&
+		length != encodedGenericCompositeTypeInfoLength {
+
 		return nil, errors.NewUnexpectedError(
 			"invalid composite type info: expected %d elements, got %d",
 			encodedCompositeTypeInfoLength, length,
@@ -1535,11 +1567,20 @@ func (d TypeDecoder) decodeCompositeTypeInfo() (atree.TypeInfo, error) {
 		)
 	}
 
+	var typeArguments []StaticType
+	if length == encodedGenericCompositeTypeInfoLength {
+		typeArguments, err = d.decodeStaticTypes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return NewCompositeTypeInfo(
 		d.memoryGauge,
 		location,
 		qualifiedIdentifier,
 		common.CompositeKind(kind),
+		typeArguments,
 	), nil
 }
 
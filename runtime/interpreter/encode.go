@@ -1009,12 +1009,18 @@ func (t OptionalStaticType) Encode(e *cbor.StreamEncoder) error {
 const (
 	// encodedCompositeStaticTypeLocationFieldKey            uint64 = 0
 	// encodedCompositeStaticTypeQualifiedIdentifierFieldKey uint64 = 1
+	// encodedCompositeStaticTypeTypeArgumentsFieldKey       uint64 = 2
 
 	// !!! *WARNING* !!!
 	//
 	// encodedCompositeStaticTypeLength MUST be updated when new element is added.
 	// It is used to verify encoded composite static type length during decoding.
 	encodedCompositeStaticTypeLength = 2
+
+	// encodedGenericCompositeStaticTypeLength is the length
+	// of an encoded instantiated generic composite static type,
+	// which additionally has type arguments.
+	encodedGenericCompositeStaticTypeLength = 3
 )
 
 // Encode encodes CompositeStaticType as
@@ -1023,15 +1029,27 @@ const (
 // 			Content: cborArray{
 //				encodedCompositeStaticTypeLocationFieldKey:            Location(v.Location),
 //				encodedCompositeStaticTypeQualifiedIdentifierFieldKey: string(v.QualifiedIdentifier),
+//				encodedCompositeStaticTypeTypeArgumentsFieldKey:       []any(v.TypeArguments),
 //		},
 // }
+//
+// The type arguments are only encoded if there are any.
+//
 func (t CompositeStaticType) Encode(e *cbor.StreamEncoder) error {
 	// Encode tag number and array head
+	var arrayHead byte
+	if len(t.TypeArguments) == 0 {
+		// array, 2 items follow
+		arrayHead = 0x82
+	} else {
+		// array, 3 items follow
+		arrayHead = 0x83
+	}
+
 	err := e.EncodeRawBytes([]byte{
 		// tag number
 		0xd8, CBORTagCompositeStaticType,
-		// array, 2 items follow
-		0x82,
+		arrayHead,
 	})
 	if err != nil {
 		return err
@@ -1044,7 +1062,33 @@ func (t CompositeStaticType) Encode(e *cbor.StreamEncoder) error {
 	}
 
 	// Encode qualified identifier at array index encodedCompositeStaticTypeQualifiedIdentifierFieldKey
-	return e.EncodeString(t.QualifiedIdentifier)
+	err = e.EncodeString(t.QualifiedIdentifier)
+	if err != nil {
+		return err
+	}
+
+	if len(t.TypeArguments) == 0 {
+		return nil
+	}
+
+	// Encode type arguments (as array) at array index encodedCompositeStaticTypeTypeArgumentsFieldKey
+	return encodeStaticTypes(e, t.TypeArguments)
+}
+
+func encodeStaticTypes(e *cbor.StreamEncoder, staticTypes []StaticType) error {
+	err := e.EncodeArrayHead(uint64(len(staticTypes)))
+	if err != nil {
+		return err
+	}
+
+	for _, staticType := range staticTypes {
+		err = EncodeStaticType(e, staticType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // NOTE: NEVER change, only add/increment; ensure uint64
@@ -1304,6 +1348,7 @@ type compositeTypeInfo struct {
 	location            common.Location
 	qualifiedIdentifier string
 	kind                common.CompositeKind
+	typeArguments       []StaticType
 }
 
 func NewCompositeTypeInfo(
@@ -1311,6 +1356,7 @@ func NewCompositeTypeInfo(
 	location common.Location,
 	qualifiedIdentifier string,
 	kind common.CompositeKind,
+	typeArguments []StaticType,
 ) compositeTypeInfo {
 	common.UseMemory(memoryGauge, common.CompositeTypeInfoMemoryUsage)
 
@@ -1318,6 +1364,7 @@ func NewCompositeTypeInfo(
 		location:            location,
 		qualifiedIdentifier: qualifiedIdentifier,
 		kind:                kind,
+		typeArguments:       typeArguments,
 	}
 }
 
@@ -1325,12 +1372,24 @@ var _ atree.TypeInfo = compositeTypeInfo{}
 
 const encodedCompositeTypeInfoLength = 3
 
+// encodedGenericCompositeTypeInfoLength is the length of the encoded type info
+// of an instantiated generic composite, which additionally has type arguments
+const encodedGenericCompositeTypeInfoLength = 4
+
 func (c compositeTypeInfo) Encode(e *cbor.StreamEncoder) error {
+	var arrayHead byte
+	if len(c.typeArguments) == 0 {
+		// array, 3 items follow
+		arrayHead = 0x83
+	} else {
+		// array, 4 items follow
+		arrayHead = 0x84
+	}
+
 	err := e.EncodeRawBytes([]byte{
 		// tag number
 		0xd8, CBORTagCompositeValue,
-		// array, 3 items follow
-		0x83,
+		arrayHead,
 	})
 	if err != nil {
 		return err
@@ -1351,15 +1410,34 @@ func (c compositeTypeInfo) Encode(e *cbor.StreamEncoder) error {
 		return err
 	}
 
+	if len(c.typeArguments) > 0 {
+		err = encodeStaticTypes(e, c.typeArguments)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (c compositeTypeInfo) Equal(o atree.TypeInfo) bool {
 	other, ok := o.(compositeTypeInfo)
-	return ok &&
-		c.location == other.location &&
-		c.qualifiedIdentifier == other.qualifiedIdentifier &&
-		c.kind == other.kind
+	if !ok ||
+		c.location != other.location ||
+		c.qualifiedIdentifier != other.qualifiedIdentifier ||
+		c.kind != other.kind ||
+		len(c.typeArguments) != len(other.typeArguments) {
+
+		return false
+	}
+
+	for i, typeArgument := range c.typeArguments {
+		if !typeArgument.Equal(other.typeArguments[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // EmptyTypeInfo
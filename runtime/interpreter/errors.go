@@ -66,12 +66,26 @@ func (e Error) Error() string {
 func (e Error) ChildErrors() []error {
 	errs := make([]error, 0, 1+len(e.StackTrace))
 
+	var previousLocationRange LocationRange
+
 	for _, invocation := range e.StackTrace {
 		locationRange := invocation.GetLocationRange()
 		if locationRange.Location == nil {
 			continue
 		}
 
+		// Only report the first of consecutive invocations at the same location,
+		// e.g. of a recursive function, as the stack trace might be very long,
+		// e.g. when the call stack depth limit is exceeded
+
+		if previousLocationRange.Location != nil &&
+			locationRange.Range == previousLocationRange.Range &&
+			locationRange.Location.ID() == previousLocationRange.Location.ID() {
+
+			continue
+		}
+		previousLocationRange = locationRange
+
 		errs = append(
 			errs,
 			StackTraceError{
@@ -825,3 +839,57 @@ func (DuplicateKeyInResourceDictionaryError) IsUserError() {}
 func (e DuplicateKeyInResourceDictionaryError) Error() string {
 	return "duplicate key in resource dictionary"
 }
+
+// StorageMutatedDuringIterationError
+//
+type StorageMutatedDuringIterationError struct {
+	Address AddressValue
+	Domain  common.PathDomain
+	LocationRange
+}
+
+var _ errors.UserError = StorageMutatedDuringIterationError{}
+
+func (StorageMutatedDuringIterationError) IsUserError() {}
+
+func (e StorageMutatedDuringIterationError) Error() string {
+	return fmt.Sprintf(
+		"cannot continue iteration over the %s storage of account %s: storage was mutated during iteration",
+		e.Domain.Identifier(),
+		e.Address,
+	)
+}
+
+// ContainerMutatedDuringIterationError is reported when an array or dictionary
+// is mutated while it is iterated over by a higher-order function, e.g. `filter`.
+//
+type ContainerMutatedDuringIterationError struct {
+	LocationRange
+}
+
+var _ errors.UserError = ContainerMutatedDuringIterationError{}
+
+func (ContainerMutatedDuringIterationError) IsUserError() {}
+
+func (e ContainerMutatedDuringIterationError) Error() string {
+	return "cannot continue iteration: container was mutated during iteration"
+}
+
+// CallStackLimitExceededError is reported when the depth of the call stack
+// exceeds the limit configured using WithCallStackDepthLimit.
+//
+type CallStackLimitExceededError struct {
+	Limit uint64
+	LocationRange
+}
+
+var _ errors.UserError = CallStackLimitExceededError{}
+
+func (CallStackLimitExceededError) IsUserError() {}
+
+func (e CallStackLimitExceededError) Error() string {
+	return fmt.Sprintf(
+		"call stack limit exceeded: %d",
+		e.Limit,
+	)
+}
@@ -44,7 +44,10 @@ type FunctionValue interface {
 // InterpretedFunctionValue
 //
 type InterpretedFunctionValue struct {
-	Interpreter      *Interpreter
+	Interpreter *Interpreter
+	// Declaration is the function declaration or function expression
+	// the function was created from
+	Declaration      ast.Element
 	ParameterList    *ast.ParameterList
 	Type             *sema.FunctionType
 	Activation       *VariableActivation
@@ -56,6 +59,7 @@ type InterpretedFunctionValue struct {
 
 func NewInterpretedFunctionValue(
 	interpreter *Interpreter,
+	declaration ast.Element,
 	parameterList *ast.ParameterList,
 	functionType *sema.FunctionType,
 	lexicalScope *VariableActivation,
@@ -69,6 +73,7 @@ func NewInterpretedFunctionValue(
 
 	return &InterpretedFunctionValue{
 		Interpreter:      interpreter,
+		Declaration:      declaration,
 		ParameterList:    parameterList,
 		Type:             functionType,
 		Activation:       lexicalScope,
@@ -246,6 +251,10 @@ func (f *HostFunctionValue) invoke(invocation Invocation) Value {
 	// The check that arguments' dynamic types match the parameter types
 	// was already performed by the interpreter's checkValueTransferTargetType function
 
+	inter := invocation.Interpreter
+	defer inter.exitFunctionInvocation()
+	inter.enterFunctionInvocation(invocation.GetLocationRange)
+
 	return f.Function(invocation)
 }
 
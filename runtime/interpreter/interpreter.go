@@ -116,6 +116,40 @@ type OnInvokedFunctionReturnFunc func(
 	line int,
 )
 
+// OnFunctionEntryFunc is a function that is triggered when the body of an interpreted function
+// is about to be executed.
+//
+type OnFunctionEntryFunc func(
+	inter *Interpreter,
+	function *InterpretedFunctionValue,
+)
+
+// OnFunctionExitFunc is a function that is triggered when the body of an interpreted function
+// was executed, or its execution was aborted.
+//
+type OnFunctionExitFunc func(
+	inter *Interpreter,
+	function *InterpretedFunctionValue,
+)
+
+// OnBranchFunc is a function that is triggered when a branch of a conditional element is about to be executed.
+//
+// The element and the branch are:
+//   - For an if-statement, 0 for the then-branch, and 1 for the else-branch, even if there is no else-block.
+//   - For a switch-statement, the index of the executed case,
+//     or the number of cases if no case is executed.
+//   - For a conditional expression, 0 for the then-branch, and 1 for the else-branch.
+//   - For a nil-coalescing binary expression, 0 if the left-hand side is not nil,
+//     and 1 if the right-hand side is evaluated.
+//   - For an optional chaining member expression, 0 if the member is accessed,
+//     and 1 if the accessed value is nil.
+//
+type OnBranchFunc func(
+	inter *Interpreter,
+	element ast.Element,
+	branch int,
+)
+
 // OnRecordTraceFunc is a function thats records a trace.
 type OnRecordTraceFunc func(
 	inter *Interpreter,
@@ -320,6 +354,18 @@ type Storage interface {
 
 type ReferencedResourceKindedValues map[atree.StorageID]map[ReferenceTrackedResourceKindedValue]struct{}
 
+// StorageMutations counts the mutations of the account storage domains
+// which are currently being iterated over.
+// The key of a storage domain is the address of the account and the domain identifier.
+//
+type StorageMutations map[StorageKey]uint64
+
+// ContainerMutations counts the mutations of the arrays and dictionaries
+// which are currently being iterated over by a higher-order function, e.g. `filter`.
+// The key of a container is its storage ID.
+//
+type ContainerMutations map[atree.StorageID]uint64
+
 type Interpreter struct {
 	Program                        *Program
 	Location                       common.Location
@@ -336,6 +382,9 @@ type Interpreter struct {
 	onLoopIteration                OnLoopIterationFunc
 	onFunctionInvocation           OnFunctionInvocationFunc
 	onInvokedFunctionReturn        OnInvokedFunctionReturnFunc
+	onFunctionEntry                OnFunctionEntryFunc
+	onFunctionExit                 OnFunctionExitFunc
+	onBranch                       OnBranchFunc
 	onRecordTrace                  OnRecordTraceFunc
 	onResourceOwnerChange          OnResourceOwnerChangeFunc
 	onMeterComputation             OnMeterComputationFunc
@@ -361,8 +410,11 @@ type Interpreter struct {
 	referencedResourceKindedValues       ReferencedResourceKindedValues
 	invalidatedResourceValidationEnabled bool
 	resourceVariables                    map[ResourceKindedValue]*Variable
+	storageMutations                     StorageMutations
+	containerMutations                   ContainerMutations
 	memoryGauge                          common.MemoryGauge
 	CallStack                            *CallStack
+	callStackDepthLimit                  uint64
 }
 
 var _ common.MemoryGauge = &Interpreter{}
@@ -419,6 +471,36 @@ func WithOnInvokedFunctionReturnHandler(handler OnInvokedFunctionReturnFunc) Opt
 	}
 }
 
+// WithOnFunctionEntryHandler returns an interpreter option which sets
+// the given function as the function entry handler.
+//
+func WithOnFunctionEntryHandler(handler OnFunctionEntryFunc) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.SetOnFunctionEntryHandler(handler)
+		return nil
+	}
+}
+
+// WithOnFunctionExitHandler returns an interpreter option which sets
+// the given function as the function exit handler.
+//
+func WithOnFunctionExitHandler(handler OnFunctionExitFunc) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.SetOnFunctionExitHandler(handler)
+		return nil
+	}
+}
+
+// WithOnBranchHandler returns an interpreter option which sets
+// the given function as the branch handler.
+//
+func WithOnBranchHandler(handler OnBranchFunc) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.SetOnBranchHandler(handler)
+		return nil
+	}
+}
+
 // WithMemoryGauge returns an interpreter option which sets
 // the given object as the memory gauge.
 //
@@ -618,6 +700,17 @@ func WithCallStack(callStack *CallStack) Option {
 	}
 }
 
+// WithCallStackDepthLimit returns an interpreter option which sets
+// the maximum depth of the call stack.
+// A limit of zero means the call stack depth is unlimited.
+//
+func WithCallStackDepthLimit(limit uint64) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.SetCallStackDepthLimit(limit)
+		return nil
+	}
+}
+
 // WithAtreeValueValidationEnabled returns an interpreter option which sets
 // the atree validation option.
 //
@@ -676,6 +769,24 @@ func withReferencedResourceKindedValues(referencedResourceKindedValues Reference
 	}
 }
 
+// withStorageMutations returns an interpreter option which sets the storage mutations.
+//
+func withStorageMutations(storageMutations StorageMutations) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.storageMutations = storageMutations
+		return nil
+	}
+}
+
+// withContainerMutations returns an interpreter option which sets the container mutations.
+//
+func withContainerMutations(containerMutations ContainerMutations) Option {
+	return func(interpreter *Interpreter) error {
+		interpreter.containerMutations = containerMutations
+		return nil
+	}
+}
+
 // WithDebugger returns an interpreter option which sets the given debugger
 //
 func WithDebugger(debugger *Debugger) Option {
@@ -720,6 +831,8 @@ func NewInterpreter(program *Program, location common.Location, options ...Optio
 			TypeRequirementCodes: map[sema.TypeID]WrapperCode{},
 		}),
 		withReferencedResourceKindedValues(map[atree.StorageID]map[ReferenceTrackedResourceKindedValue]struct{}{}),
+		withStorageMutations(StorageMutations{}),
+		withContainerMutations(ContainerMutations{}),
 		WithInvalidatedResourceValidationEnabled(true),
 	}
 
@@ -770,12 +883,40 @@ func (interpreter *Interpreter) SetOnInvokedFunctionReturnHandler(function OnInv
 	interpreter.onInvokedFunctionReturn = function
 }
 
+// SetOnFunctionEntryHandler sets the function that is triggered when the body of an interpreted function
+// is about to be executed.
+//
+func (interpreter *Interpreter) SetOnFunctionEntryHandler(function OnFunctionEntryFunc) {
+	interpreter.onFunctionEntry = function
+}
+
+// SetOnFunctionExitHandler sets the function that is triggered when the body of an interpreted function
+// was executed, or its execution was aborted.
+//
+func (interpreter *Interpreter) SetOnFunctionExitHandler(function OnFunctionExitFunc) {
+	interpreter.onFunctionExit = function
+}
+
+// SetOnBranchHandler sets the function that is triggered when a branch of a conditional element
+// is about to be executed.
+//
+func (interpreter *Interpreter) SetOnBranchHandler(function OnBranchFunc) {
+	interpreter.onBranch = function
+}
+
 // SetMemoryGauge sets the object as the memory gauge.
 //
 func (interpreter *Interpreter) SetMemoryGauge(memoryGauge common.MemoryGauge) {
 	interpreter.memoryGauge = memoryGauge
 }
 
+// SetCallStackDepthLimit sets the maximum depth of the call stack.
+// A limit of zero means the call stack depth is unlimited.
+//
+func (interpreter *Interpreter) SetCallStackDepthLimit(limit uint64) {
+	interpreter.callStackDepthLimit = limit
+}
+
 // SetOnRecordTraceHandler sets the function that is triggered when a trace is recorded.
 //
 func (interpreter *Interpreter) SetOnRecordTraceHandler(function OnRecordTraceFunc) {
@@ -788,6 +929,13 @@ func (interpreter *Interpreter) SetOnResourceOwnerChangeHandler(function OnResou
 	interpreter.onResourceOwnerChange = function
 }
 
+// DeclareCompositeTypeCode declares the code of the composite type with the given ID,
+// e.g. the destructor of a composite type which is not declared by an interpreted program.
+//
+func (interpreter *Interpreter) DeclareCompositeTypeCode(typeID sema.TypeID, code CompositeTypeCode) {
+	interpreter.typeCodes.CompositeCodes[typeID] = code
+}
+
 // SetOnMeterComputationFuncHandler sets the function that is triggered when a computation is about to happen.
 //
 func (interpreter *Interpreter) SetOnMeterComputationHandler(function OnMeterComputationFunc) {
@@ -929,12 +1077,12 @@ func locationRangeGetter(
 	}
 }
 
-func (interpreter *Interpreter) findVariable(name string) *Variable {
+func (interpreter *Interpreter) FindVariable(name string) *Variable {
 	return interpreter.activations.Find(name)
 }
 
 func (interpreter *Interpreter) findOrDeclareVariable(name string) *Variable {
-	variable := interpreter.findVariable(name)
+	variable := interpreter.FindVariable(name)
 	if variable == nil {
 		variable = interpreter.declareVariable(name, nil)
 	}
@@ -979,7 +1127,7 @@ func (interpreter *Interpreter) declareGlobal(declaration ast.Declaration) {
 	}
 	name := identifier.Identifier
 	// NOTE: semantic analysis already checked possible invalid redeclaration
-	interpreter.Globals.Set(name, interpreter.findVariable(name))
+	interpreter.Globals.Set(name, interpreter.FindVariable(name))
 }
 
 // invokeVariable looks up the function by the given name from global variables,
@@ -1324,8 +1472,9 @@ func (interpreter *Interpreter) functionDeclarationValue(
 
 	return NewInterpretedFunctionValue(
 		interpreter,
+		declaration,
 		declaration.ParameterList,
-		functionType,
+		interpreter.resolveFunctionType(functionType),
 		lexicalScope,
 		beforeStatements,
 		preConditions,
@@ -1494,7 +1643,7 @@ func (interpreter *Interpreter) visitAssignment(
 
 	value := interpreter.evalExpression(valueExpression)
 
-	transferredValue := interpreter.transferAndConvert(value, valueType, targetType, getLocationRange)
+	transferredValue := interpreter.TransferAndConvert(value, valueType, targetType, getLocationRange)
 
 	getterSetter.set(transferredValue)
 }
@@ -1766,7 +1915,23 @@ func (interpreter *Interpreter) declareNonEnumCompositeValue(
 					)
 				}
 
-				value := NewCompositeValue(
+				// Instances of user-defined generic composite types
+				// keep the type arguments of the constructor invocation
+
+				var typeArguments []StaticType
+				if compositeType.IsGeneric() && invocation.TypeParameterTypes != nil {
+					typeParameters := compositeType.TypeParameters()
+					typeArguments = make([]StaticType, len(typeParameters))
+					for i, typeParameter := range typeParameters {
+						typeArgument, ok := invocation.TypeParameterTypes.Get(typeParameter)
+						if !ok {
+							panic(errors.NewUnreachableError())
+						}
+						typeArguments[i] = ConvertSemaToStaticType(interpreter, typeArgument)
+					}
+				}
+
+				value := NewGenericCompositeValue(
 					interpreter,
 					invocation.GetLocationRange,
 					location,
@@ -1774,6 +1939,7 @@ func (interpreter *Interpreter) declareNonEnumCompositeValue(
 					declaration.CompositeKind,
 					fields,
 					address,
+					typeArguments,
 				)
 
 				value.InjectedFields = injectedFields
@@ -1985,6 +2151,7 @@ func (interpreter *Interpreter) compositeInitializerFunction(
 
 	return NewInterpretedFunctionValue(
 		interpreter,
+		initializer.FunctionDeclaration,
 		parameterList,
 		functionType,
 		lexicalScope,
@@ -2028,6 +2195,7 @@ func (interpreter *Interpreter) compositeDestructorFunction(
 
 	return NewInterpretedFunctionValue(
 		interpreter,
+		destructor.FunctionDeclaration,
 		nil,
 		emptyFunctionType,
 		lexicalScope,
@@ -2113,6 +2281,7 @@ func (interpreter *Interpreter) compositeFunction(
 
 	return NewInterpretedFunctionValue(
 		interpreter,
+		functionDeclaration,
 		parameterList,
 		functionType,
 		lexicalScope,
@@ -2137,7 +2306,7 @@ func (interpreter *Interpreter) ValueIsSubtypeOfSemaType(value Value, targetType
 	return interpreter.IsSubTypeOfSemaType(value.StaticType(interpreter), targetType)
 }
 
-func (interpreter *Interpreter) transferAndConvert(
+func (interpreter *Interpreter) TransferAndConvert(
 	value Value,
 	valueType, targetType sema.Type,
 	getLocationRange func() LocationRange,
@@ -2160,7 +2329,7 @@ func (interpreter *Interpreter) transferAndConvert(
 
 	// Defensively check the value's type matches the target type
 	if targetType != nil &&
-		!interpreter.ValueIsSubtypeOfSemaType(result, targetType) {
+		!interpreter.ValueIsSubtypeOfSemaType(result, interpreter.resolveType(targetType)) {
 
 		panic(ValueTransferTypeError{
 			TargetType:    targetType,
@@ -2699,16 +2868,22 @@ func (interpreter *Interpreter) NewSubInterpreter(
 		WithOnLoopIterationHandler(interpreter.onLoopIteration),
 		WithOnFunctionInvocationHandler(interpreter.onFunctionInvocation),
 		WithOnInvokedFunctionReturnHandler(interpreter.onInvokedFunctionReturn),
+		WithOnFunctionEntryHandler(interpreter.onFunctionEntry),
+		WithOnFunctionExitHandler(interpreter.onFunctionExit),
+		WithOnBranchHandler(interpreter.onBranch),
 		WithInjectedCompositeFieldsHandler(interpreter.injectedCompositeFieldsHandler),
 		WithContractValueHandler(interpreter.contractValueHandler),
 		WithImportLocationHandler(interpreter.importLocationHandler),
 		WithUUIDHandler(interpreter.uuidHandler),
 		WithAllInterpreters(interpreter.allInterpreters),
 		WithCallStack(interpreter.CallStack),
+		WithCallStackDepthLimit(interpreter.callStackDepthLimit),
 		WithAtreeValueValidationEnabled(interpreter.atreeValueValidationEnabled),
 		WithAtreeStorageValidationEnabled(interpreter.atreeStorageValidationEnabled),
 		withTypeCodes(interpreter.typeCodes),
 		withReferencedResourceKindedValues(interpreter.referencedResourceKindedValues),
+		withStorageMutations(interpreter.storageMutations),
+		withContainerMutations(interpreter.containerMutations),
 		WithPublicAccountHandler(interpreter.publicAccountHandler),
 		WithPublicKeyValidationHandler(interpreter.PublicKeyValidationHandler),
 		WithSignatureVerificationHandler(interpreter.SignatureVerificationHandler),
@@ -2767,10 +2942,70 @@ func (interpreter *Interpreter) writeStored(
 	identifier string,
 	value Value,
 ) {
+	interpreter.recordStorageMutation(storageAddress, domain)
+
 	accountStorage := interpreter.Storage.GetStorageMap(storageAddress, domain, true)
 	accountStorage.WriteValue(interpreter, identifier, value)
 }
 
+// recordStorageMutation records the mutation of the given account storage domain,
+// if the domain is currently being iterated over.
+//
+func (interpreter *Interpreter) recordStorageMutation(storageAddress common.Address, domain string) {
+	storageKey := StorageKey{
+		Address: storageAddress,
+		Key:     domain,
+	}
+
+	if count, ok := interpreter.storageMutations[storageKey]; ok {
+		interpreter.storageMutations[storageKey] = count + 1
+	}
+}
+
+// recordContainerMutation records the mutation of the given array or dictionary,
+// if the container is currently being iterated over.
+//
+func (interpreter *Interpreter) recordContainerMutation(storageID atree.StorageID) {
+	if count, ok := interpreter.containerMutations[storageID]; ok {
+		interpreter.containerMutations[storageID] = count + 1
+	}
+}
+
+// trackContainerMutations starts tracking the mutations of the given array or dictionary,
+// unless an outer iteration over the same container already does.
+//
+// The returned check function reports an error if the container was mutated since,
+// and the returned stop function ends the tracking.
+//
+func (interpreter *Interpreter) trackContainerMutations(
+	storageID atree.StorageID,
+	getLocationRange func() LocationRange,
+) (
+	check func(),
+	stop func(),
+) {
+	mutationCount, nested := interpreter.containerMutations[storageID]
+	if !nested {
+		interpreter.containerMutations[storageID] = mutationCount
+	}
+
+	check = func() {
+		if interpreter.containerMutations[storageID] != mutationCount {
+			panic(ContainerMutatedDuringIterationError{
+				LocationRange: getLocationRange(),
+			})
+		}
+	}
+
+	stop = func() {
+		if !nested {
+			delete(interpreter.containerMutations, storageID)
+		}
+	}
+
+	return check, stop
+}
+
 type ValueConverterDeclaration struct {
 	name         string
 	convert      func(*Interpreter, Value) Value
@@ -3550,6 +3785,26 @@ var stringFunction = func() Value {
 		),
 	)
 
+	addMember(
+		sema.StringTypeJoinFunctionName,
+		NewUnmeteredHostFunctionValue(
+			func(invocation Invocation) Value {
+				strs, ok := invocation.Arguments[0].(*ArrayValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				separator, ok := invocation.Arguments[1].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return JoinStrings(invocation.Interpreter, strs, separator)
+			},
+			sema.StringTypeJoinFunctionType,
+		),
+	)
+
 	return functionValue
 }()
 
@@ -3979,6 +4234,155 @@ func (interpreter *Interpreter) authAccountUnlinkFunction(addressValue AddressVa
 	)
 }
 
+func (interpreter *Interpreter) accountPathsField(
+	addressValue AddressValue,
+	domain common.PathDomain,
+	getLocationRange func() LocationRange,
+) *ArrayValue {
+
+	address := addressValue.ToAddress()
+
+	var paths []Value
+
+	storageMap := interpreter.Storage.GetStorageMap(address, domain.Identifier(), false)
+	if storageMap != nil {
+		iterator := storageMap.Iterator(interpreter)
+
+		for key := iterator.NextKey(); key != ""; key = iterator.NextKey() {
+			interpreter.ReportComputation(common.ComputationKindLoop, 1)
+
+			paths = append(paths, NewPathValue(interpreter, domain, key))
+		}
+	}
+
+	pathStaticType := PathValue{Domain: domain}.StaticType(interpreter)
+
+	return NewArrayValue(
+		interpreter,
+		getLocationRange,
+		NewVariableSizedStaticType(interpreter, pathStaticType),
+		common.Address{},
+		paths...,
+	)
+}
+
+// accountForEachFunction returns the function which iterates over
+// the given storage domain of the account with the given address,
+// e.g. `AuthAccount.forEachStored`.
+//
+// The iteration function is called with the path and the type of each stored value,
+// and the iteration stops if the iteration function returns false.
+//
+// The storage domain may be mutated by the iteration function,
+// e.g. a value may be loaded or saved, but then the iteration function must return false,
+// i.e. the iteration must not be continued after a mutation,
+// as the remaining elements of the iteration would be undefined.
+// Continuing the iteration after a mutation aborts the program.
+//
+func (interpreter *Interpreter) accountForEachFunction(
+	addressValue AddressValue,
+	domain common.PathDomain,
+	functionType *sema.FunctionType,
+) *HostFunctionValue {
+
+	// Converted addresses can be cached and don't have to be recomputed on each function invocation
+	address := addressValue.ToAddress()
+
+	domainIdentifier := domain.Identifier()
+
+	iterationFunctionType, ok := functionType.Parameters[0].TypeAnnotation.Type.(*sema.FunctionType)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	argumentTypes := []sema.Type{
+		iterationFunctionType.Parameters[0].TypeAnnotation.Type,
+		iterationFunctionType.Parameters[1].TypeAnnotation.Type,
+	}
+
+	return NewHostFunctionValue(
+		interpreter,
+		func(invocation Invocation) Value {
+			inter := invocation.Interpreter
+			getLocationRange := invocation.GetLocationRange
+
+			function, ok := invocation.Arguments[0].(FunctionValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			storageMap := inter.Storage.GetStorageMap(address, domainIdentifier, false)
+			if storageMap == nil {
+				return NewVoidValue(inter)
+			}
+
+			// Start tracking the mutations of the storage domain,
+			// unless an outer iteration over the same domain already does
+
+			storageKey := StorageKey{
+				Address: address,
+				Key:     domainIdentifier,
+			}
+
+			mutationCount, nested := inter.storageMutations[storageKey]
+			if !nested {
+				inter.storageMutations[storageKey] = mutationCount
+				defer delete(inter.storageMutations, storageKey)
+			}
+
+			iterator := storageMap.Iterator(inter)
+
+			for {
+				key, value := iterator.Next()
+				if value == nil {
+					break
+				}
+
+				inter.ReportComputation(common.ComputationKindLoop, 1)
+
+				var staticType StaticType
+				if link, ok := value.(LinkValue); ok {
+					staticType = NewCapabilityStaticType(inter, link.Type)
+				} else {
+					staticType = value.StaticType(inter)
+				}
+
+				iterationInvocation := NewInvocation(
+					inter,
+					nil,
+					[]Value{
+						NewPathValue(inter, domain, key),
+						NewTypeValue(inter, staticType),
+					},
+					argumentTypes,
+					nil,
+					getLocationRange,
+				)
+
+				result, ok := function.invoke(iterationInvocation).(BoolValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				if !result {
+					break
+				}
+
+				if inter.storageMutations[storageKey] != mutationCount {
+					panic(StorageMutatedDuringIterationError{
+						Address:       addressValue,
+						Domain:        domain,
+						LocationRange: getLocationRange(),
+					})
+				}
+			}
+
+			return NewVoidValue(inter)
+		},
+		functionType,
+	)
+}
+
 func (interpreter *Interpreter) capabilityBorrowFunction(
 	addressValue AddressValue,
 	pathValue PathValue,
@@ -4324,15 +4728,39 @@ func (interpreter *Interpreter) reportInvokedFunctionReturn(line int) {
 	interpreter.onInvokedFunctionReturn(interpreter, line)
 }
 
+func (interpreter *Interpreter) reportFunctionEntry(function *InterpretedFunctionValue) {
+	if interpreter.onFunctionEntry == nil {
+		return
+	}
+
+	interpreter.onFunctionEntry(interpreter, function)
+}
+
+func (interpreter *Interpreter) reportFunctionExit(function *InterpretedFunctionValue) {
+	if interpreter.onFunctionExit == nil {
+		return
+	}
+
+	interpreter.onFunctionExit(interpreter, function)
+}
+
+func (interpreter *Interpreter) reportBranch(element ast.Element, branch int) {
+	if interpreter.onBranch == nil {
+		return
+	}
+
+	interpreter.onBranch(interpreter, element, branch)
+}
+
 func (interpreter *Interpreter) ReportComputation(compKind common.ComputationKind, intensity uint) {
 	if interpreter.onMeterComputation != nil {
 		interpreter.onMeterComputation(compKind, intensity)
 	}
 }
 
-// getMember gets the member value by the given identifier from the given Value depending on its type.
+// GetMember gets the member value by the given identifier from the given Value depending on its type.
 // May return nil if the member does not exist.
-func (interpreter *Interpreter) getMember(self Value, getLocationRange func() LocationRange, identifier string) Value {
+func (interpreter *Interpreter) GetMember(self Value, getLocationRange func() LocationRange, identifier string) Value {
 	var result Value
 	// When the accessed value has a type that supports the declaration of members
 	// or is a built-in type that has members (`MemberAccessibleValue`),
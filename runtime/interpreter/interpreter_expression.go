@@ -20,6 +20,7 @@ package interpreter
 
 import (
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/onflow/cadence/fixedpoint"
@@ -60,7 +61,7 @@ func (interpreter *Interpreter) assignmentGetterSetter(expression ast.Expression
 //
 func (interpreter *Interpreter) identifierExpressionGetterSetter(identifierExpression *ast.IdentifierExpression) getterSetter {
 	identifier := identifierExpression.Identifier.Identifier
-	variable := interpreter.findVariable(identifier)
+	variable := interpreter.FindVariable(identifier)
 
 	return getterSetter{
 		get: func(_ bool) Value {
@@ -94,7 +95,7 @@ func (interpreter *Interpreter) indexExpressionGetterSetter(indexExpression *ast
 	indexedType := elaboration.IndexExpressionIndexedTypes[indexExpression]
 	indexingType := elaboration.IndexExpressionIndexingTypes[indexExpression]
 
-	transferredIndexingValue := interpreter.transferAndConvert(
+	transferredIndexingValue := interpreter.TransferAndConvert(
 		interpreter.evalExpression(indexExpression.IndexingExpression),
 		indexingType,
 		indexedType.IndexingType(),
@@ -147,9 +148,11 @@ func (interpreter *Interpreter) memberExpressionGetterSetter(memberExpression *a
 			if isOptional {
 				switch typedTarget := target.(type) {
 				case NilValue:
+					interpreter.reportBranch(memberExpression, 1)
 					return typedTarget
 
 				case *SomeValue:
+					interpreter.reportBranch(memberExpression, 0)
 					target = typedTarget.InnerValue(interpreter, getLocationRange)
 
 				default:
@@ -161,7 +164,7 @@ func (interpreter *Interpreter) memberExpressionGetterSetter(memberExpression *a
 			if isNestedResourceMove {
 				resultValue = target.(MemberAccessibleValue).RemoveMember(interpreter, getLocationRange, identifier)
 			} else {
-				resultValue = interpreter.getMember(target, getLocationRange, identifier)
+				resultValue = interpreter.GetMember(target, getLocationRange, identifier)
 			}
 			if resultValue == nil && !allowMissing {
 				panic(MissingMemberValueError{
@@ -195,7 +198,7 @@ func (interpreter *Interpreter) checkMemberAccess(
 	getLocationRange func() LocationRange,
 ) {
 	memberInfo := interpreter.Program.Elaboration.MemberExpressionMemberInfos[memberExpression]
-	expectedType := memberInfo.AccessedType
+	expectedType := interpreter.resolveType(memberInfo.AccessedType)
 
 	switch expectedType := expectedType.(type) {
 	case *sema.TransactionType:
@@ -229,7 +232,7 @@ func (interpreter *Interpreter) checkMemberAccess(
 
 func (interpreter *Interpreter) VisitIdentifierExpression(expression *ast.IdentifierExpression) ast.Repr {
 	name := expression.Identifier.Identifier
-	variable := interpreter.findVariable(name)
+	variable := interpreter.FindVariable(name)
 	value := variable.GetValue()
 
 	interpreter.checkInvalidatedResourceUse(value, variable, name, expression)
@@ -423,9 +426,12 @@ func (interpreter *Interpreter) VisitBinaryExpression(expression *ast.BinaryExpr
 
 		// only evaluate right-hand side if left-hand side is nil
 		if some, ok := leftValue.(*SomeValue); ok {
+			interpreter.reportBranch(expression, 0)
 			return some.InnerValue(interpreter, getLocationRange)
 		}
 
+		interpreter.reportBranch(expression, 1)
+
 		value := rightValue()
 
 		rightType := interpreter.Program.Elaboration.BinaryExpressionRightTypes[expression]
@@ -647,11 +653,98 @@ func (interpreter *Interpreter) VisitStringExpression(expression *ast.StringExpr
 	return NewUnmeteredStringValue(expression.Value)
 }
 
+var _ ast.StringTemplateExpressionVisitor = &Interpreter{}
+
+func (interpreter *Interpreter) VisitStringTemplateExpression(expression *ast.StringTemplateExpression) ast.Repr {
+
+	interpolatedValues := interpreter.visitExpressionsNonCopying(expression.Expressions)
+
+	return interpreter.NewStringTemplateValue(expression.Values, interpolatedValues)
+}
+
+// NewStringTemplateValue returns the string resulting from interpolating
+// the given values between the given string parts.
+//
+func (interpreter *Interpreter) NewStringTemplateValue(values []string, interpolatedValues []Value) *StringValue {
+
+	// Over-estimate the length of the resulting string,
+	// so the memory usage can be metered before the string is built
+
+	length := 0
+	for _, value := range values {
+		length = safeAdd(length, len(value))
+	}
+	for _, value := range interpolatedValues {
+		length = safeAdd(length, interpreter.overEstimateStringTemplateValueLength(value))
+	}
+
+	memoryUsage := common.NewStringMemoryUsage(length)
+
+	return NewStringValue(
+		interpreter,
+		memoryUsage,
+		func() string {
+			var builder strings.Builder
+			for i, value := range values {
+				builder.WriteString(value)
+				if i < len(interpolatedValues) {
+					builder.WriteString(stringTemplateValueString(interpolatedValues[i]))
+				}
+			}
+			return builder.String()
+		},
+	)
+}
+
+// overEstimateStringTemplateValueLength returns an over-estimation
+// of the length of the string representation of the given interpolated value
+//
+func (interpreter *Interpreter) overEstimateStringTemplateValueLength(value Value) int {
+	switch value := value.(type) {
+	case *StringValue:
+		return len(value.Str)
+	case CharacterValue:
+		return len(value)
+	case NumberValue:
+		return OverEstimateNumberStringLength(interpreter, value)
+	case AddressValue:
+		// 0x prefix and two hex digits per byte
+		return 2 + safeMul(common.AddressLength, 2)
+	case PathValue:
+		// leading slash and separator between domain and identifier
+		return 2 + safeAdd(len(value.Domain.Identifier()), len(value.Identifier))
+	default:
+		panic(errors.NewUnreachableError())
+	}
+}
+
+// stringTemplateValueString returns the string representation of the given interpolated value,
+// i.e. the result of its `toString` function
+//
+func stringTemplateValueString(value Value) string {
+	switch value := value.(type) {
+	case *StringValue:
+		return value.Str
+	case CharacterValue:
+		return string(value)
+	case NumberValue:
+		return value.String()
+	case AddressValue:
+		return value.String()
+	case PathValue:
+		return value.String()
+	default:
+		panic(errors.NewUnreachableError())
+	}
+}
+
 func (interpreter *Interpreter) VisitArrayExpression(expression *ast.ArrayExpression) ast.Repr {
 	values := interpreter.visitExpressionsNonCopying(expression.Values)
 
 	argumentTypes := interpreter.Program.Elaboration.ArrayExpressionArgumentTypes[expression]
-	arrayType := interpreter.Program.Elaboration.ArrayExpressionArrayType[expression]
+	arrayType := interpreter.resolveType(
+		interpreter.Program.Elaboration.ArrayExpressionArrayType[expression],
+	).(sema.ArrayType)
 	elementType := arrayType.ElementType(false)
 
 	copies := make([]Value, len(values))
@@ -659,7 +752,7 @@ func (interpreter *Interpreter) VisitArrayExpression(expression *ast.ArrayExpres
 		argumentType := argumentTypes[i]
 		argumentExpression := expression.Values[i]
 		getLocationRange := locationRangeGetter(interpreter, interpreter.Location, argumentExpression)
-		copies[i] = interpreter.transferAndConvert(argument, argumentType, elementType, getLocationRange)
+		copies[i] = interpreter.TransferAndConvert(argument, argumentType, elementType, getLocationRange)
 	}
 
 	// TODO: cache
@@ -680,7 +773,9 @@ func (interpreter *Interpreter) VisitDictionaryExpression(expression *ast.Dictio
 	values := interpreter.visitEntries(expression.Entries)
 
 	entryTypes := interpreter.Program.Elaboration.DictionaryExpressionEntryTypes[expression]
-	dictionaryType := interpreter.Program.Elaboration.DictionaryExpressionType[expression]
+	dictionaryType := interpreter.resolveType(
+		interpreter.Program.Elaboration.DictionaryExpressionType[expression],
+	).(*sema.DictionaryType)
 
 	var keyValuePairs []Value
 
@@ -688,14 +783,14 @@ func (interpreter *Interpreter) VisitDictionaryExpression(expression *ast.Dictio
 		entryType := entryTypes[i]
 		entry := expression.Entries[i]
 
-		key := interpreter.transferAndConvert(
+		key := interpreter.TransferAndConvert(
 			dictionaryEntryValues.Key,
 			entryType.KeyType,
 			dictionaryType.KeyType,
 			locationRangeGetter(interpreter, interpreter.Location, entry.Key),
 		)
 
-		value := interpreter.transferAndConvert(
+		value := interpreter.TransferAndConvert(
 			dictionaryEntryValues.Value,
 			entryType.ValueType,
 			dictionaryType.ValueType,
@@ -742,8 +837,10 @@ func (interpreter *Interpreter) VisitConditionalExpression(expression *ast.Condi
 		panic(errors.NewUnreachableError())
 	}
 	if value {
+		interpreter.reportBranch(expression, 0)
 		return interpreter.evalExpression(expression.Then)
 	} else {
+		interpreter.reportBranch(expression, 1)
 		return interpreter.evalExpression(expression.Else)
 	}
 }
@@ -806,7 +903,9 @@ func (interpreter *Interpreter) VisitInvocationExpression(invocationExpression *
 
 	elaboration := interpreter.Program.Elaboration
 
-	typeParameterTypes := elaboration.InvocationExpressionTypeArguments[invocationExpression]
+	typeParameterTypes := interpreter.resolveTypeArguments(
+		elaboration.InvocationExpressionTypeArguments[invocationExpression],
+	)
 	argumentTypes := elaboration.InvocationExpressionArgumentTypes[invocationExpression]
 	parameterTypes := elaboration.InvocationExpressionParameterTypes[invocationExpression]
 
@@ -870,7 +969,9 @@ func (interpreter *Interpreter) VisitFunctionExpression(expression *ast.Function
 	// lexical scope: variables in functions are bound to what is visible at declaration time
 	lexicalScope := interpreter.activations.CurrentOrNew()
 
-	functionType := interpreter.Program.Elaboration.FunctionExpressionFunctionType[expression]
+	functionType := interpreter.resolveFunctionType(
+		interpreter.Program.Elaboration.FunctionExpressionFunctionType[expression],
+	)
 
 	var preConditions ast.Conditions
 	if expression.FunctionBlock.PreConditions != nil {
@@ -892,6 +993,7 @@ func (interpreter *Interpreter) VisitFunctionExpression(expression *ast.Function
 
 	return NewInterpretedFunctionValue(
 		interpreter,
+		expression,
 		expression.ParameterList,
 		functionType,
 		lexicalScope,
@@ -907,7 +1009,9 @@ func (interpreter *Interpreter) VisitCastingExpression(expression *ast.CastingEx
 
 	getLocationRange := locationRangeGetter(interpreter, interpreter.Location, expression.Expression)
 
-	expectedType := interpreter.Program.Elaboration.CastingTargetTypes[expression]
+	expectedType := interpreter.resolveType(
+		interpreter.Program.Elaboration.CastingTargetTypes[expression],
+	)
 
 	switch expression.Operation {
 	case ast.OperationFailableCast, ast.OperationForceCast:
@@ -968,10 +1072,31 @@ func (interpreter *Interpreter) VisitDestroyExpression(expression *ast.DestroyEx
 
 func (interpreter *Interpreter) VisitReferenceExpression(referenceExpression *ast.ReferenceExpression) ast.Repr {
 
-	borrowType := interpreter.Program.Elaboration.ReferenceExpressionBorrowTypes[referenceExpression]
+	borrowType := interpreter.resolveType(
+		interpreter.Program.Elaboration.ReferenceExpressionBorrowTypes[referenceExpression],
+	)
 
 	result := interpreter.evalExpression(referenceExpression.Expression)
 
+	return interpreter.NewReferenceValue(
+		locationRangeGetter(interpreter, interpreter.Location, referenceExpression.Expression),
+		locationRangeGetter(interpreter, interpreter.Location, referenceExpression),
+		result,
+		borrowType,
+	)
+}
+
+// NewReferenceValue returns a reference to the given value with the given borrow type.
+//
+// If the borrow type is optional, references to optionals are transformed into optional references.
+//
+func (interpreter *Interpreter) NewReferenceValue(
+	getValueLocationRange func() LocationRange,
+	getLocationRange func() LocationRange,
+	result Value,
+	borrowType sema.Type,
+) Value {
+
 	if result, ok := result.(ReferenceTrackedResourceKindedValue); ok {
 		interpreter.trackReferencedResourceKindedValue(result.StorageID(), result)
 	}
@@ -989,9 +1114,7 @@ func (interpreter *Interpreter) VisitReferenceExpression(referenceExpression *as
 			// References to optionals are transformed into optional references,
 			// so move the *SomeValue out to the reference itself
 
-			getLocationRange := locationRangeGetter(interpreter, interpreter.Location, referenceExpression.Expression)
-
-			innerValue := result.InnerValue(interpreter, getLocationRange)
+			innerValue := result.InnerValue(interpreter, getValueLocationRange)
 			if result, ok := innerValue.(ReferenceTrackedResourceKindedValue); ok {
 				interpreter.trackReferencedResourceKindedValue(result.StorageID(), result)
 			}
@@ -1014,8 +1137,6 @@ func (interpreter *Interpreter) VisitReferenceExpression(referenceExpression *as
 			// but the target type is optional,
 			// then box the reference properly
 
-			getLocationRange := locationRangeGetter(interpreter, interpreter.Location, referenceExpression)
-
 			return interpreter.BoxOptional(
 				getLocationRange,
 				NewEphemeralReferenceValue(
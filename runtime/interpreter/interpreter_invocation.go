@@ -79,7 +79,7 @@ func (interpreter *Interpreter) invokeFunctionValue(
 
 		if i < parameterTypeCount {
 			parameterType := parameterTypes[i]
-			transferredArguments[i] = interpreter.transferAndConvert(
+			transferredArguments[i] = interpreter.TransferAndConvert(
 				argument,
 				argumentType,
 				parameterType,
@@ -110,16 +110,61 @@ func (interpreter *Interpreter) invokeFunctionValue(
 	return function.invoke(invocation)
 }
 
+// enterFunctionInvocation records the start of a function invocation,
+// and aborts if the call stack depth limit is exceeded.
+//
+// Every call must be paired with a call of exitFunctionInvocation,
+// which must be deferred *before* calling this function,
+// so the depth is also restored when the invocation fails.
+//
+func (interpreter *Interpreter) enterFunctionInvocation(getLocationRange func() LocationRange) {
+	callStack := interpreter.CallStack
+	callStack.depth++
+
+	limit := interpreter.callStackDepthLimit
+	if limit > 0 && callStack.depth > limit {
+		panic(CallStackLimitExceededError{
+			Limit:         limit,
+			LocationRange: getLocationRange(),
+		})
+	}
+}
+
+// exitFunctionInvocation records the end of a function invocation.
+//
+func (interpreter *Interpreter) exitFunctionInvocation() {
+	interpreter.CallStack.depth--
+}
+
 func (interpreter *Interpreter) invokeInterpretedFunction(
 	function *InterpretedFunctionValue,
 	invocation Invocation,
 ) Value {
 
+	defer interpreter.exitFunctionInvocation()
+	interpreter.enterFunctionInvocation(invocation.GetLocationRange)
+
+	if interpreter.debugger != nil {
+		interpreter.debugger.enterFunction(interpreter, function)
+		defer interpreter.debugger.exitFunction()
+	}
+
 	// Start a new activation record.
 	// Lexical scope: use the function declaration's activation record,
 	// not the current one (which would be dynamic scope)
-	interpreter.activations.PushNewWithParent(function.Activation)
-	interpreter.activations.Current().isFunction = true
+	activation := interpreter.activations.PushNewWithParent(function.Activation)
+	activation.isFunction = true
+
+	// Make the type arguments of user-defined generic functions
+	// and of the instances of user-defined generic composites available
+
+	if compositeValue, ok := invocation.Self.(*CompositeValue); ok &&
+		len(compositeValue.TypeArguments) > 0 {
+
+		activation.SetTypeArguments(interpreter.compositeTypeArguments(compositeValue))
+	}
+
+	activation.SetTypeArguments(invocation.TypeParameterTypes)
 
 	interpreter.CallStack.Push(invocation)
 
@@ -150,6 +195,14 @@ func (interpreter *Interpreter) invokeInterpretedFunctionActivated(
 		interpreter.bindParameterArguments(function.ParameterList, arguments)
 	}
 
+	interpreter.reportFunctionEntry(function)
+
+	// Only defer the report if there is a handler,
+	// as deferring has a cost for every invocation
+	if interpreter.onFunctionExit != nil {
+		defer interpreter.reportFunctionExit(function)
+	}
+
 	return interpreter.visitFunctionBody(
 		function.BeforeStatements,
 		function.PreConditions,
@@ -157,10 +210,80 @@ func (interpreter *Interpreter) invokeInterpretedFunctionActivated(
 			return interpreter.visitStatements(function.Statements)
 		},
 		function.PostConditions,
-		function.Type.ReturnTypeAnnotation.Type,
+		interpreter.resolveType(function.Type.ReturnTypeAnnotation.Type),
 	)
 }
 
+// compositeTypeArguments returns the type arguments of the given instance
+// of a user-defined generic composite type, by type parameter.
+//
+func (interpreter *Interpreter) compositeTypeArguments(value *CompositeValue) *sema.TypeParameterTypeOrderedMap {
+	compositeType, ok := interpreter.MustConvertStaticToSemaType(value.StaticType(interpreter)).(*sema.CompositeType)
+	if !ok {
+		return nil
+	}
+
+	baseType, ok := compositeType.BaseType().(*sema.CompositeType)
+	if !ok {
+		return nil
+	}
+
+	typeArguments := &sema.TypeParameterTypeOrderedMap{}
+	for i, typeArgument := range compositeType.TypeArguments() {
+		typeArguments.Set(baseType.TypeParameters()[i], typeArgument)
+	}
+
+	return typeArguments
+}
+
+// resolveType substitutes the type parameters of the invoked
+// user-defined generic functions and composites in the given type
+// with the type arguments of the invocations.
+//
+func (interpreter *Interpreter) resolveType(ty sema.Type) sema.Type {
+	typeArguments := interpreter.activations.Current().TypeArguments()
+	if typeArguments == nil {
+		return ty
+	}
+
+	resolvedType := ty.Resolve(typeArguments)
+	if resolvedType == nil {
+		return ty
+	}
+	return resolvedType
+}
+
+// resolveFunctionType resolves the given function type, see resolveType.
+//
+// Function values of functions declared in user-defined generic functions and composites
+// must have the resolved type, as they may be transferred to variables and returned.
+//
+func (interpreter *Interpreter) resolveFunctionType(functionType *sema.FunctionType) *sema.FunctionType {
+	resolvedType, ok := interpreter.resolveType(functionType).(*sema.FunctionType)
+	if !ok {
+		return functionType
+	}
+	return resolvedType
+}
+
+// resolveTypeArguments resolves the given type arguments, see resolveType.
+//
+func (interpreter *Interpreter) resolveTypeArguments(
+	typeArguments *sema.TypeParameterTypeOrderedMap,
+) *sema.TypeParameterTypeOrderedMap {
+	if typeArguments == nil ||
+		interpreter.activations.Current().TypeArguments() == nil {
+
+		return typeArguments
+	}
+
+	resolvedTypeArguments := &sema.TypeParameterTypeOrderedMap{}
+	typeArguments.Foreach(func(typeParameter *sema.TypeParameter, ty sema.Type) {
+		resolvedTypeArguments.Set(typeParameter, interpreter.resolveType(ty))
+	})
+	return resolvedTypeArguments
+}
+
 // bindParameterArguments binds the argument values to the given parameters
 //
 func (interpreter *Interpreter) bindParameterArguments(
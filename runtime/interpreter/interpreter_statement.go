@@ -79,7 +79,7 @@ func (interpreter *Interpreter) VisitReturnStatement(statement *ast.ReturnStatem
 		getLocationRange := locationRangeGetter(interpreter, interpreter.Location, statement.Expression)
 
 		// NOTE: copy on return
-		value = interpreter.transferAndConvert(value, valueType, returnType, getLocationRange)
+		value = interpreter.TransferAndConvert(value, valueType, returnType, getLocationRange)
 	}
 
 	return functionReturn{value}
@@ -96,15 +96,16 @@ func (interpreter *Interpreter) VisitContinueStatement(_ *ast.ContinueStatement)
 func (interpreter *Interpreter) VisitIfStatement(statement *ast.IfStatement) ast.Repr {
 	switch test := statement.Test.(type) {
 	case ast.Expression:
-		return interpreter.visitIfStatementWithTestExpression(test, statement.Then, statement.Else)
+		return interpreter.visitIfStatementWithTestExpression(statement, test, statement.Then, statement.Else)
 	case *ast.VariableDeclaration:
-		return interpreter.visitIfStatementWithVariableDeclaration(test, statement.Then, statement.Else)
+		return interpreter.visitIfStatementWithVariableDeclaration(statement, test, statement.Then, statement.Else)
 	default:
 		panic(errors.NewUnreachableError())
 	}
 }
 
 func (interpreter *Interpreter) visitIfStatementWithTestExpression(
+	statement *ast.IfStatement,
 	test ast.Expression,
 	thenBlock, elseBlock *ast.Block,
 ) controlReturn {
@@ -115,9 +116,13 @@ func (interpreter *Interpreter) visitIfStatementWithTestExpression(
 	}
 	var result any
 	if value {
+		interpreter.reportBranch(statement, 0)
 		result = thenBlock.Accept(interpreter)
-	} else if elseBlock != nil {
-		result = elseBlock.Accept(interpreter)
+	} else {
+		interpreter.reportBranch(statement, 1)
+		if elseBlock != nil {
+			result = elseBlock.Accept(interpreter)
+		}
 	}
 
 	if ret, ok := result.(controlReturn); ok {
@@ -127,6 +132,7 @@ func (interpreter *Interpreter) visitIfStatementWithTestExpression(
 }
 
 func (interpreter *Interpreter) visitIfStatementWithVariableDeclaration(
+	statement *ast.IfStatement,
 	declaration *ast.VariableDeclaration,
 	thenBlock, elseBlock *ast.Block,
 ) controlReturn {
@@ -167,10 +173,12 @@ func (interpreter *Interpreter) visitIfStatementWithVariableDeclaration(
 	var result any
 	if someValue, ok := value.(*SomeValue); ok {
 
+		interpreter.reportBranch(statement, 0)
+
 		targetType := interpreter.Program.Elaboration.VariableDeclarationTargetTypes[declaration]
 		getLocationRange := locationRangeGetter(interpreter, interpreter.Location, declaration.Value)
 		innerValue := someValue.InnerValue(interpreter, getLocationRange)
-		transferredUnwrappedValue := interpreter.transferAndConvert(
+		transferredUnwrappedValue := interpreter.TransferAndConvert(
 			innerValue,
 			valueType,
 			targetType,
@@ -189,8 +197,11 @@ func (interpreter *Interpreter) visitIfStatementWithVariableDeclaration(
 		)
 
 		result = thenBlock.Accept(interpreter)
-	} else if elseBlock != nil {
-		result = elseBlock.Accept(interpreter)
+	} else {
+		interpreter.reportBranch(statement, 1)
+		if elseBlock != nil {
+			result = elseBlock.Accept(interpreter)
+		}
 	}
 
 	if ret, ok := result.(controlReturn); ok {
@@ -206,9 +217,14 @@ func (interpreter *Interpreter) VisitSwitchStatement(switchStatement *ast.Switch
 		panic(errors.NewUnreachableError())
 	}
 
-	for _, switchCase := range switchStatement.Cases {
+	for i, switchCase := range switchStatement.Cases {
+
+		// NOTE: bind the index of the case for the closure
+		caseIndex := i
 
 		runStatements := func() ast.Repr {
+			interpreter.reportBranch(switchStatement, caseIndex)
+
 			// NOTE: the new block ensures that a new scope is introduced
 
 			block := ast.NewBlock(
@@ -257,6 +273,8 @@ func (interpreter *Interpreter) VisitSwitchStatement(switchStatement *ast.Switch
 		// then try the next case
 	}
 
+	interpreter.reportBranch(switchStatement, len(switchStatement.Cases))
+
 	return nil
 }
 
@@ -390,6 +408,11 @@ func (interpreter *Interpreter) VisitPragmaDeclaration(_ *ast.PragmaDeclaration)
 	return nil
 }
 
+func (interpreter *Interpreter) VisitTypeAliasDeclaration(_ *ast.TypeAliasDeclaration) ast.Repr {
+	// Type aliases are fully resolved by the checker
+	return nil
+}
+
 // VisitVariableDeclaration first visits the declaration's value,
 // then declares the variable with the name bound to the value
 func (interpreter *Interpreter) VisitVariableDeclaration(declaration *ast.VariableDeclaration) ast.Repr {
@@ -443,7 +466,7 @@ func (interpreter *Interpreter) visitVariableDeclaration(
 
 	getLocationRange := locationRangeGetter(interpreter, interpreter.Location, declaration.Value)
 
-	transferredValue := interpreter.transferAndConvert(result, valueType, targetType, getLocationRange)
+	transferredValue := interpreter.TransferAndConvert(result, valueType, targetType, getLocationRange)
 
 	valueCallback(
 		declaration.Identifier.Identifier,
@@ -502,10 +525,10 @@ func (interpreter *Interpreter) VisitSwapStatement(swap *ast.SwapStatement) ast.
 	// and left value to right target
 
 	getLocationRange := locationRangeGetter(interpreter, interpreter.Location, swap.Right)
-	transferredRightValue := interpreter.transferAndConvert(rightValue, rightType, leftType, getLocationRange)
+	transferredRightValue := interpreter.TransferAndConvert(rightValue, rightType, leftType, getLocationRange)
 
 	getLocationRange = locationRangeGetter(interpreter, interpreter.Location, swap.Left)
-	transferredLeftValue := interpreter.transferAndConvert(leftValue, leftType, rightType, getLocationRange)
+	transferredLeftValue := interpreter.TransferAndConvert(leftValue, leftType, rightType, getLocationRange)
 
 	leftGetterSetter.set(transferredRightValue)
 	rightGetterSetter.set(transferredLeftValue)
@@ -58,6 +58,16 @@ func NewInvocation(
 //
 type CallStack struct {
 	Invocations []Invocation
+	// depth is the number of active function invocations.
+	// Unlike Invocations, it also includes invocations of host functions
+	depth uint64
+}
+
+// Depth returns the number of active function invocations,
+// including invocations of host functions.
+//
+func (i *CallStack) Depth() uint64 {
+	return i.depth
 }
 
 func (i *CallStack) Push(invocation Invocation) {
@@ -0,0 +1,249 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// ProgramEncodingVersion is the version of the encoding of checked programs.
+//
+// NOTE: Increment the version when the encoding of programs, elements, or elaborations changes,
+// e.g. when fields are added to elements, or to the elaboration.
+// Programs encoded with a different version are rejected when decoding, see DecodeProgram.
+//
+// Versions:
+//   - 1: Initial encoding
+//   - 2: Type parameters of generic functions and composites,
+//     and type arguments of instantiated generic composites
+//
+const ProgramEncodingVersion = 2
+
+// encodedProgramLength is the number of elements of an encoded program
+//
+const encodedProgramLength = 5
+
+// ProgramCodeHash returns the hash of the given program code.
+//
+// The hash is part of an encoded program, and is used to detect
+// if the encoded program is outdated, i.e. if the program code changed.
+//
+func ProgramCodeHash(code []byte) [32]byte {
+	return sha3.Sum256(code)
+}
+
+// EncodedProgramHeader is the header of an encoded program.
+//
+type EncodedProgramHeader struct {
+	Version  uint64
+	CodeHash [32]byte
+	Location common.Location
+}
+
+// ProgramEncodingVersionMismatchError is returned when decoding an encoded program
+// which has a different version than the current encoding version.
+//
+type ProgramEncodingVersionMismatchError struct {
+	Version uint64
+}
+
+var _ errors.InternalError = ProgramEncodingVersionMismatchError{}
+
+func (ProgramEncodingVersionMismatchError) IsInternalError() {}
+
+func (e ProgramEncodingVersionMismatchError) Error() string {
+	return fmt.Sprintf(
+		"encoded program has version %d, expected version %d",
+		e.Version,
+		ProgramEncodingVersion,
+	)
+}
+
+// ProgramCodeHashMismatchError is returned when decoding an encoded program
+// which was encoded for different program code.
+//
+type ProgramCodeHashMismatchError struct {
+	Location common.Location
+}
+
+var _ errors.InternalError = ProgramCodeHashMismatchError{}
+
+func (ProgramCodeHashMismatchError) IsInternalError() {}
+
+func (e ProgramCodeHashMismatchError) Error() string {
+	return fmt.Sprintf(
+		"encoded program of %s is outdated: code hash mismatch",
+		e.Location,
+	)
+}
+
+// EncodeProgram encodes the given checked program, which has the given location and code,
+// so it can be stored, e.g. on disk, and later be decoded without parsing and checking it again.
+//
+// The encoding consists of the encoding version, the hash of the program code, the location,
+// the program's elements, and the program's elaboration, see sema.EncodeElaboration.
+//
+func EncodeProgram(program *Program, location common.Location, code []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	encoder := ast.CBOREncMode.NewStreamEncoder(&buffer)
+	elementEncoder := ast.NewEncoder(encoder)
+
+	err := encoder.EncodeArrayHead(encodedProgramLength)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.EncodeUint64(ProgramEncodingVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHash := ProgramCodeHash(code)
+	err = encoder.EncodeBytes(codeHash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	err = elementEncoder.Encode(&location)
+	if err != nil {
+		return nil, err
+	}
+
+	err = elementEncoder.Encode(&program.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sema.EncodeElaboration(encoder, elementEncoder, location, program.Elaboration)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// DecodeProgramHeader decodes the header of the given encoded program,
+// e.g. to determine if it is outdated without decoding it.
+//
+func DecodeProgramHeader(data []byte) (EncodedProgramHeader, error) {
+	decoder := ast.CBORDecMode.NewByteStreamDecoder(data)
+	return decodeProgramHeader(decoder, ast.NewDecoder(decoder))
+}
+
+func decodeProgramHeader(decoder *cbor.StreamDecoder, elementDecoder *ast.Decoder) (EncodedProgramHeader, error) {
+	var header EncodedProgramHeader
+
+	length, err := decoder.DecodeArrayHead()
+	if err != nil {
+		return header, err
+	}
+	if length != encodedProgramLength {
+		return header, errors.NewUnexpectedError(
+			"invalid program encoding: expected %d elements, got %d",
+			encodedProgramLength,
+			length,
+		)
+	}
+
+	header.Version, err = decoder.DecodeUint64()
+	if err != nil {
+		return header, err
+	}
+
+	codeHash, err := decoder.DecodeBytes()
+	if err != nil {
+		return header, err
+	}
+	if len(codeHash) != len(header.CodeHash) {
+		return header, errors.NewUnexpectedError(
+			"invalid program encoding: invalid code hash length %d",
+			len(codeHash),
+		)
+	}
+	copy(header.CodeHash[:], codeHash)
+
+	err = elementDecoder.Decode(&header.Location)
+	if err != nil {
+		return header, err
+	}
+
+	return header, nil
+}
+
+// DecodeProgram decodes a program encoded by EncodeProgram.
+//
+// The given code must be the current code of the program.
+// If the encoded program was encoded for different code, a ProgramCodeHashMismatchError is returned,
+// and if it was encoded with a different encoding version, a ProgramEncodingVersionMismatchError is returned.
+// In both cases, the program must be parsed and checked again.
+//
+// Types declared in other programs, e.g. imported contracts, are resolved using the given resolver.
+//
+func DecodeProgram(data []byte, code []byte, resolve sema.ElaborationResolver) (*Program, error) {
+	decoder := ast.CBORDecMode.NewByteStreamDecoder(data)
+	elementDecoder := ast.NewDecoder(decoder)
+
+	header, err := decodeProgramHeader(decoder, elementDecoder)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Version != ProgramEncodingVersion {
+		return nil, ProgramEncodingVersionMismatchError{
+			Version: header.Version,
+		}
+	}
+
+	if header.CodeHash != ProgramCodeHash(code) {
+		return nil, ProgramCodeHashMismatchError{
+			Location: header.Location,
+		}
+	}
+
+	var program *ast.Program
+	err = elementDecoder.Decode(&program)
+	if err != nil {
+		return nil, err
+	}
+
+	elaboration, err := sema.DecodeElaboration(decoder, elementDecoder, header.Location, resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		Program:     program,
+		Elaboration: elaboration,
+	}, nil
+}
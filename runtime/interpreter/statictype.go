@@ -55,7 +55,12 @@ type StaticType interface {
 type CompositeStaticType struct {
 	Location            common.Location
 	QualifiedIdentifier string
-	TypeID              common.TypeID
+	// TypeID is the type ID of the composite type,
+	// without the type arguments, if any
+	TypeID common.TypeID
+	// TypeArguments are the type arguments of an instantiated
+	// user-defined generic composite type, if any
+	TypeArguments []StaticType
 }
 
 var _ StaticType = CompositeStaticType{}
@@ -92,10 +97,12 @@ func (CompositeStaticType) elementSize() uint {
 }
 
 func (t CompositeStaticType) String() string {
-	if t.Location == nil {
-		return t.QualifiedIdentifier
+	typeArguments := make([]string, len(t.TypeArguments))
+	for i, typeArgument := range t.TypeArguments {
+		typeArguments[i] = typeArgument.String()
 	}
-	return string(t.TypeID)
+
+	return t.format(typeArguments)
 }
 
 func (t CompositeStaticType) MeteredString(memoryGauge common.MemoryGauge) string {
@@ -106,8 +113,33 @@ func (t CompositeStaticType) MeteredString(memoryGauge common.MemoryGauge) strin
 		amount = len(t.TypeID)
 	}
 
+	typeArguments := make([]string, len(t.TypeArguments))
+	if len(typeArguments) > 0 {
+		// Angle brackets and separators
+		amount += len(typeArguments) * 2
+
+		for i, typeArgument := range t.TypeArguments {
+			typeArguments[i] = typeArgument.MeteredString(memoryGauge)
+		}
+	}
+
 	common.UseMemory(memoryGauge, common.NewRawStringMemoryUsage(amount))
-	return t.String()
+	return t.format(typeArguments)
+}
+
+func (t CompositeStaticType) format(typeArguments []string) string {
+	var name string
+	if t.Location == nil {
+		name = t.QualifiedIdentifier
+	} else {
+		name = string(t.TypeID)
+	}
+
+	if len(typeArguments) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s<%s>", name, strings.Join(typeArguments, ", "))
 }
 
 func (t CompositeStaticType) Equal(other StaticType) bool {
@@ -116,7 +148,19 @@ func (t CompositeStaticType) Equal(other StaticType) bool {
 		return false
 	}
 
-	return otherCompositeType.TypeID == t.TypeID
+	if otherCompositeType.TypeID != t.TypeID ||
+		len(otherCompositeType.TypeArguments) != len(t.TypeArguments) {
+
+		return false
+	}
+
+	for i, typeArgument := range t.TypeArguments {
+		if !typeArgument.Equal(otherCompositeType.TypeArguments[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // InterfaceStaticType
@@ -601,7 +645,7 @@ func (t CapabilityStaticType) Equal(other StaticType) bool {
 func ConvertSemaToStaticType(memoryGauge common.MemoryGauge, t sema.Type) StaticType {
 	switch t := t.(type) {
 	case *sema.CompositeType:
-		return NewCompositeStaticType(memoryGauge, t.Location, t.QualifiedIdentifier(), t.ID())
+		return ConvertSemaCompositeTypeToStaticCompositeType(memoryGauge, t)
 
 	case *sema.InterfaceType:
 		return ConvertSemaInterfaceTypeToStaticInterfaceType(memoryGauge, t)
@@ -652,6 +696,35 @@ func ConvertSemaToStaticType(memoryGauge common.MemoryGauge, t sema.Type) Static
 	return primitiveStaticType
 }
 
+func ConvertSemaCompositeTypeToStaticCompositeType(
+	memoryGauge common.MemoryGauge,
+	t *sema.CompositeType,
+) CompositeStaticType {
+	baseType, ok := t.BaseType().(*sema.CompositeType)
+	if !ok {
+		return NewCompositeStaticType(memoryGauge, t.Location, t.QualifiedIdentifier(), t.ID())
+	}
+
+	// Instantiated user-defined generic composite type:
+	// Refer to the generic composite type by ID,
+	// and keep the type arguments
+
+	staticType := NewCompositeStaticType(
+		memoryGauge,
+		baseType.Location,
+		baseType.QualifiedIdentifier(),
+		baseType.ID(),
+	)
+
+	typeArguments := t.TypeArguments()
+	staticType.TypeArguments = make([]StaticType, len(typeArguments))
+	for i, typeArgument := range typeArguments {
+		staticType.TypeArguments[i] = ConvertSemaToStaticType(memoryGauge, typeArgument)
+	}
+
+	return staticType
+}
+
 func ConvertSemaArrayTypeToStaticArrayType(
 	memoryGauge common.MemoryGauge,
 	t sema.ArrayType,
@@ -711,7 +784,29 @@ func ConvertStaticToSemaType(
 ) (_ sema.Type, err error) {
 	switch t := typ.(type) {
 	case CompositeStaticType:
-		return getComposite(t.Location, t.QualifiedIdentifier, t.TypeID)
+		compositeType, err := getComposite(t.Location, t.QualifiedIdentifier, t.TypeID)
+		if err != nil || len(t.TypeArguments) == 0 {
+			return compositeType, err
+		}
+
+		typeArguments := make([]sema.Type, len(t.TypeArguments))
+		for i, typeArgument := range t.TypeArguments {
+			typeArguments[i], err = ConvertStaticToSemaType(memoryGauge, typeArgument, getInterface, getComposite)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		instantiation, ok := compositeType.Instantiate(typeArguments, nil).(*sema.CompositeType)
+		if !ok {
+			return nil, errors.NewUnexpectedError(
+				"invalid type arguments for composite type %s",
+				compositeType.QualifiedString(),
+			)
+		}
+		instantiation.InitializeInstantiatedMembers()
+
+		return instantiation, nil
 
 	case InterfaceStaticType:
 		return getInterface(t.Location, t.QualifiedIdentifier)
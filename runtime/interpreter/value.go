@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
@@ -49,7 +50,9 @@ type TypeConformanceResults map[typeConformanceResultEntry]bool
 
 type typeConformanceResultEntry struct {
 	EphemeralReferenceValue *EphemeralReferenceValue
-	EphemeralReferenceType  StaticType
+	// EphemeralReferenceType is the string representation of the referenced static type.
+	// Static types are not necessarily hashable, e.g. composite static types with type arguments
+	EphemeralReferenceType string
 }
 
 // SeenReferences is a set of seen references.
@@ -320,7 +323,7 @@ func (v TypeValue) Equal(_ *Interpreter, _ func() LocationRange, other Value) bo
 	return staticType.Equal(otherStaticType)
 }
 
-func (v TypeValue) GetMember(interpreter *Interpreter, _ func() LocationRange, name string) Value {
+func (v TypeValue) GetMember(interpreter *Interpreter, getLocationRange func() LocationRange, name string) Value {
 	switch name {
 	case "identifier":
 		var typeID string
@@ -361,11 +364,172 @@ func (v TypeValue) GetMember(interpreter *Interpreter, _ func() LocationRange, n
 			},
 			sema.MetaTypeIsSubtypeFunctionType,
 		)
+
+	case "fields":
+		return v.reflectFields(interpreter, getLocationRange)
+
+	case "functions":
+		return v.reflectFunctions(interpreter, getLocationRange)
+
+	case "conformances":
+		return v.reflectConformances(interpreter, getLocationRange)
 	}
 
 	return nil
 }
 
+var metaTypeFieldsStaticType = ConvertSemaDictionaryTypeToStaticDictionaryType(nil, sema.MetaTypeFieldsType)
+
+var metaTypeFunctionsStaticType = ConvertSemaDictionaryTypeToStaticDictionaryType(nil, sema.MetaTypeFunctionsType)
+
+var metaTypeConformancesStaticType = ConvertSemaArrayTypeToStaticArrayType(nil, sema.MetaTypeConformancesType)
+
+// foreachPublicMember calls the given function for each public member
+// of the given declaration kind, if the type is a composite or interface type.
+//
+// Private, contract-, and account-accessible members are never exposed.
+//
+func (v TypeValue) foreachPublicMember(
+	interpreter *Interpreter,
+	declarationKind common.DeclarationKind,
+	f func(name string, member *sema.Member),
+) {
+	if v.Type == nil {
+		return
+	}
+
+	var members *sema.StringMemberOrderedMap
+
+	switch semaType := interpreter.MustConvertStaticToSemaType(v.Type).(type) {
+	case *sema.CompositeType:
+		semaType.InitializeInstantiatedMembers()
+		members = semaType.Members
+
+	case *sema.InterfaceType:
+		members = semaType.Members
+
+	default:
+		return
+	}
+
+	members.Foreach(func(name string, member *sema.Member) {
+		if member.DeclarationKind != declarationKind {
+			return
+		}
+
+		switch member.Access {
+		case ast.AccessPublic, ast.AccessPublicSettable:
+			f(name, member)
+		}
+	})
+}
+
+func newReflectedStringValue(interpreter *Interpreter, name string) *StringValue {
+	return NewStringValue(
+		interpreter,
+		common.NewStringMemoryUsage(len(name)),
+		func() string {
+			return name
+		},
+	)
+}
+
+// reflectFields returns a dictionary of the public fields of the type,
+// mapping field names to field types.
+//
+func (v TypeValue) reflectFields(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+) Value {
+
+	var keysAndValues []Value
+
+	v.foreachPublicMember(
+		interpreter,
+		common.DeclarationKindField,
+		func(name string, member *sema.Member) {
+			fieldType := ConvertSemaToStaticType(interpreter, member.TypeAnnotation.Type)
+
+			keysAndValues = append(
+				keysAndValues,
+				newReflectedStringValue(interpreter, name),
+				NewTypeValue(interpreter, fieldType),
+			)
+		},
+	)
+
+	return NewDictionaryValue(
+		interpreter,
+		getLocationRange,
+		metaTypeFieldsStaticType,
+		keysAndValues...,
+	)
+}
+
+// reflectFunctions returns a dictionary of the public functions of the type,
+// mapping function names to function signatures.
+//
+func (v TypeValue) reflectFunctions(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+) Value {
+
+	var keysAndValues []Value
+
+	v.foreachPublicMember(
+		interpreter,
+		common.DeclarationKindFunction,
+		func(name string, member *sema.Member) {
+			signature := member.TypeAnnotation.Type.QualifiedString()
+
+			keysAndValues = append(
+				keysAndValues,
+				newReflectedStringValue(interpreter, name),
+				newReflectedStringValue(interpreter, signature),
+			)
+		},
+	)
+
+	return NewDictionaryValue(
+		interpreter,
+		getLocationRange,
+		metaTypeFunctionsStaticType,
+		keysAndValues...,
+	)
+}
+
+// reflectConformances returns an array of the interface types
+// the composite type explicitly declares conformance to.
+//
+func (v TypeValue) reflectConformances(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+) Value {
+
+	var conformances []Value
+
+	if v.Type != nil {
+		compositeType, ok := interpreter.MustConvertStaticToSemaType(v.Type).(*sema.CompositeType)
+		if ok {
+			for _, conformance := range compositeType.ExplicitInterfaceConformances {
+				conformanceType := ConvertSemaInterfaceTypeToStaticInterfaceType(interpreter, conformance)
+				conformances = append(
+					conformances,
+					NewTypeValue(interpreter, conformanceType),
+				)
+			}
+		}
+	}
+
+	return NewArrayValue(
+		interpreter,
+		getLocationRange,
+		metaTypeConformancesStaticType,
+		common.Address{},
+		conformances...,
+	)
+}
+
 func (TypeValue) RemoveMember(_ *Interpreter, _ func() LocationRange, _ string) Value {
 	// Types have no removable members (fields / functions)
 	panic(errors.NewUnreachableError())
@@ -1154,6 +1318,85 @@ func (v *StringValue) GetMember(interpreter *Interpreter, _ func() LocationRange
 			},
 			sema.StringTypeToLowerFunctionType,
 		)
+
+	case "toUpper":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				return v.ToUpper(invocation.Interpreter)
+			},
+			sema.StringTypeToUpperFunctionType,
+		)
+
+	case "split":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				separator, ok := invocation.Arguments[0].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.Split(invocation.Interpreter, separator)
+			},
+			sema.StringTypeSplitFunctionType,
+		)
+
+	case "replaceAll":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				original, ok := invocation.Arguments[0].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				replacement, ok := invocation.Arguments[1].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.ReplaceAll(invocation.Interpreter, original, replacement)
+			},
+			sema.StringTypeReplaceAllFunctionType,
+		)
+
+	case "contains":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				other, ok := invocation.Arguments[0].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.Contains(invocation.Interpreter, other)
+			},
+			sema.StringTypeContainsFunctionType,
+		)
+
+	case "index":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				other, ok := invocation.Arguments[0].(*StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.IndexOf(invocation.Interpreter, other)
+			},
+			sema.StringTypeIndexFunctionType,
+		)
+
+	case "trim":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				return v.Trim(invocation.Interpreter)
+			},
+			sema.StringTypeTrimFunctionType,
+		)
 	}
 
 	return nil
@@ -1209,6 +1452,349 @@ func (v *StringValue) ToLower(interpreter *Interpreter) *StringValue {
 	)
 }
 
+func (v *StringValue) ToUpper(interpreter *Interpreter) *StringValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	// Over-estimate resulting string length,
+	// as the encoding of an upper-case character may be longer than the encoding of the lowercase character, e.g ɐ => Ɐ
+
+	var lengthEstimate int
+	for _, r := range v.Str {
+		if r < unicode.MaxASCII {
+			lengthEstimate += 1
+		} else {
+			lengthEstimate += utf8.UTFMax
+		}
+	}
+
+	memoryUsage := common.NewStringMemoryUsage(lengthEstimate)
+
+	return NewStringValue(
+		interpreter,
+		memoryUsage,
+		func() string {
+			return strings.ToUpper(v.Str)
+		},
+	)
+}
+
+// graphemeBoundaries returns the byte offsets of the characters (grapheme clusters) of the string,
+// followed by the length of the string.
+//
+func (v *StringValue) graphemeBoundaries() []int {
+	boundaries := make([]int, 0, v.Length()+1)
+
+	v.prepareGraphemes()
+	for v.graphemes.Next() {
+		start, _ := v.graphemes.Positions()
+		boundaries = append(boundaries, start)
+	}
+
+	return append(boundaries, len(v.Str))
+}
+
+// indexOf returns the character index of the first occurrence of the given non-empty string,
+// starting at the given character index, and the character index after the occurrence,
+// or -1 if the string does not contain the given string.
+//
+// An occurrence must start and end at character boundaries,
+// so it does not match a part of a character.
+//
+// Candidates are found using a byte-wise search, which is linear in the length of the string,
+// and are then checked to be at character boundaries.
+//
+func (v *StringValue) indexOf(boundaries []int, other string, fromIndex int) (start int, end int) {
+	characterCount := len(boundaries) - 1
+
+	if fromIndex >= characterCount {
+		return -1, -1
+	}
+
+	offset := boundaries[fromIndex]
+
+	for offset < len(v.Str) {
+		candidate := strings.Index(v.Str[offset:], other)
+		if candidate < 0 {
+			break
+		}
+
+		startOffset := offset + candidate
+		endOffset := startOffset + len(other)
+
+		startIndex := sort.SearchInts(boundaries, startOffset)
+		if boundaries[startIndex] == startOffset {
+			endIndex := startIndex + 1 + sort.SearchInts(boundaries[startIndex+1:], endOffset)
+			if endIndex < len(boundaries) && boundaries[endIndex] == endOffset {
+				return startIndex, endIndex
+			}
+		}
+
+		// The candidate is not at character boundaries,
+		// continue searching after its start
+
+		offset = startOffset + 1
+	}
+
+	return -1, -1
+}
+
+// Contains returns true if the string contains the given string.
+//
+func (v *StringValue) Contains(interpreter *Interpreter, other *StringValue) BoolValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	if len(other.Str) == 0 {
+		return NewBoolValue(interpreter, true)
+	}
+
+	start, _ := v.indexOf(v.graphemeBoundaries(), other.Str, 0)
+
+	return NewBoolValue(interpreter, start >= 0)
+}
+
+// IndexOf returns the character index of the first occurrence of the given string,
+// or nil if the string does not contain the given string.
+//
+func (v *StringValue) IndexOf(interpreter *Interpreter, other *StringValue) OptionalValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	start := 0
+
+	if len(other.Str) > 0 {
+		start, _ = v.indexOf(v.graphemeBoundaries(), other.Str, 0)
+		if start < 0 {
+			return NilValue{}
+		}
+	}
+
+	return NewSomeValueNonCopying(
+		interpreter,
+		NewIntValueFromInt64(interpreter, int64(start)),
+	)
+}
+
+// Split returns the substrings of the string which are separated by the given separator.
+// If the separator is empty, the string is split into its characters.
+//
+func (v *StringValue) Split(interpreter *Interpreter, separator *StringValue) *ArrayValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	boundaries := v.graphemeBoundaries()
+	characterCount := len(boundaries) - 1
+
+	var parts []string
+
+	if len(separator.Str) == 0 {
+		parts = make([]string, 0, characterCount)
+		for index := 0; index < characterCount; index++ {
+			parts = append(parts, v.Str[boundaries[index]:boundaries[index+1]])
+		}
+	} else {
+		partStart := 0
+		for {
+			start, end := v.indexOf(boundaries, separator.Str, partStart)
+			if start < 0 {
+				break
+			}
+			parts = append(parts, v.Str[boundaries[partStart]:boundaries[start]])
+			partStart = end
+		}
+		parts = append(parts, v.Str[boundaries[partStart]:])
+	}
+
+	return newStringArrayValue(interpreter, parts)
+}
+
+var StringArrayStaticType = ConvertSemaArrayTypeToStaticArrayType(nil, sema.StringArrayType)
+
+func newStringArrayValue(interpreter *Interpreter, strs []string) *ArrayValue {
+	index := 0
+
+	return NewArrayValueWithIterator(
+		interpreter,
+		StringArrayStaticType,
+		common.Address{},
+		uint64(len(strs)),
+		func() Value {
+			if index >= len(strs) {
+				return nil
+			}
+
+			str := strs[index]
+			index++
+
+			return NewStringValue(
+				interpreter,
+				common.NewStringMemoryUsage(len(str)),
+				func() string {
+					return str
+				},
+			)
+		},
+	)
+}
+
+// ReplaceAll returns a new string with all non-overlapping occurrences
+// of the given original string replaced by the given replacement.
+// If the original string is empty, the replacement is inserted
+// at the beginning of the string and after each character.
+//
+func (v *StringValue) ReplaceAll(
+	interpreter *Interpreter,
+	original *StringValue,
+	replacement *StringValue,
+) *StringValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	boundaries := v.graphemeBoundaries()
+	characterCount := len(boundaries) - 1
+
+	// Determine the character indices of the occurrences,
+	// so the length of the resulting string is known in advance
+
+	type occurrence struct {
+		start int
+		end   int
+	}
+
+	var occurrences []occurrence
+
+	if len(original.Str) == 0 {
+		occurrences = make([]occurrence, 0, characterCount+1)
+		for index := 0; index <= characterCount; index++ {
+			occurrences = append(occurrences, occurrence{start: index, end: index})
+		}
+	} else {
+		fromIndex := 0
+		for {
+			start, end := v.indexOf(boundaries, original.Str, fromIndex)
+			if start < 0 {
+				break
+			}
+			occurrences = append(occurrences, occurrence{start: start, end: end})
+			fromIndex = end
+		}
+	}
+
+	if len(occurrences) == 0 {
+		return v
+	}
+
+	newLength := len(v.Str)
+	for _, occurrence := range occurrences {
+		replacedLength := boundaries[occurrence.end] - boundaries[occurrence.start]
+		newLength = safeAdd(newLength, len(replacement.Str)-replacedLength)
+	}
+
+	return NewStringValue(
+		interpreter,
+		common.NewStringMemoryUsage(newLength),
+		func() string {
+			var sb strings.Builder
+			sb.Grow(newLength)
+
+			previousEnd := 0
+			for _, occurrence := range occurrences {
+				sb.WriteString(v.Str[boundaries[previousEnd]:boundaries[occurrence.start]])
+				sb.WriteString(replacement.Str)
+				previousEnd = occurrence.end
+			}
+			sb.WriteString(v.Str[boundaries[previousEnd]:])
+
+			return sb.String()
+		},
+	)
+}
+
+// Trim returns the string with the leading and trailing whitespace characters removed.
+// A character (grapheme cluster) is only removed if all its code points are whitespace,
+// e.g. a space followed by a combining mark is not removed.
+//
+func (v *StringValue) Trim(interpreter *Interpreter) *StringValue {
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(len(v.Str)))
+
+	isWhitespace := func(character string) bool {
+		for _, r := range character {
+			if !unicode.IsSpace(r) {
+				return false
+			}
+		}
+		return true
+	}
+
+	start := len(v.Str)
+	end := 0
+
+	v.prepareGraphemes()
+	for v.graphemes.Next() {
+		if isWhitespace(v.graphemes.Str()) {
+			continue
+		}
+
+		characterStart, characterEnd := v.graphemes.Positions()
+		if characterStart < start {
+			start = characterStart
+		}
+		end = characterEnd
+	}
+
+	if start >= end {
+		return emptyString
+	}
+
+	trimmed := v.Str[start:end]
+
+	return NewStringValue(
+		interpreter,
+		common.NewStringMemoryUsage(len(trimmed)),
+		func() string {
+			return trimmed
+		},
+	)
+}
+
+// JoinStrings returns a string containing the strings of the given array,
+// separated by the given separator.
+//
+func JoinStrings(interpreter *Interpreter, strs *ArrayValue, separator *StringValue) *StringValue {
+
+	parts := make([]string, 0, strs.Count())
+	length := 0
+
+	strs.Iterate(interpreter, func(element Value) (resume bool) {
+		str, ok := element.(*StringValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		if len(parts) > 0 {
+			length = safeAdd(length, len(separator.Str))
+		}
+		length = safeAdd(length, len(str.Str))
+
+		parts = append(parts, str.Str)
+
+		// continue iteration
+		return true
+	})
+
+	interpreter.ReportComputation(common.ComputationKindStringManipulation, uint(length))
+
+	return NewStringValue(
+		interpreter,
+		common.NewStringMemoryUsage(length),
+		func() string {
+			return strings.Join(parts, separator.Str)
+		},
+	)
+}
+
 func (v *StringValue) Storable(storage atree.SlabStorage, address atree.Address, maxInlineSize uint64) (atree.Storable, error) {
 	return maybeLargeImmutableStorable(v, storage, address, maxInlineSize)
 }
@@ -1691,6 +2277,8 @@ func (v *ArrayValue) Set(interpreter *Interpreter, getLocationRange func() Locat
 
 	interpreter.checkContainerMutation(v.Type.ElementType(), element, getLocationRange)
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	common.UseMemory(interpreter, common.AtreeArrayElementOverhead)
 
 	element = element.Transfer(
@@ -1762,6 +2350,8 @@ func (v *ArrayValue) Append(interpreter *Interpreter, getLocationRange func() Lo
 
 	interpreter.checkContainerMutation(v.Type.ElementType(), element, getLocationRange)
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	element = element.Transfer(
 		interpreter,
 		getLocationRange,
@@ -1818,6 +2408,8 @@ func (v *ArrayValue) Insert(interpreter *Interpreter, getLocationRange func() Lo
 
 	interpreter.checkContainerMutation(v.Type.ElementType(), element, getLocationRange)
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	element = element.Transfer(
 		interpreter,
 		getLocationRange,
@@ -1858,6 +2450,8 @@ func (v *ArrayValue) Remove(interpreter *Interpreter, getLocationRange func() Lo
 		})
 	}
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	storable, err := v.array.Remove(uint64(index))
 	if err != nil {
 		v.handleIndexOutOfBoundsError(err, index, getLocationRange)
@@ -2130,7 +2724,89 @@ func (v *ArrayValue) GetMember(interpreter *Interpreter, getLocationRange func()
 					invocation.GetLocationRange,
 				)
 			},
-			sema.ArraySliceFunctionType(
+			sema.ArraySliceFunctionType(
+				v.SemaType(interpreter).ElementType(false),
+			),
+		)
+
+	case "filter":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				predicate, ok := invocation.Arguments[0].(FunctionValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.Filter(
+					invocation.Interpreter,
+					invocation.GetLocationRange,
+					predicate,
+				)
+			},
+			sema.ArrayFilterFunctionType(
+				v.SemaType(interpreter).ElementType(false),
+			),
+		)
+
+	case "map":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				transform, ok := invocation.Arguments[0].(FunctionValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				typeParameterPair := invocation.TypeParameterTypes.Oldest()
+				if typeParameterPair == nil {
+					panic(errors.NewUnreachableError())
+				}
+
+				return v.Map(
+					invocation.Interpreter,
+					invocation.GetLocationRange,
+					transform,
+					ConvertSemaToStaticType(invocation.Interpreter, typeParameterPair.Value),
+				)
+			},
+			sema.ArrayMapFunctionType(
+				v.SemaType(interpreter),
+			),
+		)
+
+	case "reverse":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				return v.Reverse(
+					invocation.Interpreter,
+					invocation.GetLocationRange,
+				)
+			},
+			sema.ArrayReverseFunctionType(
+				v.SemaType(interpreter),
+			),
+		)
+
+	case "sort":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				isBefore, ok := invocation.Arguments[0].(FunctionValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				v.Sort(
+					invocation.Interpreter,
+					invocation.GetLocationRange,
+					isBefore,
+				)
+
+				return NewVoidValue(invocation.Interpreter)
+			},
+			sema.ArraySortFunctionType(
 				v.SemaType(interpreter).ElementType(false),
 			),
 		)
@@ -2577,6 +3253,309 @@ func (v *ArrayValue) Slice(
 	)
 }
 
+// invokeElementFunction invokes the given function of a higher-order array or dictionary function,
+// e.g. the predicate of `filter`, with the given elements, and reports the computation of the step.
+//
+func invokeElementFunction(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	function FunctionValue,
+	elementType sema.Type,
+	elements ...Value,
+) Value {
+	interpreter.ReportComputation(common.ComputationKindLoop, 1)
+
+	argumentTypes := make([]sema.Type, len(elements))
+	for i := range elements {
+		argumentTypes[i] = elementType
+	}
+
+	invocation := NewInvocation(
+		interpreter,
+		nil,
+		elements,
+		argumentTypes,
+		nil,
+		getLocationRange,
+	)
+
+	return function.invoke(invocation)
+}
+
+// Filter returns a new variable-sized array containing the elements
+// for which the given predicate returns true, in the original order.
+//
+func (v *ArrayValue) Filter(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	predicate FunctionValue,
+) Value {
+
+	iterator, err := v.array.Iterator()
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+
+	checkMutation, stopTracking := interpreter.trackContainerMutations(v.StorageID(), getLocationRange)
+	defer stopTracking()
+
+	elementType := v.SemaType(interpreter).ElementType(false)
+
+	return NewArrayValueWithIterator(
+		interpreter,
+		NewVariableSizedStaticType(interpreter, v.Type.ElementType()),
+		common.Address{},
+		// The number of retained elements is not known in advance,
+		// so assume all elements are retained
+		uint64(v.Count()),
+		func() Value {
+
+			for {
+				atreeValue, err := iterator.Next()
+				if err != nil {
+					panic(errors.NewExternalError(err))
+				}
+
+				if atreeValue == nil {
+					return nil
+				}
+
+				value := MustConvertStoredValue(interpreter, atreeValue)
+
+				// Pass a copy of the element, so the predicate cannot mutate the array
+
+				argument := value.Transfer(
+					interpreter,
+					getLocationRange,
+					atree.Address{},
+					false,
+					nil,
+				)
+
+				result, ok := invokeElementFunction(
+					interpreter,
+					getLocationRange,
+					predicate,
+					elementType,
+					argument,
+				).(BoolValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				checkMutation()
+
+				if result {
+					return value.Transfer(
+						interpreter,
+						getLocationRange,
+						atree.Address{},
+						false,
+						nil,
+					)
+				}
+			}
+		},
+	)
+}
+
+// Map returns a new array of the same kind and size,
+// containing the results of calling the given transform function on each element.
+//
+func (v *ArrayValue) Map(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	transform FunctionValue,
+	resultElementType StaticType,
+) Value {
+
+	var resultType ArrayStaticType
+	switch arrayType := v.Type.(type) {
+	case VariableSizedStaticType:
+		resultType = NewVariableSizedStaticType(interpreter, resultElementType)
+	case ConstantSizedStaticType:
+		resultType = NewConstantSizedStaticType(interpreter, resultElementType, arrayType.Size)
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	iterator, err := v.array.Iterator()
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+
+	checkMutation, stopTracking := interpreter.trackContainerMutations(v.StorageID(), getLocationRange)
+	defer stopTracking()
+
+	elementType := v.SemaType(interpreter).ElementType(false)
+
+	return NewArrayValueWithIterator(
+		interpreter,
+		resultType,
+		common.Address{},
+		uint64(v.Count()),
+		func() Value {
+
+			atreeValue, err := iterator.Next()
+			if err != nil {
+				panic(errors.NewExternalError(err))
+			}
+
+			if atreeValue == nil {
+				return nil
+			}
+
+			argument := MustConvertStoredValue(interpreter, atreeValue).
+				Transfer(
+					interpreter,
+					getLocationRange,
+					atree.Address{},
+					false,
+					nil,
+				)
+
+			result := invokeElementFunction(
+				interpreter,
+				getLocationRange,
+				transform,
+				elementType,
+				argument,
+			)
+
+			checkMutation()
+
+			interpreter.checkContainerMutation(resultElementType, result, getLocationRange)
+
+			return result.Transfer(
+				interpreter,
+				getLocationRange,
+				atree.Address{},
+				false,
+				nil,
+			)
+		},
+	)
+}
+
+// Reverse returns a new array of the same type,
+// containing the elements in reverse order.
+//
+func (v *ArrayValue) Reverse(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+) Value {
+
+	count := v.Count()
+	index := count - 1
+
+	return NewArrayValueWithIterator(
+		interpreter,
+		v.Type,
+		common.Address{},
+		uint64(count),
+		func() Value {
+			if index < 0 {
+				return nil
+			}
+
+			interpreter.ReportComputation(common.ComputationKindLoop, 1)
+
+			value := v.Get(interpreter, getLocationRange, index)
+
+			index--
+
+			return value.Transfer(
+				interpreter,
+				getLocationRange,
+				atree.Address{},
+				false,
+				nil,
+			)
+		},
+	)
+}
+
+// Sort sorts the array in place using a stable sort,
+// where the given function determines if the first element must be ordered before the second.
+//
+func (v *ArrayValue) Sort(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	isBefore FunctionValue,
+) {
+	count := v.Count()
+	if count < 2 {
+		return
+	}
+
+	// Sort copies of the elements, so the function cannot mutate the elements of the array
+
+	elements := make([]Value, 0, count)
+
+	v.Iterate(interpreter, func(element Value) (resume bool) {
+		elements = append(
+			elements,
+			element.Transfer(
+				interpreter,
+				getLocationRange,
+				atree.Address{},
+				false,
+				nil,
+			),
+		)
+
+		// continue iteration
+		return true
+	})
+
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	checkMutation, stopTracking := interpreter.trackContainerMutations(v.StorageID(), getLocationRange)
+	defer stopTracking()
+
+	elementType := v.SemaType(interpreter).ElementType(false)
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		result, ok := invokeElementFunction(
+			interpreter,
+			getLocationRange,
+			isBefore,
+			elementType,
+			elements[indices[i]],
+			elements[indices[j]],
+		).(BoolValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		checkMutation()
+
+		return bool(result)
+	})
+
+	// Copy the elements in sorted order before replacing any of them,
+	// as setting an element removes the replaced element
+
+	sortedElements := make([]Value, count)
+	for i, index := range indices {
+		sortedElements[i] = v.Get(interpreter, getLocationRange, index).
+			Transfer(
+				interpreter,
+				getLocationRange,
+				atree.Address{},
+				false,
+				nil,
+			)
+	}
+
+	for i, element := range sortedElements {
+		v.Set(interpreter, getLocationRange, i, element)
+	}
+}
+
 // NumberValue
 //
 type NumberValue interface {
@@ -14146,6 +15125,9 @@ type CompositeValue struct {
 	isDestroyed         bool
 	typeID              common.TypeID
 	staticType          StaticType
+	// TypeArguments are the type arguments of an instance
+	// of a user-defined generic composite type, if any
+	TypeArguments []StaticType
 }
 
 type ComputedField func(*Interpreter, func() LocationRange) Value
@@ -14176,6 +15158,32 @@ func NewCompositeValue(
 	fields []CompositeField,
 	address common.Address,
 ) *CompositeValue {
+	return NewGenericCompositeValue(
+		interpreter,
+		getLocationRange,
+		location,
+		qualifiedIdentifier,
+		kind,
+		fields,
+		address,
+		nil,
+	)
+}
+
+// NewGenericCompositeValue returns a new composite value
+// of an instantiation of a user-defined generic composite type
+// with the given type arguments.
+//
+func NewGenericCompositeValue(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	location common.Location,
+	qualifiedIdentifier string,
+	kind common.CompositeKind,
+	fields []CompositeField,
+	address common.Address,
+	typeArguments []StaticType,
+) *CompositeValue {
 
 	interpreter.ReportComputation(common.ComputationKindCreateCompositeValue, 1)
 
@@ -14213,6 +15221,7 @@ func NewCompositeValue(
 				location,
 				qualifiedIdentifier,
 				kind,
+				typeArguments,
 			),
 		)
 		if err != nil {
@@ -14226,6 +15235,7 @@ func NewCompositeValue(
 		location,
 		qualifiedIdentifier,
 		kind,
+		typeArguments,
 	)
 
 	v = newCompositeValueFromConstructor(interpreter, uint64(len(fields)), typeInfo, constructor)
@@ -14251,6 +15261,7 @@ func newCompositeValueFromOrderedMap(
 		Location:            typeInfo.location,
 		QualifiedIdentifier: typeInfo.qualifiedIdentifier,
 		Kind:                typeInfo.kind,
+		TypeArguments:       typeInfo.typeArguments,
 	}
 }
 
@@ -14300,12 +15311,14 @@ func (v *CompositeValue) StaticType(interpreter *Interpreter) StaticType {
 	if v.staticType == nil {
 		// NOTE: Instead of using NewCompositeStaticType, which always generates the type ID,
 		// use the TypeID accessor, which may return an already computed type ID
-		v.staticType = NewCompositeStaticType(
+		staticType := NewCompositeStaticType(
 			interpreter,
 			v.Location,
 			v.QualifiedIdentifier,
 			v.TypeID(), // TODO TypeID metering
 		)
+		staticType.TypeArguments = v.TypeArguments
+		v.staticType = staticType
 	}
 	return v.staticType
 }
@@ -14861,10 +15874,19 @@ func (v *CompositeValue) ConformsToStaticType(
 	}
 
 	compositeType, ok := semaType.(*sema.CompositeType)
-	if !ok ||
-		v.Kind != compositeType.Kind ||
-		v.TypeID() != compositeType.ID() {
+	if !ok || v.Kind != compositeType.Kind {
+		return false
+	}
 
+	// The type ID of an instance of a generic composite type
+	// is the type ID of the generic composite type
+
+	typeID := compositeType.ID()
+	if baseType := compositeType.BaseType(); baseType != nil {
+		typeID = baseType.ID()
+	}
+
+	if v.TypeID() != typeID {
 		return false
 	}
 
@@ -15098,6 +16120,7 @@ func (v *CompositeValue) Transfer(
 			v.Location,
 			v.QualifiedIdentifier,
 			v.Kind,
+			v.TypeArguments,
 		)
 		res = newCompositeValueFromOrderedMap(dictionary, info)
 		res.InjectedFields = v.InjectedFields
@@ -15187,6 +16210,7 @@ func (v *CompositeValue) Clone(interpreter *Interpreter) Value {
 		isDestroyed:         v.isDestroyed,
 		typeID:              v.typeID,
 		staticType:          v.staticType,
+		TypeArguments:       v.TypeArguments,
 	}
 }
 
@@ -15873,11 +16897,72 @@ func (v *DictionaryValue) GetMember(
 			),
 		)
 
+	case "forEachKey":
+		return NewHostFunctionValue(
+			interpreter,
+			func(invocation Invocation) Value {
+				function, ok := invocation.Arguments[0].(FunctionValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				v.ForEachKey(
+					invocation.Interpreter,
+					invocation.GetLocationRange,
+					function,
+				)
+
+				return NewVoidValue(invocation.Interpreter)
+			},
+			sema.DictionaryForEachKeyFunctionType(
+				v.SemaType(interpreter),
+			),
+		)
 	}
 
 	return nil
 }
 
+// ForEachKey calls the given function with each key of the dictionary,
+// until the function returns false.
+//
+func (v *DictionaryValue) ForEachKey(
+	interpreter *Interpreter,
+	getLocationRange func() LocationRange,
+	function FunctionValue,
+) {
+	checkMutation, stopTracking := interpreter.trackContainerMutations(v.StorageID(), getLocationRange)
+	defer stopTracking()
+
+	keyType := v.SemaType(interpreter).KeyType
+
+	err := v.dictionary.IterateKeys(func(key atree.Value) (resume bool, err error) {
+		// atree.OrderedMap iteration provides low-level atree.Value,
+		// convert to high-level interpreter.Value
+
+		keyValue := MustConvertStoredValue(interpreter, key).
+			Transfer(interpreter, getLocationRange, atree.Address{}, false, nil)
+
+		result, ok := invokeElementFunction(
+			interpreter,
+			getLocationRange,
+			function,
+			keyType,
+			keyValue,
+		).(BoolValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		checkMutation()
+
+		return bool(result), nil
+	})
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+}
+
 func (v *DictionaryValue) RemoveMember(interpreter *Interpreter, getLocationRange func() LocationRange, _ string) Value {
 
 	if interpreter.invalidatedResourceValidationEnabled {
@@ -15921,6 +17006,8 @@ func (v *DictionaryValue) Remove(
 	keyValue Value,
 ) OptionalValue {
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	valueComparator := newValueComparator(interpreter, getLocationRange)
 	hashInputProvider := newHashInputProvider(interpreter, getLocationRange)
 
@@ -15984,6 +17071,8 @@ func (v *DictionaryValue) Insert(
 	interpreter.checkContainerMutation(v.Type.KeyType, keyValue, getLocationRange)
 	interpreter.checkContainerMutation(v.Type.ValueType, value, getLocationRange)
 
+	interpreter.recordContainerMutation(v.StorageID())
+
 	address := v.dictionary.Address()
 
 	keyValue = keyValue.Transfer(
@@ -17075,7 +18164,7 @@ func (v *StorageReferenceValue) GetMember(
 
 	interpreter.checkReferencedResourceNotDestroyed(self, getLocationRange)
 
-	return interpreter.getMember(self, getLocationRange, name)
+	return interpreter.GetMember(self, getLocationRange, name)
 }
 
 func (v *StorageReferenceValue) RemoveMember(
@@ -17399,7 +18488,7 @@ func (v *EphemeralReferenceValue) GetMember(
 
 	interpreter.checkReferencedResourceNotDestroyed(self, getLocationRange)
 
-	return interpreter.getMember(self, getLocationRange, name)
+	return interpreter.GetMember(self, getLocationRange, name)
 }
 
 func (v *EphemeralReferenceValue) RemoveMember(
@@ -17561,7 +18650,7 @@ func (v *EphemeralReferenceValue) ConformsToStaticType(
 
 	entry := typeConformanceResultEntry{
 		EphemeralReferenceValue: v,
-		EphemeralReferenceType:  staticType,
+		EphemeralReferenceType:  staticType.String(),
 	}
 
 	if result, contains := results[entry]; contains {
@@ -22,7 +22,10 @@
 
 package interpreter
 
-import "github.com/onflow/cadence/runtime/common"
+import (
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
 
 // A VariableActivation is a map of strings to values.
 // It can be used to represent an active scope in a program,
@@ -35,6 +38,9 @@ type VariableActivation struct {
 	Parent      *VariableActivation
 	isFunction  bool
 	memoryGauge common.MemoryGauge
+	// typeArguments are the type arguments of the invoked
+	// user-defined generic function or composite, if any
+	typeArguments *sema.TypeParameterTypeOrderedMap
 }
 
 func NewVariableActivation(memoryGauge common.MemoryGauge, parent *VariableActivation) *VariableActivation {
@@ -102,6 +108,56 @@ func (a *VariableActivation) FunctionValues() map[string]*Variable {
 	return values
 }
 
+// TypeArguments returns the type arguments in the activation,
+// i.e. the type arguments of the nearest enclosing invocation
+// of a user-defined generic function or composite.
+// It returns nil if there are no type arguments.
+//
+func (a *VariableActivation) TypeArguments() *sema.TypeParameterTypeOrderedMap {
+
+	current := a
+
+	for current != nil {
+		if current.typeArguments != nil {
+			return current.typeArguments
+		}
+
+		current = current.Parent
+	}
+
+	return nil
+}
+
+// SetTypeArguments adds the given type arguments to the activation.
+// The type arguments of the enclosing activations remain available.
+//
+func (a *VariableActivation) SetTypeArguments(typeArguments *sema.TypeParameterTypeOrderedMap) {
+	if typeArguments == nil || typeArguments.Len() == 0 {
+		return
+	}
+
+	merged := &sema.TypeParameterTypeOrderedMap{}
+
+	var parentTypeArguments *sema.TypeParameterTypeOrderedMap
+	if a.typeArguments != nil {
+		parentTypeArguments = a.typeArguments
+	} else if a.Parent != nil {
+		parentTypeArguments = a.Parent.TypeArguments()
+	}
+
+	if parentTypeArguments != nil {
+		parentTypeArguments.Foreach(func(typeParameter *sema.TypeParameter, ty sema.Type) {
+			merged.Set(typeParameter, ty)
+		})
+	}
+
+	typeArguments.Foreach(func(typeParameter *sema.TypeParameter, ty sema.Type) {
+		merged.Set(typeParameter, ty)
+	})
+
+	a.typeArguments = merged
+}
+
 // Set sets the given name-value pair in the activation.
 //
 func (a *VariableActivation) Set(name string, value *Variable) {
@@ -0,0 +1,93 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// AccountMigrationError is reported when the stored values of an account could not be migrated.
+// The payloads of the account are left unchanged.
+//
+type AccountMigrationError struct {
+	Address common.Address
+	// Domain and Key are the location of the stored value which could not be migrated,
+	// and are empty if the account failed as a whole, e.g. when committing the migrated values
+	Domain string
+	Key    string
+	Err    error
+}
+
+func (e *AccountMigrationError) Error() string {
+	if e.Domain == "" {
+		return fmt.Sprintf(
+			"failed to migrate account %s: %s",
+			e.Address,
+			e.Err,
+		)
+	}
+
+	return fmt.Sprintf(
+		"failed to migrate account %s, value %s/%s: %s",
+		e.Address,
+		e.Domain,
+		e.Key,
+		e.Err,
+	)
+}
+
+func (e *AccountMigrationError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidRestrictionMigrationError is reported when a static type migration
+// replaces the restriction of a restricted type with a type that is not an interface type.
+//
+type InvalidRestrictionMigrationError struct {
+	Restriction interpreter.InterfaceStaticType
+	Result      interpreter.StaticType
+}
+
+func (e *InvalidRestrictionMigrationError) Error() string {
+	return fmt.Sprintf(
+		"restriction %s was migrated to non-interface type %s",
+		e.Restriction,
+		e.Result,
+	)
+}
+
+// InvalidContainerTypeMigrationError is reported when a static type migration
+// replaces the type of a stored array or dictionary with a type of a different kind,
+// e.g. an array type with a dictionary type.
+//
+type InvalidContainerTypeMigrationError struct {
+	Type   interpreter.StaticType
+	Result interpreter.StaticType
+}
+
+func (e *InvalidContainerTypeMigrationError) Error() string {
+	return fmt.Sprintf(
+		"container type %s was migrated to incompatible type %s",
+		e.Type,
+		e.Result,
+	)
+}
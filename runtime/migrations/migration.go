@@ -0,0 +1,375 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrations
+
+import (
+	"bytes"
+	goRuntime "runtime"
+	"sort"
+	"sync"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// ValueMigration is a named set of typed transformers for stored values.
+// All transformers are optional.
+//
+type ValueMigration struct {
+	// Name is the name of the migration, which is used in the results
+	Name string
+
+	// MigrateComposite is called for each stored composite value,
+	// after the values of its fields were migrated.
+	//
+	// It returns the value which replaces the composite value,
+	// or nil if the composite value is kept.
+	// The composite value may also be updated in place, e.g. by setting its fields,
+	// and then be returned to record the migration. It is kept in that case.
+	//
+	// A replaced composite value is removed from storage,
+	// so the returned value must not share nested values with it, i.e. they must be copied,
+	// and nested resources must be moved out of it, e.g. using RemoveMember.
+	//
+	MigrateComposite func(
+		inter *interpreter.Interpreter,
+		value *interpreter.CompositeValue,
+	) (
+		interpreter.Value,
+		error,
+	)
+
+	// MigrateStaticType is called for each static type in stored values,
+	// i.e. the types of type values, the borrow types of capabilities, the types of links,
+	// and the types of arrays and dictionaries,
+	// and for all static types nested in them, e.g. the element type of an array type.
+	// Arrays and dictionaries with a migrated type are replaced with new containers.
+	// Nested static types are migrated first.
+	//
+	// It returns the static type which replaces the static type,
+	// or nil if the static type is kept.
+	//
+	MigrateStaticType func(staticType interpreter.StaticType) (interpreter.StaticType, error)
+}
+
+// Config is the configuration of a migration.
+//
+type Config struct {
+	// Migrations are the migrations which are applied, in order
+	Migrations []*ValueMigration
+	// Workers is the number of accounts which are migrated concurrently.
+	// If it is less than two, accounts are migrated sequentially.
+	//
+	// If there are multiple workers, the transformers of the migrations,
+	// and the functions provided by the interpreter options, e.g. handlers,
+	// are called concurrently, so they must be safe for concurrent use
+	Workers int
+	// InterpreterOptions are additional options for the interpreters which migrate the accounts.
+	//
+	// Replacing an element of an array or dictionary requires the element type
+	// to be a subtype of the container's element type. Unless the types are equal,
+	// the interpreters must be able to load the programs of composite types,
+	// e.g. by providing an import location handler
+	InterpreterOptions []interpreter.Option
+	// AllocateStorageIndex allocates the storage indices of the new slabs of migrated accounts,
+	// e.g. of replaced composites, and of arrays and dictionaries with a migrated static type.
+	//
+	// Embedders which keep track of the next storage index of each account must provide it,
+	// so the new slabs do not collide with slabs which are later allocated by the embedder.
+	// Storage indices allocated for accounts which fail to migrate are not used.
+	// If there are multiple workers, it is called concurrently for different accounts.
+	//
+	// If it is nil, new storage indices are allocated after the greatest storage index
+	// of the existing slabs of the account
+	AllocateStorageIndex StorageIndexAllocator
+}
+
+// MigratedValue is a stored value which was replaced, or which contains a replaced value.
+//
+type MigratedValue struct {
+	Address common.Address
+	Domain  string
+	Key     string
+	// Migrations are the names of the migrations which replaced values,
+	// or which returned composite values they updated in place,
+	// in the order they were first applied
+	Migrations []string
+}
+
+// Result is the result of a migration.
+//
+// The result is deterministic, independent of the number of workers.
+//
+type Result struct {
+	// Payloads are all payloads after the migration, sorted by owner and key
+	Payloads []Payload
+	// MigratedValues are the migrated stored values, sorted by address, domain, and key
+	MigratedValues []MigratedValue
+	// Failures are the accounts which could not be migrated, sorted by address.
+	// The payloads of these accounts are unchanged
+	Failures []*AccountMigrationError
+}
+
+// domains are the domains of the storage maps of an account
+//
+var domains = []string{
+	common.PathDomainStorage.Identifier(),
+	common.PathDomainPrivate.Identifier(),
+	common.PathDomainPublic.Identifier(),
+	runtime.StorageDomainContract,
+}
+
+// Migrate migrates the values stored in all storage maps of all accounts of the given payloads.
+//
+// Each account is migrated independently: The migrated values are re-encoded and committed
+// only if all values of the account could be migrated.
+//
+// Payloads which are not owned by an account are kept.
+//
+func Migrate(payloads []Payload, config Config) *Result {
+
+	// Group the payloads by account
+
+	accountPayloads := map[common.Address][]Payload{}
+	var addresses []common.Address
+	var unownedPayloads []Payload
+
+	for _, payload := range payloads {
+		address := payload.Owner
+
+		if address == (common.Address{}) {
+			unownedPayloads = append(unownedPayloads, payload)
+			continue
+		}
+
+		if _, ok := accountPayloads[address]; !ok {
+			addresses = append(addresses, address)
+		}
+		accountPayloads[address] = append(accountPayloads[address], payload)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+
+	// Migrate the accounts.
+	// Each account has its own storage and interpreter,
+	// and the results are stored in address order
+
+	results := make([]accountResult, len(addresses))
+
+	migrateAccountAt := func(i int) {
+		address := addresses[i]
+		results[i] = migrateAccount(address, accountPayloads[address], config)
+	}
+
+	workers := config.Workers
+	if workers > len(addresses) {
+		workers = len(addresses)
+	}
+
+	if workers < 2 {
+		for i := range addresses {
+			migrateAccountAt(i)
+		}
+	} else {
+		indices := make(chan int)
+
+		var wg sync.WaitGroup
+		wg.Add(workers)
+
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := range indices {
+					migrateAccountAt(i)
+				}
+			}()
+		}
+
+		for i := range addresses {
+			indices <- i
+		}
+		close(indices)
+
+		wg.Wait()
+	}
+
+	// Combine the results
+
+	result := &Result{
+		Payloads: unownedPayloads,
+	}
+
+	for i, address := range addresses {
+		accountResult := results[i]
+
+		if accountResult.err != nil {
+			result.Failures = append(result.Failures, accountResult.err)
+			result.Payloads = append(result.Payloads, accountPayloads[address]...)
+			continue
+		}
+
+		result.Payloads = append(result.Payloads, accountResult.payloads...)
+		result.MigratedValues = append(result.MigratedValues, accountResult.migratedValues...)
+	}
+
+	sortPayloads(result.Payloads)
+
+	return result
+}
+
+type accountResult struct {
+	payloads       []Payload
+	migratedValues []MigratedValue
+	err            *AccountMigrationError
+}
+
+func migrateAccount(
+	address common.Address,
+	payloads []Payload,
+	config Config,
+) (
+	result accountResult,
+) {
+	// Report any failure, including panics, as a failure of the account
+
+	var domain, key string
+
+	defer func() {
+		if r := recover(); r != nil {
+			var err error
+			switch r := r.(type) {
+			case goRuntime.Error:
+				err = errors.NewUnexpectedError("%s", r)
+			case error:
+				err = r
+			default:
+				err = errors.NewUnexpectedError("%v", r)
+			}
+
+			result = accountResult{
+				err: &AccountMigrationError{
+					Address: address,
+					Domain:  domain,
+					Key:     key,
+					Err:     err,
+				},
+			}
+		}
+	}()
+
+	ledger := newPayloadLedger(payloads, config.AllocateStorageIndex)
+	storage := runtime.NewStorage(ledger, nil)
+
+	options := append(
+		[]interpreter.Option{
+			interpreter.WithStorage(storage),
+		},
+		config.InterpreterOptions...,
+	)
+
+	inter, err := interpreter.NewInterpreter(nil, nil, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	migrator := &accountMigrator{
+		inter:      inter,
+		migrations: config.Migrations,
+	}
+
+	for _, domain = range domains {
+		storageMap := storage.GetStorageMap(address, domain, false)
+		if storageMap == nil {
+			continue
+		}
+
+		// Collect the keys first, as the storage map must not be mutated while iterating over it
+
+		var keys []string
+
+		iterator := storageMap.Iterator(inter)
+		for {
+			nextKey := iterator.NextKey()
+			if nextKey == "" {
+				break
+			}
+			keys = append(keys, nextKey)
+		}
+
+		for _, key = range keys {
+			migrator.applied = nil
+
+			value := storageMap.ReadValue(inter, key)
+
+			newValue := migrator.migrateValue(value)
+			if newValue != nil {
+				newValue = newValue.Transfer(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					atree.Address(address),
+					true,
+					nil,
+				)
+				storageMap.SetValue(inter, key, newValue)
+			}
+
+			if len(migrator.applied) > 0 {
+				result.migratedValues = append(
+					result.migratedValues,
+					MigratedValue{
+						Address:    address,
+						Domain:     domain,
+						Key:        key,
+						Migrations: migrator.applied,
+					},
+				)
+			}
+		}
+
+		key = ""
+	}
+
+	domain = ""
+
+	sort.Slice(result.migratedValues, func(i, j int) bool {
+		a := result.migratedValues[i]
+		b := result.migratedValues[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		return a.Key < b.Key
+	})
+
+	// Re-encode the migrated values
+
+	err = storage.Commit(inter, false)
+	if err != nil {
+		panic(err)
+	}
+
+	result.payloads = ledger.Payloads()
+
+	return result
+}
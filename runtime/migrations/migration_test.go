@@ -0,0 +1,1074 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrations
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/atree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/tests/checker"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+type testStoredValue struct {
+	address common.Address
+	domain  string
+	key     string
+	value   func(inter *interpreter.Interpreter) interpreter.Value
+}
+
+const testProgram = `
+  pub struct Old {
+      pub let x: Int
+      pub let staticType: Type
+
+      init(x: Int, staticType: Type) {
+          self.x = x
+          self.staticType = staticType
+      }
+  }
+
+  pub struct New {
+      pub let x: Int
+      pub let staticType: Type
+
+      init(x: Int, staticType: Type) {
+          self.x = x
+          self.staticType = staticType
+      }
+  }
+
+  pub resource Inner {
+      pub let x: Int
+
+      init(x: Int) {
+          self.x = x
+      }
+  }
+
+  pub resource OldResource {
+      pub let inner: @Inner
+
+      init(inner: @Inner) {
+          self.inner <- inner
+      }
+
+      destroy() {
+          destroy self.inner
+      }
+  }
+
+  pub resource NewResource {
+      pub let inner: @Inner
+
+      init(inner: @Inner) {
+          self.inner <- inner
+      }
+
+      destroy() {
+          destroy self.inner
+      }
+  }
+
+  pub resource Container {
+      pub let resources: @[OldResource]
+
+      init(resources: @[OldResource]) {
+          self.resources <- resources
+      }
+
+      destroy() {
+          destroy self.resources
+      }
+  }
+`
+
+// newTestImportOption returns an interpreter option which loads the test program,
+// so the types of stored composite values can be loaded
+//
+func newTestImportOption(t *testing.T) interpreter.Option {
+	programChecker, err := checker.ParseAndCheck(t, testProgram)
+	require.NoError(t, err)
+
+	program := interpreter.ProgramFromChecker(programChecker)
+
+	return interpreter.WithImportLocationHandler(
+		func(inter *interpreter.Interpreter, location common.Location) interpreter.Import {
+			subInterpreter, err := inter.NewSubInterpreter(program, location)
+			if err != nil {
+				panic(err)
+			}
+
+			return interpreter.InterpreterImport{
+				Interpreter: subInterpreter,
+			}
+		},
+	)
+}
+
+func newTestInterpreter(t *testing.T, payloads []Payload) (*interpreter.Interpreter, *runtime.Storage, *payloadLedger) {
+	ledger := newPayloadLedger(payloads, nil)
+	storage := runtime.NewStorage(ledger, nil)
+
+	inter, err := interpreter.NewInterpreter(
+		nil,
+		nil,
+		interpreter.WithStorage(storage),
+		newTestImportOption(t),
+	)
+	require.NoError(t, err)
+
+	return inter, storage, ledger
+}
+
+func newTestPayloads(t *testing.T, storedValues []testStoredValue) []Payload {
+	inter, storage, ledger := newTestInterpreter(t, nil)
+
+	for _, storedValue := range storedValues {
+		value := storedValue.value(inter).Transfer(
+			inter,
+			interpreter.ReturnEmptyLocationRange,
+			atree.Address(storedValue.address),
+			true,
+			nil,
+		)
+
+		storage.GetStorageMap(storedValue.address, storedValue.domain, true).
+			WriteValue(inter, storedValue.key, value)
+	}
+
+	err := storage.Commit(inter, false)
+	require.NoError(t, err)
+
+	return ledger.Payloads()
+}
+
+func accountPayloads(payloads []Payload, address common.Address) []Payload {
+	var result []Payload
+	for _, payload := range payloads {
+		if payload.Owner == address {
+			result = append(result, payload)
+		}
+	}
+	return result
+}
+
+// slabStorageIndices returns the storage indices of the slabs of the given payloads
+//
+func slabStorageIndices(payloads []Payload) map[uint64]struct{} {
+	storageIndices := map[uint64]struct{}{}
+	for _, payload := range payloads {
+		if !isSlabKey(payload.Key) {
+			continue
+		}
+		storageIndex := binary.BigEndian.Uint64([]byte(payload.Key[1:]))
+		storageIndices[storageIndex] = struct{}{}
+	}
+	return storageIndices
+}
+
+// assertNoOrphanedSlabs asserts that all slabs of the given payloads
+// are referenced by the stored values of their account
+//
+func assertNoOrphanedSlabs(t *testing.T, payloads []Payload) {
+	_, storage, _ := newTestInterpreter(t, payloads)
+
+	// Load all slabs and storage maps, so they are checked
+
+	for _, payload := range payloads {
+		if !isSlabKey(payload.Key) {
+			continue
+		}
+
+		var storageIndex atree.StorageIndex
+		copy(storageIndex[:], payload.Key[1:])
+
+		_, _, err := storage.Retrieve(atree.StorageID{
+			Address: atree.Address(payload.Owner),
+			Index:   storageIndex,
+		})
+		require.NoError(t, err)
+
+		for _, domain := range domains {
+			storage.GetStorageMap(payload.Owner, domain, false)
+		}
+	}
+
+	require.NoError(t, storage.CheckHealth())
+}
+
+var (
+	testOldType         = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "Old")
+	testNewType         = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "New")
+	testInnerType       = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "Inner")
+	testOldResourceType = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "OldResource")
+	testNewResourceType = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "NewResource")
+	testContainerType   = interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "Container")
+)
+
+func newTestOldComposite(inter *interpreter.Interpreter, x int64) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		utils.TestLocation,
+		testOldType.QualifiedIdentifier,
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			interpreter.NewCompositeField(nil, "x", interpreter.NewUnmeteredIntValueFromInt64(x)),
+			interpreter.NewCompositeField(nil, "staticType", interpreter.NewTypeValue(nil, testOldType)),
+		},
+		common.Address{},
+	)
+}
+
+func newTestInner(inter *interpreter.Interpreter, x int64) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		utils.TestLocation,
+		testInnerType.QualifiedIdentifier,
+		common.CompositeKindResource,
+		[]interpreter.CompositeField{
+			interpreter.NewCompositeField(nil, "x", interpreter.NewUnmeteredIntValueFromInt64(x)),
+		},
+		common.Address{},
+	)
+}
+
+func newTestOldResource(inter *interpreter.Interpreter, x int64) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		utils.TestLocation,
+		testOldResourceType.QualifiedIdentifier,
+		common.CompositeKindResource,
+		[]interpreter.CompositeField{
+			interpreter.NewCompositeField(nil, "inner", newTestInner(inter, x)),
+		},
+		common.Address{},
+	)
+}
+
+// testRenamedTypes are the composite types which are renamed by the rename migration
+//
+var testRenamedTypes = map[string]interpreter.CompositeStaticType{
+	testOldType.QualifiedIdentifier:         testNewType,
+	testOldResourceType.QualifiedIdentifier: testNewResourceType,
+}
+
+// newTestRenameMigration returns a migration which renames the composite type `Old` to `New`,
+// and the resource type `OldResource` to `NewResource`
+//
+func newTestRenameMigration() *ValueMigration {
+	return &ValueMigration{
+		Name: "rename",
+		MigrateComposite: func(
+			inter *interpreter.Interpreter,
+			value *interpreter.CompositeValue,
+		) (
+			interpreter.Value,
+			error,
+		) {
+			newType, ok := testRenamedTypes[value.QualifiedIdentifier]
+			if !ok {
+				return nil, nil
+			}
+
+			var fields []interpreter.CompositeField
+
+			if value.Kind == common.CompositeKindResource {
+				// Move the nested resources out of the replaced resource
+
+				var fieldNames []string
+				value.ForEachField(inter, func(fieldName string, _ interpreter.Value) {
+					fieldNames = append(fieldNames, fieldName)
+				})
+
+				for _, fieldName := range fieldNames {
+					fieldValue := value.RemoveMember(inter, interpreter.ReturnEmptyLocationRange, fieldName)
+					fields = append(fields, interpreter.NewCompositeField(nil, fieldName, fieldValue))
+				}
+			} else {
+				value.ForEachField(inter, func(fieldName string, fieldValue interpreter.Value) {
+					fieldValue = fieldValue.Transfer(
+						inter,
+						interpreter.ReturnEmptyLocationRange,
+						atree.Address{},
+						false,
+						nil,
+					)
+					fields = append(fields, interpreter.NewCompositeField(nil, fieldName, fieldValue))
+				})
+			}
+
+			return interpreter.NewCompositeValue(
+				inter,
+				interpreter.ReturnEmptyLocationRange,
+				value.Location,
+				newType.QualifiedIdentifier,
+				value.Kind,
+				fields,
+				common.Address{},
+			), nil
+		},
+		MigrateStaticType: func(staticType interpreter.StaticType) (interpreter.StaticType, error) {
+			compositeType, ok := staticType.(interpreter.CompositeStaticType)
+			if !ok || compositeType.Location != utils.TestLocation {
+				return nil, nil
+			}
+
+			newType, ok := testRenamedTypes[compositeType.QualifiedIdentifier]
+			if !ok {
+				return nil, nil
+			}
+			return newType, nil
+		},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+	storageDomain := common.PathDomainStorage.Identifier()
+	publicDomain := common.PathDomainPublic.Identifier()
+
+	oldReferenceType := interpreter.NewReferenceStaticType(nil, false, testOldType, nil)
+	newReferenceType := interpreter.NewReferenceStaticType(nil, false, testNewType, nil)
+
+	path := interpreter.NewUnmeteredPathValue(common.PathDomainStorage, "target")
+
+	payloads := newTestPayloads(t, []testStoredValue{
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "type",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewTypeValue(
+					inter,
+					interpreter.NewOptionalStaticType(inter, testOldType),
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "composite",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return newTestOldComposite(inter, 1)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "array",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewArrayValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewVariableSizedStaticType(inter, testOldType),
+					common.Address{},
+					newTestOldComposite(inter, 2),
+					newTestOldComposite(inter, 3),
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "dictionary",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewDictionaryValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewDictionaryStaticType(
+						inter,
+						interpreter.PrimitiveStaticTypeString,
+						interpreter.PrimitiveStaticTypeMetaType,
+					),
+					interpreter.NewUnmeteredStringValue("a"),
+					interpreter.NewTypeValue(inter, testOldType),
+					interpreter.NewUnmeteredStringValue("b"),
+					interpreter.NewTypeValue(inter, interpreter.PrimitiveStaticTypeInt),
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "capability",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewCapabilityValue(
+					inter,
+					interpreter.AddressValue(address),
+					path,
+					oldReferenceType,
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  publicDomain,
+			key:     "link",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewLinkValue(inter, path, oldReferenceType)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "unchanged",
+			value: func(_ *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewUnmeteredIntValueFromInt64(42)
+			},
+		},
+	})
+
+	result := Migrate(
+		payloads,
+		Config{
+			Migrations: []*ValueMigration{
+				newTestRenameMigration(),
+			},
+			InterpreterOptions: []interpreter.Option{
+				newTestImportOption(t),
+			},
+		},
+	)
+
+	require.Empty(t, result.Failures)
+
+	assert.Equal(t,
+		[]MigratedValue{
+			{Address: address, Domain: publicDomain, Key: "link", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "array", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "capability", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "composite", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "dictionary", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "type", Migrations: []string{"rename"}},
+		},
+		result.MigratedValues,
+	)
+
+	// Read back the migrated values
+
+	inter, storage, _ := newTestInterpreter(t, result.Payloads)
+
+	storageMap := storage.GetStorageMap(address, storageDomain, false)
+	require.NotNil(t, storageMap)
+
+	assertNewComposite := func(value interpreter.Value, x int64) {
+		require.IsType(t, &interpreter.CompositeValue{}, value)
+		composite := value.(*interpreter.CompositeValue)
+
+		assert.Equal(t, testNewType.QualifiedIdentifier, composite.QualifiedIdentifier)
+		assert.Equal(t,
+			interpreter.NewUnmeteredIntValueFromInt64(x),
+			composite.GetField(inter, interpreter.ReturnEmptyLocationRange, "x"),
+		)
+		assert.Equal(t,
+			interpreter.NewTypeValue(nil, testNewType),
+			composite.GetField(inter, interpreter.ReturnEmptyLocationRange, "staticType"),
+		)
+	}
+
+	assert.Equal(t,
+		interpreter.NewTypeValue(nil, interpreter.NewOptionalStaticType(nil, testNewType)),
+		storageMap.ReadValue(inter, "type"),
+	)
+
+	assertNewComposite(storageMap.ReadValue(inter, "composite"), 1)
+
+	array := storageMap.ReadValue(inter, "array")
+	require.IsType(t, &interpreter.ArrayValue{}, array)
+	assert.Equal(t,
+		interpreter.NewVariableSizedStaticType(nil, testNewType),
+		array.(*interpreter.ArrayValue).Type,
+	)
+	require.Equal(t, 2, array.(*interpreter.ArrayValue).Count())
+	assertNewComposite(array.(*interpreter.ArrayValue).Get(inter, interpreter.ReturnEmptyLocationRange, 0), 2)
+	assertNewComposite(array.(*interpreter.ArrayValue).Get(inter, interpreter.ReturnEmptyLocationRange, 1), 3)
+
+	dictionary := storageMap.ReadValue(inter, "dictionary")
+	require.IsType(t, &interpreter.DictionaryValue{}, dictionary)
+
+	element, ok := dictionary.(*interpreter.DictionaryValue).Get(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		interpreter.NewUnmeteredStringValue("a"),
+	)
+	require.True(t, ok)
+	assert.Equal(t, interpreter.NewTypeValue(nil, testNewType), element)
+
+	element, ok = dictionary.(*interpreter.DictionaryValue).Get(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		interpreter.NewUnmeteredStringValue("b"),
+	)
+	require.True(t, ok)
+	assert.Equal(t, interpreter.NewTypeValue(nil, interpreter.PrimitiveStaticTypeInt), element)
+
+	capability := storageMap.ReadValue(inter, "capability")
+	require.IsType(t, &interpreter.CapabilityValue{}, capability)
+	assert.Equal(t, newReferenceType, capability.(*interpreter.CapabilityValue).BorrowType)
+
+	assert.Equal(t,
+		interpreter.NewIntValueFromInt64(nil, 42),
+		storageMap.ReadValue(inter, "unchanged"),
+	)
+
+	link := storage.GetStorageMap(address, publicDomain, false).ReadValue(inter, "link")
+	assert.Equal(t, interpreter.NewUnmeteredLinkValue(path, newReferenceType), link)
+
+	assertNoOrphanedSlabs(t, result.Payloads)
+}
+
+func TestMigrateResources(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+	storageDomain := common.PathDomainStorage.Identifier()
+
+	payloads := newTestPayloads(t, []testStoredValue{
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "resource",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return newTestOldResource(inter, 1)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "array",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewArrayValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewVariableSizedStaticType(inter, testOldResourceType),
+					common.Address{},
+					newTestOldResource(inter, 2),
+					newTestOldResource(inter, 3),
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "dictionary",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewDictionaryValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewDictionaryStaticType(
+						inter,
+						interpreter.PrimitiveStaticTypeString,
+						testOldResourceType,
+					),
+					interpreter.NewUnmeteredStringValue("a"),
+					newTestOldResource(inter, 4),
+				)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "container",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewCompositeValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					utils.TestLocation,
+					testContainerType.QualifiedIdentifier,
+					common.CompositeKindResource,
+					[]interpreter.CompositeField{
+						interpreter.NewCompositeField(
+							nil,
+							"resources",
+							interpreter.NewArrayValue(
+								inter,
+								interpreter.ReturnEmptyLocationRange,
+								interpreter.NewVariableSizedStaticType(inter, testOldResourceType),
+								common.Address{},
+								newTestOldResource(inter, 5),
+							),
+						),
+					},
+					common.Address{},
+				)
+			},
+		},
+	})
+
+	result := Migrate(
+		payloads,
+		Config{
+			Migrations: []*ValueMigration{
+				newTestRenameMigration(),
+			},
+			InterpreterOptions: []interpreter.Option{
+				newTestImportOption(t),
+			},
+		},
+	)
+
+	require.Empty(t, result.Failures)
+
+	assert.Equal(t,
+		[]MigratedValue{
+			{Address: address, Domain: storageDomain, Key: "array", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "container", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "dictionary", Migrations: []string{"rename"}},
+			{Address: address, Domain: storageDomain, Key: "resource", Migrations: []string{"rename"}},
+		},
+		result.MigratedValues,
+	)
+
+	// The replaced resources, and the replaced arrays and dictionaries of resources,
+	// are removed from storage, but the nested resources which were moved are kept
+
+	assertNoOrphanedSlabs(t, result.Payloads)
+
+	// Read back the migrated values
+
+	inter, storage, _ := newTestInterpreter(t, result.Payloads)
+
+	storageMap := storage.GetStorageMap(address, storageDomain, false)
+	require.NotNil(t, storageMap)
+
+	assertNewResource := func(value interpreter.Value, x int64) {
+		require.IsType(t, &interpreter.CompositeValue{}, value)
+		resource := value.(*interpreter.CompositeValue)
+
+		assert.Equal(t, testNewResourceType.QualifiedIdentifier, resource.QualifiedIdentifier)
+
+		inner := resource.GetField(inter, interpreter.ReturnEmptyLocationRange, "inner")
+		require.IsType(t, &interpreter.CompositeValue{}, inner)
+
+		assert.Equal(t, testInnerType.QualifiedIdentifier, inner.(*interpreter.CompositeValue).QualifiedIdentifier)
+		assert.Equal(t,
+			interpreter.NewUnmeteredIntValueFromInt64(x),
+			inner.(*interpreter.CompositeValue).GetField(inter, interpreter.ReturnEmptyLocationRange, "x"),
+		)
+	}
+
+	assertNewResources := func(value interpreter.Value, xs ...int64) {
+		require.IsType(t, &interpreter.ArrayValue{}, value)
+		array := value.(*interpreter.ArrayValue)
+
+		assert.Equal(t,
+			interpreter.NewVariableSizedStaticType(nil, testNewResourceType),
+			array.Type,
+		)
+		require.Equal(t, len(xs), array.Count())
+		for i, x := range xs {
+			assertNewResource(array.Get(inter, interpreter.ReturnEmptyLocationRange, i), x)
+		}
+	}
+
+	assertNewResource(storageMap.ReadValue(inter, "resource"), 1)
+
+	assertNewResources(storageMap.ReadValue(inter, "array"), 2, 3)
+
+	dictionary := storageMap.ReadValue(inter, "dictionary")
+	require.IsType(t, &interpreter.DictionaryValue{}, dictionary)
+	assert.Equal(t,
+		interpreter.NewDictionaryStaticType(nil, interpreter.PrimitiveStaticTypeString, testNewResourceType),
+		dictionary.(*interpreter.DictionaryValue).Type,
+	)
+	require.Equal(t, 1, dictionary.(*interpreter.DictionaryValue).Count())
+
+	element, ok := dictionary.(*interpreter.DictionaryValue).Get(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		interpreter.NewUnmeteredStringValue("a"),
+	)
+	require.True(t, ok)
+	assertNewResource(element, 4)
+
+	container := storageMap.ReadValue(inter, "container")
+	require.IsType(t, &interpreter.CompositeValue{}, container)
+	assert.Equal(t, testContainerType.QualifiedIdentifier, container.(*interpreter.CompositeValue).QualifiedIdentifier)
+
+	assertNewResources(
+		container.(*interpreter.CompositeValue).GetField(inter, interpreter.ReturnEmptyLocationRange, "resources"),
+		5,
+	)
+}
+
+func TestMigrateFailure(t *testing.T) {
+
+	t.Parallel()
+
+	failingAddress := common.MustBytesToAddress([]byte{0x1})
+	migratedAddress := common.MustBytesToAddress([]byte{0x2})
+	storageDomain := common.PathDomainStorage.Identifier()
+
+	brokenType := interpreter.NewCompositeStaticTypeComputeTypeID(nil, utils.TestLocation, "Broken")
+	migrationErr := errors.New("broken")
+
+	payloads := newTestPayloads(t, []testStoredValue{
+		{
+			address: failingAddress,
+			domain:  storageDomain,
+			key:     "a",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewTypeValue(inter, testOldType)
+			},
+		},
+		{
+			address: failingAddress,
+			domain:  storageDomain,
+			key:     "b",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewTypeValue(inter, brokenType)
+			},
+		},
+		{
+			address: migratedAddress,
+			domain:  storageDomain,
+			key:     "a",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewTypeValue(inter, testOldType)
+			},
+		},
+	})
+
+	result := Migrate(
+		payloads,
+		Config{
+			Migrations: []*ValueMigration{
+				newTestRenameMigration(),
+				{
+					Name: "broken",
+					MigrateStaticType: func(staticType interpreter.StaticType) (interpreter.StaticType, error) {
+						if staticType.Equal(brokenType) {
+							return nil, migrationErr
+						}
+						return nil, nil
+					},
+				},
+			},
+		},
+	)
+
+	require.Len(t, result.Failures, 1)
+
+	failure := result.Failures[0]
+	assert.Equal(t, failingAddress, failure.Address)
+	assert.Equal(t, storageDomain, failure.Domain)
+	assert.Equal(t, "b", failure.Key)
+	assert.ErrorIs(t, failure, migrationErr)
+
+	// The payloads of the failed account are unchanged,
+	// even though some of its values were already migrated
+
+	assert.Equal(t,
+		accountPayloads(payloads, failingAddress),
+		accountPayloads(result.Payloads, failingAddress),
+	)
+
+	assert.Equal(t,
+		[]MigratedValue{
+			{Address: migratedAddress, Domain: storageDomain, Key: "a", Migrations: []string{"rename"}},
+		},
+		result.MigratedValues,
+	)
+
+	inter, storage, _ := newTestInterpreter(t, result.Payloads)
+
+	assert.Equal(t,
+		interpreter.NewTypeValue(nil, testNewType),
+		storage.GetStorageMap(migratedAddress, storageDomain, false).ReadValue(inter, "a"),
+	)
+}
+
+func TestMigrateDeterministic(t *testing.T) {
+
+	t.Parallel()
+
+	const accountCount = 50
+
+	storageDomain := common.PathDomainStorage.Identifier()
+
+	var storedValues []testStoredValue
+
+	for i := 0; i < accountCount; i++ {
+		address := common.MustBytesToAddress([]byte{0x1, byte(i)})
+
+		for j := 0; j <= i%5; j++ {
+			x := int64(j)
+			storedValues = append(
+				storedValues,
+				testStoredValue{
+					address: address,
+					domain:  storageDomain,
+					key:     fmt.Sprintf("value%d", j),
+					value: func(inter *interpreter.Interpreter) interpreter.Value {
+						return interpreter.NewArrayValue(
+							inter,
+							interpreter.ReturnEmptyLocationRange,
+							interpreter.NewVariableSizedStaticType(inter, testOldType),
+							common.Address{},
+							newTestOldComposite(inter, x),
+						)
+					},
+				},
+			)
+		}
+	}
+
+	payloads := newTestPayloads(t, storedValues)
+
+	migrate := func(workers int) *Result {
+		return Migrate(
+			payloads,
+			Config{
+				Migrations: []*ValueMigration{
+					newTestRenameMigration(),
+				},
+				Workers: workers,
+				InterpreterOptions: []interpreter.Option{
+					newTestImportOption(t),
+				},
+			},
+		)
+	}
+
+	sequentialResult := migrate(1)
+	require.Empty(t, sequentialResult.Failures)
+	require.Len(t, sequentialResult.MigratedValues, len(storedValues))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, sequentialResult, migrate(8))
+	}
+}
+
+func TestMigrateStorageIndexAllocation(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+	storageDomain := common.PathDomainStorage.Identifier()
+
+	payloads := newTestPayloads(t, []testStoredValue{
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "array",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewArrayValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewVariableSizedStaticType(inter, testOldType),
+					common.Address{},
+					newTestOldComposite(inter, 1),
+				)
+			},
+		},
+	})
+
+	existingStorageIndices := slabStorageIndices(payloads)
+
+	// The embedder keeps track of the next storage index of the account,
+	// which may be greater than the storage indices of the existing slabs,
+	// e.g. because slabs were removed
+
+	var greatestStorageIndex uint64
+	for storageIndex := range existingStorageIndices { //nolint:maprangecheck
+		if storageIndex > greatestStorageIndex {
+			greatestStorageIndex = storageIndex
+		}
+	}
+
+	firstAllocatedStorageIndex := greatestStorageIndex + 10
+	nextStorageIndex := firstAllocatedStorageIndex
+
+	allocate := func(allocationAddress common.Address) (atree.StorageIndex, error) {
+		require.Equal(t, address, allocationAddress)
+
+		var storageIndex atree.StorageIndex
+		binary.BigEndian.PutUint64(storageIndex[:], nextStorageIndex)
+		nextStorageIndex++
+		return storageIndex, nil
+	}
+
+	// Rebuilding the array and replacing the composite allocate new slabs
+
+	result := Migrate(
+		payloads,
+		Config{
+			Migrations: []*ValueMigration{
+				newTestRenameMigration(),
+			},
+			InterpreterOptions: []interpreter.Option{
+				newTestImportOption(t),
+			},
+			AllocateStorageIndex: allocate,
+		},
+	)
+
+	require.Empty(t, result.Failures)
+
+	migratedStorageIndices := slabStorageIndices(result.Payloads)
+
+	var newSlabCount int
+	for storageIndex := range migratedStorageIndices { //nolint:maprangecheck
+		if _, ok := existingStorageIndices[storageIndex]; ok {
+			continue
+		}
+
+		newSlabCount++
+		assert.GreaterOrEqual(t, storageIndex, firstAllocatedStorageIndex)
+		assert.Less(t, storageIndex, nextStorageIndex)
+	}
+	assert.Greater(t, newSlabCount, 0)
+
+	// Allocating again, e.g. when the embedder stores a new value,
+	// does not collide with the slabs of the migrated values
+
+	storageIndex, err := allocate(address)
+	require.NoError(t, err)
+
+	assert.NotContains(t, migratedStorageIndices, binary.BigEndian.Uint64(storageIndex[:]))
+
+	inter, storage, _ := newTestInterpreter(t, result.Payloads)
+
+	array := storage.GetStorageMap(address, storageDomain, false).ReadValue(inter, "array")
+	require.IsType(t, &interpreter.ArrayValue{}, array)
+	assert.Equal(t,
+		interpreter.NewVariableSizedStaticType(nil, testNewType),
+		array.(*interpreter.ArrayValue).Type,
+	)
+}
+
+func TestMigrateCompositeInPlace(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+	storageDomain := common.PathDomainStorage.Identifier()
+
+	payloads := newTestPayloads(t, []testStoredValue{
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "composite",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return newTestOldComposite(inter, 1)
+			},
+		},
+		{
+			address: address,
+			domain:  storageDomain,
+			key:     "array",
+			value: func(inter *interpreter.Interpreter) interpreter.Value {
+				return interpreter.NewArrayValue(
+					inter,
+					interpreter.ReturnEmptyLocationRange,
+					interpreter.NewVariableSizedStaticType(inter, testOldType),
+					common.Address{},
+					newTestOldComposite(inter, 2),
+				)
+			},
+		},
+	})
+
+	// The migration updates the composite values in place, and returns them
+
+	result := Migrate(
+		payloads,
+		Config{
+			Migrations: []*ValueMigration{
+				{
+					Name: "increment",
+					MigrateComposite: func(
+						inter *interpreter.Interpreter,
+						value *interpreter.CompositeValue,
+					) (
+						interpreter.Value,
+						error,
+					) {
+						x := value.GetField(inter, interpreter.ReturnEmptyLocationRange, "x").(interpreter.IntValue)
+
+						value.SetMember(
+							inter,
+							interpreter.ReturnEmptyLocationRange,
+							"x",
+							x.Plus(inter, interpreter.NewUnmeteredIntValueFromInt64(10)),
+						)
+
+						return value, nil
+					},
+				},
+			},
+		},
+	)
+
+	require.Empty(t, result.Failures)
+
+	assert.Equal(t,
+		[]MigratedValue{
+			{Address: address, Domain: storageDomain, Key: "array", Migrations: []string{"increment"}},
+			{Address: address, Domain: storageDomain, Key: "composite", Migrations: []string{"increment"}},
+		},
+		result.MigratedValues,
+	)
+
+	// The composite values are kept, and not removed from storage
+
+	assert.Equal(t,
+		slabStorageIndices(payloads),
+		slabStorageIndices(result.Payloads),
+	)
+
+	inter, storage, _ := newTestInterpreter(t, result.Payloads)
+
+	storageMap := storage.GetStorageMap(address, storageDomain, false)
+	require.NotNil(t, storageMap)
+
+	composite := storageMap.ReadValue(inter, "composite")
+	require.IsType(t, &interpreter.CompositeValue{}, composite)
+	assert.Equal(t,
+		interpreter.NewUnmeteredIntValueFromInt64(11),
+		composite.(*interpreter.CompositeValue).GetField(inter, interpreter.ReturnEmptyLocationRange, "x"),
+	)
+
+	array := storageMap.ReadValue(inter, "array")
+	require.IsType(t, &interpreter.ArrayValue{}, array)
+
+	element := array.(*interpreter.ArrayValue).Get(inter, interpreter.ReturnEmptyLocationRange, 0)
+	require.IsType(t, &interpreter.CompositeValue{}, element)
+	assert.Equal(t,
+		interpreter.NewUnmeteredIntValueFromInt64(12),
+		element.(*interpreter.CompositeValue).GetField(inter, interpreter.ReturnEmptyLocationRange, "x"),
+	)
+}
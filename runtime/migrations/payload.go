@@ -0,0 +1,201 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// Payload is a single register of the state,
+// i.e. a value for a key, owned by an account.
+//
+type Payload struct {
+	Owner common.Address
+	Key   string
+	Value []byte
+}
+
+// '$' + 8 byte storage index
+const slabKeyLength = 1 + 8
+
+func isSlabKey(key string) bool {
+	return len(key) == slabKeyLength && key[0] == '$'
+}
+
+type registerKey struct {
+	owner common.Address
+	key   string
+}
+
+// StorageIndexAllocator allocates a new storage index for a slab of the given account.
+//
+type StorageIndexAllocator func(address common.Address) (atree.StorageIndex, error)
+
+// payloadLedger is an atree.Ledger which is backed by payloads.
+//
+// New storage indices are allocated using the given allocator, if any.
+// Otherwise, they are allocated after the greatest storage index
+// of the existing slabs of an account, so allocation is deterministic.
+//
+type payloadLedger struct {
+	registers            map[registerKey][]byte
+	nextStorageIndices   map[common.Address]uint64
+	allocateStorageIndex StorageIndexAllocator
+}
+
+var _ atree.Ledger = &payloadLedger{}
+
+func newPayloadLedger(payloads []Payload, allocateStorageIndex StorageIndexAllocator) *payloadLedger {
+	ledger := &payloadLedger{
+		registers:            make(map[registerKey][]byte, len(payloads)),
+		nextStorageIndices:   map[common.Address]uint64{},
+		allocateStorageIndex: allocateStorageIndex,
+	}
+
+	for _, payload := range payloads {
+		ledger.set(payload.Owner, payload.Key, payload.Value)
+	}
+
+	return ledger
+}
+
+func (l *payloadLedger) set(owner common.Address, key string, value []byte) {
+	registerKey := registerKey{
+		owner: owner,
+		key:   key,
+	}
+
+	if len(value) == 0 {
+		delete(l.registers, registerKey)
+		return
+	}
+
+	l.registers[registerKey] = value
+
+	if isSlabKey(key) {
+		storageIndex := binary.BigEndian.Uint64([]byte(key[1:]))
+		if storageIndex >= l.nextStorageIndices[owner] {
+			l.nextStorageIndices[owner] = storageIndex + 1
+		}
+	}
+}
+
+func (l *payloadLedger) GetValue(owner, key []byte) ([]byte, error) {
+	address, err := common.BytesToAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.registers[registerKey{
+		owner: address,
+		key:   string(key),
+	}], nil
+}
+
+func (l *payloadLedger) SetValue(owner, key, value []byte) error {
+	address, err := common.BytesToAddress(owner)
+	if err != nil {
+		return err
+	}
+
+	// Copy the value, as the caller may reuse the slice
+	l.set(address, string(key), append([]byte(nil), value...))
+
+	return nil
+}
+
+func (l *payloadLedger) ValueExists(owner, key []byte) (bool, error) {
+	value, err := l.GetValue(owner, key)
+	if err != nil {
+		return false, err
+	}
+
+	return len(value) > 0, nil
+}
+
+func (l *payloadLedger) AllocateStorageIndex(owner []byte) (atree.StorageIndex, error) {
+	address, err := common.BytesToAddress(owner)
+	if err != nil {
+		return atree.StorageIndex{}, err
+	}
+
+	if l.allocateStorageIndex != nil {
+		return l.allocateStorageIndex(address)
+	}
+
+	storageIndex := l.nextStorageIndices[address]
+	if storageIndex == 0 {
+		// Storage index 0 is reserved as undefined
+		storageIndex = 1
+	}
+	if storageIndex == ^uint64(0) {
+		return atree.StorageIndex{}, fmt.Errorf("storage indices of account %s are exhausted", address)
+	}
+
+	l.nextStorageIndices[address] = storageIndex + 1
+
+	var result atree.StorageIndex
+	binary.BigEndian.PutUint64(result[:], storageIndex)
+	return result, nil
+}
+
+// Payloads returns all payloads of the ledger, sorted by owner and key.
+//
+func (l *payloadLedger) Payloads() []Payload {
+	payloads := make([]Payload, 0, len(l.registers))
+
+	// NOTE: ranging over maps is safe (deterministic),
+	// if it is side effect free and the payloads are sorted afterwards
+
+	for registerKey, value := range l.registers { //nolint:maprangecheck
+		payloads = append(
+			payloads,
+			Payload{
+				Owner: registerKey.owner,
+				Key:   registerKey.key,
+				Value: value,
+			},
+		)
+	}
+
+	sortPayloads(payloads)
+
+	return payloads
+}
+
+func sortPayloads(payloads []Payload) {
+	sort.Slice(payloads, func(i, j int) bool {
+		a := payloads[i]
+		b := payloads[j]
+
+		ownerComparison := bytes.Compare(a.Owner[:], b.Owner[:])
+		if ownerComparison != 0 {
+			return ownerComparison < 0
+		}
+
+		return a.Key < b.Key
+	})
+}
@@ -0,0 +1,465 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrations
+
+import (
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// accountMigrator applies the value migrations to the stored values of an account.
+//
+// Failures are reported by panicking, and are recovered for the whole account.
+//
+type accountMigrator struct {
+	inter      *interpreter.Interpreter
+	migrations []*ValueMigration
+	// applied are the names of the migrations which replaced values
+	// in the currently migrated stored value
+	applied []string
+}
+
+func (m *accountMigrator) recordMigration(name string) {
+	for _, applied := range m.applied {
+		if applied == name {
+			return
+		}
+	}
+	m.applied = append(m.applied, name)
+}
+
+// migrateValue migrates the given value, and all values nested in it.
+// Nested containers are migrated in place, unless their static type is migrated.
+//
+// It returns the value which replaces the given value, or nil if the value is kept.
+//
+func (m *accountMigrator) migrateValue(value interpreter.Value) interpreter.Value {
+	inter := m.inter
+	getLocationRange := interpreter.ReturnEmptyLocationRange
+
+	switch value := value.(type) {
+	case *interpreter.SomeValue:
+		innerValue := value.InnerValue(inter, getLocationRange)
+		newInnerValue := m.migrateValue(innerValue)
+		if newInnerValue == nil {
+			return nil
+		}
+		return interpreter.NewSomeValueNonCopying(inter, newInnerValue)
+
+	case *interpreter.ArrayValue:
+		newType := m.migrateStaticType(value.Type)
+		if newType != nil {
+			return m.rebuildArray(value, newType)
+		}
+
+		count := value.Count()
+		for i := 0; i < count; i++ {
+			element := value.Get(inter, getLocationRange, i)
+			newElement := m.migrateValue(element)
+			if newElement != nil {
+				value.Set(inter, getLocationRange, i, newElement)
+			}
+		}
+		return nil
+
+	case *interpreter.DictionaryValue:
+		newType := m.migrateStaticType(value.Type)
+		if newType != nil {
+			return m.rebuildDictionary(value, newType)
+		}
+
+		for _, key := range m.dictionaryKeys(value) {
+			element, ok := value.Get(inter, getLocationRange, key)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			newElement := m.migrateValue(element)
+			if newElement != nil {
+				value.Insert(inter, getLocationRange, key, newElement)
+			}
+		}
+		return nil
+
+	case *interpreter.CompositeValue:
+		return m.migrateComposite(value)
+
+	case interpreter.TypeValue:
+		newType := m.migrateStaticType(value.Type)
+		if newType == nil {
+			return nil
+		}
+		return interpreter.NewTypeValue(inter, newType)
+
+	case *interpreter.CapabilityValue:
+		newBorrowType := m.migrateStaticType(value.BorrowType)
+		if newBorrowType == nil {
+			return nil
+		}
+		return interpreter.NewCapabilityValue(
+			inter,
+			value.Address,
+			value.Path,
+			newBorrowType,
+		)
+
+	case interpreter.LinkValue:
+		newType := m.migrateStaticType(value.Type)
+		if newType == nil {
+			return nil
+		}
+		return interpreter.NewLinkValue(
+			inter,
+			value.TargetPath,
+			newType,
+		)
+	}
+
+	return nil
+}
+
+// rebuildArray replaces the given array, the static type of which was migrated to the given static type.
+//
+// The elements are moved out of the array and migrated, and a new array with the new type is returned.
+// The array is left empty, so removing it from storage does not remove the moved elements.
+//
+func (m *accountMigrator) rebuildArray(
+	value *interpreter.ArrayValue,
+	newType interpreter.StaticType,
+) interpreter.Value {
+	inter := m.inter
+	getLocationRange := interpreter.ReturnEmptyLocationRange
+
+	arrayType, ok := newType.(interpreter.ArrayStaticType)
+	if !ok {
+		panic(&InvalidContainerTypeMigrationError{
+			Type:   value.Type,
+			Result: newType,
+		})
+	}
+
+	count := value.Count()
+	elements := make([]interpreter.Value, count)
+	for i := count - 1; i >= 0; i-- {
+		elements[i] = value.Remove(inter, getLocationRange, i)
+	}
+
+	for i, element := range elements {
+		newElement := m.migrateValue(element)
+		if newElement != nil {
+			elements[i] = newElement
+		}
+	}
+
+	return interpreter.NewArrayValue(
+		inter,
+		getLocationRange,
+		arrayType,
+		common.Address{},
+		elements...,
+	)
+}
+
+// rebuildDictionary replaces the given dictionary, the static type of which was migrated to the given static type.
+//
+// The values are moved out of the dictionary and migrated, and a new dictionary with the new type is returned.
+// The dictionary is left empty, so removing it from storage does not remove the moved values.
+//
+func (m *accountMigrator) rebuildDictionary(
+	value *interpreter.DictionaryValue,
+	newType interpreter.StaticType,
+) interpreter.Value {
+	inter := m.inter
+	getLocationRange := interpreter.ReturnEmptyLocationRange
+
+	dictionaryType, ok := newType.(interpreter.DictionaryStaticType)
+	if !ok {
+		panic(&InvalidContainerTypeMigrationError{
+			Type:   value.Type,
+			Result: newType,
+		})
+	}
+
+	keys := m.dictionaryKeys(value)
+	keysAndValues := make([]interpreter.Value, 0, len(keys)*2)
+
+	for _, key := range keys {
+		// Copy the key, as removing the entry removes the stored key
+		key = key.Transfer(inter, getLocationRange, atree.Address{}, false, nil)
+
+		existing, ok := value.Remove(inter, getLocationRange, key).(*interpreter.SomeValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		element := existing.InnerValue(inter, getLocationRange)
+		newElement := m.migrateValue(element)
+		if newElement != nil {
+			element = newElement
+		}
+
+		keysAndValues = append(keysAndValues, key, element)
+	}
+
+	return interpreter.NewDictionaryValueWithAddress(
+		inter,
+		getLocationRange,
+		dictionaryType,
+		common.Address{},
+		keysAndValues...,
+	)
+}
+
+// dictionaryKeys returns the keys of the given dictionary.
+// The keys are collected first, as the dictionary must not be mutated while iterating over it.
+// Keys are not migrated.
+//
+func (m *accountMigrator) dictionaryKeys(value *interpreter.DictionaryValue) []interpreter.Value {
+	keys := make([]interpreter.Value, 0, value.Count())
+	value.Iterate(m.inter, func(key, _ interpreter.Value) (resume bool) {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (m *accountMigrator) migrateComposite(value *interpreter.CompositeValue) interpreter.Value {
+	inter := m.inter
+	getLocationRange := interpreter.ReturnEmptyLocationRange
+
+	// Migrate the fields first.
+	// Collect the field names first, as the composite must not be mutated while iterating over it
+
+	var fieldNames []string
+	value.ForEachField(inter, func(fieldName string, _ interpreter.Value) {
+		fieldNames = append(fieldNames, fieldName)
+	})
+
+	for _, fieldName := range fieldNames {
+		fieldValue := value.GetField(inter, getLocationRange, fieldName)
+		newFieldValue := m.migrateValue(fieldValue)
+		if newFieldValue != nil {
+			value.SetMember(inter, getLocationRange, fieldName, newFieldValue)
+		}
+	}
+
+	// Then migrate the composite itself
+
+	var result interpreter.Value
+
+	for _, migration := range m.migrations {
+		if migration.MigrateComposite == nil {
+			continue
+		}
+
+		newValue, err := migration.MigrateComposite(inter, value)
+		if err != nil {
+			panic(err)
+		}
+		if newValue == nil {
+			continue
+		}
+
+		m.recordMigration(migration.Name)
+
+		// The composite value may have been updated in place and returned.
+		// It must be kept, as replacing it would remove it from storage
+
+		if isSameComposite(newValue, value) {
+			continue
+		}
+
+		result = newValue
+
+		// Later composite migrations only apply if the replacement is a composite
+
+		newComposite, ok := newValue.(*interpreter.CompositeValue)
+		if !ok {
+			break
+		}
+		value = newComposite
+	}
+
+	return result
+}
+
+// isSameComposite returns true if the given value is the given composite value,
+// i.e. if it is stored in the same slab.
+//
+func isSameComposite(value interpreter.Value, composite *interpreter.CompositeValue) bool {
+	otherComposite, ok := value.(*interpreter.CompositeValue)
+	return ok && otherComposite.StorageID() == composite.StorageID()
+}
+
+// migrateStaticType migrates the given static type, and all static types nested in it.
+//
+// It returns the static type which replaces the given static type, or nil if the static type is kept.
+//
+func (m *accountMigrator) migrateStaticType(staticType interpreter.StaticType) interpreter.StaticType {
+	if staticType == nil {
+		return nil
+	}
+
+	result := m.migrateNestedStaticTypes(staticType)
+	if result != nil {
+		staticType = result
+	}
+
+	for _, migration := range m.migrations {
+		if migration.MigrateStaticType == nil {
+			continue
+		}
+
+		newType, err := migration.MigrateStaticType(staticType)
+		if err != nil {
+			panic(err)
+		}
+		if newType == nil {
+			continue
+		}
+
+		m.recordMigration(migration.Name)
+		result = newType
+		staticType = newType
+	}
+
+	return result
+}
+
+// migrateNestedStaticTypes migrates the static types nested in the given static type.
+//
+// It returns the static type with the migrated nested static types,
+// or nil if no nested static type was migrated.
+//
+func (m *accountMigrator) migrateNestedStaticTypes(staticType interpreter.StaticType) interpreter.StaticType {
+	inter := m.inter
+
+	switch staticType := staticType.(type) {
+	case interpreter.OptionalStaticType:
+		newType := m.migrateStaticType(staticType.Type)
+		if newType != nil {
+			return interpreter.NewOptionalStaticType(inter, newType)
+		}
+
+	case interpreter.VariableSizedStaticType:
+		newElementType := m.migrateStaticType(staticType.Type)
+		if newElementType != nil {
+			return interpreter.NewVariableSizedStaticType(inter, newElementType)
+		}
+
+	case interpreter.ConstantSizedStaticType:
+		newElementType := m.migrateStaticType(staticType.Type)
+		if newElementType != nil {
+			return interpreter.NewConstantSizedStaticType(inter, newElementType, staticType.Size)
+		}
+
+	case interpreter.DictionaryStaticType:
+		newKeyType := m.migrateStaticType(staticType.KeyType)
+		newValueType := m.migrateStaticType(staticType.ValueType)
+		if newKeyType != nil || newValueType != nil {
+			if newKeyType == nil {
+				newKeyType = staticType.KeyType
+			}
+			if newValueType == nil {
+				newValueType = staticType.ValueType
+			}
+			return interpreter.NewDictionaryStaticType(inter, newKeyType, newValueType)
+		}
+
+	case interpreter.ReferenceStaticType:
+		newBorrowedType := m.migrateStaticType(staticType.BorrowedType)
+		if newBorrowedType != nil {
+			return interpreter.NewReferenceStaticType(
+				inter,
+				staticType.Authorized,
+				newBorrowedType,
+				staticType.ReferencedType,
+			)
+		}
+
+	case interpreter.CapabilityStaticType:
+		newBorrowType := m.migrateStaticType(staticType.BorrowType)
+		if newBorrowType != nil {
+			return interpreter.NewCapabilityStaticType(inter, newBorrowType)
+		}
+
+	case *interpreter.RestrictedStaticType:
+		newType := m.migrateStaticType(staticType.Type)
+
+		var newRestrictions []interpreter.InterfaceStaticType
+
+		for i, restriction := range staticType.Restrictions {
+			newRestriction := m.migrateStaticType(restriction)
+			if newRestriction == nil {
+				continue
+			}
+
+			newInterfaceType, ok := newRestriction.(interpreter.InterfaceStaticType)
+			if !ok {
+				panic(&InvalidRestrictionMigrationError{
+					Restriction: restriction,
+					Result:      newRestriction,
+				})
+			}
+
+			if newRestrictions == nil {
+				newRestrictions = make([]interpreter.InterfaceStaticType, len(staticType.Restrictions))
+				copy(newRestrictions, staticType.Restrictions)
+			}
+			newRestrictions[i] = newInterfaceType
+		}
+
+		if newType != nil || newRestrictions != nil {
+			if newType == nil {
+				newType = staticType.Type
+			}
+			if newRestrictions == nil {
+				newRestrictions = staticType.Restrictions
+			}
+			return interpreter.NewRestrictedStaticType(inter, newType, newRestrictions)
+		}
+
+	case interpreter.CompositeStaticType:
+		var newTypeArguments []interpreter.StaticType
+
+		for i, typeArgument := range staticType.TypeArguments {
+			newTypeArgument := m.migrateStaticType(typeArgument)
+			if newTypeArgument == nil {
+				continue
+			}
+
+			if newTypeArguments == nil {
+				newTypeArguments = make([]interpreter.StaticType, len(staticType.TypeArguments))
+				copy(newTypeArguments, staticType.TypeArguments)
+			}
+			newTypeArguments[i] = newTypeArgument
+		}
+
+		if newTypeArguments != nil {
+			newType := staticType
+			newType.TypeArguments = newTypeArguments
+			return newType
+		}
+	}
+
+	return nil
+}
@@ -21,13 +21,17 @@ package parser
 import (
 	"strings"
 
+	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/parser/lexer"
 )
 
 const blockCommentStart = "/*"
 const blockCommentEnd = "*/"
 
-func (p *parser) parseCommentContent() (comment string) {
+// parseCommentContent parses the content of a block comment, including nested block comments,
+// and returns the comment including its delimiters, and the position of the end of the comment.
+//
+func (p *parser) parseCommentContent() (comment string, endPos ast.Position) {
 	var builder strings.Builder
 	defer func() {
 		comment = builder.String()
@@ -55,6 +59,8 @@ func (p *parser) parseCommentContent() (comment string) {
 
 				case lexer.TokenBlockCommentEnd:
 					builder.WriteString(blockCommentEnd)
+					// The end of the outermost comment is the last end encountered
+					endPos = p.current.EndPos
 					// Skip the comment end (`*/`)
 					p.next()
 					return nil
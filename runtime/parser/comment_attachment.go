@@ -0,0 +1,298 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"sort"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// recordComment records the given comment, if comments are recorded.
+//
+func (p *parser) recordComment(text string, startPos, endPos ast.Position) {
+	if !p.recordComments {
+		return
+	}
+
+	// The comment is not terminated, which is reported as a syntax error
+	if endPos == ast.EmptyPosition {
+		return
+	}
+
+	// Tokens are replayed when backtracking, so a comment may be encountered multiple times.
+	// Comments are recorded in the order they occur in the input,
+	// so a comment which does not start after the last recorded comment was already recorded
+
+	count := len(p.comments)
+	if count > 0 && p.comments[count-1].StartPos.Offset >= startPos.Offset {
+		return
+	}
+
+	p.comments = append(
+		p.comments,
+		ast.NewComment(
+			p.memoryGauge,
+			text,
+			ast.NewRange(
+				p.memoryGauge,
+				startPos,
+				endPos,
+			),
+		),
+	)
+}
+
+// attachComments attaches the given comments, which must be ordered by position,
+// to the declarations and statements of the given program.
+//
+// A comment is attached to the innermost declaration, statement, or switch case which contains it.
+// A comment on the same line as the end of an element is a trailing comment of the element.
+// Other comments are leading comments of the element which follows them.
+// If no element follows, the comment is a dangling comment of the enclosing element,
+// e.g. the block, or the program.
+// Comments inside an element which are not inside a nested declaration or statement,
+// e.g. comments inside an expression, become leading comments of the element.
+//
+func attachComments(program *ast.Program, comments []*ast.Comment) {
+	declarations := program.Declarations()
+
+	items := make([]ast.CommentedElement, 0, len(declarations))
+	for _, declaration := range declarations {
+		items = append(items, declaration)
+	}
+
+	attachContainerComments(&program.Comments, items, comments)
+}
+
+// attachContainerComments attaches the given comments,
+// which are all inside an element which contains the given items, e.g. the statements of a block,
+// to the items, or to the container itself.
+//
+func attachContainerComments(
+	containerComments *ast.Comments,
+	items []ast.CommentedElement,
+	comments []*ast.Comment,
+) {
+	innerComments := make([][]*ast.Comment, len(items))
+
+	itemIndex := 0
+
+	for _, comment := range comments {
+		commentOffset := comment.StartPos.Offset
+
+		// Skip the items which end before the comment
+		for itemIndex < len(items) &&
+			items[itemIndex].EndPosition(nil).Offset < commentOffset {
+
+			itemIndex++
+		}
+
+		var next ast.CommentedElement
+		if itemIndex < len(items) {
+			next = items[itemIndex]
+
+			if next.StartPosition().Offset <= commentOffset {
+				innerComments[itemIndex] = append(innerComments[itemIndex], comment)
+				continue
+			}
+		}
+
+		var previous ast.CommentedElement
+		if itemIndex > 0 {
+			previous = items[itemIndex-1]
+		}
+
+		switch {
+		case previous != nil &&
+			comment.StartPos.Line == previous.EndPosition(nil).Line &&
+			(next == nil || next.StartPosition().Line > comment.EndPos.Line):
+
+			previousComments := previous.ElementComments()
+			previousComments.Trailing = append(previousComments.Trailing, comment)
+
+		case next != nil:
+			nextComments := next.ElementComments()
+			nextComments.Leading = append(nextComments.Leading, comment)
+
+		default:
+			containerComments.Dangling = append(containerComments.Dangling, comment)
+		}
+	}
+
+	for index, comments := range innerComments {
+		if len(comments) == 0 {
+			continue
+		}
+
+		item := items[index]
+		remainingComments := attachElementComments(item, comments)
+		if len(remainingComments) == 0 {
+			continue
+		}
+
+		itemComments := item.ElementComments()
+		itemComments.Leading = append(itemComments.Leading, remainingComments...)
+	}
+}
+
+// attachElementComments attaches the given comments, which are all inside the given element,
+// to the nested declarations and statements of the element.
+//
+// Returns the comments which could not be attached to a nested declaration or statement.
+//
+func attachElementComments(element ast.HasPosition, comments []*ast.Comment) []*ast.Comment {
+	switch element := element.(type) {
+	case *ast.Block:
+		attachContainerComments(
+			&element.Comments,
+			statementItems(element.Statements),
+			comments,
+		)
+		return nil
+
+	case *ast.SwitchCase:
+		attachContainerComments(
+			&element.Comments,
+			statementItems(element.Statements),
+			comments,
+		)
+		return nil
+
+	case *ast.SwitchStatement:
+		items := make([]ast.CommentedElement, 0, len(element.Cases))
+		for _, switchCase := range element.Cases {
+			items = append(items, switchCase)
+		}
+
+		attachContainerComments(&element.Comments, items, comments)
+		return nil
+
+	case *ast.CompositeDeclaration:
+		// The members of events are not written,
+		// only the parameters of the initializer
+		if element.CompositeKind == common.CompositeKindEvent {
+			return comments
+		}
+
+		attachMembersComments(element.Members, comments)
+		return nil
+
+	case *ast.InterfaceDeclaration:
+		attachMembersComments(element.Members, comments)
+		return nil
+
+	case *ast.TransactionDeclaration:
+		var items []ast.CommentedElement
+		for _, field := range element.Fields {
+			items = append(items, field)
+		}
+		if element.Prepare != nil {
+			items = append(items, element.Prepare)
+		}
+		if element.Execute != nil {
+			items = append(items, element.Execute)
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].StartPosition().Offset < items[j].StartPosition().Offset
+		})
+
+		attachContainerComments(&element.Comments, items, comments)
+		return nil
+
+	case ast.Element:
+		return attachChildrenComments(element, comments)
+	}
+
+	return comments
+}
+
+// attachChildrenComments attaches the given comments, which are all inside the given element,
+// to the children of the element which contain them.
+//
+// Returns the comments which could not be attached to a nested declaration or statement.
+//
+func attachChildrenComments(element ast.Element, comments []*ast.Comment) []*ast.Comment {
+	var children []ast.Element
+	element.Walk(func(child ast.Element) {
+		children = append(children, child)
+	})
+
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].StartPosition().Offset < children[j].StartPosition().Offset
+	})
+
+	var remainingComments []*ast.Comment
+
+	commentIndex := 0
+
+	for _, child := range children {
+		childStartOffset := child.StartPosition().Offset
+		childEndOffset := child.EndPosition(nil).Offset
+
+		var childComments []*ast.Comment
+
+		for commentIndex < len(comments) {
+			comment := comments[commentIndex]
+			commentOffset := comment.StartPos.Offset
+
+			if commentOffset > childEndOffset {
+				break
+			}
+
+			if commentOffset < childStartOffset {
+				remainingComments = append(remainingComments, comment)
+			} else {
+				childComments = append(childComments, comment)
+			}
+
+			commentIndex++
+		}
+
+		if len(childComments) > 0 {
+			remainingComments = append(
+				remainingComments,
+				attachElementComments(child, childComments)...,
+			)
+		}
+	}
+
+	return append(remainingComments, comments[commentIndex:]...)
+}
+
+func attachMembersComments(members *ast.Members, comments []*ast.Comment) {
+	declarations := members.Declarations()
+
+	items := make([]ast.CommentedElement, 0, len(declarations))
+	for _, declaration := range declarations {
+		items = append(items, declaration)
+	}
+
+	attachContainerComments(&members.Comments, items, comments)
+}
+
+func statementItems(statements []ast.Statement) []ast.CommentedElement {
+	items := make([]ast.CommentedElement, 0, len(statements))
+	for _, statement := range statements {
+		items = append(items, statement)
+	}
+	return items
+}
@@ -0,0 +1,199 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+)
+
+func commentTexts(comments []*ast.Comment) []string {
+	texts := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		texts = append(texts, comment.Text)
+	}
+	return texts
+}
+
+func TestParseProgramWithComments(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("declarations", func(t *testing.T) {
+
+		t.Parallel()
+
+		program, err := ParseProgramWithComments(
+			`
+              // header
+
+              /// doc
+              fun test() {} // trailing
+
+              /* end */
+            `,
+			nil,
+		)
+		require.NoError(t, err)
+
+		declarations := program.Declarations()
+		require.Len(t, declarations, 1)
+
+		comments := declarations[0].ElementComments()
+		assert.Equal(t,
+			[]string{"// header", "/// doc"},
+			commentTexts(comments.Leading),
+		)
+		assert.Equal(t,
+			[]string{"// trailing"},
+			commentTexts(comments.Trailing),
+		)
+		assert.Equal(t,
+			[]string{"/* end */"},
+			commentTexts(program.Comments.Dangling),
+		)
+	})
+
+	t.Run("statements", func(t *testing.T) {
+
+		t.Parallel()
+
+		program, err := ParseProgramWithComments(
+			`
+              fun test() {
+                  // first
+                  let x = 1 /* one */
+                  let y = f(
+                      // argument
+                      x
+                  )
+                  if true {
+                      // empty
+                  }
+                  // last
+              }
+            `,
+			nil,
+		)
+		require.NoError(t, err)
+
+		functionDeclaration := program.FunctionDeclarations()[0]
+		block := functionDeclaration.FunctionBlock.Block
+		require.Len(t, block.Statements, 3)
+
+		first := block.Statements[0].ElementComments()
+		assert.Equal(t, []string{"// first"}, commentTexts(first.Leading))
+		assert.Equal(t, []string{"/* one */"}, commentTexts(first.Trailing))
+
+		// Comments inside of expressions are attached to the statement
+		second := block.Statements[1].ElementComments()
+		assert.Equal(t, []string{"// argument"}, commentTexts(second.Leading))
+
+		ifStatement := block.Statements[2].(*ast.IfStatement)
+		assert.Equal(t,
+			[]string{"// empty"},
+			commentTexts(ifStatement.Then.Comments.Dangling),
+		)
+
+		assert.Equal(t,
+			[]string{"// last"},
+			commentTexts(block.Comments.Dangling),
+		)
+	})
+
+	t.Run("members", func(t *testing.T) {
+
+		t.Parallel()
+
+		program, err := ParseProgramWithComments(
+			`
+              contract C {
+                  // field
+                  let x: Int
+
+                  /* initializer */
+                  init() {
+                      self.x = 1
+                  }
+
+                  // end
+              }
+            `,
+			nil,
+		)
+		require.NoError(t, err)
+
+		compositeDeclaration := program.CompositeDeclarations()[0]
+		members := compositeDeclaration.Members
+
+		fields := members.Fields()
+		require.Len(t, fields, 1)
+		assert.Equal(t, []string{"// field"}, commentTexts(fields[0].Leading))
+
+		initializers := members.Initializers()
+		require.Len(t, initializers, 1)
+		assert.Equal(t, []string{"/* initializer */"}, commentTexts(initializers[0].Leading))
+
+		assert.Equal(t, []string{"// end"}, commentTexts(members.Comments.Dangling))
+	})
+
+	t.Run("backtracking", func(t *testing.T) {
+
+		t.Parallel()
+
+		// The less-than expression is ambiguous with a function invocation with type arguments,
+		// so the tokens, including the comment, are replayed
+
+		program, err := ParseProgramWithComments(
+			`
+              let x = 1 < /* comment */ 2
+            `,
+			nil,
+		)
+		require.NoError(t, err)
+
+		variableDeclaration := program.VariableDeclarations()[0]
+		assert.Equal(t,
+			[]string{"/* comment */"},
+			commentTexts(variableDeclaration.Comments.Leading),
+		)
+	})
+
+	t.Run("not attached by default", func(t *testing.T) {
+
+		t.Parallel()
+
+		program, err := ParseProgram(
+			`
+              // comment
+              fun test() {}
+            `,
+			nil,
+		)
+		require.NoError(t, err)
+
+		declarations := program.Declarations()
+		require.Len(t, declarations, 1)
+		assert.True(t, declarations[0].ElementComments().IsEmpty())
+	})
+}
@@ -92,6 +92,9 @@ func parseDeclaration(p *parser, docString string) (ast.Declaration, error) {
 			case keywordStruct, keywordResource, keywordContract, keywordEnum:
 				return parseCompositeOrInterfaceDeclaration(p, access, accessPos, docString)
 
+			case keywordTypeAlias:
+				return parseTypeAliasDeclaration(p, access, accessPos, docString)
+
 			case KeywordTransaction:
 				if access != ast.AccessNotSpecified {
 					return nil, p.syntaxError("invalid access modifier for transaction")
@@ -721,6 +724,7 @@ func parseEventDeclaration(
 			p.memoryGauge,
 			ast.AccessNotSpecified,
 			ast.NewEmptyIdentifier(p.memoryGauge, ast.EmptyPosition),
+			nil,
 			parameterList,
 			nil,
 			nil,
@@ -742,6 +746,7 @@ func parseEventDeclaration(
 		common.CompositeKindEvent,
 		identifier,
 		nil,
+		nil,
 		members,
 		docString,
 		ast.NewRange(
@@ -908,10 +913,21 @@ func parseCompositeOrInterfaceDeclaration(
 		}
 	}
 
+	typeParameterList, err := parseTypeParameterList(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if typeParameterList != nil && isInterface {
+		return nil, NewSyntaxError(
+			typeParameterList.StartPos,
+			"interfaces cannot have type parameters",
+		)
+	}
+
 	p.skipSpaceAndComments(true)
 
 	var conformances []*ast.NominalType
-	var err error
 
 	if p.current.Is(lexer.TokenColon) {
 		// Skip the colon
@@ -977,6 +993,7 @@ func parseCompositeOrInterfaceDeclaration(
 			access,
 			compositeKind,
 			identifier,
+			typeParameterList,
 			conformances,
 			members,
 			docString,
@@ -1065,6 +1082,9 @@ func parseMemberOrNestedDeclaration(p *parser, docString string) (ast.Declaratio
 			case keywordStruct, keywordResource, keywordContract, keywordEnum:
 				return parseCompositeOrInterfaceDeclaration(p, access, accessPos, docString)
 
+			case keywordTypeAlias:
+				return parseTypeAliasDeclaration(p, access, accessPos, docString)
+
 			case keywordPriv, keywordPub, keywordAccess:
 				if access != ast.AccessNotSpecified {
 					return nil, p.syntaxError("unexpected access modifier")
@@ -1205,6 +1225,7 @@ func parseSpecialFunctionDeclaration(
 			p.memoryGauge,
 			access,
 			identifier,
+			nil,
 			parameterList,
 			nil,
 			functionBlock,
@@ -1253,3 +1274,61 @@ func parseEnumCase(
 		startPos,
 	), nil
 }
+
+// parseTypeAliasDeclaration parses a type alias declaration.
+//
+//     typeAliasDeclaration : 'typealias' identifier '=' type
+//
+func parseTypeAliasDeclaration(
+	p *parser,
+	access ast.Access,
+	accessPos *ast.Position,
+	docString string,
+) (*ast.TypeAliasDeclaration, error) {
+
+	startPos := p.current.StartPos
+	if accessPos != nil {
+		startPos = *accessPos
+	}
+
+	// Skip the `typealias` keyword
+	p.next()
+
+	p.skipSpaceAndComments(true)
+	if !p.current.Is(lexer.TokenIdentifier) {
+		return nil, p.syntaxError(
+			"expected identifier after start of type alias declaration, got %s",
+			p.current.Type,
+		)
+	}
+
+	identifier := p.tokenToIdentifier(p.current)
+	// Skip the identifier
+	p.next()
+	p.skipSpaceAndComments(true)
+
+	_, err := p.mustOne(lexer.TokenEqual)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaceAndComments(true)
+
+	ty, err := parseType(p, lowestBindingPower)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTypeAliasDeclaration(
+		p.memoryGauge,
+		access,
+		identifier,
+		ty,
+		docString,
+		ast.NewRange(
+			p.memoryGauge,
+			startPos,
+			ty.EndPosition(p.memoryGauge),
+		),
+	), nil
+}
@@ -5013,3 +5013,246 @@ func TestParseInvalidAccessModifiers(t *testing.T) {
 		)
 	})
 }
+
+func TestParseTypeAliasDeclaration(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("top-level", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseDeclarations("pub typealias Vault = FungibleToken.Vault", nil)
+		require.Empty(t, errs)
+
+		utils.AssertEqualWithDiff(t,
+			[]ast.Declaration{
+				&ast.TypeAliasDeclaration{
+					Access: ast.AccessPublic,
+					Identifier: ast.Identifier{
+						Identifier: "Vault",
+						Pos:        ast.Position{Offset: 14, Line: 1, Column: 14},
+					},
+					Type: &ast.NominalType{
+						Identifier: ast.Identifier{
+							Identifier: "FungibleToken",
+							Pos:        ast.Position{Offset: 22, Line: 1, Column: 22},
+						},
+						NestedIdentifiers: []ast.Identifier{
+							{
+								Identifier: "Vault",
+								Pos:        ast.Position{Offset: 36, Line: 1, Column: 36},
+							},
+						},
+					},
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 0, Line: 1, Column: 0},
+						EndPos:   ast.Position{Offset: 40, Line: 1, Column: 40},
+					},
+				},
+			},
+			result,
+		)
+	})
+
+	t.Run("member", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseDeclarations(
+			`
+              contract C {
+                  /// The amount
+                  typealias Amount = UFix64
+              }
+            `,
+			nil,
+		)
+		require.Empty(t, errs)
+
+		require.Len(t, result, 1)
+		compositeDeclaration := result[0].(*ast.CompositeDeclaration)
+
+		utils.AssertEqualWithDiff(t,
+			[]*ast.TypeAliasDeclaration{
+				{
+					Access: ast.AccessNotSpecified,
+					Identifier: ast.Identifier{
+						Identifier: "Amount",
+						Pos:        ast.Position{Offset: 89, Line: 4, Column: 28},
+					},
+					Type: &ast.NominalType{
+						Identifier: ast.Identifier{
+							Identifier: "UFix64",
+							Pos:        ast.Position{Offset: 98, Line: 4, Column: 37},
+						},
+					},
+					DocString: " The amount",
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 79, Line: 4, Column: 18},
+						EndPos:   ast.Position{Offset: 103, Line: 4, Column: 42},
+					},
+				},
+			},
+			compositeDeclaration.Members.TypeAliases(),
+		)
+	})
+
+	t.Run("missing identifier", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseDeclarations("typealias = Int", nil)
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "expected identifier after start of type alias declaration, got '='",
+					Pos:     ast.Position{Offset: 10, Line: 1, Column: 10},
+				},
+			},
+			errs,
+		)
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseDeclarations("typealias Amount: UFix64", nil)
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "expected token '='",
+					Pos:     ast.Position{Offset: 16, Line: 1, Column: 16},
+				},
+			},
+			errs,
+		)
+	})
+}
+
+func TestParseTypeParameterList(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("function", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseDeclarations("fun first<T: AnyStruct, U>() {}", nil)
+		require.Empty(t, errs)
+
+		require.Len(t, result, 1)
+		functionDeclaration := result[0].(*ast.FunctionDeclaration)
+
+		utils.AssertEqualWithDiff(t,
+			&ast.TypeParameterList{
+				TypeParameters: []*ast.TypeParameter{
+					{
+						Identifier: ast.Identifier{
+							Identifier: "T",
+							Pos:        ast.Position{Offset: 10, Line: 1, Column: 10},
+						},
+						TypeBound: &ast.TypeAnnotation{
+							Type: &ast.NominalType{
+								Identifier: ast.Identifier{
+									Identifier: "AnyStruct",
+									Pos:        ast.Position{Offset: 13, Line: 1, Column: 13},
+								},
+							},
+							StartPos: ast.Position{Offset: 13, Line: 1, Column: 13},
+						},
+					},
+					{
+						Identifier: ast.Identifier{
+							Identifier: "U",
+							Pos:        ast.Position{Offset: 24, Line: 1, Column: 24},
+						},
+					},
+				},
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 9, Line: 1, Column: 9},
+					EndPos:   ast.Position{Offset: 25, Line: 1, Column: 25},
+				},
+			},
+			functionDeclaration.TypeParameterList,
+		)
+	})
+
+	t.Run("composite", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseDeclarations("struct Box<T> {}", nil)
+		require.Empty(t, errs)
+
+		require.Len(t, result, 1)
+		compositeDeclaration := result[0].(*ast.CompositeDeclaration)
+
+		utils.AssertEqualWithDiff(t,
+			&ast.TypeParameterList{
+				TypeParameters: []*ast.TypeParameter{
+					{
+						Identifier: ast.Identifier{
+							Identifier: "T",
+							Pos:        ast.Position{Offset: 11, Line: 1, Column: 11},
+						},
+					},
+				},
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 10, Line: 1, Column: 10},
+					EndPos:   ast.Position{Offset: 12, Line: 1, Column: 12},
+				},
+			},
+			compositeDeclaration.TypeParameterList,
+		)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseDeclarations("struct interface I<T> {}", nil)
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "interfaces cannot have type parameters",
+					Pos:     ast.Position{Offset: 18, Line: 1, Column: 18},
+				},
+			},
+			errs,
+		)
+	})
+
+	t.Run("missing comma", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseDeclarations("fun test<T U>() {}", nil)
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "expected comma or end of type parameter list, got identifier",
+					Pos:     ast.Position{Offset: 11, Line: 1, Column: 11},
+				},
+			},
+			errs,
+		)
+	})
+
+	t.Run("missing end", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseDeclarations("fun test<T", nil)
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "missing '>' at end of type parameter list",
+					Pos:     ast.Position{Offset: 10, Line: 1, Column: 10},
+				},
+			},
+			errs,
+		)
+	})
+}
@@ -530,6 +530,7 @@ func init() {
 	})
 
 	defineNestedExpression()
+	defineStringTemplateExpression()
 	defineInvocationExpression()
 	defineArrayExpression()
 	defineDictionaryExpression()
@@ -1105,6 +1106,86 @@ func defineNestedExpression() {
 	)
 }
 
+// defineStringTemplateExpression defines the string template expression,
+// e.g. `"Hello \(name)!"`.
+//
+// The lexer emits a start token for the part up to the first interpolation,
+// middle tokens for the parts between interpolations,
+// and an end token for the part after the last interpolation.
+//
+func defineStringTemplateExpression() {
+	setExprNullDenotation(
+		lexer.TokenStringTemplateStart,
+		func(p *parser, startToken lexer.Token) (ast.Expression, error) {
+			startLiteral := startToken.Value.(string)
+
+			// Skip the leading quote and the trailing `\(`
+			values := []string{
+				parseStringLiteralContent(p, startLiteral[1:len(startLiteral)-2]),
+			}
+
+			var expressions []ast.Expression
+
+			for {
+				expression, err := parseExpression(p, lowestBindingPower)
+				if err != nil {
+					return nil, err
+				}
+
+				expressions = append(expressions, expression)
+
+				token := p.current
+				literal, _ := token.Value.(string)
+
+				switch token.Type {
+				case lexer.TokenStringTemplateMiddle:
+					// Skip the leading `)` and the trailing `\(`
+					values = append(
+						values,
+						parseStringLiteralContent(p, literal[1:len(literal)-2]),
+					)
+					p.next()
+
+				case lexer.TokenStringTemplateEnd:
+					p.next()
+
+					// Skip the leading `)` and the trailing quote, if any
+					endOffset := len(literal)
+					if endOffset >= 2 && literal[endOffset-1] == '"' {
+						endOffset--
+					} else {
+						p.reportSyntaxError("invalid end of string literal: missing '\"'")
+					}
+
+					values = append(
+						values,
+						parseStringLiteralContent(p, literal[1:endOffset]),
+					)
+
+					return ast.NewStringTemplateExpression(
+						p.memoryGauge,
+						values,
+						expressions,
+						ast.NewRange(
+							p.memoryGauge,
+							startToken.StartPos,
+							token.EndPos,
+						),
+					), nil
+
+				default:
+					return nil, p.syntaxError(
+						"expected token %s or %s, got %s",
+						lexer.TokenParenClose,
+						lexer.TokenStringTemplateEnd,
+						token.Type,
+					)
+				}
+			}
+		},
+	)
+}
+
 func defineArrayExpression() {
 	setExprNullDenotation(
 		lexer.TokenBracketOpen,
@@ -1175,6 +1175,112 @@ func TestParseString(t *testing.T) {
 	})
 }
 
+func TestParseStringTemplate(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("single interpolation", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseExpression(`"a\(x)b"`, nil)
+		require.Empty(t, errs)
+
+		utils.AssertEqualWithDiff(t,
+			&ast.StringTemplateExpression{
+				Values: []string{"a", "b"},
+				Expressions: []ast.Expression{
+					&ast.IdentifierExpression{
+						Identifier: ast.Identifier{
+							Identifier: "x",
+							Pos:        ast.Position{Line: 1, Column: 4, Offset: 4},
+						},
+					},
+				},
+				Range: ast.Range{
+					StartPos: ast.Position{Line: 1, Column: 0, Offset: 0},
+					EndPos:   ast.Position{Line: 1, Column: 7, Offset: 7},
+				},
+			},
+			result,
+		)
+	})
+
+	t.Run("multiple interpolations, nested", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseExpression(`"\(f((1)))\n\("\(y)")\\"`, nil)
+		require.Empty(t, errs)
+
+		require.IsType(t, &ast.StringTemplateExpression{}, result)
+		template := result.(*ast.StringTemplateExpression)
+
+		assert.Equal(t, []string{"", "\n", "\\"}, template.Values)
+		require.Len(t, template.Expressions, 2)
+		assert.IsType(t, &ast.InvocationExpression{}, template.Expressions[0])
+		assert.IsType(t, &ast.StringTemplateExpression{}, template.Expressions[1])
+
+		assert.Equal(t,
+			`"\(f(1))\n\("\(y)")\\"`,
+			template.String(),
+		)
+	})
+
+	t.Run("escaped interpolation", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := ParseExpression(`"\\(x)"`, nil)
+		require.Empty(t, errs)
+
+		utils.AssertEqualWithDiff(t,
+			&ast.StringExpression{
+				Value: `\(x)`,
+				Range: ast.Range{
+					StartPos: ast.Position{Line: 1, Column: 0, Offset: 0},
+					EndPos:   ast.Position{Line: 1, Column: 6, Offset: 6},
+				},
+			},
+			result,
+		)
+	})
+
+	t.Run("invalid, missing end", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseExpression(`"a\(x)b`, nil)
+
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "invalid end of string literal: missing '\"'",
+					Pos:     ast.Position{Offset: 7, Line: 1, Column: 7},
+				},
+			},
+			errs,
+		)
+	})
+
+	t.Run("invalid, unterminated interpolation", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, errs := ParseExpression(`"a\(x`, nil)
+
+		utils.AssertEqualWithDiff(t,
+			[]error{
+				&SyntaxError{
+					Message: "expected token ')' or end of string template, got EOF",
+					Pos:     ast.Position{Offset: 5, Line: 1, Column: 5},
+				},
+			},
+			errs,
+		)
+	})
+}
+
 func TestParseInvocation(t *testing.T) {
 
 	t.Parallel()
@@ -23,6 +23,137 @@ import (
 	"github.com/onflow/cadence/runtime/parser/lexer"
 )
 
+// parseTypeParameterList parses an optional type parameter list
+// of a generic function or composite declaration.
+//
+//     typeParameterList : ( '<' ( typeParameter ( ',' typeParameter )* )? '>' )?
+//
+func parseTypeParameterList(p *parser) (*ast.TypeParameterList, error) {
+	var typeParameters []*ast.TypeParameter
+
+	p.skipSpaceAndComments(true)
+
+	if !p.current.Is(lexer.TokenLess) {
+		return nil, nil
+	}
+
+	startPos := p.current.StartPos
+	// Skip the opening angle bracket
+	p.next()
+
+	var endPos ast.Position
+
+	expectTypeParameter := true
+
+	atEnd := false
+	for !atEnd {
+		p.skipSpaceAndComments(true)
+		switch p.current.Type {
+		case lexer.TokenIdentifier:
+			if !expectTypeParameter {
+				return nil, p.syntaxError(
+					"expected comma or end of type parameter list, got %s",
+					p.current.Type,
+				)
+			}
+			typeParameter, err := parseTypeParameter(p)
+			if err != nil {
+				return nil, err
+			}
+
+			typeParameters = append(typeParameters, typeParameter)
+			expectTypeParameter = false
+
+		case lexer.TokenComma:
+			if expectTypeParameter {
+				return nil, p.syntaxError(
+					"expected type parameter or end of type parameter list, got %s",
+					p.current.Type,
+				)
+			}
+			// Skip the comma
+			p.next()
+			expectTypeParameter = true
+
+		case lexer.TokenGreater:
+			endPos = p.current.EndPos
+			// Skip the closing angle bracket
+			p.next()
+			atEnd = true
+
+		case lexer.TokenEOF:
+			return nil, p.syntaxError(
+				"missing %s at end of type parameter list",
+				lexer.TokenGreater,
+			)
+
+		default:
+			if expectTypeParameter {
+				return nil, p.syntaxError(
+					"expected type parameter or end of type parameter list, got %s",
+					p.current.Type,
+				)
+			} else {
+				return nil, p.syntaxError(
+					"expected comma or end of type parameter list, got %s",
+					p.current.Type,
+				)
+			}
+		}
+	}
+
+	return ast.NewTypeParameterList(
+		p.memoryGauge,
+		typeParameters,
+		ast.NewRange(
+			p.memoryGauge,
+			startPos,
+			endPos,
+		),
+	), nil
+}
+
+// parseTypeParameter parses a type parameter and its optional type bound.
+//
+//     typeParameter : identifier ( ':' typeAnnotation )?
+//
+func parseTypeParameter(p *parser) (*ast.TypeParameter, error) {
+	p.skipSpaceAndComments(true)
+
+	if !p.current.Is(lexer.TokenIdentifier) {
+		return nil, p.syntaxError(
+			"expected type parameter name, got %s",
+			p.current.Type,
+		)
+	}
+
+	identifier := p.tokenToIdentifier(p.current)
+	// Skip the identifier
+	p.next()
+
+	p.skipSpaceAndComments(true)
+
+	var typeBound *ast.TypeAnnotation
+
+	if p.current.Is(lexer.TokenColon) {
+		// Skip the colon
+		p.next()
+		p.skipSpaceAndComments(true)
+
+		var err error
+		typeBound, err = parseTypeAnnotation(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ast.NewTypeParameter(
+		p.memoryGauge,
+		identifier,
+		typeBound,
+	), nil
+}
+
 func parseParameterList(p *parser) (parameterList *ast.ParameterList, err error) {
 	var parameters []*ast.Parameter
 
@@ -218,6 +349,11 @@ func parseFunctionDeclaration(
 	// Skip the identifier
 	p.next()
 
+	typeParameterList, err := parseTypeParameterList(p)
+	if err != nil {
+		return nil, err
+	}
+
 	parameterList, returnTypeAnnotation, functionBlock, err :=
 		parseFunctionParameterListAndRest(p, functionBlockIsOptional)
 
@@ -229,6 +365,7 @@ func parseFunctionDeclaration(
 		p.memoryGauge,
 		access,
 		identifier,
+		typeParameterList,
 		parameterList,
 		returnTypeAnnotation,
 		functionBlock,
@@ -74,6 +74,9 @@ type lexer struct {
 	tokenCount int
 	// memoryGauge is used for metering memory usage
 	memoryGauge common.MemoryGauge
+	// stringTemplateParenDepths contains, for each interpolation of a string template
+	// which is currently being lexed, the number of parentheses opened in the interpolation
+	stringTemplateParenDepths []int
 }
 
 var _ TokenStream = &lexer{}
@@ -130,6 +133,7 @@ func (l *lexer) clear() {
 	l.cursor = 0
 	l.tokens = l.tokens[:0]
 	l.tokenCount = 0
+	l.stringTemplateParenDepths = l.stringTemplateParenDepths[:0]
 }
 
 func (l *lexer) Reclaim() {
@@ -406,25 +410,68 @@ func (l *lexer) acceptWhile(f func(rune) bool) {
 	}
 }
 
-func (l *lexer) scanString(quote rune) {
+// scanString scans the string until the given quote.
+//
+// It returns true if the scanning stopped at the start of an interpolation,
+// i.e. after the escape sequence `\(`
+//
+func (l *lexer) scanString(quote rune) (interpolation bool) {
 	r := l.next()
 	for r != quote {
 		switch r {
 		case '\n', EOF:
 			// NOTE: invalid end of string handled by parser
 			l.backupOne()
-			return
+			return false
 		case '\\':
 			r = l.next()
 			switch r {
 			case '\n', EOF:
 				// NOTE: invalid end of string handled by parser
 				l.backupOne()
-				return
+				return false
+			case '(':
+				return true
 			}
 		}
 		r = l.next()
 	}
+	return false
+}
+
+// startStringTemplateInterpolation records the start of an interpolation of a string template
+func (l *lexer) startStringTemplateInterpolation() {
+	l.stringTemplateParenDepths = append(l.stringTemplateParenDepths, 0)
+}
+
+// openParen records an opening parenthesis.
+func (l *lexer) openParen() {
+	count := len(l.stringTemplateParenDepths)
+	if count == 0 {
+		return
+	}
+	l.stringTemplateParenDepths[count-1]++
+}
+
+// closeParen records a closing parenthesis.
+//
+// It returns true if the parenthesis ends an interpolation of a string template
+//
+func (l *lexer) closeParen() (endsInterpolation bool) {
+	count := len(l.stringTemplateParenDepths)
+	if count == 0 {
+		return false
+	}
+
+	lastIndex := count - 1
+	depth := l.stringTemplateParenDepths[lastIndex]
+	if depth == 0 {
+		l.stringTemplateParenDepths = l.stringTemplateParenDepths[:lastIndex]
+		return true
+	}
+
+	l.stringTemplateParenDepths[lastIndex] = depth - 1
+	return false
 }
 
 func (l *lexer) scanBinaryRemainder() {
@@ -478,7 +525,12 @@ func (l *lexer) scanFixedPointRemainder() {
 func (l *lexer) tokenValueMemoryUsage(tokenType TokenType) common.MemoryUsage {
 	tokenLength := l.wordLength()
 
-	if tokenType == TokenString {
+	switch tokenType {
+	case TokenString,
+		TokenStringTemplateStart,
+		TokenStringTemplateMiddle,
+		TokenStringTemplateEnd:
+
 		return common.NewStringMemoryUsage(tokenLength)
 	}
 
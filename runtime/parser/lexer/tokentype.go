@@ -81,6 +81,9 @@ const (
 	TokenAsExclamationMark
 	TokenAsQuestionMark
 	TokenPragma
+	TokenStringTemplateStart
+	TokenStringTemplateMiddle
+	TokenStringTemplateEnd
 	// NOTE: not an actual token, must be last item
 	TokenMax
 )
@@ -202,6 +205,12 @@ func (t TokenType) String() string {
 		return `'as?'`
 	case TokenPragma:
 		return `'#'`
+	case TokenStringTemplateStart:
+		return "start of string template"
+	case TokenStringTemplateMiddle:
+		return "middle of string template"
+	case TokenStringTemplateEnd:
+		return "end of string template"
 	default:
 		panic(errors.NewUnreachableError())
 	}
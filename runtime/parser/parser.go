@@ -65,6 +65,10 @@ type parser struct {
 	expressionDepth int
 	// typeDepth is the depth of the type (if >0)
 	typeDepth int
+	// recordComments is true if comments should be recorded, so they can be attached to the AST
+	recordComments bool
+	// comments are the recorded comments, in the order they occur in the input
+	comments []*ast.Comment
 }
 
 // Parse creates a lexer to scan the given input string,
@@ -398,7 +402,9 @@ func (p *parser) parseTrivia(options triviaOptions) (containsNewline bool, docSt
 			p.next()
 
 		case lexer.TokenBlockCommentStart:
-			comment := p.parseCommentContent()
+			startPos := p.current.StartPos
+			comment, endPos := p.parseCommentContent()
+			p.recordComment(comment, startPos, endPos)
 			if options.parseDocStrings {
 				inLineDocString = false
 				docStringBuilder.Reset()
@@ -409,12 +415,15 @@ func (p *parser) parseTrivia(options triviaOptions) (containsNewline bool, docSt
 			}
 
 		case lexer.TokenLineComment:
+			comment, ok := p.current.Value.(string)
+			if !ok {
+				// we just checked that this is a comment
+				panic(errors.NewUnreachableError())
+			}
+
+			p.recordComment(comment, p.current.StartPos, p.current.EndPos)
+
 			if options.parseDocStrings {
-				comment, ok := p.current.Value.(string)
-				if !ok {
-					// we just checked that this is a comment
-					panic(errors.NewUnreachableError())
-				}
 				if strings.HasPrefix(comment, "///") {
 					if inLineDocString {
 						docStringBuilder.WriteRune('\n')
@@ -589,6 +598,17 @@ func ParseProgram(code string, memoryGauge common.MemoryGauge) (program *ast.Pro
 	return ParseProgramFromTokenStream(tokens, memoryGauge)
 }
 
+// ParseProgramWithComments parses the given code into a program, like ParseProgram,
+// and attaches the comments in the code to the declarations and statements of the program.
+//
+// See ast.Comments for how the comments are attached.
+//
+func ParseProgramWithComments(code string, memoryGauge common.MemoryGauge) (program *ast.Program, err error) {
+	tokens := lexer.Lex(code, memoryGauge)
+	defer tokens.Reclaim()
+	return parseProgramFromTokenStream(tokens, memoryGauge, true)
+}
+
 func ParseProgramFromTokenStream(
 	input lexer.TokenStream,
 	memoryGauge common.MemoryGauge,
@@ -596,13 +616,29 @@ func ParseProgramFromTokenStream(
 	program *ast.Program,
 	err error,
 ) {
+	return parseProgramFromTokenStream(input, memoryGauge, false)
+}
+
+func parseProgramFromTokenStream(
+	input lexer.TokenStream,
+	memoryGauge common.MemoryGauge,
+	withComments bool,
+) (
+	program *ast.Program,
+	err error,
+) {
+	var comments []*ast.Comment
+
 	var res any
 	var errs []error
 	res, errs = ParseTokenStream(
 		memoryGauge,
 		input,
 		func(p *parser) (any, error) {
-			return parseDeclarations(p, lexer.TokenEOF)
+			p.recordComments = withComments
+			declarations, err := parseDeclarations(p, lexer.TokenEOF)
+			comments = p.comments
+			return declarations, err
 		},
 	)
 	if len(errs) > 0 {
@@ -623,6 +659,10 @@ func ParseProgramFromTokenStream(
 
 	program = ast.NewProgram(memoryGauge, declarations)
 
+	if withComments {
+		attachComments(program, comments)
+	}
+
 	return
 }
 
@@ -0,0 +1,459 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/pprof/profile"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// profilerHostFunctionName is the name of the frames of invoked host functions,
+// e.g. `log` or `AuthAccount.save`
+//
+const profilerHostFunctionName = "<host function>"
+
+// Profiler records where executed programs spend their time and computation.
+//
+// The profiler is instrumenting: it is driven by the interpreter's statement,
+// function invocation, function entry and exit, and computation metering handlers,
+// and attributes both the wall time and the metered computation
+// to the Cadence call stack at the time.
+//
+// The recorded profile can be written in the pprof format,
+// e.g. to be visualized using `go tool pprof`.
+//
+// A profiler records one execution at a time,
+// it must not be used by concurrent executions.
+//
+type Profiler struct {
+	lock sync.Mutex
+	// now returns the current time, and can be replaced in tests
+	now  func() time.Time
+	last time.Time
+
+	stack            []*profilerFrame
+	functionNames    map[*ast.Program]map[ast.Element]string
+	samples          map[string]*profilerSample
+	computationKinds map[common.ComputationKind]struct{}
+
+	// pendingStatementComputation is the computation metered for the statement
+	// which is about to be executed. It is attributed once the statement is known
+	pendingStatementComputation uint
+}
+
+type profilerFunction struct {
+	name     string
+	filename string
+}
+
+type profilerLocation struct {
+	function profilerFunction
+	line     int
+}
+
+type profilerFrame struct {
+	profilerLocation
+	// invocation is true if the frame was pushed for an invocation expression.
+	// Until the invoked function is entered, the frame is assumed to be a host function
+	invocation bool
+	entered    bool
+}
+
+type profilerSample struct {
+	stack       []profilerLocation
+	wallTime    time.Duration
+	computation map[common.ComputationKind]uint
+}
+
+// NewProfiler returns a new profiler with an empty profile
+//
+func NewProfiler() *Profiler {
+	return &Profiler{
+		now:              time.Now,
+		functionNames:    map[*ast.Program]map[ast.Element]string{},
+		samples:          map[string]*profilerSample{},
+		computationKinds: map[common.ComputationKind]struct{}{},
+	}
+}
+
+// InterpreterOptions returns the interpreter options which set the handlers of the profiler.
+// The runtime sets the handlers itself, see Context.Profiler
+//
+func (p *Profiler) InterpreterOptions() []interpreter.Option {
+	return []interpreter.Option{
+		interpreter.WithOnStatementHandler(p.OnStatement),
+		interpreter.WithOnFunctionInvocationHandler(p.OnFunctionInvocation),
+		interpreter.WithOnInvokedFunctionReturnHandler(p.OnInvokedFunctionReturn),
+		interpreter.WithOnFunctionEntryHandler(p.OnFunctionEntry),
+		interpreter.WithOnFunctionExitHandler(p.OnFunctionExit),
+		interpreter.WithOnMeterComputationFuncHandler(p.OnMeterComputation),
+	}
+}
+
+// tick attributes the wall time which elapsed since the last event
+// to the current call stack
+//
+func (p *Profiler) tick() {
+	now := p.now()
+	if len(p.stack) > 0 && !p.last.IsZero() {
+		p.currentSample().wallTime += now.Sub(p.last)
+	}
+	p.last = now
+}
+
+func (p *Profiler) top() *profilerFrame {
+	if len(p.stack) == 0 {
+		return nil
+	}
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *Profiler) push(frame *profilerFrame) {
+	p.stack = append(p.stack, frame)
+}
+
+func (p *Profiler) pop() *profilerFrame {
+	lastIndex := len(p.stack) - 1
+	frame := p.stack[lastIndex]
+	p.stack[lastIndex] = nil
+	p.stack = p.stack[:lastIndex]
+	return frame
+}
+
+// currentSample returns the sample for the current call stack
+//
+func (p *Profiler) currentSample() *profilerSample {
+	var keyBuilder strings.Builder
+	for _, frame := range p.stack {
+		keyBuilder.WriteString(frame.function.filename)
+		keyBuilder.WriteByte(0)
+		keyBuilder.WriteString(frame.function.name)
+		keyBuilder.WriteByte(0)
+		keyBuilder.WriteString(strconv.Itoa(frame.line))
+		keyBuilder.WriteByte(1)
+	}
+	key := keyBuilder.String()
+
+	sample, ok := p.samples[key]
+	if !ok {
+		stack := make([]profilerLocation, len(p.stack))
+		for i, frame := range p.stack {
+			stack[i] = frame.profilerLocation
+		}
+		sample = &profilerSample{
+			stack:       stack,
+			computation: map[common.ComputationKind]uint{},
+		}
+		p.samples[key] = sample
+	}
+	return sample
+}
+
+func (p *Profiler) function(inter *interpreter.Interpreter, function *interpreter.InterpretedFunctionValue) profilerFunction {
+	filename := coverageSourceName(inter.Location.ID())
+
+	declaration := function.Declaration
+	if declaration == nil {
+		return profilerFunction{
+			name:     "<anonymous>",
+			filename: filename,
+		}
+	}
+
+	var name string
+	if inter.Program != nil && inter.Program.Program != nil {
+		program := inter.Program.Program
+		names, ok := p.functionNames[program]
+		if !ok {
+			names = qualifiedFunctionNames(program)
+			p.functionNames[program] = names
+		}
+		name = names[declaration]
+	}
+	if name == "" {
+		name = functionName(declaration, nil)
+	}
+
+	return profilerFunction{
+		name:     name,
+		filename: filename,
+	}
+}
+
+// OnStatement is the interpreter statement handler of the profiler
+//
+func (p *Profiler) OnStatement(_ *interpreter.Interpreter, statement ast.Statement) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tick()
+
+	if top := p.top(); top != nil {
+		top.line = statement.StartPosition().Line
+	}
+
+	if p.pendingStatementComputation > 0 {
+		p.currentSample().computation[common.ComputationKindStatement] += p.pendingStatementComputation
+		p.pendingStatementComputation = 0
+	}
+}
+
+// OnFunctionInvocation is the interpreter function invocation handler of the profiler
+//
+func (p *Profiler) OnFunctionInvocation(inter *interpreter.Interpreter, line int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tick()
+
+	if top := p.top(); top != nil {
+		top.line = line
+	}
+
+	// The invoked function is not known yet.
+	// If it is an interpreted function, the frame is updated when the function is entered
+
+	p.push(&profilerFrame{
+		profilerLocation: profilerLocation{
+			function: profilerFunction{
+				name:     profilerHostFunctionName,
+				filename: coverageSourceName(inter.Location.ID()),
+			},
+		},
+		invocation: true,
+	})
+}
+
+// OnInvokedFunctionReturn is the interpreter invoked function return handler of the profiler
+//
+func (p *Profiler) OnInvokedFunctionReturn(_ *interpreter.Interpreter, _ int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tick()
+
+	// Frames of interpreted functions are already popped when the function exits,
+	// only the frames of invoked host functions are left
+
+	if top := p.top(); top != nil && top.invocation && !top.entered {
+		p.pop()
+	}
+}
+
+// OnFunctionEntry is the interpreter function entry handler of the profiler
+//
+func (p *Profiler) OnFunctionEntry(inter *interpreter.Interpreter, function *interpreter.InterpretedFunctionValue) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tick()
+
+	var line int
+	if function.Declaration != nil {
+		line = function.Declaration.StartPosition().Line
+	}
+
+	location := profilerLocation{
+		function: p.function(inter, function),
+		line:     line,
+	}
+
+	// If the function was invoked by an invocation expression,
+	// the frame of the invocation is the frame of the function.
+	// Otherwise, e.g. when a transaction function is invoked by the runtime,
+	// a new frame is pushed
+
+	top := p.top()
+	if top != nil && top.invocation && !top.entered {
+		top.profilerLocation = location
+		top.entered = true
+		return
+	}
+
+	p.push(&profilerFrame{
+		profilerLocation: location,
+		entered:          true,
+	})
+}
+
+// OnFunctionExit is the interpreter function exit handler of the profiler
+//
+func (p *Profiler) OnFunctionExit(_ *interpreter.Interpreter, _ *interpreter.InterpretedFunctionValue) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tick()
+
+	// Pop the frame of the function, and the frames of host functions
+	// it invoked and which did not return because the execution was aborted
+
+	for len(p.stack) > 0 {
+		frame := p.pop()
+		if frame.entered {
+			break
+		}
+	}
+}
+
+// OnMeterComputation is the interpreter computation metering handler of the profiler
+//
+func (p *Profiler) OnMeterComputation(kind common.ComputationKind, intensity uint) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.computationKinds[kind] = struct{}{}
+
+	// Statements are metered before the statement handler is called,
+	// so attribute the computation once the statement is known
+
+	if kind == common.ComputationKindStatement {
+		p.pendingStatementComputation += intensity
+		return
+	}
+
+	p.currentSample().computation[kind] += intensity
+}
+
+// Profile returns the recorded profile in the pprof format.
+//
+// The first sample value is the wall time in nanoseconds,
+// the following sample values are the metered computation intensities,
+// one per computation kind.
+//
+func (p *Profiler) Profile() *profile.Profile {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	computationKinds := make([]common.ComputationKind, 0, len(p.computationKinds))
+	for kind := range p.computationKinds { //nolint:maprangecheck
+		computationKinds = append(computationKinds, kind)
+	}
+	sort.Slice(computationKinds, func(i, j int) bool {
+		return computationKinds[i] < computationKinds[j]
+	})
+
+	const wallSampleType = "wall"
+
+	result := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{
+				Type: wallSampleType,
+				Unit: "nanoseconds",
+			},
+		},
+		DefaultSampleType: wallSampleType,
+		PeriodType: &profile.ValueType{
+			Type: wallSampleType,
+			Unit: "nanoseconds",
+		},
+		Period: 1,
+	}
+
+	for _, kind := range computationKinds {
+		result.SampleType = append(
+			result.SampleType,
+			&profile.ValueType{
+				Type: kind.String(),
+				Unit: "intensity",
+			},
+		)
+	}
+
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples { //nolint:maprangecheck
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	functions := map[profilerFunction]*profile.Function{}
+	locations := map[profilerLocation]*profile.Location{}
+
+	for _, key := range keys {
+		sample := p.samples[key]
+
+		values := make([]int64, 0, len(result.SampleType))
+		values = append(values, sample.wallTime.Nanoseconds())
+		for _, kind := range computationKinds {
+			values = append(values, int64(sample.computation[kind]))
+		}
+
+		// The locations of a pprof sample start with the innermost frame
+
+		sampleLocations := make([]*profile.Location, 0, len(sample.stack))
+
+		for i := len(sample.stack) - 1; i >= 0; i-- {
+			stackLocation := sample.stack[i]
+
+			location, ok := locations[stackLocation]
+			if !ok {
+				function, ok := functions[stackLocation.function]
+				if !ok {
+					function = &profile.Function{
+						ID:       uint64(len(result.Function) + 1),
+						Name:     stackLocation.function.name,
+						Filename: stackLocation.function.filename,
+					}
+					functions[stackLocation.function] = function
+					result.Function = append(result.Function, function)
+				}
+
+				location = &profile.Location{
+					ID: uint64(len(result.Location) + 1),
+					Line: []profile.Line{
+						{
+							Function: function,
+							Line:     int64(stackLocation.line),
+						},
+					},
+				}
+				locations[stackLocation] = location
+				result.Location = append(result.Location, location)
+			}
+
+			sampleLocations = append(sampleLocations, location)
+		}
+
+		result.Sample = append(
+			result.Sample,
+			&profile.Sample{
+				Location: sampleLocations,
+				Value:    values,
+			},
+		)
+	}
+
+	return result
+}
+
+// WriteProfile writes the recorded profile as a gzip-compressed pprof protocol buffer,
+// which can be read by `go tool pprof`
+//
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	return p.Profile().Write(w)
+}
@@ -0,0 +1,249 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// profileSampleValues returns the values of the sample type with the given name,
+// by the call stack of the samples, e.g. `main:5;add:2`
+//
+func profileSampleValues(t *testing.T, prof *profile.Profile, sampleType string) map[string]int64 {
+	index := -1
+	for i, valueType := range prof.SampleType {
+		if valueType.Type == sampleType {
+			index = i
+		}
+	}
+	require.NotEqual(t, -1, index)
+
+	values := map[string]int64{}
+	for _, sample := range prof.Sample {
+		value := sample.Value[index]
+		if value == 0 {
+			continue
+		}
+
+		frames := make([]string, 0, len(sample.Location))
+		for i := len(sample.Location) - 1; i >= 0; i-- {
+			line := sample.Location[i].Line[0]
+			frames = append(frames, fmt.Sprintf("%s:%d", line.Function.Name, line.Line))
+		}
+		values[strings.Join(frames, ";")] += value
+	}
+	return values
+}
+
+func TestRuntimeProfiler(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	profiler := NewProfiler()
+
+	// Advance the clock by one nanosecond on each event
+
+	var clock int64
+	profiler.now = func() time.Time {
+		clock++
+		return time.Unix(0, clock)
+	}
+
+	script := []byte(`
+      pub struct S {
+          pub fun double(_ n: Int): Int {
+              return n * 2
+          }
+      }
+
+      pub fun add(_ a: Int, _ b: Int): Int {
+          return a + b
+      }
+
+      pub fun main(): Int {
+          var sum = 0
+          var i = 0
+          while i < 3 {
+              sum = add(sum, i)
+              i = i + 1
+          }
+          log(sum)
+          return S().double(sum)
+      }
+    `)
+
+	var logs []string
+
+	runtimeInterface := &testRuntimeInterface{
+		log: func(message string) {
+			logs = append(logs, message)
+		},
+	}
+
+	value, err := runtime.ExecuteScript(
+		Script{
+			Source: script,
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  common.StringLocation("test.cdc"),
+			Profiler:  profiler,
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, cadence.NewInt(6), value)
+	assert.Equal(t, []string{"3"}, logs)
+
+	prof := profiler.Profile()
+	require.NoError(t, prof.CheckValid())
+
+	assert.Equal(t, "wall", prof.DefaultSampleType)
+
+	for _, function := range prof.Function {
+		assert.Equal(t, "test.cdc", function.Filename)
+	}
+
+	assert.Equal(t,
+		map[string]int64{
+			"main:13":            1,
+			"main:14":            1,
+			"main:15":            1,
+			"main:16":            3,
+			"main:16;add:9":      3,
+			"main:17":            3,
+			"main:19":            1,
+			"main:20":            1,
+			"main:20;S.double:4": 1,
+		},
+		profileSampleValues(t, prof, common.ComputationKindStatement.String()),
+	)
+
+	assert.Equal(t,
+		map[string]int64{
+			"main:16": 3,
+			"main:19": 1,
+			"main:20": 2,
+		},
+		profileSampleValues(t, prof, common.ComputationKindFunctionInvocation.String()),
+	)
+
+	// Time is also attributed to invocations before the invoked function is entered,
+	// and to host functions, e.g. `log` and the constructor of `S`
+
+	wallTimes := profileSampleValues(t, prof, "wall")
+
+	stacks := make([]string, 0, len(wallTimes))
+	for stack := range wallTimes { //nolint:maprangecheck
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	assert.Equal(t,
+		[]string{
+			"main:12",
+			"main:13",
+			"main:14",
+			"main:15",
+			"main:16",
+			"main:16;<host function>:0",
+			"main:16;add:8",
+			"main:16;add:9",
+			"main:17",
+			"main:19",
+			"main:19;<host function>:0",
+			"main:20",
+			"main:20;<host function>:0",
+			"main:20;S.double:3",
+			"main:20;S.double:4",
+		},
+		stacks,
+	)
+
+	// The written profile can be parsed again
+
+	var buffer bytes.Buffer
+	err = profiler.WriteProfile(&buffer)
+	require.NoError(t, err)
+
+	parsed, err := profile.Parse(&buffer)
+	require.NoError(t, err)
+	assert.Len(t, parsed.Sample, len(prof.Sample))
+}
+
+func TestRuntimeProfilerTransaction(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	profiler := NewProfiler()
+
+	script := []byte(`
+      transaction {
+          prepare() {
+              let x = 1
+          }
+
+          execute {
+              let y = 2
+          }
+      }
+    `)
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: script,
+		},
+		Context{
+			Interface: &testRuntimeInterface{},
+			Location:  common.StringLocation("test.cdc"),
+			Profiler:  profiler,
+		},
+	)
+	require.NoError(t, err)
+
+	prof := profiler.Profile()
+	require.NoError(t, prof.CheckValid())
+
+	// The transaction functions are invoked by the runtime,
+	// so they are not nested
+
+	assert.Equal(t,
+		map[string]int64{
+			"prepare:4": 1,
+			"execute:8": 1,
+		},
+		profileSampleValues(t, prof, common.ComputationKindStatement.String()),
+	)
+}
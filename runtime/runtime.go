@@ -195,6 +195,7 @@ var validTopLevelDeclarationsInTransaction = []common.DeclarationKind{
 	common.DeclarationKindImport,
 	common.DeclarationKindFunction,
 	common.DeclarationKindTransaction,
+	common.DeclarationKindTypeAlias,
 }
 
 var validTopLevelDeclarationsInAccountCode = []common.DeclarationKind{
@@ -970,6 +971,10 @@ func (r *interpreterRuntime) check(
 		return nil, err
 	}
 
+	if r.coverageReport != nil {
+		r.coverageReport.InspectProgram(startContext.Location, program)
+	}
+
 	return elaboration, nil
 }
 
@@ -1056,7 +1061,22 @@ func (r *interpreterRuntime) newInterpreter(
 			r.importLocationHandler(context, functions, values, checkerOptions),
 		),
 		interpreter.WithOnStatementHandler(
-			r.onStatementHandler(),
+			r.onStatementHandler(context),
+		),
+		interpreter.WithOnFunctionInvocationHandler(
+			r.onFunctionInvocationHandler(context),
+		),
+		interpreter.WithOnInvokedFunctionReturnHandler(
+			r.onInvokedFunctionReturnHandler(context),
+		),
+		interpreter.WithOnFunctionEntryHandler(
+			r.onFunctionEntryHandler(context),
+		),
+		interpreter.WithOnFunctionExitHandler(
+			r.onFunctionExitHandler(context),
+		),
+		interpreter.WithOnBranchHandler(
+			r.onBranchHandler(),
 		),
 		interpreter.WithPublicAccountHandler(
 			func(inter *interpreter.Interpreter, address interpreter.AddressValue) interpreter.Value {
@@ -1172,7 +1192,7 @@ func (r *interpreterRuntime) newInterpreter(
 	}
 
 	defaultOptions = append(defaultOptions,
-		r.meteringInterpreterOptions(context.Interface)...,
+		r.meteringInterpreterOptions(context)...,
 	)
 
 	return interpreter.NewInterpreter(
@@ -1322,36 +1342,18 @@ func (r *interpreterRuntime) injectedCompositeFieldsHandler(
 	}
 }
 
-func (r *interpreterRuntime) meteringInterpreterOptions(runtimeInterface Interface) []interpreter.Option {
-	callStackDepth := 0
-	// TODO: make runtime interface function
-	const callStackDepthLimit = 2000
-
-	checkCallStackDepth := func() {
-
-		if callStackDepth <= callStackDepthLimit {
-			return
-		}
-
-		panic(CallStackLimitExceededError{
-			Limit: callStackDepthLimit,
-		})
-	}
+func (r *interpreterRuntime) meteringInterpreterOptions(context Context) []interpreter.Option {
+	runtimeInterface := context.Interface
+	profiler := context.Profiler
 
 	return []interpreter.Option{
-		interpreter.WithOnFunctionInvocationHandler(
-			func(_ *interpreter.Interpreter, _ int) {
-				callStackDepth++
-				checkCallStackDepth()
-			},
-		),
-		interpreter.WithOnInvokedFunctionReturnHandler(
-			func(_ *interpreter.Interpreter, _ int) {
-				callStackDepth--
-			},
-		),
+		interpreter.WithCallStackDepthLimit(context.EffectiveCallStackDepthLimit()),
 		interpreter.WithOnMeterComputationFuncHandler(
 			func(compKind common.ComputationKind, intensity uint) {
+				if profiler != nil {
+					profiler.OnMeterComputation(compKind, intensity)
+				}
+
 				var err error
 				wrapPanic(func() {
 					err = runtimeInterface.MeterComputation(compKind, intensity)
@@ -2871,15 +2873,77 @@ func (r *interpreterRuntime) newAccountContractsGetNamesFunction(
 	}
 }
 
-func (r *interpreterRuntime) onStatementHandler() interpreter.OnStatementFunc {
-	if r.coverageReport == nil {
+func (r *interpreterRuntime) onStatementHandler(context Context) interpreter.OnStatementFunc {
+	coverageReport := r.coverageReport
+	profiler := context.Profiler
+
+	if coverageReport == nil && profiler == nil {
 		return nil
 	}
 
 	return func(inter *interpreter.Interpreter, statement ast.Statement) {
-		location := inter.Location
-		line := statement.StartPosition().Line
-		r.coverageReport.AddLineHit(location, line)
+		if coverageReport != nil {
+			location := inter.Location
+			line := statement.StartPosition().Line
+			coverageReport.AddLineHit(location, line)
+		}
+
+		if profiler != nil {
+			profiler.OnStatement(inter, statement)
+		}
+	}
+}
+
+func (r *interpreterRuntime) onFunctionInvocationHandler(context Context) interpreter.OnFunctionInvocationFunc {
+	if context.Profiler == nil {
+		return nil
+	}
+
+	return context.Profiler.OnFunctionInvocation
+}
+
+func (r *interpreterRuntime) onInvokedFunctionReturnHandler(context Context) interpreter.OnInvokedFunctionReturnFunc {
+	if context.Profiler == nil {
+		return nil
+	}
+
+	return context.Profiler.OnInvokedFunctionReturn
+}
+
+func (r *interpreterRuntime) onFunctionEntryHandler(context Context) interpreter.OnFunctionEntryFunc {
+	coverageReport := r.coverageReport
+	profiler := context.Profiler
+
+	if coverageReport == nil && profiler == nil {
+		return nil
+	}
+
+	return func(inter *interpreter.Interpreter, function *interpreter.InterpretedFunctionValue) {
+		if coverageReport != nil && function.Declaration != nil {
+			coverageReport.AddFunctionHit(inter.Location, function.Declaration)
+		}
+
+		if profiler != nil {
+			profiler.OnFunctionEntry(inter, function)
+		}
+	}
+}
+
+func (r *interpreterRuntime) onFunctionExitHandler(context Context) interpreter.OnFunctionExitFunc {
+	if context.Profiler == nil {
+		return nil
+	}
+
+	return context.Profiler.OnFunctionExit
+}
+
+func (r *interpreterRuntime) onBranchHandler() interpreter.OnBranchFunc {
+	if r.coverageReport == nil {
+		return nil
+	}
+
+	return func(inter *interpreter.Interpreter, element ast.Element, branch int) {
+		r.coverageReport.AddBranchHit(inter.Location, element, branch)
 	}
 }
 
@@ -1973,6 +1973,112 @@ func TestRuntimeStorageMultipleTransactionsResourceFunction(t *testing.T) {
 	assert.Contains(t, loggedMessages, "42")
 }
 
+// TestRuntimeStorageMultipleTransactionsGenericResource tests that
+// a stored instance of a generic resource retains its type arguments
+//
+func TestRuntimeStorageMultipleTransactionsGenericResource(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	vaults := []byte(`
+      pub resource Vault<T: Integer> {
+        pub let balance: T
+
+        init(balance: T) {
+          self.balance = balance
+        }
+      }
+
+      pub fun createVault<T: Integer>(balance: T): @Vault<T> {
+        return <-create Vault(balance: balance)
+      }
+    `)
+
+	script1 := []byte(`
+      import "vaults"
+
+      transaction {
+
+        prepare(signer: AuthAccount) {
+          signer.save(<-createVault(balance: 42 as UInt8), to: /storage/vault)
+        }
+      }
+    `)
+
+	script2 := []byte(`
+      import "vaults"
+
+      transaction {
+        prepare(signer: AuthAccount) {
+          let vault = signer.borrow<&Vault<UInt8>>(from: /storage/vault)!
+          log(vault.balance)
+          log(vault.getType().identifier)
+
+          let loaded <- signer.load<@AnyResource>(from: /storage/vault)!
+          log(loaded.isInstance(Type<@Vault<UInt16>>()))
+          destroy loaded
+        }
+      }
+    `)
+
+	var loggedMessages []string
+
+	ledger := newTestLedger(nil, nil)
+
+	runtimeInterface := &testRuntimeInterface{
+		getCode: func(location Location) (bytes []byte, err error) {
+			switch location {
+			case common.StringLocation("vaults"):
+				return vaults, nil
+			default:
+				return nil, fmt.Errorf("unknown import location: %s", location)
+			}
+		},
+		storage: ledger,
+		getSigningAccounts: func() ([]Address, error) {
+			return []Address{{42}}, nil
+		},
+		log: func(message string) {
+			loggedMessages = append(loggedMessages, message)
+		},
+	}
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: script1,
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	err = runtime.ExecuteTransaction(
+		Script{
+			Source: script2,
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]string{
+			"42",
+			`"S.vaults.Vault<UInt8>"`,
+			"false",
+		},
+		loggedMessages,
+	)
+}
+
 // TestRuntimeStorageMultipleTransactionsResourceField tests reading a field
 // of a stored resource declared in an imported program
 //
@@ -6927,6 +7033,79 @@ func TestRuntimeStackOverflow(t *testing.T) {
 	require.ErrorAs(t, err, &callStackLimitExceededErr)
 }
 
+func TestRuntimeCallStackDepthLimit(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	script := []byte(`
+      pub fun recurse(_ n: Int) {
+          if n > 0 {
+              recurse(n - 1)
+          }
+      }
+
+      pub fun main(n: Int) {
+          recurse(n)
+      }
+    `)
+
+	runtimeInterface := &testRuntimeInterface{
+		storage: newTestLedger(nil, nil),
+	}
+	runtimeInterface.decodeArgument = func(b []byte, t cadence.Type) (value cadence.Value, err error) {
+		return json.Decode(nil, b)
+	}
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	executeScript := func(n int, limit uint64) error {
+		_, err := runtime.ExecuteScript(
+			Script{
+				Source: script,
+				Arguments: encodeArgs([]cadence.Value{
+					cadence.NewInt(n),
+				}),
+			},
+			Context{
+				Interface:           runtimeInterface,
+				Location:            nextTransactionLocation(),
+				CallStackDepthLimit: limit,
+			},
+		)
+		return err
+	}
+
+	t.Run("custom limit", func(t *testing.T) {
+
+		// main + 10 invocations of recurse
+		err := executeScript(9, 11)
+		require.NoError(t, err)
+
+		err = executeScript(10, 11)
+		require.Error(t, err)
+		assertRuntimeErrorIsUserError(t, err)
+
+		var callStackLimitExceededErr CallStackLimitExceededError
+		require.ErrorAs(t, err, &callStackLimitExceededErr)
+		assert.Equal(t, uint64(11), callStackLimitExceededErr.Limit)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+
+		err := executeScript(100, 0)
+		require.NoError(t, err)
+
+		err = executeScript(DefaultCallStackDepthLimit, 0)
+		require.Error(t, err)
+
+		var callStackLimitExceededErr CallStackLimitExceededError
+		require.ErrorAs(t, err, &callStackLimitExceededErr)
+		assert.Equal(t, uint64(DefaultCallStackDepthLimit), callStackLimitExceededErr.Limit)
+	})
+}
+
 func TestRuntimeInternalErrors(t *testing.T) {
 
 	t.Parallel()
@@ -41,6 +41,12 @@ const AuthAccountGetCapabilityField = "getCapability"
 const AuthAccountGetLinkTargetField = "getLinkTarget"
 const AuthAccountContractsField = "contracts"
 const AuthAccountKeysField = "keys"
+const AuthAccountStoragePathsField = "storagePaths"
+const AuthAccountPublicPathsField = "publicPaths"
+const AuthAccountPrivatePathsField = "privatePaths"
+const AuthAccountForEachStoredField = "forEachStored"
+const AuthAccountForEachPublicField = "forEachPublic"
+const AuthAccountForEachPrivateField = "forEachPrivate"
 
 // AuthAccountType represents the authorized access to an account.
 // Access to an AuthAccount means having full access to its storage, public keys, and code.
@@ -170,6 +176,42 @@ var AuthAccountType = func() *CompositeType {
 			AuthAccountKeysType,
 			accountTypeKeysFieldDocString,
 		),
+		NewUnmeteredPublicConstantFieldMember(
+			authAccountType,
+			AuthAccountStoragePathsField,
+			AuthAccountStoragePathsType,
+			authAccountTypeStoragePathsFieldDocString,
+		),
+		NewUnmeteredPublicConstantFieldMember(
+			authAccountType,
+			AuthAccountPublicPathsField,
+			AccountPublicPathsType,
+			accountTypePublicPathsFieldDocString,
+		),
+		NewUnmeteredPublicConstantFieldMember(
+			authAccountType,
+			AuthAccountPrivatePathsField,
+			AuthAccountPrivatePathsType,
+			authAccountTypePrivatePathsFieldDocString,
+		),
+		NewUnmeteredPublicFunctionMember(
+			authAccountType,
+			AuthAccountForEachStoredField,
+			AuthAccountForEachStoredFunctionType,
+			authAccountForEachStoredFunctionDocString,
+		),
+		NewUnmeteredPublicFunctionMember(
+			authAccountType,
+			AuthAccountForEachPublicField,
+			AccountForEachPublicFunctionType,
+			accountForEachPublicFunctionDocString,
+		),
+		NewUnmeteredPublicFunctionMember(
+			authAccountType,
+			AuthAccountForEachPrivateField,
+			AuthAccountForEachPrivateFunctionType,
+			authAccountForEachPrivateFunctionDocString,
+		),
 	}
 
 	authAccountType.Members = GetMembersAsMap(members)
@@ -520,6 +562,99 @@ var AccountTypeGetLinkTargetFunctionType = &FunctionType{
 	),
 }
 
+var AuthAccountStoragePathsType = &VariableSizedType{
+	Type: StoragePathType,
+}
+
+var AccountPublicPathsType = &VariableSizedType{
+	Type: PublicPathType,
+}
+
+var AuthAccountPrivatePathsType = &VariableSizedType{
+	Type: PrivatePathType,
+}
+
+const authAccountTypeStoragePathsFieldDocString = `
+All the storage paths of the account which have an object stored under them
+`
+
+const accountTypePublicPathsFieldDocString = `
+All the public paths of the account which have a capability linked under them
+`
+
+const authAccountTypePrivatePathsFieldDocString = `
+All the private paths of the account which have a capability linked under them
+`
+
+// AccountForEachFunctionType returns the type of the functions
+// which iterate over the paths of the given path type in an account, e.g. `forEachStored`.
+//
+// The given function is called with each path and the type of the object stored under the path.
+// The iteration stops when the function returns false.
+//
+func AccountForEachFunctionType(pathType Type) *FunctionType {
+	iterationFunctionType := &FunctionType{
+		Parameters: []*Parameter{
+			{
+				Label:          ArgumentLabelNotRequired,
+				Identifier:     "path",
+				TypeAnnotation: NewTypeAnnotation(pathType),
+			},
+			{
+				Label:          ArgumentLabelNotRequired,
+				Identifier:     "type",
+				TypeAnnotation: NewTypeAnnotation(MetaType),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(BoolType),
+	}
+
+	return &FunctionType{
+		Parameters: []*Parameter{
+			{
+				Label:          ArgumentLabelNotRequired,
+				Identifier:     "function",
+				TypeAnnotation: NewTypeAnnotation(iterationFunctionType),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(VoidType),
+	}
+}
+
+var AuthAccountForEachStoredFunctionType = AccountForEachFunctionType(StoragePathType)
+
+var AccountForEachPublicFunctionType = AccountForEachFunctionType(PublicPathType)
+
+var AuthAccountForEachPrivateFunctionType = AccountForEachFunctionType(PrivatePathType)
+
+const authAccountForEachStoredFunctionDocString = `
+Iterates over all the objects stored in the account's storage, calling the given function with the storage path and the type of each object.
+The iteration stops when the function returns false.
+
+The order of iteration is undefined.
+
+Saving or loading objects in the account's storage while iterating aborts the program.
+Borrowing and modifying objects while iterating is allowed
+`
+
+const accountForEachPublicFunctionDocString = `
+Iterates over all the capabilities linked in the account's public domain, calling the given function with the public path and the type of each capability.
+The iteration stops when the function returns false.
+
+The order of iteration is undefined.
+
+Linking or unlinking capabilities in the account's public domain while iterating aborts the program
+`
+
+const authAccountForEachPrivateFunctionDocString = `
+Iterates over all the capabilities linked in the account's private domain, calling the given function with the private path and the type of each capability.
+The iteration stops when the function returns false.
+
+The order of iteration is undefined.
+
+Linking or unlinking capabilities in the account's private domain while iterating aborts the program
+`
+
 // AuthAccountKeysType represents the keys associated with an auth account.
 var AuthAccountKeysType = func() *CompositeType {
 
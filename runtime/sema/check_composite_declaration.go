@@ -197,9 +197,13 @@ func (checker *Checker) visitCompositeDeclaration(declaration *ast.CompositeDecl
 	for _, nestedComposite := range declaration.Members.Composites() {
 		nestedComposite.Accept(checker)
 	}
+
+	for _, typeAlias := range declaration.Members.TypeAliases() {
+		typeAlias.Accept(checker)
+	}
 }
 
-// declareCompositeNestedTypes declares the types nested in a composite,
+// declareCompositeNestedTypes declares the types and type aliases nested in a composite,
 // and the constructors for them if `declareConstructors` is true
 // and `kind` is `ContainerKindComposite`.
 //
@@ -217,6 +221,15 @@ func (checker *Checker) declareCompositeNestedTypes(
 	compositeType := checker.Elaboration.CompositeDeclarationTypes[declaration]
 	nestedDeclarations := checker.Elaboration.CompositeNestedDeclarations[declaration]
 
+	// Declare the type parameters of a generic composite, if any
+
+	if compositeType.IsGeneric() {
+		checker.declareTypeParameters(
+			declaration.TypeParameterList,
+			compositeType.typeParameters,
+		)
+	}
+
 	compositeType.nestedTypes.Foreach(func(name string, nestedType Type) {
 
 		nestedDeclaration := nestedDeclarations[name]
@@ -283,6 +296,31 @@ func (checker *Checker) declareCompositeNestedTypes(
 			}
 		}
 	})
+
+	// Declare the type aliases nested in the composite, if any.
+	// They were previously declared in `declareCompositeTypeAliases`
+
+	if compositeType.typeAliases == nil {
+		return
+	}
+
+	for _, typeAliasDeclaration := range declaration.Members.TypeAliases() {
+
+		aliasedType, ok := compositeType.typeAliases.Get(typeAliasDeclaration.Identifier.Identifier)
+		if !ok {
+			continue
+		}
+
+		_, err := checker.typeActivations.DeclareType(typeDeclaration{
+			identifier:               typeAliasDeclaration.Identifier,
+			ty:                       aliasedType,
+			declarationKind:          typeAliasDeclaration.DeclarationKind(),
+			access:                   typeAliasDeclaration.Access,
+			docString:                typeAliasDeclaration.DocString,
+			allowOuterScopeShadowing: true,
+		})
+		checker.report(err)
+	}
 }
 
 func (checker *Checker) declareNestedDeclarations(
@@ -441,6 +479,29 @@ func (checker *Checker) declareCompositeType(declaration *ast.CompositeDeclarati
 		)
 	}
 
+	// Resolve type parameters.
+	// Only structures and resources can be generic
+
+	if !declaration.TypeParameterList.IsEmpty() {
+		switch declaration.CompositeKind {
+		case common.CompositeKindStructure,
+			common.CompositeKindResource:
+
+			compositeType.typeParameters = checker.typeParameters(declaration.TypeParameterList)
+
+		default:
+			checker.report(
+				&InvalidTypeParametersError{
+					DeclarationKind: declaration.DeclarationKind(),
+					Range: ast.NewRangeFromPositioned(
+						checker.memoryGauge,
+						declaration.TypeParameterList,
+					),
+				},
+			)
+		}
+	}
+
 	// Resolve conformances
 
 	if declaration.CompositeKind == common.CompositeKindEnum {
@@ -1312,8 +1373,12 @@ func (checker *Checker) compositeConstructorType(
 	argumentLabels []string,
 ) {
 
+	// The constructor of a generic composite is generic,
+	// and returns an instantiation of the composite type
+
 	constructorFunctionType = &FunctionType{
 		IsConstructor:        true,
+		TypeParameters:       compositeType.typeParameters,
 		ReturnTypeAnnotation: NewTypeAnnotation(compositeType),
 	}
 
@@ -1479,7 +1544,11 @@ func (checker *Checker) defaultMembersAndOrigins(
 
 		identifier := function.Identifier.Identifier
 
-		functionType := checker.functionType(function.ParameterList, function.ReturnTypeAnnotation)
+		functionType := checker.functionType(
+			function.TypeParameterList,
+			function.ParameterList,
+			function.ReturnTypeAnnotation,
+		)
 
 		argumentLabels := function.ParameterList.EffectiveArgumentLabels()
 
@@ -231,6 +231,49 @@ func (checker *Checker) VisitStringExpression(expression *ast.StringExpression)
 	return actualType
 }
 
+var _ ast.StringTemplateExpressionVisitor = &Checker{}
+
+func (checker *Checker) VisitStringTemplateExpression(expression *ast.StringTemplateExpression) ast.Repr {
+
+	// The interpolated expressions must have a string representation,
+	// i.e. they must be strings, characters,
+	// or have a `toString` function, like numbers, addresses, and paths
+
+	valueTypes := make([]Type, len(expression.Expressions))
+
+	for i, interpolatedExpression := range expression.Expressions {
+		valueType := checker.VisitExpression(interpolatedExpression, nil)
+		valueTypes[i] = valueType
+
+		if valueType.IsInvalidType() || IsValidStringTemplateValueType(valueType) {
+			continue
+		}
+
+		checker.report(
+			&TypeMismatchWithDescriptionError{
+				ExpectedTypeDescription: "a string, character, number, address, or path type",
+				ActualType:              valueType,
+				Range:                   ast.NewRangeFromPositioned(checker.memoryGauge, interpolatedExpression),
+			},
+		)
+	}
+
+	checker.Elaboration.StringTemplateExpressionValueTypes[expression] = valueTypes
+
+	return StringType
+}
+
+// IsValidStringTemplateValueType returns true if values of the given type
+// can be interpolated in a string template
+//
+func IsValidStringTemplateValueType(ty Type) bool {
+	return IsSubType(ty, StringType) ||
+		IsSubType(ty, CharacterType) ||
+		IsSubType(ty, NumberType) ||
+		IsSubType(ty, &AddressType{}) ||
+		IsSubType(ty, PathType)
+}
+
 func (checker *Checker) VisitIndexExpression(expression *ast.IndexExpression) ast.Repr {
 	return checker.visitIndexExpression(expression, false)
 }
@@ -0,0 +1,167 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sema
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+func (checker *Checker) VisitTypeAliasDeclaration(declaration *ast.TypeAliasDeclaration) ast.Repr {
+
+	// NOTE: The aliased type was already resolved in `declareTypeAliasDeclaration`
+
+	checker.checkDeclarationAccessModifier(
+		declaration.Access,
+		declaration.DeclarationKind(),
+		declaration.StartPos,
+		true,
+	)
+
+	return nil
+}
+
+// declareTypeAliasDeclaration resolves the type aliased by the given declaration,
+// declares the alias in the current type activation, and records it in the elaboration.
+//
+// Type aliases are transparent: The alias is declared with the aliased type itself,
+// so the alias and the aliased type are the same type.
+//
+// Type aliases may only refer to type aliases which were declared before them,
+// so aliases can never be cyclic.
+//
+func (checker *Checker) declareTypeAliasDeclaration(declaration *ast.TypeAliasDeclaration) (Type, bool) {
+
+	aliasedType := checker.ConvertType(declaration.Type)
+
+	variable, err := checker.typeActivations.DeclareType(typeDeclaration{
+		identifier:               declaration.Identifier,
+		ty:                       aliasedType,
+		declarationKind:          declaration.DeclarationKind(),
+		access:                   declaration.Access,
+		docString:                declaration.DocString,
+		allowOuterScopeShadowing: false,
+	})
+	checker.report(err)
+
+	if checker.positionInfoEnabled {
+		checker.recordVariableDeclarationOccurrence(
+			declaration.Identifier.Identifier,
+			variable,
+		)
+	}
+
+	checker.Elaboration.TypeAliasDeclarationTypes[declaration] = aliasedType
+
+	return aliasedType, err == nil
+}
+
+// declareCompositeTypeAliases declares the type aliases nested in the given composite declaration,
+// and registers them in the composite type, so they can also be referred to
+// using the qualified name, e.g. `C.Alias`, including from other programs which import the composite.
+//
+// Only contracts support nested type aliases.
+//
+// It assumes the types of the composite declaration and its nested declarations
+// were previously declared using `declareCompositeType`.
+//
+func (checker *Checker) declareCompositeTypeAliases(declaration *ast.CompositeDeclaration) {
+
+	for _, nestedInterface := range declaration.Members.Interfaces() {
+		checker.declareInterfaceTypeAliases(nestedInterface)
+	}
+
+	for _, nestedComposite := range declaration.Members.Composites() {
+		checker.declareCompositeTypeAliases(nestedComposite)
+	}
+
+	typeAliasDeclarations := declaration.Members.TypeAliases()
+	if len(typeAliasDeclarations) == 0 {
+		return
+	}
+
+	if declaration.CompositeKind != common.CompositeKindContract {
+		checker.reportInvalidNestedTypeAlias(
+			typeAliasDeclarations[0],
+			declaration.DeclarationKind(),
+		)
+		return
+	}
+
+	compositeType := checker.Elaboration.CompositeDeclarationTypes[declaration]
+
+	// Activate new scope for the nested types and type aliases,
+	// so the aliased types may refer to them
+
+	checker.typeActivations.Enter()
+	defer checker.typeActivations.Leave(declaration.EndPosition)
+
+	checker.declareCompositeNestedTypes(declaration, ContainerKindComposite, false)
+
+	compositeType.typeAliases = &StringTypeOrderedMap{}
+
+	for _, typeAliasDeclaration := range typeAliasDeclarations {
+		aliasedType, ok := checker.declareTypeAliasDeclaration(typeAliasDeclaration)
+		if !ok {
+			continue
+		}
+
+		compositeType.typeAliases.Set(
+			typeAliasDeclaration.Identifier.Identifier,
+			aliasedType,
+		)
+	}
+}
+
+// declareInterfaceTypeAliases reports the type aliases nested in the given interface declaration,
+// and the declarations nested in it, as interfaces do not support nested type aliases.
+//
+func (checker *Checker) declareInterfaceTypeAliases(declaration *ast.InterfaceDeclaration) {
+
+	for _, nestedInterface := range declaration.Members.Interfaces() {
+		checker.declareInterfaceTypeAliases(nestedInterface)
+	}
+
+	for _, nestedComposite := range declaration.Members.Composites() {
+		checker.declareCompositeTypeAliases(nestedComposite)
+	}
+
+	typeAliasDeclarations := declaration.Members.TypeAliases()
+	if len(typeAliasDeclarations) == 0 {
+		return
+	}
+
+	checker.reportInvalidNestedTypeAlias(
+		typeAliasDeclarations[0],
+		declaration.DeclarationKind(),
+	)
+}
+
+func (checker *Checker) reportInvalidNestedTypeAlias(
+	declaration *ast.TypeAliasDeclaration,
+	containerDeclarationKind common.DeclarationKind,
+) {
+	checker.report(
+		&InvalidNestedDeclarationError{
+			NestedDeclarationKind:    declaration.DeclarationKind(),
+			ContainerDeclarationKind: containerDeclarationKind,
+			Range:                    ast.NewRangeFromPositioned(checker.memoryGauge, declaration.Identifier),
+		},
+	)
+}
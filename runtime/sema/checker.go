@@ -509,6 +509,21 @@ func (checker *Checker) VisitProgram(program *ast.Program) ast.Repr {
 		VisitThisAndNested(compositeType, registerInElaboration)
 	}
 
+	// Declare type aliases, after all interface and composite types were declared,
+	// so aliases may refer to them
+
+	for _, declaration := range program.TypeAliasDeclarations() {
+		checker.declareTypeAliasDeclaration(declaration)
+	}
+
+	for _, declaration := range program.InterfaceDeclarations() {
+		checker.declareInterfaceTypeAliases(declaration)
+	}
+
+	for _, declaration := range program.CompositeDeclarations() {
+		checker.declareCompositeTypeAliases(declaration)
+	}
+
 	// Declare interfaces' and composites' members
 
 	for _, declaration := range program.InterfaceDeclarations() {
@@ -595,7 +610,11 @@ func (checker *Checker) checkTopLevelDeclarationValidity(declarations []ast.Decl
 }
 
 func (checker *Checker) declareGlobalFunctionDeclaration(declaration *ast.FunctionDeclaration) {
-	functionType := checker.functionType(declaration.ParameterList, declaration.ReturnTypeAnnotation)
+	functionType := checker.functionType(
+		declaration.TypeParameterList,
+		declaration.ParameterList,
+		declaration.ReturnTypeAnnotation,
+	)
 	checker.Elaboration.FunctionDeclarationFunctionTypes[declaration] = functionType
 	checker.declareFunctionDeclaration(declaration, functionType)
 }
@@ -941,7 +960,9 @@ func (checker *Checker) findAndCheckValueVariable(identifierExpression *ast.Iden
 func (checker *Checker) ConvertType(t ast.Type) Type {
 	switch t := t.(type) {
 	case *ast.NominalType:
-		return checker.convertNominalType(t)
+		ty := checker.convertNominalType(t)
+		checker.checkGenericCompositeTypeInstantiated(ty, t)
+		return ty
 
 	case *ast.VariableSizedType:
 		return checker.convertVariableSizedType(t)
@@ -1356,7 +1377,7 @@ func (checker *Checker) convertNominalType(t *ast.NominalType) Type {
 
 	for _, identifier := range t.NestedIdentifiers {
 		if containerType, ok := ty.(ContainerType); ok && containerType.IsContainerType() {
-			ty, _ = containerType.GetNestedTypes().Get(identifier.Identifier)
+			ty = nestedTypeOrTypeAlias(containerType, identifier.Identifier)
 		} else {
 			if !ty.IsInvalidType() {
 				checker.report(
@@ -1409,20 +1430,129 @@ func (checker *Checker) ConvertTypeAnnotation(typeAnnotation *ast.TypeAnnotation
 }
 
 func (checker *Checker) functionType(
+	typeParameterList *ast.TypeParameterList,
 	parameterList *ast.ParameterList,
 	returnTypeAnnotation *ast.TypeAnnotation,
 ) *FunctionType {
+
+	// The type parameters of a generic function are only in scope
+	// for the parameters, the return type, and the function body
+
+	var typeParameters []*TypeParameter
+
+	if !typeParameterList.IsEmpty() {
+		typeParameters = checker.typeParameters(typeParameterList)
+
+		checker.typeActivations.Enter()
+		defer checker.typeActivations.Leave(typeParameterList.EndPosition)
+
+		checker.declareTypeParameters(typeParameterList, typeParameters)
+	}
+
 	convertedParameters := checker.parameters(parameterList)
 
 	convertedReturnTypeAnnotation :=
 		checker.ConvertTypeAnnotation(returnTypeAnnotation)
 
 	return &FunctionType{
+		TypeParameters:       typeParameters,
 		Parameters:           convertedParameters,
 		ReturnTypeAnnotation: convertedReturnTypeAnnotation,
 	}
 }
 
+// typeParameters converts the given type parameter list
+// of a generic function or composite declaration.
+//
+// The type bound of a type parameter defaults to `AnyStruct`.
+//
+func (checker *Checker) typeParameters(typeParameterList *ast.TypeParameterList) []*TypeParameter {
+	if typeParameterList.IsEmpty() {
+		return nil
+	}
+
+	typeParameters := make([]*TypeParameter, len(typeParameterList.TypeParameters))
+
+	positions := make(map[string]ast.Position, len(typeParameterList.TypeParameters))
+
+	for i, typeParameter := range typeParameterList.TypeParameters {
+		identifier := typeParameter.Identifier
+		name := identifier.Identifier
+
+		// Type parameters must be unique, and may not shadow built-in types
+
+		if previousPos, ok := positions[name]; ok {
+			checker.report(
+				&RedeclarationError{
+					Kind:        common.DeclarationKindTypeParameter,
+					Name:        name,
+					Pos:         identifier.Pos,
+					PreviousPos: &previousPos,
+				},
+			)
+		} else if variable := checker.typeActivations.Find(name); variable != nil && variable.ActivationDepth == 0 {
+			checker.report(
+				&RedeclarationError{
+					Kind:        common.DeclarationKindTypeParameter,
+					Name:        name,
+					Pos:         identifier.Pos,
+					PreviousPos: variable.Pos,
+				},
+			)
+		} else {
+			positions[name] = identifier.Pos
+		}
+
+		var typeBound Type = AnyStructType
+
+		if typeParameter.TypeBound != nil {
+			typeBoundAnnotation := checker.ConvertTypeAnnotation(typeParameter.TypeBound)
+			checker.checkTypeAnnotation(typeBoundAnnotation, typeParameter.TypeBound)
+
+			typeBound = typeBoundAnnotation.Type
+		}
+
+		typeParameters[i] = &TypeParameter{
+			Name:        name,
+			TypeBound:   typeBound,
+			UserDefined: true,
+		}
+	}
+
+	return typeParameters
+}
+
+// declareTypeParameters declares the generic types of the given type parameters
+// in the current type activation, so they can be used in type annotations.
+//
+// Errors for the type parameters are reported when they are converted, see `typeParameters`.
+//
+func (checker *Checker) declareTypeParameters(
+	typeParameterList *ast.TypeParameterList,
+	typeParameters []*TypeParameter,
+) {
+	for i, typeParameter := range typeParameters {
+		identifier := typeParameterList.TypeParameters[i].Identifier
+
+		variable, _ := checker.typeActivations.DeclareType(typeDeclaration{
+			identifier: identifier,
+			ty: &GenericType{
+				TypeParameter: typeParameter,
+			},
+			declarationKind:          common.DeclarationKindTypeParameter,
+			access:                   ast.AccessNotSpecified,
+			allowOuterScopeShadowing: true,
+		})
+
+		if checker.positionInfoEnabled {
+			checker.recordVariableDeclarationOccurrence(
+				identifier.Identifier,
+				variable,
+			)
+		}
+	}
+}
+
 func (checker *Checker) parameters(parameterList *ast.ParameterList) []*Parameter {
 
 	parameters := make([]*Parameter, len(parameterList.Parameters))
@@ -2327,9 +2457,45 @@ func (checker *Checker) effectiveCompositeMemberAccess(access ast.Access) ast.Ac
 	}
 }
 
+// checkGenericCompositeTypeInstantiated checks that a generic composite type
+// is instantiated with type arguments, unless it is referred to
+// in its own declaration, where the type parameters are in scope.
+//
+func (checker *Checker) checkGenericCompositeTypeInstantiated(ty Type, t *ast.NominalType) {
+	compositeType, ok := ty.(*CompositeType)
+	if !ok || !compositeType.IsGeneric() {
+		return
+	}
+
+	firstTypeParameter := compositeType.typeParameters[0]
+	if variable := checker.typeActivations.Find(firstTypeParameter.Name); variable != nil {
+		if genericType, ok := variable.Type.(*GenericType); ok &&
+			genericType.TypeParameter == firstTypeParameter {
+
+			return
+		}
+	}
+
+	checker.report(
+		&MissingTypeArgumentsError{
+			Type:               compositeType,
+			TypeParameterCount: len(compositeType.typeParameters),
+			Range:              ast.NewRangeFromPositioned(checker.memoryGauge, t),
+		},
+	)
+}
+
 func (checker *Checker) convertInstantiationType(t *ast.InstantiationType) Type {
 
-	ty := checker.ConvertType(t.Type)
+	// NOTE: Generic composite types are instantiated,
+	// so they do not have to be checked for missing type arguments
+
+	var ty Type
+	if nominalType, ok := t.Type.(*ast.NominalType); ok {
+		ty = checker.convertNominalType(nominalType)
+	} else {
+		ty = checker.ConvertType(t.Type)
+	}
 
 	// Always convert (check) the type arguments,
 	// even if the instantiated type
@@ -2344,7 +2510,7 @@ func (checker *Checker) convertInstantiationType(t *ast.InstantiationType) Type
 	}
 
 	parameterizedType, ok := ty.(ParameterizedType)
-	if !ok {
+	if !ok || len(parameterizedType.TypeParameters()) == 0 {
 
 		// The type is not parameterized,
 		// report an error for all type arguments
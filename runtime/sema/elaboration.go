@@ -70,8 +70,10 @@ type Elaboration struct {
 	DictionaryExpressionEntryTypes      map[*ast.DictionaryExpression][]DictionaryEntryType
 	IntegerExpressionType               map[*ast.IntegerExpression]Type
 	StringExpressionType                map[*ast.StringExpression]Type
+	StringTemplateExpressionValueTypes  map[*ast.StringTemplateExpression][]Type
 	FixedPointExpression                map[*ast.FixedPointExpression]Type
 	TransactionDeclarationTypes         map[*ast.TransactionDeclaration]*TransactionType
+	TypeAliasDeclarationTypes           map[*ast.TypeAliasDeclaration]Type
 	SwapStatementLeftTypes              map[*ast.SwapStatement]Type
 	SwapStatementRightTypes             map[*ast.SwapStatement]Type
 	// IsNestedResourceMoveExpression indicates if the access the index or member expression
@@ -141,8 +143,10 @@ func NewElaboration(gauge common.MemoryGauge, extendedElaboration bool) *Elabora
 		DictionaryExpressionEntryTypes:      map[*ast.DictionaryExpression][]DictionaryEntryType{},
 		IntegerExpressionType:               map[*ast.IntegerExpression]Type{},
 		StringExpressionType:                map[*ast.StringExpression]Type{},
+		StringTemplateExpressionValueTypes:  map[*ast.StringTemplateExpression][]Type{},
 		FixedPointExpression:                map[*ast.FixedPointExpression]Type{},
 		TransactionDeclarationTypes:         map[*ast.TransactionDeclaration]*TransactionType{},
+		TypeAliasDeclarationTypes:           map[*ast.TypeAliasDeclaration]Type{},
 		SwapStatementLeftTypes:              map[*ast.SwapStatement]Type{},
 		SwapStatementRightTypes:             map[*ast.SwapStatement]Type{},
 		IsNestedResourceMoveExpression:      map[ast.Expression]struct{}{},
@@ -0,0 +1,1582 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sema
+
+import (
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// ElaborationResolver returns the elaboration of the program with the given location.
+// It is used to resolve the types declared in other programs when decoding an elaboration.
+//
+type ElaborationResolver func(location common.Location) (*Elaboration, error)
+
+// DecodeElaboration decodes an elaboration encoded by EncodeElaboration.
+//
+// The program must have been decoded with the given element decoder before.
+// Types declared in other programs are resolved using the given resolver.
+//
+func DecodeElaboration(
+	decoder *cbor.StreamDecoder,
+	elementDecoder *ast.Decoder,
+	location common.Location,
+	resolve ElaborationResolver,
+) (
+	*Elaboration,
+	error,
+) {
+	d := &elaborationDecoder{
+		decoder:              decoder,
+		elementDecoder:       elementDecoder,
+		location:             location,
+		resolve:              resolve,
+		importedElaborations: map[common.LocationID]*Elaboration{},
+	}
+	return d.decodeElaboration()
+}
+
+type elaborationDecoder struct {
+	decoder              *cbor.StreamDecoder
+	elementDecoder       *ast.Decoder
+	location             common.Location
+	resolve              ElaborationResolver
+	importedElaborations map[common.LocationID]*Elaboration
+	types                []Type
+	typeParameters       []*TypeParameter
+	// instantiations are the decoded instantiations of generic composite types,
+	// which are only initialized once their generic composite types are decoded completely
+	instantiations []*CompositeType
+}
+
+// decodedPredeclaredValue is a predeclared value of a decoded elaboration.
+// Only the names of predeclared values are encoded, see EncodeElaboration.
+//
+type decodedPredeclaredValue string
+
+var _ ValueDeclaration = decodedPredeclaredValue("")
+
+func (v decodedPredeclaredValue) ValueDeclarationName() string {
+	return string(v)
+}
+
+func (decodedPredeclaredValue) ValueDeclarationType() Type {
+	return InvalidType
+}
+
+func (decodedPredeclaredValue) ValueDeclarationDocString() string {
+	return ""
+}
+
+func (decodedPredeclaredValue) ValueDeclarationKind() common.DeclarationKind {
+	return common.DeclarationKindUnknown
+}
+
+func (decodedPredeclaredValue) ValueDeclarationPosition() ast.Position {
+	return ast.EmptyPosition
+}
+
+func (decodedPredeclaredValue) ValueDeclarationIsConstant() bool {
+	return true
+}
+
+func (decodedPredeclaredValue) ValueDeclarationArgumentLabels() []string {
+	return nil
+}
+
+func (decodedPredeclaredValue) ValueDeclarationAvailable(_ common.Location) bool {
+	return true
+}
+
+// decodedPredeclaredType is a predeclared type of a decoded elaboration.
+// Only the names of predeclared types are encoded, see EncodeElaboration.
+//
+type decodedPredeclaredType string
+
+var _ TypeDeclaration = decodedPredeclaredType("")
+
+func (t decodedPredeclaredType) TypeDeclarationName() string {
+	return string(t)
+}
+
+func (decodedPredeclaredType) TypeDeclarationType() Type {
+	return InvalidType
+}
+
+func (decodedPredeclaredType) TypeDeclarationKind() common.DeclarationKind {
+	return common.DeclarationKindUnknown
+}
+
+func (decodedPredeclaredType) TypeDeclarationPosition() ast.Position {
+	return ast.EmptyPosition
+}
+
+func (d *elaborationDecoder) decodeElaboration() (*Elaboration, error) {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+	if length != encodedElaborationLength {
+		return nil, errors.NewUnexpectedError(
+			"invalid elaboration encoding: expected %d elements, got %d",
+			encodedElaborationLength,
+			length,
+		)
+	}
+
+	elaboration := NewElaboration(nil, false)
+
+	err = decodeElementMap(d, elaboration.PostConditionsRewrite, d.decodePostConditionsRewrite)
+	if err != nil {
+		return nil, err
+	}
+
+	var compositeTypes []*CompositeType
+	var interfaceTypes []*InterfaceType
+
+	for _, decode := range []func() error{
+		func() (err error) {
+			compositeTypes, err = decodeTypesAs[*CompositeType](d)
+			return
+		},
+		func() (err error) {
+			interfaceTypes, err = decodeTypesAs[*InterfaceType](d)
+			return
+		},
+		func() (err error) {
+			elaboration.TransactionTypes, err = decodeTypesAs[*TransactionType](d)
+			return
+		},
+		func() error {
+			names, err := d.decodeNames()
+			for _, name := range names {
+				elaboration.EffectivePredeclaredValues[name] = decodedPredeclaredValue(name)
+			}
+			return err
+		},
+		func() error {
+			names, err := d.decodeNames()
+			for _, name := range names {
+				elaboration.EffectivePredeclaredTypes[name] = decodedPredeclaredType(name)
+			}
+			return err
+		},
+		func() error {
+			return d.decodeVariables(elaboration.GlobalValues)
+		},
+		func() error {
+			return d.decodeVariables(elaboration.GlobalTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.FunctionDeclarationFunctionTypes, decodeTypeAs[*FunctionType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.VariableDeclarationValueTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.VariableDeclarationSecondValueTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.VariableDeclarationTargetTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.AssignmentStatementValueTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.AssignmentStatementTargetTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.CompositeDeclarationTypes, decodeTypeAs[*CompositeType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InterfaceDeclarationTypes, decodeTypeAs[*InterfaceType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ConstructorFunctionTypes, decodeTypeAs[*FunctionType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.FunctionExpressionFunctionType, decodeTypeAs[*FunctionType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InvocationExpressionArgumentTypes, d.decodeTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InvocationExpressionParameterTypes, d.decodeTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InvocationExpressionReturnTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InvocationExpressionTypeArguments, d.decodeTypeArguments)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.CastingStaticValueTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.CastingTargetTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ReturnStatementValueTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ReturnStatementReturnTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.BinaryExpressionResultTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.BinaryExpressionLeftTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.BinaryExpressionRightTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.MemberExpressionMemberInfos, d.decodeMemberInfo)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.MemberExpressionExpectedTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ArrayExpressionArgumentTypes, d.decodeTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ArrayExpressionArrayType, decodeTypeAs[ArrayType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.DictionaryExpressionType, decodeTypeAs[*DictionaryType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.DictionaryExpressionEntryTypes, d.decodeDictionaryEntryTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.IntegerExpressionType, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.StringExpressionType, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.StringTemplateExpressionValueTypes, d.decodeTypes)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.FixedPointExpression, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.TransactionDeclarationTypes, decodeTypeAs[*TransactionType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.TypeAliasDeclarationTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.SwapStatementLeftTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.SwapStatementRightTypes, d.decodeType)
+		},
+		func() error {
+			return d.decodeNestedResourceMoveExpressions(elaboration.IsNestedResourceMoveExpression)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.CompositeNestedDeclarations, d.decodeNestedDeclarations)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.InterfaceNestedDeclarations, d.decodeNestedDeclarations)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.EmitStatementEventTypes, decodeTypeAs[*CompositeType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.IdentifierInInvocationTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ImportDeclarationsResolvedLocations, d.decodeResolvedLocations)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.ReferenceExpressionBorrowTypes, d.decodeType)
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.IndexExpressionIndexedTypes, decodeTypeAs[ValueIndexableType](d))
+		},
+		func() error {
+			return decodeElementMap(d, elaboration.IndexExpressionIndexingTypes, d.decodeType)
+		},
+	} {
+		err = decode()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The types are only registered by their type ID once decoded completely,
+	// as the type ID of a nested type depends on its container type
+
+	for _, instantiation := range d.instantiations {
+		instantiation.initializeInstantiation()
+		instantiation.genericType.cachedInstantiation(instantiation)
+	}
+
+	for _, compositeType := range compositeTypes {
+		elaboration.CompositeTypes[compositeType.ID()] = compositeType
+	}
+
+	for _, interfaceType := range interfaceTypes {
+		elaboration.InterfaceTypes[interfaceType.ID()] = interfaceType
+	}
+
+	for declaration, compositeType := range elaboration.CompositeDeclarationTypes { //nolint:maprangecheck
+		elaboration.CompositeTypeDeclarations[compositeType] = declaration
+	}
+
+	for declaration, interfaceType := range elaboration.InterfaceDeclarationTypes { //nolint:maprangecheck
+		elaboration.InterfaceTypeDeclarations[interfaceType] = declaration
+	}
+
+	return elaboration, nil
+}
+
+// decodeNil decodes a CBOR nil, if the next value is nil.
+//
+func (d *elaborationDecoder) decodeNil() (bool, error) {
+	t, err := d.decoder.NextType()
+	if err != nil {
+		return false, err
+	}
+	if t != cbor.NilType {
+		return false, nil
+	}
+	return true, d.decoder.DecodeNil()
+}
+
+// decodeArrayHead decodes the head of an array which must have the given length.
+//
+func (d *elaborationDecoder) decodeArrayHead(expectedLength uint64) error {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+	if length != expectedLength {
+		return errors.NewUnexpectedError(
+			"invalid elaboration encoding: expected %d elements, got %d",
+			expectedLength,
+			length,
+		)
+	}
+	return nil
+}
+
+func (d *elaborationDecoder) decodeElement(index uint64) (any, error) {
+	element, ok := d.elementDecoder.Pointer(index)
+	if !ok {
+		return nil, errors.NewUnexpectedError("invalid element reference: %d", index)
+	}
+	return element, nil
+}
+
+func decodeElementMap[K comparable, V any](
+	d *elaborationDecoder,
+	m map[K]V,
+	decodeValue func() (V, error),
+) error {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < length; i++ {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		index, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return err
+		}
+
+		element, err := d.decodeElement(index)
+		if err != nil {
+			return err
+		}
+
+		key, ok := element.(K)
+		if !ok {
+			return errors.NewUnexpectedError("invalid element reference: %d", index)
+		}
+
+		value, err := decodeValue()
+		if err != nil {
+			return err
+		}
+
+		m[key] = value
+	}
+
+	return nil
+}
+
+func decodeTypeAs[T Type](d *elaborationDecoder) func() (T, error) {
+	return func() (T, error) {
+		var result T
+
+		ty, err := d.decodeType()
+		if err != nil || ty == nil {
+			return result, err
+		}
+
+		result, ok := ty.(T)
+		if !ok {
+			return result, errors.NewUnexpectedError("invalid elaboration encoding: unexpected type %s", ty)
+		}
+
+		return result, nil
+	}
+}
+
+func decodeTypesAs[T Type](d *elaborationDecoder) ([]T, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	decodeType := decodeTypeAs[T](d)
+
+	types := make([]T, length)
+	for i := range types {
+		types[i], err = decodeType()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return types, nil
+}
+
+func (d *elaborationDecoder) decodePostConditionsRewrite() (rewrite PostConditionsRewrite, err error) {
+	err = d.decodeArrayHead(2)
+	if err != nil {
+		return
+	}
+
+	err = d.elementDecoder.Decode(&rewrite.BeforeStatements)
+	if err != nil {
+		return
+	}
+
+	err = d.elementDecoder.Decode(&rewrite.RewrittenPostConditions)
+	return
+}
+
+func (d *elaborationDecoder) decodeNames() (names []string, err error) {
+	err = d.elementDecoder.Decode(&names)
+	return
+}
+
+func (d *elaborationDecoder) decodeVariables(variables *StringVariableOrderedMap) error {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < length; i++ {
+		variable, err := d.decodeVariable()
+		if err != nil {
+			return err
+		}
+		variables.Set(variable.Identifier, variable)
+	}
+
+	return nil
+}
+
+func (d *elaborationDecoder) decodeVariable() (*Variable, error) {
+	err := d.decodeArrayHead(10)
+	if err != nil {
+		return nil, err
+	}
+
+	variable := &Variable{}
+
+	variable.Identifier, err = d.decoder.DecodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	declarationKind, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return nil, err
+	}
+	variable.DeclarationKind = common.DeclarationKind(declarationKind)
+
+	variable.Type, err = d.decodeType()
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return nil, err
+	}
+	variable.Access = ast.Access(access)
+
+	variable.IsConstant, err = d.decoder.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+
+	variable.IsBaseValue, err = d.decoder.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+
+	activationDepth, err := d.decoder.DecodeInt64()
+	if err != nil {
+		return nil, err
+	}
+	variable.ActivationDepth = int(activationDepth)
+
+	err = d.elementDecoder.Decode(&variable.ArgumentLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.elementDecoder.Decode(&variable.Pos)
+	if err != nil {
+		return nil, err
+	}
+
+	variable.DocString, err = d.decoder.DecodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	return variable, nil
+}
+
+func (d *elaborationDecoder) decodeTypes() ([]Type, error) {
+	return decodeTypesAs[Type](d)
+}
+
+func (d *elaborationDecoder) decodeTypeArguments() (*TypeParameterTypeOrderedMap, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	typeArguments := &TypeParameterTypeOrderedMap{}
+
+	for i := uint64(0); i < length; i++ {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		typeParameter, err := d.decodeTypeParameter()
+		if err != nil {
+			return nil, err
+		}
+
+		ty, err := d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		typeArguments.Set(typeParameter, ty)
+	}
+
+	return typeArguments, nil
+}
+
+func (d *elaborationDecoder) decodeDictionaryEntryTypes() ([]DictionaryEntryType, error) {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	entryTypes := make([]DictionaryEntryType, length)
+
+	for i := range entryTypes {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		entryTypes[i].KeyType, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		entryTypes[i].ValueType, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entryTypes, nil
+}
+
+func (d *elaborationDecoder) decodeMemberInfo() (memberInfo MemberInfo, err error) {
+	err = d.decodeArrayHead(3)
+	if err != nil {
+		return
+	}
+
+	memberInfo.Member, err = d.decodeMemberOrReference()
+	if err != nil {
+		return
+	}
+
+	memberInfo.IsOptional, err = d.decoder.DecodeBool()
+	if err != nil {
+		return
+	}
+
+	memberInfo.AccessedType, err = d.decodeType()
+	return
+}
+
+func (d *elaborationDecoder) decodeNestedResourceMoveExpressions(expressions map[ast.Expression]struct{}) error {
+	var indices []uint64
+	err := d.elementDecoder.Decode(&indices)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		element, err := d.decodeElement(index)
+		if err != nil {
+			return err
+		}
+
+		expression, ok := element.(ast.Expression)
+		if !ok {
+			return errors.NewUnexpectedError("invalid element reference: %d", index)
+		}
+
+		expressions[expression] = struct{}{}
+	}
+
+	return nil
+}
+
+func (d *elaborationDecoder) decodeNestedDeclarations() (map[string]ast.Declaration, error) {
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	declarations := make(map[string]ast.Declaration, length)
+
+	for i := uint64(0); i < length; i++ {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		identifier, err := d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return nil, err
+		}
+
+		element, err := d.decodeElement(index)
+		if err != nil {
+			return nil, err
+		}
+
+		declaration, ok := element.(ast.Declaration)
+		if !ok {
+			return nil, errors.NewUnexpectedError("invalid element reference: %d", index)
+		}
+
+		declarations[identifier] = declaration
+	}
+
+	return declarations, nil
+}
+
+func (d *elaborationDecoder) decodeResolvedLocations() (resolvedLocations []ResolvedLocation, err error) {
+	err = d.elementDecoder.Decode(&resolvedLocations)
+	return
+}
+
+func (d *elaborationDecoder) decodeType() (Type, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	tag, err := d.decoder.DecodeTagNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case cborTagBuiltinType:
+		typeID, err := d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		ty, ok := builtinEncodingTypes()[TypeID(typeID)]
+		if !ok {
+			return nil, errors.NewUnexpectedError("cannot decode unknown type %s", typeID)
+		}
+		return ty, nil
+
+	case cborTagImportedCompositeType, cborTagImportedInterfaceType:
+		return d.decodeImportedType(tag)
+
+	case cborTagTypeReference:
+		index, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return nil, err
+		}
+		if index >= uint64(len(d.types)) {
+			return nil, errors.NewUnexpectedError("invalid type reference: %d", index)
+		}
+		return d.types[index], nil
+
+	case cborTagOptionalType:
+		ty := &OptionalType{}
+		d.types = append(d.types, ty)
+
+		ty.Type, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagVariableSizedType:
+		ty := &VariableSizedType{}
+		d.types = append(d.types, ty)
+
+		ty.Type, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagConstantSizedType:
+		ty := &ConstantSizedType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Type, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Size, err = d.decoder.DecodeInt64()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagDictionaryType:
+		ty := &DictionaryType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		ty.KeyType, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		ty.ValueType, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagReferenceType:
+		ty := &ReferenceType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Authorized, err = d.decoder.DecodeBool()
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Type, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagCapabilityType:
+		ty := &CapabilityType{}
+		d.types = append(d.types, ty)
+
+		ty.BorrowType, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagRestrictedType:
+		ty := &RestrictedType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Type, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		ty.Restrictions, err = decodeTypesAs[*InterfaceType](d)
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagAddressType:
+		ty := &AddressType{}
+		d.types = append(d.types, ty)
+
+		err = d.decoder.DecodeNil()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagFunctionType:
+		ty := &FunctionType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeFunctionType(ty)
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagGenericType:
+		ty := &GenericType{}
+		d.types = append(d.types, ty)
+
+		ty.TypeParameter, err = d.decodeTypeParameter()
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagCompositeType:
+		ty := &CompositeType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeCompositeType(ty)
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagInstantiatedCompositeType:
+		return d.decodeInstantiatedCompositeType()
+
+	case cborTagInterfaceType:
+		ty := &InterfaceType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeInterfaceType(ty)
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+
+	case cborTagTransactionType:
+		ty := &TransactionType{}
+		d.types = append(d.types, ty)
+
+		err = d.decodeTransactionType(ty)
+		if err != nil {
+			return nil, err
+		}
+		return ty, nil
+	}
+
+	return nil, errors.NewUnexpectedError("invalid type tag: %d", tag)
+}
+
+func (d *elaborationDecoder) decodeImportedType(tag uint64) (Type, error) {
+	err := d.decodeArrayHead(2)
+	if err != nil {
+		return nil, err
+	}
+
+	var location common.Location
+	err = d.elementDecoder.Decode(&location)
+	if err != nil {
+		return nil, err
+	}
+
+	typeID, err := d.decoder.DecodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	if location == nil {
+		return nil, errors.NewUnexpectedError("cannot decode imported type %s without location", typeID)
+	}
+
+	elaboration, err := d.importedElaboration(location)
+	if err != nil {
+		return nil, err
+	}
+
+	var ty Type
+	var ok bool
+
+	switch tag {
+	case cborTagImportedCompositeType:
+		ty, ok = elaboration.CompositeTypes[TypeID(typeID)]
+	case cborTagImportedInterfaceType:
+		ty, ok = elaboration.InterfaceTypes[TypeID(typeID)]
+	}
+
+	if !ok {
+		return nil, errors.NewUnexpectedError("cannot resolve imported type %s", typeID)
+	}
+
+	return ty, nil
+}
+
+func (d *elaborationDecoder) importedElaboration(location common.Location) (*Elaboration, error) {
+	locationID := location.ID()
+
+	if elaboration, ok := d.importedElaborations[locationID]; ok {
+		return elaboration, nil
+	}
+
+	if d.resolve == nil {
+		return nil, errors.NewUnexpectedError("cannot resolve elaboration of %s", location)
+	}
+
+	elaboration, err := d.resolve(location)
+	if err != nil {
+		return nil, err
+	}
+	if elaboration == nil {
+		return nil, errors.NewUnexpectedError("cannot resolve elaboration of %s", location)
+	}
+
+	d.importedElaborations[locationID] = elaboration
+
+	return elaboration, nil
+}
+
+func (d *elaborationDecoder) decodeInstantiatedCompositeType() (Type, error) {
+	err := d.decodeArrayHead(2)
+	if err != nil {
+		return nil, err
+	}
+
+	genericType, err := d.decodeType()
+	if err != nil {
+		return nil, err
+	}
+
+	compositeType, ok := genericType.(*CompositeType)
+	if !ok {
+		return nil, errors.NewUnexpectedError("invalid generic composite type: %T", genericType)
+	}
+
+	typeArguments, err := d.decodeTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	// The generic composite type might not be decoded completely yet,
+	// e.g. if the instantiation occurs in one of its members,
+	// so the instantiation is initialized after decoding
+
+	instantiation := &CompositeType{
+		genericType:   compositeType,
+		typeArguments: typeArguments,
+	}
+	d.instantiations = append(d.instantiations, instantiation)
+
+	return instantiation, nil
+}
+
+func (d *elaborationDecoder) decodeTypeParameters() ([]*TypeParameter, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	typeParameters := make([]*TypeParameter, length)
+
+	for i := range typeParameters {
+		typeParameters[i], err = d.decodeTypeParameter()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return typeParameters, nil
+}
+
+func (d *elaborationDecoder) decodeTypeParameter() (*TypeParameter, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	tag, err := d.decoder.DecodeTagNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case cborTagTypeParameterReference:
+		index, err := d.decoder.DecodeUint64()
+		if err != nil {
+			return nil, err
+		}
+		if index >= uint64(len(d.typeParameters)) {
+			return nil, errors.NewUnexpectedError("invalid type parameter reference: %d", index)
+		}
+		return d.typeParameters[index], nil
+
+	case cborTagTypeParameter:
+		typeParameter := &TypeParameter{}
+		d.typeParameters = append(d.typeParameters, typeParameter)
+
+		err = d.decodeArrayHead(4)
+		if err != nil {
+			return nil, err
+		}
+
+		typeParameter.Name, err = d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		typeParameter.TypeBound, err = d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		typeParameter.Optional, err = d.decoder.DecodeBool()
+		if err != nil {
+			return nil, err
+		}
+
+		typeParameter.UserDefined, err = d.decoder.DecodeBool()
+		if err != nil {
+			return nil, err
+		}
+
+		return typeParameter, nil
+	}
+
+	return nil, errors.NewUnexpectedError("invalid type parameter tag: %d", tag)
+}
+
+func (d *elaborationDecoder) decodeTypeAnnotation() (*TypeAnnotation, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	err = d.decodeArrayHead(2)
+	if err != nil {
+		return nil, err
+	}
+
+	typeAnnotation := &TypeAnnotation{}
+
+	typeAnnotation.IsResource, err = d.decoder.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+
+	typeAnnotation.Type, err = d.decodeType()
+	if err != nil {
+		return nil, err
+	}
+
+	return typeAnnotation, nil
+}
+
+func (d *elaborationDecoder) decodeParameters() ([]*Parameter, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := make([]*Parameter, length)
+
+	for i := range parameters {
+		err = d.decodeArrayHead(3)
+		if err != nil {
+			return nil, err
+		}
+
+		parameter := &Parameter{}
+
+		parameter.Label, err = d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		parameter.Identifier, err = d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		parameter.TypeAnnotation, err = d.decodeTypeAnnotation()
+		if err != nil {
+			return nil, err
+		}
+
+		parameters[i] = parameter
+	}
+
+	return parameters, nil
+}
+
+func (d *elaborationDecoder) decodeFunctionType(ty *FunctionType) error {
+	err := d.decodeArrayHead(6)
+	if err != nil {
+		return err
+	}
+
+	ty.IsConstructor, err = d.decoder.DecodeBool()
+	if err != nil {
+		return err
+	}
+
+	ty.TypeParameters, err = d.decodeTypeParameters()
+	if err != nil {
+		return err
+	}
+
+	ty.Parameters, err = d.decodeParameters()
+	if err != nil {
+		return err
+	}
+
+	ty.ReturnTypeAnnotation, err = d.decodeTypeAnnotation()
+	if err != nil {
+		return err
+	}
+
+	isNil, err := d.decodeNil()
+	if err != nil {
+		return err
+	}
+	if !isNil {
+		requiredArgumentCount, err := d.decoder.DecodeInt64()
+		if err != nil {
+			return err
+		}
+		ty.RequiredArgumentCount = RequiredArgumentCount(int(requiredArgumentCount))
+	}
+
+	ty.Members, err = d.decodeMembers()
+	return err
+}
+
+func (d *elaborationDecoder) decodeCompositeType(ty *CompositeType) error {
+	err := d.decodeArrayHead(15)
+	if err != nil {
+		return err
+	}
+
+	err = d.elementDecoder.Decode(&ty.Location)
+	if err != nil {
+		return err
+	}
+
+	ty.Identifier, err = d.decoder.DecodeString()
+	if err != nil {
+		return err
+	}
+
+	kind, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return err
+	}
+	ty.Kind = common.CompositeKind(kind)
+
+	ty.ExplicitInterfaceConformances, err = decodeTypesAs[*InterfaceType](d)
+	if err != nil {
+		return err
+	}
+
+	ty.ImplicitTypeRequirementConformances, err = decodeTypesAs[*CompositeType](d)
+	if err != nil {
+		return err
+	}
+
+	ty.Members, err = d.decodeMembers()
+	if err != nil {
+		return err
+	}
+
+	err = d.elementDecoder.Decode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	ty.ConstructorParameters, err = d.decodeParameters()
+	if err != nil {
+		return err
+	}
+
+	ty.nestedTypes, err = d.decodeNestedTypes()
+	if err != nil {
+		return err
+	}
+
+	ty.typeAliases, err = d.decodeNestedTypes()
+	if err != nil {
+		return err
+	}
+
+	ty.containerType, err = d.decodeType()
+	if err != nil {
+		return err
+	}
+
+	ty.EnumRawType, err = d.decodeType()
+	if err != nil {
+		return err
+	}
+
+	ty.hasComputedMembers, err = d.decoder.DecodeBool()
+	if err != nil {
+		return err
+	}
+
+	ty.importable, err = d.decoder.DecodeBool()
+	if err != nil {
+		return err
+	}
+
+	ty.typeParameters, err = d.decodeTypeParameters()
+	return err
+}
+
+func (d *elaborationDecoder) decodeInterfaceType(ty *InterfaceType) error {
+	err := d.decodeArrayHead(8)
+	if err != nil {
+		return err
+	}
+
+	err = d.elementDecoder.Decode(&ty.Location)
+	if err != nil {
+		return err
+	}
+
+	ty.Identifier, err = d.decoder.DecodeString()
+	if err != nil {
+		return err
+	}
+
+	compositeKind, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return err
+	}
+	ty.CompositeKind = common.CompositeKind(compositeKind)
+
+	ty.Members, err = d.decodeMembers()
+	if err != nil {
+		return err
+	}
+
+	err = d.elementDecoder.Decode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	ty.InitializerParameters, err = d.decodeParameters()
+	if err != nil {
+		return err
+	}
+
+	ty.containerType, err = d.decodeType()
+	if err != nil {
+		return err
+	}
+
+	ty.nestedTypes, err = d.decodeNestedTypes()
+	return err
+}
+
+func (d *elaborationDecoder) decodeTransactionType(ty *TransactionType) error {
+	err := d.decodeArrayHead(4)
+	if err != nil {
+		return err
+	}
+
+	ty.Members, err = d.decodeMembers()
+	if err != nil {
+		return err
+	}
+
+	err = d.elementDecoder.Decode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	ty.PrepareParameters, err = d.decodeParameters()
+	if err != nil {
+		return err
+	}
+
+	ty.Parameters, err = d.decodeParameters()
+	return err
+}
+
+func (d *elaborationDecoder) decodeNestedTypes() (*StringTypeOrderedMap, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	nestedTypes := &StringTypeOrderedMap{}
+
+	for i := uint64(0); i < length; i++ {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		identifier, err := d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		nestedType, err := d.decodeType()
+		if err != nil {
+			return nil, err
+		}
+
+		nestedTypes.Set(identifier, nestedType)
+	}
+
+	return nestedTypes, nil
+}
+
+func (d *elaborationDecoder) decodeMembers() (*StringMemberOrderedMap, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	length, err := d.decoder.DecodeArrayHead()
+	if err != nil {
+		return nil, err
+	}
+
+	members := &StringMemberOrderedMap{}
+
+	for i := uint64(0); i < length; i++ {
+		err = d.decodeArrayHead(2)
+		if err != nil {
+			return nil, err
+		}
+
+		identifier, err := d.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+
+		member, err := d.decodeMember()
+		if err != nil {
+			return nil, err
+		}
+
+		members.Set(identifier, member)
+	}
+
+	return members, nil
+}
+
+func (d *elaborationDecoder) decodeMemberOrReference() (*Member, error) {
+	isNil, err := d.decodeNil()
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	t, err := d.decoder.NextType()
+	if err != nil {
+		return nil, err
+	}
+
+	if t != cbor.TagType {
+		return d.decodeMember()
+	}
+
+	tag, err := d.decoder.DecodeTagNumber()
+	if err != nil {
+		return nil, err
+	}
+	if tag != cborTagMemberReference {
+		return nil, errors.NewUnexpectedError("invalid member tag: %d", tag)
+	}
+
+	err = d.decodeArrayHead(2)
+	if err != nil {
+		return nil, err
+	}
+
+	containerType, err := d.decodeType()
+	if err != nil {
+		return nil, err
+	}
+
+	identifier, err := d.decoder.DecodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	var members *StringMemberOrderedMap
+
+	switch containerType := containerType.(type) {
+	case *CompositeType:
+		members = containerType.Members
+	case *InterfaceType:
+		members = containerType.Members
+	case *TransactionType:
+		members = containerType.Members
+	}
+
+	if members != nil {
+		member, ok := members.Get(identifier)
+		if ok {
+			return member, nil
+		}
+	}
+
+	return nil, errors.NewUnexpectedError("cannot resolve member %s of %s", identifier, containerType)
+}
+
+func (d *elaborationDecoder) decodeMember() (*Member, error) {
+	err := d.decodeArrayHead(10)
+	if err != nil {
+		return nil, err
+	}
+
+	member := &Member{}
+
+	member.ContainerType, err = d.decodeType()
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return nil, err
+	}
+	member.Access = ast.Access(access)
+
+	err = d.elementDecoder.Decode(&member.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	member.TypeAnnotation, err = d.decodeTypeAnnotation()
+	if err != nil {
+		return nil, err
+	}
+
+	declarationKind, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return nil, err
+	}
+	member.DeclarationKind = common.DeclarationKind(declarationKind)
+
+	variableKind, err := d.decoder.DecodeUint64()
+	if err != nil {
+		return nil, err
+	}
+	member.VariableKind = ast.VariableKind(variableKind)
+
+	err = d.elementDecoder.Decode(&member.ArgumentLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Predeclared, err = d.decoder.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+
+	member.IgnoreInSerialization, err = d.decoder.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+
+	member.DocString, err = d.decoder.DecodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
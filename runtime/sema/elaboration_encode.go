@@ -0,0 +1,1444 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sema
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// CBOR tags of the elaboration encoding.
+//
+const (
+	// cborTagBuiltinType is the tag of a type which is encoded by its type ID,
+	// see builtinEncodingTypes
+	cborTagBuiltinType = 210 + iota
+	// cborTagImportedCompositeType and cborTagImportedInterfaceType are the tags of
+	// composite and interface types declared in another program,
+	// which are encoded by their location and type ID
+	cborTagImportedCompositeType
+	cborTagImportedInterfaceType
+	// cborTagTypeReference is the tag of a type which was already encoded,
+	// the tag content is the index of the type, in the order the types were encoded
+	cborTagTypeReference
+	cborTagOptionalType
+	cborTagVariableSizedType
+	cborTagConstantSizedType
+	cborTagDictionaryType
+	cborTagReferenceType
+	cborTagCapabilityType
+	cborTagRestrictedType
+	cborTagAddressType
+	cborTagFunctionType
+	cborTagGenericType
+	cborTagCompositeType
+	cborTagInterfaceType
+	cborTagTransactionType
+	// cborTagTypeParameter and cborTagTypeParameterReference are the tags of
+	// a type parameter which is encoded for the first time, and which was already encoded
+	cborTagTypeParameter
+	cborTagTypeParameterReference
+	// cborTagMemberReference is the tag of a member of a composite, interface, or transaction type
+	// declared in the program, which is encoded by its container type and identifier
+	cborTagMemberReference
+	// cborTagInstantiatedCompositeType is the tag of an instantiation
+	// of a user-defined generic composite type,
+	// which is encoded by its generic composite type and its type arguments
+	cborTagInstantiatedCompositeType
+)
+
+// encodedElaborationLength is the number of elements of an encoded elaboration
+//
+const encodedElaborationLength = 52
+
+var builtinEncodingTypesOnce sync.Once
+var builtinEncodingTypesByID map[TypeID]Type
+
+// builtinEncodingTypes returns the types which are encoded by their type ID,
+// i.e. the simple types, the number types, and the native composite types.
+//
+func builtinEncodingTypes() map[TypeID]Type {
+	builtinEncodingTypesOnce.Do(func() {
+		builtinEncodingTypesByID = map[TypeID]Type{}
+
+		add := func(ty Type) {
+			switch ty := ty.(type) {
+			case *SimpleType, *NumericType, *FixedPointNumericType:
+				builtinEncodingTypesByID[ty.ID()] = ty
+			case *CompositeType:
+				VisitThisAndNested(ty, func(ty Type) {
+					builtinEncodingTypesByID[ty.ID()] = ty
+				})
+			}
+		}
+
+		_ = BaseTypeActivation.ForEach(func(_ string, variable *Variable) error {
+			add(variable.Type)
+			return nil
+		})
+
+		for _, ty := range []Type{
+			AnyType,
+			InvalidType,
+			StorableType,
+		} {
+			add(ty)
+		}
+
+		for _, ty := range AllNumberTypes {
+			add(ty)
+		}
+
+		for _, ty := range NativeCompositeTypes { //nolint:maprangecheck
+			add(ty)
+		}
+	})
+	return builtinEncodingTypesByID
+}
+
+// EncodeElaboration encodes the given elaboration of the program with the given location.
+//
+// The program must have been encoded with the given element encoder before,
+// as the elaboration refers to the elements of the program by their pointer index.
+//
+// Types declared in the program are encoded completely.
+// Types declared in other programs, e.g. imported contracts, are only encoded by their location and type ID,
+// and must be provided when decoding, see DecodeElaboration.
+//
+// Only the names of predeclared values and types are encoded,
+// they are neither part of the global values nor the global types of the encoded elaboration.
+// The extended elaboration, which is only used for linting, is not encoded.
+//
+func EncodeElaboration(
+	encoder *cbor.StreamEncoder,
+	elementEncoder *ast.Encoder,
+	location common.Location,
+	elaboration *Elaboration,
+) error {
+	e := &elaborationEncoder{
+		encoder:              encoder,
+		elementEncoder:       elementEncoder,
+		location:             location,
+		typeIndices:          map[Type]uint64{},
+		typeParameterIndices: map[*TypeParameter]uint64{},
+	}
+	return e.encodeElaboration(elaboration)
+}
+
+type elaborationEncoder struct {
+	encoder              *cbor.StreamEncoder
+	elementEncoder       *ast.Encoder
+	location             common.Location
+	typeIndices          map[Type]uint64
+	typeParameterIndices map[*TypeParameter]uint64
+}
+
+func (e *elaborationEncoder) encodeElaboration(elaboration *Elaboration) error {
+	err := e.encoder.EncodeArrayHead(encodedElaborationLength)
+	if err != nil {
+		return err
+	}
+
+	// The post-conditions rewrites are encoded first,
+	// as they contain elements which are not part of the program,
+	// and which are referred to by the other entries
+
+	err = encodeElementMap(e, elaboration.PostConditionsRewrite, e.encodePostConditionsRewrite)
+	if err != nil {
+		return err
+	}
+
+	for _, encode := range []func() error{
+		func() error {
+			return encodeNominalTypes(e, elaboration.CompositeTypes)
+		},
+		func() error {
+			return encodeNominalTypes(e, elaboration.InterfaceTypes)
+		},
+		func() error {
+			return e.encodeTransactionTypes(elaboration.TransactionTypes)
+		},
+		func() error {
+			return encodeNames(e, elaboration.EffectivePredeclaredValues)
+		},
+		func() error {
+			return encodeNames(e, elaboration.EffectivePredeclaredTypes)
+		},
+		func() error {
+			return encodeVariables(e, elaboration.GlobalValues, elaboration.EffectivePredeclaredValues)
+		},
+		func() error {
+			return encodeVariables(e, elaboration.GlobalTypes, elaboration.EffectivePredeclaredTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.FunctionDeclarationFunctionTypes, encodeTypeAs[*FunctionType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.VariableDeclarationValueTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.VariableDeclarationSecondValueTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.VariableDeclarationTargetTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.AssignmentStatementValueTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.AssignmentStatementTargetTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.CompositeDeclarationTypes, encodeTypeAs[*CompositeType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InterfaceDeclarationTypes, encodeTypeAs[*InterfaceType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ConstructorFunctionTypes, encodeTypeAs[*FunctionType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.FunctionExpressionFunctionType, encodeTypeAs[*FunctionType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InvocationExpressionArgumentTypes, e.encodeTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InvocationExpressionParameterTypes, e.encodeTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InvocationExpressionReturnTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InvocationExpressionTypeArguments, e.encodeTypeArguments)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.CastingStaticValueTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.CastingTargetTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ReturnStatementValueTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ReturnStatementReturnTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.BinaryExpressionResultTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.BinaryExpressionLeftTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.BinaryExpressionRightTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.MemberExpressionMemberInfos, e.encodeMemberInfo)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.MemberExpressionExpectedTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ArrayExpressionArgumentTypes, e.encodeTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ArrayExpressionArrayType, encodeTypeAs[ArrayType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.DictionaryExpressionType, encodeTypeAs[*DictionaryType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.DictionaryExpressionEntryTypes, e.encodeDictionaryEntryTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.IntegerExpressionType, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.StringExpressionType, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.StringTemplateExpressionValueTypes, e.encodeTypes)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.FixedPointExpression, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.TransactionDeclarationTypes, encodeTypeAs[*TransactionType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.TypeAliasDeclarationTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.SwapStatementLeftTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.SwapStatementRightTypes, e.encodeType)
+		},
+		func() error {
+			return e.encodeNestedResourceMoveExpressions(elaboration.IsNestedResourceMoveExpression)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.CompositeNestedDeclarations, e.encodeNestedDeclarations)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.InterfaceNestedDeclarations, e.encodeNestedDeclarations)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.EmitStatementEventTypes, encodeTypeAs[*CompositeType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.IdentifierInInvocationTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ImportDeclarationsResolvedLocations, e.encodeResolvedLocations)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.ReferenceExpressionBorrowTypes, e.encodeType)
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.IndexExpressionIndexedTypes, encodeTypeAs[ValueIndexableType](e))
+		},
+		func() error {
+			return encodeElementMap(e, elaboration.IndexExpressionIndexingTypes, e.encodeType)
+		},
+	} {
+		err = encode()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeElementMap encodes the entries of the given map, which is keyed by elements,
+// as an array of pairs of the pointer index of the element and the encoded value,
+// ordered by the pointer index.
+//
+// Entries of elements which were not encoded are skipped,
+// as they are not reachable from the decoded program.
+//
+func encodeElementMap[K comparable, V any](
+	e *elaborationEncoder,
+	m map[K]V,
+	encodeValue func(V) error,
+) error {
+	type entry struct {
+		index uint64
+		value V
+	}
+
+	entries := make([]entry, 0, len(m))
+
+	for key, value := range m { //nolint:maprangecheck
+		index, ok := e.elementEncoder.PointerIndex(key)
+		if !ok {
+			continue
+		}
+		entries = append(entries, entry{
+			index: index,
+			value: value,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].index < entries[j].index
+	})
+
+	err := e.encoder.EncodeArrayHead(uint64(len(entries)))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeUint64(entry.index)
+		if err != nil {
+			return err
+		}
+
+		err = encodeValue(entry.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeTypeAs[T Type](e *elaborationEncoder) func(T) error {
+	return func(ty T) error {
+		return e.encodeType(ty)
+	}
+}
+
+// encodeNestedResourceMoveExpressions encodes the given set of expressions
+// as an array of the pointer indices of the expressions, in increasing order.
+//
+func (e *elaborationEncoder) encodeNestedResourceMoveExpressions(expressions map[ast.Expression]struct{}) error {
+	indices := make([]uint64, 0, len(expressions))
+
+	for expression := range expressions { //nolint:maprangecheck
+		index, ok := e.elementEncoder.PointerIndex(expression)
+		if !ok {
+			continue
+		}
+		indices = append(indices, index)
+	}
+
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+
+	return e.elementEncoder.Encode(&indices)
+}
+
+func (e *elaborationEncoder) encodePostConditionsRewrite(rewrite PostConditionsRewrite) error {
+	err := e.encoder.EncodeArrayHead(2)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&rewrite.BeforeStatements)
+	if err != nil {
+		return err
+	}
+
+	return e.elementEncoder.Encode(&rewrite.RewrittenPostConditions)
+}
+
+// encodeNominalTypes encodes the values of the given composite or interface types, ordered by type ID.
+//
+func encodeNominalTypes[T Type](e *elaborationEncoder, types map[TypeID]T) error {
+	typeIDs := make([]string, 0, len(types))
+	for typeID := range types { //nolint:maprangecheck
+		typeIDs = append(typeIDs, string(typeID))
+	}
+	sort.Strings(typeIDs)
+
+	err := e.encoder.EncodeArrayHead(uint64(len(typeIDs)))
+	if err != nil {
+		return err
+	}
+
+	for _, typeID := range typeIDs {
+		err = e.encodeType(types[TypeID(typeID)])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeTransactionTypes(types []*TransactionType) error {
+	err := e.encoder.EncodeArrayHead(uint64(len(types)))
+	if err != nil {
+		return err
+	}
+
+	for _, ty := range types {
+		err = e.encodeType(ty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeNames encodes the names of the given predeclared values or types, in lexicographic order.
+//
+func encodeNames[T any](e *elaborationEncoder, declarations map[string]T) error {
+	names := make([]string, 0, len(declarations))
+	for name := range declarations { //nolint:maprangecheck
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return e.elementEncoder.Encode(&names)
+}
+
+// encodeVariables encodes the given global values or types,
+// except for the predeclared ones.
+//
+func encodeVariables[T any](
+	e *elaborationEncoder,
+	variables *StringVariableOrderedMap,
+	predeclared map[string]T,
+) error {
+	var globalVariables []*Variable
+
+	variables.Foreach(func(name string, variable *Variable) {
+		if _, ok := predeclared[name]; ok {
+			return
+		}
+		globalVariables = append(globalVariables, variable)
+	})
+
+	err := e.encoder.EncodeArrayHead(uint64(len(globalVariables)))
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range globalVariables {
+		err = e.encodeVariable(variable)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeVariable(variable *Variable) error {
+	err := e.encoder.EncodeArrayHead(10)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeString(variable.Identifier)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(variable.DeclarationKind))
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(variable.Type)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(variable.Access))
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(variable.IsConstant)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(variable.IsBaseValue)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeInt64(int64(variable.ActivationDepth))
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&variable.ArgumentLabels)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&variable.Pos)
+	if err != nil {
+		return err
+	}
+
+	return e.encoder.EncodeString(variable.DocString)
+}
+
+func (e *elaborationEncoder) encodeTypes(types []Type) error {
+	if types == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(len(types)))
+	if err != nil {
+		return err
+	}
+
+	for _, ty := range types {
+		err = e.encodeType(ty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeTypeArguments(typeArguments *TypeParameterTypeOrderedMap) error {
+	if typeArguments == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(typeArguments.Len()))
+	if err != nil {
+		return err
+	}
+
+	return typeArguments.ForeachWithError(func(typeParameter *TypeParameter, ty Type) error {
+		err := e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encodeTypeParameter(typeParameter)
+		if err != nil {
+			return err
+		}
+
+		return e.encodeType(ty)
+	})
+}
+
+func (e *elaborationEncoder) encodeDictionaryEntryTypes(entryTypes []DictionaryEntryType) error {
+	err := e.encoder.EncodeArrayHead(uint64(len(entryTypes)))
+	if err != nil {
+		return err
+	}
+
+	for _, entryType := range entryTypes {
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encodeType(entryType.KeyType)
+		if err != nil {
+			return err
+		}
+
+		err = e.encodeType(entryType.ValueType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeMemberInfo(memberInfo MemberInfo) error {
+	err := e.encoder.EncodeArrayHead(3)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeMemberOrReference(memberInfo.Member)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(memberInfo.IsOptional)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeType(memberInfo.AccessedType)
+}
+
+// encodeNestedDeclarations encodes the given nested declarations,
+// as an array of pairs of the identifier and the pointer index of the declaration,
+// in lexicographic order of the identifiers.
+//
+func (e *elaborationEncoder) encodeNestedDeclarations(declarations map[string]ast.Declaration) error {
+	identifiers := make([]string, 0, len(declarations))
+	for identifier := range declarations { //nolint:maprangecheck
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	err := e.encoder.EncodeArrayHead(uint64(len(identifiers)))
+	if err != nil {
+		return err
+	}
+
+	for _, identifier := range identifiers {
+		index, ok := e.elementEncoder.PointerIndex(declarations[identifier])
+		if !ok {
+			return errors.NewUnexpectedError("cannot encode nested declaration %s", identifier)
+		}
+
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeString(identifier)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeUint64(index)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeResolvedLocations(resolvedLocations []ResolvedLocation) error {
+	return e.elementEncoder.Encode(&resolvedLocations)
+}
+
+func (e *elaborationEncoder) isDeclaredInProgram(location common.Location) bool {
+	return location != nil &&
+		e.location != nil &&
+		location.ID() == e.location.ID()
+}
+
+// encodeType encodes the given type.
+//
+// Types which are encoded for the first time are assigned an index,
+// later occurrences of the same type are encoded as a reference.
+// This preserves the identity of types, and allows types declared in the program to be recursive.
+//
+func (e *elaborationEncoder) encodeType(ty Type) error {
+	if ty == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	if index, ok := e.typeIndices[ty]; ok {
+		err := e.encoder.EncodeTagHead(cborTagTypeReference)
+		if err != nil {
+			return err
+		}
+		return e.encoder.EncodeUint64(index)
+	}
+
+	switch ty := ty.(type) {
+	case *SimpleType, *NumericType, *FixedPointNumericType:
+		return e.encodeBuiltinType(ty)
+
+	case *CompositeType:
+		if ty.genericType != nil {
+			return e.encodeInstantiatedCompositeType(ty)
+		}
+		if ty.Location == nil {
+			return e.encodeBuiltinType(ty)
+		}
+		if !e.isDeclaredInProgram(ty.Location) {
+			return e.encodeImportedType(cborTagImportedCompositeType, ty.Location, ty.ID())
+		}
+
+	case *InterfaceType:
+		if ty.Location == nil {
+			return e.encodeBuiltinType(ty)
+		}
+		if !e.isDeclaredInProgram(ty.Location) {
+			return e.encodeImportedType(cborTagImportedInterfaceType, ty.Location, ty.ID())
+		}
+	}
+
+	e.typeIndices[ty] = uint64(len(e.typeIndices))
+
+	switch ty := ty.(type) {
+	case *OptionalType:
+		err := e.encoder.EncodeTagHead(cborTagOptionalType)
+		if err != nil {
+			return err
+		}
+		return e.encodeType(ty.Type)
+
+	case *VariableSizedType:
+		err := e.encoder.EncodeTagHead(cborTagVariableSizedType)
+		if err != nil {
+			return err
+		}
+		return e.encodeType(ty.Type)
+
+	case *ConstantSizedType:
+		err := e.encoder.EncodeTagHead(cborTagConstantSizedType)
+		if err != nil {
+			return err
+		}
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+		err = e.encodeType(ty.Type)
+		if err != nil {
+			return err
+		}
+		return e.encoder.EncodeInt64(ty.Size)
+
+	case *DictionaryType:
+		err := e.encoder.EncodeTagHead(cborTagDictionaryType)
+		if err != nil {
+			return err
+		}
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+		err = e.encodeType(ty.KeyType)
+		if err != nil {
+			return err
+		}
+		return e.encodeType(ty.ValueType)
+
+	case *ReferenceType:
+		err := e.encoder.EncodeTagHead(cborTagReferenceType)
+		if err != nil {
+			return err
+		}
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+		err = e.encoder.EncodeBool(ty.Authorized)
+		if err != nil {
+			return err
+		}
+		return e.encodeType(ty.Type)
+
+	case *CapabilityType:
+		err := e.encoder.EncodeTagHead(cborTagCapabilityType)
+		if err != nil {
+			return err
+		}
+		return e.encodeType(ty.BorrowType)
+
+	case *RestrictedType:
+		err := e.encoder.EncodeTagHead(cborTagRestrictedType)
+		if err != nil {
+			return err
+		}
+		err = e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+		err = e.encodeType(ty.Type)
+		if err != nil {
+			return err
+		}
+		return e.encodeInterfaceTypes(ty.Restrictions)
+
+	case *AddressType:
+		err := e.encoder.EncodeTagHead(cborTagAddressType)
+		if err != nil {
+			return err
+		}
+		return e.encoder.EncodeNil()
+
+	case *FunctionType:
+		err := e.encoder.EncodeTagHead(cborTagFunctionType)
+		if err != nil {
+			return err
+		}
+		return e.encodeFunctionType(ty)
+
+	case *GenericType:
+		err := e.encoder.EncodeTagHead(cborTagGenericType)
+		if err != nil {
+			return err
+		}
+		return e.encodeTypeParameter(ty.TypeParameter)
+
+	case *CompositeType:
+		err := e.encoder.EncodeTagHead(cborTagCompositeType)
+		if err != nil {
+			return err
+		}
+		return e.encodeCompositeType(ty)
+
+	case *InterfaceType:
+		err := e.encoder.EncodeTagHead(cborTagInterfaceType)
+		if err != nil {
+			return err
+		}
+		return e.encodeInterfaceType(ty)
+
+	case *TransactionType:
+		err := e.encoder.EncodeTagHead(cborTagTransactionType)
+		if err != nil {
+			return err
+		}
+		return e.encodeTransactionType(ty)
+	}
+
+	return errors.NewUnexpectedError("cannot encode type %s", ty)
+}
+
+func (e *elaborationEncoder) encodeBuiltinType(ty Type) error {
+	typeID := ty.ID()
+
+	if _, ok := builtinEncodingTypes()[typeID]; !ok {
+		return errors.NewUnexpectedError("cannot encode type %s", ty)
+	}
+
+	err := e.encoder.EncodeTagHead(cborTagBuiltinType)
+	if err != nil {
+		return err
+	}
+	return e.encoder.EncodeString(string(typeID))
+}
+
+func (e *elaborationEncoder) encodeImportedType(tag uint64, location common.Location, typeID TypeID) error {
+	err := e.encoder.EncodeTagHead(tag)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeArrayHead(2)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&location)
+	if err != nil {
+		return err
+	}
+
+	return e.encoder.EncodeString(string(typeID))
+}
+
+func (e *elaborationEncoder) encodeInterfaceTypes(types []*InterfaceType) error {
+	if types == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(len(types)))
+	if err != nil {
+		return err
+	}
+
+	for _, ty := range types {
+		err = e.encodeType(ty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeCompositeTypes(types []*CompositeType) error {
+	if types == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(len(types)))
+	if err != nil {
+		return err
+	}
+
+	for _, ty := range types {
+		err = e.encodeType(ty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeInstantiatedCompositeType(ty *CompositeType) error {
+	err := e.encoder.EncodeTagHead(cborTagInstantiatedCompositeType)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeArrayHead(2)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(ty.genericType)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeTypes(ty.typeArguments)
+}
+
+func (e *elaborationEncoder) encodeTypeParameter(typeParameter *TypeParameter) error {
+	if typeParameter == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	if index, ok := e.typeParameterIndices[typeParameter]; ok {
+		err := e.encoder.EncodeTagHead(cborTagTypeParameterReference)
+		if err != nil {
+			return err
+		}
+		return e.encoder.EncodeUint64(index)
+	}
+
+	e.typeParameterIndices[typeParameter] = uint64(len(e.typeParameterIndices))
+
+	err := e.encoder.EncodeTagHead(cborTagTypeParameter)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeArrayHead(4)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeString(typeParameter.Name)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(typeParameter.TypeBound)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(typeParameter.Optional)
+	if err != nil {
+		return err
+	}
+
+	return e.encoder.EncodeBool(typeParameter.UserDefined)
+}
+
+func (e *elaborationEncoder) encodeTypeParameters(typeParameters []*TypeParameter) error {
+	if typeParameters == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(len(typeParameters)))
+	if err != nil {
+		return err
+	}
+
+	for _, typeParameter := range typeParameters {
+		err = e.encodeTypeParameter(typeParameter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *elaborationEncoder) encodeTypeAnnotation(typeAnnotation *TypeAnnotation) error {
+	if typeAnnotation == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(2)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(typeAnnotation.IsResource)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeType(typeAnnotation.Type)
+}
+
+func (e *elaborationEncoder) encodeParameters(parameters []*Parameter) error {
+	if parameters == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(len(parameters)))
+	if err != nil {
+		return err
+	}
+
+	for _, parameter := range parameters {
+		err = e.encoder.EncodeArrayHead(3)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeString(parameter.Label)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeString(parameter.Identifier)
+		if err != nil {
+			return err
+		}
+
+		err = e.encodeTypeAnnotation(parameter.TypeAnnotation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeFunctionType encodes the given function type.
+//
+// The argument expressions check of built-in functions is not encoded,
+// it is only needed when checking invocations.
+//
+func (e *elaborationEncoder) encodeFunctionType(ty *FunctionType) error {
+	err := e.encoder.EncodeArrayHead(6)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(ty.IsConstructor)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeTypeParameters(ty.TypeParameters)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeParameters(ty.Parameters)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeTypeAnnotation(ty.ReturnTypeAnnotation)
+	if err != nil {
+		return err
+	}
+
+	if ty.RequiredArgumentCount != nil {
+		err = e.encoder.EncodeInt64(int64(*ty.RequiredArgumentCount))
+	} else {
+		err = e.encoder.EncodeNil()
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.encodeMembers(ty.Members)
+}
+
+func (e *elaborationEncoder) encodeCompositeType(ty *CompositeType) error {
+	err := e.encoder.EncodeArrayHead(15)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&ty.Location)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeString(ty.Identifier)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(ty.Kind))
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeInterfaceTypes(ty.ExplicitInterfaceConformances)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeCompositeTypes(ty.ImplicitTypeRequirementConformances)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeMembers(ty.Members)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeParameters(ty.ConstructorParameters)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeNestedTypes(ty.nestedTypes)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeNestedTypes(ty.typeAliases)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(ty.containerType)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(ty.EnumRawType)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(ty.hasComputedMembers)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(ty.importable)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeTypeParameters(ty.typeParameters)
+}
+
+func (e *elaborationEncoder) encodeInterfaceType(ty *InterfaceType) error {
+	err := e.encoder.EncodeArrayHead(8)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&ty.Location)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeString(ty.Identifier)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(ty.CompositeKind))
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeMembers(ty.Members)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeParameters(ty.InitializerParameters)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(ty.containerType)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeNestedTypes(ty.nestedTypes)
+}
+
+func (e *elaborationEncoder) encodeTransactionType(ty *TransactionType) error {
+	err := e.encoder.EncodeArrayHead(4)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeMembers(ty.Members)
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&ty.Fields)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeParameters(ty.PrepareParameters)
+	if err != nil {
+		return err
+	}
+
+	return e.encodeParameters(ty.Parameters)
+}
+
+func (e *elaborationEncoder) encodeNestedTypes(nestedTypes *StringTypeOrderedMap) error {
+	if nestedTypes == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(nestedTypes.Len()))
+	if err != nil {
+		return err
+	}
+
+	return nestedTypes.ForeachWithError(func(identifier string, nestedType Type) error {
+		err := e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeString(identifier)
+		if err != nil {
+			return err
+		}
+
+		return e.encodeType(nestedType)
+	})
+}
+
+func (e *elaborationEncoder) encodeMembers(members *StringMemberOrderedMap) error {
+	if members == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	err := e.encoder.EncodeArrayHead(uint64(members.Len()))
+	if err != nil {
+		return err
+	}
+
+	return members.ForeachWithError(func(identifier string, member *Member) error {
+		err := e.encoder.EncodeArrayHead(2)
+		if err != nil {
+			return err
+		}
+
+		err = e.encoder.EncodeString(identifier)
+		if err != nil {
+			return err
+		}
+
+		return e.encodeMember(member)
+	})
+}
+
+// encodeMemberOrReference encodes the given member as a reference,
+// if it is a member of a composite, interface, or transaction type declared in the program,
+// as the member is then encoded as part of the type.
+// Otherwise, e.g. for members of built-in types, the member is encoded completely.
+//
+func (e *elaborationEncoder) encodeMemberOrReference(member *Member) error {
+	if member == nil {
+		return e.encoder.EncodeNil()
+	}
+
+	var members *StringMemberOrderedMap
+
+	switch containerType := member.ContainerType.(type) {
+	case *CompositeType:
+		// The members of instantiations of generic composite types
+		// are only determined on first use, so they are always encoded
+		if e.isDeclaredInProgram(containerType.Location) &&
+			containerType.genericType == nil {
+
+			members = containerType.Members
+		}
+	case *InterfaceType:
+		if e.isDeclaredInProgram(containerType.Location) {
+			members = containerType.Members
+		}
+	case *TransactionType:
+		members = containerType.Members
+	}
+
+	if members != nil {
+		identifier := member.Identifier.Identifier
+		declaredMember, ok := members.Get(identifier)
+		if ok && declaredMember == member {
+			err := e.encoder.EncodeTagHead(cborTagMemberReference)
+			if err != nil {
+				return err
+			}
+
+			err = e.encoder.EncodeArrayHead(2)
+			if err != nil {
+				return err
+			}
+
+			err = e.encodeType(member.ContainerType)
+			if err != nil {
+				return err
+			}
+
+			return e.encoder.EncodeString(identifier)
+		}
+	}
+
+	return e.encodeMember(member)
+}
+
+func (e *elaborationEncoder) encodeMember(member *Member) error {
+	err := e.encoder.EncodeArrayHead(10)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeType(member.ContainerType)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(member.Access))
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&member.Identifier)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeTypeAnnotation(member.TypeAnnotation)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(member.DeclarationKind))
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeUint64(uint64(member.VariableKind))
+	if err != nil {
+		return err
+	}
+
+	err = e.elementEncoder.Encode(&member.ArgumentLabels)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(member.Predeclared)
+	if err != nil {
+		return err
+	}
+
+	err = e.encoder.EncodeBool(member.IgnoreInSerialization)
+	if err != nil {
+		return err
+	}
+
+	return e.encoder.EncodeString(member.DocString)
+}
@@ -3578,6 +3578,57 @@ func (e *UnparameterizedTypeInstantiationError) SecondaryError() string {
 	)
 }
 
+// InvalidTypeParametersError
+
+type InvalidTypeParametersError struct {
+	DeclarationKind common.DeclarationKind
+	ast.Range
+}
+
+var _ SemanticError = &InvalidTypeParametersError{}
+var _ errors.UserError = &InvalidTypeParametersError{}
+
+func (*InvalidTypeParametersError) isSemanticError() {}
+
+func (*InvalidTypeParametersError) IsUserError() {}
+
+func (e *InvalidTypeParametersError) Error() string {
+	return fmt.Sprintf(
+		"%s declarations cannot have type parameters",
+		e.DeclarationKind.Name(),
+	)
+}
+
+// MissingTypeArgumentsError
+
+type MissingTypeArgumentsError struct {
+	Type               Type
+	TypeParameterCount int
+	ast.Range
+}
+
+var _ SemanticError = &MissingTypeArgumentsError{}
+var _ errors.UserError = &MissingTypeArgumentsError{}
+var _ errors.SecondaryError = &MissingTypeArgumentsError{}
+
+func (*MissingTypeArgumentsError) isSemanticError() {}
+
+func (*MissingTypeArgumentsError) IsUserError() {}
+
+func (e *MissingTypeArgumentsError) Error() string {
+	return fmt.Sprintf(
+		"missing type arguments for generic type `%s`",
+		e.Type.QualifiedString(),
+	)
+}
+
+func (e *MissingTypeArgumentsError) SecondaryError() string {
+	return fmt.Sprintf(
+		"expected %d type arguments",
+		e.TypeParameterCount,
+	)
+}
+
 // TypeAnnotationRequiredError
 
 type TypeAnnotationRequiredError struct {
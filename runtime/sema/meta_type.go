@@ -31,6 +31,21 @@ const metaTypeSubtypeDocString = `
 Returns true if this type is a subtype of the given type at run-time
 `
 
+const metaTypeFieldsDocString = `
+The public fields of the type, mapping each field name to the type of the field.
+Empty if the type is not a composite or interface type
+`
+
+const metaTypeFunctionsDocString = `
+The public functions of the type, mapping each function name to the signature of the function.
+Empty if the type is not a composite or interface type
+`
+
+const metaTypeConformancesDocString = `
+The interfaces the type explicitly declares conformance to.
+Empty if the type is not a composite type
+`
+
 const MetaTypeName = "Type"
 
 // MetaType represents the type of a type.
@@ -61,6 +76,30 @@ var MetaTypeIsSubtypeFunctionType = &FunctionType{
 	),
 }
 
+// MetaTypeFieldsType is the type of the reflection member `fields` of a type,
+// mapping field names to field types.
+//
+var MetaTypeFieldsType = &DictionaryType{
+	KeyType:   StringType,
+	ValueType: MetaType,
+}
+
+// MetaTypeFunctionsType is the type of the reflection member `functions` of a type,
+// mapping function names to function signatures.
+//
+// Function types are not storable, so the signatures are provided as strings.
+//
+var MetaTypeFunctionsType = &DictionaryType{
+	KeyType:   StringType,
+	ValueType: StringType,
+}
+
+// MetaTypeConformancesType is the type of the reflection member `conformances` of a type.
+//
+var MetaTypeConformancesType = &VariableSizedType{
+	Type: MetaType,
+}
+
 func init() {
 	MetaType.Members = func(t *SimpleType) map[string]MemberResolver {
 		return map[string]MemberResolver{
@@ -88,6 +127,42 @@ func init() {
 					)
 				},
 			},
+			"fields": {
+				Kind: common.DeclarationKindField,
+				Resolve: func(memoryGauge common.MemoryGauge, identifier string, _ ast.Range, _ func(error)) *Member {
+					return NewPublicConstantFieldMember(
+						memoryGauge,
+						t,
+						identifier,
+						MetaTypeFieldsType,
+						metaTypeFieldsDocString,
+					)
+				},
+			},
+			"functions": {
+				Kind: common.DeclarationKindField,
+				Resolve: func(memoryGauge common.MemoryGauge, identifier string, _ ast.Range, _ func(error)) *Member {
+					return NewPublicConstantFieldMember(
+						memoryGauge,
+						t,
+						identifier,
+						MetaTypeFunctionsType,
+						metaTypeFunctionsDocString,
+					)
+				},
+			},
+			"conformances": {
+				Kind: common.DeclarationKindField,
+				Resolve: func(memoryGauge common.MemoryGauge, identifier string, _ ast.Range, _ func(error)) *Member {
+					return NewPublicConstantFieldMember(
+						memoryGauge,
+						t,
+						identifier,
+						MetaTypeConformancesType,
+						metaTypeConformancesDocString,
+					)
+				},
+			},
 		}
 	}
 }
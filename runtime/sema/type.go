@@ -221,6 +221,25 @@ func VisitThisAndNested(t Type, visit func(ty Type)) {
 	})
 }
 
+// nestedTypeOrTypeAlias returns the type nested in the given container type
+// which has the given identifier, or the type aliased by the nested type alias
+// which has the given identifier. Returns nil if neither exists.
+//
+func nestedTypeOrTypeAlias(containerType ContainerType, identifier string) Type {
+	nestedType, ok := containerType.GetNestedTypes().Get(identifier)
+	if ok {
+		return nestedType
+	}
+
+	compositeType, ok := containerType.(*CompositeType)
+	if !ok || compositeType.typeAliases == nil {
+		return nil
+	}
+
+	aliasedType, _ := compositeType.typeAliases.Get(identifier)
+	return aliasedType
+}
+
 // CompositeKindedType is a type which has a composite kind
 //
 type CompositeKindedType interface {
@@ -680,28 +699,38 @@ func (t *GenericType) Equal(other Type) bool {
 	return t.TypeParameter == otherType.TypeParameter
 }
 
-func (*GenericType) IsResourceType() bool {
-	return false
+// NOTE: The generic type of a user-defined type parameter
+// has the properties of its type bound, e.g. it is a resource type
+// if the type bound is a resource type.
+// The generic types of built-in generic functions only occur in signatures.
+
+func (t *GenericType) IsResourceType() bool {
+	return t.TypeParameter.isUserDefined() &&
+		t.TypeParameter.TypeBound.IsResourceType()
 }
 
 func (*GenericType) IsInvalidType() bool {
 	return false
 }
 
-func (*GenericType) IsStorable(_ map[*Member]bool) bool {
-	return false
+func (t *GenericType) IsStorable(results map[*Member]bool) bool {
+	return t.TypeParameter.isUserDefined() &&
+		t.TypeParameter.TypeBound.IsStorable(results)
 }
 
-func (*GenericType) IsExternallyReturnable(_ map[*Member]bool) bool {
-	return false
+func (t *GenericType) IsExternallyReturnable(results map[*Member]bool) bool {
+	return t.TypeParameter.isUserDefined() &&
+		t.TypeParameter.TypeBound.IsExternallyReturnable(results)
 }
 
-func (t *GenericType) IsImportable(_ map[*Member]bool) bool {
-	return false
+func (t *GenericType) IsImportable(results map[*Member]bool) bool {
+	return t.TypeParameter.isUserDefined() &&
+		t.TypeParameter.TypeBound.IsImportable(results)
 }
 
-func (*GenericType) IsEquatable() bool {
-	return false
+func (t *GenericType) IsEquatable() bool {
+	return t.TypeParameter.isUserDefined() &&
+		t.TypeParameter.TypeBound.IsEquatable()
 }
 
 func (*GenericType) TypeAnnotationState() TypeAnnotationState {
@@ -756,12 +785,21 @@ func (t *GenericType) Unify(
 func (t *GenericType) Resolve(typeArguments *TypeParameterTypeOrderedMap) Type {
 	ty, ok := typeArguments.Get(t.TypeParameter)
 	if !ok {
+		// User-defined type parameters which are not bound,
+		// e.g. the type parameters of an enclosing generic function or composite type,
+		// resolve to themselves
+		if t.TypeParameter.UserDefined {
+			return t
+		}
 		return nil
 	}
 	return ty
 }
 
 func (t *GenericType) GetMembers() map[string]MemberResolver {
+	if t.TypeParameter.isUserDefined() {
+		return t.TypeParameter.TypeBound.GetMembers()
+	}
 	return withBuiltinMembers(t, nil)
 }
 
@@ -1620,6 +1658,33 @@ It does not modify the original array.
 If either of the parameters are out of the bounds of the array, or the indices are invalid (` + "`from > upTo`" + `), then the function will fail.
 `
 
+const arrayTypeFilterFunctionDocString = `
+Returns a new variable-sized array containing the elements of the array for which the given function returns true, in the original order.
+
+Available if the array element type is not resource-kinded
+`
+
+const arrayTypeMapFunctionDocString = `
+Returns a new array containing the results of calling the given function on each element of the array.
+
+The returned array has the same kind and length as the original array.
+Available if the array element type is not resource-kinded
+`
+
+const arrayTypeReverseFunctionDocString = `
+Returns a new array containing the elements of the array in reverse order.
+It does not modify the original array.
+
+Available if the array element type is not resource-kinded
+`
+
+const arrayTypeSortFunctionDocString = `
+Sorts the array in place, using the given function to determine whether the first given element must be ordered before the second given element.
+
+The sort is stable, i.e. elements which are not ordered before one another by the given function keep their original order.
+Available if the array element type is not resource-kinded
+`
+
 func getArrayMembers(arrayType ArrayType) map[string]MemberResolver {
 
 	members := map[string]MemberResolver{
@@ -1713,6 +1778,110 @@ func getArrayMembers(arrayType ArrayType) map[string]MemberResolver {
 				)
 			},
 		},
+		"filter": {
+			Kind: common.DeclarationKindFunction,
+			Resolve: func(memoryGauge common.MemoryGauge, identifier string, targetRange ast.Range, report func(error)) *Member {
+
+				elementType := arrayType.ElementType(false)
+
+				// The elements are passed to the given function,
+				// so an array of resources cannot be filtered
+
+				if elementType.IsResourceType() {
+					report(
+						&InvalidResourceArrayMemberError{
+							Name:            identifier,
+							DeclarationKind: common.DeclarationKindFunction,
+							Range:           targetRange,
+						},
+					)
+				}
+
+				return NewPublicFunctionMember(
+					memoryGauge,
+					arrayType,
+					identifier,
+					ArrayFilterFunctionType(elementType),
+					arrayTypeFilterFunctionDocString,
+				)
+			},
+		},
+		"map": {
+			Kind: common.DeclarationKindFunction,
+			Resolve: func(memoryGauge common.MemoryGauge, identifier string, targetRange ast.Range, report func(error)) *Member {
+
+				elementType := arrayType.ElementType(false)
+
+				if elementType.IsResourceType() {
+					report(
+						&InvalidResourceArrayMemberError{
+							Name:            identifier,
+							DeclarationKind: common.DeclarationKindFunction,
+							Range:           targetRange,
+						},
+					)
+				}
+
+				return NewPublicFunctionMember(
+					memoryGauge,
+					arrayType,
+					identifier,
+					ArrayMapFunctionType(arrayType),
+					arrayTypeMapFunctionDocString,
+				)
+			},
+		},
+		"reverse": {
+			Kind: common.DeclarationKindFunction,
+			Resolve: func(memoryGauge common.MemoryGauge, identifier string, targetRange ast.Range, report func(error)) *Member {
+
+				elementType := arrayType.ElementType(false)
+
+				if elementType.IsResourceType() {
+					report(
+						&InvalidResourceArrayMemberError{
+							Name:            identifier,
+							DeclarationKind: common.DeclarationKindFunction,
+							Range:           targetRange,
+						},
+					)
+				}
+
+				return NewPublicFunctionMember(
+					memoryGauge,
+					arrayType,
+					identifier,
+					ArrayReverseFunctionType(arrayType),
+					arrayTypeReverseFunctionDocString,
+				)
+			},
+		},
+		"sort": {
+			Kind:     common.DeclarationKindFunction,
+			Mutating: true,
+			Resolve: func(memoryGauge common.MemoryGauge, identifier string, targetRange ast.Range, report func(error)) *Member {
+
+				elementType := arrayType.ElementType(false)
+
+				if elementType.IsResourceType() {
+					report(
+						&InvalidResourceArrayMemberError{
+							Name:            identifier,
+							DeclarationKind: common.DeclarationKindFunction,
+							Range:           targetRange,
+						},
+					)
+				}
+
+				return NewPublicFunctionMember(
+					memoryGauge,
+					arrayType,
+					identifier,
+					ArraySortFunctionType(elementType),
+					arrayTypeSortFunctionDocString,
+				)
+			},
+		},
 	}
 
 	// TODO: maybe still return members but report a helpful error?
@@ -2025,6 +2194,124 @@ func ArraySliceFunctionType(elementType Type) *FunctionType {
 	}
 }
 
+func ArrayFilterFunctionType(elementType Type) *FunctionType {
+	return &FunctionType{
+		Parameters: []*Parameter{
+			{
+				Label:      ArgumentLabelNotRequired,
+				Identifier: "f",
+				TypeAnnotation: NewTypeAnnotation(
+					&FunctionType{
+						Parameters: []*Parameter{
+							{
+								Label:          ArgumentLabelNotRequired,
+								Identifier:     "element",
+								TypeAnnotation: NewTypeAnnotation(elementType),
+							},
+						},
+						ReturnTypeAnnotation: NewTypeAnnotation(BoolType),
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(
+			&VariableSizedType{
+				Type: elementType,
+			},
+		),
+	}
+}
+
+// ArrayMapFunctionType returns the type of the `map` function of the given array type.
+// The function has a type parameter for the element type of the result,
+// which has the same kind and size as the given array type.
+//
+func ArrayMapFunctionType(arrayType ArrayType) *FunctionType {
+
+	typeParameter := &TypeParameter{
+		Name: "U",
+	}
+
+	resultElementType := &GenericType{
+		TypeParameter: typeParameter,
+	}
+
+	var returnType Type
+	switch arrayType := arrayType.(type) {
+	case *VariableSizedType:
+		returnType = &VariableSizedType{
+			Type: resultElementType,
+		}
+	case *ConstantSizedType:
+		returnType = &ConstantSizedType{
+			Type: resultElementType,
+			Size: arrayType.Size,
+		}
+	default:
+		panic(errors.NewUnreachableError())
+	}
+
+	return &FunctionType{
+		TypeParameters: []*TypeParameter{
+			typeParameter,
+		},
+		Parameters: []*Parameter{
+			{
+				Label:      ArgumentLabelNotRequired,
+				Identifier: "transform",
+				TypeAnnotation: NewTypeAnnotation(
+					&FunctionType{
+						Parameters: []*Parameter{
+							{
+								Label:          ArgumentLabelNotRequired,
+								Identifier:     "element",
+								TypeAnnotation: NewTypeAnnotation(arrayType.ElementType(false)),
+							},
+						},
+						ReturnTypeAnnotation: NewTypeAnnotation(resultElementType),
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(returnType),
+	}
+}
+
+func ArrayReverseFunctionType(arrayType ArrayType) *FunctionType {
+	return &FunctionType{
+		ReturnTypeAnnotation: NewTypeAnnotation(arrayType),
+	}
+}
+
+func ArraySortFunctionType(elementType Type) *FunctionType {
+	return &FunctionType{
+		Parameters: []*Parameter{
+			{
+				Label:      "by",
+				Identifier: "isBefore",
+				TypeAnnotation: NewTypeAnnotation(
+					&FunctionType{
+						Parameters: []*Parameter{
+							{
+								Label:          ArgumentLabelNotRequired,
+								Identifier:     "a",
+								TypeAnnotation: NewTypeAnnotation(elementType),
+							},
+							{
+								Label:          ArgumentLabelNotRequired,
+								Identifier:     "b",
+								TypeAnnotation: NewTypeAnnotation(elementType),
+							},
+						},
+						ReturnTypeAnnotation: NewTypeAnnotation(BoolType),
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(VoidType),
+	}
+}
+
 // VariableSizedType is a variable sized array type
 type VariableSizedType struct {
 	Type                Type
@@ -2373,6 +2660,14 @@ type TypeParameter struct {
 	Name      string
 	TypeBound Type
 	Optional  bool
+	// UserDefined is true if the type parameter is declared
+	// by a generic function or composite type in a program,
+	// instead of by a built-in generic function
+	UserDefined bool
+}
+
+func (p *TypeParameter) isUserDefined() bool {
+	return p.UserDefined && p.TypeBound != nil
 }
 
 func (p TypeParameter) string(typeFormatter func(Type) string) string {
@@ -2875,7 +3170,30 @@ func (t *FunctionType) Unify(
 
 func (t *FunctionType) Resolve(typeArguments *TypeParameterTypeOrderedMap) Type {
 
-	// TODO: type parameters ?
+	// type parameters:
+	// The function's own type parameters are not bound by the given type arguments,
+	// e.g. when resolving the type of a generic function of a generic composite type
+
+	if len(t.TypeParameters) > 0 {
+		outerTypeArguments := typeArguments
+		typeArguments = &TypeParameterTypeOrderedMap{}
+
+		outerTypeArguments.Foreach(func(typeParameter *TypeParameter, ty Type) {
+			typeArguments.Set(typeParameter, ty)
+		})
+
+		for _, typeParameter := range t.TypeParameters {
+			if _, ok := typeArguments.Get(typeParameter); ok {
+				continue
+			}
+			typeArguments.Set(
+				typeParameter,
+				&GenericType{
+					TypeParameter: typeParameter,
+				},
+			)
+		}
+	}
 
 	// parameters
 
@@ -2904,6 +3222,7 @@ func (t *FunctionType) Resolve(typeArguments *TypeParameterTypeOrderedMap) Type
 	}
 
 	return &FunctionType{
+		TypeParameters:        t.TypeParameters,
 		Parameters:            newParameters,
 		ReturnTypeAnnotation:  NewTypeAnnotation(newReturnType),
 		RequiredArgumentCount: t.RequiredArgumentCount,
@@ -3332,6 +3651,13 @@ func init() {
 		StringTypeEncodeHexFunctionDocString,
 	))
 
+	addMember(NewUnmeteredPublicFunctionMember(
+		functionType,
+		StringTypeJoinFunctionName,
+		StringTypeJoinFunctionType,
+		StringTypeJoinFunctionDocString,
+	))
+
 	BaseValueActivation.Set(
 		typeName,
 		baseFunctionVariable(
@@ -3462,6 +3788,7 @@ type CompositeType struct {
 	// TODO: add support for overloaded initializers
 	ConstructorParameters []*Parameter
 	nestedTypes           *StringTypeOrderedMap
+	typeAliases           *StringTypeOrderedMap
 	containerType         Type
 	EnumRawType           Type
 	hasComputedMembers    bool
@@ -3469,6 +3796,16 @@ type CompositeType struct {
 	// Only applicable for native composite types.
 	importable bool
 
+	// typeParameters are the type parameters of a generic composite type
+	typeParameters      []*TypeParameter
+	instantiations      map[TypeID]*CompositeType
+	instantiationsMutex sync.Mutex
+	// genericType and typeArguments are only set for an instantiation
+	// of a generic composite type, see `Instantiate`
+	genericType             *CompositeType
+	typeArguments           []Type
+	instantiatedMembersOnce sync.Once
+
 	cachedIdentifiers *struct {
 		TypeID              TypeID
 		QualifiedIdentifier string
@@ -3505,11 +3842,45 @@ func (t *CompositeType) addImplicitTypeRequirementConformance(typeRequirement *C
 func (*CompositeType) IsType() {}
 
 func (t *CompositeType) String() string {
-	return t.Identifier
+	return formatCompositeTypeInstantiation(
+		t.Identifier,
+		t.typeArguments,
+		func(ty Type) string {
+			return ty.String()
+		},
+	)
 }
 
 func (t *CompositeType) QualifiedString() string {
-	return t.QualifiedIdentifier()
+	return formatCompositeTypeInstantiation(
+		t.QualifiedIdentifier(),
+		t.typeArguments,
+		func(ty Type) string {
+			return ty.QualifiedString()
+		},
+	)
+}
+
+func formatCompositeTypeInstantiation(
+	identifier string,
+	typeArguments []Type,
+	typeFormatter func(Type) string,
+) string {
+	if len(typeArguments) == 0 {
+		return identifier
+	}
+
+	var builder strings.Builder
+	builder.WriteString(identifier)
+	builder.WriteRune('<')
+	for i, typeArgument := range typeArguments {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(typeFormatter(typeArgument))
+	}
+	builder.WriteRune('>')
+	return builder.String()
 }
 
 func (t *CompositeType) GetContainerType() Type {
@@ -3578,6 +3949,19 @@ func (t *CompositeType) initializeIdentifiers() {
 		typeID = t.Location.TypeID(nil, identifier)
 	}
 
+	// The type ID of an instantiated composite type includes the type IDs of the type arguments.
+	// The qualified identifier is the one of the generic composite type
+
+	if len(t.typeArguments) > 0 {
+		typeID = TypeID(formatCompositeTypeInstantiation(
+			string(typeID),
+			t.typeArguments,
+			func(ty Type) string {
+				return string(ty.ID())
+			},
+		))
+	}
+
 	t.cachedIdentifiers = &struct {
 		TypeID              TypeID
 		QualifiedIdentifier string
@@ -3598,6 +3982,7 @@ func (t *CompositeType) Equal(other Type) bool {
 }
 
 func (t *CompositeType) GetMembers() map[string]MemberResolver {
+	t.InitializeInstantiatedMembers()
 	t.initializeMemberResolvers()
 	return t.memberResolvers
 }
@@ -3631,6 +4016,8 @@ func (t *CompositeType) IsStorable(results map[*Member]bool) bool {
 		return false
 	}
 
+	t.InitializeInstantiatedMembers()
+
 	// If this composite type has a member which is non-storable,
 	// then the composite type is not storable.
 
@@ -3659,6 +4046,8 @@ func (t *CompositeType) IsImportable(results map[*Member]bool) bool {
 		return false
 	}
 
+	t.InitializeInstantiatedMembers()
+
 	// If this composite type has a member which is not importable,
 	// then the composite type is not importable.
 
@@ -3683,6 +4072,8 @@ func (t *CompositeType) IsExternallyReturnable(results map[*Member]bool) bool {
 		return false
 	}
 
+	t.InitializeInstantiatedMembers()
+
 	// If this composite type has a member which is not externally returnable,
 	// then the composite type is not externally returnable.
 
@@ -3709,6 +4100,8 @@ func (t *CompositeType) RewriteWithRestrictedTypes() (result Type, rewritten boo
 }
 
 func (t *CompositeType) InterfaceType() *InterfaceType {
+	t.InitializeInstantiatedMembers()
+
 	return &InterfaceType{
 		Location:              t.Location,
 		Identifier:            t.Identifier,
@@ -3744,13 +4137,245 @@ func (t *CompositeType) TypeRequirements() []*CompositeType {
 	return typeRequirements
 }
 
-func (*CompositeType) Unify(_ Type, _ *TypeParameterTypeOrderedMap, _ func(err error), _ ast.Range) bool {
-	// TODO:
-	return false
+func (t *CompositeType) Unify(
+	other Type,
+	typeParameters *TypeParameterTypeOrderedMap,
+	report func(err error),
+	outerRange ast.Range,
+) bool {
+
+	// Only instantiations of the same generic composite type can be unified,
+	// by unifying their type arguments
+
+	genericType := t.genericCompositeType()
+	if genericType == nil {
+		return false
+	}
+
+	otherComposite, ok := other.(*CompositeType)
+	if !ok || otherComposite.genericCompositeType() != genericType {
+		return false
+	}
+
+	otherTypeArguments := otherComposite.compositeTypeArguments()
+
+	result := false
+
+	for i, typeArgument := range t.compositeTypeArguments() {
+		if typeArgument.Unify(otherTypeArguments[i], typeParameters, report, outerRange) {
+			result = true
+		}
+	}
+
+	return result
 }
 
-func (t *CompositeType) Resolve(_ *TypeParameterTypeOrderedMap) Type {
-	return t
+func (t *CompositeType) Resolve(typeArguments *TypeParameterTypeOrderedMap) Type {
+	genericType := t.genericCompositeType()
+	if genericType == nil {
+		return t
+	}
+
+	compositeTypeArguments := t.compositeTypeArguments()
+	resolvedTypeArguments := make([]Type, len(compositeTypeArguments))
+
+	for i, typeArgument := range compositeTypeArguments {
+		resolvedTypeArgument := typeArgument.Resolve(typeArguments)
+		if resolvedTypeArgument == nil {
+			return nil
+		}
+		resolvedTypeArguments[i] = resolvedTypeArgument
+	}
+
+	return genericType.Instantiate(resolvedTypeArguments, nil)
+}
+
+// TypeParameters returns the type parameters of a generic composite type.
+// Instantiations of a generic composite type have no type parameters.
+//
+func (t *CompositeType) TypeParameters() []*TypeParameter {
+	return t.typeParameters
+}
+
+// Instantiate returns the instantiation of the generic composite type
+// with the given type arguments.
+//
+// The instantiation with the generic types of the type parameters themselves,
+// e.g. `S<T>` in the declaration of `S<T>`, is the generic composite type.
+//
+func (t *CompositeType) Instantiate(typeArguments []Type, _ func(err error)) Type {
+	if len(typeArguments) != len(t.typeParameters) {
+		return InvalidType
+	}
+
+	isGenericType := true
+	for i, typeArgument := range typeArguments {
+		genericType, ok := typeArgument.(*GenericType)
+		if !ok || genericType.TypeParameter != t.typeParameters[i] {
+			isGenericType = false
+			break
+		}
+	}
+	if isGenericType {
+		return t
+	}
+
+	instantiation := &CompositeType{
+		genericType:   t,
+		typeArguments: typeArguments,
+	}
+	instantiation.initializeInstantiation()
+
+	// Instantiations are cached, so that instantiations
+	// with the same type arguments have the same members
+
+	return t.cachedInstantiation(instantiation)
+}
+
+// initializeInstantiation initializes an instantiated composite type
+// from the declaration of its generic composite type.
+//
+func (t *CompositeType) initializeInstantiation() {
+	genericType := t.genericType
+
+	t.Location = genericType.Location
+	t.Identifier = genericType.Identifier
+	t.Kind = genericType.Kind
+	t.ExplicitInterfaceConformances = genericType.ExplicitInterfaceConformances
+	t.ImplicitTypeRequirementConformances = genericType.ImplicitTypeRequirementConformances
+	t.nestedTypes = genericType.nestedTypes
+	t.typeAliases = genericType.typeAliases
+	t.containerType = genericType.containerType
+}
+
+func (t *CompositeType) cachedInstantiation(instantiation *CompositeType) *CompositeType {
+	typeID := instantiation.ID()
+
+	t.instantiationsMutex.Lock()
+	defer t.instantiationsMutex.Unlock()
+
+	if existing, ok := t.instantiations[typeID]; ok {
+		return existing
+	}
+
+	if t.instantiations == nil {
+		t.instantiations = map[TypeID]*CompositeType{}
+	}
+	t.instantiations[typeID] = instantiation
+
+	return instantiation
+}
+
+// BaseType returns the generic composite type of an instantiated composite type,
+// or nil if the composite type is not instantiated.
+//
+func (t *CompositeType) BaseType() Type {
+	if t.genericType == nil {
+		return nil
+	}
+	return t.genericType
+}
+
+// TypeArguments returns the type arguments of an instantiated composite type.
+//
+func (t *CompositeType) TypeArguments() []Type {
+	return t.typeArguments
+}
+
+// IsGeneric returns true if the composite type has type parameters,
+// i.e. if it must be instantiated with type arguments.
+//
+func (t *CompositeType) IsGeneric() bool {
+	return len(t.typeParameters) > 0
+}
+
+// genericCompositeType returns the generic composite type
+// of a generic or an instantiated composite type, if any.
+//
+func (t *CompositeType) genericCompositeType() *CompositeType {
+	if t.genericType != nil {
+		return t.genericType
+	}
+	if len(t.typeParameters) > 0 {
+		return t
+	}
+	return nil
+}
+
+// compositeTypeArguments returns the type arguments of an instantiated composite type,
+// or the generic types of the type parameters of a generic composite type.
+//
+func (t *CompositeType) compositeTypeArguments() []Type {
+	if t.genericType != nil {
+		return t.typeArguments
+	}
+
+	typeArguments := make([]Type, len(t.typeParameters))
+	for i, typeParameter := range t.typeParameters {
+		typeArguments[i] = &GenericType{
+			TypeParameter: typeParameter,
+		}
+	}
+	return typeArguments
+}
+
+// InitializeInstantiatedMembers determines the members of an instantiated composite type,
+// by substituting the type arguments in the members of the generic composite type.
+//
+// The members are only determined on first use, as the generic composite type
+// might be instantiated before its members are declared.
+// It must be called before the members, fields, or constructor parameters
+// of an instantiation are accessed directly.
+//
+func (t *CompositeType) InitializeInstantiatedMembers() {
+	genericType := t.genericType
+	if genericType == nil {
+		return
+	}
+
+	t.instantiatedMembersOnce.Do(func() {
+		typeArguments := &TypeParameterTypeOrderedMap{}
+		for i, typeParameter := range genericType.typeParameters {
+			typeArguments.Set(typeParameter, t.typeArguments[i])
+		}
+
+		resolve := func(ty Type) Type {
+			resolvedType := ty.Resolve(typeArguments)
+			if resolvedType == nil {
+				return ty
+			}
+			return resolvedType
+		}
+
+		members := &StringMemberOrderedMap{}
+
+		genericType.Members.Foreach(func(name string, member *Member) {
+			instantiatedMember := *member
+			instantiatedMember.ContainerType = t
+			instantiatedMember.TypeAnnotation = &TypeAnnotation{
+				IsResource: member.TypeAnnotation.IsResource,
+				Type:       resolve(member.TypeAnnotation.Type),
+			}
+			members.Set(name, &instantiatedMember)
+		})
+
+		constructorParameters := make([]*Parameter, len(genericType.ConstructorParameters))
+		for i, parameter := range genericType.ConstructorParameters {
+			constructorParameters[i] = &Parameter{
+				Label:      parameter.Label,
+				Identifier: parameter.Identifier,
+				TypeAnnotation: &TypeAnnotation{
+					IsResource: parameter.TypeAnnotation.IsResource,
+					Type:       resolve(parameter.TypeAnnotation.Type),
+				},
+			}
+		}
+
+		t.Members = members
+		t.Fields = genericType.Fields
+		t.ConstructorParameters = constructorParameters
+		t.hasComputedMembers = genericType.hasComputedMembers
+	})
 }
 
 func (t *CompositeType) IsContainerType() bool {
@@ -4362,6 +4987,12 @@ Removes the value for the given key from the dictionary.
 Returns the value as an optional if the dictionary contained the key, or nil if the dictionary did not contain the key
 `
 
+const dictionaryTypeForEachKeyFunctionDocString = `
+Iterates over the keys of the dictionary, calling the given function with each key.
+
+Iteration stops early if the function returns false
+`
+
 func (t *DictionaryType) GetMembers() map[string]MemberResolver {
 	t.initializeMemberResolvers()
 	return t.memberResolvers
@@ -4470,10 +5101,56 @@ func (t *DictionaryType) initializeMemberResolvers() {
 					)
 				},
 			},
+			"forEachKey": {
+				Kind: common.DeclarationKindFunction,
+				Resolve: func(memoryGauge common.MemoryGauge, identifier string, targetRange ast.Range, report func(error)) *Member {
+					if t.KeyType.IsResourceType() {
+						report(
+							&InvalidResourceDictionaryMemberError{
+								Name:            identifier,
+								DeclarationKind: common.DeclarationKindFunction,
+								Range:           targetRange,
+							},
+						)
+					}
+
+					return NewPublicFunctionMember(
+						memoryGauge,
+						t,
+						identifier,
+						DictionaryForEachKeyFunctionType(t),
+						dictionaryTypeForEachKeyFunctionDocString,
+					)
+				},
+			},
 		})
 	})
 }
 
+func DictionaryForEachKeyFunctionType(t *DictionaryType) *FunctionType {
+	return &FunctionType{
+		Parameters: []*Parameter{
+			{
+				Label:      ArgumentLabelNotRequired,
+				Identifier: "f",
+				TypeAnnotation: NewTypeAnnotation(
+					&FunctionType{
+						Parameters: []*Parameter{
+							{
+								Label:          ArgumentLabelNotRequired,
+								Identifier:     "key",
+								TypeAnnotation: NewTypeAnnotation(t.KeyType),
+							},
+						},
+						ReturnTypeAnnotation: NewTypeAnnotation(BoolType),
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: NewTypeAnnotation(VoidType),
+	}
+}
+
 func DictionaryContainsKeyFunctionType(t *DictionaryType) *FunctionType {
 	return &FunctionType{
 		Parameters: []*Parameter{
@@ -4728,9 +5405,16 @@ func (*ReferenceType) Unify(_ Type, _ *TypeParameterTypeOrderedMap, _ func(err e
 	return false
 }
 
-func (t *ReferenceType) Resolve(_ *TypeParameterTypeOrderedMap) Type {
-	// TODO:
-	return t
+func (t *ReferenceType) Resolve(typeArguments *TypeParameterTypeOrderedMap) Type {
+	newInnerType := t.Type.Resolve(typeArguments)
+	if newInnerType == nil {
+		return nil
+	}
+
+	return &ReferenceType{
+		Authorized: t.Authorized,
+		Type:       newInnerType,
+	}
 }
 
 const AddressTypeName = "Address"
@@ -4874,7 +5558,20 @@ func IsSubType(subType Type, superType Type) bool {
 		return true
 	}
 
-	return checkSubTypeWithoutEquality(subType, superType)
+	if checkSubTypeWithoutEquality(subType, superType) {
+		return true
+	}
+
+	// The generic type of a user-defined type parameter
+	// is a subtype of all supertypes of its type bound
+
+	if genericType, ok := subType.(*GenericType); ok &&
+		genericType.TypeParameter.isUserDefined() {
+
+		return IsSubType(genericType.TypeParameter.TypeBound, superType)
+	}
+
+	return false
 }
 
 // IsSameTypeKind determines if the given subtype belongs to the
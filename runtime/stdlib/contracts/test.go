@@ -16,8 +16,11 @@
  * limitations under the License.
  */
 
-package ir
+package contracts
 
-type Local struct {
-	Type ValType
-}
+import (
+	_ "embed"
+)
+
+//go:embed test.cdc
+var TestContract string
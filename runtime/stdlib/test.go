@@ -0,0 +1,1018 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stdlib
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib/contracts"
+)
+
+// This is the Cadence standard library for writing tests.
+// It provides the Cadence constructs (structs, functions, etc.) that are needed to
+// write tests in Cadence.
+
+const testContractTypeName = "Test"
+
+const blockchainTypeName = "Blockchain"
+const blockchainBackendTypeName = "BlockchainBackend"
+const emulatorBackendTypeName = "EmulatorBackend"
+const matcherTypeName = "Matcher"
+const resultStatusTypeName = "ResultStatus"
+const scriptResultTypeName = "ScriptResult"
+const transactionResultTypeName = "TransactionResult"
+const accountTypeName = "Account"
+const errorTypeName = "Error"
+
+const resultStatusSucceededRawValue = 0
+const resultStatusFailedRawValue = 1
+
+const matcherTestFieldName = "test"
+
+const TestContractLocation = common.IdentifierLocation(testContractTypeName)
+
+// TestFramework is the host-side counterpart of the natively implemented
+// functionality of the `Test` contract.
+//
+type TestFramework interface {
+	// NewEmulatorBackend returns a new, empty emulated blockchain.
+	NewEmulatorBackend() EmulatorBackend
+
+	// ReadFile returns the content of the file at the given path.
+	ReadFile(path string) (string, error)
+}
+
+// EmulatorBackend is the host-side implementation of the `Test.BlockchainBackend` interface.
+//
+type EmulatorBackend interface {
+	// RunScript executes the given script with the given arguments.
+	RunScript(inter *interpreter.Interpreter, code string, arguments []interpreter.Value) *ScriptResult
+
+	// CreateAccount creates a new account.
+	CreateAccount() (*Account, error)
+
+	// RunTransaction executes the given transaction,
+	// signed and authorized by the given accounts, with the given arguments.
+	RunTransaction(
+		inter *interpreter.Interpreter,
+		code string,
+		signers []*Account,
+		arguments []interpreter.Value,
+	) error
+
+	// DeployContract deploys the given contract code to the given account,
+	// and initializes the contract with the given arguments.
+	DeployContract(
+		inter *interpreter.Interpreter,
+		name string,
+		code string,
+		account *Account,
+		arguments []interpreter.Value,
+	) error
+}
+
+// ScriptResult is the result of a script execution.
+// Error is non-nil if the execution failed.
+//
+type ScriptResult struct {
+	Value interpreter.Value
+	Error error
+}
+
+// Account is an account created on the emulated blockchain.
+//
+type Account struct {
+	Address common.Address
+}
+
+var TestContractChecker = func() *sema.Checker {
+
+	program, err := parser.ParseProgram(contracts.TestContract, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	var checker *sema.Checker
+	checker, err = sema.NewChecker(
+		program,
+		TestContractLocation,
+		nil,
+		false,
+		sema.WithPredeclaredValues(BuiltinFunctions.ToSemaValueDeclarations()),
+		sema.WithPredeclaredTypes(BuiltinTypes.ToTypeDeclarations()),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	err = checker.Check()
+	if err != nil {
+		panic(err)
+	}
+
+	return checker
+}()
+
+var testContractType = func() *sema.CompositeType {
+	variable, ok := TestContractChecker.Elaboration.GlobalTypes.Get(testContractTypeName)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return variable.Type.(*sema.CompositeType)
+}()
+
+func testNestedType(name string) sema.Type {
+	nestedType, ok := testContractType.GetNestedTypes().Get(name)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return nestedType
+}
+
+var blockchainType = testNestedType(blockchainTypeName).(*sema.CompositeType)
+
+var blockchainBackendInterfaceType = testNestedType(blockchainBackendTypeName).(*sema.InterfaceType)
+
+var matcherType = testNestedType(matcherTypeName).(*sema.CompositeType)
+
+var resultStatusType = testNestedType(resultStatusTypeName).(*sema.CompositeType)
+
+var scriptResultType = testNestedType(scriptResultTypeName).(*sema.CompositeType)
+
+var transactionResultType = testNestedType(transactionResultTypeName).(*sema.CompositeType)
+
+var accountType = testNestedType(accountTypeName).(*sema.CompositeType)
+
+var errorType = testNestedType(errorTypeName).(*sema.CompositeType)
+
+var matcherTestFunctionType = func() *sema.FunctionType {
+	member, ok := matcherType.Members.Get(matcherTestFieldName)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return member.TypeAnnotation.Type.(*sema.FunctionType)
+}()
+
+// EmulatorBackendType is the natively implemented composite type
+// which conforms to the `Test.BlockchainBackend` interface.
+// Values of this type are created by `Test.newEmulatorBlockchain`.
+//
+var EmulatorBackendType = func() *sema.CompositeType {
+
+	ty := &sema.CompositeType{
+		Location:   TestContractLocation,
+		Identifier: emulatorBackendTypeName,
+		Kind:       common.CompositeKindStructure,
+		ExplicitInterfaceConformances: []*sema.InterfaceType{
+			blockchainBackendInterfaceType,
+		},
+	}
+	ty.SetContainerType(testContractType)
+
+	var members []*sema.Member
+	blockchainBackendInterfaceType.Members.Foreach(func(name string, member *sema.Member) {
+		functionType, ok := member.TypeAnnotation.Type.(*sema.FunctionType)
+		if !ok {
+			return
+		}
+		members = append(
+			members,
+			sema.NewUnmeteredPublicFunctionMember(
+				ty,
+				name,
+				functionType,
+				member.DocString,
+			),
+		)
+	})
+	ty.Members = sema.GetMembersAsMap(members)
+
+	return ty
+}()
+
+func init() {
+
+	// Enrich the `Test` contract type with the natively implemented functions
+
+	for _, function := range []struct {
+		name         string
+		functionType *sema.FunctionType
+		docString    string
+	}{
+		{testAssertFunctionName, testAssertFunctionType, testAssertFunctionDocString},
+		{testFailFunctionName, testFailFunctionType, testFailFunctionDocString},
+		{testExpectFunctionName, testExpectFunctionType, testExpectFunctionDocString},
+		{testEqualFunctionName, testEqualFunctionType, testEqualFunctionDocString},
+		{testNewEmulatorBlockchainFunctionName, testNewEmulatorBlockchainFunctionType, testNewEmulatorBlockchainFunctionDocString},
+		{testReadFileFunctionName, testReadFileFunctionType, testReadFileFunctionDocString},
+	} {
+		testContractType.Members.Set(
+			function.name,
+			sema.NewUnmeteredPublicFunctionMember(
+				testContractType,
+				function.name,
+				function.functionType,
+				function.docString,
+			),
+		)
+	}
+
+	// Enrich the `Test` contract elaboration with the natively implemented composite types
+
+	TestContractChecker.Elaboration.CompositeTypes[EmulatorBackendType.ID()] = EmulatorBackendType
+}
+
+var testContractInitializerTypes = func() (result []sema.Type) {
+	result = make([]sema.Type, len(testContractType.ConstructorParameters))
+	for i, parameter := range testContractType.ConstructorParameters {
+		result[i] = parameter.TypeAnnotation.Type
+	}
+	return result
+}()
+
+// NewTestContract constructs the `Test` contract value,
+// and injects the natively implemented functions,
+// which are backed by the given test framework.
+//
+func NewTestContract(
+	inter *interpreter.Interpreter,
+	testFramework TestFramework,
+	constructor interpreter.FunctionValue,
+	invocationRange ast.Range,
+) (
+	*interpreter.CompositeValue,
+	error,
+) {
+	value, err := inter.InvokeFunctionValue(
+		constructor,
+		nil,
+		testContractInitializerTypes,
+		testContractInitializerTypes,
+		invocationRange,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	compositeValue := value.(*interpreter.CompositeValue)
+
+	// Inject the natively implemented functions
+
+	compositeValue.Functions[testAssertFunctionName] = testAssertFunction
+	compositeValue.Functions[testFailFunctionName] = testFailFunction
+	compositeValue.Functions[testExpectFunctionName] = testExpectFunction
+	compositeValue.Functions[testEqualFunctionName] = testEqualFunction
+	compositeValue.Functions[testNewEmulatorBlockchainFunctionName] =
+		newTestNewEmulatorBlockchainFunction(testFramework)
+	compositeValue.Functions[testReadFileFunctionName] =
+		newTestReadFileFunction(testFramework)
+
+	return compositeValue, nil
+}
+
+// Functions of the `Test` contract.
+// Type checking of the arguments was already performed by the checker,
+// so the functions only have to assert the Go types of the values.
+
+// 'Test.assert' function
+
+const testAssertFunctionName = "assert"
+
+const testAssertFunctionDocString = `
+Fails the test if the given condition is false, and reports a message which explains why the condition is false.
+`
+
+var testAssertFunctionType = &sema.FunctionType{
+	Parameters: []*sema.Parameter{
+		{
+			Label:          sema.ArgumentLabelNotRequired,
+			Identifier:     "condition",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.BoolType),
+		},
+		{
+			Identifier:     "message",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.StringType),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.VoidType,
+	),
+	RequiredArgumentCount: sema.RequiredArgumentCount(1),
+}
+
+var testAssertFunction = interpreter.NewUnmeteredHostFunctionValue(
+	func(invocation interpreter.Invocation) interpreter.Value {
+		condition, ok := invocation.Arguments[0].(interpreter.BoolValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		var message string
+		if len(invocation.Arguments) > 1 {
+			messageValue, ok := invocation.Arguments[1].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			message = messageValue.Str
+		}
+
+		if !condition {
+			panic(AssertionError{
+				Message:       message,
+				LocationRange: invocation.GetLocationRange(),
+			})
+		}
+
+		return interpreter.VoidValue{}
+	},
+	testAssertFunctionType,
+)
+
+// 'Test.fail' function
+
+const testFailFunctionName = "fail"
+
+const testFailFunctionDocString = `
+Fails the test unconditionally, and reports a message which explains why the test failed.
+`
+
+var testFailFunctionType = &sema.FunctionType{
+	Parameters: []*sema.Parameter{
+		{
+			Identifier:     "message",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.StringType),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.VoidType,
+	),
+	RequiredArgumentCount: sema.RequiredArgumentCount(0),
+}
+
+var testFailFunction = interpreter.NewUnmeteredHostFunctionValue(
+	func(invocation interpreter.Invocation) interpreter.Value {
+		var message string
+		if len(invocation.Arguments) > 0 {
+			messageValue, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			message = messageValue.Str
+		}
+
+		panic(AssertionError{
+			Message:       message,
+			LocationRange: invocation.GetLocationRange(),
+		})
+	},
+	testFailFunctionType,
+)
+
+// 'Test.expect' function
+
+const testExpectFunctionName = "expect"
+
+const testExpectFunctionDocString = `
+Fails the test if the given value does not match the given matcher.
+`
+
+var testExpectFunctionType = &sema.FunctionType{
+	Parameters: []*sema.Parameter{
+		{
+			Label:          sema.ArgumentLabelNotRequired,
+			Identifier:     "value",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.AnyStructType),
+		},
+		{
+			Label:          sema.ArgumentLabelNotRequired,
+			Identifier:     "matcher",
+			TypeAnnotation: sema.NewTypeAnnotation(matcherType),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.VoidType,
+	),
+}
+
+var testExpectFunction = interpreter.NewUnmeteredHostFunctionValue(
+	func(invocation interpreter.Invocation) interpreter.Value {
+		value := invocation.Arguments[0]
+
+		matcher, ok := invocation.Arguments[1].(*interpreter.CompositeValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		inter := invocation.Interpreter
+		getLocationRange := invocation.GetLocationRange
+
+		testFunction, ok := matcher.GetField(
+			inter,
+			getLocationRange,
+			matcherTestFieldName,
+		).(interpreter.FunctionValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		locationRange := getLocationRange()
+
+		result, err := inter.InvokeFunctionValue(
+			testFunction,
+			[]interpreter.Value{value},
+			[]sema.Type{sema.AnyStructType},
+			[]sema.Type{sema.AnyStructType},
+			locationRange,
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		matched, ok := result.(interpreter.BoolValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		if !matched {
+			panic(AssertionError{
+				Message:       fmt.Sprintf("given value does not match: %s", value),
+				LocationRange: locationRange,
+			})
+		}
+
+		return interpreter.VoidValue{}
+	},
+	testExpectFunctionType,
+)
+
+// 'Test.equal' function
+
+const testEqualFunctionName = "equal"
+
+const testEqualFunctionDocString = `
+Returns a matcher that succeeds if the tested value is equal to the given value.
+`
+
+var testEqualFunctionType = &sema.FunctionType{
+	Parameters: []*sema.Parameter{
+		{
+			Label:          sema.ArgumentLabelNotRequired,
+			Identifier:     "value",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.AnyStructType),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		matcherType,
+	),
+}
+
+var testEqualFunction = interpreter.NewUnmeteredHostFunctionValue(
+	func(invocation interpreter.Invocation) interpreter.Value {
+		expected := invocation.Arguments[0]
+
+		inter := invocation.Interpreter
+
+		equalTestFunction := interpreter.NewHostFunctionValue(
+			inter,
+			func(invocation interpreter.Invocation) interpreter.Value {
+				actual := invocation.Arguments[0]
+
+				equatableExpected, ok := expected.(interpreter.EquatableValue)
+				if !ok {
+					return interpreter.BoolValue(false)
+				}
+
+				return interpreter.BoolValue(
+					equatableExpected.Equal(
+						invocation.Interpreter,
+						invocation.GetLocationRange,
+						actual,
+					),
+				)
+			},
+			matcherTestFunctionType,
+		)
+
+		return newMatcherValue(inter, invocation.GetLocationRange, equalTestFunction)
+	},
+	testEqualFunctionType,
+)
+
+func newMatcherValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	testFunction interpreter.FunctionValue,
+) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		matcherType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			{
+				Name:  matcherTestFieldName,
+				Value: testFunction,
+			},
+		},
+		common.Address{},
+	)
+}
+
+// 'Test.newEmulatorBlockchain' function
+
+const testNewEmulatorBlockchainFunctionName = "newEmulatorBlockchain"
+
+const blockchainBackendFieldName = "backend"
+
+const testNewEmulatorBlockchainFunctionDocString = `
+Creates a blockchain which is backed by a new emulator instance.
+`
+
+var testNewEmulatorBlockchainFunctionType = &sema.FunctionType{
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		blockchainType,
+	),
+}
+
+func newTestNewEmulatorBlockchainFunction(testFramework TestFramework) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			getLocationRange := invocation.GetLocationRange
+
+			backend := newEmulatorBackendValue(
+				inter,
+				getLocationRange,
+				testFramework.NewEmulatorBackend(),
+			)
+
+			blockchain := interpreter.NewCompositeValue(
+				inter,
+				getLocationRange,
+				TestContractLocation,
+				blockchainType.QualifiedIdentifier(),
+				common.CompositeKindStructure,
+				nil,
+				common.Address{},
+			)
+
+			// NOTE: The backend is provided as a computed field, instead of a stored field:
+			// The functions of the backend are implemented natively,
+			// and would get lost when the backend value is stored.
+
+			blockchain.ComputedFields = map[string]interpreter.ComputedField{
+				blockchainBackendFieldName: func(_ *interpreter.Interpreter, _ func() interpreter.LocationRange) interpreter.Value {
+					return backend
+				},
+			}
+
+			return blockchain
+		},
+		testNewEmulatorBlockchainFunctionType,
+	)
+}
+
+// 'Test.readFile' function
+
+const testReadFileFunctionName = "readFile"
+
+const testReadFileFunctionDocString = `
+Returns the content of the file at the given path.
+`
+
+var testReadFileFunctionType = &sema.FunctionType{
+	Parameters: []*sema.Parameter{
+		{
+			Label:          sema.ArgumentLabelNotRequired,
+			Identifier:     "path",
+			TypeAnnotation: sema.NewTypeAnnotation(sema.StringType),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.StringType,
+	),
+}
+
+func newTestReadFileFunction(testFramework TestFramework) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			pathValue, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			content, err := testFramework.ReadFile(pathValue.Str)
+			if err != nil {
+				panic(TestFrameworkError{
+					Err:           err,
+					LocationRange: invocation.GetLocationRange(),
+				})
+			}
+
+			return interpreter.NewStringValue(
+				invocation.Interpreter,
+				common.NewStringMemoryUsage(len(content)),
+				func() string {
+					return content
+				},
+			)
+		},
+		testReadFileFunctionType,
+	)
+}
+
+// 'EmulatorBackend' struct
+
+const emulatorBackendExecuteScriptFunctionName = "executeScript"
+const emulatorBackendCreateAccountFunctionName = "createAccount"
+const emulatorBackendExecuteTransactionFunctionName = "executeTransaction"
+const emulatorBackendDeployContractFunctionName = "deployContract"
+
+func emulatorBackendFunctionType(name string) *sema.FunctionType {
+	member, ok := EmulatorBackendType.Members.Get(name)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return member.TypeAnnotation.Type.(*sema.FunctionType)
+}
+
+func newEmulatorBackendValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	backend EmulatorBackend,
+) *interpreter.CompositeValue {
+
+	value := interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		EmulatorBackendType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		nil,
+		common.Address{},
+	)
+
+	value.Functions = map[string]interpreter.FunctionValue{
+		emulatorBackendExecuteScriptFunctionName:      newEmulatorBackendExecuteScriptFunction(backend),
+		emulatorBackendCreateAccountFunctionName:      newEmulatorBackendCreateAccountFunction(backend),
+		emulatorBackendExecuteTransactionFunctionName: newEmulatorBackendExecuteTransactionFunction(backend),
+		emulatorBackendDeployContractFunctionName:     newEmulatorBackendDeployContractFunction(backend),
+	}
+
+	return value
+}
+
+func newEmulatorBackendExecuteScriptFunction(backend EmulatorBackend) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			script, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			argumentsArray, ok := invocation.Arguments[1].(*interpreter.ArrayValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			inter := invocation.Interpreter
+			getLocationRange := invocation.GetLocationRange
+
+			arguments := arrayValueElements(inter, argumentsArray)
+
+			result := backend.RunScript(inter, script.Str, arguments)
+
+			return newScriptResultValue(inter, getLocationRange, result)
+		},
+		emulatorBackendFunctionType(emulatorBackendExecuteScriptFunctionName),
+	)
+}
+
+func newEmulatorBackendCreateAccountFunction(backend EmulatorBackend) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			account, err := backend.CreateAccount()
+			if err != nil {
+				panic(TestFrameworkError{
+					Err:           err,
+					LocationRange: invocation.GetLocationRange(),
+				})
+			}
+
+			return newAccountValue(
+				invocation.Interpreter,
+				invocation.GetLocationRange,
+				account,
+			)
+		},
+		emulatorBackendFunctionType(emulatorBackendCreateAccountFunctionName),
+	)
+}
+
+func newEmulatorBackendExecuteTransactionFunction(backend EmulatorBackend) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			transaction, ok := invocation.Arguments[0].(*interpreter.CompositeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			inter := invocation.Interpreter
+			getLocationRange := invocation.GetLocationRange
+
+			code, ok := transaction.GetField(inter, getLocationRange, "code").(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			signersArray, ok := transaction.GetField(inter, getLocationRange, "signers").(*interpreter.ArrayValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			var signers []*Account
+			for _, signer := range arrayValueElements(inter, signersArray) {
+				signerValue, ok := signer.(*interpreter.CompositeValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				signers = append(signers, accountFromValue(inter, getLocationRange, signerValue))
+			}
+
+			argumentsArray, ok := transaction.GetField(inter, getLocationRange, "arguments").(*interpreter.ArrayValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			arguments := arrayValueElements(inter, argumentsArray)
+
+			err := backend.RunTransaction(inter, code.Str, signers, arguments)
+
+			return newTransactionResultValue(inter, getLocationRange, err)
+		},
+		emulatorBackendFunctionType(emulatorBackendExecuteTransactionFunctionName),
+	)
+}
+
+func newEmulatorBackendDeployContractFunction(backend EmulatorBackend) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		func(invocation interpreter.Invocation) interpreter.Value {
+			name, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			code, ok := invocation.Arguments[1].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			accountValue, ok := invocation.Arguments[2].(*interpreter.CompositeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			argumentsArray, ok := invocation.Arguments[3].(*interpreter.ArrayValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			inter := invocation.Interpreter
+			getLocationRange := invocation.GetLocationRange
+
+			account := accountFromValue(inter, getLocationRange, accountValue)
+			arguments := arrayValueElements(inter, argumentsArray)
+
+			err := backend.DeployContract(inter, name.Str, code.Str, account, arguments)
+
+			return newOptionalErrorValue(inter, getLocationRange, err)
+		},
+		emulatorBackendFunctionType(emulatorBackendDeployContractFunctionName),
+	)
+}
+
+// Values of the `Test` contract's types
+
+func arrayValueElements(inter *interpreter.Interpreter, array *interpreter.ArrayValue) []interpreter.Value {
+	elements := make([]interpreter.Value, 0, array.Count())
+	array.Iterate(inter, func(element interpreter.Value) (resume bool) {
+		elements = append(elements, element)
+		return true
+	})
+	return elements
+}
+
+func newResultStatusValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	succeeded bool,
+) *interpreter.CompositeValue {
+
+	// The raw values of enum cases are their declaration indices,
+	// see the declaration of `Test.ResultStatus`
+	var rawValue uint8 = resultStatusFailedRawValue
+	if succeeded {
+		rawValue = resultStatusSucceededRawValue
+	}
+
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		resultStatusType.QualifiedIdentifier(),
+		common.CompositeKindEnum,
+		[]interpreter.CompositeField{
+			{
+				Name:  sema.EnumRawValueFieldName,
+				Value: interpreter.NewUInt8Value(inter, func() uint8 { return rawValue }),
+			},
+		},
+		common.Address{},
+	)
+}
+
+func newErrorValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	err error,
+) *interpreter.CompositeValue {
+
+	message := err.Error()
+
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		errorType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			{
+				Name: "message",
+				Value: interpreter.NewStringValue(
+					inter,
+					common.NewStringMemoryUsage(len(message)),
+					func() string {
+						return message
+					},
+				),
+			},
+		},
+		common.Address{},
+	)
+}
+
+func newOptionalErrorValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	err error,
+) interpreter.OptionalValue {
+	if err == nil {
+		return interpreter.NewNilValue(inter)
+	}
+
+	return interpreter.NewSomeValueNonCopying(
+		inter,
+		newErrorValue(inter, getLocationRange, err),
+	)
+}
+
+func newScriptResultValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	result *ScriptResult,
+) *interpreter.CompositeValue {
+
+	var returnValue interpreter.OptionalValue
+	if result.Error != nil || result.Value == nil {
+		returnValue = interpreter.NewNilValue(inter)
+	} else {
+		returnValue = interpreter.NewSomeValueNonCopying(inter, result.Value)
+	}
+
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		scriptResultType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			{
+				Name:  "status",
+				Value: newResultStatusValue(inter, getLocationRange, result.Error == nil),
+			},
+			{
+				Name:  "returnValue",
+				Value: returnValue,
+			},
+			{
+				Name:  "error",
+				Value: newOptionalErrorValue(inter, getLocationRange, result.Error),
+			},
+		},
+		common.Address{},
+	)
+}
+
+func newTransactionResultValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	err error,
+) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		transactionResultType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			{
+				Name:  "status",
+				Value: newResultStatusValue(inter, getLocationRange, err == nil),
+			},
+			{
+				Name:  "error",
+				Value: newOptionalErrorValue(inter, getLocationRange, err),
+			},
+		},
+		common.Address{},
+	)
+}
+
+func newAccountValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	account *Account,
+) *interpreter.CompositeValue {
+	return interpreter.NewCompositeValue(
+		inter,
+		getLocationRange,
+		TestContractLocation,
+		accountType.QualifiedIdentifier(),
+		common.CompositeKindStructure,
+		[]interpreter.CompositeField{
+			{
+				Name:  "address",
+				Value: interpreter.NewAddressValue(inter, account.Address),
+			},
+		},
+		common.Address{},
+	)
+}
+
+func accountFromValue(
+	inter *interpreter.Interpreter,
+	getLocationRange func() interpreter.LocationRange,
+	accountValue *interpreter.CompositeValue,
+) *Account {
+	address, ok := accountValue.GetField(inter, getLocationRange, "address").(interpreter.AddressValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	return &Account{
+		Address: address.ToAddress(),
+	}
+}
+
+// TestFrameworkError is reported when the test framework
+// fails to perform a natively implemented operation.
+//
+type TestFrameworkError struct {
+	Err error
+	interpreter.LocationRange
+}
+
+var _ errors.UserError = TestFrameworkError{}
+
+func (TestFrameworkError) IsUserError() {}
+
+func (e TestFrameworkError) Unwrap() error {
+	return e.Err
+}
+
+func (e TestFrameworkError) Error() string {
+	return fmt.Sprintf("test framework error: %s", e.Err.Error())
+}
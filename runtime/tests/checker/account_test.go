@@ -1508,3 +1508,151 @@ func TestPublicAccountContracts(t *testing.T) {
 	})
 
 }
+
+func TestCheckAccount_paths(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("AuthAccount", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            let storagePaths: [StoragePath] = authAccount.storagePaths
+            let publicPaths: [PublicPath] = authAccount.publicPaths
+            let privatePaths: [PrivatePath] = authAccount.privatePaths
+        `)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("PublicAccount", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            let publicPaths: [PublicPath] = publicAccount.publicPaths
+        `)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("PublicAccount, storage paths", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            let storagePaths = publicAccount.storagePaths
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.NotDeclaredMemberError{}, errs[0])
+	})
+
+	t.Run("assignment", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                authAccount.storagePaths = []
+            }
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 2)
+
+		assert.IsType(t, &sema.InvalidAssignmentAccessError{}, errs[0])
+		assert.IsType(t, &sema.AssignmentToConstantMemberError{}, errs[1])
+	})
+}
+
+func TestCheckAccount_forEach(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("AuthAccount", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                authAccount.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                    return true
+                })
+                authAccount.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+                    return true
+                })
+                authAccount.forEachPrivate(fun (path: PrivatePath, type: Type): Bool {
+                    return true
+                })
+            }
+        `)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("PublicAccount", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                publicAccount.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+                    return true
+                })
+            }
+        `)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("PublicAccount, stored", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                publicAccount.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                    return true
+                })
+            }
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.NotDeclaredMemberError{}, errs[0])
+	})
+
+	t.Run("invalid path type", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                authAccount.forEachStored(fun (path: PublicPath, type: Type): Bool {
+                    return true
+                })
+            }
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("missing return", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheckAccount(t, `
+            fun test() {
+                authAccount.forEachStored(fun (path: StoragePath, type: Type) {})
+            }
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+}
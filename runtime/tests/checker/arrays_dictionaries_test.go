@@ -1224,3 +1224,205 @@ func TestNilAssignmentToDictionary(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestCheckArrayFilter(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test(): [Int] {
+          let a = [1, 2, 3, 4]
+          return a.filter(fun (x: Int): Bool {
+              return x % 2 == 0
+          })
+      }
+
+      fun testConstantSized(): [Int] {
+          let a: [Int; 4] = [1, 2, 3, 4]
+          return a.filter(fun (x: Int): Bool {
+              return x > 2
+          })
+      }
+    `)
+
+	require.NoError(t, err)
+}
+
+func TestCheckInvalidArrayFilterPredicate(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test(): [Int] {
+          let a = [1, 2, 3, 4]
+          return a.filter(fun (x: String): Bool {
+              return true
+          })
+      }
+    `)
+
+	errs := ExpectCheckerErrors(t, err, 1)
+
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+}
+
+func TestCheckArrayMap(t *testing.T) {
+
+	t.Parallel()
+
+	checker, err := ParseAndCheck(t, `
+      let a = [1, 2, 3].map(fun (x: Int): String {
+          return x.toString()
+      })
+
+      let c: [Int; 3] = [1, 2, 3]
+
+      let b = c.map(fun (x: Int): Int8 {
+          return Int8(x)
+      })
+    `)
+
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		&sema.VariableSizedType{
+			Type: sema.StringType,
+		},
+		RequireGlobalValue(t, checker.Elaboration, "a"),
+	)
+
+	assert.Equal(t,
+		&sema.ConstantSizedType{
+			Type: sema.Int8Type,
+			Size: 3,
+		},
+		RequireGlobalValue(t, checker.Elaboration, "b"),
+	)
+}
+
+func TestCheckInvalidArrayMapResultType(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      let a: [Int] = [1, 2, 3].map(fun (x: Int): String {
+          return x.toString()
+      })
+    `)
+
+	errs := ExpectCheckerErrors(t, err, 1)
+
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+}
+
+func TestCheckArrayReverse(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test(): [Int] {
+          let a = [1, 2, 3]
+          return a.reverse()
+      }
+
+      fun testConstantSized(): [Int; 3] {
+          let a: [Int; 3] = [1, 2, 3]
+          return a.reverse()
+      }
+    `)
+
+	require.NoError(t, err)
+}
+
+func TestCheckArraySort(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test(): [Int] {
+          let a = [3, 1, 2]
+          a.sort(by: fun (a: Int, b: Int): Bool {
+              return a < b
+          })
+          return a
+      }
+    `)
+
+	require.NoError(t, err)
+}
+
+func TestCheckInvalidResourceArrayHigherOrderFunctions(t *testing.T) {
+
+	t.Parallel()
+
+	for _, name := range []string{
+		"filter",
+		"map",
+		"reverse",
+		"sort",
+	} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+
+			t.Parallel()
+
+			_, err := ParseAndCheck(t,
+				fmt.Sprintf(
+					`
+                      resource X {}
+
+                      fun test() {
+                          let xs <- [<-create X()]
+                          let f = xs.%s
+                          destroy xs
+                      }
+                    `,
+					name,
+				),
+			)
+
+			errs := ExpectCheckerErrors(t, err, 2)
+
+			assert.IsType(t, &sema.InvalidResourceArrayMemberError{}, errs[0])
+			assert.IsType(t, &sema.ResourceMethodBindingError{}, errs[1])
+		})
+	}
+}
+
+func TestCheckDictionaryForEachKey(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test(): Int {
+          let d = {"a": 1, "b": 2}
+          var count = 0
+          d.forEachKey(fun (key: String): Bool {
+              count = count + d[key]!
+              return true
+          })
+          return count
+      }
+    `)
+
+	require.NoError(t, err)
+}
+
+func TestCheckInvalidDictionaryForEachKey(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test() {
+          let d = {"a": 1, "b": 2}
+          d.forEachKey(fun (key: Int): Bool {
+              return true
+          })
+      }
+    `)
+
+	errs := ExpectCheckerErrors(t, err, 1)
+
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+}
@@ -0,0 +1,438 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+func TestCheckGenericFunctionDeclaration(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("inferred", func(t *testing.T) {
+
+		t.Parallel()
+
+		checker, err := ParseAndCheck(t, `
+          fun first<T: AnyStruct>(_ xs: [T]): T? {
+              if xs.length == 0 {
+                  return nil
+              }
+              let x: T = xs[0]
+              return x
+          }
+
+          let x = first([1, 2])
+          let y = first(["a"])
+        `)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			&sema.OptionalType{Type: sema.IntType},
+			RequireGlobalValue(t, checker.Elaboration, "x"),
+		)
+		assert.Equal(t,
+			&sema.OptionalType{Type: sema.StringType},
+			RequireGlobalValue(t, checker.Elaboration, "y"),
+		)
+	})
+
+	t.Run("explicit type argument", func(t *testing.T) {
+
+		t.Parallel()
+
+		checker, err := ParseAndCheck(t, `
+          fun identity<T>(_ x: T): T {
+              return x
+          }
+
+          let x = identity<Int8>(1 as Int8)
+        `)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			sema.Int8Type,
+			RequireGlobalValue(t, checker.Elaboration, "x"),
+		)
+	})
+
+	t.Run("type bound violated", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun double<T: Integer>(_ x: T): [T] {
+              return [x, x]
+          }
+
+          let x = double("a")
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("type parameter mismatch", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun pair<T>(_ a: T, _ b: T): [T] {
+              return [a, b]
+          }
+
+          let x = pair(1, "a")
+        `)
+		errs := ExpectCheckerErrors(t, err, 2)
+
+		assert.IsType(t, &sema.TypeParameterTypeMismatchError{}, errs[0])
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
+	})
+
+	t.Run("not inferrable", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun make<T>(): [T] {
+              return []
+          }
+
+          let x = make()
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.TypeParameterTypeInferenceError{}, errs[0])
+	})
+
+	t.Run("generic value is opaque", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun test<T>(_ x: T): Int {
+              return x
+          }
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("type bound members", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct interface Named {
+              let name: String
+          }
+
+          struct Person: Named {
+              let name: String
+
+              init(name: String) {
+                  self.name = name
+              }
+          }
+
+          fun nameOf<T: {Named}>(_ x: T): String {
+              return x.name
+          }
+
+          let name = nameOf(Person(name: "Alice"))
+        `)
+		require.NoError(t, err)
+	})
+
+	t.Run("resource type bound", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          resource R {}
+
+          fun keep<T: @AnyResource>(_ r: @T): @T {
+              return <-r
+          }
+
+          fun test() {
+              let r <- keep(<-create R())
+              destroy r
+          }
+        `)
+		require.NoError(t, err)
+	})
+
+	t.Run("resource loss", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun lose<T: @AnyResource>(_ r: @T) {}
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.ResourceLossError{}, errs[0])
+	})
+
+	t.Run("duplicate type parameter", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun test<T, T>(_ x: T) {}
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.RedeclarationError{}, errs[0])
+	})
+
+	t.Run("type parameter not in scope", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun test<T>(_ x: T) {}
+
+          let x: T = 1
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.NotDeclaredError{}, errs[0])
+	})
+
+	t.Run("nested generic function", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          fun test(): Int {
+              fun identity<T>(_ x: T): T {
+                  return x
+              }
+              return identity(1)
+          }
+        `)
+		require.NoError(t, err)
+	})
+}
+
+func TestCheckGenericCompositeDeclaration(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("structure", func(t *testing.T) {
+
+		t.Parallel()
+
+		checker, err := ParseAndCheck(t, `
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+
+              fun get(): T {
+                  return self.value
+              }
+
+              fun map<U>(_ f: ((T): U)): Box<U> {
+                  return Box(value: f(self.value))
+              }
+          }
+
+          let box = Box(value: 1)
+          let value = box.value
+          let got = box.get()
+          let mapped = box.map(fun (x: Int): String { return x.toString() })
+          let explicit: Box<Int> = Box<Int>(value: 2)
+        `)
+		require.NoError(t, err)
+
+		boxType := RequireGlobalValue(t, checker.Elaboration, "box")
+		assert.Equal(t, "Box<Int>", boxType.String())
+		assert.Equal(t, sema.TypeID("S.test.Box<Int>"), boxType.ID())
+
+		assert.Equal(t, sema.IntType, RequireGlobalValue(t, checker.Elaboration, "value"))
+		assert.Equal(t, sema.IntType, RequireGlobalValue(t, checker.Elaboration, "got"))
+		assert.Equal(t,
+			"Box<String>",
+			RequireGlobalValue(t, checker.Elaboration, "mapped").String(),
+		)
+	})
+
+	t.Run("resource", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          resource Vault<T: Integer> {
+              var balance: T
+
+              init(balance: T) {
+                  self.balance = balance
+              }
+          }
+
+          fun test(): Int8 {
+              let vault <- create Vault(balance: 1 as Int8)
+              let balance = vault.balance
+              destroy vault
+              return balance
+          }
+        `)
+		require.NoError(t, err)
+	})
+
+	t.Run("type mismatch of instantiations", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+          }
+
+          let box: Box<String> = Box(value: 1)
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("field of instantiation", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct Holder {
+              let box: Box<Int>
+
+              init() {
+                  self.box = Box(value: 1)
+              }
+          }
+
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+          }
+
+          let value: Int = Holder().box.value
+        `)
+		require.NoError(t, err)
+	})
+
+	t.Run("missing type arguments", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+          }
+
+          let box: Box = Box(value: 1)
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.MissingTypeArgumentsError{}, errs[0])
+	})
+
+	t.Run("invalid type argument count", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct Box<T> {}
+
+          let box: Box<Int, Int>? = nil
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.InvalidTypeArgumentCountError{}, errs[0])
+	})
+
+	t.Run("type bound violated", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          struct Box<T: Number> {}
+
+          let box: Box<String>? = nil
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("contract", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          contract C<T> {}
+        `)
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		assert.IsType(t, &sema.InvalidTypeParametersError{}, errs[0])
+	})
+
+	t.Run("nested in contract", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+          contract C {
+              struct Box<T> {
+                  let value: T
+
+                  init(value: T) {
+                      self.value = value
+                  }
+              }
+
+              fun box(): Box<Bool> {
+                  return Box(value: true)
+              }
+          }
+        `)
+		require.NoError(t, err)
+	})
+}
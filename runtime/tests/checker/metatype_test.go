@@ -227,6 +227,51 @@ func TestCheckIsSubtype(t *testing.T) {
 	}
 }
 
+func TestCheckMetaTypeReflection(t *testing.T) {
+
+	t.Parallel()
+
+	checker, err := ParseAndCheck(t, `
+      resource R {}
+
+      let type = Type<@R>()
+      let fields = type.fields
+      let functions = type.functions
+      let conformances = type.conformances
+    `)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		sema.MetaTypeFieldsType,
+		RequireGlobalValue(t, checker.Elaboration, "fields"),
+	)
+	assert.Equal(t,
+		sema.MetaTypeFunctionsType,
+		RequireGlobalValue(t, checker.Elaboration, "functions"),
+	)
+	assert.Equal(t,
+		sema.MetaTypeConformancesType,
+		RequireGlobalValue(t, checker.Elaboration, "conformances"),
+	)
+}
+
+func TestCheckInvalidMetaTypeReflectionAssignment(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+      fun test() {
+          let type = Type<Int>()
+          type.fields = {}
+      }
+    `)
+
+	errs := ExpectCheckerErrors(t, err, 2)
+
+	require.IsType(t, &sema.InvalidAssignmentAccessError{}, errs[0])
+	require.IsType(t, &sema.AssignmentToConstantMemberError{}, errs[1])
+}
+
 func TestCheckIsInstance_Redeclaration(t *testing.T) {
 
 	t.Parallel()
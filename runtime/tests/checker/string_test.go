@@ -324,3 +324,109 @@ func TestCheckStringToLower(t *testing.T) {
 		RequireGlobalValue(t, checker.Elaboration, "x"),
 	)
 }
+
+func TestCheckStringFunctions(t *testing.T) {
+
+	t.Parallel()
+
+	checker, err := ParseAndCheck(t, `
+        let upper = "abc".toUpper()
+        let parts = "a,b,c".split(separator: ",")
+        let joined = String.join(["a", "b"], separator: ",")
+        let replaced = "aXb".replaceAll(of: "X", with: "Y")
+        let contained = "abc".contains("b")
+        let index = "abc".index(of: "c")
+        let trimmed = "  abc  ".trim()
+	`)
+
+	require.NoError(t, err)
+
+	for name, expectedType := range map[string]sema.Type{
+		"upper":     sema.StringType,
+		"parts":     &sema.VariableSizedType{Type: sema.StringType},
+		"joined":    sema.StringType,
+		"replaced":  sema.StringType,
+		"contained": sema.BoolType,
+		"index":     &sema.OptionalType{Type: sema.IntType},
+		"trimmed":   sema.StringType,
+	} {
+		assert.Equal(t,
+			expectedType,
+			RequireGlobalValue(t, checker.Elaboration, name),
+			name,
+		)
+	}
+}
+
+func TestCheckInvalidStringFunctionArguments(t *testing.T) {
+
+	t.Parallel()
+
+	_, err := ParseAndCheck(t, `
+        let parts = "a,b,c".split(separator: 1)
+        let joined = String.join([1, 2], separator: ",")
+        let contained = "abc".contains(of: "b")
+	`)
+
+	errs := ExpectCheckerErrors(t, err, 4)
+
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
+	assert.IsType(t, &sema.TypeMismatchError{}, errs[2])
+	assert.IsType(t, &sema.IncorrectArgumentLabelError{}, errs[3])
+}
+
+func TestCheckStringTemplate(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+
+		t.Parallel()
+
+		checker, err := ParseAndCheck(t, `
+            let s = "s"
+            let c: Character = "c"
+            let i = 1
+            let f = 1.5
+            let a: Address = 0x1
+            let p = /storage/foo
+            let x = "\(s) \(c) \(i) \(f) \(a) \(p) \(i + 1)"
+        `)
+
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			sema.StringType,
+			RequireGlobalValue(t, checker.Elaboration, "x"),
+		)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+            let b = true
+            let x = "\(b)"
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.TypeMismatchWithDescriptionError{}, errs[0])
+	})
+
+	t.Run("invalid, not a character", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := ParseAndCheck(t, `
+            let c = "c"
+            let x: Character = "\(c)"
+        `)
+
+		errs := ExpectCheckerErrors(t, err, 1)
+
+		require.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+}
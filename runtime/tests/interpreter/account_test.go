@@ -1902,3 +1902,448 @@ func TestInterpretAccount_StorageFields(t *testing.T) {
 		}
 	}
 }
+
+func TestInterpretAccount_paths(t *testing.T) {
+
+	t.Parallel()
+
+	const setup = `
+      resource R {}
+
+      fun setup() {
+          authAccount.save(1, to: /storage/a)
+          authAccount.save(<-create R(), to: /storage/b)
+          authAccount.link<&R>(/public/b, target: /storage/b)
+          authAccount.link<&R>(/private/b, target: /storage/b)
+          authAccount.link<&Int>(/private/a, target: /storage/a)
+      }
+    `
+
+	for accountType, auth := range map[string]bool{
+		"AuthAccount":   true,
+		"PublicAccount": false,
+	} {
+
+		auth := auth
+
+		t.Run(accountType, func(t *testing.T) {
+
+			t.Parallel()
+
+			code := setup + `
+              fun test(): Bool {
+                  let paths = account.publicPaths
+                  return paths.length == 1
+                      && paths[0].toString() == "/public/b"
+              }
+            `
+
+			address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+			inter, _ := testAccount(t, address, auth, code)
+
+			_, err := inter.Invoke("setup")
+			require.NoError(t, err)
+
+			value, err := inter.Invoke("test")
+			require.NoError(t, err)
+			require.Equal(t, interpreter.BoolValue(true), value)
+		})
+	}
+
+	t.Run("storage and private paths", func(t *testing.T) {
+
+		t.Parallel()
+
+		code := setup + `
+          fun test(): Bool {
+              let storagePaths: {String: Bool} = {}
+              for path in account.storagePaths {
+                  storagePaths[path.toString()] = true
+              }
+
+              let privatePaths: {String: Bool} = {}
+              for path in account.privatePaths {
+                  privatePaths[path.toString()] = true
+              }
+
+              return storagePaths.length == 2
+                  && storagePaths["/storage/a"] == true
+                  && storagePaths["/storage/b"] == true
+                  && privatePaths.length == 2
+                  && privatePaths["/private/a"] == true
+                  && privatePaths["/private/b"] == true
+          }
+        `
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(t, address, true, code)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+		require.Equal(t, interpreter.BoolValue(true), value)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			`
+              fun test(): Int {
+                  return account.storagePaths.length
+              }
+            `,
+		)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+		AssertValuesEqual(t, inter, interpreter.NewUnmeteredIntValueFromInt64(0), value)
+	})
+}
+
+func TestInterpretAuthAccount_forEachStored(t *testing.T) {
+
+	t.Parallel()
+
+	const setup = `
+      resource R {}
+
+      struct S {}
+
+      fun setup() {
+          account.save(1, to: /storage/a)
+          account.save(<-create R(), to: /storage/b)
+          account.save(S(), to: /storage/c)
+      }
+    `
+
+	t.Run("all", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test(): {StoragePath: Type} {
+                  let types: {StoragePath: Type} = {}
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      types[path] = type
+                      return true
+                  })
+                  return types
+              }
+
+              fun check(): Bool {
+                  let types = test()
+                  return types.length == 3
+                      && types[/storage/a] == Type<Int>()
+                      && types[/storage/b] == Type<@R>()
+                      && types[/storage/c] == Type<S>()
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		var loopIterations uint
+		inter.SetOnMeterComputationHandler(func(compKind common.ComputationKind, intensity uint) {
+			if compKind == common.ComputationKindLoop {
+				loopIterations += intensity
+			}
+		})
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		// Each iteration is metered
+		assert.Equal(t, uint(3), loopIterations)
+
+		value, err := inter.Invoke("check")
+		require.NoError(t, err)
+		require.Equal(t, interpreter.BoolValue(true), value)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test(): Int {
+                  var count = 0
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      count = count + 1
+                      return false
+                  })
+                  return count
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+		AssertValuesEqual(t, inter, interpreter.NewUnmeteredIntValueFromInt64(1), value)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			`
+              fun test(): Int {
+                  var count = 0
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      count = count + 1
+                      return true
+                  })
+                  return count
+              }
+            `,
+		)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+		AssertValuesEqual(t, inter, interpreter.NewUnmeteredIntValueFromInt64(0), value)
+	})
+
+	t.Run("mutation, then stop", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, getAccountValues := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test() {
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      if type == Type<@R>() {
+                          destroy account.load<@R>(from: path)
+                          return false
+                      }
+                      return true
+                  })
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+		require.Len(t, getAccountValues(), 3)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+		require.Len(t, getAccountValues(), 2)
+	})
+
+	t.Run("mutation, then continue", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test() {
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      account.save(2, to: /storage/d)
+                      return true
+                  })
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.StorageMutatedDuringIterationError{})
+	})
+
+	t.Run("mutation of other domain", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test(): Int {
+                  var count = 0
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      count = count + 1
+                      account.link<&AnyStruct>(/public/x, target: path)
+                      account.unlink(/public/x)
+                      return true
+                  })
+                  return count
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+		AssertValuesEqual(t, inter, interpreter.NewUnmeteredIntValueFromInt64(3), value)
+	})
+
+	t.Run("nested mutation, then continue", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+		inter, _ := testAccount(
+			t,
+			address,
+			true,
+			setup+`
+              fun test() {
+                  account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                      account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+                          account.save(2, to: /storage/d)
+                          return false
+                      })
+                      return true
+                  })
+              }
+            `,
+		)
+
+		_, err := inter.Invoke("setup")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.StorageMutatedDuringIterationError{})
+	})
+}
+
+func TestInterpretAccount_forEachPublic(t *testing.T) {
+
+	t.Parallel()
+
+	for accountType, auth := range map[string]bool{
+		"AuthAccount":   true,
+		"PublicAccount": false,
+	} {
+
+		auth := auth
+
+		t.Run(accountType, func(t *testing.T) {
+
+			t.Parallel()
+
+			address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+			inter, _ := testAccount(
+				t,
+				address,
+				auth,
+				`
+                  resource R {}
+
+                  fun setup() {
+                      authAccount.save(<-create R(), to: /storage/r)
+                      authAccount.link<&R>(/public/r, target: /storage/r)
+                      authAccount.link<&R>(/private/r, target: /storage/r)
+                  }
+
+                  fun test(): Bool {
+                      var count = 0
+                      var found = false
+                      account.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+                          count = count + 1
+                          found = path.toString() == "/public/r"
+                              && type == Type<Capability<&R>>()
+                          return true
+                      })
+                      return count == 1 && found
+                  }
+                `,
+			)
+
+			_, err := inter.Invoke("setup")
+			require.NoError(t, err)
+
+			value, err := inter.Invoke("test")
+			require.NoError(t, err)
+			require.Equal(t, interpreter.BoolValue(true), value)
+		})
+	}
+}
+
+func TestInterpretAuthAccount_forEachPrivate(t *testing.T) {
+
+	t.Parallel()
+
+	address := interpreter.NewUnmeteredAddressValueFromBytes([]byte{42})
+
+	inter, _ := testAccount(
+		t,
+		address,
+		true,
+		`
+          resource R {}
+
+          fun test(): Bool {
+              account.save(<-create R(), to: /storage/r)
+              account.link<&R>(/public/r, target: /storage/r)
+              account.link<&R>(/private/r, target: /storage/r)
+
+              var paths: [PrivatePath] = []
+              account.forEachPrivate(fun (path: PrivatePath, type: Type): Bool {
+                  paths.append(path)
+                  return true
+              })
+              return paths.length == 1 && paths[0].toString() == "/private/r"
+          }
+        `,
+	)
+
+	value, err := inter.Invoke("test")
+	require.NoError(t, err)
+	require.Equal(t, interpreter.BoolValue(true), value)
+}
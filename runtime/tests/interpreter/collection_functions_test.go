@@ -0,0 +1,391 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+func TestInterpretArrayFilter(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("variable-sized", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [Int] {
+              let a = [1, 2, 3, 4, 5, 6]
+              return a.filter(fun (x: Int): Bool {
+                  return x % 2 == 0
+              })
+          }
+        `)
+
+		var loopIterations uint
+		inter.SetOnMeterComputationHandler(func(compKind common.ComputationKind, intensity uint) {
+			if compKind == common.ComputationKindLoop {
+				loopIterations += intensity
+			}
+		})
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "[2, 4, 6]", value.String())
+
+		// Each element is metered
+		assert.Equal(t, uint(6), loopIterations)
+	})
+
+	t.Run("constant-sized", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [Int] {
+              let a: [Int; 3] = [1, 2, 3]
+              return a.filter(fun (x: Int): Bool {
+                  return x > 1
+              })
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "[2, 3]", value.String())
+	})
+
+	t.Run("copies", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct S {
+              var value: Int
+
+              init(value: Int) {
+                  self.value = value
+              }
+          }
+
+          fun test(): [Int] {
+              let a = [S(value: 1), S(value: 2)]
+              let b = a.filter(fun (s: S): Bool {
+                  s.value = s.value * 10
+                  return s.value > 10
+              })
+              b[0].value = 3
+              return [a[0].value, a[1].value, b[0].value]
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "[1, 2, 3]", value.String())
+	})
+
+	t.Run("mutation", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [Int] {
+              let a = [1, 2, 3]
+              return a.filter(fun (x: Int): Bool {
+                  a.append(x)
+                  return true
+              })
+          }
+        `)
+
+		_, err := inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.ContainerMutatedDuringIterationError{})
+	})
+}
+
+func TestInterpretArrayMap(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("variable-sized", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [String] {
+              let a = [1, 2, 3]
+              return a.map(fun (x: Int): String {
+                  return x.toString()
+              })
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, `["1", "2", "3"]`, value.String())
+		assert.Equal(t,
+			interpreter.VariableSizedStaticType{
+				Type: interpreter.PrimitiveStaticTypeString,
+			},
+			value.StaticType(inter),
+		)
+	})
+
+	t.Run("constant-sized", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [Int8; 3] {
+              let a: [Int; 3] = [1, 2, 3]
+              return a.map(fun (x: Int): Int8 {
+                  return Int8(x * 2)
+              })
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "[2, 4, 6]", value.String())
+		assert.Equal(t,
+			interpreter.ConstantSizedStaticType{
+				Type: interpreter.PrimitiveStaticTypeInt8,
+				Size: 3,
+			},
+			value.StaticType(inter),
+		)
+	})
+
+	t.Run("resources", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          resource R {
+              let value: Int
+
+              init(value: Int) {
+                  self.value = value
+              }
+          }
+
+          fun test(): Int {
+              let rs <- [1, 2, 3].map(fun (x: Int): @R {
+                  return <-create R(value: x)
+              })
+              let value = rs[2].value
+              destroy rs
+              return value
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, interpreter.NewUnmeteredIntValueFromInt64(3), value)
+	})
+}
+
+func TestInterpretArrayReverse(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test(): [AnyStruct] {
+          let a = [1, 2, 3]
+          let b: [Int; 2] = [4, 5]
+          let c: [Int] = []
+          return [a.reverse(), b.reverse(), c.reverse(), a]
+      }
+    `)
+
+	value, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	assert.Equal(t, "[[3, 2, 1], [5, 4], [], [1, 2, 3]]", value.String())
+}
+
+func TestInterpretArraySort(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("stable", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): [[Int]] {
+              let a = [[2, 0], [1, 0], [2, 1], [1, 1], [0, 0]]
+              a.sort(by: fun (a: [Int], b: [Int]): Bool {
+                  return a[0] < b[0]
+              })
+              return a
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, "[[0, 0], [1, 0], [1, 1], [2, 0], [2, 1]]", value.String())
+	})
+
+	t.Run("field", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct S {
+              let values: [String]
+
+              init() {
+                  self.values = ["c", "a", "b"]
+              }
+
+              fun sorted(): [String] {
+                  self.values.sort(by: fun (a: String, b: String): Bool {
+                      return a.utf8[0] < b.utf8[0]
+                  })
+                  return self.values
+              }
+          }
+
+          fun test(): [String] {
+              return S().sorted()
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, `["a", "b", "c"]`, value.String())
+	})
+
+	t.Run("mutation", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test() {
+              let a = [3, 2, 1]
+              a.sort(by: fun (x: Int, y: Int): Bool {
+                  a.removeLast()
+                  return x < y
+              })
+          }
+        `)
+
+		_, err := inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.ContainerMutatedDuringIterationError{})
+	})
+}
+
+func TestInterpretDictionaryForEachKey(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("all", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): Int {
+              let d = {"a": 1, "b": 2, "c": 3}
+              var sum = 0
+              d.forEachKey(fun (key: String): Bool {
+                  sum = sum + d[key]!
+                  return true
+              })
+              return sum
+          }
+        `)
+
+		var loopIterations uint
+		inter.SetOnMeterComputationHandler(func(compKind common.ComputationKind, intensity uint) {
+			if compKind == common.ComputationKindLoop {
+				loopIterations += intensity
+			}
+		})
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, interpreter.NewUnmeteredIntValueFromInt64(6), value)
+
+		// Each key is metered
+		assert.Equal(t, uint(3), loopIterations)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test(): Int {
+              let d = {"a": 1, "b": 2, "c": 3}
+              var count = 0
+              d.forEachKey(fun (key: String): Bool {
+                  count = count + 1
+                  return count < 2
+              })
+              return count
+          }
+        `)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t, interpreter.NewUnmeteredIntValueFromInt64(2), value)
+	})
+
+	t.Run("mutation", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun test() {
+              let d = {"a": 1, "b": 2, "c": 3}
+              d.forEachKey(fun (key: String): Bool {
+                  d.remove(key: key)
+                  return true
+              })
+          }
+        `)
+
+		_, err := inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.ContainerMutatedDuringIterationError{})
+	})
+}
@@ -0,0 +1,386 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/onflow/atree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	. "github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestInterpretGenericFunctionDeclaration(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("inferred", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun first<T: AnyStruct>(_ xs: [T]): T? {
+              if xs.length == 0 {
+                  return nil
+              }
+              return xs[0]
+          }
+
+          fun test(): [AnyStruct?] {
+              let strings: [String] = []
+              return [first([1, 2]), first(["a"]), first(strings)]
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		AssertValueSlicesEqual(
+			t,
+			inter,
+			[]interpreter.Value{
+				interpreter.NewUnmeteredSomeValueNonCopying(interpreter.NewUnmeteredIntValueFromInt64(1)),
+				interpreter.NewUnmeteredSomeValueNonCopying(interpreter.NewUnmeteredStringValue("a")),
+				interpreter.NilValue{},
+			},
+			arrayElements(inter, result.(*interpreter.ArrayValue)),
+		)
+	})
+
+	t.Run("array literal", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun wrap<T>(_ value: T): [T] {
+              return [value]
+          }
+
+          fun test(): [String] {
+              return wrap("a")
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			interpreter.VariableSizedStaticType{
+				Type: interpreter.PrimitiveStaticTypeString,
+			},
+			result.StaticType(inter),
+		)
+	})
+
+	t.Run("cast", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun cast<T>(_ value: AnyStruct): T? {
+              return value as? T
+          }
+
+          fun test(): [AnyStruct?] {
+              return [cast<Int>(1), cast<String>(1)]
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		AssertValueSlicesEqual(
+			t,
+			inter,
+			[]interpreter.Value{
+				interpreter.NewUnmeteredSomeValueNonCopying(interpreter.NewUnmeteredIntValueFromInt64(1)),
+				interpreter.NilValue{},
+			},
+			arrayElements(inter, result.(*interpreter.ArrayValue)),
+		)
+	})
+
+	t.Run("nested invocation", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun typeOf<T>(): Type {
+              return Type<T>()
+          }
+
+          fun typeOfArray<T>(): Type {
+              return typeOf<[T]>()
+          }
+
+          fun test(): Type {
+              return typeOfArray<Int8>()
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			interpreter.TypeValue{
+				Type: interpreter.VariableSizedStaticType{
+					Type: interpreter.PrimitiveStaticTypeInt8,
+				},
+			},
+			result,
+		)
+	})
+
+	t.Run("closure declared inside", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun wrap<T>(_ value: T): [T] {
+              let id = fun (x: T): T {
+                  return x
+              }
+              fun wrapped(_ x: T): [T] {
+                  return [x]
+              }
+              return wrapped(id(value))
+          }
+
+          fun test(): [Int] {
+              return wrap(1)
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		AssertValueSlicesEqual(
+			t,
+			inter,
+			[]interpreter.Value{
+				interpreter.NewUnmeteredIntValueFromInt64(1),
+			},
+			arrayElements(inter, result.(*interpreter.ArrayValue)),
+		)
+	})
+
+	t.Run("closure returned", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          fun constant<T>(_ value: T): ((): T) {
+              return fun (): T {
+                  return value
+              }
+          }
+
+          fun test(): String {
+              let f = constant("a")
+              return f()
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			interpreter.NewUnmeteredStringValue("a"),
+			result,
+		)
+	})
+}
+
+func TestInterpretGenericCompositeDeclaration(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("structure", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+
+              fun values(): [T] {
+                  return [self.value]
+              }
+          }
+
+          let box = Box(value: 1)
+          let values = box.values()
+          let type = box.getType()
+        `)
+
+		box := inter.Globals["box"].GetValue()
+
+		expectedType := interpreter.NewCompositeStaticTypeComputeTypeID(nil, TestLocation, "Box")
+		expectedType.TypeArguments = []interpreter.StaticType{
+			interpreter.PrimitiveStaticTypeInt,
+		}
+
+		assert.Equal(t,
+			expectedType,
+			box.StaticType(inter),
+		)
+
+		assert.Equal(t,
+			interpreter.VariableSizedStaticType{
+				Type: interpreter.PrimitiveStaticTypeInt,
+			},
+			inter.Globals["values"].GetValue().StaticType(inter),
+		)
+
+		assert.Equal(t,
+			interpreter.TypeValue{
+				Type: expectedType,
+			},
+			inter.Globals["type"].GetValue(),
+		)
+	})
+
+	t.Run("dynamic cast", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct Box<T> {
+              let value: T
+
+              init(value: T) {
+                  self.value = value
+              }
+          }
+
+          fun test(): [Bool] {
+              let box: AnyStruct = Box(value: 1)
+              return [
+                  box.isInstance(Type<Box<Int>>()),
+                  box.isInstance(Type<Box<String>>())
+              ]
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		AssertValueSlicesEqual(
+			t,
+			inter,
+			[]interpreter.Value{
+				interpreter.BoolValue(true),
+				interpreter.BoolValue(false),
+			},
+			arrayElements(inter, result.(*interpreter.ArrayValue)),
+		)
+	})
+
+	t.Run("resource, transfer", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          resource Vault<T: Integer> {
+              var balance: T
+
+              init(balance: T) {
+                  self.balance = balance
+              }
+          }
+
+          fun test(): UInt8 {
+              let vaults <- [<-create Vault(balance: 42 as UInt8)]
+              let vault <- vaults.removeFirst()
+              let balance = vault.balance
+              destroy vault
+              destroy vaults
+              return balance
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		AssertValuesEqual(
+			t,
+			inter,
+			interpreter.NewUnmeteredUInt8Value(42),
+			result,
+		)
+	})
+}
+
+func TestInterpretGenericCompositeStorage(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      struct Box<T> {
+          let value: T
+
+          init(value: T) {
+              self.value = value
+          }
+      }
+
+      fun test(): Box<String> {
+          return Box(value: "a")
+      }
+    `)
+
+	result, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	// Transfer the value to an account, which encodes it,
+	// and ensure the type arguments are retained
+
+	address := common.Address{0x1}
+
+	transferred := result.Transfer(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		atree.Address(address),
+		false,
+		nil,
+	).(*interpreter.CompositeValue)
+
+	assert.Equal(t,
+		[]interpreter.StaticType{
+			interpreter.PrimitiveStaticTypeString,
+		},
+		transferred.TypeArguments,
+	)
+
+	assert.True(t,
+		transferred.ConformsToStaticType(
+			inter,
+			interpreter.ReturnEmptyLocationRange,
+			interpreter.TypeConformanceResults{},
+		),
+	)
+}
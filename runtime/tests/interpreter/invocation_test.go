@@ -21,9 +21,13 @@ package interpreter_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+	. "github.com/onflow/cadence/runtime/tests/utils"
 )
 
 func TestInterpretFunctionInvocationCheckArgumentTypes(t *testing.T) {
@@ -41,3 +45,159 @@ func TestInterpretFunctionInvocationCheckArgumentTypes(t *testing.T) {
 
 	require.ErrorAs(t, err, &interpreter.ValueTransferTypeError{})
 }
+
+func TestInterpretCallStackDepthLimit(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+      fun recurse(_ n: Int): Int {
+          if n == 0 {
+              return 0
+          }
+          return recurse(n - 1) + 1
+      }
+
+      fun test(_ n: Int): Int {
+          return recurse(n)
+      }
+    `
+
+	newInterpreter := func(t *testing.T, limit uint64) *interpreter.Interpreter {
+		inter, err := parseCheckAndInterpretWithOptions(t,
+			code,
+			ParseCheckAndInterpretOptions{
+				Options: []interpreter.Option{
+					interpreter.WithCallStackDepthLimit(limit),
+				},
+			},
+		)
+		require.NoError(t, err)
+		return inter
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := newInterpreter(t, 10)
+
+		// test + 9 invocations of recurse
+		value, err := inter.Invoke("test", interpreter.NewUnmeteredIntValueFromInt64(8))
+		require.NoError(t, err)
+
+		AssertValuesEqual(
+			t,
+			inter,
+			interpreter.NewUnmeteredIntValueFromInt64(8),
+			value,
+		)
+
+		assert.Equal(t, uint64(0), inter.CallStack.Depth())
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := newInterpreter(t, 10)
+
+		// test + 10 invocations of recurse
+		_, err := inter.Invoke("test", interpreter.NewUnmeteredIntValueFromInt64(9))
+		require.Error(t, err)
+
+		var callStackLimitExceededErr interpreter.CallStackLimitExceededError
+		require.ErrorAs(t, err, &callStackLimitExceededErr)
+
+		assert.Equal(t, uint64(10), callStackLimitExceededErr.Limit)
+		assert.Equal(t, TestLocation, callStackLimitExceededErr.Location)
+		assert.Equal(t, 6, callStackLimitExceededErr.StartPosition().Line)
+
+		// The depth is restored after the failed invocation
+
+		assert.Equal(t, uint64(0), inter.CallStack.Depth())
+
+		_, err = inter.Invoke("test", interpreter.NewUnmeteredIntValueFromInt64(1))
+		require.NoError(t, err)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := newInterpreter(t, 0)
+
+		_, err := inter.Invoke("test", interpreter.NewUnmeteredIntValueFromInt64(1000))
+		require.NoError(t, err)
+	})
+
+	t.Run("host function", func(t *testing.T) {
+
+		t.Parallel()
+
+		// Recursion through a host function, which invokes the given function
+
+		invokeFunctionType := &sema.FunctionType{
+			Parameters: []*sema.Parameter{
+				{
+					Label:      sema.ArgumentLabelNotRequired,
+					Identifier: "f",
+					TypeAnnotation: sema.NewTypeAnnotation(
+						&sema.FunctionType{
+							ReturnTypeAnnotation: sema.NewTypeAnnotation(sema.VoidType),
+						},
+					),
+				},
+			},
+			ReturnTypeAnnotation: sema.NewTypeAnnotation(sema.VoidType),
+		}
+
+		invokeFunction := stdlib.NewStandardLibraryFunction(
+			"invoke",
+			invokeFunctionType,
+			"",
+			func(invocation interpreter.Invocation) interpreter.Value {
+				function, ok := invocation.Arguments[0].(interpreter.FunctionValue)
+				require.True(t, ok)
+
+				_, err := invocation.Interpreter.InvokeFunctionValue(
+					function,
+					nil,
+					nil,
+					nil,
+					invocation.GetLocationRange(),
+				)
+				if err != nil {
+					panic(err)
+				}
+
+				return interpreter.NewUnmeteredVoidValue()
+			},
+		)
+
+		valueDeclarations := stdlib.StandardLibraryFunctions{invokeFunction}
+
+		inter, err := parseCheckAndInterpretWithOptions(t,
+			`
+              fun test() {
+                  invoke(test)
+              }
+            `,
+			ParseCheckAndInterpretOptions{
+				CheckerOptions: []sema.Option{
+					sema.WithPredeclaredValues(valueDeclarations.ToSemaValueDeclarations()),
+				},
+				Options: []interpreter.Option{
+					interpreter.WithPredeclaredValues(valueDeclarations.ToInterpreterValueDeclarations()),
+					interpreter.WithCallStackDepthLimit(10),
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+
+		require.ErrorAs(t, err, &interpreter.CallStackLimitExceededError{})
+	})
+}
@@ -703,8 +703,8 @@ func TestInterpretSimpleCompositeMetering(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Equal(t, uint64(1), meter.getMemory(common.MemoryKindSimpleCompositeValueBase))
-		// AuthAccount has 18 fields
-		assert.Equal(t, uint64(18), meter.getMemory(common.MemoryKindSimpleCompositeValue))
+		// AuthAccount has 24 fields
+		assert.Equal(t, uint64(24), meter.getMemory(common.MemoryKindSimpleCompositeValue))
 	})
 
 	t.Run("public account", func(t *testing.T) {
@@ -723,8 +723,8 @@ func TestInterpretSimpleCompositeMetering(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Equal(t, uint64(1), meter.getMemory(common.MemoryKindSimpleCompositeValueBase))
-		// PublicAccount has 9 fields
-		assert.Equal(t, uint64(9), meter.getMemory(common.MemoryKindSimpleCompositeValue))
+		// PublicAccount has 11 fields
+		assert.Equal(t, uint64(11), meter.getMemory(common.MemoryKindSimpleCompositeValue))
 	})
 }
 
@@ -7406,6 +7406,31 @@ func TestInterpretStringMetering(t *testing.T) {
 	})
 }
 
+func TestInterpretStringTemplateMetering(t *testing.T) {
+
+	t.Parallel()
+
+	script := `
+        pub fun main() {
+            let x = "cd"
+            let y = "a\(x)b"
+        }
+    `
+	meter := newTestMemoryGauge()
+	inter := parseCheckAndInterpretWithMemoryMetering(t, script, meter)
+
+	_, err := inter.Invoke("main")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), meter.getMemory(common.MemoryKindStringTemplateExpression))
+
+	// literal: 1 + 2 * " + 2 (cd)
+	// + template start: 1 + 4 ("a\()
+	// + template end: 1 + 3 ()b")
+	// + result: 1 + 4 (acdb)
+	assert.Equal(t, uint64(19), meter.getMemory(common.MemoryKindStringValue))
+}
+
 func TestInterpretCharacterMetering(t *testing.T) {
 	t.Parallel()
 
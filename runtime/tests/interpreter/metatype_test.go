@@ -296,6 +296,227 @@ func TestInterpretMetaTypeIdentifier(t *testing.T) {
 	})
 }
 
+func TestInterpretMetaTypeReflection(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("composite", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct interface I {}
+
+          struct interface J {}
+
+          struct S: I, J {
+              pub let a: Int
+              pub(set) var b: String?
+              priv let c: Bool
+              access(contract) let d: Int
+              access(account) let e: Int
+
+              init() {
+                  self.a = 1
+                  self.b = nil
+                  self.c = true
+                  self.d = 2
+                  self.e = 3
+              }
+
+              pub fun foo(_ x: Int): String {
+                  return x.toString()
+              }
+
+              priv fun bar() {}
+          }
+
+          fun test(): [Bool] {
+              let type = Type<S>()
+              let fields = type.fields
+              let functions = type.functions
+              let conformances = type.conformances
+              return [
+                  fields.length == 2,
+                  fields["a"] == Type<Int>(),
+                  fields["b"] == Type<String?>(),
+                  fields["c"] == nil,
+                  fields["d"] == nil,
+                  fields["e"] == nil,
+                  functions.length == 3,
+                  functions["foo"] == "((_ x: Int): String)",
+                  functions["isInstance"] != nil,
+                  functions["getType"] != nil,
+                  functions["bar"] == nil,
+                  conformances.length == 2,
+                  conformances[0].identifier == "S.test.I",
+                  conformances[1].identifier == "S.test.J"
+              ]
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		array, ok := result.(*interpreter.ArrayValue)
+		require.True(t, ok)
+
+		for i := 0; i < array.Count(); i++ {
+			assert.Equal(t,
+				interpreter.BoolValue(true),
+				array.Get(inter, interpreter.ReturnEmptyLocationRange, i),
+				"element %d",
+				i,
+			)
+		}
+	})
+
+	t.Run("resource", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          resource R {
+              pub let id: UInt8
+
+              init() {
+                  self.id = 1
+              }
+          }
+
+          let fields = Type<@R>().fields
+          let hasOwner = fields["owner"] == Type<PublicAccount?>()
+          let hasUUID = fields["uuid"] == Type<UInt64>()
+          let hasID = fields["id"] == Type<UInt8>()
+        `)
+
+		for _, name := range []string{"hasOwner", "hasUUID", "hasID"} {
+			assert.Equal(t,
+				interpreter.BoolValue(true),
+				inter.Globals[name].GetValue(),
+				name,
+			)
+		}
+	})
+
+	t.Run("interface", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          struct interface I {
+              pub let a: Int
+
+              pub fun foo(): Int
+          }
+
+          struct S: I {
+              pub let a: Int
+
+              init() {
+                  self.a = 1
+              }
+
+              pub fun foo(): Int {
+                  return self.a
+              }
+          }
+
+          let type = Type<S>().conformances[0]
+          let fieldCount = type.fields.length
+          let functionCount = type.functions.length
+          let conformanceCount = type.conformances.length
+        `)
+
+		AssertValuesEqual(
+			t,
+			inter,
+			interpreter.NewUnmeteredIntValueFromInt64(1),
+			inter.Globals["fieldCount"].GetValue(),
+		)
+		// foo, and the predeclared isInstance and getType
+		AssertValuesEqual(
+			t,
+			inter,
+			interpreter.NewUnmeteredIntValueFromInt64(3),
+			inter.Globals["functionCount"].GetValue(),
+		)
+		AssertValuesEqual(
+			t,
+			inter,
+			interpreter.NewUnmeteredIntValueFromInt64(0),
+			inter.Globals["conformanceCount"].GetValue(),
+		)
+	})
+
+	t.Run("non-composite", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          let type = Type<[Int]>()
+          let fieldCount = type.fields.length
+          let functionCount = type.functions.length
+          let conformanceCount = type.conformances.length
+        `)
+
+		for _, name := range []string{"fieldCount", "functionCount", "conformanceCount"} {
+			AssertValuesEqual(
+				t,
+				inter,
+				interpreter.NewUnmeteredIntValueFromInt64(0),
+				inter.Globals[name].GetValue(),
+			)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+
+		t.Parallel()
+
+		valueDeclarations := stdlib.StandardLibraryValues{
+			{
+				Name: "unknownType",
+				Type: sema.MetaType,
+				ValueFactory: func(i *interpreter.Interpreter) interpreter.Value {
+					return interpreter.TypeValue{
+						Type: nil,
+					}
+				},
+				Kind: common.DeclarationKindConstant,
+			},
+		}
+
+		semaValueDeclarations := valueDeclarations.ToSemaValueDeclarations()
+		interpreterValueDeclarations := valueDeclarations.ToInterpreterValueDeclarations()
+
+		inter, err := parseCheckAndInterpretWithOptions(t,
+			`
+              let fieldCount = unknownType.fields.length
+              let conformanceCount = unknownType.conformances.length
+            `,
+			ParseCheckAndInterpretOptions{
+				CheckerOptions: []sema.Option{
+					sema.WithPredeclaredValues(semaValueDeclarations),
+				},
+				Options: []interpreter.Option{
+					interpreter.WithPredeclaredValues(interpreterValueDeclarations),
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		for _, name := range []string{"fieldCount", "conformanceCount"} {
+			AssertValuesEqual(
+				t,
+				inter,
+				interpreter.NewUnmeteredIntValueFromInt64(0),
+				inter.Globals[name].GetValue(),
+			)
+		}
+	})
+}
+
 func TestInterpretIsInstance(t *testing.T) {
 
 	t.Parallel()
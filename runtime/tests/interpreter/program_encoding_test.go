@@ -0,0 +1,460 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/tests/checker"
+	. "github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func encodeAndDecodeProgram(
+	t *testing.T,
+	checker *sema.Checker,
+	code string,
+	resolve sema.ElaborationResolver,
+) *interpreter.Program {
+
+	data, err := interpreter.EncodeProgram(
+		interpreter.ProgramFromChecker(checker),
+		checker.Location,
+		[]byte(code),
+	)
+	require.NoError(t, err)
+
+	program, err := interpreter.DecodeProgram(data, []byte(code), resolve)
+	require.NoError(t, err)
+
+	return program
+}
+
+func TestInterpretEncodedProgram(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+      pub struct interface HasID {
+          pub let id: Int
+
+          pub fun double(): Int {
+              post { result == before(self.id) * 2 }
+          }
+      }
+
+      pub struct S: HasID {
+          pub let id: Int
+
+          init(id: Int) {
+              self.id = id
+          }
+
+          pub fun double(): Int {
+              return self.id * 2
+          }
+      }
+
+      pub resource R {
+          pub var count: Int
+
+          init() {
+              self.count = 0
+          }
+
+          pub fun increment() {
+              self.count = self.count + 1
+          }
+      }
+
+      pub enum Color: UInt8 {
+          pub case red
+          pub case green
+      }
+
+      pub event Tested(value: Int)
+
+      typealias Numbers = [Int]
+
+      pub struct Box<T> {
+          pub let value: T
+
+          init(value: T) {
+              self.value = value
+          }
+
+          pub fun wrap(): Box<[T]> {
+              return Box<[T]>(value: [self.value])
+          }
+      }
+
+      pub fun first<T>(_ values: [T]): T {
+          return values[0]
+      }
+
+      pub fun apply(_ f: ((Int): Int), _ x: Int): Int {
+          return f(x)
+      }
+
+      pub fun test(): [AnyStruct] {
+          pre { true }
+
+          let s = S(id: 21)
+
+          let r <- create R()
+          r.increment()
+          let ref = &r as &R
+          ref.increment()
+          let count = r.count
+          destroy r
+
+          let numbers: Numbers = [3, 1, 2]
+          var sum = 0
+          for n in numbers {
+              sum = sum + n
+          }
+
+          let dict: {String: Int} = {"a": 1}
+          let optional: Int? = dict["a"]
+
+          let color = Color.green
+          var branch = 0
+          switch color {
+              case Color.red:
+                  branch = 1
+              case Color.green:
+                  branch = 2
+          }
+
+          let casted = (s as AnyStruct) as? {HasID}
+
+          emit Tested(value: branch)
+
+          return [
+              s.double(),
+              count,
+              sum,
+              optional ?? 0,
+              color.rawValue,
+              "x\(sum)y",
+              1.5 + 0.25,
+              apply(fun (x: Int): Int { return x * 2 }, 4),
+              branch,
+              casted != nil,
+              numbers.contains(2),
+              Type<S>().identifier,
+              Box(value: 3).wrap().value,
+              first(["first"]),
+              Type<Box<Int>>().identifier
+          ]
+      }
+    `
+
+	checker, err := checker.ParseAndCheck(t, code)
+	require.NoError(t, err)
+
+	interpret := func(program *interpreter.Program) (interpreter.Value, []string) {
+		var events []string
+		var uuid uint64
+
+		inter, err := interpreter.NewInterpreter(
+			program,
+			TestLocation,
+			interpreter.WithStorage(newUnmeteredInMemoryStorage()),
+			interpreter.WithUUIDHandler(func() (uint64, error) {
+				uuid++
+				return uuid, nil
+			}),
+			interpreter.WithOnEventEmittedHandler(
+				func(
+					_ *interpreter.Interpreter,
+					_ func() interpreter.LocationRange,
+					event *interpreter.CompositeValue,
+					_ *sema.CompositeType,
+				) error {
+					events = append(events, event.String())
+					return nil
+				},
+			),
+		)
+		require.NoError(t, err)
+
+		err = inter.Interpret()
+		require.NoError(t, err)
+
+		value, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		return value, events
+	}
+
+	expectedValue, expectedEvents := interpret(interpreter.ProgramFromChecker(checker))
+
+	program := encodeAndDecodeProgram(t, checker, code, nil)
+
+	value, events := interpret(program)
+
+	assert.Equal(t, expectedValue.String(), value.String())
+	assert.Equal(t, expectedEvents, events)
+
+	// The global types are decoded completely
+
+	variable, ok := program.Elaboration.GlobalTypes.Get("S")
+	require.True(t, ok)
+
+	structType := variable.Type.(*sema.CompositeType)
+	assert.Equal(t, common.TypeID("S.test.S"), structType.ID())
+	assert.Equal(t, []string{"id"}, structType.Fields)
+	assert.Equal(t, "HasID", structType.ExplicitInterfaceConformances[0].Identifier)
+
+	member, ok := structType.Members.Get("double")
+	require.True(t, ok)
+	assert.Same(t, structType, member.ContainerType)
+	assert.Equal(t,
+		"((): Int)",
+		member.TypeAnnotation.Type.QualifiedString(),
+	)
+
+	// Generic composite types keep their type parameters,
+	// and instantiations refer to them
+
+	variable, ok = program.Elaboration.GlobalTypes.Get("Box")
+	require.True(t, ok)
+
+	boxType := variable.Type.(*sema.CompositeType)
+	require.Len(t, boxType.TypeParameters(), 1)
+
+	member, ok = boxType.Members.Get("wrap")
+	require.True(t, ok)
+
+	returnType := member.TypeAnnotation.Type.(*sema.FunctionType).ReturnTypeAnnotation.Type
+	assert.Equal(t, common.TypeID("S.test.Box<[T]>"), returnType.ID())
+	assert.Same(t, boxType, returnType.(*sema.CompositeType).BaseType())
+}
+
+func TestInterpretEncodedProgramImport(t *testing.T) {
+
+	t.Parallel()
+
+	const importedCode = `
+      pub struct interface Shape {
+          pub fun area(): Int
+      }
+
+      pub struct Square: Shape {
+          pub let side: Int
+
+          init(side: Int) {
+              self.side = side
+          }
+
+          pub fun area(): Int {
+              return self.side * self.side
+          }
+      }
+
+      pub fun makeSquare(_ side: Int): Square {
+          return Square(side: side)
+      }
+    `
+
+	const importingCode = `
+      import Square, Shape, makeSquare from "imported"
+
+      pub fun test(): Int {
+          let shape: {Shape} = makeSquare(3)
+          return shape.area() + Square(side: 2).area()
+      }
+    `
+
+	importedChecker, err := checker.ParseAndCheckWithOptions(t,
+		importedCode,
+		checker.ParseAndCheckOptions{
+			Location: ImportedLocation,
+		},
+	)
+	require.NoError(t, err)
+
+	importedProgram := encodeAndDecodeProgram(t, importedChecker, importedCode, nil)
+
+	// Check the importing program against the decoded imported program
+
+	importingChecker, err := checker.ParseAndCheckWithOptions(t,
+		importingCode,
+		checker.ParseAndCheckOptions{
+			Options: []sema.Option{
+				sema.WithImportHandler(
+					func(_ *sema.Checker, _ common.Location, _ ast.Range) (sema.Import, error) {
+						return sema.ElaborationImport{
+							Elaboration: importedProgram.Elaboration,
+						}, nil
+					},
+				),
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	importingProgram := encodeAndDecodeProgram(t,
+		importingChecker,
+		importingCode,
+		func(location common.Location) (*sema.Elaboration, error) {
+			assert.Equal(t, ImportedLocation, location)
+			return importedProgram.Elaboration, nil
+		},
+	)
+
+	inter, err := interpreter.NewInterpreter(
+		importingProgram,
+		TestLocation,
+		interpreter.WithStorage(newUnmeteredInMemoryStorage()),
+		interpreter.WithImportLocationHandler(
+			func(inter *interpreter.Interpreter, location common.Location) interpreter.Import {
+				subInterpreter, err := inter.NewSubInterpreter(importedProgram, location)
+				if err != nil {
+					panic(err)
+				}
+
+				return interpreter.InterpreterImport{
+					Interpreter: subInterpreter,
+				}
+			},
+		),
+	)
+	require.NoError(t, err)
+
+	err = inter.Interpret()
+	require.NoError(t, err)
+
+	value, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	AssertValuesEqual(
+		t,
+		inter,
+		interpreter.NewUnmeteredIntValueFromInt64(13),
+		value,
+	)
+}
+
+func TestDecodeOutdatedProgram(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+      pub fun test(): Int {
+          return 1
+      }
+    `
+
+	checker, err := checker.ParseAndCheck(t, code)
+	require.NoError(t, err)
+
+	data, err := interpreter.EncodeProgram(
+		interpreter.ProgramFromChecker(checker),
+		checker.Location,
+		[]byte(code),
+	)
+	require.NoError(t, err)
+
+	header, err := interpreter.DecodeProgramHeader(data)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		interpreter.EncodedProgramHeader{
+			Version:  interpreter.ProgramEncodingVersion,
+			CodeHash: interpreter.ProgramCodeHash([]byte(code)),
+			Location: TestLocation,
+		},
+		header,
+	)
+
+	_, err = interpreter.DecodeProgram(data, []byte("pub fun test() {}"), nil)
+	require.Error(t, err)
+
+	require.ErrorAs(t, err, &interpreter.ProgramCodeHashMismatchError{})
+}
+
+func TestDecodeProgramWithOtherEncodingVersion(t *testing.T) {
+
+	t.Parallel()
+
+	// Programs encoded with the previous version cannot contain generic declarations,
+	// so they must be rejected, and the program must be parsed and checked again
+
+	const code = `
+      pub struct Box<T> {
+          pub let value: T
+
+          init(value: T) {
+              self.value = value
+          }
+      }
+
+      pub fun test(): Int {
+          return Box(value: 1).value
+      }
+    `
+
+	checker, err := checker.ParseAndCheck(t, code)
+	require.NoError(t, err)
+
+	data, err := interpreter.EncodeProgram(
+		interpreter.ProgramFromChecker(checker),
+		checker.Location,
+		[]byte(code),
+	)
+	require.NoError(t, err)
+
+	// The program can be decoded with the current version
+
+	program, err := interpreter.DecodeProgram(data, []byte(code), nil)
+	require.NoError(t, err)
+
+	variable, ok := program.Elaboration.GlobalTypes.Get("Box")
+	require.True(t, ok)
+	require.Len(t, variable.Type.(*sema.CompositeType).TypeParameters(), 1)
+
+	// The encoded program is an array, and the version is its first element.
+	// Small versions are encoded in a single byte.
+
+	const versionIndex = 1
+	require.Equal(t, byte(interpreter.ProgramEncodingVersion), data[versionIndex])
+
+	data[versionIndex] = 1
+
+	_, err = interpreter.DecodeProgram(data, []byte(code), nil)
+	require.Error(t, err)
+
+	var versionMismatchErr interpreter.ProgramEncodingVersionMismatchError
+	require.ErrorAs(t, err, &versionMismatchErr)
+
+	assert.Equal(t,
+		interpreter.ProgramEncodingVersionMismatchError{
+			Version: 1,
+		},
+		versionMismatchErr,
+	)
+}
@@ -19,8 +19,10 @@
 package interpreter_test
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/cadence/runtime/common"
@@ -208,6 +210,231 @@ func TestInterpretStringToLower(t *testing.T) {
 	)
 }
 
+func TestInterpretStringToUpper(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test(): String {
+          return "Flowers für Dich".toUpper()
+      }
+    `)
+
+	result, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	require.Equal(t,
+		interpreter.NewUnmeteredStringValue("FLOWERS FÜR DICH"),
+		result,
+	)
+}
+
+func TestInterpretStringSplit(t *testing.T) {
+
+	t.Parallel()
+
+	type test struct {
+		str       string
+		separator string
+		result    string
+	}
+
+	tests := []test{
+		{"a,b,c", ",", `["a", "b", "c"]`},
+		{"a, b, c", ", ", `["a", "b", "c"]`},
+		{",a,", ",", `["", "a", ""]`},
+		{"abc", ",", `["abc"]`},
+		{"", ",", `[""]`},
+		{"abc", "", `["a", "b", "c"]`},
+		// The separator does not match a part of a character:
+		// "e\u{301}" is the single character "é"
+		{"ae\\u{301}b", "e", `["ae\u{301}b"]`},
+		{"ae\\u{301}be\\u{301}", "e\\u{301}", `["a", "b", ""]`},
+		{"🇨🇦🇺🇸", "", `["\u{1f1e8}\u{1f1e6}", "\u{1f1fa}\u{1f1f8}"]`},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.str, func(t *testing.T) {
+
+			t.Parallel()
+
+			inter := parseCheckAndInterpret(t,
+				fmt.Sprintf(
+					`
+                      fun test(): [String] {
+                          return "%s".split(separator: "%s")
+                      }
+                    `,
+					test.str,
+					test.separator,
+				),
+			)
+
+			result, err := inter.Invoke("test")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.result, result.String())
+		})
+	}
+}
+
+func TestInterpretStringJoin(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test(): [String] {
+          return [
+              String.join(["a", "b", "c"], separator: ", "),
+              String.join(["a"], separator: ","),
+              String.join([], separator: ","),
+              String.join("a,b".split(separator: ","), separator: "")
+          ]
+      }
+    `)
+
+	result, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	assert.Equal(t, `["a, b, c", "a", "", "ab"]`, result.String())
+}
+
+func TestInterpretStringReplaceAll(t *testing.T) {
+
+	t.Parallel()
+
+	type test struct {
+		str         string
+		original    string
+		replacement string
+		result      string
+	}
+
+	tests := []test{
+		{"aXbXc", "X", "Y", "aYbYc"},
+		{"aXXb", "XX", "", "ab"},
+		{"aaa", "aa", "b", "ba"},
+		{"abc", "X", "Y", "abc"},
+		{"ab", "", "-", "-a-b-"},
+		// The original string does not match a part of a character
+		{"ae\\u{301}e", "e", "E", "ae\\u{301}E"},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.str, func(t *testing.T) {
+
+			t.Parallel()
+
+			inter := parseCheckAndInterpret(t,
+				fmt.Sprintf(
+					`
+                      fun test(): Bool {
+                          return "%s".replaceAll(of: "%s", with: "%s") == "%s"
+                      }
+                    `,
+					test.str,
+					test.original,
+					test.replacement,
+					test.result,
+				),
+			)
+
+			result, err := inter.Invoke("test")
+			require.NoError(t, err)
+
+			assert.Equal(t, interpreter.BoolValue(true), result)
+		})
+	}
+}
+
+func TestInterpretStringContainsAndIndex(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun contains(): [Bool] {
+          return [
+              "abc".contains("bc"),
+              "abc".contains(""),
+              "abc".contains("x"),
+              "ae\u{301}".contains("e"),
+              "e\u{301}b".contains("\u{301}b")
+          ]
+      }
+
+      fun index(): [Int?] {
+          return [
+              "abc".index(of: "bc"),
+              "abc".index(of: ""),
+              "abc".index(of: "x"),
+              "e\u{301}bc".index(of: "b"),
+              "ae\u{301}e".index(of: "e")
+          ]
+      }
+    `)
+
+	result, err := inter.Invoke("contains")
+	require.NoError(t, err)
+
+	assert.Equal(t, "[true, true, false, false, false]", result.String())
+
+	result, err = inter.Invoke("index")
+	require.NoError(t, err)
+
+	assert.Equal(t, "[1, 0, nil, 1, 2]", result.String())
+}
+
+func TestInterpretStringTrim(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test(): [String] {
+          return [
+              "  abc \t\n".trim(),
+              "abc".trim(),
+              "   ".trim(),
+              " a b ".trim()
+          ]
+      }
+    `)
+
+	result, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	assert.Equal(t, `["abc", "abc", "", "a b"]`, result.String())
+}
+
+func TestInterpretStringFunctionsComputation(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test() {
+          let str = "a,b,c"
+          str.split(separator: ",")
+          str.contains("c")
+      }
+    `)
+
+	var computation uint
+	inter.SetOnMeterComputationHandler(func(compKind common.ComputationKind, intensity uint) {
+		if compKind == common.ComputationKindStringManipulation {
+			computation += intensity
+		}
+	})
+
+	_, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	// Each function is metered by the length of the string
+	assert.Equal(t, uint(10), computation)
+}
+
 func TestInterpretStringAccess(t *testing.T) {
 
 	t.Parallel()
@@ -343,3 +570,30 @@ func TestInterpretCompareCharacters(t *testing.T) {
 		inter.Globals["z"].GetValue(),
 	)
 }
+
+func TestInterpretStringTemplate(t *testing.T) {
+
+	t.Parallel()
+
+	inter := parseCheckAndInterpret(t, `
+      fun test(): String {
+          let name = "World"
+          let c: Character = "!"
+          let count = 2
+          let amount: UFix64 = 1.5
+          let address: Address = 0x1
+          let path = /public/foo
+          return "Hello \(name)\(c) \(count + 1) \(amount) \(address) \(path) \("nested \(count)")"
+      }
+    `)
+
+	result, err := inter.Invoke("test")
+	require.NoError(t, err)
+
+	RequireValuesEqual(
+		t,
+		inter,
+		interpreter.NewUnmeteredStringValue("Hello World! 3 1.50000000 0x0000000000000001 /public/foo nested 2"),
+		result,
+	)
+}
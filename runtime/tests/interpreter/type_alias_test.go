@@ -0,0 +1,107 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/interpreter"
+	. "github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestInterpretTypeAlias(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("top-level", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter := parseCheckAndInterpret(t, `
+          typealias Amounts = [UFix64]
+
+          fun test(): Bool {
+              let amounts: Amounts = [1.0, 2.0]
+              return amounts.getType() == Type<[UFix64]>()
+                  && Type<Amounts>() == Type<[UFix64]>()
+          }
+        `)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		RequireValuesEqual(
+			t,
+			inter,
+			interpreter.BoolValue(true),
+			result,
+		)
+	})
+
+	t.Run("contract", func(t *testing.T) {
+
+		t.Parallel()
+
+		inter, err := parseCheckAndInterpretWithOptions(t,
+			`
+              contract C {
+
+                  pub typealias Vault = R
+
+                  pub resource R {
+                      pub let balance: UFix64
+
+                      init(balance: UFix64) {
+                          self.balance = balance
+                      }
+                  }
+
+                  pub fun createVault(balance: UFix64): @Vault {
+                      return <-create R(balance: balance)
+                  }
+              }
+
+              fun test(): Bool {
+                  let vault: @C.Vault <- C.createVault(balance: 1.0)
+                  let isR = vault.getType() == Type<@C.R>()
+                  destroy vault
+                  return isR
+              }
+	        `,
+			ParseCheckAndInterpretOptions{
+				Options: []interpreter.Option{
+					makeContractValueHandler(nil, nil, nil),
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+
+		RequireValuesEqual(
+			t,
+			inter,
+			interpreter.BoolValue(true),
+			result,
+		)
+	})
+}
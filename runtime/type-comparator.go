@@ -27,9 +27,26 @@ var _ ast.TypeEqualityChecker = &TypeComparator{}
 
 type TypeComparator struct {
 	RootDeclIdentifier *Identifier
+
+	// ExpectedTypeAliases and FoundTypeAliases are the types aliased
+	// by the type aliases of the expected and the found types, by name.
+	// Type aliases are transparent, i.e. a type alias is equal to the aliased type.
+	ExpectedTypeAliases map[string]ast.Type
+	FoundTypeAliases    map[string]ast.Type
 }
 
 func (c *TypeComparator) CheckNominalTypeEquality(expected *ast.NominalType, found ast.Type) error {
+	expandedExpectedType := c.expandTypeAlias(expected, c.ExpectedTypeAliases)
+	if expandedExpectedType != expected {
+		err := expandedExpectedType.CheckEqual(found, c)
+		if err != nil {
+			return getTypeMismatchError(expected, found)
+		}
+		return nil
+	}
+
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundNominalType, ok := found.(*ast.NominalType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -45,6 +62,8 @@ func (c *TypeComparator) CheckNominalTypeEquality(expected *ast.NominalType, fou
 }
 
 func (c *TypeComparator) CheckOptionalTypeEquality(expected *ast.OptionalType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundOptionalType, ok := found.(*ast.OptionalType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -54,6 +73,8 @@ func (c *TypeComparator) CheckOptionalTypeEquality(expected *ast.OptionalType, f
 }
 
 func (c *TypeComparator) CheckVariableSizedTypeEquality(expected *ast.VariableSizedType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundVarSizedType, ok := found.(*ast.VariableSizedType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -63,6 +84,8 @@ func (c *TypeComparator) CheckVariableSizedTypeEquality(expected *ast.VariableSi
 }
 
 func (c *TypeComparator) CheckConstantSizedTypeEquality(expected *ast.ConstantSizedType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundConstSizedType, ok := found.(*ast.ConstantSizedType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -79,6 +102,8 @@ func (c *TypeComparator) CheckConstantSizedTypeEquality(expected *ast.ConstantSi
 }
 
 func (c *TypeComparator) CheckDictionaryTypeEquality(expected *ast.DictionaryType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundDictionaryType, ok := found.(*ast.DictionaryType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -93,6 +118,8 @@ func (c *TypeComparator) CheckDictionaryTypeEquality(expected *ast.DictionaryTyp
 }
 
 func (c *TypeComparator) CheckRestrictedTypeEquality(expected *ast.RestrictedType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundRestrictedType, ok := found.(*ast.RestrictedType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -132,6 +159,8 @@ func (c *TypeComparator) CheckRestrictedTypeEquality(expected *ast.RestrictedTyp
 }
 
 func (c *TypeComparator) CheckInstantiationTypeEquality(expected *ast.InstantiationType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundInstType, ok := found.(*ast.InstantiationType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -154,6 +183,8 @@ func (c *TypeComparator) CheckInstantiationTypeEquality(expected *ast.Instantiat
 }
 
 func (c *TypeComparator) CheckFunctionTypeEquality(expected *ast.FunctionType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	foundFuncType, ok := found.(*ast.FunctionType)
 	if !ok || len(expected.ParameterTypeAnnotations) != len(foundFuncType.ParameterTypeAnnotations) {
 		return getTypeMismatchError(expected, found)
@@ -171,6 +202,8 @@ func (c *TypeComparator) CheckFunctionTypeEquality(expected *ast.FunctionType, f
 }
 
 func (c *TypeComparator) CheckReferenceTypeEquality(expected *ast.ReferenceType, found ast.Type) error {
+	found = c.expandTypeAlias(found, c.FoundTypeAliases)
+
 	refType, ok := found.(*ast.ReferenceType)
 	if !ok {
 		return getTypeMismatchError(expected, found)
@@ -227,6 +260,51 @@ func (c *TypeComparator) checkIdentifierEquality(
 	return identifiersEqual(simpleNominalType.NestedIdentifiers, qualifiedNominalType.NestedIdentifiers[1:])
 }
 
+// expandTypeAlias returns the type aliased by the given type,
+// if it refers to one of the given type aliases, either by its simple name (`Alias`),
+// or by its name qualified with the root declaration (`C.Alias`).
+// Otherwise, the given type is returned.
+//
+func (c *TypeComparator) expandTypeAlias(ty ast.Type, typeAliases map[string]ast.Type) ast.Type {
+
+	// Type aliases can only refer to type aliases declared before them,
+	// so there are at most as many expansions as there are type aliases
+
+	for i := 0; i < len(typeAliases); i++ {
+		nominalType, ok := ty.(*ast.NominalType)
+		if !ok {
+			return ty
+		}
+
+		var name string
+
+		switch len(nominalType.NestedIdentifiers) {
+		case 0:
+			name = nominalType.Identifier.Identifier
+
+		case 1:
+			if c.RootDeclIdentifier == nil ||
+				nominalType.Identifier.Identifier != c.RootDeclIdentifier.Identifier {
+
+				return ty
+			}
+			name = nominalType.NestedIdentifiers[0].Identifier
+
+		default:
+			return ty
+		}
+
+		aliasedType, ok := typeAliases[name]
+		if !ok {
+			return ty
+		}
+
+		ty = aliasedType
+	}
+
+	return ty
+}
+
 func identifiersEqual(expected []ast.Identifier, found []ast.Identifier) bool {
 	if len(expected) != len(found) {
 		return false
@@ -0,0 +1,47 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package analyzers provides analyzers for Cadence programs,
+// which can be run with the analysis package.
+//
+package analyzers
+
+import (
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+// SecurityAnalyzers are the analyzers which detect security issues, by name
+//
+var SecurityAnalyzers = map[string]*analysis.Analyzer{
+	"public-mutable-field":   PublicMutableFieldAnalyzer,
+	"public-capability":      PublicCapabilityAnalyzer,
+	"auth-account-parameter": AuthAccountParameterAnalyzer,
+	"auth-reference-return":  AuthReferenceReturnAnalyzer,
+}
+
+// Analyzers are the analyzers of this package, by name
+//
+var Analyzers = func() map[string]*analysis.Analyzer {
+	analyzers := map[string]*analysis.Analyzer{
+		"unnecessary-force": UnnecessaryForceAnalyzer,
+	}
+	for name, analyzer := range SecurityAnalyzers { //nolint:maprangecheck
+		analyzers[name] = analyzer
+	}
+	return analyzers
+}()
@@ -0,0 +1,112 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+// AuthAccountParameterAnalyzer reports parameters of type AuthAccount.
+//
+// Passing an AuthAccount to a function gives the function full access to the account,
+// so functions should rather receive the capabilities they need.
+// Parameters of the prepare functions of transactions are not reported.
+//
+var AuthAccountParameterAnalyzer = &analysis.Analyzer{
+	Description: "Detects functions which have AuthAccount parameters",
+	Requires: []*analysis.Analyzer{
+		analysis.InspectorAnalyzer,
+	},
+	Run: func(pass *analysis.Pass) interface{} {
+		inspector := pass.ResultOf[analysis.InspectorAnalyzer].(*ast.Inspector)
+
+		program := pass.Program
+		location := program.Location
+		elaboration := program.Elaboration
+
+		checkParameters := func(parameterList *ast.ParameterList, functionType *sema.FunctionType) {
+			if parameterList == nil || functionType == nil {
+				return
+			}
+
+			for i, parameter := range parameterList.Parameters {
+				if i >= len(functionType.Parameters) {
+					return
+				}
+
+				parameterType := functionType.Parameters[i].TypeAnnotation.Type
+				if !isAuthAccountType(parameterType) {
+					continue
+				}
+
+				pass.Report(
+					analysis.Diagnostic{
+						Location: location,
+						Range:    parameter.Range,
+						Category: SecurityCategory,
+						Message: fmt.Sprintf(
+							"parameter `%s` has type `%s`, which gives the function full access to the account",
+							parameter.Identifier.Identifier,
+							parameterType.QualifiedString(),
+						),
+					},
+				)
+			}
+		}
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.FunctionDeclaration)(nil),
+				(*ast.SpecialFunctionDeclaration)(nil),
+				(*ast.FunctionExpression)(nil),
+			},
+			func(element ast.Element) {
+				switch element := element.(type) {
+				case *ast.FunctionDeclaration:
+					checkParameters(
+						element.ParameterList,
+						elaboration.FunctionDeclarationFunctionTypes[element],
+					)
+
+				case *ast.SpecialFunctionDeclaration:
+					if element.Kind == common.DeclarationKindPrepare {
+						return
+					}
+					checkParameters(
+						element.FunctionDeclaration.ParameterList,
+						elaboration.ConstructorFunctionTypes[element],
+					)
+
+				case *ast.FunctionExpression:
+					checkParameters(
+						element.ParameterList,
+						elaboration.FunctionExpressionFunctionType[element],
+					)
+				}
+			},
+		)
+
+		return nil
+	},
+}
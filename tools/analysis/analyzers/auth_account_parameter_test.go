@@ -0,0 +1,97 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+func TestAuthAccountParameterAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("functions", func(t *testing.T) {
+
+		t.Parallel()
+
+		diagnostics := runAnalyzer(
+			t,
+			`
+              pub contract C {
+                  pub fun setup(account: AuthAccount, amount: Int) {}
+
+                  pub fun check(account: PublicAccount) {}
+
+                  init() {
+                      let f = fun (account: &AuthAccount?) {}
+                  }
+              }
+            `,
+			analyzers.AuthAccountParameterAnalyzer,
+		)
+
+		require.Equal(
+			t,
+			[]analysis.Diagnostic{
+				{
+					Location: testLocation,
+					Category: analyzers.SecurityCategory,
+					Message:  "parameter `account` has type `AuthAccount`, which gives the function full access to the account",
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 64, Line: 3, Column: 32},
+						EndPos:   ast.Position{Offset: 83, Line: 3, Column: 51},
+					},
+				},
+				{
+					Location: testLocation,
+					Category: analyzers.SecurityCategory,
+					Message:  "parameter `account` has type `&AuthAccount?`, which gives the function full access to the account",
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 225, Line: 8, Column: 35},
+						EndPos:   ast.Position{Offset: 246, Line: 8, Column: 56},
+					},
+				},
+			},
+			diagnostics,
+		)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+
+		t.Parallel()
+
+		diagnostics := runAnalyzer(
+			t,
+			`
+              transaction {
+                  prepare(signer: AuthAccount) {}
+              }
+            `,
+			analyzers.AuthAccountParameterAnalyzer,
+		)
+
+		require.Empty(t, diagnostics)
+	})
+}
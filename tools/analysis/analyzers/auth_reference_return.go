@@ -0,0 +1,91 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+// AuthReferenceReturnAnalyzer reports public functions which return authorized references.
+//
+// An authorized reference can be downcast, so any caller gains access
+// to all members of the referenced value.
+//
+var AuthReferenceReturnAnalyzer = &analysis.Analyzer{
+	Description: "Detects public functions which return authorized references",
+	Requires: []*analysis.Analyzer{
+		analysis.InspectorAnalyzer,
+	},
+	Run: func(pass *analysis.Pass) interface{} {
+		inspector := pass.ResultOf[analysis.InspectorAnalyzer].(*ast.Inspector)
+
+		program := pass.Program
+		location := program.Location
+		elaboration := program.Elaboration
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.FunctionDeclaration)(nil),
+			},
+			func(element ast.Element) {
+				functionDeclaration, ok := element.(*ast.FunctionDeclaration)
+				if !ok {
+					return
+				}
+
+				if functionDeclaration.Access != ast.AccessPublic ||
+					functionDeclaration.ReturnTypeAnnotation == nil {
+
+					return
+				}
+
+				functionType := elaboration.FunctionDeclarationFunctionTypes[functionDeclaration]
+				if functionType == nil {
+					return
+				}
+
+				returnType := functionType.ReturnTypeAnnotation.Type
+
+				referenceType, ok := sema.UnwrapOptionalType(returnType).(*sema.ReferenceType)
+				if !ok || !referenceType.Authorized {
+					return
+				}
+
+				pass.Report(
+					analysis.Diagnostic{
+						Location: location,
+						Range:    ast.NewRangeFromPositioned(nil, functionDeclaration.ReturnTypeAnnotation),
+						Category: SecurityCategory,
+						Message: fmt.Sprintf(
+							"public function `%s` returns authorized reference type `%s`",
+							functionDeclaration.Identifier.Identifier,
+							returnType.QualifiedString(),
+						),
+					},
+				)
+			},
+		)
+
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+func TestAuthReferenceReturnAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	diagnostics := runAnalyzer(
+		t,
+		`
+          pub contract C {
+              pub resource R {}
+
+              pub fun borrowAuth(): auth &R? {
+                  return nil
+              }
+
+              pub fun borrow(): &R? {
+                  return nil
+              }
+
+              access(contract) fun borrowInternal(): auth &R? {
+                  return nil
+              }
+          }
+        `,
+		analyzers.AuthReferenceReturnAnalyzer,
+	)
+
+	require.Equal(
+		t,
+		[]analysis.Diagnostic{
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message:  "public function `borrowAuth` returns authorized reference type `auth &C.R?`",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 97, Line: 5, Column: 36},
+					EndPos:   ast.Position{Offset: 104, Line: 5, Column: 43},
+				},
+			},
+		},
+		diagnostics,
+	)
+}
@@ -0,0 +1,146 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+// PublicCapabilityAnalyzer reports capabilities which are linked to a public path
+// and expose an authorized reference, or a reference to a composite type
+// instead of a restricted type, e.g. `&Vault` instead of `&Vault{Receiver}`.
+//
+var PublicCapabilityAnalyzer = &analysis.Analyzer{
+	Description: "Detects public capabilities which expose unrestricted references",
+	Requires: []*analysis.Analyzer{
+		analysis.InspectorAnalyzer,
+	},
+	Run: func(pass *analysis.Pass) interface{} {
+		inspector := pass.ResultOf[analysis.InspectorAnalyzer].(*ast.Inspector)
+
+		program := pass.Program
+		location := program.Location
+		elaboration := program.Elaboration
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.InvocationExpression)(nil),
+			},
+			func(element ast.Element) {
+				invocationExpression, ok := element.(*ast.InvocationExpression)
+				if !ok {
+					return
+				}
+
+				if !isAuthAccountLinkInvocation(invocationExpression, elaboration) ||
+					!hasPublicPathArgument(invocationExpression) {
+
+					return
+				}
+
+				typeArguments := elaboration.InvocationExpressionTypeArguments[invocationExpression]
+				if typeArguments == nil || typeArguments.Len() == 0 {
+					return
+				}
+
+				referenceType, ok := typeArguments.Oldest().Value.(*sema.ReferenceType)
+				if !ok {
+					return
+				}
+
+				var message string
+
+				if referenceType.Authorized {
+					message = fmt.Sprintf(
+						"public capability exposes authorized reference type `%s`",
+						referenceType.QualifiedString(),
+					)
+				} else if _, ok := referenceType.Type.(*sema.CompositeType); ok {
+					message = fmt.Sprintf(
+						"public capability exposes unrestricted reference type `%s`, "+
+							"consider restricting it to interfaces",
+						referenceType.QualifiedString(),
+					)
+				} else {
+					return
+				}
+
+				var diagnosticRange ast.Range
+				if len(invocationExpression.TypeArguments) > 0 {
+					diagnosticRange = ast.NewRangeFromPositioned(nil, invocationExpression.TypeArguments[0])
+				} else {
+					diagnosticRange = ast.NewRangeFromPositioned(nil, invocationExpression)
+				}
+
+				pass.Report(
+					analysis.Diagnostic{
+						Location: location,
+						Range:    diagnosticRange,
+						Category: SecurityCategory,
+						Message:  message,
+					},
+				)
+			},
+		)
+
+		return nil
+	},
+}
+
+// isAuthAccountLinkInvocation returns true if the given invocation
+// is an invocation of the `link` function of an AuthAccount.
+//
+func isAuthAccountLinkInvocation(
+	invocationExpression *ast.InvocationExpression,
+	elaboration *sema.Elaboration,
+) bool {
+	memberExpression, ok := invocationExpression.InvokedExpression.(*ast.MemberExpression)
+	if !ok {
+		return false
+	}
+
+	member := elaboration.MemberExpressionMemberInfos[memberExpression].Member
+	if member == nil {
+		return false
+	}
+
+	return member.Identifier.Identifier == sema.AuthAccountLinkField &&
+		member.ContainerType.Equal(sema.AuthAccountType)
+}
+
+// hasPublicPathArgument returns true if the first argument of the given invocation
+// is a path literal in the public domain.
+//
+func hasPublicPathArgument(invocationExpression *ast.InvocationExpression) bool {
+	if len(invocationExpression.Arguments) == 0 {
+		return false
+	}
+
+	pathExpression, ok := invocationExpression.Arguments[0].Expression.(*ast.PathExpression)
+	if !ok {
+		return false
+	}
+
+	return common.PathDomainFromIdentifier(pathExpression.Domain.Identifier) == common.PathDomainPublic
+}
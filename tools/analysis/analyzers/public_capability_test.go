@@ -0,0 +1,80 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+func TestPublicCapabilityAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	diagnostics := runAnalyzer(
+		t,
+		`
+          pub contract C {
+              pub resource interface Receiver {}
+
+              pub resource Vault: Receiver {}
+
+              init() {
+                  self.account.save(<-create Vault(), to: /storage/vault)
+                  self.account.link<&Vault>(/public/vault, target: /storage/vault)
+                  self.account.link<&Vault{Receiver}>(/public/receiver, target: /storage/vault)
+                  self.account.link<auth &Vault{Receiver}>(/public/auth, target: /storage/vault)
+                  self.account.link<&Vault>(/private/vault, target: /storage/vault)
+              }
+          }
+        `,
+		analyzers.PublicCapabilityAnalyzer,
+	)
+
+	require.Equal(
+		t,
+		[]analysis.Diagnostic{
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message: "public capability exposes unrestricted reference type `&C.Vault`, " +
+					"consider restricting it to interfaces",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 258, Line: 9, Column: 36},
+					EndPos:   ast.Position{Offset: 263, Line: 9, Column: 41},
+				},
+			},
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message:  "public capability exposes authorized reference type `auth &C.Vault{C.Receiver}`",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 437, Line: 11, Column: 36},
+					EndPos:   ast.Position{Offset: 457, Line: 11, Column: 56},
+				},
+			},
+		},
+		diagnostics,
+	)
+}
@@ -0,0 +1,120 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+// PublicMutableFieldAnalyzer reports public fields which anyone can mutate:
+// Settable fields (`pub(set)`), and public fields with array or dictionary types,
+// the elements of which can be modified through the field, even if it is constant.
+//
+var PublicMutableFieldAnalyzer = &analysis.Analyzer{
+	Description: "Detects public fields which can be mutated by anyone",
+	Requires: []*analysis.Analyzer{
+		analysis.InspectorAnalyzer,
+	},
+	Run: func(pass *analysis.Pass) interface{} {
+		inspector := pass.ResultOf[analysis.InspectorAnalyzer].(*ast.Inspector)
+
+		program := pass.Program
+		location := program.Location
+		elaboration := program.Elaboration
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.CompositeDeclaration)(nil),
+			},
+			func(element ast.Element) {
+				compositeDeclaration, ok := element.(*ast.CompositeDeclaration)
+				if !ok {
+					return
+				}
+
+				compositeType := elaboration.CompositeDeclarationTypes[compositeDeclaration]
+				if compositeType == nil {
+					return
+				}
+
+				for _, field := range compositeDeclaration.Members.Fields() {
+
+					var message string
+
+					switch field.Access {
+					case ast.AccessPublicSettable:
+						message = fmt.Sprintf(
+							"public settable field `%s` can be set by anyone",
+							field.Identifier.Identifier,
+						)
+
+					case ast.AccessPublic:
+						member, ok := compositeType.Members.Get(field.Identifier.Identifier)
+						if !ok || member.TypeAnnotation == nil {
+							continue
+						}
+
+						fieldType := member.TypeAnnotation.Type
+						if !isContainerType(fieldType) {
+							continue
+						}
+
+						message = fmt.Sprintf(
+							"public field `%s` has type `%s`, its contents can be modified by anyone",
+							field.Identifier.Identifier,
+							fieldType.QualifiedString(),
+						)
+
+					default:
+						continue
+					}
+
+					pass.Report(
+						analysis.Diagnostic{
+							Location: location,
+							Range:    field.Range,
+							Category: SecurityCategory,
+							Message:  message,
+						},
+					)
+				}
+			},
+		)
+
+		return nil
+	},
+}
+
+// isContainerType returns true if the given type is an array or dictionary type,
+// or an optional of it.
+//
+func isContainerType(ty sema.Type) bool {
+	switch sema.UnwrapOptionalType(ty).(type) {
+	case *sema.VariableSizedType,
+		*sema.ConstantSizedType,
+		*sema.DictionaryType:
+
+		return true
+	}
+	return false
+}
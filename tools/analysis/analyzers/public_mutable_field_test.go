@@ -0,0 +1,90 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+func TestPublicMutableFieldAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	diagnostics := runAnalyzer(
+		t,
+		`
+          pub contract C {
+              pub let values: [Int]
+              pub var names: {String: Int}?
+              pub(set) var count: Int
+              pub let total: Int
+              access(self) let secrets: [Int]
+
+              init() {
+                  self.values = []
+                  self.names = nil
+                  self.count = 0
+                  self.total = 0
+                  self.secrets = []
+              }
+          }
+        `,
+		analyzers.PublicMutableFieldAnalyzer,
+	)
+
+	require.Equal(
+		t,
+		[]analysis.Diagnostic{
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message:  "public field `values` has type `[Int]`, its contents can be modified by anyone",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 42, Line: 3, Column: 14},
+					EndPos:   ast.Position{Offset: 62, Line: 3, Column: 34},
+				},
+			},
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message:  "public field `names` has type `{String: Int}?`, its contents can be modified by anyone",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 78, Line: 4, Column: 14},
+					EndPos:   ast.Position{Offset: 106, Line: 4, Column: 42},
+				},
+			},
+			{
+				Location: testLocation,
+				Category: analyzers.SecurityCategory,
+				Message:  "public settable field `count` can be set by anyone",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 122, Line: 5, Column: 14},
+					EndPos:   ast.Position{Offset: 144, Line: 5, Column: 36},
+				},
+			},
+		},
+		diagnostics,
+	)
+}
@@ -16,12 +16,23 @@
  * limitations under the License.
  */
 
-package ir
+package analyzers
 
-//go:generate go run golang.org/x/tools/cmd/stringer -type=UnOp
+import (
+	"github.com/onflow/cadence/runtime/sema"
+)
 
-type UnOp uint
+// SecurityCategory is the category of diagnostics which report security issues
+//
+const SecurityCategory = "security"
 
-const (
-	UnOpUnknown UnOp = iota
-)
+// isAuthAccountType returns true if the given type is the AuthAccount type,
+// an optional of it, or a reference to it.
+//
+func isAuthAccountType(ty sema.Type) bool {
+	ty = sema.UnwrapOptionalType(ty)
+	if referenceType, ok := ty.(*sema.ReferenceType); ok {
+		ty = referenceType.Type
+	}
+	return ty.Equal(sema.AuthAccountType)
+}
@@ -0,0 +1,91 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+const UnnecessaryForceCategory = "unnecessary-force"
+
+// UnnecessaryForceAnalyzer reports force expressions on values which are not optional,
+// and suggests to remove the force operator.
+//
+var UnnecessaryForceAnalyzer = &analysis.Analyzer{
+	Description: "Detects unnecessary uses of the force operator",
+	Requires: []*analysis.Analyzer{
+		analysis.InspectorAnalyzer,
+	},
+	Run: func(pass *analysis.Pass) interface{} {
+		inspector := pass.ResultOf[analysis.InspectorAnalyzer].(*ast.Inspector)
+
+		program := pass.Program
+		location := program.Location
+		elaboration := program.Elaboration
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.ForceExpression)(nil),
+			},
+			func(element ast.Element) {
+				forceExpression, ok := element.(*ast.ForceExpression)
+				if !ok {
+					return
+				}
+
+				valueType := elaboration.ForceExpressionTypes[forceExpression]
+				if valueType == nil || valueType.IsInvalidType() {
+					return
+				}
+
+				if _, ok := valueType.(*sema.OptionalType); ok {
+					return
+				}
+
+				operatorRange := ast.Range{
+					StartPos: forceExpression.EndPos,
+					EndPos:   forceExpression.EndPos,
+				}
+
+				pass.Report(
+					analysis.Diagnostic{
+						Location: location,
+						Range:    ast.NewRangeFromPositioned(nil, forceExpression),
+						Category: UnnecessaryForceCategory,
+						Message:  "unnecessary force operator",
+						SuggestedFixes: []analysis.SuggestedFix{
+							{
+								Message: "Remove unnecessary force operator",
+								TextEdits: []analysis.TextEdit{
+									{
+										Range: operatorRange,
+									},
+								},
+							},
+						},
+					},
+				)
+			},
+		)
+
+		return nil
+	},
+}
@@ -0,0 +1,94 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/cadence/tools/analysis/analyzers"
+)
+
+func TestUnnecessaryForceAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	location := common.StringLocation("test")
+	const code = `
+      pub fun test(a: Int, b: Int?) {
+          let x = a!
+          let y = b!
+      }
+    `
+
+	config := &analysis.Config{
+		Mode: analysis.NeedTypes,
+		ResolveCode: func(
+			location common.Location,
+			_ common.Location,
+			_ ast.Range,
+		) (string, error) {
+			return code, nil
+		},
+	}
+
+	programs, err := analysis.Load(config, location)
+	require.NoError(t, err)
+
+	var diagnostics []analysis.Diagnostic
+
+	programs.Run(
+		[]*analysis.Analyzer{
+			analyzers.UnnecessaryForceAnalyzer,
+		},
+		func(diagnostic analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic)
+		},
+	)
+
+	require.Len(t, diagnostics, 1)
+
+	diagnostic := diagnostics[0]
+	assert.Equal(t, analyzers.UnnecessaryForceCategory, diagnostic.Category)
+	assert.Equal(t, "unnecessary force operator", diagnostic.Message)
+	assert.Equal(t,
+		ast.Range{
+			StartPos: ast.Position{Offset: 57, Line: 3, Column: 18},
+			EndPos:   ast.Position{Offset: 58, Line: 3, Column: 19},
+		},
+		diagnostic.Range,
+	)
+
+	fixed, count := analysis.ApplyFixes(code, diagnostics)
+	assert.Equal(t, 1, count)
+	assert.Equal(t,
+		`
+      pub fun test(a: Int, b: Int?) {
+          let x = a
+          let y = b!
+      }
+    `,
+		fixed,
+	)
+}
@@ -0,0 +1,500 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type=BlockKind
+
+// BlockKind describes the role of a basic block in a control-flow graph
+//
+type BlockKind uint8
+
+const (
+	BlockKindUnknown BlockKind = iota
+	BlockKindEntry
+	BlockKindExit
+	BlockKindPostConditions
+	BlockKindBody
+	BlockKindIfThen
+	BlockKindIfElse
+	BlockKindIfDone
+	BlockKindWhileHead
+	BlockKindWhileBody
+	BlockKindWhileDone
+	BlockKindForHead
+	BlockKindForBody
+	BlockKindForDone
+	BlockKindSwitchCaseTest
+	BlockKindSwitchCaseBody
+	BlockKindSwitchDone
+	BlockKindUnreachable
+)
+
+// Block is a basic block of a control-flow graph:
+// A sequence of nodes which are evaluated in order,
+// without any control flow into or out of the middle of the sequence.
+//
+// The nodes of a block are simple statements, e.g. variable declarations, assignments,
+// expression statements, and return, break, and continue statements,
+// and the expressions which determine the control flow,
+// e.g. the test of an if-statement, the value of a switch statement,
+// or the test of a condition.
+//
+// The test of an if-statement with an optional binding (`if let`)
+// is the variable declaration. Its value is evaluated on both branches,
+// but the variable is only declared in the then-branch.
+//
+// The head of a for-in loop contains the for-statement,
+// which represents the binding of the next element to the loop variable.
+//
+type Block struct {
+	Index int
+	Kind  BlockKind
+	Nodes []ast.Element
+	Succs []*Block
+	Preds []*Block
+	// Live is true if the block is reachable from the entry block
+	Live bool
+}
+
+func (b *Block) addSucc(succ *Block) {
+	b.Succs = append(b.Succs, succ)
+	succ.Preds = append(succ.Preds, b)
+}
+
+// CFG is the control-flow graph of a function.
+//
+// Control flows from the entry block, which contains the pre-conditions,
+// to the exit block, which is preceded by the post-conditions.
+//
+// A block which aborts the execution of the function,
+// e.g. because it invokes a function which never returns, like `panic`,
+// has no successors. Failing conditions are not represented as edges.
+//
+type CFG struct {
+	// Function is the function declaration, special function declaration,
+	// or function expression
+	Function      ast.Element
+	FunctionBlock *ast.FunctionBlock
+	// Blocks are the basic blocks of the function.
+	// The index of each block is its position in this list
+	Blocks []*Block
+	Entry  *Block
+	Exit   *Block
+}
+
+// NewCFG returns the control-flow graph for the given function block.
+//
+// The elaboration is optional. If it is provided, invocations of functions
+// which never return are treated as the end of the control flow.
+//
+func NewCFG(
+	function ast.Element,
+	functionBlock *ast.FunctionBlock,
+	elaboration *sema.Elaboration,
+) *CFG {
+	builder := &cfgBuilder{
+		cfg: &CFG{
+			Function:      function,
+			FunctionBlock: functionBlock,
+		},
+		elaboration: elaboration,
+	}
+	builder.build()
+	return builder.cfg
+}
+
+type cfgJumpTargets struct {
+	breakTarget    *Block
+	continueTarget *Block
+}
+
+type cfgBuilder struct {
+	cfg         *CFG
+	elaboration *sema.Elaboration
+	// current is the block to which nodes are added
+	current *Block
+	// returnTarget is the block to which return statements jump,
+	// i.e. the post-conditions, if any, or the exit block
+	returnTarget *Block
+	jumpTargets  []cfgJumpTargets
+}
+
+func (b *cfgBuilder) newBlock(kind BlockKind) *Block {
+	block := &Block{
+		Index: len(b.cfg.Blocks),
+		Kind:  kind,
+	}
+	b.cfg.Blocks = append(b.cfg.Blocks, block)
+	return block
+}
+
+func (b *cfgBuilder) add(node ast.Element) {
+	b.current.Nodes = append(b.current.Nodes, node)
+}
+
+// jump adds an edge from the current block to the given target,
+// and continues with a new, unreachable block.
+//
+func (b *cfgBuilder) jump(target *Block) {
+	if target != nil {
+		b.current.addSucc(target)
+	}
+	b.current = b.newBlock(BlockKindUnreachable)
+}
+
+func (b *cfgBuilder) build() {
+	cfg := b.cfg
+	functionBlock := cfg.FunctionBlock
+
+	cfg.Entry = b.newBlock(BlockKindEntry)
+	b.current = cfg.Entry
+
+	b.conditions(functionBlock.PreConditions)
+
+	exit := b.newBlock(BlockKindExit)
+	cfg.Exit = exit
+
+	b.returnTarget = exit
+
+	var postConditions *Block
+	if !functionBlock.PostConditions.IsEmpty() {
+		postConditions = b.newBlock(BlockKindPostConditions)
+		b.returnTarget = postConditions
+	}
+
+	if functionBlock.Block != nil {
+		body := b.newBlock(BlockKindBody)
+		b.current.addSucc(body)
+		b.current = body
+
+		b.statements(functionBlock.Block.Statements)
+	}
+
+	b.current.addSucc(b.returnTarget)
+
+	if postConditions != nil {
+		b.current = postConditions
+		b.conditions(functionBlock.PostConditions)
+		b.current.addSucc(exit)
+	}
+
+	b.markLive(cfg.Entry)
+}
+
+func (b *cfgBuilder) conditions(conditions *ast.Conditions) {
+	if conditions == nil {
+		return
+	}
+
+	for _, condition := range *conditions {
+		b.add(condition.Test)
+		if condition.Message != nil {
+			b.add(condition.Message)
+		}
+	}
+}
+
+func (b *cfgBuilder) statements(statements []ast.Statement) {
+	for _, statement := range statements {
+		b.statement(statement)
+	}
+}
+
+func (b *cfgBuilder) statement(statement ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.ReturnStatement:
+		b.add(statement)
+		b.jump(b.returnTarget)
+
+	case *ast.BreakStatement:
+		b.add(statement)
+		b.jump(b.breakTarget())
+
+	case *ast.ContinueStatement:
+		b.add(statement)
+		b.jump(b.continueTarget())
+
+	case *ast.IfStatement:
+		b.ifStatement(statement)
+
+	case *ast.WhileStatement:
+		b.whileStatement(statement)
+
+	case *ast.ForStatement:
+		b.forStatement(statement)
+
+	case *ast.SwitchStatement:
+		b.switchStatement(statement)
+
+	case *ast.ExpressionStatement:
+		b.add(statement)
+		if b.neverReturns(statement.Expression) {
+			b.jump(nil)
+		}
+
+	default:
+		b.add(statement)
+	}
+}
+
+// neverReturns returns true if the given expression is an invocation
+// of a function which never returns, e.g. `panic`.
+//
+func (b *cfgBuilder) neverReturns(expression ast.Expression) bool {
+	if b.elaboration == nil {
+		return false
+	}
+
+	invocationExpression, ok := expression.(*ast.InvocationExpression)
+	if !ok {
+		return false
+	}
+
+	returnType := b.elaboration.InvocationExpressionReturnTypes[invocationExpression]
+	return returnType == sema.NeverType
+}
+
+func (b *cfgBuilder) ifStatement(statement *ast.IfStatement) {
+	b.add(statement.Test)
+
+	head := b.current
+	done := b.newBlock(BlockKindIfDone)
+
+	then := b.newBlock(BlockKindIfThen)
+	head.addSucc(then)
+	b.current = then
+	b.statements(statement.Then.Statements)
+	b.current.addSucc(done)
+
+	if statement.Else != nil {
+		els := b.newBlock(BlockKindIfElse)
+		head.addSucc(els)
+		b.current = els
+		b.statements(statement.Else.Statements)
+		b.current.addSucc(done)
+	} else {
+		head.addSucc(done)
+	}
+
+	b.current = done
+}
+
+func (b *cfgBuilder) whileStatement(statement *ast.WhileStatement) {
+	head := b.newBlock(BlockKindWhileHead)
+	b.current.addSucc(head)
+	b.current = head
+	b.add(statement.Test)
+
+	body := b.newBlock(BlockKindWhileBody)
+	done := b.newBlock(BlockKindWhileDone)
+
+	head.addSucc(body)
+	head.addSucc(done)
+
+	b.loopBody(statement.Block, body, head, done)
+}
+
+func (b *cfgBuilder) forStatement(statement *ast.ForStatement) {
+	b.add(statement.Value)
+
+	head := b.newBlock(BlockKindForHead)
+	b.current.addSucc(head)
+	b.current = head
+	b.add(statement)
+
+	body := b.newBlock(BlockKindForBody)
+	done := b.newBlock(BlockKindForDone)
+
+	head.addSucc(body)
+	head.addSucc(done)
+
+	b.loopBody(statement.Block, body, head, done)
+}
+
+func (b *cfgBuilder) loopBody(block *ast.Block, body *Block, head *Block, done *Block) {
+	b.jumpTargets = append(
+		b.jumpTargets,
+		cfgJumpTargets{
+			breakTarget:    done,
+			continueTarget: head,
+		},
+	)
+
+	b.current = body
+	b.statements(block.Statements)
+	b.current.addSucc(head)
+
+	b.jumpTargets = b.jumpTargets[:len(b.jumpTargets)-1]
+
+	b.current = done
+}
+
+func (b *cfgBuilder) switchStatement(statement *ast.SwitchStatement) {
+	b.add(statement.Expression)
+
+	done := b.newBlock(BlockKindSwitchDone)
+
+	// A break statement in a switch statement breaks out of the switch statement,
+	// a continue statement continues the enclosing loop, if any
+
+	b.jumpTargets = append(
+		b.jumpTargets,
+		cfgJumpTargets{
+			breakTarget:    done,
+			continueTarget: b.continueTarget(),
+		},
+	)
+
+	// The tests of the cases are evaluated in order,
+	// until one matches. The default case matches always
+
+	test := b.current
+	hasDefault := false
+
+	for _, switchCase := range statement.Cases {
+
+		// Cases after the default case are unreachable
+		if test == nil {
+			test = b.newBlock(BlockKindUnreachable)
+		}
+
+		body := b.newBlock(BlockKindSwitchCaseBody)
+
+		if switchCase.Expression == nil {
+			hasDefault = true
+			test.addSucc(body)
+			test = nil
+		} else {
+			caseTest := b.newBlock(BlockKindSwitchCaseTest)
+			test.addSucc(caseTest)
+			caseTest.Nodes = append(caseTest.Nodes, switchCase.Expression)
+			caseTest.addSucc(body)
+			test = caseTest
+		}
+
+		b.current = body
+		b.statements(switchCase.Statements)
+		b.current.addSucc(done)
+	}
+
+	if !hasDefault {
+		test.addSucc(done)
+	}
+
+	b.jumpTargets = b.jumpTargets[:len(b.jumpTargets)-1]
+
+	b.current = done
+}
+
+func (b *cfgBuilder) breakTarget() *Block {
+	// The checker rejects break statements outside of loops and switch statements,
+	// but the program might not have been checked
+	if len(b.jumpTargets) == 0 {
+		return nil
+	}
+	return b.jumpTargets[len(b.jumpTargets)-1].breakTarget
+}
+
+func (b *cfgBuilder) continueTarget() *Block {
+	if len(b.jumpTargets) == 0 {
+		return nil
+	}
+	return b.jumpTargets[len(b.jumpTargets)-1].continueTarget
+}
+
+func (b *cfgBuilder) markLive(block *Block) {
+	if block.Live {
+		return
+	}
+	block.Live = true
+	for _, succ := range block.Succs {
+		b.markLive(succ)
+	}
+}
+
+// CFGs are the control-flow graphs of all functions of a program
+//
+type CFGs struct {
+	// Functions are the control-flow graphs of all functions, in program order
+	Functions       []*CFG
+	byFunctionBlock map[*ast.FunctionBlock]*CFG
+}
+
+// Of returns the control-flow graph of the function with the given function block,
+// or nil if the function block has no control-flow graph.
+//
+func (cfgs *CFGs) Of(functionBlock *ast.FunctionBlock) *CFG {
+	return cfgs.byFunctionBlock[functionBlock]
+}
+
+// CFGAnalyzer builds the control-flow graphs of all functions of a program,
+// including nested functions and function expressions.
+// The result is *CFGs.
+//
+var CFGAnalyzer = &Analyzer{
+	Description: "Builds the control-flow graphs of all functions",
+	Requires: []*Analyzer{
+		InspectorAnalyzer,
+	},
+	Run: func(pass *Pass) interface{} {
+		inspector := pass.ResultOf[InspectorAnalyzer].(*ast.Inspector)
+		elaboration := pass.Program.Elaboration
+
+		cfgs := &CFGs{
+			byFunctionBlock: map[*ast.FunctionBlock]*CFG{},
+		}
+
+		addFunction := func(function ast.Element, functionBlock *ast.FunctionBlock) {
+			if functionBlock == nil {
+				return
+			}
+			if _, ok := cfgs.byFunctionBlock[functionBlock]; ok {
+				return
+			}
+			cfg := NewCFG(function, functionBlock, elaboration)
+			cfgs.Functions = append(cfgs.Functions, cfg)
+			cfgs.byFunctionBlock[functionBlock] = cfg
+		}
+
+		inspector.Preorder(
+			[]ast.Element{
+				(*ast.FunctionDeclaration)(nil),
+				(*ast.SpecialFunctionDeclaration)(nil),
+				(*ast.FunctionExpression)(nil),
+			},
+			func(element ast.Element) {
+				switch element := element.(type) {
+				case *ast.FunctionDeclaration:
+					addFunction(element, element.FunctionBlock)
+				case *ast.SpecialFunctionDeclaration:
+					addFunction(element, element.FunctionDeclaration.FunctionBlock)
+				case *ast.FunctionExpression:
+					addFunction(element, element.FunctionBlock)
+				}
+			},
+		)
+
+		return cfgs
+	},
+}
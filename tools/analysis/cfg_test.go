@@ -0,0 +1,350 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+func buildCFGs(t *testing.T, code string) *analysis.CFGs {
+	location := common.StringLocation("test")
+
+	config := &analysis.Config{
+		Mode: analysis.NeedTypes,
+		ResolveCode: func(
+			location common.Location,
+			_ common.Location,
+			_ ast.Range,
+		) (string, error) {
+			return code, nil
+		},
+	}
+
+	programs, err := analysis.Load(config, location)
+	require.NoError(t, err)
+
+	var cfgs *analysis.CFGs
+
+	analyzer := &analysis.Analyzer{
+		Requires: []*analysis.Analyzer{
+			analysis.CFGAnalyzer,
+		},
+		Run: func(pass *analysis.Pass) interface{} {
+			cfgs = pass.ResultOf[analysis.CFGAnalyzer].(*analysis.CFGs)
+			return nil
+		},
+	}
+
+	programs[location].Run(
+		[]*analysis.Analyzer{analyzer},
+		func(_ analysis.Diagnostic) {},
+	)
+
+	require.NotNil(t, cfgs)
+
+	return cfgs
+}
+
+// cfgString returns a description of the given control-flow graph,
+// one line per block, with the kind, the node element types, and the successors.
+// Unreachable blocks are marked with an exclamation mark.
+//
+func cfgString(cfg *analysis.CFG) string {
+	var builder strings.Builder
+
+	for _, block := range cfg.Blocks {
+		_, _ = fmt.Fprintf(
+			&builder,
+			"%d %s",
+			block.Index,
+			strings.TrimPrefix(block.Kind.String(), "BlockKind"),
+		)
+		if !block.Live {
+			builder.WriteString("!")
+		}
+
+		nodes := make([]string, 0, len(block.Nodes))
+		for _, node := range block.Nodes {
+			nodes = append(nodes, strings.TrimPrefix(node.ElementType().String(), "ElementType"))
+		}
+		_, _ = fmt.Fprintf(&builder, " [%s]", strings.Join(nodes, " "))
+
+		if len(block.Succs) > 0 {
+			builder.WriteString(" ->")
+			for _, succ := range block.Succs {
+				_, _ = fmt.Fprintf(&builder, " %d", succ.Index)
+			}
+		}
+
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func TestCFG(t *testing.T) {
+
+	t.Parallel()
+
+	test := func(t *testing.T, code string, expected string) {
+		cfgs := buildCFGs(t, code)
+		require.Len(t, cfgs.Functions, 1)
+		assert.Equal(t,
+			strings.TrimLeft(expected, "\n"),
+			cfgString(cfgs.Functions[0]),
+		)
+	}
+
+	t.Run("conditions", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test(x: Int): Int {
+                  pre {
+                      x > 0: "x must be positive"
+                  }
+                  post {
+                      result > 1
+                  }
+                  let y = x + 1
+                  return y
+              }
+            `,
+			`
+0 Entry [BinaryExpression StringExpression] -> 3
+1 Exit []
+2 PostConditions [BinaryExpression] -> 1
+3 Body [VariableDeclaration ReturnStatement] -> 2
+4 Unreachable! [] -> 2
+`,
+		)
+	})
+
+	t.Run("if let", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test(x: Int?): Int {
+                  var y = 0
+                  if let z = x {
+                      y = z
+                  } else {
+                      return 0
+                  }
+                  return y
+              }
+            `,
+			`
+0 Entry [] -> 2
+1 Exit []
+2 Body [VariableDeclaration VariableDeclaration] -> 4 5
+3 IfDone [ReturnStatement] -> 1
+4 IfThen [AssignmentStatement] -> 3
+5 IfElse [ReturnStatement] -> 1
+6 Unreachable! [] -> 3
+7 Unreachable! [] -> 1
+`,
+		)
+	})
+
+	t.Run("while", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test() {
+                  var i = 0
+                  while i < 10 {
+                      i = i + 1
+                      if i == 5 {
+                          continue
+                      }
+                      if i == 7 {
+                          break
+                      }
+                  }
+              }
+            `,
+			`
+0 Entry [] -> 2
+1 Exit []
+2 Body [VariableDeclaration] -> 3
+3 WhileHead [BinaryExpression] -> 4 5
+4 WhileBody [AssignmentStatement BinaryExpression] -> 7 6
+5 WhileDone [] -> 1
+6 IfDone [BinaryExpression] -> 10 9
+7 IfThen [ContinueStatement] -> 3
+8 Unreachable! [] -> 6
+9 IfDone [] -> 3
+10 IfThen [BreakStatement] -> 5
+11 Unreachable! [] -> 9
+`,
+		)
+	})
+
+	t.Run("for", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test(values: [Int]): Int {
+                  var sum = 0
+                  for value in values {
+                      sum = sum + value
+                  }
+                  return sum
+              }
+            `,
+			`
+0 Entry [] -> 2
+1 Exit []
+2 Body [VariableDeclaration IdentifierExpression] -> 3
+3 ForHead [ForStatement] -> 4 5
+4 ForBody [AssignmentStatement] -> 3
+5 ForDone [ReturnStatement] -> 1
+6 Unreachable! [] -> 1
+`,
+		)
+	})
+
+	t.Run("switch", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test(x: Int): Int {
+                  var y = 0
+                  while true {
+                      switch x {
+                      case 1:
+                          y = 1
+                          break
+                      case 2:
+                          continue
+                      default:
+                          return 3
+                      }
+                  }
+                  return y
+              }
+            `,
+			`
+0 Entry [] -> 2
+1 Exit []
+2 Body [VariableDeclaration] -> 3
+3 WhileHead [BoolExpression] -> 4 5
+4 WhileBody [IdentifierExpression] -> 8
+5 WhileDone [ReturnStatement] -> 1
+6 SwitchDone [] -> 3
+7 SwitchCaseBody [AssignmentStatement BreakStatement] -> 6
+8 SwitchCaseTest [IntegerExpression] -> 7 11
+9 Unreachable! [] -> 6
+10 SwitchCaseBody [ContinueStatement] -> 3
+11 SwitchCaseTest [IntegerExpression] -> 10 13
+12 Unreachable! [] -> 6
+13 SwitchCaseBody [ReturnStatement] -> 1
+14 Unreachable! [] -> 6
+15 Unreachable! [] -> 1
+`,
+		)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+
+		t.Parallel()
+
+		test(
+			t,
+			`
+              pub fun test(x: Int): Int {
+                  if x > 0 {
+                      panic("positive")
+                  }
+                  return x
+              }
+            `,
+			`
+0 Entry [] -> 2
+1 Exit []
+2 Body [BinaryExpression] -> 4 3
+3 IfDone [ReturnStatement] -> 1
+4 IfThen [ExpressionStatement]
+5 Unreachable! [] -> 3
+6 Unreachable! [] -> 1
+`,
+		)
+	})
+}
+
+func TestCFGAnalyzer(t *testing.T) {
+
+	t.Parallel()
+
+	cfgs := buildCFGs(t, `
+      pub contract C {
+
+          pub fun f(): Int {
+              let g = fun (): Int {
+                  return 1
+              }
+              return g()
+          }
+
+          init() {}
+      }
+    `)
+
+	require.Len(t, cfgs.Functions, 3)
+
+	var kinds []string
+	for _, cfg := range cfgs.Functions {
+		kinds = append(kinds, fmt.Sprintf("%T", cfg.Function))
+		assert.Same(t, cfg, cfgs.Of(cfg.FunctionBlock))
+	}
+
+	assert.Equal(t,
+		[]string{
+			"*ast.FunctionDeclaration",
+			"*ast.FunctionExpression",
+			"*ast.SpecialFunctionDeclaration",
+		},
+		kinds,
+	)
+}
@@ -0,0 +1,225 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis
+
+import (
+	"github.com/onflow/cadence/runtime/ast"
+)
+
+// DataFlowDirection is the direction in which facts are propagated
+// through a control-flow graph
+//
+type DataFlowDirection uint8
+
+const (
+	// DataFlowForward propagates facts from the entry block along the edges,
+	// e.g. for reaching definitions or resource states
+	DataFlowForward DataFlowDirection = iota
+	// DataFlowBackward propagates facts from the exit block against the edges,
+	// e.g. for live variables
+	DataFlowBackward
+)
+
+// DataFlowProblem describes a data-flow problem over facts of type F.
+//
+// Facts must be treated as immutable: Join and Transfer must return new facts
+// instead of modifying their arguments.
+//
+type DataFlowProblem[F any] struct {
+	Direction DataFlowDirection
+	// Boundary is the fact at the start of the analysis,
+	// i.e. before the entry block for forward problems,
+	// and after the exit block for backward problems
+	Boundary F
+	// Initial is the fact which all other blocks start with,
+	// i.e. the identity of Join
+	Initial F
+	// Join combines the facts at a merge point of the control flow
+	Join func(a, b F) F
+	// Equal returns true if the facts are equal,
+	// which determines when the solution has reached a fixed point
+	Equal func(a, b F) bool
+	// Transfer returns the fact after the evaluation of the given node
+	// (before, for backward problems), given the fact before it (after, for backward problems)
+	Transfer func(node ast.Element, fact F) F
+}
+
+// DataFlowResult is the solution of a data-flow problem.
+//
+// In and Out are the facts at the start and at the end of each block,
+// indexed by the block's index, independent of the direction of the problem.
+//
+type DataFlowResult[F any] struct {
+	CFG     *CFG
+	Problem DataFlowProblem[F]
+	In      []F
+	Out     []F
+}
+
+// SolveDataFlow solves the given data-flow problem for the given control-flow graph,
+// using a worklist algorithm.
+//
+// The join and transfer functions must be monotonic,
+// and the facts must form a lattice of finite height,
+// otherwise the solver might not terminate.
+//
+func SolveDataFlow[F any](cfg *CFG, problem DataFlowProblem[F]) DataFlowResult[F] {
+	blockCount := len(cfg.Blocks)
+
+	in := make([]F, blockCount)
+	out := make([]F, blockCount)
+
+	for i := 0; i < blockCount; i++ {
+		in[i] = problem.Initial
+		out[i] = problem.Initial
+	}
+
+	forward := problem.Direction == DataFlowForward
+
+	var boundaryBlock *Block
+	if forward {
+		boundaryBlock = cfg.Entry
+	} else {
+		boundaryBlock = cfg.Exit
+	}
+
+	// Process the blocks in the order of the direction initially,
+	// then only the blocks whose inputs changed
+
+	worklist := make([]*Block, 0, blockCount)
+	queued := make([]bool, blockCount)
+
+	enqueue := func(block *Block) {
+		if queued[block.Index] {
+			return
+		}
+		queued[block.Index] = true
+		worklist = append(worklist, block)
+	}
+
+	if forward {
+		for _, block := range cfg.Blocks {
+			enqueue(block)
+		}
+	} else {
+		for i := blockCount - 1; i >= 0; i-- {
+			enqueue(cfg.Blocks[i])
+		}
+	}
+
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+		queued[block.Index] = false
+
+		// Join the facts of the predecessors (successors, for backward problems)
+
+		var sources []*Block
+		if forward {
+			sources = block.Preds
+		} else {
+			sources = block.Succs
+		}
+
+		fact := problem.Initial
+		if block == boundaryBlock {
+			fact = problem.Boundary
+		}
+		for _, source := range sources {
+			if forward {
+				fact = problem.Join(fact, out[source.Index])
+			} else {
+				fact = problem.Join(fact, in[source.Index])
+			}
+		}
+
+		// Apply the transfer function for each node of the block
+
+		var previous F
+		if forward {
+			in[block.Index] = fact
+			for _, node := range block.Nodes {
+				fact = problem.Transfer(node, fact)
+			}
+			previous = out[block.Index]
+			out[block.Index] = fact
+		} else {
+			out[block.Index] = fact
+			for i := len(block.Nodes) - 1; i >= 0; i-- {
+				fact = problem.Transfer(block.Nodes[i], fact)
+			}
+			previous = in[block.Index]
+			in[block.Index] = fact
+		}
+
+		if problem.Equal(previous, fact) {
+			continue
+		}
+
+		// The result of the block changed,
+		// so the blocks which depend on it must be processed again
+
+		var targets []*Block
+		if forward {
+			targets = block.Succs
+		} else {
+			targets = block.Preds
+		}
+		for _, target := range targets {
+			enqueue(target)
+		}
+	}
+
+	return DataFlowResult[F]{
+		CFG:     cfg,
+		Problem: problem,
+		In:      in,
+		Out:     out,
+	}
+}
+
+// ForEachNode calls the given function for each node of the given block,
+// in the order of evaluation, with the facts before and after the evaluation of the node.
+//
+func (result DataFlowResult[F]) ForEachNode(block *Block, f func(node ast.Element, before F, after F)) {
+	nodes := block.Nodes
+
+	switch result.Problem.Direction {
+	case DataFlowForward:
+		fact := result.In[block.Index]
+		for _, node := range nodes {
+			after := result.Problem.Transfer(node, fact)
+			f(node, fact, after)
+			fact = after
+		}
+
+	case DataFlowBackward:
+		// Compute the facts in reverse order,
+		// but report them in the order of evaluation
+
+		facts := make([]F, len(nodes)+1)
+		facts[len(nodes)] = result.Out[block.Index]
+		for i := len(nodes) - 1; i >= 0; i-- {
+			facts[i] = result.Problem.Transfer(nodes[i], facts[i+1])
+		}
+		for i, node := range nodes {
+			f(node, facts[i], facts[i+1])
+		}
+	}
+}
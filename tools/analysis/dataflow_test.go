@@ -0,0 +1,245 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+type nameSet map[string]struct{}
+
+func (s nameSet) with(names ...string) nameSet {
+	result := make(nameSet, len(s)+len(names))
+	for name := range s {
+		result[name] = struct{}{}
+	}
+	for _, name := range names {
+		result[name] = struct{}{}
+	}
+	return result
+}
+
+func (s nameSet) without(names ...string) nameSet {
+	result := s.with()
+	for _, name := range names {
+		delete(result, name)
+	}
+	return result
+}
+
+func (s nameSet) union(other nameSet) nameSet {
+	result := s.with()
+	for name := range other {
+		result[name] = struct{}{}
+	}
+	return result
+}
+
+func (s nameSet) equal(other nameSet) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for name := range s {
+		if _, ok := other[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s nameSet) sorted() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// definedName returns the name of the variable which is written by the given node, if any
+//
+func definedName(node ast.Element) string {
+	switch node := node.(type) {
+	case *ast.VariableDeclaration:
+		return node.Identifier.Identifier
+	case *ast.AssignmentStatement:
+		if identifierExpression, ok := node.Target.(*ast.IdentifierExpression); ok {
+			return identifierExpression.Identifier.Identifier
+		}
+	case *ast.ForStatement:
+		return node.Identifier.Identifier
+	}
+	return ""
+}
+
+// usedNames returns the names of the variables which are read by the given node
+//
+func usedNames(node ast.Element) []string {
+	var root ast.Element
+	switch node := node.(type) {
+	case *ast.VariableDeclaration:
+		root = node.Value
+	case *ast.AssignmentStatement:
+		root = node.Value
+	case *ast.ForStatement:
+		return nil
+	default:
+		root = node
+	}
+
+	var names []string
+	ast.Inspect(root, func(element ast.Element) bool {
+		if identifierExpression, ok := element.(*ast.IdentifierExpression); ok {
+			names = append(names, identifierExpression.Identifier.Identifier)
+		}
+		return true
+	})
+	return names
+}
+
+func TestSolveDataFlow(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("backward: dead stores", func(t *testing.T) {
+
+		t.Parallel()
+
+		cfgs := buildCFGs(t, `
+          pub fun test(a: Bool): Int {
+              var x = 1
+              x = 2
+              var y = 3
+              var z = 4
+              if a {
+                  y = 5
+                  z = 6
+              }
+              return x + y
+          }
+        `)
+
+		require.Len(t, cfgs.Functions, 1)
+		cfg := cfgs.Functions[0]
+
+		liveVariables := analysis.DataFlowProblem[nameSet]{
+			Direction: analysis.DataFlowBackward,
+			Boundary:  nameSet{},
+			Initial:   nameSet{},
+			Join:      nameSet.union,
+			Equal:     nameSet.equal,
+			Transfer: func(node ast.Element, live nameSet) nameSet {
+				return live.without(definedName(node)).with(usedNames(node)...)
+			},
+		}
+
+		result := analysis.SolveDataFlow(cfg, liveVariables)
+
+		assert.Equal(t, []string{"a"}, result.In[cfg.Entry.Index].sorted())
+
+		var deadStores []string
+		for _, block := range cfg.Blocks {
+			result.ForEachNode(block, func(node ast.Element, _ nameSet, after nameSet) {
+				name := definedName(node)
+				if name == "" {
+					return
+				}
+				if _, ok := after[name]; !ok {
+					deadStores = append(deadStores, node.(ast.Statement).String())
+				}
+			})
+		}
+
+		assert.Equal(t,
+			[]string{
+				"var x = 1",
+				"var z = 4",
+				"z = 6",
+			},
+			deadStores,
+		)
+	})
+
+	t.Run("forward: loop", func(t *testing.T) {
+
+		t.Parallel()
+
+		cfgs := buildCFGs(t, `
+          pub fun test(): Int {
+              var i = 0
+              var x = 0
+              var y = 0
+              while i < 10 {
+                  if i == 5 {
+                      x = i
+                      break
+                  }
+                  i = i + 1
+              }
+              return x
+          }
+        `)
+
+		require.Len(t, cfgs.Functions, 1)
+		cfg := cfgs.Functions[0]
+
+		// The variables which may have been assigned after their declaration
+
+		assignedVariables := analysis.DataFlowProblem[nameSet]{
+			Direction: analysis.DataFlowForward,
+			Boundary:  nameSet{},
+			Initial:   nameSet{},
+			Join:      nameSet.union,
+			Equal:     nameSet.equal,
+			Transfer: func(node ast.Element, assigned nameSet) nameSet {
+				if _, ok := node.(*ast.AssignmentStatement); ok {
+					return assigned.with(definedName(node))
+				}
+				return assigned
+			},
+		}
+
+		result := analysis.SolveDataFlow(cfg, assignedVariables)
+
+		var whileHead, whileDone *analysis.Block
+		for _, block := range cfg.Blocks {
+			switch block.Kind {
+			case analysis.BlockKindWhileHead:
+				whileHead = block
+			case analysis.BlockKindWhileDone:
+				whileDone = block
+			}
+		}
+		require.NotNil(t, whileHead)
+		require.NotNil(t, whileDone)
+
+		// The assignment of i in the loop body reaches the loop head through the back edge
+
+		assert.Equal(t, []string{"i"}, result.In[whileHead.Index].sorted())
+		assert.Equal(t, []string{"i", "x"}, result.In[whileDone.Index].sorted())
+		assert.Equal(t, []string{"i", "x"}, result.In[cfg.Exit.Index].sorted())
+	})
+}
@@ -28,5 +28,55 @@ type Diagnostic struct {
 	Location         common.Location
 	Category         string // optional
 	Message          string
-	SecondaryMessage string // optional
+	SecondaryMessage string         // optional
+	SuggestedFixes   []SuggestedFix // optional
+}
+
+// SuggestedFix is a change to the code which resolves a diagnostic.
+//
+// The text edits of a fix must not overlap.
+//
+type SuggestedFix struct {
+	Message   string
+	TextEdits []TextEdit
+}
+
+// TextEdit is a change to the code.
+//
+// If the insertion is not empty, it is inserted at the start position of the range.
+// Otherwise, the code in the range is replaced with the replacement.
+//
+type TextEdit struct {
+	Replacement string
+	Insertion   string
+	ast.Range
+}
+
+// ApplyTo applies the edit to the given code.
+//
+func (edit TextEdit) ApplyTo(code string) string {
+	if edit.Insertion != "" {
+		return code[:edit.StartPos.Offset] +
+			edit.Insertion +
+			code[edit.StartPos.Offset:]
+	}
+
+	return code[:edit.StartPos.Offset] +
+		edit.Replacement +
+		code[edit.EndPos.Offset+1:]
+}
+
+// startOffset returns the offset of the first character affected by the edit.
+//
+func (edit TextEdit) startOffset() int {
+	return edit.StartPos.Offset
+}
+
+// endOffset returns the offset after the last character affected by the edit.
+//
+func (edit TextEdit) endOffset() int {
+	if edit.Insertion != "" {
+		return edit.StartPos.Offset
+	}
+	return edit.EndPos.Offset + 1
 }
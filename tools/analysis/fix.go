@@ -0,0 +1,117 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis
+
+import (
+	"sort"
+)
+
+// ApplyFixes applies the first suggested fix of each of the given diagnostics,
+// which must be reported for the given code, and returns the fixed code
+// and the number of applied fixes.
+//
+// A fix is skipped if any of its edits overlaps with an edit of a fix that was already applied,
+// so the diagnostic can be fixed in a later run, after the code was analyzed again.
+//
+func ApplyFixes(code string, diagnostics []Diagnostic) (string, int) {
+
+	var edits []TextEdit
+	fixCount := 0
+
+	for _, diagnostic := range diagnostics {
+		if len(diagnostic.SuggestedFixes) == 0 {
+			continue
+		}
+
+		fix := diagnostic.SuggestedFixes[0]
+
+		if !editsApplicable(code, fix.TextEdits, edits) {
+			continue
+		}
+
+		edits = append(edits, fix.TextEdits...)
+		fixCount++
+	}
+
+	// Apply the edits from the end of the code to the start,
+	// so the offsets of the remaining edits stay valid.
+	// Insertions at the start of a replaced range are inserted before the replacement
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i], edits[j]
+		if a.startOffset() != b.startOffset() {
+			return a.startOffset() > b.startOffset()
+		}
+		return a.Insertion == "" && b.Insertion != ""
+	})
+
+	for _, edit := range edits {
+		code = edit.ApplyTo(code)
+	}
+
+	return code, fixCount
+}
+
+// editsApplicable returns true if the given edits are in the bounds of the code,
+// and do not overlap with each other or with the given applied edits.
+//
+func editsApplicable(code string, edits []TextEdit, appliedEdits []TextEdit) bool {
+	for i, edit := range edits {
+		if edit.startOffset() < 0 ||
+			edit.endOffset() > len(code) ||
+			edit.startOffset() > edit.endOffset() {
+
+			return false
+		}
+
+		for _, other := range edits[:i] {
+			if editsOverlap(edit, other) {
+				return false
+			}
+		}
+
+		for _, other := range appliedEdits {
+			if editsOverlap(edit, other) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func editsOverlap(a, b TextEdit) bool {
+	aStart, aEnd := a.startOffset(), a.endOffset()
+	bStart, bEnd := b.startOffset(), b.endOffset()
+
+	// Insertions at the same position conflict, as their order is ambiguous
+	if aStart == aEnd && bStart == bEnd {
+		return aStart == bStart
+	}
+
+	// An insertion conflicts with a replacement if it is strictly inside the replaced range
+	if aStart == aEnd {
+		return bStart < aStart && aStart < bEnd
+	}
+	if bStart == bEnd {
+		return aStart < bStart && bStart < aEnd
+	}
+
+	return aStart < bEnd && bStart < aEnd
+}
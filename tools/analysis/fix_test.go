@@ -0,0 +1,149 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package analysis_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/tools/analysis"
+)
+
+func TestApplyFixes(t *testing.T) {
+
+	t.Parallel()
+
+	offsetRange := func(startOffset, endOffset int) ast.Range {
+		return ast.Range{
+			StartPos: ast.Position{Offset: startOffset},
+			EndPos:   ast.Position{Offset: endOffset},
+		}
+	}
+
+	diagnostic := func(edits ...analysis.TextEdit) analysis.Diagnostic {
+		return analysis.Diagnostic{
+			SuggestedFixes: []analysis.SuggestedFix{
+				{
+					Message:   "fix",
+					TextEdits: edits,
+				},
+			},
+		}
+	}
+
+	t.Run("replacement", func(t *testing.T) {
+
+		t.Parallel()
+
+		fixed, count := analysis.ApplyFixes(
+			"let x = y!",
+			[]analysis.Diagnostic{
+				diagnostic(analysis.TextEdit{
+					Range: offsetRange(9, 9),
+				}),
+			},
+		)
+		assert.Equal(t, "let x = y", fixed)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("insertion", func(t *testing.T) {
+
+		t.Parallel()
+
+		fixed, count := analysis.ApplyFixes(
+			"let x = y",
+			[]analysis.Diagnostic{
+				diagnostic(analysis.TextEdit{
+					Insertion: " as Int",
+					Range:     offsetRange(9, 9),
+				}),
+			},
+		)
+		assert.Equal(t, "let x = y as Int", fixed)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("multiple edits", func(t *testing.T) {
+
+		t.Parallel()
+
+		fixed, count := analysis.ApplyFixes(
+			"let x = a! + b!",
+			[]analysis.Diagnostic{
+				diagnostic(
+					analysis.TextEdit{
+						Range: offsetRange(9, 9),
+					},
+					analysis.TextEdit{
+						Replacement: "c",
+						Range:       offsetRange(13, 13),
+					},
+				),
+				{
+					Message: "no fix",
+				},
+			},
+		)
+		assert.Equal(t, "let x = a + c!", fixed)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("overlapping", func(t *testing.T) {
+
+		t.Parallel()
+
+		fixed, count := analysis.ApplyFixes(
+			"let x = y!!",
+			[]analysis.Diagnostic{
+				diagnostic(analysis.TextEdit{
+					Replacement: "z",
+					Range:       offsetRange(8, 9),
+				}),
+				diagnostic(analysis.TextEdit{
+					Range: offsetRange(9, 10),
+				}),
+				diagnostic(analysis.TextEdit{
+					Insertion: "let a = 1\n",
+					Range:     offsetRange(0, 0),
+				}),
+			},
+		)
+		assert.Equal(t, "let a = 1\nlet x = z!", fixed)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("out of bounds", func(t *testing.T) {
+
+		t.Parallel()
+
+		fixed, count := analysis.ApplyFixes(
+			"let x = y",
+			[]analysis.Diagnostic{
+				diagnostic(analysis.TextEdit{
+					Range: offsetRange(9, 9),
+				}),
+			},
+		)
+		assert.Equal(t, "let x = y", fixed)
+		assert.Equal(t, 0, count)
+	})
+}
@@ -19,6 +19,8 @@
 package analysis
 
 import (
+	"sort"
+
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/parser"
@@ -28,6 +30,24 @@ import (
 
 type Programs map[common.Location]*Program
 
+// Run runs the given DAG of analyzers on all programs,
+// in the order of their locations
+//
+func (programs Programs) Run(analyzers []*Analyzer, report func(Diagnostic)) {
+	locations := make([]common.Location, 0, len(programs))
+	for location := range programs { //nolint:maprangecheck
+		locations = append(locations, location)
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].ID() < locations[j].ID()
+	})
+
+	for _, location := range locations {
+		programs[location].Run(analyzers, report)
+	}
+}
+
 func (programs Programs) Load(config *Config, location common.Location) error {
 	return programs.load(config, location, nil, ast.Range{})
 }
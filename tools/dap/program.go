@@ -0,0 +1,356 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/pretty"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+// program is a launched program
+//
+type program struct {
+	location    common.StringLocation
+	code        string
+	checker     *sema.Checker
+	interpreter *interpreter.Interpreter
+}
+
+// newProgram parses and checks the program at the given path,
+// and prepares an interpreter for it, which uses an in-memory storage.
+//
+// Messages logged by the program are passed to the given log function.
+//
+func newProgram(
+	path string,
+	debugger *interpreter.Debugger,
+	log func(message string),
+) (
+	*program,
+	error,
+) {
+	codeBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &program{
+		location: common.NewStringLocation(nil, path),
+		code:     string(codeBytes),
+	}
+
+	astProgram, err := parser.ParseProgram(p.code, nil)
+	if err != nil {
+		return nil, p.formatError(err)
+	}
+
+	semaPredeclaredValues, interpreterPredeclaredValues :=
+		stdlib.FlowDefaultPredeclaredValues(p.builtinImpls(log))
+
+	p.checker, err = sema.NewChecker(
+		astProgram,
+		p.location,
+		nil,
+		false,
+		sema.WithPredeclaredValues(semaPredeclaredValues),
+		sema.WithPredeclaredTypes(stdlib.FlowDefaultPredeclaredTypes),
+		sema.WithImportHandler(
+			func(_ *sema.Checker, importedLocation common.Location, _ ast.Range) (sema.Import, error) {
+				return nil, fmt.Errorf("cannot import `%s`: imports are not supported", importedLocation)
+			},
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.checker.Check()
+	if err != nil {
+		return nil, p.formatError(err)
+	}
+
+	var uuid uint64
+
+	storage := interpreter.NewInMemoryStorage(nil)
+
+	// NOTE: storage option must be provided *before* the predeclared values option,
+	// as predeclared values may rely on storage
+
+	p.interpreter, err = interpreter.NewInterpreter(
+		interpreter.ProgramFromChecker(p.checker),
+		p.location,
+		interpreter.WithStorage(storage),
+		interpreter.WithUUIDHandler(func() (uint64, error) {
+			defer func() { uuid++ }()
+			return uuid, nil
+		}),
+		interpreter.WithDebugger(debugger),
+		interpreter.WithPredeclaredValues(interpreterPredeclaredValues),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *program) builtinImpls(log func(message string)) stdlib.FlowBuiltinImpls {
+	impls := stdlib.DefaultFlowBuiltinImpls()
+
+	impls.Log = func(invocation interpreter.Invocation) interpreter.Value {
+		log(invocation.Arguments[0].MeteredString(invocation.Interpreter, interpreter.SeenReferences{}))
+		return interpreter.VoidValue{}
+	}
+
+	return impls
+}
+
+// formatError returns the given error, pretty-printed
+//
+func (p *program) formatError(err error) error {
+	var buffer bytes.Buffer
+
+	printErr := pretty.NewErrorPrettyPrinter(&buffer, false).
+		PrettyPrintError(
+			err,
+			p.location,
+			map[common.Location]string{
+				p.location: p.code,
+			},
+		)
+	if printErr != nil {
+		return err
+	}
+
+	return fmt.Errorf("%s", buffer.String())
+}
+
+// run interprets the program.
+//
+// If the program declares a transaction, the transaction is executed
+// with the given arguments and signers.
+// Otherwise, if the program declares a function `main`,
+// the function is invoked with the given arguments and its result is returned.
+//
+func (p *program) run(arguments []json.RawMessage, signers []string) (result interpreter.Value, err error) {
+	inter := p.interpreter
+
+	err = inter.Interpret()
+	if err != nil {
+		return nil, p.formatError(err)
+	}
+
+	elaboration := p.checker.Elaboration
+
+	if len(elaboration.TransactionTypes) > 0 {
+		transactionType := elaboration.TransactionTypes[0]
+
+		transactionArguments, err := p.importArguments(arguments, transactionType.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		signerAccounts, err := p.signerAccounts(signers, len(transactionType.PrepareParameters))
+		if err != nil {
+			return nil, err
+		}
+
+		err = inter.InvokeTransaction(0, append(transactionArguments, signerAccounts...)...)
+		if err != nil {
+			return nil, p.formatError(err)
+		}
+
+		return nil, nil
+	}
+
+	if !inter.Globals.Contains(sema.FunctionEntryPointName) {
+		return nil, nil
+	}
+
+	functionType, err := elaboration.FunctionEntryPointType()
+	if err != nil {
+		return nil, p.formatError(err)
+	}
+
+	mainArguments, err := p.importArguments(arguments, functionType.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = inter.Invoke(sema.FunctionEntryPointName, mainArguments...)
+	if err != nil {
+		return nil, p.formatError(err)
+	}
+
+	return result, nil
+}
+
+// importArguments decodes the given JSON-encoded Cadence values
+// and imports them as arguments for the given parameters
+//
+func (p *program) importArguments(
+	arguments []json.RawMessage,
+	parameters []*sema.Parameter,
+) (
+	[]interpreter.Value,
+	error,
+) {
+	if len(arguments) != len(parameters) {
+		return nil, fmt.Errorf(
+			"incorrect number of arguments: expected %d, got %d",
+			len(parameters),
+			len(arguments),
+		)
+	}
+
+	values := make([]interpreter.Value, 0, len(arguments))
+
+	for i, argument := range arguments {
+		parameter := parameters[i]
+
+		decoded, err := jsoncdc.Decode(nil, argument)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+
+		value, err := runtime.ImportValue(
+			p.interpreter,
+			interpreter.ReturnEmptyLocationRange,
+			decoded,
+			parameter.TypeAnnotation.Type,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// signerAccounts returns the given number of signer accounts.
+//
+// The addresses of the accounts are the given addresses,
+// followed by the addresses 0x1, 0x2, etc., if there are not enough addresses
+//
+func (p *program) signerAccounts(addresses []string, count int) ([]interpreter.Value, error) {
+	accounts := make([]interpreter.Value, 0, count)
+
+	for i := 0; i < count; i++ {
+		var address common.Address
+
+		if i < len(addresses) {
+			var err error
+			address, err = common.HexToAddress(addresses[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid signer address %q: %w", addresses[i], err)
+			}
+		} else {
+			address = common.MustBytesToAddress([]byte{byte(i + 1)})
+		}
+
+		accounts = append(
+			accounts,
+			p.newAuthAccountValue(interpreter.NewUnmeteredAddressValueFromBytes(address[:])),
+		)
+	}
+
+	return accounts, nil
+}
+
+// newAuthAccountValue returns a new account which only supports the storage API,
+// as there is no network the keys and contracts could be stored in
+//
+func (p *program) newAuthAccountValue(address interpreter.AddressValue) interpreter.Value {
+	inter := p.interpreter
+
+	unsupportedFunction := interpreter.NewHostFunctionValue(
+		inter,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			panic(errors.NewDefaultUserError("account keys and contracts are not supported"))
+		},
+		stdlib.PanicFunction.Type,
+	)
+
+	returnZeroUFix64 := func() interpreter.UFix64Value {
+		return interpreter.NewUnmeteredUFix64Value(0)
+	}
+
+	returnZeroUInt64 := func(_ *interpreter.Interpreter) interpreter.UInt64Value {
+		return interpreter.NewUnmeteredUInt64Value(0)
+	}
+
+	return interpreter.NewAuthAccountValue(
+		inter,
+		address,
+		returnZeroUFix64,
+		returnZeroUFix64,
+		returnZeroUInt64,
+		returnZeroUInt64,
+		unsupportedFunction,
+		unsupportedFunction,
+		func() interpreter.Value {
+			return interpreter.NewAuthAccountContractsValue(
+				inter,
+				address,
+				unsupportedFunction,
+				unsupportedFunction,
+				unsupportedFunction,
+				unsupportedFunction,
+				func(
+					inter *interpreter.Interpreter,
+					getLocationRange func() interpreter.LocationRange,
+				) *interpreter.ArrayValue {
+					return interpreter.NewArrayValue(
+						inter,
+						getLocationRange,
+						interpreter.VariableSizedStaticType{
+							Type: interpreter.PrimitiveStaticTypeString,
+						},
+						common.Address{},
+					)
+				},
+			)
+		},
+		func() interpreter.Value {
+			return interpreter.NewAuthAccountKeysValue(
+				inter,
+				address,
+				unsupportedFunction,
+				unsupportedFunction,
+				unsupportedFunction,
+			)
+		},
+	)
+}
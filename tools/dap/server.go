@@ -0,0 +1,817 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+// threadID is the ID of the only thread of a program
+const threadID = 1
+
+const (
+	stopReasonEntry      = "entry"
+	stopReasonBreakpoint = "breakpoint"
+	stopReasonStep       = "step"
+	stopReasonPause      = "pause"
+)
+
+type ObjectStream = jsonrpc2.ObjectStream
+
+// NewStdioStream returns a stream which reads and writes
+// Content-Length framed messages from STDIN and to STDOUT.
+//
+func NewStdioStream() ObjectStream {
+	return jsonrpc2.NewBufferedStream(
+		StdinStdoutReadWriterCloser{},
+		jsonrpc2.VSCodeObjectCodec{},
+	)
+}
+
+type handler func(server *Server, arguments json.RawMessage) (any, error)
+
+var handlers = map[string]handler{
+	"initialize":              (*Server).initialize,
+	"launch":                  (*Server).launch,
+	"setBreakpoints":          (*Server).setBreakpoints,
+	"setFunctionBreakpoints":  (*Server).setFunctionBreakpoints,
+	"setExceptionBreakpoints": (*Server).setExceptionBreakpoints,
+	"configurationDone":       (*Server).configurationDone,
+	"threads":                 (*Server).threads,
+	"stackTrace":              (*Server).stackTrace,
+	"scopes":                  (*Server).scopes,
+	"variables":               (*Server).variables,
+	"evaluate":                (*Server).evaluate,
+	"continue":                (*Server).continueProgram,
+	"next":                    (*Server).next,
+	"stepIn":                  (*Server).stepIn,
+	"stepOut":                 (*Server).stepOut,
+	"pause":                   (*Server).pause,
+	"disconnect":              (*Server).disconnect,
+}
+
+// Server is a Debug Adapter Protocol server for Cadence programs.
+//
+// Requests are handled sequentially, in the order they are received.
+// The launched program is run concurrently, and its stops are reported as events.
+//
+type Server struct {
+	stream ObjectStream
+
+	// writeLock guards the sequence number and writes to the stream
+	writeLock sync.Mutex
+	seq       int
+
+	// afterResponse are functions which are called after the response
+	// to the current request was sent, e.g. to send events or continue the program
+	afterResponse []func()
+
+	debugger *interpreter.Debugger
+
+	// lock guards the state below, which is accessed
+	// by the request handlers and by the goroutines running the program
+	lock                sync.Mutex
+	program             *program
+	launchArguments     LaunchRequestArguments
+	configured          bool
+	started             bool
+	disconnected        bool
+	done                chan struct{}
+	stop                *interpreter.Stop
+	stopReason          string
+	containers          variableContainers
+	breakpointID        int
+	lineBreakpoints     map[common.Location][]uint
+	functionBreakpoints []string
+}
+
+func NewServer(stream ObjectStream) *Server {
+	return &Server{
+		stream:          stream,
+		debugger:        interpreter.NewDebugger(),
+		done:            make(chan struct{}),
+		stopReason:      stopReasonBreakpoint,
+		lineBreakpoints: map[common.Location][]uint{},
+	}
+}
+
+// Run handles requests until the client disconnects
+//
+func (s *Server) Run() error {
+	for {
+		var request Request
+		err := s.stream.ReadObject(&request)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				s.shutdown()
+				return nil
+			}
+			return err
+		}
+
+		if request.Type != messageTypeRequest {
+			continue
+		}
+
+		err = s.handle(&request)
+		if err != nil {
+			return err
+		}
+
+		if request.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(request *Request) error {
+	var body any
+	var err error
+
+	handler, ok := handlers[request.Command]
+	if ok {
+		body, err = handler(s, request.Arguments)
+	} else {
+		err = fmt.Errorf("unsupported request: %s", request.Command)
+	}
+
+	response := Response{
+		ProtocolMessage: ProtocolMessage{
+			Type: messageTypeResponse,
+		},
+		RequestSeq: request.Seq,
+		Success:    err == nil,
+		Command:    request.Command,
+		Body:       body,
+	}
+	if err != nil {
+		response.Message = err.Error()
+	}
+
+	err = s.send(&response, &response.Seq)
+
+	afterResponse := s.afterResponse
+	s.afterResponse = nil
+
+	if err != nil {
+		return err
+	}
+
+	for _, f := range afterResponse {
+		f()
+	}
+
+	return nil
+}
+
+func (s *Server) send(message any, seq *int) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	s.seq++
+	*seq = s.seq
+
+	return s.stream.WriteObject(message)
+}
+
+func (s *Server) sendEvent(event string, body any) {
+	message := Event{
+		ProtocolMessage: ProtocolMessage{
+			Type: messageTypeEvent,
+		},
+		Event: event,
+		Body:  body,
+	}
+
+	// Events are sent asynchronously,
+	// so there is no request to report a write error for
+	_ = s.send(&message, &message.Seq)
+}
+
+func (s *Server) output(category string, output string) {
+	s.sendEvent(
+		"output",
+		OutputEventBody{
+			Category: category,
+			Output:   output,
+		},
+	)
+}
+
+func decodeArguments(arguments json.RawMessage, target any) error {
+	if len(arguments) == 0 {
+		return nil
+	}
+	return json.Unmarshal(arguments, target)
+}
+
+func (s *Server) initialize(_ json.RawMessage) (any, error) {
+	s.afterResponse = append(s.afterResponse, func() {
+		s.sendEvent("initialized", nil)
+	})
+
+	return Capabilities{
+		SupportsConfigurationDoneRequest:  true,
+		SupportsFunctionBreakpoints:       true,
+		SupportsConditionalBreakpoints:    true,
+		SupportsHitConditionalBreakpoints: true,
+		SupportsEvaluateForHovers:         true,
+	}, nil
+}
+
+func (s *Server) launch(rawArguments json.RawMessage) (any, error) {
+	var arguments LaunchRequestArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	if arguments.Program == "" {
+		return nil, fmt.Errorf("missing program")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.program != nil {
+		return nil, fmt.Errorf("program already launched")
+	}
+
+	var debugger *interpreter.Debugger
+	if !arguments.NoDebug {
+		debugger = s.debugger
+	}
+
+	program, err := newProgram(
+		arguments.Program,
+		debugger,
+		func(message string) {
+			s.output("stdout", message+"\n")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.program = program
+	s.launchArguments = arguments
+
+	for _, name := range s.functionBreakpoints {
+		s.debugger.AddFunctionBreakpoint(program.location, name)
+	}
+
+	s.afterResponse = append(s.afterResponse, s.startIfReady)
+
+	return nil, nil
+}
+
+func (s *Server) configurationDone(_ json.RawMessage) (any, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.configured = true
+
+	s.afterResponse = append(s.afterResponse, s.startIfReady)
+
+	return nil, nil
+}
+
+// startIfReady starts the program once it is launched
+// and the client finished the configuration, e.g. of the breakpoints
+//
+func (s *Server) startIfReady() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.program == nil || !s.configured || s.started {
+		return
+	}
+
+	s.started = true
+
+	if s.launchArguments.StopOnEntry && !s.launchArguments.NoDebug {
+		s.stopReason = stopReasonEntry
+		s.debugger.RequestPause()
+	}
+
+	go s.handleStops()
+	go s.runProgram(s.program, s.launchArguments)
+}
+
+func (s *Server) runProgram(program *program, arguments LaunchRequestArguments) {
+	defer close(s.done)
+
+	exitCode := 0
+
+	result, err := program.run(arguments.Arguments, arguments.Signers)
+	if err != nil {
+		s.output("stderr", err.Error()+"\n")
+		exitCode = 1
+	} else if result != nil {
+		if _, ok := result.(interpreter.VoidValue); !ok {
+			s.output("console", fmt.Sprintf("Result: %s\n", result))
+		}
+	}
+
+	s.sendEvent("exited", ExitedEventBody{ExitCode: exitCode})
+	s.sendEvent("terminated", nil)
+}
+
+// handleStops reports the stops of the program until the program finished
+//
+func (s *Server) handleStops() {
+	for {
+		select {
+		case stop := <-s.debugger.Stops():
+			s.lock.Lock()
+
+			if s.disconnected {
+				s.lock.Unlock()
+				s.debugger.Continue()
+				continue
+			}
+
+			s.stop = &stop
+			s.containers.reset()
+			reason := s.stopReason
+			s.stopReason = stopReasonBreakpoint
+
+			s.lock.Unlock()
+
+			s.sendEvent(
+				"stopped",
+				StoppedEventBody{
+					Reason:            reason,
+					ThreadID:          threadID,
+					AllThreadsStopped: true,
+				},
+			)
+
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// resume resumes the stopped program after the response was sent,
+// using the given function of the debugger
+//
+func (s *Server) resume(reason string, resume func()) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stop == nil {
+		return fmt.Errorf("program is not stopped")
+	}
+
+	s.stop = nil
+	s.containers.reset()
+	s.stopReason = reason
+
+	s.afterResponse = append(s.afterResponse, resume)
+
+	return nil
+}
+
+func (s *Server) continueProgram(_ json.RawMessage) (any, error) {
+	err := s.resume(stopReasonBreakpoint, s.debugger.Continue)
+	if err != nil {
+		return nil, err
+	}
+
+	return ContinueResponseBody{
+		AllThreadsContinued: true,
+	}, nil
+}
+
+func (s *Server) next(_ json.RawMessage) (any, error) {
+	return nil, s.resume(stopReasonStep, s.debugger.ContinueStepOver)
+}
+
+func (s *Server) stepIn(_ json.RawMessage) (any, error) {
+	return nil, s.resume(stopReasonStep, s.debugger.ContinueStepIn)
+}
+
+func (s *Server) stepOut(_ json.RawMessage) (any, error) {
+	return nil, s.resume(stopReasonStep, s.debugger.ContinueStepOut)
+}
+
+func (s *Server) pause(_ json.RawMessage) (any, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stop != nil {
+		return nil, nil
+	}
+
+	s.stopReason = stopReasonPause
+	s.debugger.RequestPause()
+
+	return nil, nil
+}
+
+func (s *Server) disconnect(_ json.RawMessage) (any, error) {
+	s.shutdown()
+	return nil, nil
+}
+
+// shutdown lets the program run to completion without stopping
+//
+func (s *Server) shutdown() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.disconnected = true
+	s.debugger.ClearBreakpoints()
+
+	if s.stop != nil {
+		s.stop = nil
+		s.containers.reset()
+		s.debugger.Continue()
+	}
+}
+
+func (s *Server) setBreakpoints(rawArguments json.RawMessage) (any, error) {
+	var arguments SetBreakpointsArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	location := common.NewStringLocation(nil, arguments.Source.Path)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, line := range s.lineBreakpoints[location] {
+		s.debugger.RemoveBreakpoint(location, line)
+	}
+
+	lines := make([]uint, 0, len(arguments.Breakpoints))
+	breakpoints := make([]Breakpoint, 0, len(arguments.Breakpoints))
+
+	for _, sourceBreakpoint := range arguments.Breakpoints {
+		s.breakpointID++
+
+		breakpoint := Breakpoint{
+			ID:     s.breakpointID,
+			Source: &arguments.Source,
+			Line:   sourceBreakpoint.Line,
+		}
+
+		conditions, err := breakpointConditions(sourceBreakpoint)
+		if err != nil {
+			breakpoint.Message = err.Error()
+		} else {
+			line := uint(sourceBreakpoint.Line)
+
+			if conditions.Condition != nil || conditions.HitCount > 0 {
+				s.debugger.AddConditionalBreakpoint(location, line, conditions)
+			} else {
+				s.debugger.AddBreakpoint(location, line)
+			}
+
+			lines = append(lines, line)
+			breakpoint.Verified = true
+		}
+
+		breakpoints = append(breakpoints, breakpoint)
+	}
+
+	s.lineBreakpoints[location] = lines
+
+	return SetBreakpointsResponseBody{
+		Breakpoints: breakpoints,
+	}, nil
+}
+
+func breakpointConditions(sourceBreakpoint SourceBreakpoint) (interpreter.BreakpointConditions, error) {
+	var conditions interpreter.BreakpointConditions
+
+	if sourceBreakpoint.Condition != "" {
+		condition, errs := parser.ParseExpression(sourceBreakpoint.Condition, nil)
+		if len(errs) > 0 {
+			return conditions, fmt.Errorf("invalid condition: %w", errs[0])
+		}
+		conditions.Condition = condition
+	}
+
+	if sourceBreakpoint.HitCondition != "" {
+		hitCount, err := strconv.ParseUint(sourceBreakpoint.HitCondition, 10, 0)
+		if err != nil {
+			return conditions, fmt.Errorf("invalid hit condition: must be a number of hits")
+		}
+		conditions.HitCount = uint(hitCount)
+	}
+
+	return conditions, nil
+}
+
+func (s *Server) setFunctionBreakpoints(rawArguments json.RawMessage) (any, error) {
+	var arguments SetFunctionBreakpointsArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Function breakpoints are always set in the launched program.
+	// If the program is not launched yet, they are set when it is launched
+
+	if s.program != nil {
+		for _, name := range s.functionBreakpoints {
+			s.debugger.RemoveFunctionBreakpoint(s.program.location, name)
+		}
+	}
+
+	s.functionBreakpoints = make([]string, 0, len(arguments.Breakpoints))
+	breakpoints := make([]Breakpoint, 0, len(arguments.Breakpoints))
+
+	for _, functionBreakpoint := range arguments.Breakpoints {
+		s.functionBreakpoints = append(s.functionBreakpoints, functionBreakpoint.Name)
+
+		if s.program != nil {
+			s.debugger.AddFunctionBreakpoint(s.program.location, functionBreakpoint.Name)
+		}
+
+		s.breakpointID++
+		breakpoints = append(
+			breakpoints,
+			Breakpoint{
+				ID:       s.breakpointID,
+				Verified: true,
+			},
+		)
+	}
+
+	return SetBreakpointsResponseBody{
+		Breakpoints: breakpoints,
+	}, nil
+}
+
+func (s *Server) setExceptionBreakpoints(_ json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *Server) threads(_ json.RawMessage) (any, error) {
+	return ThreadsResponseBody{
+		Threads: []Thread{
+			{
+				ID:   threadID,
+				Name: "main",
+			},
+		},
+	}, nil
+}
+
+// frameID returns the ID of the frame with the given index in the call stack.
+//
+// The frame IDs start at 1, as the ID 0 indicates the innermost frame in evaluate requests
+//
+func frameID(index int) int {
+	return index + 1
+}
+
+// stoppedFrame returns the frame with the given ID of the current stop.
+// The frame ID 0 refers to the innermost frame.
+//
+// NOTE: lock must be held
+//
+func (s *Server) stoppedFrame(id int) (interpreter.StackFrame, error) {
+	if s.stop == nil {
+		return interpreter.StackFrame{}, fmt.Errorf("program is not stopped")
+	}
+
+	callStack := s.stop.CallStack
+
+	if id == 0 {
+		return callStack[len(callStack)-1], nil
+	}
+
+	index := id - 1
+	if index < 0 || index >= len(callStack) {
+		return interpreter.StackFrame{}, fmt.Errorf("unknown frame: %d", id)
+	}
+
+	return callStack[index], nil
+}
+
+func (s *Server) stackTrace(rawArguments json.RawMessage) (any, error) {
+	var arguments StackTraceArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stackFrames := []StackFrame{}
+
+	if s.stop == nil {
+		return StackTraceResponseBody{
+			StackFrames: stackFrames,
+		}, nil
+	}
+
+	callStack := s.stop.CallStack
+
+	// Frames are reported from the innermost to the outermost frame
+
+	for i := len(callStack) - 1; i >= 0; i-- {
+		frame := callStack[i]
+
+		name := frame.FunctionName
+		if name == "" {
+			name = "<anonymous>"
+		}
+
+		stackFrame := StackFrame{
+			ID:     frameID(i),
+			Name:   name,
+			Source: locationSource(frame.Interpreter.Location),
+		}
+
+		if frame.Statement != nil {
+			position := frame.Statement.StartPosition()
+			stackFrame.Line = position.Line
+			stackFrame.Column = position.Column + 1
+		}
+
+		stackFrames = append(stackFrames, stackFrame)
+	}
+
+	totalFrames := len(stackFrames)
+
+	start := arguments.StartFrame
+	if start > totalFrames {
+		start = totalFrames
+	}
+
+	end := totalFrames
+	if arguments.Levels > 0 && start+arguments.Levels < end {
+		end = start + arguments.Levels
+	}
+
+	return StackTraceResponseBody{
+		StackFrames: stackFrames[start:end],
+		TotalFrames: totalFrames,
+	}, nil
+}
+
+func locationSource(location common.Location) *Source {
+	if stringLocation, ok := location.(common.StringLocation); ok {
+		path := string(stringLocation)
+		return &Source{
+			Name: filepath.Base(path),
+			Path: path,
+		}
+	}
+
+	return &Source{
+		Name: location.String(),
+	}
+}
+
+func (s *Server) scopes(rawArguments json.RawMessage) (any, error) {
+	var arguments ScopesArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	frame, err := s.stoppedFrame(arguments.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []Scope{}
+
+	if frame.Activation != nil {
+		scopes = append(
+			scopes,
+			Scope{
+				Name: "Locals",
+				VariablesReference: s.containers.add(variableContainer{
+					interpreter: frame.Interpreter,
+					activation:  frame.Activation,
+				}),
+			},
+		)
+	}
+
+	if frame.Interpreter.Program != nil {
+		scopes = append(
+			scopes,
+			Scope{
+				Name: "Globals",
+				VariablesReference: s.containers.add(variableContainer{
+					interpreter: frame.Interpreter,
+					elaboration: frame.Interpreter.Program.Elaboration,
+				}),
+			},
+		)
+	}
+
+	return ScopesResponseBody{
+		Scopes: scopes,
+	}, nil
+}
+
+func (s *Server) variables(rawArguments json.RawMessage) (any, error) {
+	var arguments VariablesArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stop == nil {
+		return nil, fmt.Errorf("program is not stopped")
+	}
+
+	container, ok := s.containers.get(arguments.VariablesReference)
+	if !ok {
+		return nil, fmt.Errorf("unknown variables reference: %d", arguments.VariablesReference)
+	}
+
+	return VariablesResponseBody{
+		Variables: s.containers.variables(container),
+	}, nil
+}
+
+func (s *Server) evaluate(rawArguments json.RawMessage) (any, error) {
+	var arguments EvaluateArguments
+	err := decodeArguments(rawArguments, &arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, errs := parser.ParseExpression(arguments.Expression, nil)
+	if len(errs) > 0 {
+		return nil, parser.Error{
+			Code:   arguments.Expression,
+			Errors: errs,
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	frame, err := s.stoppedFrame(arguments.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := s.debugger.Evaluate(
+		frame.Interpreter,
+		frame.Activation,
+		expression,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	variable := s.containers.newVariable(frame.Interpreter, "", value)
+
+	return EvaluateResponseBody{
+		Result:             variable.Value,
+		Type:               variable.Type,
+		VariablesReference: variable.VariablesReference,
+	}, nil
+}
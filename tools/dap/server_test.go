@@ -0,0 +1,487 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	Type    string          `json:"type"`
+	Command string          `json:"command"`
+	Event   string          `json:"event"`
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type testClient struct {
+	t        *testing.T
+	stream   ObjectStream
+	seq      int
+	messages chan testMessage
+	// pending are the received messages which were not expected yet
+	pending []testMessage
+	done    chan error
+}
+
+// newTestClient starts a server and returns a client connected to it
+//
+func newTestClient(t *testing.T) *testClient {
+	serverConn, clientConn := net.Pipe()
+
+	server := NewServer(
+		jsonrpc2.NewBufferedStream(serverConn, jsonrpc2.VSCodeObjectCodec{}),
+	)
+
+	client := &testClient{
+		t:        t,
+		stream:   jsonrpc2.NewBufferedStream(clientConn, jsonrpc2.VSCodeObjectCodec{}),
+		messages: make(chan testMessage, 100),
+		done:     make(chan error, 1),
+	}
+
+	go func() {
+		client.done <- server.Run()
+		_ = serverConn.Close()
+	}()
+
+	go func() {
+		defer close(client.messages)
+		for {
+			var message testMessage
+			err := client.stream.ReadObject(&message)
+			if err != nil {
+				return
+			}
+			client.messages <- message
+		}
+	}()
+
+	return client
+}
+
+func (c *testClient) send(command string, arguments any) {
+	c.seq++
+
+	rawArguments, err := json.Marshal(arguments)
+	require.NoError(c.t, err)
+
+	err = c.stream.WriteObject(Request{
+		ProtocolMessage: ProtocolMessage{
+			Seq:  c.seq,
+			Type: messageTypeRequest,
+		},
+		Command:   command,
+		Arguments: rawArguments,
+	})
+	require.NoError(c.t, err)
+}
+
+// expect returns the first received message which satisfies the given predicate
+//
+func (c *testClient) expect(predicate func(message testMessage) bool) testMessage {
+	for i, message := range c.pending {
+		if predicate(message) {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return message
+		}
+	}
+
+	for {
+		select {
+		case message, ok := <-c.messages:
+			require.True(c.t, ok, "connection closed")
+
+			if predicate(message) {
+				return message
+			}
+			c.pending = append(c.pending, message)
+
+		case <-time.After(10 * time.Second):
+			require.FailNow(c.t, "timeout")
+		}
+	}
+}
+
+// request sends the given request and returns the body of the successful response
+//
+func (c *testClient) request(command string, arguments any, body any) {
+	c.send(command, arguments)
+
+	response := c.expectResponse(command)
+	require.True(c.t, response.Success, response.Message)
+
+	if body != nil {
+		require.NoError(c.t, json.Unmarshal(response.Body, body))
+	}
+}
+
+func (c *testClient) expectResponse(command string) testMessage {
+	return c.expect(func(message testMessage) bool {
+		return message.Type == messageTypeResponse && message.Command == command
+	})
+}
+
+func (c *testClient) expectEvent(event string, body any) {
+	message := c.expect(func(message testMessage) bool {
+		return message.Type == messageTypeEvent && message.Event == event
+	})
+
+	if body != nil {
+		require.NoError(c.t, json.Unmarshal(message.Body, body))
+	}
+}
+
+func (c *testClient) disconnect() {
+	c.request("disconnect", nil, nil)
+	require.NoError(c.t, <-c.done)
+}
+
+func writeTestProgram(t *testing.T, code string) string {
+	path := filepath.Join(t.TempDir(), "test.cdc")
+	require.NoError(t, os.WriteFile(path, []byte(code), 0644))
+	return path
+}
+
+func TestServerDebugging(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestProgram(t, `pub struct S {
+    pub let x: Int
+
+    init(x: Int) {
+        self.x = x
+    }
+}
+
+pub fun add(_ a: Int, _ b: Int): Int {
+    let sum = a + b
+    return sum
+}
+
+pub fun main(): Int {
+    let s = S(x: 1)
+    let values = [1, 2]
+    log("start")
+    let total = add(s.x, values[1])
+    return total
+}
+`)
+
+	client := newTestClient(t)
+
+	var capabilities Capabilities
+	client.request("initialize", nil, &capabilities)
+	assert.True(t, capabilities.SupportsConditionalBreakpoints)
+
+	client.expectEvent("initialized", nil)
+
+	client.request(
+		"launch",
+		LaunchRequestArguments{
+			Program: path,
+		},
+		nil,
+	)
+
+	var breakpoints SetBreakpointsResponseBody
+	client.request(
+		"setBreakpoints",
+		SetBreakpointsArguments{
+			Source: Source{Path: path},
+			Breakpoints: []SourceBreakpoint{
+				{Line: 19},
+				{Line: 15, Condition: "true &&"},
+			},
+		},
+		&breakpoints,
+	)
+	require.Len(t, breakpoints.Breakpoints, 2)
+	assert.True(t, breakpoints.Breakpoints[0].Verified)
+	assert.False(t, breakpoints.Breakpoints[1].Verified)
+
+	client.request(
+		"setFunctionBreakpoints",
+		SetFunctionBreakpointsArguments{
+			Breakpoints: []FunctionBreakpoint{
+				{Name: "add"},
+			},
+		},
+		nil,
+	)
+
+	client.request("configurationDone", nil, nil)
+
+	var output OutputEventBody
+	client.expectEvent("output", &output)
+	assert.Equal(t, "\"start\"\n", output.Output)
+
+	// Stop at the function breakpoint
+
+	var stopped StoppedEventBody
+	client.expectEvent("stopped", &stopped)
+	assert.Equal(t, stopReasonBreakpoint, stopped.Reason)
+
+	var stackTrace StackTraceResponseBody
+	client.request(
+		"stackTrace",
+		StackTraceArguments{ThreadID: threadID},
+		&stackTrace,
+	)
+	require.Len(t, stackTrace.StackFrames, 2)
+
+	addFrame := stackTrace.StackFrames[0]
+	assert.Equal(t, "add", addFrame.Name)
+	assert.Equal(t, 10, addFrame.Line)
+	assert.Equal(t, path, addFrame.Source.Path)
+
+	mainFrame := stackTrace.StackFrames[1]
+	assert.Equal(t, "main", mainFrame.Name)
+	assert.Equal(t, 18, mainFrame.Line)
+
+	// Locals of the innermost frame
+
+	var scopes ScopesResponseBody
+	client.request("scopes", ScopesArguments{FrameID: addFrame.ID}, &scopes)
+	require.Len(t, scopes.Scopes, 2)
+	assert.Equal(t, "Locals", scopes.Scopes[0].Name)
+	assert.Equal(t, "Globals", scopes.Scopes[1].Name)
+
+	var variables VariablesResponseBody
+	client.request(
+		"variables",
+		VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference},
+		&variables,
+	)
+	assert.Equal(t,
+		[]Variable{
+			{Name: "a", Value: "1", Type: "Int"},
+			{Name: "b", Value: "2", Type: "Int"},
+		},
+		variables.Variables,
+	)
+
+	// Evaluate in the innermost frame
+
+	var evaluated EvaluateResponseBody
+	client.request("evaluate", EvaluateArguments{Expression: "a + b"}, &evaluated)
+	assert.Equal(t,
+		EvaluateResponseBody{
+			Result: "3",
+			Type:   "Int",
+		},
+		evaluated,
+	)
+
+	// Evaluate and expand a composite in the calling frame
+
+	client.request(
+		"evaluate",
+		EvaluateArguments{
+			Expression: "s",
+			FrameID:    mainFrame.ID,
+		},
+		&evaluated,
+	)
+	assert.Equal(t, fmt.Sprintf("S.%s.S(x: 1)", path), evaluated.Result)
+	require.NotZero(t, evaluated.VariablesReference)
+
+	client.request(
+		"variables",
+		VariablesArguments{VariablesReference: evaluated.VariablesReference},
+		&variables,
+	)
+	assert.Equal(t,
+		[]Variable{
+			{Name: "x", Value: "1", Type: "Int"},
+		},
+		variables.Variables,
+	)
+
+	// Expand an array in the locals of the calling frame
+
+	client.request("scopes", ScopesArguments{FrameID: mainFrame.ID}, &scopes)
+	client.request(
+		"variables",
+		VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference},
+		&variables,
+	)
+	require.Len(t, variables.Variables, 2)
+	assert.Equal(t, "s", variables.Variables[0].Name)
+
+	valuesVariable := variables.Variables[1]
+	assert.Equal(t, "values", valuesVariable.Name)
+	assert.Equal(t, "[1, 2]", valuesVariable.Value)
+	assert.Equal(t, "[Int]", valuesVariable.Type)
+
+	client.request(
+		"variables",
+		VariablesArguments{VariablesReference: valuesVariable.VariablesReference},
+		&variables,
+	)
+	assert.Equal(t,
+		[]Variable{
+			{Name: "0", Value: "1", Type: "Int"},
+			{Name: "1", Value: "2", Type: "Int"},
+		},
+		variables.Variables,
+	)
+
+	// Step out of the function
+
+	client.request("stepOut", nil, nil)
+
+	client.expectEvent("stopped", &stopped)
+	assert.Equal(t, stopReasonStep, stopped.Reason)
+
+	client.request(
+		"stackTrace",
+		StackTraceArguments{ThreadID: threadID},
+		&stackTrace,
+	)
+	require.Len(t, stackTrace.StackFrames, 1)
+	assert.Equal(t, 19, stackTrace.StackFrames[0].Line)
+
+	// Run to completion
+
+	client.request("continue", nil, nil)
+
+	client.expectEvent("output", &output)
+	assert.Equal(t, "Result: 3\n", output.Output)
+
+	var exited ExitedEventBody
+	client.expectEvent("exited", &exited)
+	assert.Equal(t, 0, exited.ExitCode)
+
+	client.expectEvent("terminated", nil)
+
+	client.disconnect()
+}
+
+func TestServerTransaction(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestProgram(t, `
+transaction(value: Int) {
+    prepare(signer: AuthAccount) {
+        signer.save(value, to: /storage/value)
+        log(signer.load<Int>(from: /storage/value))
+    }
+}
+`)
+
+	client := newTestClient(t)
+
+	client.request("initialize", nil, nil)
+
+	client.request(
+		"launch",
+		LaunchRequestArguments{
+			Program: path,
+			Arguments: []json.RawMessage{
+				json.RawMessage(`{"type":"Int","value":"42"}`),
+			},
+			NoDebug: true,
+		},
+		nil,
+	)
+
+	client.request("configurationDone", nil, nil)
+
+	var output OutputEventBody
+	client.expectEvent("output", &output)
+	assert.Equal(t, "42\n", output.Output)
+
+	var exited ExitedEventBody
+	client.expectEvent("exited", &exited)
+	assert.Equal(t, 0, exited.ExitCode)
+
+	client.disconnect()
+}
+
+func TestServerLaunchErrors(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("check error", func(t *testing.T) {
+
+		t.Parallel()
+
+		path := writeTestProgram(t, `pub fun main() { let x: Int = "" }`)
+
+		client := newTestClient(t)
+
+		client.request("initialize", nil, nil)
+
+		client.send("launch", LaunchRequestArguments{Program: path})
+
+		response := client.expectResponse("launch")
+		require.False(t, response.Success)
+		assert.Contains(t, response.Message, "mismatched types")
+
+		client.disconnect()
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+
+		t.Parallel()
+
+		client := newTestClient(t)
+
+		client.send(
+			"launch",
+			LaunchRequestArguments{
+				Program: filepath.Join(t.TempDir(), "missing.cdc"),
+			},
+		)
+
+		response := client.expectResponse("launch")
+		require.False(t, response.Success)
+
+		client.disconnect()
+	})
+
+	t.Run("evaluate while running", func(t *testing.T) {
+
+		t.Parallel()
+
+		client := newTestClient(t)
+
+		client.send("evaluate", EvaluateArguments{Expression: "1"})
+
+		response := client.expectResponse("evaluate")
+		require.False(t, response.Success)
+		assert.Equal(t, "program is not stopped", response.Message)
+
+		client.disconnect()
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dap
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// variableContainer is a scope or a value which has variables,
+// i.e. the target of a variables reference.
+//
+// Exactly one of the activation, the elaboration, or the value is set
+//
+type variableContainer struct {
+	interpreter *interpreter.Interpreter
+	// activation is set for the scope of the locals of a frame
+	activation *interpreter.VariableActivation
+	// elaboration is set for the scope of the globals of a program
+	elaboration *sema.Elaboration
+	// value is set for composites, arrays, and dictionaries
+	value interpreter.Value
+}
+
+// variableContainers are the variable containers of a stop.
+//
+// The variables reference of a container is its index plus one,
+// as the reference 0 indicates that there are no variables
+//
+type variableContainers struct {
+	containers []variableContainer
+}
+
+func (c *variableContainers) add(container variableContainer) int {
+	c.containers = append(c.containers, container)
+	return len(c.containers)
+}
+
+func (c *variableContainers) get(reference int) (variableContainer, bool) {
+	if reference < 1 || reference > len(c.containers) {
+		return variableContainer{}, false
+	}
+	return c.containers[reference-1], true
+}
+
+func (c *variableContainers) reset() {
+	c.containers = nil
+}
+
+// valueReference returns the variables reference for the given value.
+// Returns 0 if the value has no variables
+//
+func (c *variableContainers) valueReference(inter *interpreter.Interpreter, value interpreter.Value) int {
+	if someValue, ok := value.(*interpreter.SomeValue); ok {
+		value = someValue.InnerValue(inter, interpreter.ReturnEmptyLocationRange)
+	}
+
+	switch value := value.(type) {
+	case *interpreter.CompositeValue,
+		*interpreter.SimpleCompositeValue:
+
+		return c.add(variableContainer{
+			interpreter: inter,
+			value:       value,
+		})
+
+	case *interpreter.ArrayValue:
+		if value.Count() == 0 {
+			return 0
+		}
+		return c.add(variableContainer{
+			interpreter: inter,
+			value:       value,
+		})
+
+	case *interpreter.DictionaryValue:
+		if value.Count() == 0 {
+			return 0
+		}
+		return c.add(variableContainer{
+			interpreter: inter,
+			value:       value,
+		})
+
+	default:
+		return 0
+	}
+}
+
+// newVariable returns the variable with the given name and value.
+// Composites, arrays, and dictionaries can be expanded
+//
+func (c *variableContainers) newVariable(
+	inter *interpreter.Interpreter,
+	name string,
+	value interpreter.Value,
+) Variable {
+	return Variable{
+		Name:               name,
+		Value:              value.String(),
+		Type:               valueType(inter, value),
+		VariablesReference: c.valueReference(inter, value),
+	}
+}
+
+func valueType(inter *interpreter.Interpreter, value interpreter.Value) string {
+	staticType := value.StaticType(inter)
+	if staticType == nil {
+		return ""
+	}
+
+	if functionStaticType, ok := staticType.(interpreter.FunctionStaticType); ok &&
+		functionStaticType.Type == nil {
+
+		return ""
+	}
+
+	return staticType.String()
+}
+
+// variables returns the variables of the given container
+//
+func (c *variableContainers) variables(container variableContainer) []Variable {
+	inter := container.interpreter
+
+	var variables []Variable
+
+	addVariable := func(name string, value interpreter.Value) {
+		variables = append(variables, c.newVariable(inter, name, value))
+	}
+
+	switch {
+	case container.activation != nil:
+		values := container.activation.FunctionValues()
+
+		names := make([]string, 0, len(values))
+		for name := range values { //nolint:maprangecheck
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			addVariable(name, values[name].GetValue())
+		}
+
+	case container.elaboration != nil:
+		container.elaboration.GlobalValues.Foreach(func(name string, _ *sema.Variable) {
+			if _, ok := container.elaboration.EffectivePredeclaredValues[name]; ok {
+				return
+			}
+
+			variable, ok := inter.Globals.Get(name)
+			if !ok {
+				return
+			}
+
+			addVariable(name, variable.GetValue())
+		})
+
+	default:
+		switch value := container.value.(type) {
+		case *interpreter.CompositeValue:
+			fields := map[string]interpreter.Value{}
+			value.ForEachField(inter, func(name string, value interpreter.Value) {
+				fields[name] = value
+			})
+
+			names := make([]string, 0, len(fields))
+			for name := range fields { //nolint:maprangecheck
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				addVariable(name, fields[name])
+			}
+
+		case *interpreter.SimpleCompositeValue:
+			for _, name := range value.FieldNames {
+				addVariable(name, value.Fields[name])
+			}
+
+		case *interpreter.ArrayValue:
+			index := 0
+			value.Iterate(inter, func(element interpreter.Value) (resume bool) {
+				addVariable(strconv.Itoa(index), element)
+				index++
+				return true
+			})
+
+		case *interpreter.DictionaryValue:
+			value.Iterate(inter, func(key, value interpreter.Value) (resume bool) {
+				addVariable(key.String(), value)
+				return true
+			})
+		}
+	}
+
+	if variables == nil {
+		variables = []Variable{}
+	}
+
+	return variables
+}
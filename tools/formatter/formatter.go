@@ -0,0 +1,427 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package formatter formats Cadence code.
+//
+// The code is formatted using the prettier documents of the AST (see ast.Prettier),
+// and the comments in the code are preserved (see parser.ParseProgramWithComments).
+//
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/turbolent/prettier"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/parser/lexer"
+)
+
+const DefaultMaxLineWidth = 80
+const DefaultIndent = "    "
+
+// A Config specifies how code is formatted.
+// The zero value is a valid configuration.
+type Config struct {
+	// MaxLineWidth is the maximum width of a line.
+	// If zero, DefaultMaxLineWidth is used.
+	MaxLineWidth int
+
+	// Indent is the string used for one level of indentation.
+	// If empty, DefaultIndent is used.
+	Indent string
+}
+
+func (c Config) maxLineWidth() int {
+	if c.MaxLineWidth <= 0 {
+		return DefaultMaxLineWidth
+	}
+	return c.MaxLineWidth
+}
+
+func (c Config) indent() string {
+	if c.Indent == "" {
+		return DefaultIndent
+	}
+	return c.Indent
+}
+
+// Format formats the given code, which must be a valid program.
+//
+func Format(code string, config Config) (string, error) {
+	program, err := parser.ParseProgramWithComments(code, nil)
+	if err != nil {
+		return "", err
+	}
+
+	formatted := render(program.Doc(), config)
+	if formatted != "" {
+		formatted += "\n"
+	}
+
+	err = checkFormatted(code, formatted)
+	if err != nil {
+		return "", err
+	}
+
+	return formatted, nil
+}
+
+// Edit is a replacement of a range of code
+//
+type Edit struct {
+	// StartPos is the position of the first replaced character
+	StartPos ast.Position
+	// EndPos is the position after the last replaced character
+	EndPos ast.Position
+	// NewText is the text which replaces the range
+	NewText string
+}
+
+// FormatRange formats the declarations and statements of the given code, which must be a valid program,
+// that overlap with the range from the given start offset (inclusive) to the given end offset (exclusive).
+//
+// If the range is inside of a composite, interface, function, or transaction declaration,
+// or inside of the block of a loop, only the nested declarations or statements overlapping with the range are formatted.
+//
+// Returns nil if there is nothing to format in the range, or if the range is already formatted.
+//
+func FormatRange(code string, startOffset, endOffset int, config Config) (*Edit, error) {
+	program, err := parser.ParseProgramWithComments(code, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if endOffset <= startOffset {
+		endOffset = startOffset + 1
+	}
+
+	items := declarationItems(program.Declarations())
+	depth := 0
+
+	for {
+		first, last := overlappingItems(items, startOffset, endOffset)
+		if first < 0 {
+			return nil, nil
+		}
+
+		if first == last {
+			nested := nestedItems(items[first].element)
+			if len(nested) > 0 {
+				nestedFirst, nestedLast := overlappingItems(nested, startOffset, endOffset)
+				if nestedFirst >= 0 &&
+					nested[nestedFirst].startOffset() <= startOffset &&
+					nested[nestedLast].endOffset() >= endOffset &&
+					(nestedFirst == nestedLast || nested[nestedFirst].contiguous) {
+
+					items = nested
+					depth++
+					continue
+				}
+			}
+		}
+
+		return formatItems(code, items[first:last+1], depth, config), nil
+	}
+}
+
+// item is a declaration or statement which can be formatted on its own
+//
+type item struct {
+	element ast.CommentedElement
+	doc     func() prettier.Doc
+	// contiguous is true if the item and the following items in the same container
+	// are not separated by anything else than comments
+	contiguous bool
+	// isStatement is true if the item is a statement,
+	// which is separated from the following statements by a line break,
+	// instead of an empty line
+	isStatement bool
+}
+
+// startOffset returns the offset of the start of the item, including its leading comments
+//
+func (i item) startOffset() int {
+	offset := i.element.StartPosition().Offset
+	for _, comment := range i.element.ElementComments().Leading {
+		if comment.StartPos.Offset < offset {
+			offset = comment.StartPos.Offset
+		}
+	}
+	return offset
+}
+
+// endOffset returns the offset after the end of the item, including its trailing comments
+//
+func (i item) endOffset() int {
+	offset := i.element.EndPosition(nil).Offset
+	trailing := i.element.ElementComments().Trailing
+	if len(trailing) > 0 {
+		lastTrailingOffset := trailing[len(trailing)-1].EndPos.Offset
+		if lastTrailingOffset > offset {
+			offset = lastTrailingOffset
+		}
+	}
+	return offset + 1
+}
+
+func declarationItems(declarations []ast.Declaration) []item {
+	items := make([]item, 0, len(declarations))
+	for _, declaration := range declarations {
+		items = append(
+			items,
+			item{
+				element:    declaration,
+				doc:        declaration.Doc,
+				contiguous: true,
+			},
+		)
+	}
+	return items
+}
+
+func statementItems(statements []ast.Statement) []item {
+	items := make([]item, 0, len(statements))
+	for _, statement := range statements {
+		items = append(
+			items,
+			item{
+				element:     statement,
+				doc:         statement.Doc,
+				contiguous:  true,
+				isStatement: true,
+			},
+		)
+	}
+	return items
+}
+
+// nestedItems returns the nested declarations or statements of the given element, if any
+//
+func nestedItems(element ast.CommentedElement) []item {
+	switch element := element.(type) {
+	case *ast.CompositeDeclaration:
+		// The members of events are not written,
+		// only the parameters of the initializer
+		if element.CompositeKind == common.CompositeKindEvent {
+			return nil
+		}
+		return declarationItems(element.Members.Declarations())
+
+	case *ast.InterfaceDeclaration:
+		return declarationItems(element.Members.Declarations())
+
+	case *ast.FunctionDeclaration:
+		if element.FunctionBlock == nil {
+			return nil
+		}
+		return statementItems(element.FunctionBlock.Block.Statements)
+
+	case *ast.SpecialFunctionDeclaration:
+		return nestedItems(element.FunctionDeclaration)
+
+	case *ast.TransactionDeclaration:
+		// The pre-conditions and post-conditions are between the fields and functions,
+		// so only a single field or function can be formatted on its own
+		var items []item
+		for _, field := range element.Fields {
+			items = append(items, item{element: field, doc: field.Doc})
+		}
+		for _, function := range []*ast.SpecialFunctionDeclaration{element.Prepare, element.Execute} {
+			if function == nil {
+				continue
+			}
+			items = append(items, item{element: function, doc: function.Doc})
+		}
+		return items
+
+	case *ast.WhileStatement:
+		return statementItems(element.Block.Statements)
+
+	case *ast.ForStatement:
+		return statementItems(element.Block.Statements)
+	}
+
+	return nil
+}
+
+// overlappingItems returns the index of the first and last item overlapping with the given range,
+// or -1 if no item overlaps with the range.
+//
+func overlappingItems(items []item, startOffset, endOffset int) (first, last int) {
+	first, last = -1, -1
+	for i, item := range items {
+		if item.startOffset() >= endOffset || item.endOffset() <= startOffset {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	return
+}
+
+// formatItems formats the given consecutive items, which are nested at the given depth
+//
+func formatItems(code string, items []item, depth int, config Config) *Edit {
+
+	var doc prettier.Doc
+
+	if items[0].isStatement {
+		statements := make([]ast.Statement, 0, len(items))
+		for _, item := range items {
+			statements = append(statements, item.element.(ast.Statement))
+		}
+		doc = ast.StatementsDoc(statements)
+	} else {
+		docs := make([]prettier.Doc, 0, len(items))
+		for _, item := range items {
+			docs = append(
+				docs,
+				prettier.Concat{
+					prettier.HardLine{},
+					ast.CommentedDoc(item.element, item.doc()),
+				},
+			)
+		}
+		doc = prettier.Join(prettier.HardLine{}, docs...)
+	}
+
+	var indentedDoc prettier.Doc = doc
+	for i := 0; i < depth; i++ {
+		indentedDoc = prettier.Indent{Doc: indentedDoc}
+	}
+
+	// The document starts with a line break, followed by the indentation
+	newText := strings.TrimPrefix(render(indentedDoc, config), "\n")
+
+	startOffset := items[0].startOffset()
+	endOffset := items[len(items)-1].endOffset()
+
+	// Replace the indentation of the first line, if the items start on their own line
+
+	lineStartOffset := strings.LastIndexByte(code[:startOffset], '\n') + 1
+	if strings.TrimSpace(code[lineStartOffset:startOffset]) == "" {
+		startOffset = lineStartOffset
+	} else {
+		newText = strings.TrimLeft(newText, " \t")
+	}
+
+	if code[startOffset:endOffset] == newText {
+		return nil
+	}
+
+	return &Edit{
+		StartPos: offsetPosition(code, startOffset),
+		EndPos:   offsetPosition(code, endOffset),
+		NewText:  newText,
+	}
+}
+
+// render renders the given document, without trailing whitespace
+//
+func render(doc prettier.Doc, config Config) string {
+	var builder strings.Builder
+	prettier.Prettier(&builder, doc, config.maxLineWidth(), config.indent())
+
+	lines := strings.Split(builder.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// checkFormatted checks that the formatted code is a valid program,
+// and that it contains all comments of the original code
+//
+func checkFormatted(code string, formatted string) error {
+	_, err := parser.ParseProgram(formatted, nil)
+	if err != nil {
+		return fmt.Errorf("formatting produced an invalid program: %w", err)
+	}
+
+	expectedCount := commentCount(code)
+	actualCount := commentCount(formatted)
+	if actualCount != expectedCount {
+		return fmt.Errorf(
+			"formatting lost comments: expected %d, got %d",
+			expectedCount,
+			actualCount,
+		)
+	}
+
+	return nil
+}
+
+// commentCount returns the number of comments in the given code.
+// Nested block comments are counted as part of the enclosing block comment.
+//
+func commentCount(code string) int {
+	tokens := lexer.Lex(code, nil)
+	defer tokens.Reclaim()
+
+	count := 0
+	blockCommentDepth := 0
+
+	for {
+		token := tokens.Next()
+
+		switch token.Type {
+		case lexer.TokenEOF:
+			return count
+
+		case lexer.TokenLineComment:
+			count++
+
+		case lexer.TokenBlockCommentStart:
+			if blockCommentDepth == 0 {
+				count++
+			}
+			blockCommentDepth++
+
+		case lexer.TokenBlockCommentEnd:
+			blockCommentDepth--
+		}
+	}
+}
+
+// offsetPosition returns the position of the given offset in the given code
+//
+func offsetPosition(code string, offset int) ast.Position {
+	line := 1
+	column := 0
+	for i := 0; i < offset; i++ {
+		if code[i] == '\n' {
+			line++
+			column = 0
+		} else {
+			column++
+		}
+	}
+
+	return ast.Position{
+		Offset: offset,
+		Line:   line,
+		Column: column,
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+const unformattedCode = `
+// License
+
+/// C is a contract
+pub contract C {
+  pub var x: Int // the value
+  /* initializer */
+  init() { self.x = 1
+     // done
+  }
+
+  pub fun f(): Int {
+      let a = 1
+
+
+      // after empty lines
+      return a
+  }
+}
+`
+
+const formattedCode = `// License
+
+/// C is a contract
+pub contract C {
+    pub var x: Int // the value
+
+    /* initializer */
+    init() {
+        self.x = 1
+        // done
+    }
+
+    pub fun f(): Int {
+        let a = 1
+
+        // after empty lines
+        return a
+    }
+}
+`
+
+func TestFormat(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("comments", func(t *testing.T) {
+
+		t.Parallel()
+
+		formatted, err := Format(unformattedCode, Config{})
+		require.NoError(t, err)
+		assert.Equal(t, formattedCode, formatted)
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+
+		t.Parallel()
+
+		formatted, err := Format(formattedCode, Config{})
+		require.NoError(t, err)
+		assert.Equal(t, formattedCode, formatted)
+	})
+
+	t.Run("indent", func(t *testing.T) {
+
+		t.Parallel()
+
+		formatted, err := Format(
+			"fun test() { return }",
+			Config{Indent: "\t"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "fun test() {\n\treturn\n}\n", formatted)
+	})
+
+	t.Run("only comments", func(t *testing.T) {
+
+		t.Parallel()
+
+		formatted, err := Format("  // a\n\n  /* b */  ", Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "// a\n\n/* b */\n", formatted)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+
+		t.Parallel()
+
+		_, err := Format("fun test( {}", Config{})
+		require.Error(t, err)
+		require.IsType(t, parser.Error{}, err)
+	})
+}
+
+func TestFormatRange(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+pub contract C {
+    pub fun f() {
+          let a = 1
+        let b = 2
+    }
+
+    pub fun g() {
+      let c = 3
+    }
+}
+`
+
+	t.Run("statement", func(t *testing.T) {
+
+		t.Parallel()
+
+		// Range inside of "let a = 1"
+		edit, err := FormatRange(code, 48, 49, Config{})
+		require.NoError(t, err)
+		require.NotNil(t, edit)
+
+		assert.Equal(t,
+			&Edit{
+				StartPos: ast.Position{Offset: 36, Line: 4, Column: 0},
+				EndPos:   ast.Position{Offset: 55, Line: 4, Column: 19},
+				NewText:  "        let a = 1",
+			},
+			edit,
+		)
+	})
+
+	t.Run("formatted statement", func(t *testing.T) {
+
+		t.Parallel()
+
+		// Range inside of "let b = 2"
+		edit, err := FormatRange(code, 66, 67, Config{})
+		require.NoError(t, err)
+		require.Nil(t, edit)
+	})
+
+	t.Run("declarations", func(t *testing.T) {
+
+		t.Parallel()
+
+		// Range from "let b = 2" to "let c = 3"
+		edit, err := FormatRange(code, 66, 107, Config{})
+		require.NoError(t, err)
+		require.NotNil(t, edit)
+
+		assert.Equal(t,
+			&Edit{
+				StartPos: ast.Position{Offset: 18, Line: 3, Column: 0},
+				EndPos:   ast.Position{Offset: 120, Line: 10, Column: 5},
+				NewText: "    pub fun f() {\n" +
+					"        let a = 1\n" +
+					"        let b = 2\n" +
+					"    }\n" +
+					"\n" +
+					"    pub fun g() {\n" +
+					"        let c = 3\n" +
+					"    }",
+			},
+			edit,
+		)
+	})
+
+	t.Run("outside of declarations", func(t *testing.T) {
+
+		t.Parallel()
+
+		edit, err := FormatRange(code, 0, 1, Config{})
+		require.NoError(t, err)
+		require.Nil(t, edit)
+	})
+}
@@ -0,0 +1,602 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onflow/atree"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// EmulatorBackend is the emulated blockchain which backs `Test.Blockchain` values.
+//
+// It executes scripts and transactions using the interpreter runtime,
+// and keeps all accounts, contracts and storage in memory.
+//
+type EmulatorBackend struct {
+	runtime          runtime.Runtime
+	runtimeInterface *runtimeInterface
+	transactionHash  uint64
+}
+
+var _ stdlib.EmulatorBackend = &EmulatorBackend{}
+
+// NewEmulatorBackend returns a new, empty emulated blockchain.
+// If the given coverage report is not nil, line hits of all
+// executed scripts, transactions and contracts are recorded in it.
+//
+func NewEmulatorBackend(coverageReport *runtime.CoverageReport) *EmulatorBackend {
+	rt := runtime.NewInterpreterRuntime(
+		runtime.WithContractUpdateValidationEnabled(true),
+	)
+	if coverageReport != nil {
+		rt.SetCoverageReport(coverageReport)
+	}
+
+	return &EmulatorBackend{
+		runtime:          rt,
+		runtimeInterface: newRuntimeInterface(),
+	}
+}
+
+// Logs returns all messages logged by executed scripts and transactions.
+//
+func (e *EmulatorBackend) Logs() []string {
+	return e.runtimeInterface.logs
+}
+
+// Events returns all events emitted by executed transactions.
+//
+func (e *EmulatorBackend) Events() []cadence.Event {
+	return e.runtimeInterface.events
+}
+
+func (e *EmulatorBackend) RunScript(
+	inter *interpreter.Interpreter,
+	code string,
+	arguments []interpreter.Value,
+) *stdlib.ScriptResult {
+
+	encodedArguments, err := e.encodeArguments(inter, arguments)
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error: err,
+		}
+	}
+
+	value, err := e.runtime.ExecuteScript(
+		runtime.Script{
+			Source:    []byte(code),
+			Arguments: encodedArguments,
+		},
+		runtime.Context{
+			Interface: e.runtimeInterface,
+			Location:  common.ScriptLocation(e.nextTransactionHash()),
+		},
+	)
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error: err,
+		}
+	}
+
+	importedValue, err := runtime.ImportValue(
+		inter,
+		interpreter.ReturnEmptyLocationRange,
+		value,
+		sema.AnyStructType,
+	)
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error: err,
+		}
+	}
+
+	return &stdlib.ScriptResult{
+		Value: importedValue,
+	}
+}
+
+func (e *EmulatorBackend) CreateAccount() (*stdlib.Account, error) {
+	address, err := e.runtimeInterface.CreateAccount(common.Address{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stdlib.Account{
+		Address: address,
+	}, nil
+}
+
+func (e *EmulatorBackend) RunTransaction(
+	inter *interpreter.Interpreter,
+	code string,
+	signers []*stdlib.Account,
+	arguments []interpreter.Value,
+) error {
+
+	encodedArguments, err := e.encodeArguments(inter, arguments)
+	if err != nil {
+		return err
+	}
+
+	signerAddresses := make([]common.Address, len(signers))
+	for i, signer := range signers {
+		signerAddresses[i] = signer.Address
+	}
+
+	e.runtimeInterface.signers = signerAddresses
+	defer func() {
+		e.runtimeInterface.signers = nil
+	}()
+
+	err = e.runtime.ExecuteTransaction(
+		runtime.Script{
+			Source:    []byte(code),
+			Arguments: encodedArguments,
+		},
+		runtime.Context{
+			Interface: e.runtimeInterface,
+			Location:  common.TransactionLocation(e.nextTransactionHash()),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	e.runtimeInterface.blockHeight++
+
+	return nil
+}
+
+func (e *EmulatorBackend) DeployContract(
+	inter *interpreter.Interpreter,
+	name string,
+	code string,
+	account *stdlib.Account,
+	arguments []interpreter.Value,
+) error {
+
+	// Deploy the contract using a transaction which is signed by the given account.
+	// The contract initializer arguments are passed as transaction arguments,
+	// so their types are declared as transaction parameters.
+
+	var parameters strings.Builder
+	var contractArguments strings.Builder
+
+	for i, argument := range arguments {
+		exportedArgument, err := runtime.ExportValue(
+			argument,
+			inter,
+			interpreter.ReturnEmptyLocationRange,
+		)
+		if err != nil {
+			return err
+		}
+
+		parameters.WriteString(
+			fmt.Sprintf(
+				", arg%d: %s",
+				i,
+				exportedArgument.Type().ID(),
+			),
+		)
+
+		contractArguments.WriteString(fmt.Sprintf(", arg%d", i))
+	}
+
+	transaction := fmt.Sprintf(
+		`
+          transaction(code: String%s) {
+              prepare(signer: AuthAccount) {
+                  signer.contracts.add(name: "%s", code: code.decodeHex()%s)
+              }
+          }
+        `,
+		parameters.String(),
+		name,
+		contractArguments.String(),
+	)
+
+	codeArgument := interpreter.NewUnmeteredStringValue(
+		hex.EncodeToString([]byte(code)),
+	)
+
+	return e.RunTransaction(
+		inter,
+		transaction,
+		[]*stdlib.Account{account},
+		append([]interpreter.Value{codeArgument}, arguments...),
+	)
+}
+
+func (e *EmulatorBackend) encodeArguments(
+	inter *interpreter.Interpreter,
+	arguments []interpreter.Value,
+) ([][]byte, error) {
+
+	encodedArguments := make([][]byte, len(arguments))
+
+	for i, argument := range arguments {
+		exportedArgument, err := runtime.ExportValue(
+			argument,
+			inter,
+			interpreter.ReturnEmptyLocationRange,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedArguments[i], err = json.Encode(exportedArgument)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encodedArguments, nil
+}
+
+func (e *EmulatorBackend) nextTransactionHash() (hash [32]byte) {
+	e.transactionHash++
+	binary.BigEndian.PutUint64(hash[:], e.transactionHash)
+	return
+}
+
+// runtimeInterface is the runtime.Interface implementation of the emulator backend.
+// It keeps all accounts, contracts, and storage in memory.
+//
+type runtimeInterface struct {
+	storedValues   map[string][]byte
+	storageIndices map[string]uint64
+	contracts      map[common.AddressLocation][]byte
+	contractNames  map[common.Address][]string
+	programs       map[common.Location]*interpreter.Program
+	accountCount   uint64
+	uuid           uint64
+	blockHeight    uint64
+	signers        []common.Address
+	logs           []string
+	events         []cadence.Event
+}
+
+var _ runtime.Interface = &runtimeInterface{}
+
+func newRuntimeInterface() *runtimeInterface {
+	return &runtimeInterface{
+		storedValues:   map[string][]byte{},
+		storageIndices: map[string]uint64{},
+		contracts:      map[common.AddressLocation][]byte{},
+		contractNames:  map[common.Address][]string{},
+		programs:       map[common.Location]*interpreter.Program{},
+	}
+}
+
+func storageKey(owner, key []byte) string {
+	return strings.Join([]string{string(owner), string(key)}, "|")
+}
+
+func (i *runtimeInterface) ResolveLocation(
+	identifiers []runtime.Identifier,
+	location runtime.Location,
+) ([]runtime.ResolvedLocation, error) {
+
+	addressLocation, ok := location.(common.AddressLocation)
+
+	// if the location is not an address location, e.g. an identifier location
+	// (`import Crypto`), then return a single resolved location which declares
+	// all identifiers.
+
+	if !ok {
+		return []runtime.ResolvedLocation{
+			{
+				Location:    location,
+				Identifiers: identifiers,
+			},
+		}, nil
+	}
+
+	// if the location is an address,
+	// and no specific identifiers where requested in the import statement,
+	// then fetch all identifiers at this address
+
+	if len(identifiers) == 0 {
+		for _, name := range i.contractNames[addressLocation.Address] {
+			identifiers = append(
+				identifiers,
+				runtime.Identifier{
+					Identifier: name,
+				},
+			)
+		}
+	}
+
+	// return one resolved location per identifier.
+	// each resolved location is an address contract location
+
+	resolvedLocations := make([]runtime.ResolvedLocation, len(identifiers))
+	for index, identifier := range identifiers {
+		resolvedLocations[index] = runtime.ResolvedLocation{
+			Location: common.AddressLocation{
+				Address: addressLocation.Address,
+				Name:    identifier.Identifier,
+			},
+			Identifiers: []runtime.Identifier{identifier},
+		}
+	}
+
+	return resolvedLocations, nil
+}
+
+func (i *runtimeInterface) GetCode(location runtime.Location) ([]byte, error) {
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok {
+		return nil, fmt.Errorf("cannot get code for location: %s", location)
+	}
+	return i.contracts[addressLocation], nil
+}
+
+func (i *runtimeInterface) GetProgram(location runtime.Location) (*interpreter.Program, error) {
+	return i.programs[location], nil
+}
+
+func (i *runtimeInterface) SetProgram(location runtime.Location, program *interpreter.Program) error {
+	i.programs[location] = program
+	return nil
+}
+
+func (i *runtimeInterface) GetValue(owner, key []byte) (value []byte, err error) {
+	return i.storedValues[storageKey(owner, key)], nil
+}
+
+func (i *runtimeInterface) SetValue(owner, key, value []byte) (err error) {
+	i.storedValues[storageKey(owner, key)] = value
+	return nil
+}
+
+func (i *runtimeInterface) ValueExists(owner, key []byte) (exists bool, err error) {
+	return len(i.storedValues[storageKey(owner, key)]) > 0, nil
+}
+
+func (i *runtimeInterface) AllocateStorageIndex(owner []byte) (result atree.StorageIndex, err error) {
+	index := i.storageIndices[string(owner)] + 1
+	i.storageIndices[string(owner)] = index
+	binary.BigEndian.PutUint64(result[:], index)
+	return
+}
+
+func (i *runtimeInterface) CreateAccount(_ runtime.Address) (address runtime.Address, err error) {
+	i.accountCount++
+	binary.BigEndian.PutUint64(address[:], i.accountCount)
+	return address, nil
+}
+
+func (i *runtimeInterface) AddEncodedAccountKey(_ runtime.Address, _ []byte) error {
+	return errNotSupported("adding encoded account keys")
+}
+
+func (i *runtimeInterface) RevokeEncodedAccountKey(_ runtime.Address, _ int) (publicKey []byte, err error) {
+	return nil, errNotSupported("revoking encoded account keys")
+}
+
+func (i *runtimeInterface) AddAccountKey(
+	_ runtime.Address,
+	_ *runtime.PublicKey,
+	_ runtime.HashAlgorithm,
+	_ int,
+) (*runtime.AccountKey, error) {
+	return nil, errNotSupported("adding account keys")
+}
+
+func (i *runtimeInterface) GetAccountKey(_ runtime.Address, _ int) (*runtime.AccountKey, error) {
+	return nil, nil
+}
+
+func (i *runtimeInterface) RevokeAccountKey(_ runtime.Address, _ int) (*runtime.AccountKey, error) {
+	return nil, errNotSupported("revoking account keys")
+}
+
+func (i *runtimeInterface) UpdateAccountContractCode(address runtime.Address, name string, code []byte) (err error) {
+	location := common.AddressLocation{
+		Address: address,
+		Name:    name,
+	}
+
+	if _, ok := i.contracts[location]; !ok {
+		i.contractNames[address] = append(i.contractNames[address], name)
+	}
+
+	i.contracts[location] = code
+	delete(i.programs, location)
+
+	return nil
+}
+
+func (i *runtimeInterface) GetAccountContractCode(address runtime.Address, name string) (code []byte, err error) {
+	location := common.AddressLocation{
+		Address: address,
+		Name:    name,
+	}
+	return i.contracts[location], nil
+}
+
+func (i *runtimeInterface) RemoveAccountContractCode(address runtime.Address, name string) (err error) {
+	location := common.AddressLocation{
+		Address: address,
+		Name:    name,
+	}
+
+	delete(i.contracts, location)
+	delete(i.programs, location)
+
+	names := i.contractNames[address]
+	for index, contractName := range names {
+		if contractName == name {
+			i.contractNames[address] = append(names[:index:index], names[index+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (i *runtimeInterface) GetSigningAccounts() ([]runtime.Address, error) {
+	return i.signers, nil
+}
+
+func (i *runtimeInterface) ProgramLog(message string) error {
+	i.logs = append(i.logs, message)
+	return nil
+}
+
+func (i *runtimeInterface) EmitEvent(event cadence.Event) error {
+	i.events = append(i.events, event)
+	return nil
+}
+
+func (i *runtimeInterface) GenerateUUID() (uint64, error) {
+	i.uuid++
+	return i.uuid, nil
+}
+
+func (i *runtimeInterface) MeterComputation(_ common.ComputationKind, _ uint) error {
+	return nil
+}
+
+func (i *runtimeInterface) DecodeArgument(argument []byte, _ cadence.Type) (cadence.Value, error) {
+	return json.Decode(nil, argument)
+}
+
+func (i *runtimeInterface) GetCurrentBlockHeight() (uint64, error) {
+	return i.blockHeight, nil
+}
+
+func (i *runtimeInterface) GetBlockAtHeight(height uint64) (block runtime.Block, exists bool, err error) {
+	if height > i.blockHeight {
+		return runtime.Block{}, false, nil
+	}
+
+	var heightBytes [8]byte
+	binary.BigEndian.PutUint64(heightBytes[:], height)
+
+	return runtime.Block{
+		Height:    height,
+		View:      height,
+		Hash:      sha256.Sum256(heightBytes[:]),
+		Timestamp: time.Unix(int64(height), 0).UnixNano(),
+	}, true, nil
+}
+
+func (i *runtimeInterface) UnsafeRandom() (uint64, error) {
+	return 0, nil
+}
+
+func (i *runtimeInterface) VerifySignature(
+	_ []byte,
+	_ string,
+	_ []byte,
+	_ []byte,
+	_ runtime.SignatureAlgorithm,
+	_ runtime.HashAlgorithm,
+) (bool, error) {
+	return false, errNotSupported("signature verification")
+}
+
+func (i *runtimeInterface) Hash(_ []byte, _ string, _ runtime.HashAlgorithm) ([]byte, error) {
+	return nil, errNotSupported("hashing")
+}
+
+func (i *runtimeInterface) GetAccountBalance(_ common.Address) (value uint64, err error) {
+	return 0, nil
+}
+
+func (i *runtimeInterface) GetAccountAvailableBalance(_ common.Address) (value uint64, err error) {
+	return 0, nil
+}
+
+func (i *runtimeInterface) GetStorageUsed(_ runtime.Address) (value uint64, err error) {
+	return 0, nil
+}
+
+func (i *runtimeInterface) GetStorageCapacity(_ runtime.Address) (value uint64, err error) {
+	return 0, nil
+}
+
+func (i *runtimeInterface) ImplementationDebugLog(_ string) error {
+	return nil
+}
+
+func (i *runtimeInterface) ValidatePublicKey(_ *runtime.PublicKey) error {
+	return nil
+}
+
+func (i *runtimeInterface) GetAccountContractNames(address runtime.Address) ([]string, error) {
+	return i.contractNames[address], nil
+}
+
+func (i *runtimeInterface) RecordTrace(
+	_ string,
+	_ common.Location,
+	_ time.Duration,
+	_ []attribute.KeyValue,
+) {
+	// NO-OP
+}
+
+func (i *runtimeInterface) BLSVerifyPOP(_ *runtime.PublicKey, _ []byte) (bool, error) {
+	return false, errNotSupported("BLS proof of possession verification")
+}
+
+func (i *runtimeInterface) BLSAggregateSignatures(_ [][]byte) ([]byte, error) {
+	return nil, errNotSupported("BLS signature aggregation")
+}
+
+func (i *runtimeInterface) BLSAggregatePublicKeys(_ []*runtime.PublicKey) (*runtime.PublicKey, error) {
+	return nil, errNotSupported("BLS public key aggregation")
+}
+
+func (i *runtimeInterface) ResourceOwnerChanged(
+	_ *interpreter.Interpreter,
+	_ *interpreter.CompositeValue,
+	_ common.Address,
+	_ common.Address,
+) {
+	// NO-OP
+}
+
+func (i *runtimeInterface) MeterMemory(_ common.MemoryUsage) error {
+	return nil
+}
+
+func errNotSupported(operation string) error {
+	return fmt.Errorf("%s is not supported by the emulator backend", operation)
+}
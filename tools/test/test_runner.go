@@ -0,0 +1,443 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/cmd"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/pretty"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// testFunctionPrefix is the prefix of the names of the functions
+// that are discovered and run as tests.
+//
+const testFunctionPrefix = "test"
+
+// setupFunctionName is the name of the optional function
+// which is invoked before each test.
+//
+const setupFunctionName = "setup"
+
+// Result is the result of running a single test function.
+// Error is nil if the test passed.
+//
+type Result struct {
+	TestName string
+	Error    error
+}
+
+// Results is the list of results of all tests of a test script.
+//
+type Results []Result
+
+// TestRunner runs tests written in Cadence.
+//
+// Tests are the functions of a script which have a name with the prefix `test`,
+// and which have no parameters. Each test function is run in isolation:
+// the script is interpreted again for each test, i.e. global variables are reset,
+// and blockchains created using `Test.newEmulatorBlockchain` are not shared between tests.
+//
+// If the script declares a `setup` function, it is invoked before each test.
+//
+type TestRunner struct {
+	coverageReport *runtime.CoverageReport
+}
+
+// NewTestRunner returns a new test runner.
+//
+func NewTestRunner() *TestRunner {
+	return &TestRunner{}
+}
+
+// WithCoverageReport configures the test runner to record the line hits
+// of test scripts and of all scripts, transactions and contracts
+// executed by emulated blockchains in the given report.
+//
+func (r *TestRunner) WithCoverageReport(coverageReport *runtime.CoverageReport) *TestRunner {
+	r.coverageReport = coverageReport
+	return r
+}
+
+// RunTests runs all tests of the given script.
+//
+// The returned error is non-nil if the script could not be parsed or checked.
+// Failures of tests are reported in the results.
+//
+func (r *TestRunner) RunTests(location common.Location, script string) (Results, error) {
+	program, err := r.parseAndCheck(location, script)
+	if err != nil {
+		return nil, err
+	}
+
+	var results Results
+
+	for _, functionDeclaration := range program.Program.FunctionDeclarations() {
+		functionName := functionDeclaration.Identifier.Identifier
+
+		if !isTestFunction(functionDeclaration) {
+			continue
+		}
+
+		results = append(
+			results,
+			Result{
+				TestName: functionName,
+				Error:    r.runTest(program, location, functionName),
+			},
+		)
+	}
+
+	return results, nil
+}
+
+// RunTest runs the single test function with the given name of the given script.
+//
+func (r *TestRunner) RunTest(location common.Location, script string, functionName string) (*Result, error) {
+	program, err := r.parseAndCheck(location, script)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		TestName: functionName,
+		Error:    r.runTest(program, location, functionName),
+	}, nil
+}
+
+func isTestFunction(declaration *ast.FunctionDeclaration) bool {
+	return strings.HasPrefix(declaration.Identifier.Identifier, testFunctionPrefix) &&
+		(declaration.ParameterList == nil || len(declaration.ParameterList.Parameters) == 0)
+}
+
+func (r *TestRunner) parseAndCheck(location common.Location, script string) (*interpreter.Program, error) {
+	program, err := parser.ParseProgram(script, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The checker is configured like the checkers of the command-line tools,
+	// e.g. `cadence execute`, but test scripts may only import `Test` and `Crypto`
+
+	checker, err := cmd.NewChecker(
+		program,
+		location,
+		map[common.Location]string{},
+		nil,
+		sema.WithImportHandler(
+			func(checker *sema.Checker, importedLocation common.Location, _ ast.Range) (sema.Import, error) {
+				var elaboration *sema.Elaboration
+
+				switch importedLocation {
+				case stdlib.CryptoChecker.Location:
+					elaboration = stdlib.CryptoChecker.Elaboration
+
+				case stdlib.TestContractLocation:
+					elaboration = stdlib.TestContractChecker.Elaboration
+
+				default:
+					return nil, ImportedProgramError{
+						Location: importedLocation,
+					}
+				}
+
+				return sema.ElaborationImport{
+					Elaboration: elaboration,
+				}, nil
+			},
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checker.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.coverageReport != nil {
+		r.coverageReport.InspectProgram(location, program)
+	}
+
+	return interpreter.ProgramFromChecker(checker), nil
+}
+
+func (r *TestRunner) runTest(
+	program *interpreter.Program,
+	location common.Location,
+	functionName string,
+) (err error) {
+
+	inter, err := r.newInterpreter(program, location)
+	if err != nil {
+		return err
+	}
+
+	err = inter.Interpret()
+	if err != nil {
+		return err
+	}
+
+	if inter.Globals.Contains(setupFunctionName) {
+		_, err = inter.Invoke(setupFunctionName)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = inter.Invoke(functionName)
+	return err
+}
+
+func (r *TestRunner) newInterpreter(
+	program *interpreter.Program,
+	location common.Location,
+) (*interpreter.Interpreter, error) {
+
+	testFramework := &testFramework{
+		runner:   r,
+		location: location,
+	}
+
+	// The interpreter is configured like the interpreters of the command-line tools,
+	// e.g. `cadence execute`, but additionally loads the `Test` contract
+
+	options := []interpreter.Option{
+		interpreter.WithImportLocationHandler(
+			func(inter *interpreter.Interpreter, location common.Location) interpreter.Import {
+				var checker *sema.Checker
+
+				switch location {
+				case stdlib.CryptoChecker.Location:
+					checker = stdlib.CryptoChecker
+
+				case stdlib.TestContractLocation:
+					checker = stdlib.TestContractChecker
+
+				default:
+					panic(errors.NewUnexpectedError("cannot import location: %s", location))
+				}
+
+				subInterpreter, err := inter.NewSubInterpreter(
+					interpreter.ProgramFromChecker(checker),
+					location,
+				)
+				if err != nil {
+					panic(err)
+				}
+
+				return interpreter.InterpreterImport{
+					Interpreter: subInterpreter,
+				}
+			},
+		),
+		interpreter.WithContractValueHandler(
+			func(
+				inter *interpreter.Interpreter,
+				compositeType *sema.CompositeType,
+				constructorGenerator func(common.Address) *interpreter.HostFunctionValue,
+				invocationRange ast.Range,
+			) *interpreter.CompositeValue {
+
+				var contract *interpreter.CompositeValue
+				var err error
+
+				switch compositeType.Location {
+				case stdlib.CryptoChecker.Location:
+					contract, err = stdlib.NewCryptoContract(
+						inter,
+						constructorGenerator(common.Address{}),
+						invocationRange,
+					)
+
+				case stdlib.TestContractLocation:
+					contract, err = stdlib.NewTestContract(
+						inter,
+						testFramework,
+						constructorGenerator(common.Address{}),
+						invocationRange,
+					)
+
+				default:
+					panic(errors.NewUnexpectedError(
+						"cannot load contract: %s",
+						compositeType.Location,
+					))
+				}
+
+				if err != nil {
+					panic(err)
+				}
+
+				return contract
+			},
+		),
+	}
+
+	if r.coverageReport != nil {
+		// Only record the coverage of the test script itself,
+		// not the coverage of the test framework
+
+		options = append(
+			options,
+			interpreter.WithOnStatementHandler(
+				func(inter *interpreter.Interpreter, statement ast.Statement) {
+					if inter.Location != location {
+						return
+					}
+					line := statement.StartPosition().Line
+					r.coverageReport.AddLineHit(inter.Location, line)
+				},
+			),
+			interpreter.WithOnFunctionEntryHandler(
+				func(inter *interpreter.Interpreter, function *interpreter.InterpretedFunctionValue) {
+					if inter.Location != location || function.Declaration == nil {
+						return
+					}
+					r.coverageReport.AddFunctionHit(inter.Location, function.Declaration)
+				},
+			),
+			interpreter.WithOnBranchHandler(
+				func(inter *interpreter.Interpreter, element ast.Element, branch int) {
+					if inter.Location != location {
+						return
+					}
+					r.coverageReport.AddBranchHit(inter.Location, element, branch)
+				},
+			),
+		)
+	}
+
+	return cmd.NewInterpreter(
+		program,
+		location,
+		map[common.Location]string{},
+		nil,
+		options...,
+	)
+}
+
+// testFramework is the stdlib.TestFramework implementation of the test runner.
+//
+type testFramework struct {
+	runner   *TestRunner
+	location common.Location
+}
+
+var _ stdlib.TestFramework = &testFramework{}
+
+func (f *testFramework) NewEmulatorBackend() stdlib.EmulatorBackend {
+	return NewEmulatorBackend(f.runner.coverageReport)
+}
+
+// ReadFile returns the content of the file at the given path.
+// Relative paths are resolved relative to the directory of the test script,
+// if the location of the test script is a file path.
+//
+func (f *testFramework) ReadFile(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		if stringLocation, ok := f.location.(common.StringLocation); ok {
+			path = filepath.Join(filepath.Dir(string(stringLocation)), path)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// ImportedProgramError is reported when a test script imports a program
+// other than the standard library contracts `Test` and `Crypto`.
+// Contracts under test should be deployed to an emulated blockchain instead.
+//
+type ImportedProgramError struct {
+	Location common.Location
+}
+
+var _ errors.UserError = ImportedProgramError{}
+
+func (ImportedProgramError) IsUserError() {}
+
+func (e ImportedProgramError) Error() string {
+	return fmt.Sprintf(
+		"cannot import `%s`: test scripts may only import `%s` and `%s`",
+		e.Location,
+		stdlib.TestContractLocation,
+		stdlib.CryptoChecker.Location,
+	)
+}
+
+// PrettyPrintResults writes a human-readable summary of the given results to the given writer.
+// Errors of failed tests are pretty-printed with source excerpts from the given codes.
+//
+func PrettyPrintResults(
+	writer pretty.Writer,
+	results Results,
+	location common.Location,
+	codes map[common.Location]string,
+	colorize bool,
+) error {
+	var passed, failed int
+
+	for _, result := range results {
+		if result.Error == nil {
+			passed++
+			_, err := fmt.Fprintf(writer, "- PASS: %s\n", result.TestName)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		failed++
+		_, err := fmt.Fprintf(writer, "- FAIL: %s\n", result.TestName)
+		if err != nil {
+			return err
+		}
+
+		err = pretty.NewErrorPrettyPrinter(writer, colorize).
+			PrettyPrintError(result.Error, location, codes)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(writer)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "\n%d passed, %d failed\n", passed, failed)
+	return err
+}
@@ -0,0 +1,434 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+var testLocation = common.StringLocation("test")
+
+func runTests(t *testing.T, script string) Results {
+	runner := NewTestRunner()
+	results, err := runner.RunTests(testLocation, script)
+	require.NoError(t, err)
+	return results
+}
+
+func TestRunningMultipleTests(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+        pub fun testFunc1() {
+            assert(false)
+        }
+
+        pub fun testFunc2() {
+            assert(true)
+        }
+
+        pub fun helper(x: Int) {
+            assert(false)
+        }
+    `
+
+	results := runTests(t, code)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "testFunc1", results[0].TestName)
+	assert.Error(t, results[0].Error)
+
+	assert.Equal(t, "testFunc2", results[1].TestName)
+	assert.NoError(t, results[1].Error)
+}
+
+func TestRunningSingleTest(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+        pub fun testFunc1() {
+            assert(false)
+        }
+
+        pub fun testFunc2() {
+            assert(true)
+        }
+    `
+
+	runner := NewTestRunner()
+
+	result, err := runner.RunTest(testLocation, code, "testFunc1")
+	require.NoError(t, err)
+	assert.Error(t, result.Error)
+
+	result, err = runner.RunTest(testLocation, code, "testFunc2")
+	require.NoError(t, err)
+	assert.NoError(t, result.Error)
+}
+
+func TestSetup(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+        pub var x = 0
+
+        pub fun setup() {
+            x = x + 1
+        }
+
+        pub fun testFirst() {
+            assert(x == 1)
+            x = 10
+        }
+
+        pub fun testSecond() {
+            // globals are reset between tests
+            assert(x == 1)
+        }
+    `
+
+	results := runTests(t, code)
+	require.Len(t, results, 2)
+
+	for _, result := range results {
+		assert.NoError(t, result.Error, result.TestName)
+	}
+}
+
+func TestAssertFunctions(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("assert", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testPass() {
+                Test.assert(true, message: "should not fail")
+            }
+
+            pub fun testFail() {
+                Test.assert(false, message: "some reason")
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 2)
+
+		assert.NoError(t, results[0].Error)
+
+		require.Error(t, results[1].Error)
+		require.ErrorAs(t, results[1].Error, &stdlib.AssertionError{})
+		assert.Contains(t, results[1].Error.Error(), "some reason")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testFail() {
+                Test.fail(message: "some reason")
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 1)
+
+		require.ErrorAs(t, results[0].Error, &stdlib.AssertionError{})
+		assert.Contains(t, results[0].Error.Error(), "some reason")
+	})
+}
+
+func TestMatchers(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("equal", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testEqual() {
+                Test.expect("hello", Test.equal("hello"))
+            }
+
+            pub fun testNotEqual() {
+                Test.expect(1, Test.equal(2))
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 2)
+
+		assert.NoError(t, results[0].Error)
+		require.ErrorAs(t, results[1].Error, &stdlib.AssertionError{})
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testCustom() {
+                let isEven = Test.Matcher(test: fun (value: AnyStruct): Bool {
+                    return (value as! Int) % 2 == 0
+                })
+
+                Test.expect(4, isEven)
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 1)
+		assert.NoError(t, results[0].Error)
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testCombined() {
+                let one = Test.equal(1)
+                let two = Test.equal(2)
+
+                Test.expect(1, one.or(two))
+                Test.expect(2, one.or(two))
+                Test.expect(1, Test.not(two))
+                Test.expect(1, one.and(Test.not(two)))
+            }
+
+            pub fun testCombinedFailure() {
+                Test.expect(3, Test.equal(1).or(Test.equal(2)))
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 2)
+
+		assert.NoError(t, results[0].Error)
+		require.ErrorAs(t, results[1].Error, &stdlib.AssertionError{})
+	})
+}
+
+func TestImportingPrograms(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+        import FooContract from 0x01
+
+        pub fun testFoo() {}
+    `
+
+	runner := NewTestRunner()
+	_, err := runner.RunTests(testLocation, code)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test scripts may only import `Test` and `Crypto`")
+}
+
+func TestEmulatorBlockchain(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("execute script", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testScript() {
+                let blockchain = Test.newEmulatorBlockchain()
+
+                let result = blockchain.executeScript(
+                    "pub fun main(a: Int, b: Int): Int { return a + b }",
+                    [2, 3]
+                )
+
+                Test.assert(result.status == Test.ResultStatus.succeeded, message: "script failed")
+                Test.expect(result.returnValue!, Test.equal(5))
+            }
+
+            pub fun testFailingScript() {
+                let blockchain = Test.newEmulatorBlockchain()
+
+                let result = blockchain.executeScript(
+                    "pub fun main() { panic(\"oops\") }",
+                    []
+                )
+
+                Test.assert(result.status == Test.ResultStatus.failed, message: "script succeeded")
+                Test.assert(result.returnValue == nil, message: "unexpected return value")
+                Test.assert(result.error != nil, message: "missing error")
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 2)
+
+		for _, result := range results {
+			assert.NoError(t, result.Error, result.TestName)
+		}
+	})
+
+	t.Run("execute transaction", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testTransaction() {
+                let blockchain = Test.newEmulatorBlockchain()
+                let account = blockchain.createAccount()
+
+                let tx = Test.Transaction(
+                    code: "transaction(x: Int) { prepare(signer: AuthAccount) { signer.save(x, to: /storage/x) } }",
+                    signers: [account],
+                    arguments: [42]
+                )
+
+                let result = blockchain.executeTransaction(tx)
+                Test.assert(result.status == Test.ResultStatus.succeeded, message: "transaction failed")
+
+                let script = "pub fun main(address: Address): Int { return getAuthAccount(address).load<Int>(from: /storage/x)! }"
+                let scriptResult = blockchain.executeScript(script, [account.address])
+                Test.expect(scriptResult.returnValue!, Test.equal(42))
+            }
+
+            pub fun testFailingTransaction() {
+                let blockchain = Test.newEmulatorBlockchain()
+
+                let tx = Test.Transaction(
+                    code: "transaction { prepare() { panic(\"oops\") } }",
+                    signers: [],
+                    arguments: []
+                )
+
+                let result = blockchain.executeTransaction(tx)
+                Test.assert(result.status == Test.ResultStatus.failed, message: "transaction succeeded")
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 2)
+
+		for _, result := range results {
+			assert.NoError(t, result.Error, result.TestName)
+		}
+	})
+
+	t.Run("deploy contract", func(t *testing.T) {
+		t.Parallel()
+
+		const code = `
+            import Test
+
+            pub fun testDeploy() {
+                let blockchain = Test.newEmulatorBlockchain()
+                let account = blockchain.createAccount()
+
+                let contractCode = "pub contract Foo { pub let greeting: String; init(greeting: String) { self.greeting = greeting } }"
+
+                let err = blockchain.deployContract(
+                    name: "Foo",
+                    code: contractCode,
+                    account: account,
+                    arguments: ["hello"]
+                )
+                Test.assert(err == nil, message: "deployment failed")
+
+                let script = "import Foo from 0x01\n pub fun main(): String { return Foo.greeting }"
+                let result = blockchain.executeScript(script, [])
+                Test.expect(result.returnValue!, Test.equal("hello"))
+            }
+        `
+
+		results := runTests(t, code)
+		require.Len(t, results, 1)
+		assert.NoError(t, results[0].Error)
+	})
+}
+
+func TestCoverageReport(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+        pub fun testFoo() {
+            let x = 1
+            if x == 2 {
+                panic("unreachable")
+            }
+        }
+    `
+
+	coverageReport := runtime.NewCoverageReport()
+
+	runner := NewTestRunner().WithCoverageReport(coverageReport)
+	results, err := runner.RunTests(testLocation, code)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Error)
+
+	locationCoverage := coverageReport.Coverage[testLocation.ID()]
+	require.NotNil(t, locationCoverage)
+
+	assert.Equal(
+		t,
+		map[int]int{
+			3: 1,
+			4: 1,
+			5: 0,
+		},
+		locationCoverage.LineHits,
+	)
+
+	assert.Equal(t, "66.7%", locationCoverage.Percentage())
+
+	functions := locationCoverage.Functions
+	require.Len(t, functions, 1)
+	for _, function := range functions {
+		assert.Equal(t, "testFoo", function.Name)
+		assert.Equal(t, 1, function.Hits)
+	}
+
+	branches := locationCoverage.Branches
+	require.Len(t, branches, 1)
+	for _, branch := range branches {
+		assert.Equal(t, runtime.BranchKindIf, branch.Kind)
+		assert.Equal(t, []int{0, 1}, branch.Hits)
+	}
+}
@@ -1240,6 +1240,14 @@ func NewMeteredFix64(gauge common.MemoryGauge, constructor func() (string, error
 	return NewFix64(value)
 }
 
+// NewMeteredFix64FromRawFixedPointNumber returns a Fix64 value
+// for the given raw fixed-point number, i.e. the value scaled by the factor 10^8
+//
+func NewMeteredFix64FromRawFixedPointNumber(gauge common.MemoryGauge, n int64) Fix64 {
+	common.UseMemory(gauge, fix64MemoryUsage)
+	return Fix64(n)
+}
+
 func (Fix64) isValue() {}
 
 func (Fix64) Type() Type {
@@ -1301,6 +1309,14 @@ func NewMeteredUFix64(gauge common.MemoryGauge, constructor func() (string, erro
 	return NewUFix64(value)
 }
 
+// NewMeteredUFix64FromRawFixedPointNumber returns a UFix64 value
+// for the given raw fixed-point number, i.e. the value scaled by the factor 10^8
+//
+func NewMeteredUFix64FromRawFixedPointNumber(gauge common.MemoryGauge, n uint64) UFix64 {
+	common.UseMemory(gauge, ufix64MemoryUsage)
+	return UFix64(n)
+}
+
 func ParseUFix64(s string) (uint64, error) {
 	v, err := fixedpoint.ParseUFix64(s)
 	if err != nil {
@@ -16,13 +16,29 @@
  * limitations under the License.
  */
 
-package ir
+package vm
 
-//go:generate go run golang.org/x/tools/cmd/stringer -type=BinOp
+import (
+	"fmt"
 
-type BinOp uint
-
-const (
-	BinOpUnknown BinOp = iota
-	BinOpPlus
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
 )
+
+// ImportUnavailableError is reported when a program is imported,
+// but the VM has no import handler.
+//
+type ImportUnavailableError struct {
+	Location common.Location
+}
+
+var _ errors.UserError = ImportUnavailableError{}
+
+func (ImportUnavailableError) IsUserError() {}
+
+func (e ImportUnavailableError) Error() string {
+	return fmt.Sprintf(
+		"cannot import program: no import handler for location `%s`",
+		e.Location,
+	)
+}
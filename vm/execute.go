@@ -0,0 +1,904 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"encoding/binary"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/compiler"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// frame is the activation of a function.
+//
+type frame struct {
+	module   *module
+	function *compiler.Function
+	locals   []interpreter.Value
+	upvalues [][]interpreter.Value
+	// ip is the offset of the next instruction
+	ip int
+	// offset is the offset of the current instruction
+	offset int
+	// stackBase is the size of the stack when the function was invoked
+	stackBase        int
+	getLocationRange func() interpreter.LocationRange
+}
+
+func (f *frame) readU8() uint8 {
+	operand := f.function.Code[f.ip]
+	f.ip++
+	return operand
+}
+
+func (f *frame) readU16() uint16 {
+	operand := binary.BigEndian.Uint16(f.function.Code[f.ip:])
+	f.ip += 2
+	return operand
+}
+
+func (vm *VM) push(value interpreter.Value) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() interpreter.Value {
+	last := len(vm.stack) - 1
+	value := vm.stack[last]
+	vm.stack[last] = nil
+	vm.stack = vm.stack[:last]
+	return value
+}
+
+func (vm *VM) peek() interpreter.Value {
+	return vm.stack[len(vm.stack)-1]
+}
+
+// popN pops the given number of values, in the order they were pushed.
+//
+func (vm *VM) popN(count int) []interpreter.Value {
+	start := len(vm.stack) - count
+	values := make([]interpreter.Value, count)
+	copy(values, vm.stack[start:])
+	for i := start; i < len(vm.stack); i++ {
+		vm.stack[i] = nil
+	}
+	vm.stack = vm.stack[:start]
+	return values
+}
+
+func (vm *VM) pushFrame(function *FunctionValue, arguments []interpreter.Value) {
+
+	limit := vm.callStackDepthLimit
+	if limit > 0 && uint64(len(vm.frames)) >= limit {
+		var locationRange interpreter.LocationRange
+		if len(vm.frames) > 0 {
+			locationRange = vm.frames[len(vm.frames)-1].getLocationRange()
+		}
+		panic(interpreter.CallStackLimitExceededError{
+			Limit:         limit,
+			LocationRange: locationRange,
+		})
+	}
+
+	compiled := function.Function
+
+	locals := make([]interpreter.Value, compiled.LocalCount)
+	localIndex := 0
+	if compiled.IsMethod {
+		locals[0] = function.self
+		localIndex = 1
+	}
+	copy(locals[localIndex:], arguments)
+
+	frame := &frame{
+		module:    function.module,
+		function:  compiled,
+		locals:    locals,
+		upvalues:  function.upvalues,
+		stackBase: len(vm.stack),
+	}
+	frame.getLocationRange = func() interpreter.LocationRange {
+		return interpreter.LocationRange{
+			Location: frame.module.program.Location,
+			Range:    frame.function.Range(frame.offset),
+		}
+	}
+
+	vm.frames = append(vm.frames, frame)
+}
+
+// call invokes the given function and returns its result.
+//
+func (vm *VM) call(function *FunctionValue, arguments []interpreter.Value) interpreter.Value {
+	vm.pushFrame(function, arguments)
+	return vm.run(len(vm.frames) - 1)
+}
+
+// run executes instructions until the frame at the given depth returns.
+//
+func (vm *VM) run(depth int) interpreter.Value {
+	frame := vm.frames[len(vm.frames)-1]
+
+	for {
+		frame.offset = frame.ip
+		opcode := compiler.Opcode(frame.readU8())
+
+		module := frame.module
+		inter := module.interpreter
+
+		switch opcode {
+
+		// Control flow
+
+		case compiler.Return, compiler.ReturnValue:
+			var result interpreter.Value
+			if opcode == compiler.ReturnValue {
+				result = vm.pop()
+			} else {
+				result = interpreter.NewVoidValue(inter)
+			}
+
+			vm.frames[len(vm.frames)-1] = nil
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == depth {
+				return result
+			}
+
+			frame = vm.frames[len(vm.frames)-1]
+			vm.push(result)
+
+		case compiler.Jump:
+			frame.ip = int(frame.readU16())
+
+		case compiler.JumpIfFalse:
+			target := frame.readU16()
+			if !bool(vm.pop().(interpreter.BoolValue)) {
+				frame.ip = int(target)
+			}
+
+		case compiler.JumpIfTrue:
+			target := frame.readU16()
+			if bool(vm.pop().(interpreter.BoolValue)) {
+				frame.ip = int(target)
+			}
+
+		case compiler.JumpIfNil:
+			target := frame.readU16()
+			if _, ok := vm.pop().(interpreter.NilValue); ok {
+				frame.ip = int(target)
+			}
+
+		case compiler.Loop:
+			inter.ReportComputation(common.ComputationKindLoop, 1)
+
+		case compiler.Statement:
+			inter.ReportComputation(common.ComputationKindStatement, 1)
+
+		case compiler.Fail:
+			kind := ast.ConditionKind(frame.readU8())
+
+			var message string
+			if messageValue, ok := vm.pop().(*interpreter.StringValue); ok {
+				message = messageValue.Str
+			}
+
+			panic(interpreter.ConditionError{
+				ConditionKind: kind,
+				Message:       message,
+				LocationRange: frame.getLocationRange(),
+			})
+
+		case compiler.FailForceAssignment:
+			panic(interpreter.ForceAssignmentToNonNilResourceError{
+				LocationRange: frame.getLocationRange(),
+			})
+
+		// Constants
+
+		case compiler.True:
+			vm.push(interpreter.NewBoolValue(inter, true))
+
+		case compiler.False:
+			vm.push(interpreter.NewBoolValue(inter, false))
+
+		case compiler.Nil:
+			vm.push(interpreter.NewNilValue(inter))
+
+		case compiler.Void:
+			vm.push(interpreter.NewVoidValue(inter))
+
+		case compiler.GetConstant:
+			vm.push(vm.constant(module, frame.readU16()))
+
+		// Variables
+
+		case compiler.GetLocal:
+			vm.push(frame.locals[frame.readU16()])
+
+		case compiler.SetLocal:
+			frame.locals[frame.readU16()] = vm.pop()
+
+		case compiler.GetUpvalue:
+			depth := frame.readU16()
+			index := frame.readU16()
+			vm.push(frame.upvalues[depth-1][index])
+
+		case compiler.SetUpvalue:
+			depth := frame.readU16()
+			index := frame.readU16()
+			frame.upvalues[depth-1][index] = vm.pop()
+
+		case compiler.GetGlobal:
+			vm.push(vm.getGlobal(module, frame.readU16()))
+
+		case compiler.SetGlobal:
+			vm.setGlobal(module, frame.readU16(), vm.pop())
+
+		case compiler.GetBuiltin:
+			name := vm.constantString(module, frame.readU16())
+			vm.push(vm.builtin(name))
+
+		// Stack
+
+		case compiler.Pop:
+			vm.pop()
+
+		case compiler.Dup:
+			vm.push(vm.peek())
+
+		// Functions
+
+		case compiler.NewClosure:
+			function := module.program.Functions[frame.readU16()]
+
+			upvalues := make([][]interpreter.Value, 0, len(frame.upvalues)+1)
+			upvalues = append(upvalues, frame.locals)
+			upvalues = append(upvalues, frame.upvalues...)
+
+			vm.push(newFunctionValue(vm, module, function, upvalues))
+
+		case compiler.Invoke:
+			invocation := module.program.Invocations[frame.readU16()]
+			arguments := vm.popN(len(invocation.ArgumentTypes))
+			function := vm.pop()
+
+			inter.ReportComputation(common.ComputationKindFunctionInvocation, 1)
+
+			if function, ok := function.(*FunctionValue); ok {
+				vm.pushFrame(function, arguments)
+				frame = vm.frames[len(vm.frames)-1]
+				continue
+			}
+
+			vm.push(vm.invokeValue(inter, frame, function, arguments, invocation))
+
+		case compiler.GetMethod:
+			name := vm.constantString(module, frame.readU16())
+			target := vm.pop()
+			vm.push(vm.getMethod(inter, frame, target, name))
+
+		// Composites
+
+		case compiler.NewComposite:
+			compositeType := compositeType(module, frame.readU16())
+			vm.push(vm.newComposite(inter, frame, compositeType))
+
+		case compiler.GetField, compiler.GetFieldOrNil:
+			name := vm.constantString(module, frame.readU16())
+			target := vm.pop()
+
+			value := inter.GetMember(target, frame.getLocationRange, name)
+			if value == nil {
+				if opcode == compiler.GetField {
+					panic(interpreter.MissingMemberValueError{
+						Name:          name,
+						LocationRange: frame.getLocationRange(),
+					})
+				}
+				value = interpreter.NewNilValue(inter)
+			}
+			vm.push(value)
+
+		case compiler.SetField:
+			name := vm.constantString(module, frame.readU16())
+			value := vm.pop()
+			target := vm.pop().(interpreter.MemberAccessibleValue)
+			target.SetMember(inter, frame.getLocationRange, name, value)
+
+		case compiler.RemoveField:
+			name := vm.constantString(module, frame.readU16())
+			target := vm.pop().(interpreter.MemberAccessibleValue)
+			vm.push(target.RemoveMember(inter, frame.getLocationRange, name))
+
+		case compiler.Destroy:
+			value := vm.pop().(interpreter.ResourceKindedValue)
+			value.Destroy(inter, frame.getLocationRange)
+
+		case compiler.Emit:
+			eventType := compositeType(module, frame.readU16())
+			event := vm.pop().(*interpreter.CompositeValue)
+
+			if vm.onEventEmitted == nil {
+				panic(interpreter.EventEmissionUnavailableError{
+					LocationRange: frame.getLocationRange(),
+				})
+			}
+
+			err := vm.onEventEmitted(event, eventType)
+			if err != nil {
+				panic(err)
+			}
+
+		// Arrays and dictionaries
+
+		case compiler.NewArray:
+			arrayType, ok := module.program.Types[frame.readU16()].(sema.ArrayType)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			elements := vm.popN(int(frame.readU16()))
+
+			vm.push(interpreter.NewArrayValue(
+				inter,
+				frame.getLocationRange,
+				interpreter.ConvertSemaArrayTypeToStaticArrayType(inter, arrayType),
+				common.Address{},
+				elements...,
+			))
+
+		case compiler.NewDictionary:
+			dictionaryType, ok := module.program.Types[frame.readU16()].(*sema.DictionaryType)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			keysAndValues := vm.popN(int(frame.readU16()) * 2)
+
+			vm.push(interpreter.NewDictionaryValue(
+				inter,
+				frame.getLocationRange,
+				interpreter.ConvertSemaDictionaryTypeToStaticDictionaryType(inter, dictionaryType),
+				keysAndValues...,
+			))
+
+		case compiler.GetIndex:
+			key := vm.pop()
+			target := vm.pop().(interpreter.ValueIndexableValue)
+			vm.push(target.GetKey(inter, frame.getLocationRange, key))
+
+		case compiler.SetIndex:
+			value := vm.pop()
+			key := vm.pop()
+			target := vm.pop().(interpreter.ValueIndexableValue)
+			target.SetKey(inter, frame.getLocationRange, key, value)
+
+		case compiler.RemoveIndex:
+			key := vm.pop()
+			target := vm.pop().(interpreter.ValueIndexableValue)
+			vm.push(target.RemoveKey(inter, frame.getLocationRange, key))
+
+		case compiler.InsertIndex:
+			value := vm.pop()
+			key := vm.pop()
+			target := vm.pop().(interpreter.ValueIndexableValue)
+			target.InsertKey(inter, frame.getLocationRange, key, value)
+
+		// Optionals, references, and casting
+
+		case compiler.Some:
+			vm.push(interpreter.NewSomeValueNonCopying(inter, vm.pop()))
+
+		case compiler.EnsureOptional:
+			value := vm.pop()
+			if _, ok := value.(interpreter.OptionalValue); !ok {
+				value = interpreter.NewSomeValueNonCopying(inter, value)
+			}
+			vm.push(value)
+
+		case compiler.Unwrap:
+			some, ok := vm.pop().(*interpreter.SomeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			vm.push(some.InnerValue(inter, frame.getLocationRange))
+
+		case compiler.Force:
+			switch value := vm.pop().(type) {
+			case *interpreter.SomeValue:
+				vm.push(value.InnerValue(inter, frame.getLocationRange))
+
+			case interpreter.NilValue:
+				panic(interpreter.ForceNilError{
+					LocationRange: frame.getLocationRange(),
+				})
+
+			default:
+				vm.push(value)
+			}
+
+		case compiler.NewReference:
+			borrowType := module.program.Types[frame.readU16()]
+			value := vm.pop()
+			vm.push(inter.NewReferenceValue(
+				frame.getLocationRange,
+				frame.getLocationRange,
+				value,
+				borrowType,
+			))
+
+		case compiler.FailableCast:
+			expectedType := module.program.Types[frame.readU16()]
+			value := vm.pop()
+
+			if !inter.IsSubTypeOfSemaType(value.StaticType(inter), expectedType) {
+				vm.push(interpreter.NewNilValue(inter))
+				break
+			}
+
+			// The failable cast may upcast to an optional type, e.g. `1 as? Int?`, so box
+			value = inter.BoxOptional(frame.getLocationRange, value, expectedType)
+			vm.push(interpreter.NewSomeValueNonCopying(inter, value))
+
+		case compiler.ForceCast:
+			expectedType := module.program.Types[frame.readU16()]
+			value := vm.pop()
+
+			if !inter.IsSubTypeOfSemaType(value.StaticType(inter), expectedType) {
+				panic(interpreter.ForceCastTypeMismatchError{
+					ExpectedType:  expectedType,
+					LocationRange: frame.getLocationRange(),
+				})
+			}
+
+			vm.push(inter.BoxOptional(frame.getLocationRange, value, expectedType))
+
+		case compiler.Transfer:
+			value := vm.pop()
+			vm.push(value.Transfer(
+				inter,
+				frame.getLocationRange,
+				atree.Address{},
+				false,
+				nil,
+			))
+
+		case compiler.TransferAndConvert:
+			valueType := module.program.Types[frame.readU16()]
+			targetType := module.program.Types[frame.readU16()]
+			value := vm.pop()
+			vm.push(inter.TransferAndConvert(value, valueType, targetType, frame.getLocationRange))
+
+		case compiler.Convert:
+			valueType := module.program.Types[frame.readU16()]
+			targetType := module.program.Types[frame.readU16()]
+			value := vm.pop()
+			vm.push(inter.ConvertAndBox(frame.getLocationRange, value, valueType, targetType))
+
+		// Operators
+
+		case compiler.Add,
+			compiler.Subtract,
+			compiler.Multiply,
+			compiler.Divide,
+			compiler.Mod,
+			compiler.Less,
+			compiler.LessEqual,
+			compiler.Greater,
+			compiler.GreaterEqual:
+
+			right := vm.pop()
+			left := vm.pop()
+			vm.push(numberOperation(inter, frame, opcode, left, right))
+
+		case compiler.BitwiseOr,
+			compiler.BitwiseXor,
+			compiler.BitwiseAnd,
+			compiler.BitwiseLeftShift,
+			compiler.BitwiseRightShift:
+
+			right := vm.pop()
+			left := vm.pop()
+			vm.push(integerOperation(inter, frame, opcode, left, right))
+
+		case compiler.Equal:
+			right := vm.pop()
+			left := vm.pop()
+			vm.push(testEqual(inter, frame, left, right))
+
+		case compiler.NotEqual:
+			right := vm.pop()
+			left := vm.pop()
+			vm.push(!testEqual(inter, frame, left, right))
+
+		case compiler.Not:
+			value := vm.pop().(interpreter.BoolValue)
+			vm.push(value.Negate(inter))
+
+		case compiler.Negate:
+			value := vm.pop().(interpreter.NumberValue)
+			vm.push(value.Negate(inter))
+
+		case compiler.StringTemplate:
+			count := int(frame.readU16())
+			interpolatedValues := vm.popN(count)
+			parts := vm.popN(count + 1)
+
+			values := make([]string, len(parts))
+			for i, part := range parts {
+				values[i] = part.(*interpreter.StringValue).Str
+			}
+
+			vm.push(inter.NewStringTemplateValue(values, interpolatedValues))
+
+		default:
+			panic(errors.NewUnexpectedError("unknown opcode: %s", opcode))
+		}
+	}
+}
+
+func (vm *VM) constantString(module *module, index uint16) string {
+	stringValue, ok := vm.constant(module, index).(*interpreter.StringValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return stringValue.Str
+}
+
+// invokeValue invokes a function value which is not a function of a compiled program,
+// e.g. a host function, or a bound function of a built-in value.
+//
+func (vm *VM) invokeValue(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	function interpreter.Value,
+	arguments []interpreter.Value,
+	invocation compiler.Invocation,
+) interpreter.Value {
+
+	var self interpreter.MemberAccessibleValue
+
+	switch value := function.(type) {
+	case interpreter.BoundFunctionValue:
+		self = value.Self
+		function = value.Function
+
+	case *interpreter.EphemeralReferenceValue:
+		// References to functions are invoked like the referenced function
+		return vm.invokeValue(inter, frame, value.Value, arguments, invocation)
+	}
+
+	switch function := function.(type) {
+	case *FunctionValue:
+		if self != nil {
+			function = function.bind(vm, self)
+		}
+		return vm.call(function, arguments)
+
+	case *interpreter.HostFunctionValue:
+		return function.Function(interpreter.NewInvocation(
+			inter,
+			self,
+			arguments,
+			invocation.ArgumentTypes,
+			invocation.TypeParameterTypes,
+			frame.getLocationRange,
+		))
+
+	case interpreter.FunctionValue:
+		parameterTypes := make([]sema.Type, len(arguments))
+		if functionType := functionStaticType(inter, function); functionType != nil {
+			for i, parameter := range functionType.Parameters {
+				if i < len(parameterTypes) {
+					parameterTypes[i] = parameter.TypeAnnotation.Type
+				}
+			}
+		}
+
+		result, err := inter.InvokeFunctionValue(
+			function,
+			arguments,
+			invocation.ArgumentTypes,
+			parameterTypes,
+			ast.NewUnmeteredRange(
+				frame.getLocationRange().StartPos,
+				frame.getLocationRange().EndPos,
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return result
+	}
+
+	panic(interpreter.NotInvokableError{
+		Value: function,
+	})
+}
+
+func functionStaticType(inter *interpreter.Interpreter, function interpreter.FunctionValue) *sema.FunctionType {
+	staticType, ok := function.StaticType(inter).(interpreter.FunctionStaticType)
+	if !ok {
+		return nil
+	}
+	return staticType.Type
+}
+
+// getMethod returns the method with the given name of the given value,
+// bound to the value.
+//
+// Methods of composites are looked up in the program which declares the composite type,
+// and in the programs which declare the interfaces the composite type conforms to.
+// Other members, e.g. built-in functions, are provided by the interpreter.
+//
+func (vm *VM) getMethod(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	target interpreter.Value,
+	name string,
+) interpreter.Value {
+
+	self := target
+	if reference, ok := self.(*interpreter.EphemeralReferenceValue); ok {
+		self = reference.Value
+	}
+
+	if composite, ok := self.(*interpreter.CompositeValue); ok {
+		if method := vm.lookupMethod(composite, name); method != nil {
+			return method.bind(vm, composite)
+		}
+	}
+
+	value := inter.GetMember(target, frame.getLocationRange, name)
+	if value == nil {
+		panic(interpreter.MissingMemberValueError{
+			Name:          name,
+			LocationRange: frame.getLocationRange(),
+		})
+	}
+	return value
+}
+
+func (vm *VM) lookupMethod(composite *interpreter.CompositeValue, name string) *FunctionValue {
+	if composite.Location == nil {
+		return nil
+	}
+
+	qualifiedIdentifier := composite.QualifiedIdentifier
+	module := vm.module(composite.Location)
+
+	qualifiedName := qualifiedIdentifier + "." + name
+	if function := module.function(qualifiedName); function != nil {
+		return newFunctionValue(vm, module, function, nil)
+	}
+
+	return nil
+}
+
+// newComposite returns a new composite value of the given type, without fields,
+// which are set by the constructor.
+//
+func (vm *VM) newComposite(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	compositeType *sema.CompositeType,
+) *interpreter.CompositeValue {
+
+	var fields []interpreter.CompositeField
+
+	if compositeType.Kind == common.CompositeKindResource {
+		if vm.uuidHandler == nil {
+			panic(interpreter.UUIDUnavailableError{
+				LocationRange: frame.getLocationRange(),
+			})
+		}
+
+		uuid, err := vm.uuidHandler()
+		if err != nil {
+			panic(err)
+		}
+
+		fields = append(
+			fields,
+			interpreter.NewCompositeField(
+				inter,
+				sema.ResourceUUIDFieldName,
+				interpreter.NewUInt64Value(
+					inter,
+					func() uint64 {
+						return uuid
+					},
+				),
+			),
+		)
+	}
+
+	var address common.Address
+	if compositeType.Kind == common.CompositeKindContract {
+		if location, ok := compositeType.Location.(common.AddressLocation); ok {
+			address = location.Address
+		}
+	}
+
+	value := interpreter.NewCompositeValue(
+		inter,
+		frame.getLocationRange,
+		compositeType.Location,
+		compositeType.QualifiedIdentifier(),
+		compositeType.Kind,
+		fields,
+		address,
+	)
+
+	if compositeType.Kind == common.CompositeKindResource {
+		vm.declareDestructor(inter, compositeType, value)
+	}
+
+	return value
+}
+
+// declareDestructor declares the destructor of the given resource type, if any,
+// so it is also invoked when resources are destroyed which were loaded from storage,
+// or which were moved into arrays and dictionaries.
+//
+func (vm *VM) declareDestructor(
+	inter *interpreter.Interpreter,
+	compositeType *sema.CompositeType,
+	value *interpreter.CompositeValue,
+) {
+	typeID := compositeType.ID()
+	if vm.destructors[typeID] {
+		return
+	}
+	vm.destructors[typeID] = true
+
+	destructor := vm.lookupMethod(value, compiler.DestructorFunctionName)
+	if destructor == nil {
+		return
+	}
+
+	inter.DeclareCompositeTypeCode(
+		typeID,
+		interpreter.CompositeTypeCode{
+			DestructorFunction: destructor,
+		},
+	)
+}
+
+func numberOperation(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	opcode compiler.Opcode,
+	leftValue, rightValue interpreter.Value,
+) interpreter.Value {
+
+	left, leftOk := leftValue.(interpreter.NumberValue)
+	right, rightOk := rightValue.(interpreter.NumberValue)
+	if !leftOk || !rightOk {
+		panic(invalidOperandsError(inter, frame, opcode, leftValue, rightValue))
+	}
+
+	switch opcode {
+	case compiler.Add:
+		return left.Plus(inter, right)
+	case compiler.Subtract:
+		return left.Minus(inter, right)
+	case compiler.Multiply:
+		return left.Mul(inter, right)
+	case compiler.Divide:
+		return left.Div(inter, right)
+	case compiler.Mod:
+		return left.Mod(inter, right)
+	case compiler.Less:
+		return left.Less(inter, right)
+	case compiler.LessEqual:
+		return left.LessEqual(inter, right)
+	case compiler.Greater:
+		return left.Greater(inter, right)
+	case compiler.GreaterEqual:
+		return left.GreaterEqual(inter, right)
+	}
+
+	panic(errors.NewUnreachableError())
+}
+
+func integerOperation(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	opcode compiler.Opcode,
+	leftValue, rightValue interpreter.Value,
+) interpreter.Value {
+
+	left, leftOk := leftValue.(interpreter.IntegerValue)
+	right, rightOk := rightValue.(interpreter.IntegerValue)
+	if !leftOk || !rightOk {
+		panic(invalidOperandsError(inter, frame, opcode, leftValue, rightValue))
+	}
+
+	switch opcode {
+	case compiler.BitwiseOr:
+		return left.BitwiseOr(inter, right)
+	case compiler.BitwiseXor:
+		return left.BitwiseXor(inter, right)
+	case compiler.BitwiseAnd:
+		return left.BitwiseAnd(inter, right)
+	case compiler.BitwiseLeftShift:
+		return left.BitwiseLeftShift(inter, right)
+	case compiler.BitwiseRightShift:
+		return left.BitwiseRightShift(inter, right)
+	}
+
+	panic(errors.NewUnreachableError())
+}
+
+var binaryOperations = map[compiler.Opcode]ast.Operation{
+	compiler.Add:               ast.OperationPlus,
+	compiler.Subtract:          ast.OperationMinus,
+	compiler.Multiply:          ast.OperationMul,
+	compiler.Divide:            ast.OperationDiv,
+	compiler.Mod:               ast.OperationMod,
+	compiler.BitwiseOr:         ast.OperationBitwiseOr,
+	compiler.BitwiseXor:        ast.OperationBitwiseXor,
+	compiler.BitwiseAnd:        ast.OperationBitwiseAnd,
+	compiler.BitwiseLeftShift:  ast.OperationBitwiseLeftShift,
+	compiler.BitwiseRightShift: ast.OperationBitwiseRightShift,
+	compiler.Less:              ast.OperationLess,
+	compiler.LessEqual:         ast.OperationLessEqual,
+	compiler.Greater:           ast.OperationGreater,
+	compiler.GreaterEqual:      ast.OperationGreaterEqual,
+}
+
+func invalidOperandsError(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	opcode compiler.Opcode,
+	left, right interpreter.Value,
+) interpreter.InvalidOperandsError {
+	return interpreter.InvalidOperandsError{
+		Operation:     binaryOperations[opcode],
+		LeftType:      left.StaticType(inter),
+		RightType:     right.StaticType(inter),
+		LocationRange: frame.getLocationRange(),
+	}
+}
+
+func testEqual(
+	inter *interpreter.Interpreter,
+	frame *frame,
+	left, right interpreter.Value,
+) interpreter.BoolValue {
+
+	left = inter.Unbox(frame.getLocationRange, left)
+	right = inter.Unbox(frame.getLocationRange, right)
+
+	valueGetter := func() bool {
+		leftEquatable, ok := left.(interpreter.EquatableValue)
+		if !ok {
+			return false
+		}
+
+		return leftEquatable.Equal(inter, frame.getLocationRange, right)
+	}
+
+	return interpreter.NewBoolValueFromConstructor(inter, valueGetter)
+}
@@ -0,0 +1,77 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"github.com/onflow/cadence/runtime/compiler"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// FunctionValue is a function of a compiled program.
+//
+// It is also a host function value, so it can be passed to and invoked by host functions
+// and the interpreter, and it is invoked directly by the VM.
+//
+type FunctionValue struct {
+	*interpreter.HostFunctionValue
+	Function *compiler.Function
+	module   *module
+	// upvalues are the locals of the enclosing functions, innermost first
+	upvalues [][]interpreter.Value
+	// self is the receiver of methods
+	self interpreter.Value
+}
+
+var _ interpreter.Value = &FunctionValue{}
+
+func newFunctionValue(
+	vm *VM,
+	module *module,
+	function *compiler.Function,
+	upvalues [][]interpreter.Value,
+) *FunctionValue {
+
+	functionValue := &FunctionValue{
+		Function: function,
+		module:   module,
+		upvalues: upvalues,
+	}
+
+	functionValue.HostFunctionValue = interpreter.NewHostFunctionValue(
+		module.interpreter,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			target := functionValue
+			if function.IsMethod && invocation.Self != nil {
+				target = target.bind(vm, invocation.Self)
+			}
+			return vm.call(target, invocation.Arguments)
+		},
+		function.Type,
+	)
+
+	return functionValue
+}
+
+// bind returns the method bound to the given receiver.
+//
+func (f *FunctionValue) bind(vm *VM, self interpreter.Value) *FunctionValue {
+	bound := newFunctionValue(vm, f.module, f.Function, f.upvalues)
+	bound.self = self
+	return bound
+}
@@ -0,0 +1,110 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/compiler"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// ImportHandlerFunc is a function that returns the compiled program for a location.
+//
+type ImportHandlerFunc func(location common.Location) (*compiler.Program, error)
+
+// ContractValueHandlerFunc is a function that returns the value of a deployed contract.
+// If it returns nil, the contract is initialized by invoking its initializer without arguments.
+//
+type ContractValueHandlerFunc func(location common.Location, name string) (*interpreter.CompositeValue, error)
+
+// OnEventEmittedFunc is a function that is triggered when an event is emitted by the program.
+//
+type OnEventEmittedFunc func(event *interpreter.CompositeValue, eventType *sema.CompositeType) error
+
+// Option is a VM option.
+//
+type Option func(*VM) error
+
+// WithInterpreterOptions returns a VM option which sets the options
+// of the interpreter context of the VM, e.g. the storage or the predeclared values.
+//
+func WithInterpreterOptions(options ...interpreter.Option) Option {
+	return func(vm *VM) error {
+		vm.interpreterOptions = append(vm.interpreterOptions, options...)
+		return nil
+	}
+}
+
+// WithOnMeterComputationFuncHandler returns a VM option which sets
+// the given function as the computation metering handler.
+//
+// The VM reports the same computation kinds as the interpreter,
+// e.g. statements, loop iterations, and function invocations.
+//
+func WithOnMeterComputationFuncHandler(handler interpreter.OnMeterComputationFunc) Option {
+	return WithInterpreterOptions(interpreter.WithOnMeterComputationFuncHandler(handler))
+}
+
+// WithImportHandler returns a VM option which sets the given function as the import handler.
+//
+func WithImportHandler(handler ImportHandlerFunc) Option {
+	return func(vm *VM) error {
+		vm.importHandler = handler
+		return nil
+	}
+}
+
+// WithContractValueHandler returns a VM option which sets the given function as the contract value handler.
+//
+func WithContractValueHandler(handler ContractValueHandlerFunc) Option {
+	return func(vm *VM) error {
+		vm.contractValueHandler = handler
+		return nil
+	}
+}
+
+// WithUUIDHandler returns a VM option which sets the given function as the UUID handler,
+// which provides the UUIDs of resources.
+//
+func WithUUIDHandler(handler interpreter.UUIDHandlerFunc) Option {
+	return func(vm *VM) error {
+		vm.uuidHandler = handler
+		return nil
+	}
+}
+
+// WithOnEventEmittedHandler returns a VM option which sets the given function as the event handler.
+//
+func WithOnEventEmittedHandler(handler OnEventEmittedFunc) Option {
+	return func(vm *VM) error {
+		vm.onEventEmitted = handler
+		return nil
+	}
+}
+
+// WithCallStackDepthLimit returns a VM option which sets the maximum depth of the call stack.
+// A limit of zero means no limit.
+//
+func WithCallStackDepthLimit(limit uint64) Option {
+	return func(vm *VM) error {
+		vm.callStackDepthLimit = limit
+		return nil
+	}
+}
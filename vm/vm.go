@@ -1,6 +1,3 @@
-//go:build wasmtime
-// +build wasmtime
-
 /*
  * Cadence - The resource-oriented smart contract programming language
  *
@@ -22,130 +19,479 @@
 package vm
 
 import (
-	"fmt"
-	"math/big"
-
-	"C"
-
-	"github.com/bytecodealliance/wasmtime-go"
-
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/compiler"
+	"github.com/onflow/cadence/runtime/errors"
 	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
 )
 
-type VM interface {
-	Invoke(name string, arguments ...interpreter.Value) (interpreter.Value, error)
+// VM executes programs compiled by the compiler.
+//
+// Values are interpreter values, so values can be passed between the VM and host functions,
+// and operations on values, e.g. arithmetic, storage, and transfers,
+// are performed by the interpreter context of the VM.
+//
+type VM struct {
+	interpreter *interpreter.Interpreter
+	modules     map[common.LocationID]*module
+	stack       []interpreter.Value
+	frames      []*frame
+	// destructors are the resource types for which the destructor was declared
+	destructors map[sema.TypeID]bool
+
+	interpreterOptions   []interpreter.Option
+	importHandler        ImportHandlerFunc
+	contractValueHandler ContractValueHandlerFunc
+	uuidHandler          interpreter.UUIDHandlerFunc
+	onEventEmitted       OnEventEmittedFunc
+	callStackDepthLimit  uint64
 }
 
-type vm struct {
-	instance *wasmtime.Instance
-}
+// NewVM returns a new VM for the given program,
+// and initializes the globals of the program.
+//
+func NewVM(program *compiler.Program, options ...Option) (vm *VM, err error) {
 
-func (m *vm) Invoke(name string, arguments ...interpreter.Value) (interpreter.Value, error) {
-	f := m.instance.GetExport(name).Func()
+	vm = &VM{
+		modules:     map[common.LocationID]*module{},
+		destructors: map[sema.TypeID]bool{},
+	}
 
-	rawArguments := make([]any, len(arguments))
-	for i, argument := range arguments {
-		rawArguments[i] = argument
+	for _, option := range options {
+		err = option(vm)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	res, err := f.Call(rawArguments...)
+	interpreterOptions := append(
+		[]interpreter.Option{
+			interpreter.WithImportLocationHandler(vm.importLocation),
+		},
+		vm.interpreterOptions...,
+	)
+
+	vm.interpreter, err = interpreter.NewInterpreter(
+		newInterpreterProgram(program),
+		program.Location,
+		interpreterOptions...,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	if res == nil {
-		return nil, nil
+	mainModule := newModule(program, vm.interpreter)
+	vm.modules[program.Location.ID()] = mainModule
+
+	defer vm.recoverErrors(func(internalErr error) {
+		vm = nil
+		err = internalErr
+	})
+
+	vm.initializeModule(mainModule)
+
+	return vm, nil
+}
+
+func newInterpreterProgram(program *compiler.Program) *interpreter.Program {
+	return &interpreter.Program{
+		Program:     ast.NewProgram(nil, nil),
+		Elaboration: program.Elaboration,
 	}
+}
 
-	return res.(interpreter.Value), nil
+// Interpreter returns the interpreter context of the VM,
+// which can be used to construct and inspect values.
+//
+func (vm *VM) Interpreter() *interpreter.Interpreter {
+	return vm.interpreter
 }
 
-func NewVM(wasm []byte) (VM, error) {
+// Invoke invokes the global function with the given name
+// of the program of the VM, with the given arguments.
+//
+func (vm *VM) Invoke(name string, arguments ...interpreter.Value) (result interpreter.Value, err error) {
 
-	config := wasmtime.NewConfig()
-	config.SetWasmReferenceTypes(true)
+	mainModule := vm.mainModule()
 
-	engine := wasmtime.NewEngineWithConfig(config)
+	index, ok := mainModule.program.GlobalIndex(name)
+	if !ok {
+		return nil, interpreter.NotDeclaredError{
+			ExpectedKind: common.DeclarationKindFunction,
+			Name:         name,
+		}
+	}
 
-	store := wasmtime.NewStore(engine)
+	defer vm.recoverErrors(func(internalErr error) {
+		err = internalErr
+	})
 
-	module, err := wasmtime.NewModule(store.Engine, wasm)
-	if err != nil {
-		return nil, err
+	function, ok := vm.getGlobal(mainModule, index).(*FunctionValue)
+	if !ok {
+		return nil, interpreter.NotInvokableError{
+			Value: mainModule.globals[index],
+		}
 	}
 
-	intFunc := wasmtime.WrapFunc(
-		store,
-		func(caller *wasmtime.Caller, offset int32, length int32) (any, *wasmtime.Trap) {
-			if offset < 0 {
-				return nil, wasmtime.NewTrap(store, fmt.Sprintf("Int: invalid offset: %d", offset))
-			}
+	parameterCount := int(function.Function.ParameterCount)
+	if len(arguments) != parameterCount {
+		return nil, interpreter.ArgumentCountError{
+			ParameterCount: parameterCount,
+			ArgumentCount:  len(arguments),
+		}
+	}
 
-			if length < 2 {
-				return nil, wasmtime.NewTrap(store, fmt.Sprintf("Int: invalid length: %d", length))
-			}
+	return vm.call(function, arguments), nil
+}
 
-			mem := caller.GetExport("mem").Memory()
+// InitializeContract invokes the initializer of the contract
+// declared by the program of the VM, with the given arguments,
+// and returns the contract value.
+//
+func (vm *VM) InitializeContract(arguments ...interpreter.Value) (contract *interpreter.CompositeValue, err error) {
 
-			bytes := C.GoBytes(mem.Data(), C.int(length))
+	mainModule := vm.mainModule()
 
-			value := new(big.Int).SetBytes(bytes[1:])
-			if bytes[0] == 0 {
-				value = value.Neg(value)
-			}
+	if mainModule.program.Contract == "" {
+		return nil, errors.NewDefaultUserError("program does not declare a contract")
+	}
 
-			return interpreter.NewIntValueFromBigInt(value), nil
-		},
-	)
+	defer vm.recoverErrors(func(internalErr error) {
+		err = internalErr
+	})
 
-	stringFunc := wasmtime.WrapFunc(store, func(caller *wasmtime.Caller, offset int32, length int32) (any, *wasmtime.Trap) {
-		if offset < 0 {
-			return nil, wasmtime.NewTrap(store, fmt.Sprintf("String: invalid offset: %d", offset))
+	return vm.initializeContract(mainModule, arguments), nil
+}
+
+func (vm *VM) mainModule() *module {
+	return vm.modules[vm.interpreter.Location.ID()]
+}
+
+// recoverErrors recovers panics which occurred during the execution,
+// and wraps them like the interpreter does,
+// with the position of the instruction which was executed.
+//
+func (vm *VM) recoverErrors(onError func(error)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	var err error
+
+	switch r := r.(type) {
+	case interpreter.Error,
+		errors.ExternalError,
+		errors.InternalError,
+		errors.UserError:
+		err = r.(error)
+	case error:
+		err = errors.NewUnexpectedErrorFromCause(r)
+	default:
+		err = errors.NewUnexpectedError("%s", r)
+	}
+
+	location := vm.interpreter.Location
+
+	if len(vm.frames) > 0 {
+		frame := vm.frames[len(vm.frames)-1]
+		location = frame.module.program.Location
+
+		if _, ok := err.(ast.HasPosition); !ok {
+			err = interpreter.PositionedError{
+				Err:   err,
+				Range: frame.function.Range(frame.offset),
+			}
 		}
+	}
 
-		if length < 0 {
-			return nil, wasmtime.NewTrap(store, fmt.Sprintf("String: invalid length: %d", length))
+	if _, ok := err.(interpreter.Error); !ok {
+		err = interpreter.Error{
+			Err:      err,
+			Location: location,
 		}
+	}
 
-		mem := caller.GetExport("mem").Memory()
+	// Reset the state, the execution was aborted
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
 
-		bytes := C.GoBytes(mem.Data(), C.int(length))
+	onError(err)
+}
 
-		return interpreter.NewStringValue(string(bytes)), nil
-	})
+// module is a loaded program, with its globals.
+//
+type module struct {
+	program     *compiler.Program
+	interpreter *interpreter.Interpreter
+	globals     []interpreter.Value
+	// imports are the resolved imports of the program, in the order of the imports
+	imports     []*resolvedImport
+	constants   []interpreter.Value
+	functions   map[string]uint16
+	initialized bool
+}
 
-	addFunc := wasmtime.WrapFunc(store, func(left, right any) (any, *wasmtime.Trap) {
-		leftNumber, ok := left.(interpreter.NumberValue)
-		if !ok {
-			return nil, wasmtime.NewTrap(store, fmt.Sprintf("add: invalid left: %#+v", left))
-		}
+type resolvedImport struct {
+	module *module
+	index  uint16
+}
 
-		rightNumber, ok := right.(interpreter.NumberValue)
-		if !ok {
-			return nil, wasmtime.NewTrap(store, fmt.Sprintf("add: invalid right: %#+v", right))
+func newModule(program *compiler.Program, inter *interpreter.Interpreter) *module {
+	functions := make(map[string]uint16, len(program.Functions))
+	for i, function := range program.Functions {
+		if function.Name != "" {
+			functions[function.Name] = uint16(i)
 		}
+	}
 
-		return leftNumber.Plus(nil, rightNumber), nil
-	})
+	return &module{
+		program:     program,
+		interpreter: inter,
+		globals:     make([]interpreter.Value, len(program.Globals)),
+		imports:     make([]*resolvedImport, len(program.Imports)),
+		constants:   make([]interpreter.Value, len(program.Constants)),
+		functions:   functions,
+	}
+}
 
-	// NOTE: wasmtime currently does not support specifying imports by name,
-	// unlike other WebAssembly APIs like wasmer, JavaScript, etc.,
-	// i.e. imports are imported in the order they are given.
-
-	instance, err := wasmtime.NewInstance(
-		store,
-		module,
-		[]*wasmtime.Extern{
-			intFunc.AsExtern(),
-			stringFunc.AsExtern(),
-			addFunc.AsExtern(),
-		},
+// function returns the function of the module with the given qualified name, if any.
+//
+func (m *module) function(name string) *compiler.Function {
+	index, ok := m.functions[name]
+	if !ok {
+		return nil
+	}
+	return m.program.Functions[index]
+}
+
+// module returns the module for the given location,
+// loading the program using the import handler if needed.
+//
+// The globals of the module are initialized when they are first accessed.
+//
+func (vm *VM) module(location common.Location) *module {
+	locationID := location.ID()
+
+	if module, ok := vm.modules[locationID]; ok {
+		return module
+	}
+
+	if vm.importHandler == nil {
+		panic(ImportUnavailableError{
+			Location: location,
+		})
+	}
+
+	program, err := vm.importHandler(location)
+	if err != nil {
+		panic(err)
+	}
+
+	subInterpreter, err := vm.interpreter.NewSubInterpreter(
+		newInterpreterProgram(program),
+		location,
 	)
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
 
-	return &vm{
-		instance: instance,
-	}, nil
+	module := newModule(program, subInterpreter)
+	vm.modules[locationID] = module
+
+	return module
+}
+
+// importLocation is the import location handler of the interpreter context,
+// so the interpreter can look up the types of values of imported programs.
+//
+func (vm *VM) importLocation(_ *interpreter.Interpreter, location common.Location) interpreter.Import {
+	return interpreter.InterpreterImport{
+		Interpreter: vm.module(location).interpreter,
+	}
+}
+
+func (vm *VM) initializeModule(module *module) {
+	if module.initialized {
+		return
+	}
+	module.initialized = true
+
+	initializer := module.program.Functions[module.program.Initializer]
+	vm.call(newFunctionValue(vm, module, initializer, nil), nil)
+}
+
+// getGlobal returns the value of the global with the given index.
+//
+// The contract of the module, if any, is initialized when it is first accessed.
+//
+func (vm *VM) getGlobal(module *module, index uint16) interpreter.Value {
+	globalCount := len(module.globals)
+
+	if int(index) >= globalCount {
+		resolved := vm.resolveImport(module, int(index)-globalCount)
+		return vm.getGlobal(resolved.module, resolved.index)
+	}
+
+	value := module.globals[index]
+	if value == nil &&
+		module.program.Contract != "" &&
+		module.program.Globals[index] == module.program.Contract {
+
+		value = vm.contractValue(module)
+	}
+
+	return value
+}
+
+func (vm *VM) setGlobal(module *module, index uint16, value interpreter.Value) {
+	globalCount := len(module.globals)
+
+	if int(index) >= globalCount {
+		resolved := vm.resolveImport(module, int(index)-globalCount)
+		vm.setGlobal(resolved.module, resolved.index, value)
+		return
+	}
+
+	module.globals[index] = value
+}
+
+func (vm *VM) resolveImport(module *module, importIndex int) *resolvedImport {
+	resolved := module.imports[importIndex]
+	if resolved != nil {
+		return resolved
+	}
+
+	imp := module.program.Imports[importIndex]
+
+	importedModule := vm.module(imp.Location)
+	vm.initializeModule(importedModule)
+
+	index, ok := importedModule.program.GlobalIndex(imp.Name)
+	if !ok {
+		panic(interpreter.NotDeclaredError{
+			ExpectedKind: common.DeclarationKindValue,
+			Name:         imp.Name,
+		})
+	}
+
+	resolved = &resolvedImport{
+		module: importedModule,
+		index:  index,
+	}
+	module.imports[importIndex] = resolved
+
+	return resolved
+}
+
+// contractValue returns the contract value of the module,
+// which is provided by the contract value handler,
+// or is initialized by invoking the contract initializer without arguments.
+//
+func (vm *VM) contractValue(module *module) interpreter.Value {
+	name := module.program.Contract
+
+	if vm.contractValueHandler != nil {
+		contract, err := vm.contractValueHandler(module.program.Location, name)
+		if err != nil {
+			panic(err)
+		}
+
+		if contract != nil {
+			index, _ := module.program.GlobalIndex(name)
+			module.globals[index] = contract
+			return contract
+		}
+	}
+
+	return vm.initializeContract(module, nil)
+}
+
+func (vm *VM) initializeContract(module *module, arguments []interpreter.Value) *interpreter.CompositeValue {
+	name := module.program.Contract
+
+	constructor := module.function(name)
+	if constructor == nil {
+		panic(errors.NewUnreachableError())
+	}
+
+	// The constructor sets the contract global
+	contract, ok := vm.call(newFunctionValue(vm, module, constructor, nil), arguments).(*interpreter.CompositeValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	return contract
+}
+
+// builtin returns the predeclared value with the given name.
+//
+func (vm *VM) builtin(name string) interpreter.Value {
+	variable := vm.interpreter.FindVariable(name)
+	if variable == nil {
+		panic(interpreter.NotDeclaredError{
+			ExpectedKind: common.DeclarationKindValue,
+			Name:         name,
+		})
+	}
+	return variable.GetValue()
+}
+
+// constant returns the value for the constant with the given index.
+//
+func (vm *VM) constant(module *module, index uint16) interpreter.Value {
+	value := module.constants[index]
+	if value == nil {
+		value = vm.newConstantValue(module, module.program.Constants[index])
+		module.constants[index] = value
+	}
+
+	// NOTE: the interpreter creates a new string value for each evaluation
+	if stringValue, ok := value.(*interpreter.StringValue); ok {
+		return interpreter.NewUnmeteredStringValue(stringValue.Str)
+	}
+
+	return value
+}
+
+func (vm *VM) newConstantValue(module *module, constant compiler.Constant) interpreter.Value {
+	inter := module.interpreter
+
+	switch constant.Kind {
+	case compiler.ConstantKindString:
+		return interpreter.NewUnmeteredStringValue(string(constant.Data))
+
+	case compiler.ConstantKindCharacter:
+		return interpreter.NewUnmeteredCharacterValue(string(constant.Data))
+
+	case compiler.ConstantKindInteger:
+		return inter.NewIntegerValueFromBigInt(constant.BigInt(), constant.Type)
+
+	case compiler.ConstantKindFix64:
+		return interpreter.NewUnmeteredFix64Value(int64(constant.Uint64()))
+
+	case compiler.ConstantKindUFix64:
+		return interpreter.NewUnmeteredUFix64Value(constant.Uint64())
+
+	case compiler.ConstantKindAddress:
+		return interpreter.NewUnmeteredAddressValueFromBytes(constant.Data)
+
+	case compiler.ConstantKindPath:
+		domain := common.PathDomain(constant.Data[0])
+		return interpreter.NewUnmeteredPathValue(domain, string(constant.Data[1:]))
+	}
+
+	panic(errors.NewUnreachableError())
+}
+
+// compositeType returns the type with the given index, which must be a composite type.
+//
+func compositeType(module *module, index uint16) *sema.CompositeType {
+	compositeType, ok := module.program.Types[index].(*sema.CompositeType)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	return compositeType
 }
@@ -0,0 +1,871 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright 2019-2022 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/compiler"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/tests/checker"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+// execution is the outcome of invoking a function with either the interpreter or the VM.
+//
+type execution struct {
+	result      string
+	err         string
+	events      []string
+	computation map[common.ComputationKind]uint
+}
+
+var contractLocation = common.AddressLocation{
+	Address: common.MustBytesToAddress([]byte{0x1}),
+	Name:    "C",
+}
+
+// differentialTest parses and checks the program, and optionally an imported contract,
+// invokes the function `test` with both the interpreter and the VM,
+// and asserts that the results, errors, emitted events, and metered computation are equal.
+//
+type differentialTest struct {
+	code     string
+	contract string
+}
+
+func (test differentialTest) check(t *testing.T) (main *sema.Checker, imported *sema.Checker) {
+
+	var options []sema.Option
+
+	if test.contract != "" {
+		var err error
+		imported, err = checker.ParseAndCheckWithOptions(t,
+			test.contract,
+			checker.ParseAndCheckOptions{
+				Location: contractLocation,
+			},
+		)
+		require.NoError(t, err)
+
+		options = append(options,
+			sema.WithLocationHandler(
+				func(identifiers []ast.Identifier, _ common.Location) ([]sema.ResolvedLocation, error) {
+					return []sema.ResolvedLocation{
+						{
+							Location:    contractLocation,
+							Identifiers: identifiers,
+						},
+					}, nil
+				},
+			),
+			sema.WithImportHandler(
+				func(_ *sema.Checker, _ common.Location, _ ast.Range) (sema.Import, error) {
+					return sema.ElaborationImport{
+						Elaboration: imported.Elaboration,
+					}, nil
+				},
+			),
+		)
+	}
+
+	main, err := checker.ParseAndCheckWithOptions(t,
+		test.code,
+		checker.ParseAndCheckOptions{
+			Options: options,
+		},
+	)
+	require.NoError(t, err)
+
+	return main, imported
+}
+
+func (test differentialTest) run(t *testing.T, arguments ...interpreter.Value) execution {
+
+	main, imported := test.check(t)
+
+	interpreted := test.interpret(t, main, imported, arguments)
+	executed := test.execute(t, main, imported, arguments)
+
+	assert.Equal(t, interpreted, executed)
+
+	return executed
+}
+
+func newExecution() *execution {
+	return &execution{
+		computation: map[common.ComputationKind]uint{},
+	}
+}
+
+func (e *execution) finish(result interpreter.Value, err error) execution {
+	if err != nil {
+		e.err = err.Error()
+	} else if result != nil {
+		e.result = result.String()
+	}
+	return *e
+}
+
+func (e *execution) meterComputation(kind common.ComputationKind, intensity uint) {
+	switch kind {
+	case common.ComputationKindStatement,
+		common.ComputationKindLoop,
+		common.ComputationKindFunctionInvocation:
+
+		e.computation[kind] += intensity
+	}
+}
+
+func (e *execution) uuidHandler() func() (uint64, error) {
+	var uuid uint64
+	return func() (uint64, error) {
+		uuid++
+		return uuid, nil
+	}
+}
+
+func (test differentialTest) interpret(
+	t *testing.T,
+	main *sema.Checker,
+	imported *sema.Checker,
+	arguments []interpreter.Value,
+) execution {
+
+	execution := newExecution()
+
+	options := []interpreter.Option{
+		interpreter.WithStorage(interpreter.NewInMemoryStorage(nil)),
+		interpreter.WithUUIDHandler(execution.uuidHandler()),
+		interpreter.WithOnMeterComputationFuncHandler(execution.meterComputation),
+		interpreter.WithOnEventEmittedHandler(
+			func(
+				_ *interpreter.Interpreter,
+				_ func() interpreter.LocationRange,
+				event *interpreter.CompositeValue,
+				_ *sema.CompositeType,
+			) error {
+				execution.events = append(execution.events, event.String())
+				return nil
+			},
+		),
+		interpreter.WithContractValueHandler(
+			func(
+				inter *interpreter.Interpreter,
+				_ *sema.CompositeType,
+				constructorGenerator func(common.Address) *interpreter.HostFunctionValue,
+				invocationRange ast.Range,
+			) *interpreter.CompositeValue {
+
+				constructor := constructorGenerator(contractLocation.Address)
+
+				value, err := inter.InvokeFunctionValue(constructor, nil, nil, nil, invocationRange)
+				require.NoError(t, err)
+
+				return value.(*interpreter.CompositeValue)
+			},
+		),
+		interpreter.WithImportLocationHandler(
+			func(inter *interpreter.Interpreter, location common.Location) interpreter.Import {
+				subInterpreter, err := inter.NewSubInterpreter(
+					interpreter.ProgramFromChecker(imported),
+					location,
+				)
+				require.NoError(t, err)
+
+				return interpreter.InterpreterImport{
+					Interpreter: subInterpreter,
+				}
+			},
+		),
+	}
+
+	inter, err := interpreter.NewInterpreter(
+		interpreter.ProgramFromChecker(main),
+		main.Location,
+		options...,
+	)
+	require.NoError(t, err)
+
+	err = inter.Interpret()
+	require.NoError(t, err)
+
+	return execution.finish(inter.Invoke("test", arguments...))
+}
+
+func compile(t *testing.T, checker *sema.Checker) *compiler.Program {
+	program, err := compiler.NewCompiler(checker.Program, checker.Elaboration, checker.Location).Compile()
+	require.NoError(t, err)
+	return program
+}
+
+func (test differentialTest) execute(
+	t *testing.T,
+	main *sema.Checker,
+	imported *sema.Checker,
+	arguments []interpreter.Value,
+) execution {
+
+	execution := newExecution()
+
+	vm, err := NewVM(
+		compile(t, main),
+		WithInterpreterOptions(
+			interpreter.WithStorage(interpreter.NewInMemoryStorage(nil)),
+		),
+		WithOnMeterComputationFuncHandler(execution.meterComputation),
+		WithUUIDHandler(execution.uuidHandler()),
+		WithOnEventEmittedHandler(
+			func(event *interpreter.CompositeValue, _ *sema.CompositeType) error {
+				execution.events = append(execution.events, event.String())
+				return nil
+			},
+		),
+		WithImportHandler(
+			func(location common.Location) (*compiler.Program, error) {
+				require.Equal(t, contractLocation, location)
+				return compile(t, imported), nil
+			},
+		),
+	)
+	require.NoError(t, err)
+
+	return execution.finish(vm.Invoke("test", arguments...))
+}
+
+func TestVM(t *testing.T) {
+
+	t.Parallel()
+
+	tests := map[string]differentialTest{
+		"arithmetic": {
+			code: `
+              fun test(): [AnyStruct] {
+                  let a = 7
+                  let b: UInt8 = 3
+                  let c = 1.5
+                  return [a + 2, a - 2, a * 2, a / 2, a % 2, -a, b << 2, b | 4, b & 1, b ^ 1, c * 2.0]
+              }
+            `,
+		},
+		"comparison and logic": {
+			code: `
+              fun test(): [Bool] {
+                  let a = 1
+                  let b = 2
+                  return [a < b, a <= b, a > b, a >= b, a == b, a != b, !(a == b), a < b && b < a, a < b || b < a]
+              }
+            `,
+		},
+		"recursion": {
+			code: `
+              fun fib(_ n: Int): Int {
+                  if n < 2 {
+                      return n
+                  }
+                  return fib(n - 1) + fib(n - 2)
+              }
+
+              fun test(): Int {
+                  return fib(14)
+              }
+            `,
+		},
+		"loops": {
+			code: `
+              fun test(): Int {
+                  var sum = 0
+                  var i = 0
+                  while i < 10 {
+                      i = i + 1
+                      if i % 2 == 0 {
+                          continue
+                      }
+                      if i > 7 {
+                          break
+                      }
+                      sum = sum + i
+                  }
+                  for j, x in [10, 20, 30] {
+                      sum = sum + x * j
+                  }
+                  return sum
+              }
+            `,
+		},
+		"closures": {
+			code: `
+              fun makeCounter(): ((): Int) {
+                  var count = 0
+                  return fun (): Int {
+                      count = count + 1
+                      return count
+                  }
+              }
+
+              fun test(): [Int] {
+                  let counter = makeCounter()
+                  counter()
+                  counter()
+                  let add = fun (_ a: Int): ((Int): Int) {
+                      return fun (_ b: Int): Int {
+                          return a + b
+                      }
+                  }
+                  return [counter(), add(1)(2)]
+              }
+            `,
+		},
+		"structs": {
+			code: `
+              struct Point {
+                  var x: Int
+                  var y: Int
+
+                  init(x: Int, y: Int) {
+                      self.x = x
+                      self.y = y
+                  }
+
+                  fun move(by: Int) {
+                      self.x = self.x + by
+                      self.y = self.y + by
+                  }
+              }
+
+              fun test(): Point {
+                  let p = Point(x: 1, y: 2)
+                  p.move(by: 3)
+                  return p
+              }
+            `,
+		},
+		"resources": {
+			code: `
+              resource R {
+                  var value: Int
+                  var inner: @R?
+
+                  init(value: Int) {
+                      self.value = value
+                      self.inner <- nil
+                  }
+
+                  destroy() {
+                      emit Destroyed(value: self.value)
+                      destroy self.inner
+                  }
+              }
+
+              event Destroyed(value: Int)
+
+              fun test(): Int {
+                  let r <- create R(value: 1)
+                  r.inner <-! create R(value: 2)
+                  let inner <- r.inner <- nil
+                  let value = r.value + inner?.value!
+                  let rs <- [<-r, <-inner!]
+                  destroy rs
+                  return value
+              }
+            `,
+		},
+		"references": {
+			code: `
+              resource R {
+                  var value: Int
+
+                  init() {
+                      self.value = 1
+                  }
+
+                  fun increment() {
+                      self.value = self.value + 1
+                  }
+              }
+
+              fun test(): Int {
+                  let r <- create R()
+                  let ref = &r as &R
+                  ref.increment()
+                  let optionalRef = &r as &R?
+                  let value = ref.value + optionalRef!.value
+                  destroy r
+                  return value
+              }
+            `,
+		},
+		"optionals": {
+			code: `
+              struct S {
+                  let value: Int
+                  init() { self.value = 1 }
+                  fun get(): Int { return self.value }
+              }
+
+              fun test(): [AnyStruct] {
+                  let a: Int? = 1
+                  let b: Int? = nil
+                  let s: S? = S()
+                  var values: [AnyStruct] = [a ?? 2, b ?? 2, s?.value, s?.get()]
+                  if let x = a {
+                      values.append(x)
+                  }
+                  if let y = b {
+                      values.append(y)
+                  } else {
+                      values.append("none")
+                  }
+                  return values
+              }
+            `,
+		},
+		"arrays and dictionaries": {
+			code: `
+              fun test(): [AnyStruct] {
+                  let xs = [1, 2, 3]
+                  xs[0] = 10
+                  xs.append(4)
+                  let removed = xs.remove(at: 1)
+                  let d: {String: Int} = {"a": 1}
+                  d["b"] = 2
+                  let old = d.remove(key: "a")
+                  return [xs, xs.length, removed, d["b"], d["a"], old, d.keys]
+              }
+            `,
+		},
+		"literals": {
+			code: `
+              fun test(): [AnyStruct] {
+                  let c: Character = "x"
+                  return [c, "s", 0x42 as Address, /storage/foo, -1.25, 2.5, 1_000_000 as UInt64, nil, true]
+              }
+            `,
+		},
+		"swap": {
+			code: `
+              fun test(): [Int] {
+                  var a = 1
+                  var b = 2
+                  a <-> b
+                  let xs = [3, 4]
+                  xs[0] <-> xs[1]
+                  return [a, b, xs[0], xs[1]]
+              }
+            `,
+		},
+		"resource dictionaries": {
+			code: `
+              resource R {
+                  let id: Int
+                  init(id: Int) { self.id = id }
+              }
+
+              fun test(): [Int] {
+                  let rs: @{String: R} <- {"a": <-create R(id: 1)}
+                  rs["b"] <-! create R(id: 2)
+                  let old <- rs.insert(key: "a", <-create R(id: 3))
+                  let b <- rs.remove(key: "b")!
+                  let ids = [old?.id!, b.id, rs.length]
+                  destroy old
+                  destroy b
+                  destroy rs
+                  return ids
+              }
+            `,
+		},
+		"force assignment": {
+			code: `
+              resource R {}
+
+              fun test() {
+                  let rs: @{String: R} <- {"a": <-create R()}
+                  rs["a"] <-! create R()
+                  destroy rs
+              }
+            `,
+		},
+		"switch": {
+			code: `
+              fun describe(_ x: Int): String {
+                  switch x {
+                  case 1:
+                      return "one"
+                  case 2:
+                      return "two"
+                  default:
+                      return "many"
+                  }
+              }
+
+              fun test(): [String] {
+                  return [describe(1), describe(2), describe(3)]
+              }
+            `,
+		},
+		"enums": {
+			code: `
+              enum Color: UInt8 {
+                  case red
+                  case green
+              }
+
+              fun test(): [AnyStruct] {
+                  return [Color.green.rawValue, Color(rawValue: 0)?.rawValue, Color(rawValue: 5)]
+              }
+            `,
+		},
+		"casting": {
+			code: `
+              fun test(): [AnyStruct] {
+                  let x: AnyStruct = 1
+                  return [x as? Int, x as? String, x as! Int, x.getType(), x.isInstance(Type<Int>())]
+              }
+            `,
+		},
+		"string templates": {
+			code: `
+              fun test(): String {
+                  let name = "world"
+                  let count = 3
+                  return "hello \(name), \(count + 1) times"
+              }
+            `,
+		},
+		"conditions": {
+			code: `
+              fun half(_ x: Int): Int {
+                  pre {
+                      x % 2 == 0: "x must be even"
+                  }
+                  post {
+                      result * 2 == x
+                  }
+                  return x / 2
+              }
+
+              fun test(): Int {
+                  return half(4) + half(3)
+              }
+            `,
+		},
+		"force nil": {
+			code: `
+              fun test(): Int {
+                  let x: Int? = nil
+                  return x!
+              }
+            `,
+		},
+		"overflow": {
+			code: `
+              fun test(): UInt8 {
+                  let x: UInt8 = 255
+                  return x + 1
+              }
+            `,
+		},
+		"events": {
+			code: `
+              event Transfer(amount: UFix64, to: Address)
+
+              fun test() {
+                  emit Transfer(amount: 1.5, to: 0x1)
+                  emit Transfer(amount: 2.0, to: 0x2)
+              }
+            `,
+		},
+		"interfaces": {
+			code: `
+              struct interface Shape {
+                  fun area(): Int
+              }
+
+              struct Square: Shape {
+                  let side: Int
+                  init(side: Int) { self.side = side }
+                  fun area(): Int { return self.side * self.side }
+              }
+
+              struct Rectangle: Shape {
+                  let width: Int
+                  let height: Int
+                  init(width: Int, height: Int) {
+                      self.width = width
+                      self.height = height
+                  }
+                  fun area(): Int { return self.width * self.height }
+              }
+
+              fun test(): Int {
+                  let shapes: [{Shape}] = [Square(side: 2), Rectangle(width: 2, height: 3)]
+                  var total = 0
+                  for shape in shapes {
+                      total = total + shape.area()
+                  }
+                  return total
+              }
+            `,
+		},
+		"interface pre-condition": {
+			code: `
+              struct interface I {
+                  fun f(_ x: Int): Int {
+                      pre { x > 0 }
+                  }
+              }
+
+              struct S: I {
+                  fun f(_ x: Int): Int { return x }
+              }
+
+              fun test(): Int {
+                  let s = S()
+                  return s.f(-1)
+              }
+            `,
+		},
+		"interface post-conditions": {
+			code: `
+              struct interface I {
+                  fun f(_ x: Int): Int {
+                      post { result == before(x) * 2: "result must be doubled" }
+                  }
+              }
+
+              struct interface J {
+                  fun f(_ y: Int): Int {
+                      pre { y < 10: "y must be less than 10" }
+                      post { result > y }
+                  }
+              }
+
+              struct S: I, J {
+                  fun f(_ z: Int): Int {
+                      pre { z != 3 }
+                      if z == 4 {
+                          return z * 2 + 1
+                      }
+                      return z * 2
+                  }
+              }
+
+              fun test(): [Int] {
+                  let s = S()
+                  return [s.f(1), s.f(2), s.f(4)]
+              }
+            `,
+		},
+		"interface initializer and destructor conditions": {
+			code: `
+              resource interface R {
+                  balance: Int
+
+                  init(balance: Int) {
+                      pre { balance >= 0: "balance must not be negative" }
+                  }
+
+                  destroy() {
+                      pre { self.balance == 0: "balance must be zero" }
+                  }
+              }
+
+              resource Vault: R {
+                  let balance: Int
+
+                  init(balance: Int) {
+                      self.balance = balance
+                  }
+              }
+
+              fun test(): Int {
+                  let empty <- create Vault(balance: 0)
+                  destroy empty
+                  let vault <- create Vault(balance: 1)
+                  let balance = vault.balance
+                  destroy vault
+                  return balance
+              }
+            `,
+		},
+		"contract import": {
+			contract: `
+              pub contract C {
+                  pub var counter: Int
+
+                  pub resource Vault {
+                      pub var balance: Int
+                      init(balance: Int) { self.balance = balance }
+                  }
+
+                  init() {
+                      self.counter = 10
+                  }
+
+                  pub fun increment(): Int {
+                      self.counter = self.counter + 1
+                      return self.counter
+                  }
+
+                  pub fun createVault(balance: Int): @Vault {
+                      return <-create Vault(balance: balance)
+                  }
+              }
+            `,
+			code: `
+              import C from 0x1
+
+              fun test(): [Int] {
+                  let vault <- C.createVault(balance: 5)
+                  let balance = vault.balance
+                  destroy vault
+                  return [C.increment(), C.increment(), balance]
+              }
+            `,
+		},
+	}
+
+	for name, test := range tests { //nolint:maprangecheck
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+
+			t.Parallel()
+
+			test.run(t)
+		})
+	}
+}
+
+func TestVMArguments(t *testing.T) {
+
+	t.Parallel()
+
+	result := differentialTest{
+		code: `
+          fun test(a: Int, b: String): String {
+              return b.concat(a.toString())
+          }
+        `,
+	}.run(t,
+		interpreter.NewUnmeteredIntValueFromInt64(42),
+		interpreter.NewUnmeteredStringValue("answer: "),
+	)
+
+	assert.Equal(t, `"answer: 42"`, result.result)
+}
+
+func TestVMGenericFunctions(t *testing.T) {
+
+	t.Parallel()
+
+	// The compiler does not support generic declarations,
+	// so the invocation of a generic function is only interpreted,
+	// and the compilation must be rejected instead of producing code
+	// which fails when the argument is transferred to the type parameter
+
+	test := differentialTest{
+		code: `
+          fun id<T>(_ x: T): T {
+              return x
+          }
+
+          fun test(): Int {
+              return id<Int>(3)
+          }
+        `,
+	}
+
+	main, imported := test.check(t)
+
+	interpreted := test.interpret(t, main, imported, nil)
+	assert.Empty(t, interpreted.err)
+	assert.Equal(t, "3", interpreted.result)
+
+	_, err := compiler.NewCompiler(main.Program, main.Elaboration, main.Location).Compile()
+	require.Error(t, err)
+
+	var unsupportedErr *compiler.UnsupportedError
+	require.ErrorAs(t, err, &unsupportedErr)
+	assert.Equal(t, "generic declarations", unsupportedErr.Feature)
+}
+
+func TestVMComputationMetering(t *testing.T) {
+
+	t.Parallel()
+
+	result := differentialTest{
+		code: `
+          fun add(_ a: Int, _ b: Int): Int {
+              return a + b
+          }
+
+          fun test(): Int {
+              var sum = 0
+              for x in [1, 2, 3] {
+                  sum = add(sum, x)
+              }
+              return sum
+          }
+        `,
+	}.run(t)
+
+	assert.Equal(t,
+		map[common.ComputationKind]uint{
+			common.ComputationKindStatement:          9,
+			common.ComputationKindLoop:               3,
+			common.ComputationKindFunctionInvocation: 3,
+		},
+		result.computation,
+	)
+}
+
+func TestVMCallStackDepthLimit(t *testing.T) {
+
+	t.Parallel()
+
+	checker, err := checker.ParseAndCheck(t, `
+      fun test(): Int {
+          return test()
+      }
+    `)
+	require.NoError(t, err)
+
+	vm, err := NewVM(
+		compile(t, checker),
+		WithInterpreterOptions(
+			interpreter.WithStorage(interpreter.NewInMemoryStorage(nil)),
+		),
+		WithCallStackDepthLimit(10),
+	)
+	require.NoError(t, err)
+
+	_, err = vm.Invoke("test")
+	require.Error(t, err)
+
+	var limitErr interpreter.CallStackLimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, utils.TestLocation, limitErr.Location)
+}